@@ -0,0 +1,453 @@
+package bolt
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxAnyDepth bounds the nesting appendAny follows before handing the
+// value to encoding/json, which reports cycles properly.
+const maxAnyDepth = 32
+
+var (
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+	timeType          = reflect.TypeFor[time.Time]()
+)
+
+// appendAny appends the JSON encoding of v, matching encoding/json's
+// output for the values it supports (strings are escaped the way the rest
+// of bolt escapes them, which leaves <, > and & alone). Common types are
+// handled by a type switch without allocating; other types go through
+// reflection with per-struct-type field lists cached. It reports false,
+// with buf in an unspecified state past its original length, for values
+// it leaves to encoding/json: NaN and infinite floats, cycles, unsupported
+// map keys and struct tags using ",string", ",omitzero" or embedding.
+func appendAny(buf []byte, v any, depth int) ([]byte, bool) {
+	if depth > maxAnyDepth {
+		return buf, false
+	}
+	switch v := v.(type) {
+	case nil:
+		return append(buf, "null"...), true
+	case string:
+		return appendQuoted(buf, v), true
+	case bool:
+		return strconv.AppendBool(buf, v), true
+	case int:
+		return appendInt(buf, v), true
+	case int8:
+		return appendInt64(buf, int64(v)), true
+	case int16:
+		return appendInt64(buf, int64(v)), true
+	case int32:
+		return appendInt64(buf, int64(v)), true
+	case int64:
+		return appendInt64(buf, v), true
+	case uint:
+		return appendUint(buf, uint64(v)), true
+	case uint8:
+		return appendUint(buf, uint64(v)), true
+	case uint16:
+		return appendUint(buf, uint64(v)), true
+	case uint32:
+		return appendUint(buf, uint64(v)), true
+	case uint64:
+		return appendUint(buf, v), true
+	case float64:
+		return appendJSONFloat(buf, v, 64)
+	case float32:
+		return appendJSONFloat(buf, float64(v), 32)
+	case time.Time:
+		return appendTimeAny(buf, v)
+	case json.RawMessage:
+		return appendRawJSON(buf, v)
+	case []byte:
+		return appendBase64(buf, v), true
+	case []string:
+		if v == nil {
+			return append(buf, "null"...), true
+		}
+		buf = append(buf, '[')
+		for i, s := range v {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf = appendQuoted(buf, s)
+		}
+		return append(buf, ']'), true
+	case []int:
+		if v == nil {
+			return append(buf, "null"...), true
+		}
+		buf = append(buf, '[')
+		for i, n := range v {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf = appendInt(buf, n)
+		}
+		return append(buf, ']'), true
+	case []any:
+		if v == nil {
+			return append(buf, "null"...), true
+		}
+		buf = append(buf, '[')
+		for i, x := range v {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			var ok bool
+			if buf, ok = appendAny(buf, x, depth+1); !ok {
+				return buf, false
+			}
+		}
+		return append(buf, ']'), true
+	case map[string]string:
+		if v == nil {
+			return append(buf, "null"...), true
+		}
+		buf = append(buf, '{')
+		for i, k := range sortedKeys(v) {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf = appendQuoted(buf, k)
+			buf = append(buf, ':')
+			buf = appendQuoted(buf, v[k])
+		}
+		return append(buf, '}'), true
+	case map[string]any:
+		if v == nil {
+			return append(buf, "null"...), true
+		}
+		buf = append(buf, '{')
+		for i, k := range sortedKeys(v) {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf = appendQuoted(buf, k)
+			buf = append(buf, ':')
+			var ok bool
+			if buf, ok = appendAny(buf, v[k], depth+1); !ok {
+				return buf, false
+			}
+		}
+		return append(buf, '}'), true
+	}
+	return appendValue(buf, reflect.ValueOf(v), depth)
+}
+
+// appendValue is the reflection path of appendAny.
+func appendValue(buf []byte, v reflect.Value, depth int) ([]byte, bool) {
+	if depth > maxAnyDepth {
+		return buf, false
+	}
+	if !v.IsValid() {
+		return append(buf, "null"...), true
+	}
+	t := v.Type()
+
+	// Marshalers take precedence, as in encoding/json. Nil pointers are
+	// encoded as null rather than calling the method.
+	if t.Kind() == reflect.Pointer && v.IsNil() {
+		return append(buf, "null"...), true
+	}
+	if t == timeType {
+		return appendTimeAny(buf, v.Interface().(time.Time))
+	}
+	if t.Kind() != reflect.Pointer && v.CanAddr() &&
+		(reflect.PointerTo(t).Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType)) {
+		// encoding/json uses pointer-receiver methods of addressable values.
+		return appendValue(buf, v.Addr(), depth)
+	}
+	if t.Implements(jsonMarshalerType) {
+		raw, err := v.Interface().(json.Marshaler).MarshalJSON()
+		if err != nil {
+			return buf, false
+		}
+		return appendRawJSON(buf, raw)
+	}
+	if t.Implements(textMarshalerType) {
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return buf, false
+		}
+		return appendQuoted(buf, string(text)), true
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return appendQuoted(buf, v.String()), true
+	case reflect.Bool:
+		return strconv.AppendBool(buf, v.Bool()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return appendInt64(buf, v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return appendUint(buf, v.Uint()), true
+	case reflect.Float32:
+		return appendJSONFloat(buf, v.Float(), 32)
+	case reflect.Float64:
+		return appendJSONFloat(buf, v.Float(), 64)
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return append(buf, "null"...), true
+		}
+		return appendValue(buf, v.Elem(), depth+1)
+	case reflect.Struct:
+		return appendStruct(buf, v, depth)
+	case reflect.Map:
+		return appendMap(buf, v, depth)
+	case reflect.Slice:
+		if v.IsNil() {
+			return append(buf, "null"...), true
+		}
+		if t.Elem().Kind() == reflect.Uint8 && !reflect.PointerTo(t.Elem()).Implements(jsonMarshalerType) &&
+			!reflect.PointerTo(t.Elem()).Implements(textMarshalerType) {
+			return appendBase64(buf, v.Bytes()), true
+		}
+		return appendList(buf, v, depth)
+	case reflect.Array:
+		return appendList(buf, v, depth)
+	}
+	return buf, false // channels, funcs, complex numbers, unsafe pointers
+}
+
+func appendList(buf []byte, v reflect.Value, depth int) ([]byte, bool) {
+	buf = append(buf, '[')
+	for i := range v.Len() {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		var ok bool
+		if buf, ok = appendValue(buf, v.Index(i), depth+1); !ok {
+			return buf, false
+		}
+	}
+	return append(buf, ']'), true
+}
+
+func appendMap(buf []byte, v reflect.Value, depth int) ([]byte, bool) {
+	if v.IsNil() {
+		return append(buf, "null"...), true
+	}
+	// encoding/json sorts by the encoded key; only string and integer
+	// keys are supported here, whose encodings sort like their values.
+	type entry struct {
+		key string
+		val reflect.Value
+	}
+	entries := make([]entry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		k := iter.Key()
+		var key string
+		if k.Kind() != reflect.String && k.Type().Implements(textMarshalerType) {
+			return buf, false
+		}
+		switch k.Kind() {
+		case reflect.String:
+			key = k.String()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			key = strconv.FormatInt(k.Int(), 10)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			key = strconv.FormatUint(k.Uint(), 10)
+		default:
+			return buf, false
+		}
+		entries = append(entries, entry{key, iter.Value()})
+	}
+	slices.SortFunc(entries, func(a, b entry) int { return strings.Compare(a.key, b.key) })
+
+	buf = append(buf, '{')
+	for i, en := range entries {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = appendQuoted(buf, en.key)
+		buf = append(buf, ':')
+		var ok bool
+		if buf, ok = appendValue(buf, en.val, depth+1); !ok {
+			return buf, false
+		}
+	}
+	return append(buf, '}'), true
+}
+
+// structField is one encoded field of a struct type.
+type structField struct {
+	index     int
+	key       []byte // `"name":`, escaped
+	omitEmpty bool
+}
+
+// structInfo is the cached encoding plan of a struct type. ok is false
+// for types left to encoding/json.
+type structInfo struct {
+	fields []structField
+	ok     bool
+}
+
+var structCache sync.Map // reflect.Type -> *structInfo
+
+func cachedStructInfo(t reflect.Type) *structInfo {
+	if si, ok := structCache.Load(t); ok {
+		return si.(*structInfo)
+	}
+	si := &structInfo{ok: true}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if f.Anonymous {
+			si.ok = false // embedding promotes fields; leave it to encoding/json
+			break
+		}
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		sf := structField{index: i}
+		for opt := range strings.SplitSeq(opts, ",") {
+			switch opt {
+			case "omitempty":
+				sf.omitEmpty = true
+			case "string", "omitzero":
+				si.ok = false
+			}
+		}
+		sf.key = append(appendQuoted(nil, name), ':')
+		si.fields = append(si.fields, sf)
+	}
+	actual, _ := structCache.LoadOrStore(t, si)
+	return actual.(*structInfo)
+}
+
+func appendStruct(buf []byte, v reflect.Value, depth int) ([]byte, bool) {
+	si := cachedStructInfo(v.Type())
+	if !si.ok {
+		return buf, false
+	}
+	buf = append(buf, '{')
+	first := true
+	for _, f := range si.fields {
+		fv := v.Field(f.index)
+		if f.omitEmpty && isEmptyValue(fv) {
+			continue
+		}
+		if !first {
+			buf = append(buf, ',')
+		}
+		first = false
+		buf = append(buf, f.key...)
+		var ok bool
+		if buf, ok = appendValue(buf, fv, depth+1); !ok {
+			return buf, false
+		}
+	}
+	return append(buf, '}'), true
+}
+
+// isEmptyValue mirrors encoding/json's omitempty rule.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+func appendQuoted(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+	buf = appendJSONString(buf, s)
+	return append(buf, '"')
+}
+
+// appendJSONFloat formats f like encoding/json: plain notation except for
+// very small or large magnitudes, and no leading zero in the exponent.
+func appendJSONFloat(buf []byte, f float64, bits int) ([]byte, bool) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return buf, false
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	buf = strconv.AppendFloat(buf, f, format, -1, bits)
+	if format == 'e' {
+		// clean up e-09 to e-9
+		if n := len(buf); n >= 4 && buf[n-4] == 'e' && buf[n-3] == '-' && buf[n-2] == '0' {
+			buf[n-2] = buf[n-1]
+			buf = buf[:n-1]
+		}
+	}
+	return buf, true
+}
+
+func appendTimeAny(buf []byte, t time.Time) ([]byte, bool) {
+	if y := t.Year(); y < 0 || y >= 10000 {
+		return buf, false // encoding/json reports an error
+	}
+	buf = append(buf, '"')
+	buf = t.AppendFormat(buf, time.RFC3339Nano)
+	return append(buf, '"'), true
+}
+
+// appendRawJSON appends pre-encoded JSON, compacted to keep the record on
+// one line. Invalid JSON is left to encoding/json to report.
+func appendRawJSON(buf []byte, raw []byte) ([]byte, bool) {
+	if raw == nil {
+		return append(buf, "null"...), true
+	}
+	if !json.Valid(raw) {
+		return buf, false
+	}
+	if bytes.ContainsAny(raw, " \t\r\n") {
+		var b bytes.Buffer
+		if err := json.Compact(&b, raw); err != nil {
+			return buf, false
+		}
+		raw = b.Bytes()
+	}
+	return append(buf, raw...), true
+}
+
+func appendBase64(buf []byte, b []byte) []byte {
+	if b == nil {
+		return append(buf, "null"...)
+	}
+	buf = append(buf, '"')
+	buf = base64.StdEncoding.AppendEncode(buf, b)
+	return append(buf, '"')
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,130 @@
+package bolt
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+type anyInner struct {
+	N    int      `json:"n"`
+	Tags []string `json:"tags,omitempty"`
+}
+
+type anyLevel int
+
+func (l anyLevel) MarshalText() ([]byte, error) {
+	return []byte("lvl" + strings.Repeat("!", int(l))), nil
+}
+
+type anyPtrMarshaler struct{ v int }
+
+func (p *anyPtrMarshaler) MarshalJSON() ([]byte, error) { return []byte(`{ "ptr" : 1 }`), nil }
+
+type anyOuter struct {
+	Name     string            `json:"name"`
+	Skip     string            `json:"-"`
+	Dash     string            `json:"-,"`
+	Empty    string            `json:",omitempty"`
+	Inner    anyInner          `json:"inner"`
+	InnerPtr *anyInner         `json:"inner_ptr"`
+	Any      any               `json:"any"`
+	Labels   map[string]string `json:"labels"`
+	ByID     map[int]float64   `json:"by_id"`
+	Raw      []byte            `json:"raw"`
+	Level    anyLevel          `json:"level"`
+	Ptr      anyPtrMarshaler   `json:"ptr"`
+	When     time.Time         `json:"when"`
+	Took     time.Duration     `json:"took"`
+	Arr      [2]uint8          `json:"arr"`
+	IP       net.IP            `json:"ip"`
+	private  int
+}
+
+func TestAppendAnyMatchesEncodingJSON(t *testing.T) {
+	when := time.Date(2025, 3, 4, 5, 6, 7, 891, time.FixedZone("x", 3600))
+	values := []any{
+		nil, "plain", "quote\" back\\ nl\n ctl\x01 é", true, 0, -42, int8(-8), uint64(math.MaxUint64),
+		3.0, 0.1, 1e21, 1.5e-7, -0.0, float32(0.1), float32(3e-7), math.MaxFloat64, math.SmallestNonzeroFloat64,
+		when, []byte("bytes"), []byte(nil), []string{"a", "b"}, []string(nil), []int{1, -2},
+		[]any{1, "x", nil, []any{true}}, map[string]string{"b": "2", "a": "1"},
+		map[string]any{"z": 1, "a": []int{1}, "m": map[string]any{"k": nil}},
+		json.RawMessage(`{"a": [1, 2]}`), time.Second, anyLevel(2),
+		&anyPtrMarshaler{},
+		anyOuter{
+			Name: "n", Skip: "s", Dash: "d", Inner: anyInner{N: 1},
+			InnerPtr: &anyInner{N: 2, Tags: []string{"t"}}, Any: map[string]any{"k": 1.25},
+			Labels: map[string]string{"env": "prod"}, ByID: map[int]float64{10: 1, 2: 2, -1: 3},
+			Raw: []byte{0, 1, 2}, Level: 1, When: when, Took: time.Millisecond,
+			Arr: [2]uint8{1, 2}, IP: net.ParseIP("10.0.0.1"), private: 7,
+		},
+		&anyOuter{},
+		struct{ A, B int }{1, 2},
+	}
+	for _, v := range values {
+		want, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("json.Marshal(%#v): %v", v, err)
+		}
+		got, ok := appendAny(nil, v, 0)
+		if !ok {
+			t.Errorf("appendAny(%#v) fell back", v)
+			continue
+		}
+		if string(got) != string(want) {
+			t.Errorf("appendAny(%#v)\n got %s\nwant %s", v, got, want)
+		}
+	}
+}
+
+func TestAppendAnyFallback(t *testing.T) {
+	type embedded struct{ anyInner }
+	type tagged struct {
+		N int `json:"n,string"`
+	}
+	cyclic := []any{nil}
+	cyclic[0] = cyclic
+	for _, v := range []any{math.NaN(), math.Inf(1), embedded{}, tagged{}, make(chan int), cyclic,
+		json.RawMessage(`{`), map[anyLevel]int{1: 1}} {
+		if _, ok := appendAny(nil, v, 0); ok {
+			t.Errorf("appendAny(%T) did not fall back", v)
+		}
+	}
+
+	var buf strings.Builder
+	logger := New(NewJSONHandler(&buf))
+	logger.Info().Any("e", embedded{anyInner{N: 1}}).Any("nan", math.NaN()).Msg("x")
+	if got := buf.String(); !strings.Contains(got, `"e":{"n":1}`) || !strings.Contains(got, `"nan":"!ERROR: json: unsupported value: NaN!"`) {
+		t.Errorf("fallback output = %s", got)
+	}
+}
+
+func TestAnyAllocations(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewJSONHandler(&buf))
+	// Box the values up front: converting a slice to interface{} at the
+	// call site allocates regardless of the encoder.
+	var (
+		tags  any = []string{"a", "b"}
+		ids   any = []int{1, 2, 3}
+		items any = []any{1, "x", true}
+		inner any = &anyInner{N: 1, Tags: []string{"t"}}
+		when  any = time.Unix(1700000000, 0).UTC()
+	)
+
+	if raceDetectorEnabled {
+		t.Skip("allocation counts are not meaningful under -race")
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		buf.Reset()
+		logger.Info().Any("s", "str").Any("n", 42).Any("f", 1.5).Any("tags", tags).
+			Any("ids", ids).Any("items", items).Any("inner", inner).Any("when", when).Msg("x")
+	})
+	if allocs > 0 {
+		t.Errorf("Any allocated %.1f times per event", allocs)
+	}
+}
@@ -0,0 +1,335 @@
+package bolt
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// Lane is the priority class of an event queued by [AsyncHandler].
+type Lane uint8
+
+const (
+	// LaneLow is for high-volume diagnostics (TRACE, DEBUG). It is the
+	// first to lose events under backpressure.
+	LaneLow Lane = iota
+	// LaneNormal is for regular operational events (INFO, WARN).
+	LaneNormal
+	// LaneHigh is for events that must not be lost (ERROR, audit). It is
+	// always drained first and can be configured to block when full.
+	LaneHigh
+
+	numLanes = 3
+)
+
+// DefaultAsyncQueueSize is the per-lane capacity used by
+// [NewAsyncHandler] when none is configured.
+const DefaultAsyncQueueSize = 1024
+
+var (
+	// ErrQueueFull is returned by [AsyncHandler.Write] when an event is
+	// dropped because its lane is full.
+	ErrQueueFull = errors.New("bolt: async queue full, event dropped")
+	// ErrHandlerClosed is returned by [AsyncHandler.Write] after Close.
+	ErrHandlerClosed = errors.New("bolt: async handler closed")
+)
+
+// AsyncOptions configures [NewAsyncHandler]. A nil *AsyncOptions uses the
+// defaults.
+type AsyncOptions struct {
+	// QueueSize is the capacity of each lane. Defaults to
+	// DefaultAsyncQueueSize.
+	QueueSize int
+
+	// Classify assigns each event to a lane. It runs on the logging
+	// goroutine and may inspect the event with [Event.Level] and
+	// [Event.WalkFields]. Defaults to [DefaultLane].
+	Classify func(e *Event) Lane
+
+	// BlockHigh makes writes to a full LaneHigh wait for space instead of
+	// dropping the event. Enable it for compliance workloads where losing
+	// an audit record is worse than stalling the caller. An event with a
+	// context, see [Event.Ctx], waits only until the context is done.
+	BlockHigh bool
+
+	// ErrorHandler, if set, receives errors returned by the wrapped
+	// handler. Those writes happen on the background goroutine, so the
+	// errors cannot be returned to the logger.
+	ErrorHandler ErrorHandler
+}
+
+// DefaultLane classifies events by level: ERROR and FATAL go to
+// LaneHigh, TRACE and DEBUG to LaneLow, everything else to LaneNormal.
+func DefaultLane(e *Event) Lane {
+	switch {
+	case e.Level() >= ERROR:
+		return LaneHigh
+	case e.Level() <= DEBUG:
+		return LaneLow
+	default:
+		return LaneNormal
+	}
+}
+
+// asyncRecord is a copy of an event's finished buffer, with its logger
+// and context; the event itself is recycled as soon as Write returns.
+type asyncRecord struct {
+	level Level
+	buf   []byte
+	l     *Logger
+	ctx   context.Context
+}
+
+// AsyncHandler moves handler writes off the logging goroutine. Events are
+// copied into one of three bounded priority lanes and written to the
+// wrapped handler by a single background goroutine, which always drains
+// higher lanes first. Under sustained backpressure the low lane therefore
+// fills and drops first, while the high lane keeps flowing and, with
+// [AsyncOptions.BlockHigh], never drops an event without a context.
+//
+// FATAL events are written synchronously after the queues are flushed, so
+// nothing queued is lost when the process exits.
+//
+// Call Close before exiting to flush pending events. Use it as:
+//
+//	async := bolt.NewAsyncHandler(bolt.NewJSONHandler(os.Stdout), &bolt.AsyncOptions{
+//	    Classify: func(e *bolt.Event) bolt.Lane {
+//	        audit := false
+//	        e.WalkFields(func(k, _ []byte) bool {
+//	            audit = string(k) == "audit"
+//	            return !audit
+//	        })
+//	        if audit {
+//	            return bolt.LaneHigh
+//	        }
+//	        return bolt.DefaultLane(e)
+//	    },
+//	    BlockHigh: true,
+//	})
+//	defer async.Close()
+//	logger := bolt.New(async)
+type AsyncHandler struct {
+	next      Handler
+	classify  func(e *Event) Lane
+	blockHigh bool
+	onError   ErrorHandler
+
+	lanes   [numLanes]chan asyncRecord
+	dropped [numLanes]atomic.Uint64
+	flushc  chan chan struct{}
+	quit    chan struct{}
+	exited  chan struct{}
+
+	mu     sync.RWMutex // held for reading while enqueueing; Close takes it for writing
+	closed bool
+
+	wmu     sync.Mutex // serializes writes to next
+	ev      Event      // reused to pass records to next; guarded by wmu
+	bufPool sync.Pool
+}
+
+// NewAsyncHandler starts an AsyncHandler writing to next. If opts is nil,
+// defaults are used.
+func NewAsyncHandler(next Handler, opts *AsyncOptions) *AsyncHandler {
+	if opts == nil {
+		opts = &AsyncOptions{}
+	}
+	size := opts.QueueSize
+	if size <= 0 {
+		size = DefaultAsyncQueueSize
+	}
+	h := &AsyncHandler{
+		next:      next,
+		classify:  opts.Classify,
+		blockHigh: opts.BlockHigh,
+		onError:   opts.ErrorHandler,
+		flushc:    make(chan chan struct{}),
+		quit:      make(chan struct{}),
+		exited:    make(chan struct{}),
+	}
+	if h.classify == nil {
+		h.classify = DefaultLane
+	}
+	for i := range h.lanes {
+		h.lanes[i] = make(chan asyncRecord, size)
+	}
+	go h.run()
+	return h
+}
+
+// Write implements [Handler]. It queues a copy of the event and returns
+// ErrQueueFull if the event's lane is full and the event was dropped, or
+// ErrMemoryBudget if the copy would exceed the [SetMemoryBudget] budget.
+func (h *AsyncHandler) Write(e *Event) error {
+	if e.level == FATAL {
+		return h.writeFatal(e)
+	}
+	lane := h.classify(e)
+	if lane >= numLanes {
+		lane = LaneHigh
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.closed {
+		return ErrHandlerClosed
+	}
+
+	if lane == LaneHigh && h.blockHigh {
+		memCharge(len(e.buf))
+		rec := asyncRecord{level: e.level, buf: append(h.getBuf(), e.buf...), l: e.l, ctx: e.ctx}
+		select {
+		case h.lanes[lane] <- rec:
+			return nil
+		default:
+		}
+		select {
+		case h.lanes[lane] <- rec:
+			return nil
+		case <-e.done():
+			memRelease(len(rec.buf))
+			h.putBuf(rec.buf)
+			h.dropped[lane].Add(1)
+			return e.ctxErr(ErrQueueFull)
+		}
+	}
+	if !memReserve(len(e.buf)) {
+		h.dropped[lane].Add(1)
+		return ErrMemoryBudget
+	}
+	rec := asyncRecord{level: e.level, buf: append(h.getBuf(), e.buf...), l: e.l, ctx: e.ctx}
+	select {
+	case h.lanes[lane] <- rec:
+		return nil
+	default:
+		memRelease(len(rec.buf))
+		h.putBuf(rec.buf)
+		h.dropped[lane].Add(1)
+		return ErrQueueFull
+	}
+}
+
+// writeFatal flushes everything queued and then writes e directly, so the
+// record is on its way out before Msg terminates the process.
+func (h *AsyncHandler) writeFatal(e *Event) error {
+	h.Flush()
+	h.wmu.Lock()
+	defer h.wmu.Unlock()
+	return h.next.Write(e)
+}
+
+// Dropped returns the number of events dropped from lane because it was
+// full.
+func (h *AsyncHandler) Dropped(lane Lane) uint64 {
+	if lane >= numLanes {
+		return 0
+	}
+	return h.dropped[lane].Load()
+}
+
+// Flush blocks until every event queued before the call has been written
+// to the wrapped handler. It returns immediately after Close.
+func (h *AsyncHandler) Flush() {
+	ack := make(chan struct{})
+	select {
+	case h.flushc <- ack:
+		<-ack
+	case <-h.exited:
+	}
+}
+
+// Close stops accepting events, writes everything still queued and stops
+// the background goroutine. Writes after Close return ErrHandlerClosed.
+// It is safe to call more than once.
+func (h *AsyncHandler) Close() error {
+	h.mu.Lock()
+	if !h.closed {
+		h.closed = true
+		close(h.quit)
+	}
+	h.mu.Unlock()
+	<-h.exited
+	return nil
+}
+
+func (h *AsyncHandler) run() {
+	defer close(h.exited)
+	for {
+		if rec, ok := h.poll(); ok {
+			h.write(rec)
+			continue
+		}
+		select {
+		case rec := <-h.lanes[LaneHigh]:
+			h.write(rec)
+		case rec := <-h.lanes[LaneNormal]:
+			h.write(rec)
+		case rec := <-h.lanes[LaneLow]:
+			h.write(rec)
+		case ack := <-h.flushc:
+			h.drain()
+			close(ack)
+		case <-h.quit:
+			h.drain()
+			return
+		}
+	}
+}
+
+// poll returns the oldest record of the highest non-empty lane without
+// blocking.
+func (h *AsyncHandler) poll() (asyncRecord, bool) {
+	for lane := LaneHigh; ; lane-- {
+		select {
+		case rec := <-h.lanes[lane]:
+			return rec, true
+		default:
+		}
+		if lane == LaneLow {
+			return asyncRecord{}, false
+		}
+	}
+}
+
+func (h *AsyncHandler) drain() {
+	for {
+		rec, ok := h.poll()
+		if !ok {
+			return
+		}
+		h.write(rec)
+	}
+}
+
+func (h *AsyncHandler) write(rec asyncRecord) {
+	h.wmu.Lock()
+	h.ev.level, h.ev.buf, h.ev.l = rec.level, rec.buf, rec.l
+	if rec.ctx != nil {
+		// The caller has moved on: keep the context's values for the
+		// handler, but not a cancellation or deadline it no longer waits on.
+		h.ev.ctx = context.WithoutCancel(rec.ctx)
+	}
+	err := h.next.Write(&h.ev)
+	h.ev.buf, h.ev.l, h.ev.ctx = nil, nil, nil
+	h.wmu.Unlock()
+	memRelease(len(rec.buf))
+	h.putBuf(rec.buf)
+	if err != nil && h.onError != nil {
+		h.onError(err)
+	}
+}
+
+func (h *AsyncHandler) getBuf() []byte {
+	if b, ok := h.bufPool.Get().(*[]byte); ok {
+		return (*b)[:0]
+	}
+	return make([]byte, 0, DefaultBufferSize)
+}
+
+func (h *AsyncHandler) putBuf(b []byte) {
+	if cap(b) > PoolBufferCap {
+		return
+	}
+	h.bufPool.Put(&b)
+}
@@ -0,0 +1,206 @@
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// gatedHandler records messages in write order and blocks every write
+// until the gate is opened, letting tests build a backlog.
+type gatedHandler struct {
+	gate    chan struct{}
+	started chan struct{}
+	once    sync.Once
+	mu      sync.Mutex
+	lines   []string
+}
+
+func newGatedHandler() *gatedHandler {
+	return &gatedHandler{gate: make(chan struct{}), started: make(chan struct{})}
+}
+
+func (g *gatedHandler) Write(e *Event) error {
+	g.once.Do(func() { close(g.started) })
+	<-g.gate
+	g.mu.Lock()
+	g.lines = append(g.lines, string(e.buf))
+	g.mu.Unlock()
+	return nil
+}
+
+func (g *gatedHandler) messages() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var out []string
+	for _, l := range g.lines {
+		i := strings.Index(l, `"message":"`)
+		out = append(out, l[i+len(`"message":"`):strings.LastIndexByte(l, '"')])
+	}
+	return out
+}
+
+func TestAsyncHandlerDrainsHighLaneFirst(t *testing.T) {
+	g := newGatedHandler()
+	h := NewAsyncHandler(g, nil)
+	logger := New(h)
+
+	logger.Info().Msg("first") // occupies the worker
+	<-g.started
+	logger.Debug().Msg("low")
+	logger.Info().Msg("normal")
+	logger.Error().Msg("high")
+	close(g.gate)
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := strings.Join(g.messages(), ",")
+	if got != "first,high,normal,low" {
+		t.Errorf("write order = %s; want first,high,normal,low", got)
+	}
+}
+
+func TestAsyncHandlerDropsLowLaneFirst(t *testing.T) {
+	g := newGatedHandler()
+	h := NewAsyncHandler(g, &AsyncOptions{QueueSize: 2})
+	var errs []error
+	logger := New(h).SetErrorHandler(func(err error) { errs = append(errs, err) })
+
+	logger.Info().Msg("first")
+	<-g.started
+	for i := 0; i < 5; i++ {
+		logger.Debug().Msg("low")
+	}
+	logger.Error().Msg("high")
+	close(g.gate)
+	_ = h.Close()
+
+	if got := h.Dropped(LaneLow); got != 3 {
+		t.Errorf("Dropped(LaneLow) = %d; want 3", got)
+	}
+	if got := h.Dropped(LaneHigh); got != 0 {
+		t.Errorf("Dropped(LaneHigh) = %d; want 0", got)
+	}
+	if len(errs) != 3 || !errors.Is(errs[0], ErrQueueFull) {
+		t.Errorf("error handler got %v; want 3 x ErrQueueFull", errs)
+	}
+}
+
+func TestAsyncHandlerBlockHigh(t *testing.T) {
+	g := newGatedHandler()
+	h := NewAsyncHandler(g, &AsyncOptions{
+		QueueSize: 1,
+		BlockHigh: true,
+		Classify: func(e *Event) Lane {
+			audit := false
+			e.WalkFields(func(k, _ []byte) bool {
+				audit = string(k) == "audit"
+				return !audit
+			})
+			if audit {
+				return LaneHigh
+			}
+			return DefaultLane(e)
+		},
+	})
+	logger := New(h)
+
+	logger.Info().Msg("first")
+	<-g.started
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 3; i++ {
+			logger.Info().Bool("audit", true).Msg("audit")
+		}
+		close(done)
+	}()
+	close(g.gate)
+	<-done
+	_ = h.Close()
+
+	if got := len(g.messages()); got != 4 {
+		t.Errorf("wrote %d events; want 4 with no audit drops", got)
+	}
+	if h.Dropped(LaneHigh) != 0 {
+		t.Errorf("audit events dropped with BlockHigh")
+	}
+}
+
+func TestAsyncHandlerFatalFlushesQueue(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewAsyncHandler(NewJSONHandler(&buf), nil)
+	defer h.Close()
+	logger := New(h)
+
+	for i := 0; i < 10; i++ {
+		logger.Info().Int("i", i).Msg("queued")
+	}
+	logger.Fatal().Msg("fatal")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 11 || !strings.Contains(lines[10], `"message":"fatal"`) {
+		t.Errorf("fatal not written after queued events: %q", lines)
+	}
+}
+
+func TestAsyncHandlerClose(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewAsyncHandler(NewJSONHandler(&buf), nil)
+	logger := New(h)
+	logger.Info().Msg("before close")
+	_ = h.Close()
+	_ = h.Close()
+
+	if !strings.Contains(buf.String(), "before close") {
+		t.Errorf("queued event lost on Close: %q", buf.String())
+	}
+	var errs []error
+	logger.SetErrorHandler(func(err error) { errs = append(errs, err) })
+	logger.Info().Msg("after close")
+	if len(errs) != 1 || !errors.Is(errs[0], ErrHandlerClosed) {
+		t.Errorf("write after Close: %v", errs)
+	}
+	h.Flush() // must not block after Close
+}
+
+type ctxKey struct{}
+
+// probeHandler records the logger and context of the event it is given.
+type probeHandler struct {
+	mu  sync.Mutex
+	l   *Logger
+	ctx context.Context
+}
+
+func (p *probeHandler) Write(e *Event) error {
+	p.mu.Lock()
+	p.l, p.ctx = e.l, e.Context()
+	p.mu.Unlock()
+	return nil
+}
+
+func TestAsyncHandlerPassesLoggerAndContext(t *testing.T) {
+	probe := &probeHandler{}
+	h := NewAsyncHandler(probe, nil)
+	logger := New(h)
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "span"))
+	logger.Info().Ctx(ctx).Msg("queued")
+	cancel()
+	_ = h.Close()
+
+	probe.mu.Lock()
+	defer probe.mu.Unlock()
+	if probe.l != logger {
+		t.Errorf("wrapped handler saw logger %p, want %p", probe.l, logger)
+	}
+	if v := probe.ctx.Value(ctxKey{}); v != "span" {
+		t.Errorf("context value = %v, want span", v)
+	}
+	if probe.ctx.Err() != nil {
+		t.Error("caller's cancellation reached the wrapped handler")
+	}
+}
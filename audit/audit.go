@@ -0,0 +1,332 @@
+// Package audit emits structured audit events for compliance trails.
+//
+// [Logger] builds audit events and refuses to log those missing the
+// mandatory compliance fields (actor, action, resource, outcome):
+//
+//	al := audit.New(logger, nil)
+//	err := al.Event("access").
+//	    Actor("u-1").Action("read").Resource("patient/42").
+//	    Outcome(audit.OutcomeSuccess).Compliance("HIPAA").
+//	    Log("record viewed")
+//
+// [ChainWriter] makes a trail tamper-evident by adding the SHA-256 of
+// the previous record to each record; [Verify] checks the chain.
+//
+// [ConfigChange] diffs two values of a configuration type and returns the
+// change set; [LogConfigChange] logs it as one event:
+//
+//	{"level":"info","audit":"config_change","changed":2,
+//	 "changes":{"server.port":{"old":8080,"new":9090},"db.password":{"redacted":true}},
+//	 "message":"configuration changed"}
+//
+// Struct fields are named by their json tag, falling back to the Go
+// field name, and nested structs and string-keyed maps are diffed
+// recursively with "."-joined paths. Slices and arrays whose elements
+// hold tagged fields are diffed element by element, with the index as
+// the path element ("backends.0.password"); other slices and values are
+// compared as a whole. Types that marshal themselves, such as
+// time.Time, are treated as single values.
+//
+// Secrets are controlled by struct tags:
+//
+//	Password string `audit:"redact"` // change is recorded, values are not
+//	Cache    []byte `audit:"-"`      // never diffed
+//
+// Fields tagged `json:"-"`, the convention [bolt.LogStartup] relies on to
+// hide secrets, are redacted as well. Redaction applies to everything
+// below a redacted field, and to any value recorded whole, such as an
+// added slice element or a map with non-string keys, whose type holds a
+// tagged field.
+package audit
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.klarlabs.de/bolt"
+)
+
+// ConfigChangeMessage is the message of [LogConfigChange] events.
+const ConfigChangeMessage = "configuration changed"
+
+// Change is one difference between two configurations. Old is nil for
+// added values and New is nil for removed ones. Redacted changes carry
+// neither.
+type Change struct {
+	Path     string
+	Old      any
+	New      any
+	Redacted bool
+}
+
+// ChangeSet is the ordered list of changes between two configurations.
+// It implements [bolt.LogObjectMarshaler], encoding each change under its
+// path.
+type ChangeSet []Change
+
+// MarshalLogObject implements [bolt.LogObjectMarshaler].
+func (cs ChangeSet) MarshalLogObject(e *bolt.Event) {
+	for _, c := range cs {
+		path := c.Path
+		if path == "" {
+			path = "."
+		}
+		e.Dict(path, func(d *bolt.Event) {
+			if c.Redacted {
+				d.Bool("redacted", true)
+				return
+			}
+			d.Any("old", c.Old).Any("new", c.New)
+		})
+	}
+}
+
+// Paths returns the path of every change.
+func (cs ChangeSet) Paths() []string {
+	paths := make([]string, len(cs))
+	for i, c := range cs {
+		paths[i] = c.Path
+	}
+	return paths
+}
+
+// ConfigChange returns the differences between old and new, in field
+// order, with map keys sorted. Pointers are followed; a nil pointer
+// compares as absent, and a pointer back to a value being compared is
+// not followed again. Exported fields promoted from embedded structs are
+// compared as if declared in the outer struct, even when the embedded
+// type is unexported. Values of different types are reported as a single
+// change at the root.
+func ConfigChange(old, new any) ChangeSet {
+	var cs ChangeSet
+	diff(&cs, "", reflect.ValueOf(old), reflect.ValueOf(new), false, map[visit]bool{})
+	return cs
+}
+
+// LogConfigChange logs the differences between old and new at INFO and
+// returns them. Nothing is logged when the configurations are equal.
+func LogConfigChange(logger *bolt.Logger, old, new any) ChangeSet {
+	cs := ConfigChange(old, new)
+	if len(cs) > 0 {
+		logger.Info().
+			Str("audit", "config_change").
+			Int("changed", len(cs)).
+			Object("changes", cs).
+			Msg(ConfigChangeMessage)
+	}
+	return cs
+}
+
+var (
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// visit identifies a pair of values being compared, so that diff stops
+// at cycles.
+type visit struct {
+	a, b uintptr
+	t    reflect.Type
+}
+
+// diff appends the differences between a and b under path to cs. seen
+// holds the pairs being compared further up, to stop at cycles.
+func diff(cs *ChangeSet, path string, a, b reflect.Value, redact bool, seen map[visit]bool) {
+	a, b = indirect(a), indirect(b)
+	if !a.IsValid() || !b.IsValid() || a.Type() != b.Type() {
+		if a.IsValid() || b.IsValid() {
+			cs.add(path, a, b, redact)
+		}
+		return
+	}
+	if v, ok := visitOf(a, b); ok {
+		if seen[v] {
+			return
+		}
+		seen[v] = true
+		defer delete(seen, v)
+	}
+
+	t := a.Type()
+	switch {
+	case t.Kind() == reflect.Struct && !isLeaf(t):
+		for i := range t.NumField() {
+			f := t.Field(i)
+			name, skip, red := fieldInfo(f)
+			if skip {
+				continue
+			}
+			p := path
+			if !f.Anonymous || f.Type.Kind() != reflect.Struct || f.Tag.Get("json") != "" {
+				p = join(path, name)
+			}
+			diff(cs, p, a.Field(i), b.Field(i), redact || red, seen)
+		}
+	case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String && !isLeaf(t):
+		keys := make(map[string]reflect.Value, a.Len()+b.Len())
+		for _, k := range a.MapKeys() {
+			keys[k.String()] = k
+		}
+		for _, k := range b.MapKeys() {
+			keys[k.String()] = k
+		}
+		names := make([]string, 0, len(keys))
+		for name := range keys {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			k := keys[name]
+			diff(cs, join(path, name), a.MapIndex(k), b.MapIndex(k), redact, seen)
+		}
+	case (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && !isLeaf(t) && hidesFields(t.Elem()):
+		for i := range max(a.Len(), b.Len()) {
+			var ea, eb reflect.Value
+			if i < a.Len() {
+				ea = a.Index(i)
+			}
+			if i < b.Len() {
+				eb = b.Index(i)
+			}
+			diff(cs, join(path, strconv.Itoa(i)), ea, eb, redact, seen)
+		}
+	default:
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			cs.add(path, a, b, redact)
+		}
+	}
+}
+
+func (cs *ChangeSet) add(path string, a, b reflect.Value, redact bool) {
+	for _, v := range [2]reflect.Value{a, b} {
+		if v.IsValid() && hidesFields(v.Type()) {
+			redact = true
+		}
+	}
+	c := Change{Path: path, Redacted: redact}
+	if !redact {
+		if a.IsValid() {
+			c.Old = a.Interface()
+		}
+		if b.IsValid() {
+			c.New = b.Interface()
+		}
+	}
+	*cs = append(*cs, c)
+}
+
+// visitOf returns the visit for a and b if they are maps, slices or
+// values reached through a pointer, the only values a cycle can lead
+// back to.
+func visitOf(a, b reflect.Value) (visit, bool) {
+	switch {
+	case a.Kind() == reflect.Map || a.Kind() == reflect.Slice:
+		return visit{a.Pointer(), b.Pointer(), a.Type()}, true
+	case a.CanAddr() && b.CanAddr():
+		return visit{a.UnsafeAddr(), b.UnsafeAddr(), a.Type()}, true
+	}
+	return visit{}, false
+}
+
+// indirect follows pointers and interfaces, returning the zero Value for
+// nil.
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// isLeaf reports whether values of t marshal themselves and should be
+// compared whole.
+func isLeaf(t reflect.Type) bool {
+	pt := reflect.PointerTo(t)
+	return t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) ||
+		pt.Implements(jsonMarshalerType) || pt.Implements(textMarshalerType)
+}
+
+// hiddenTypes caches hidesFields by type.
+var hiddenTypes sync.Map // reflect.Type -> bool
+
+// hidesFields reports whether values of t contain a struct field that is
+// redacted or skipped, so they must not be recorded whole.
+func hidesFields(t reflect.Type) bool {
+	if v, ok := hiddenTypes.Load(t); ok {
+		return v.(bool)
+	}
+	hidden := findHidden(t, map[reflect.Type]bool{})
+	hiddenTypes.Store(t, hidden)
+	return hidden
+}
+
+// findHidden implements hidesFields; seen breaks cycles in recursive
+// types.
+func findHidden(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] || isLeaf(t) {
+		return false
+	}
+	seen[t] = true
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		return findHidden(t.Elem(), seen)
+	case reflect.Map:
+		return findHidden(t.Key(), seen) || findHidden(t.Elem(), seen)
+	case reflect.Struct:
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if !f.IsExported() && !f.Anonymous {
+				continue
+			}
+			if _, skip, red := fieldInfo(f); (skip && f.IsExported()) || red || findHidden(f.Type, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fieldInfo returns the path element for f and whether it is skipped or
+// redacted.
+func fieldInfo(f reflect.StructField) (name string, skip, redact bool) {
+	if !f.IsExported() && !promotes(f) {
+		return "", true, false
+	}
+	switch f.Tag.Get("audit") {
+	case "-":
+		return "", true, false
+	case "redact":
+		redact = true
+	}
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return f.Name, false, true
+	}
+	name, _, _ = strings.Cut(tag, ",")
+	if name == "" {
+		name = f.Name
+	}
+	return name, false, redact
+}
+
+// promotes reports whether f is an embedded struct of an unexported type
+// whose exported fields are promoted, as encoding/json does. Embedded
+// pointers to such structs are skipped: reflect does not let their
+// fields be read.
+func promotes(f reflect.StructField) bool {
+	return f.Anonymous && f.Type.Kind() == reflect.Struct && !isLeaf(f.Type)
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
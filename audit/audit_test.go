@@ -0,0 +1,190 @@
+package audit_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.klarlabs.de/bolt"
+	"go.klarlabs.de/bolt/audit"
+)
+
+type dbConfig struct {
+	Host     string `json:"host"`
+	Password string `audit:"redact"`
+	Token    string `json:"-"`
+}
+
+type config struct {
+	Port     int               `json:"port"`
+	Hosts    []string          `json:"hosts,omitempty"`
+	DB       dbConfig          `json:"db"`
+	Limits   map[string]int    `json:"limits"`
+	Timeout  time.Duration     `json:"timeout"`
+	Deployed time.Time         `json:"deployed"`
+	Cache    map[string][]byte `audit:"-"`
+	Replica  *dbConfig         `json:"replica"`
+	internal int
+}
+
+func TestConfigChange(t *testing.T) {
+	deployed := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	old := config{
+		Port:     8080,
+		Hosts:    []string{"a"},
+		DB:       dbConfig{Host: "db1", Password: "p1", Token: "t1"},
+		Limits:   map[string]int{"rps": 100, "burst": 10},
+		Timeout:  time.Second,
+		Deployed: deployed,
+		Cache:    map[string][]byte{"x": {1}},
+		internal: 1,
+	}
+	updated := old
+	updated.Port = 9090
+	updated.Hosts = []string{"a", "b"}
+	updated.DB = dbConfig{Host: "db1", Password: "p2", Token: "t2"}
+	updated.Limits = map[string]int{"rps": 200, "conns": 5}
+	updated.Deployed = deployed.Add(time.Hour)
+	updated.Cache = nil
+	updated.Replica = &dbConfig{Host: "db2"}
+	updated.internal = 2
+
+	got := audit.ConfigChange(old, &updated)
+	want := audit.ChangeSet{
+		{Path: "port", Old: 8080, New: 9090},
+		{Path: "hosts", Old: []string{"a"}, New: []string{"a", "b"}},
+		{Path: "db.Password", Redacted: true},
+		{Path: "db.Token", Redacted: true},
+		{Path: "limits.burst", Old: 10},
+		{Path: "limits.conns", New: 5},
+		{Path: "limits.rps", Old: 100, New: 200},
+		{Path: "deployed", Old: deployed, New: deployed.Add(time.Hour)},
+		{Path: "replica", Redacted: true}, // dbConfig holds tagged fields
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ConfigChange:\n got %+v\nwant %+v", got, want)
+	}
+
+	if cs := audit.ConfigChange(old, old); len(cs) != 0 {
+		t.Errorf("equal configs produced changes: %+v", cs)
+	}
+}
+
+type backend struct {
+	Addr     string `json:"addr"`
+	Password string `json:"password" audit:"redact"`
+}
+
+func TestConfigChangeSliceRedaction(t *testing.T) {
+	type cfg struct {
+		Backends []backend       `json:"backends"`
+		ByPort   map[int]backend `json:"by_port"`
+		Pools    [1][]backend    `json:"pools"`
+	}
+	old := cfg{
+		Backends: []backend{{Addr: "a", Password: "s3cret-old"}},
+		ByPort:   map[int]backend{1: {Password: "s3cret-old"}},
+	}
+	updated := cfg{
+		Backends: []backend{{Addr: "b", Password: "s3cret-new"}, {Addr: "c", Password: "s3cret-new"}},
+		ByPort:   map[int]backend{1: {Password: "s3cret-new"}},
+		Pools:    [1][]backend{{{Password: "s3cret-new"}}},
+	}
+
+	got := audit.ConfigChange(old, updated)
+	want := audit.ChangeSet{
+		{Path: "backends.0.addr", Old: "a", New: "b"},
+		{Path: "backends.0.password", Redacted: true},
+		{Path: "backends.1", Redacted: true},
+		{Path: "by_port", Redacted: true},
+		{Path: "pools.0.0", Redacted: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ConfigChange:\n got %+v\nwant %+v", got, want)
+	}
+
+	var buf bytes.Buffer
+	audit.LogConfigChange(bolt.New(bolt.NewJSONHandler(&buf)), old, updated)
+	if strings.Contains(buf.String(), "s3cret") {
+		t.Errorf("password leaked: %s", buf.String())
+	}
+}
+
+type node struct {
+	Name string `json:"name"`
+	Next *node  `json:"next"`
+}
+
+func TestConfigChangeCycle(t *testing.T) {
+	old := &node{Name: "a"}
+	old.Next = &node{Name: "b", Next: old}
+	updated := &node{Name: "a"}
+	updated.Next = &node{Name: "c", Next: updated}
+
+	got := audit.ConfigChange(old, updated)
+	want := audit.ChangeSet{{Path: "next.name", Old: "b", New: "c"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ConfigChange:\n got %+v\nwant %+v", got, want)
+	}
+
+	// The same value reached twice without a cycle is compared each time.
+	shared := &node{Name: "x"}
+	type pair struct {
+		A *node `json:"a"`
+		B *node `json:"b"`
+	}
+	got = audit.ConfigChange(pair{shared, shared}, pair{&node{Name: "y"}, &node{Name: "y"}})
+	if want := []string{"a.name", "b.name"}; !reflect.DeepEqual(got.Paths(), want) {
+		t.Errorf("paths = %v, want %v", got.Paths(), want)
+	}
+}
+
+type limits struct {
+	RPS    int    `json:"rps"`
+	Secret string `audit:"redact"`
+}
+
+func TestConfigChangeUnexportedEmbedded(t *testing.T) {
+	type cfg struct {
+		limits
+		Port int `json:"port"`
+	}
+	old := cfg{limits{RPS: 10, Secret: "a"}, 80}
+	updated := cfg{limits{RPS: 20, Secret: "b"}, 80}
+
+	got := audit.ConfigChange(old, updated)
+	want := audit.ChangeSet{
+		{Path: "rps", Old: 10, New: 20},
+		{Path: "Secret", Redacted: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ConfigChange:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestLogConfigChange(t *testing.T) {
+	var buf bytes.Buffer
+	logger := bolt.New(bolt.NewJSONHandler(&buf))
+
+	old := config{Port: 1, DB: dbConfig{Password: "secret-1"}}
+	updated := config{Port: 2, DB: dbConfig{Password: "secret-2"}}
+	audit.LogConfigChange(logger, old, updated)
+
+	want := `{"level":"info","audit":"config_change","changed":2,` +
+		`"changes":{"port":{"old":1,"new":2},"db.Password":{"redacted":true}},` +
+		`"message":"configuration changed"}` + "\n"
+	if buf.String() != want {
+		t.Errorf("got  %s\nwant %s", buf.String(), want)
+	}
+	if strings.Contains(buf.String(), "secret") {
+		t.Error("redacted value logged")
+	}
+
+	buf.Reset()
+	audit.LogConfigChange(logger, old, old)
+	if buf.Len() != 0 {
+		t.Errorf("logged an empty change set: %s", buf.String())
+	}
+}
@@ -0,0 +1,139 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"go.klarlabs.de/bolt"
+)
+
+// ChainField is the key under which [ChainWriter] records the previous
+// record's hash.
+const ChainField = "prev_hash"
+
+// GenesisHash is the ChainField value of the first record of a chain.
+const GenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// maxChainRecord bounds a chained line: the longest record bolt writes
+// plus the ChainField ChainWriter adds to it.
+const maxChainRecord = bolt.MaxRecordSize + len(`,"`+ChainField+`":""`) + len(GenesisHash)
+
+// ErrChainBroken is returned by [Verify] when a record's ChainField does
+// not match the hash of the record before it.
+var ErrChainBroken = errors.New("audit: hash chain broken")
+
+// errNotRecord is returned for writes that are not one JSON object.
+var errNotRecord = errors.New("audit: chain write is not a JSON record")
+
+// ChainWriter links the records written through it into a SHA-256 hash
+// chain: each record gets a ChainField holding the hex SHA-256 of the
+// previous record as written, without its newline. Changing, inserting
+// or removing a record breaks the link to the one after it, which
+// [Verify] reports. Removing records from the end cannot be detected
+// from the file alone; store [ChainWriter.Head] elsewhere (a database, a
+// WORM bucket) at intervals to anchor the chain.
+//
+// ChainWriter relies on bolt handlers writing each record with exactly
+// one Write call, and expects JSON records; put it under a JSONHandler:
+//
+//	chain := audit.NewChainWriter(file, "")
+//	logger := bolt.New(bolt.NewJSONHandler(chain))
+type ChainWriter struct {
+	mu   sync.Mutex
+	w    io.Writer
+	head string
+	buf  []byte
+}
+
+// NewChainWriter returns a ChainWriter writing to w. head is the hash of
+// the last record already in w, as returned by [Verify], to continue an
+// existing chain; "" starts a new one at GenesisHash.
+func NewChainWriter(w io.Writer, head string) *ChainWriter {
+	if head == "" {
+		head = GenesisHash
+	}
+	return &ChainWriter{w: w, head: head}
+}
+
+// Write adds the ChainField to the record p and writes it. On error the
+// chain does not advance, so the next record links to the last one
+// written successfully.
+func (c *ChainWriter) Write(p []byte) (int, error) {
+	record := bytes.TrimSuffix(p, []byte("\n"))
+	if len(record) < 2 || record[0] != '{' || record[len(record)-1] != '}' {
+		return 0, errNotRecord
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	buf := append(c.buf[:0], record[:len(record)-1]...)
+	if len(record) > 2 {
+		buf = append(buf, ',')
+	}
+	buf = append(buf, `"`+ChainField+`":"`...)
+	buf = append(buf, c.head...)
+	buf = append(buf, '"', '}')
+	sum := sha256.Sum256(buf)
+	buf = append(buf, '\n')
+	c.buf = buf
+	if _, err := c.w.Write(buf); err != nil {
+		return 0, err
+	}
+	c.head = hex.EncodeToString(sum[:])
+	return len(p), nil
+}
+
+// Head returns the hash of the last record written, or the head passed
+// to NewChainWriter if there is none yet.
+func (c *ChainWriter) Head() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.head
+}
+
+// Verify reads NDJSON records written by a [ChainWriter] from r and
+// checks every link, starting from head ("" for GenesisHash). It returns
+// the hash of the last record, to pass to [NewChainWriter] when
+// appending, or an error wrapping [ErrChainBroken] naming the first
+// record, counted from 1, whose link does not match. Blank lines are
+// skipped.
+func Verify(r io.Reader, head string) (string, error) {
+	if head == "" {
+		head = GenesisHash
+	}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, maxChainRecord+1)
+	n := 0
+	for sc.Scan() {
+		line := bytes.TrimSuffix(sc.Bytes(), []byte("\r"))
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		n++
+		var rec struct {
+			Prev *string `json:"prev_hash"`
+		}
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return "", fmt.Errorf("%w: record %d: %v", ErrChainBroken, n, err)
+		}
+		if rec.Prev == nil {
+			return "", fmt.Errorf("%w: record %d: no %s", ErrChainBroken, n, ChainField)
+		}
+		if *rec.Prev != head {
+			return "", fmt.Errorf("%w: record %d: %s %s, want %s", ErrChainBroken, n, ChainField, *rec.Prev, head)
+		}
+		sum := sha256.Sum256(line)
+		head = hex.EncodeToString(sum[:])
+	}
+	if err := sc.Err(); err != nil {
+		return "", err
+	}
+	return head, nil
+}
@@ -0,0 +1,86 @@
+package audit_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"go.klarlabs.de/bolt"
+	"go.klarlabs.de/bolt/audit"
+)
+
+func TestChainWriter(t *testing.T) {
+	var file bytes.Buffer
+	chain := audit.NewChainWriter(&file, "")
+	logger := bolt.New(bolt.NewJSONHandler(chain))
+	for _, msg := range []string{"one", "two", "three"} {
+		logger.Info().Str("user", "ann").Msg(msg)
+	}
+
+	lines := strings.SplitAfter(file.String(), "\n")
+	if !strings.HasSuffix(lines[0], `"message":"one","prev_hash":"`+audit.GenesisHash+`"}`+"\n") {
+		t.Errorf("first record = %s", lines[0])
+	}
+	head, err := audit.Verify(strings.NewReader(file.String()), "")
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if head != chain.Head() {
+		t.Errorf("Verify head = %s, writer head = %s", head, chain.Head())
+	}
+
+	// Appending after a restart continues the chain.
+	resumed := audit.NewChainWriter(&file, head)
+	bolt.New(bolt.NewJSONHandler(resumed)).Info().Msg("four")
+	if _, err := audit.Verify(strings.NewReader(file.String()), ""); err != nil {
+		t.Errorf("Verify after resume: %v", err)
+	}
+
+	for name, tampered := range map[string]string{
+		"edited":  strings.Replace(file.String(), `"message":"two"`, `"message":"2"`, 1),
+		"removed": lines[0] + lines[2],
+		"swapped": lines[1] + lines[0] + lines[2],
+	} {
+		_, err := audit.Verify(strings.NewReader(tampered), "")
+		if !errors.Is(err, audit.ErrChainBroken) {
+			t.Errorf("%s: err = %v, want ErrChainBroken", name, err)
+		}
+	}
+}
+
+func TestVerifyLargestRecord(t *testing.T) {
+	var file bytes.Buffer
+	chain := audit.NewChainWriter(&file, "")
+	var logErr error
+	logger := bolt.New(bolt.NewJSONHandler(chain)).SetErrorHandler(func(err error) { logErr = err })
+	e := logger.Info()
+	value := strings.Repeat("y", bolt.MaxValueLength)
+	for i := 0; i < bolt.MaxBufferSize/bolt.MaxValueLength-1; i++ {
+		e = e.Str(fmt.Sprint("k", i), value)
+	}
+	e.Msg(strings.Repeat("\x01", bolt.MaxValueLength)) // escaped as \u0001
+	if logErr != nil {
+		t.Fatal(logErr)
+	}
+
+	head, err := audit.Verify(bytes.NewReader(file.Bytes()), "")
+	if err != nil {
+		t.Fatalf("Verify of a %d-byte record: %v", file.Len(), err)
+	}
+	if head != chain.Head() {
+		t.Errorf("Verify head = %s, writer head = %s", head, chain.Head())
+	}
+}
+
+func TestChainWriterRejectsNonRecords(t *testing.T) {
+	var file bytes.Buffer
+	chain := audit.NewChainWriter(&file, "")
+	if n, err := chain.Write([]byte("plain text\n")); err == nil || n != 0 {
+		t.Errorf("Write = %d, %v; want an error", n, err)
+	}
+	if chain.Head() != audit.GenesisHash || file.Len() != 0 {
+		t.Error("rejected write advanced the chain")
+	}
+}
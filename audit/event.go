@@ -0,0 +1,165 @@
+package audit
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.klarlabs.de/bolt"
+)
+
+// Compliance field keys written by [Event].
+const (
+	FieldActor    = "actor"
+	FieldAction   = "action"
+	FieldResource = "resource"
+	FieldOutcome  = "outcome"
+)
+
+// Common outcomes for [Event.Outcome].
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	OutcomeDenied  = "denied"
+)
+
+// DefaultRequired are the fields every audit event must carry unless
+// [Options.Required] says otherwise: who did what to which resource, and
+// with what result.
+var DefaultRequired = []string{FieldActor, FieldAction, FieldResource, FieldOutcome}
+
+// ErrMissingFields is returned by [Event.Log] when required fields are
+// missing or empty. Nothing is logged.
+var ErrMissingFields = errors.New("audit: missing required fields")
+
+// Options configures [New]. A nil *Options uses the defaults.
+type Options struct {
+	// Required lists the fields [Event.Log] insists on. nil uses
+	// DefaultRequired; an empty non-nil slice requires nothing.
+	Required []string
+}
+
+// Logger writes audit events to a bolt logger, which must admit INFO.
+// Give audit events a logger of their own, writing through a
+// [ChainWriter] to make the trail tamper-evident:
+//
+//	chain := audit.NewChainWriter(file, "")
+//	al := audit.New(bolt.New(bolt.NewJSONHandler(chain)), nil)
+//
+//	err := al.Event("access").
+//	    Actor(user.ID).Action("read").Resource("patient/42").
+//	    Outcome(audit.OutcomeSuccess).
+//	    Compliance("HIPAA").
+//	    Log("record viewed")
+type Logger struct {
+	logger   *bolt.Logger
+	required []string
+}
+
+// New returns a Logger writing to logger. If opts is nil, defaults are
+// used.
+func New(logger *bolt.Logger, opts *Options) *Logger {
+	required := DefaultRequired
+	if opts != nil && opts.Required != nil {
+		required = opts.Required
+	}
+	return &Logger{logger: logger, required: required}
+}
+
+// Event starts an audit event of the given type, such as "access",
+// "auth" or "data_change", logged under the "audit" key.
+func (l *Logger) Event(typ string) *Event {
+	return &Event{l: l, typ: typ}
+}
+
+// Event builds one audit event. Set its fields and finish with
+// [Event.Log]. An Event is not safe for concurrent use and must not be
+// reused after Log.
+type Event struct {
+	l      *Logger
+	typ    string
+	fields []field
+	tags   []string
+}
+
+type field struct {
+	key, value string
+}
+
+// Actor sets who performed the action, typically a user or service ID.
+func (e *Event) Actor(id string) *Event { return e.Str(FieldActor, id) }
+
+// Action sets what was done, such as "read" or "delete".
+func (e *Event) Action(action string) *Event { return e.Str(FieldAction, action) }
+
+// Resource sets what the action was performed on.
+func (e *Event) Resource(resource string) *Event { return e.Str(FieldResource, resource) }
+
+// Outcome sets the result, usually one of the Outcome constants.
+func (e *Event) Outcome(outcome string) *Event { return e.Str(FieldOutcome, outcome) }
+
+// Reason sets why the action was taken or refused.
+func (e *Event) Reason(reason string) *Event { return e.Str("reason", reason) }
+
+// Session sets the session the action was performed in.
+func (e *Event) Session(id string) *Event { return e.Str("session_id", id) }
+
+// SourceIP sets the address the request came from.
+func (e *Event) SourceIP(ip string) *Event { return e.Str("source_ip", ip) }
+
+// Compliance adds the frameworks the event is relevant to, such as
+// "SOX" or "HIPAA", logged as the "compliance" array.
+func (e *Event) Compliance(frameworks ...string) *Event {
+	e.tags = append(e.tags, frameworks...)
+	return e
+}
+
+// Str sets a field, replacing an earlier value for key.
+func (e *Event) Str(key, value string) *Event {
+	for i := range e.fields {
+		if e.fields[i].key == key {
+			e.fields[i].value = value
+			return e
+		}
+	}
+	e.fields = append(e.fields, field{key, value})
+	return e
+}
+
+// Log validates the event and logs it at INFO with a fresh "event_id"
+// and a "timestamp". If required fields are missing or empty it logs
+// nothing and returns an error wrapping [ErrMissingFields] that names
+// them.
+func (e *Event) Log(message string) error {
+	var missing []string
+	for _, k := range e.l.required {
+		if e.get(k) == "" {
+			missing = append(missing, k)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrMissingFields, strings.Join(missing, ", "))
+	}
+
+	ev := e.l.logger.Info().
+		Str("audit", e.typ).
+		EventID("event_id", bolt.NewEventID()).
+		Timestamp()
+	for _, f := range e.fields {
+		ev.Str(f.key, f.value)
+	}
+	if len(e.tags) > 0 {
+		ev.Strs("compliance", e.tags)
+	}
+	ev.Msg(message)
+	return nil
+}
+
+func (e *Event) get(key string) string {
+	for _, f := range e.fields {
+		if f.key == key {
+			return f.value
+		}
+	}
+	return ""
+}
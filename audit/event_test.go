@@ -0,0 +1,71 @@
+package audit_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.klarlabs.de/bolt"
+	"go.klarlabs.de/bolt/audit"
+)
+
+func TestEventLog(t *testing.T) {
+	var buf bytes.Buffer
+	al := audit.New(bolt.New(bolt.NewJSONHandler(&buf)), nil)
+
+	err := al.Event("access").
+		Actor("u-1").Action("read").Resource("patient/42").
+		Outcome(audit.OutcomeDenied).Reason("no consent").
+		Session("s-9").SourceIP("10.0.0.1").
+		Str("ticket", "T-1").Str("ticket", "T-2").
+		Compliance("HIPAA", "SOX").
+		Log("record access denied")
+	if err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+
+	var rec map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	want := map[string]any{
+		"level": "info", "audit": "access", "actor": "u-1", "action": "read",
+		"resource": "patient/42", "outcome": "denied", "reason": "no consent",
+		"session_id": "s-9", "source_ip": "10.0.0.1", "ticket": "T-2",
+		"message": "record access denied",
+	}
+	for k, v := range want {
+		if rec[k] != v {
+			t.Errorf("%s = %v, want %v", k, rec[k], v)
+		}
+	}
+	if id, _ := rec["event_id"].(string); len(id) != 26 {
+		t.Errorf("event_id = %v, want a ULID", rec["event_id"])
+	}
+	if _, ok := rec["timestamp"]; !ok {
+		t.Error("no timestamp")
+	}
+	if tags, _ := rec["compliance"].([]any); len(tags) != 2 || tags[0] != "HIPAA" {
+		t.Errorf("compliance = %v", rec["compliance"])
+	}
+}
+
+func TestEventLogMissingFields(t *testing.T) {
+	var buf bytes.Buffer
+	al := audit.New(bolt.New(bolt.NewJSONHandler(&buf)), nil)
+
+	err := al.Event("auth").Actor("u-1").Outcome("").Log("login")
+	if !errors.Is(err, audit.ErrMissingFields) || !strings.HasSuffix(err.Error(), "action, resource, outcome") {
+		t.Errorf("err = %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("invalid event was logged: %s", buf.String())
+	}
+
+	custom := audit.New(bolt.New(bolt.NewJSONHandler(&buf)), &audit.Options{Required: []string{}})
+	if err := custom.Event("auth").Log("login"); err != nil || buf.Len() == 0 {
+		t.Errorf("no required fields: err = %v, output = %q", err, buf.String())
+	}
+}
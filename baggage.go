@@ -0,0 +1,52 @@
+package bolt
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/baggage"
+)
+
+// DebugBaggageKey is the OpenTelemetry baggage member that elevates
+// logging for one request. When a context passed to [Logger.Ctx] carries
+// it, the returned logger logs at DEBUG (or TRACE for the value "trace")
+// even if the base logger is at INFO, and sampling hooks keep its events.
+//
+// Because baggage travels with the trace context through every
+// OTel-instrumented hop, setting it once at the edge turns on verbose
+// logging for that request in every service:
+//
+//	m, _ := baggage.NewMember(bolt.DebugBaggageKey, "1")
+//	b, _ := baggage.New(m)
+//	ctx = baggage.ContextWithBaggage(ctx, b)
+//
+// Accepted values are "1", "true" and "debug" for DEBUG, and "trace" for
+// TRACE; anything else is ignored. The override only ever lowers the
+// level; a logger already at or below it keeps its level, but sampling
+// hooks still keep its events. Anyone who can set request baggage can raise log volume, so
+// strip the member at trust boundaries if that is a concern.
+const DebugBaggageKey = "bolt.debug"
+
+// baggageLevel returns the level requested through DebugBaggageKey.
+func baggageLevel(ctx context.Context) (Level, bool) {
+	v := baggage.FromContext(ctx).Member(DebugBaggageKey).Value()
+	switch strings.ToLower(v) {
+	case "1", "true", debugStr:
+		return DEBUG, true
+	case traceStr:
+		return TRACE, true
+	}
+	return 0, false
+}
+
+// elevate returns a copy of l marked so that sampling hooks keep its
+// events, at level or at l's own level if that is already lower.
+func (l *Logger) elevate(level Level) *Logger {
+	c := l.clone()
+	c.elevated = true
+	if level < l.GetLevel() {
+		atomic.StoreInt64(&c.level, int64(level))
+	}
+	return c
+}
@@ -0,0 +1,131 @@
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/baggage"
+)
+
+func withDebugBaggage(t *testing.T, value string) context.Context {
+	t.Helper()
+	m, err := baggage.NewMember(DebugBaggageKey, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := baggage.New(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return baggage.ContextWithBaggage(context.Background(), b)
+}
+
+func TestCtxDebugBaggageElevatesLevel(t *testing.T) {
+	var buf bytes.Buffer
+	base := New(NewJSONHandler(&buf)).SetLevel(INFO)
+
+	base.Ctx(context.Background()).Debug().Msg("plain")
+	base.Ctx(withDebugBaggage(t, "0")).Debug().Msg("ignored value")
+	base.Ctx(withDebugBaggage(t, "1")).Debug().Msg("elevated")
+	base.Ctx(withDebugBaggage(t, "1")).Trace().Msg("not trace")
+	base.Ctx(withDebugBaggage(t, "trace")).Trace().Msg("trace")
+	base.Debug().Msg("base untouched")
+
+	out := buf.String()
+	for _, s := range []string{"plain", "ignored value", "not trace", "base untouched"} {
+		if strings.Contains(out, s) {
+			t.Errorf("%q logged: %s", s, out)
+		}
+	}
+	for _, s := range []string{"elevated", `"message":"trace"`} {
+		if !strings.Contains(out, s) {
+			t.Errorf("%q missing: %s", s, out)
+		}
+	}
+}
+
+func TestCtxDebugBaggageNeverRaisesLevel(t *testing.T) {
+	var buf bytes.Buffer
+	base := New(NewJSONHandler(&buf)).SetLevel(TRACE)
+	l := base.Ctx(withDebugBaggage(t, "debug"))
+	l.Trace().Msg("still trace")
+	if !strings.Contains(buf.String(), "still trace") {
+		t.Errorf("baggage raised the level: %s", buf.String())
+	}
+}
+
+func TestCtxDebugBaggageBypassesSampling(t *testing.T) {
+	var buf bytes.Buffer
+	base := New(NewJSONHandler(&buf)).SetLevel(INFO).
+		AddHook(NewSampleHook(1000)).
+		AddEventHook(NewHashSampler("user_id", 0))
+
+	base.Info().Int("user_id", 1).Msg("sampled out")
+	elevated := base.Ctx(withDebugBaggage(t, "true")).With().Int("user_id", 1).Logger()
+	for i := 0; i < 3; i++ {
+		elevated.Info().Msg("kept")
+	}
+	if got := strings.Count(buf.String(), "kept"); got != 3 || strings.Contains(buf.String(), "sampled out") {
+		t.Errorf("got %d elevated events: %s", got, buf.String())
+	}
+}
+
+func TestCtxDebugBaggageBypassesSamplingAtDebug(t *testing.T) {
+	var buf bytes.Buffer
+	base := New(NewJSONHandler(&buf)).SetLevel(DEBUG).
+		AddHook(NewSampleHook(1000)).
+		AddEventHook(NewHashSampler("user_id", 0))
+
+	elevated := base.Ctx(withDebugBaggage(t, "1")).With().Int("user_id", 1).Logger()
+	if elevated.GetLevel() != DEBUG {
+		t.Errorf("level = %v, want DEBUG", elevated.GetLevel())
+	}
+	for i := 0; i < 3; i++ {
+		elevated.Debug().Msg("kept")
+	}
+	elevated.Trace().Msg("not trace")
+	if got := strings.Count(buf.String(), "kept"); got != 3 || strings.Contains(buf.String(), "not trace") {
+		t.Errorf("got %d elevated events: %s", got, buf.String())
+	}
+}
+
+func TestLoggerCloneCopiesEveryField(t *testing.T) {
+	l := New(NewJSONHandler(&bytes.Buffer{}))
+	l.context = []byte(`"k":"v"`)
+	l.hooks = []Hook{NewSampleHook(2)}
+	l.eventHooks = []EventHook{NewIdempotencyKeyHook()}
+	l.keyReplacer = MongoKeyReplacer
+	l.stackOpts = &StackOptions{}
+	l.callerOpts = &CallerOptions{}
+	l.extractors = []ContextExtractor{func(context.Context) (string, any, bool) { return "", nil, false }}
+	l.elevated = true
+	l.trackFields = true
+	l.ctxFields = []int32{0}
+	l.devWhere = "main.go:1"
+
+	// Fields that clone handles specially; every other field must be set
+	// above so that forgetting to copy it fails the comparison below.
+	special := map[string]bool{"level": true, "devPending": true}
+	c := l.clone()
+	src, dst := reflect.ValueOf(l).Elem(), reflect.ValueOf(c).Elem()
+	for i := range src.NumField() {
+		name := src.Type().Field(i).Name
+		if special[name] {
+			continue
+		}
+		if src.Field(i).IsZero() {
+			t.Errorf("field %s not set in this test", name)
+			continue
+		}
+		if fmt.Sprint(src.Field(i)) != fmt.Sprint(dst.Field(i)) {
+			t.Errorf("clone does not copy %s", name)
+		}
+	}
+	if c.GetLevel() != l.GetLevel() {
+		t.Errorf("clone level = %v, want %v", c.GetLevel(), l.GetLevel())
+	}
+}
@@ -0,0 +1,301 @@
+package bolt
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultBatchBytes is the batch size limit of a [BatchHandler] when
+	// none is configured.
+	DefaultBatchBytes = 1 << 20 // 1 MiB
+	// DefaultBatchDelay is how long a [BatchHandler] holds records when no
+	// delay is configured.
+	DefaultBatchDelay = time.Second
+	// DefaultBatchQueue is the number of full batches a [BatchHandler]
+	// lets wait for the wrapped handler when none is configured.
+	DefaultBatchQueue = 4
+)
+
+// ErrBatchFull is returned by [BatchHandler.Write] when an event is
+// dropped because the batch queue is full.
+var ErrBatchFull = errors.New("bolt: batch queue full, event dropped")
+
+// BatchOptions configures [NewBatchHandler]. A nil *BatchOptions uses the
+// defaults.
+type BatchOptions struct {
+	// MaxBytes closes a batch before it would exceed this many bytes.
+	// A single record larger than MaxBytes forms a batch of its own.
+	// Defaults to DefaultBatchBytes.
+	MaxBytes int
+
+	// MaxDelay bounds how long a record may wait for its batch to fill.
+	// Defaults to DefaultBatchDelay.
+	MaxDelay time.Duration
+
+	// Queue is the number of full batches that may wait while the
+	// wrapped handler is busy. Defaults to DefaultBatchQueue.
+	Queue int
+
+	// Block makes Write wait for the queue to drain when it is full,
+	// slowing callers to the sink's pace, instead of dropping the event
+	// with ErrBatchFull. An event with a context, see [Event.Ctx], waits
+	// only until the context is done.
+	Block bool
+
+	// ErrorHandler, if set, receives errors returned by the wrapped
+	// handler. Batches are written on a background goroutine, so the
+	// errors cannot be returned to the logger.
+	ErrorHandler ErrorHandler
+}
+
+// batch is a run of finished records written to the wrapped handler in
+// one call, with the highest level among them.
+type batch struct {
+	level Level
+	buf   []byte
+}
+
+// BatchHandler collects records into batches of up to MaxBytes and
+// passes each batch to the wrapped handler as one event whose buffer
+// holds the records back to back, newline-terminated NDJSON. A batch is
+// written when it is full, when its oldest record has waited about
+// MaxDelay, and on Flush and Close. Writes to the wrapped handler happen
+// on a background goroutine, one batch at a time, so a slow network
+// sink does not stall logging until Queue full batches are waiting; then
+// Write blocks or drops according to [BatchOptions.Block].
+//
+// Wrap a handler that writes the event buffer verbatim, such as a
+// JSONHandler over an HTTP or Kafka writer, so each batch becomes one
+// request or message:
+//
+//	bh := bolt.NewBatchHandler(bolt.NewJSONHandler(lokiWriter), &bolt.BatchOptions{
+//	    MaxBytes: 512 << 10,
+//	    MaxDelay: 2 * time.Second,
+//	})
+//	defer bh.Close()
+//	logger := bolt.New(bh)
+//
+// The wrapped handler sees the batch's highest level through
+// [Event.Level]. Parsing handlers such as ConsoleHandler only see the
+// first record and are not useful behind a BatchHandler. FATAL events
+// flush all batches before Write returns, so nothing is lost when the
+// process exits.
+type BatchHandler struct {
+	next     Handler
+	maxBytes int
+	block    bool
+	onError  ErrorHandler
+
+	mu     sync.Mutex // guards cur and closed; held while queueing
+	cur    batch
+	closed bool
+
+	full    chan batch
+	flushc  chan chan struct{}
+	quit    chan struct{}
+	exited  chan struct{}
+	dropped atomic.Uint64
+	ev      Event // passes batches to next; used by the run goroutine only
+	free    sync.Pool
+}
+
+// NewBatchHandler starts a BatchHandler writing to next. If opts is nil,
+// defaults are used. Call Close before exiting to write pending records.
+func NewBatchHandler(next Handler, opts *BatchOptions) *BatchHandler {
+	if opts == nil {
+		opts = &BatchOptions{}
+	}
+	h := &BatchHandler{
+		next:     next,
+		maxBytes: opts.MaxBytes,
+		block:    opts.Block,
+		onError:  opts.ErrorHandler,
+		flushc:   make(chan chan struct{}),
+		quit:     make(chan struct{}),
+		exited:   make(chan struct{}),
+	}
+	if h.maxBytes <= 0 {
+		h.maxBytes = DefaultBatchBytes
+	}
+	queue := opts.Queue
+	if queue <= 0 {
+		queue = DefaultBatchQueue
+	}
+	delay := opts.MaxDelay
+	if delay <= 0 {
+		delay = DefaultBatchDelay
+	}
+	h.full = make(chan batch, queue)
+	go h.run(delay)
+	return h
+}
+
+// Write implements [Handler]. It adds a copy of the event to the current
+// batch and returns ErrBatchFull if the event was dropped, or
+// ErrMemoryBudget if holding it would exceed the [SetMemoryBudget]
+// budget. FATAL events are never dropped for the budget.
+func (h *BatchHandler) Write(e *Event) error {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return ErrHandlerClosed
+	}
+	if len(h.cur.buf) > 0 && len(h.cur.buf)+len(e.buf) > h.maxBytes && !h.queue(e) {
+		h.mu.Unlock()
+		h.dropped.Add(1)
+		if h.block {
+			return e.ctxErr(ErrBatchFull)
+		}
+		return ErrBatchFull
+	}
+	if e.level == FATAL {
+		memCharge(len(e.buf))
+	} else if !memReserve(len(e.buf)) {
+		h.mu.Unlock()
+		h.dropped.Add(1)
+		return ErrMemoryBudget
+	}
+	if h.cur.buf == nil {
+		h.cur.buf = h.getBuf()
+		h.cur.level = e.level
+	}
+	h.cur.buf = append(h.cur.buf, e.buf...)
+	h.cur.level = max(h.cur.level, e.level)
+	h.mu.Unlock()
+
+	if e.level == FATAL {
+		h.Flush()
+	}
+	return nil
+}
+
+// queue moves the current batch to the queue to make room for e,
+// reporting false if the queue is full and the handler does not block,
+// or blocks and e's context ended first. Called with h.mu held.
+func (h *BatchHandler) queue(e *Event) bool {
+	select {
+	case h.full <- h.cur:
+	default:
+		if !h.block {
+			return false
+		}
+		select {
+		case h.full <- h.cur:
+		case <-e.done():
+			return false
+		}
+	}
+	h.cur = batch{}
+	return true
+}
+
+// take removes and returns the current batch.
+func (h *BatchHandler) take() batch {
+	h.mu.Lock()
+	b := h.cur
+	h.cur = batch{}
+	h.mu.Unlock()
+	return b
+}
+
+// Dropped returns the number of events dropped because the queue was
+// full.
+func (h *BatchHandler) Dropped() uint64 {
+	return h.dropped.Load()
+}
+
+// Queued returns the number of full batches waiting for the wrapped
+// handler.
+func (h *BatchHandler) Queued() int {
+	return len(h.full)
+}
+
+// Flush blocks until every record written before the call has been
+// passed to the wrapped handler. It returns immediately after Close.
+func (h *BatchHandler) Flush() {
+	ack := make(chan struct{})
+	select {
+	case h.flushc <- ack:
+		<-ack
+	case <-h.exited:
+	}
+}
+
+// Close stops accepting events, writes every pending batch and stops the
+// background goroutine. Writes after Close return ErrHandlerClosed. It
+// is safe to call more than once.
+func (h *BatchHandler) Close() error {
+	h.mu.Lock()
+	if !h.closed {
+		h.closed = true
+		close(h.quit)
+	}
+	h.mu.Unlock()
+	<-h.exited
+	return nil
+}
+
+func (h *BatchHandler) run(delay time.Duration) {
+	defer close(h.exited)
+	t := time.NewTicker(delay)
+	defer t.Stop()
+	for {
+		select {
+		case b := <-h.full:
+			h.write(b)
+		case <-t.C:
+			h.write(h.take())
+		case ack := <-h.flushc:
+			h.drain()
+			close(ack)
+		case <-h.quit:
+			h.drain()
+			return
+		}
+	}
+}
+
+// drain writes the queued batches and then the current one.
+func (h *BatchHandler) drain() {
+	for {
+		select {
+		case b := <-h.full:
+			h.write(b)
+		default:
+			h.write(h.take())
+			return
+		}
+	}
+}
+
+func (h *BatchHandler) write(b batch) {
+	if len(b.buf) == 0 {
+		return
+	}
+	h.ev.level = b.level
+	h.ev.buf = b.buf
+	err := h.next.Write(&h.ev)
+	h.ev.buf = nil
+	memRelease(len(b.buf))
+	h.putBuf(b.buf)
+	if err != nil && h.onError != nil {
+		h.onError(err)
+	}
+}
+
+func (h *BatchHandler) getBuf() []byte {
+	if b, ok := h.free.Get().(*[]byte); ok {
+		return (*b)[:0]
+	}
+	return make([]byte, 0, min(h.maxBytes, DefaultBufferedWriterSize))
+}
+
+func (h *BatchHandler) putBuf(b []byte) {
+	if cap(b) > 2*h.maxBytes {
+		return // a single oversized record; don't keep it around
+	}
+	h.free.Put(&b)
+}
@@ -0,0 +1,164 @@
+package bolt
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// batchRecorder keeps every batch it is handed, with its level.
+type batchRecorder struct {
+	mu      sync.Mutex
+	batches []string
+	levels  []Level
+}
+
+func (r *batchRecorder) Write(e *Event) error {
+	r.mu.Lock()
+	r.batches = append(r.batches, string(e.buf))
+	r.levels = append(r.levels, e.Level())
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *batchRecorder) snapshot() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.batches...)
+}
+
+func TestBatchHandlerSizeTrigger(t *testing.T) {
+	rec := &batchRecorder{}
+	h := NewBatchHandler(rec, &BatchOptions{MaxBytes: 200, MaxDelay: time.Hour})
+	logger := New(h)
+
+	for range 10 {
+		logger.Info().Str("k", "0123456789").Msg("batched")
+	}
+	h.Flush()
+
+	got := rec.snapshot()
+	if len(got) < 2 {
+		t.Fatalf("got %d batches, want the records split by size", len(got))
+	}
+	records := 0
+	for _, b := range got {
+		if len(b) > 200 {
+			t.Errorf("batch of %d bytes exceeds MaxBytes", len(b))
+		}
+		if !strings.HasSuffix(b, "\n") {
+			t.Errorf("batch %q is not newline-terminated", b)
+		}
+		records += strings.Count(b, "\n")
+	}
+	if records != 10 {
+		t.Errorf("got %d records, want 10", records)
+	}
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBatchHandlerTimeTrigger(t *testing.T) {
+	rec := &batchRecorder{}
+	h := NewBatchHandler(rec, &BatchOptions{MaxDelay: 10 * time.Millisecond})
+	defer h.Close()
+	New(h).Info().Msg("one")
+	New(h).Warn().Msg("two")
+
+	deadline := time.Now().Add(2 * time.Second)
+	for strings.Count(strings.Join(rec.snapshot(), ""), "\n") < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("records not written after MaxDelay")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if last := rec.levels[len(rec.levels)-1]; last != WARN {
+		t.Errorf("batch level = %v, want the highest level WARN", last)
+	}
+}
+
+func TestBatchHandlerDropsWhenQueueFull(t *testing.T) {
+	g := newGatedHandler()
+	var errs []error
+	h := NewBatchHandler(g, &BatchOptions{MaxBytes: 1, MaxDelay: time.Hour, Queue: 1})
+	logger := New(h).SetErrorHandler(func(err error) { errs = append(errs, err) })
+
+	logger.Info().Msg("first")  // becomes the current batch
+	logger.Info().Msg("second") // queues "first", which the run loop takes
+	<-g.started
+	logger.Info().Msg("third")  // queues "second"
+	logger.Info().Msg("fourth") // queue full: dropped
+	if h.Dropped() != 1 {
+		t.Errorf("Dropped() = %d, want 1", h.Dropped())
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], ErrBatchFull) {
+		t.Errorf("errors = %v, want one ErrBatchFull", errs)
+	}
+
+	close(g.gate)
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(g.messages(), ","); got != "first,second,third" {
+		t.Errorf("written %s, want first,second,third", got)
+	}
+}
+
+func TestBatchHandlerBlocks(t *testing.T) {
+	g := newGatedHandler()
+	h := NewBatchHandler(g, &BatchOptions{MaxBytes: 1, MaxDelay: time.Hour, Queue: 1, Block: true})
+	logger := New(h)
+
+	logger.Info().Msg("first")
+	logger.Info().Msg("second")
+	<-g.started
+	logger.Info().Msg("third")
+	done := make(chan struct{})
+	go func() {
+		logger.Info().Msg("fourth") // waits for the queue
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("Write returned while the queue was full")
+	case <-time.After(10 * time.Millisecond):
+	}
+	close(g.gate)
+	<-done
+	h.Close()
+	if got := strings.Join(g.messages(), ","); got != "first,second,third,fourth" {
+		t.Errorf("written %s, want all four", got)
+	}
+	if h.Dropped() != 0 {
+		t.Errorf("Dropped() = %d, want 0", h.Dropped())
+	}
+}
+
+func TestBatchHandlerClose(t *testing.T) {
+	rec := &batchRecorder{}
+	h := NewBatchHandler(rec, &BatchOptions{MaxDelay: time.Hour})
+	var errs []error
+	logger := New(h).SetErrorHandler(func(err error) { errs = append(errs, err) })
+
+	logger.Info().Msg("pending")
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := rec.snapshot(); len(got) != 1 || !strings.Contains(got[0], `"message":"pending"`) {
+		t.Errorf("Close wrote %q, want the pending record", got)
+	}
+
+	logger.Info().Msg("late")
+	if len(errs) != 1 || !errors.Is(errs[0], ErrHandlerClosed) {
+		t.Errorf("errors = %v, want ErrHandlerClosed", errs)
+	}
+	if err := h.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+	h.Flush() // must not block after Close
+}
@@ -0,0 +1,114 @@
+// Package benchkit is the harness behind bolt's competitive benchmarks and
+// the analysis behind bolt-benchmark, exported so that forks, wrappers and
+// adapters can be measured with the same workloads and compared with the
+// same numbers instead of copying the benchmark suite.
+//
+// A logging library joins the harness by implementing [Adapter], which
+// prepares each [Scenario] once and returns the call to time:
+//
+//	type myAdapter struct{}
+//
+//	func (myAdapter) Name() string { return "mylog" }
+//
+//	func (myAdapter) Prepare(s benchkit.Scenario, w io.Writer) func() {
+//	    l := mylog.New(w)
+//	    switch s {
+//	    case benchkit.Standard:
+//	        return func() { l.Info("hello world", "foo", "bar", "baz", 123) }
+//	    }
+//	    return nil // scenario not supported
+//	}
+//
+//	func BenchmarkLoggers(b *testing.B) {
+//	    benchkit.Run(b, benchkit.Bolt{}, myAdapter{})
+//	}
+//
+// The output of "go test -bench" is read back with [ParseResults], and two
+// runs are compared with [Compare].
+//
+// The exported API follows the module's compatibility promise: scenarios
+// may be added, but existing ones keep their names and workloads so that
+// results stay comparable across releases.
+package benchkit
+
+import (
+	"io"
+	"testing"
+
+	"go.klarlabs.de/bolt"
+)
+
+// Scenario names a workload every adapter is measured on.
+type Scenario string
+
+const (
+	// Standard logs "hello world" at info level with the string field
+	// foo="bar" and the integer field baz=123.
+	Standard Scenario = "Standard"
+	// FiveFields logs "hello world" at info level with the string fields
+	// f1="v1" through f5="v5".
+	FiveFields Scenario = "FiveFields"
+	// Disabled makes the Standard call on a logger whose level filters
+	// out info, measuring the cost of a suppressed event.
+	Disabled Scenario = "Disabled"
+)
+
+// Scenarios lists the scenarios [Run] measures, in order.
+var Scenarios = []Scenario{Standard, FiveFields, Disabled}
+
+// Adapter plugs a logging library or wrapper into the harness.
+type Adapter interface {
+	// Name identifies the adapter in benchmark names. It must not contain
+	// spaces or slashes.
+	Name() string
+	// Prepare builds a logger writing JSON to w and returns a function
+	// that logs the scenario's event once. Setup belongs in Prepare, which
+	// is not timed. A nil function marks the scenario as unsupported.
+	Prepare(s Scenario, w io.Writer) func()
+}
+
+// Run measures every adapter on every scenario as sub-benchmarks named
+// "<adapter>/<scenario>", reporting allocations. Output goes to
+// io.Discard, so the numbers exclude I/O.
+func Run(b *testing.B, adapters ...Adapter) {
+	for _, a := range adapters {
+		b.Run(a.Name(), func(b *testing.B) {
+			for _, s := range Scenarios {
+				fn := a.Prepare(s, io.Discard)
+				if fn == nil {
+					continue
+				}
+				b.Run(string(s), func(b *testing.B) {
+					b.ReportAllocs()
+					b.ResetTimer()
+					for i := 0; i < b.N; i++ {
+						fn()
+					}
+				})
+			}
+		})
+	}
+}
+
+// Bolt is the [Adapter] for bolt itself, using a [bolt.JSONHandler].
+type Bolt struct{}
+
+// Name returns "bolt".
+func (Bolt) Name() string { return "bolt" }
+
+// Prepare implements [Adapter].
+func (Bolt) Prepare(s Scenario, w io.Writer) func() {
+	logger := bolt.New(bolt.NewJSONHandler(w))
+	switch s {
+	case Standard:
+		return func() { logger.Info().Str("foo", "bar").Int("baz", 123).Msg("hello world") }
+	case FiveFields:
+		return func() {
+			logger.Info().Str("f1", "v1").Str("f2", "v2").Str("f3", "v3").Str("f4", "v4").Str("f5", "v5").Msg("hello world")
+		}
+	case Disabled:
+		logger.SetLevel(bolt.FATAL)
+		return func() { logger.Info().Str("foo", "bar").Int("baz", 123).Msg("hello world") }
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package benchkit
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestBoltAdapter(t *testing.T) {
+	want := map[Scenario]string{
+		Standard:   `{"level":"info","foo":"bar","baz":123,"message":"hello world"}` + "\n",
+		FiveFields: `{"level":"info","f1":"v1","f2":"v2","f3":"v3","f4":"v4","f5":"v5","message":"hello world"}` + "\n",
+		Disabled:   "",
+	}
+	for _, s := range Scenarios {
+		var buf bytes.Buffer
+		fn := Bolt{}.Prepare(s, &buf)
+		if fn == nil {
+			t.Fatalf("%s: not supported", s)
+		}
+		fn()
+		if buf.String() != want[s] {
+			t.Errorf("%s: got %q, want %q", s, buf.String(), want[s])
+		}
+	}
+}
+
+// countingAdapter supports only Standard and counts its calls.
+type countingAdapter struct{ calls *int }
+
+func (countingAdapter) Name() string { return "counting" }
+
+func (a countingAdapter) Prepare(s Scenario, _ io.Writer) func() {
+	if s != Standard {
+		return nil
+	}
+	return func() { *a.calls++ }
+}
+
+func TestRunCustomAdapter(t *testing.T) {
+	var calls int
+	testing.Benchmark(func(b *testing.B) {
+		Run(b, countingAdapter{&calls})
+	})
+	if calls == 0 {
+		t.Error("adapter was never called")
+	}
+}
+
+func BenchmarkBolt(b *testing.B) {
+	Run(b, Bolt{})
+}
@@ -0,0 +1,152 @@
+package benchkit
+
+import (
+	"bufio"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Results holds the samples of one "go test -bench" run.
+type Results struct {
+	// CPU is the processor go test reported, if any.
+	CPU string
+	// Samples maps benchmark name to unit, such as "ns/op", to the value
+	// of every run of that benchmark.
+	Samples map[string]map[string][]float64
+}
+
+// Delta compares one benchmark across two runs.
+type Delta struct {
+	Name    string        `json:"name"`
+	Metrics []MetricDelta `json:"metrics"`
+}
+
+// MetricDelta compares the medians of one metric, such as ns/op. A side
+// without samples had no such benchmark; DeltaPct is only set when both
+// sides have samples and the base is non-zero.
+type MetricDelta struct {
+	Unit     string   `json:"unit"`
+	Base     float64  `json:"base"`
+	Head     float64  `json:"head"`
+	BaseN    int      `json:"base_samples"`
+	HeadN    int      `json:"head_samples"`
+	DeltaPct *float64 `json:"delta_pct,omitempty"`
+}
+
+// ParseResults collects the result lines of go test -bench output, such
+// as
+//
+//	BenchmarkBolt-8   11540166   105.2 ns/op   0 B/op   0 allocs/op
+//
+// skipping everything else. Benchmarks run with -count appear once with
+// one sample per run.
+func ParseResults(r io.Reader) (Results, error) {
+	res := Results{Samples: make(map[string]map[string][]float64)}
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := sc.Text()
+		if name, ok := strings.CutPrefix(line, "cpu: "); ok && res.CPU == "" {
+			res.CPU = strings.TrimSpace(name)
+			continue
+		}
+		f := strings.Fields(line)
+		if len(f) < 4 || !strings.HasPrefix(f[0], "Benchmark") {
+			continue
+		}
+		if _, err := strconv.Atoi(f[1]); err != nil {
+			continue // not a result line, e.g. a log line
+		}
+		for i := 2; i+1 < len(f); i += 2 {
+			v, err := strconv.ParseFloat(f[i], 64)
+			if err != nil {
+				break
+			}
+			if res.Samples[f[0]] == nil {
+				res.Samples[f[0]] = make(map[string][]float64)
+			}
+			res.Samples[f[0]][f[i+1]] = append(res.Samples[f[0]][f[i+1]], v)
+		}
+	}
+	return res, sc.Err()
+}
+
+// Median returns the median sample of a benchmark's unit and the number
+// of samples, which is zero if there are none.
+func (r Results) Median(name, unit string) (float64, int) {
+	v := r.Samples[name][unit]
+	return median(v), len(v)
+}
+
+// Compare pairs the benchmarks of two runs, sorted by name, with units in
+// a fixed order: ns/op, B/op, allocs/op, then custom metrics.
+func Compare(base, head Results) []Delta {
+	names := make([]string, 0, len(base.Samples)+len(head.Samples))
+	for name := range base.Samples {
+		names = append(names, name)
+	}
+	for name := range head.Samples {
+		if _, ok := base.Samples[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	deltas := make([]Delta, 0, len(names))
+	for _, name := range names {
+		b, h := base.Samples[name], head.Samples[name]
+		units := make([]string, 0, 4)
+		for u := range b {
+			units = append(units, u)
+		}
+		for u := range h {
+			if _, ok := b[u]; !ok {
+				units = append(units, u)
+			}
+		}
+		slices.SortFunc(units, func(a, b string) int {
+			if c := UnitRank(a) - UnitRank(b); c != 0 {
+				return c
+			}
+			return strings.Compare(a, b)
+		})
+		d := Delta{Name: name}
+		for _, u := range units {
+			m := MetricDelta{Unit: u, Base: median(b[u]), Head: median(h[u]), BaseN: len(b[u]), HeadN: len(h[u])}
+			if m.BaseN > 0 && m.HeadN > 0 && m.Base != 0 {
+				pct := (m.Head - m.Base) / m.Base * 100
+				m.DeltaPct = &pct
+			}
+			d.Metrics = append(d.Metrics, m)
+		}
+		deltas = append(deltas, d)
+	}
+	return deltas
+}
+
+// UnitRank orders units for display: 0 for ns/op, 1 for B/op, 2 for
+// allocs/op and 3 for anything else.
+func UnitRank(unit string) int {
+	switch unit {
+	case "ns/op":
+		return 0
+	case "B/op":
+		return 1
+	case "allocs/op":
+		return 2
+	}
+	return 3
+}
+
+func median(v []float64) float64 {
+	if len(v) == 0 {
+		return 0
+	}
+	s := slices.Clone(v)
+	slices.Sort(s)
+	if len(s)%2 == 1 {
+		return s[len(s)/2]
+	}
+	return (s[len(s)/2-1] + s[len(s)/2]) / 2
+}
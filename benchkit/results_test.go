@@ -0,0 +1,64 @@
+package benchkit
+
+import (
+	"strings"
+	"testing"
+)
+
+const sampleOutput = `goos: linux
+goarch: amd64
+pkg: go.klarlabs.de/bolt
+cpu: Test CPU @ 3.00GHz
+BenchmarkInfo-8     	10000000	       100.0 ns/op	       0 B/op	       0 allocs/op
+BenchmarkInfo-8     	10000000	       104.0 ns/op	       0 B/op	       0 allocs/op
+BenchmarkInfo-8     	10000000	       102.0 ns/op	       0 B/op	       0 allocs/op
+BenchmarkWith-8     	 1000000	      1000 ns/op	     288 B/op	       4 allocs/op	  12.5 MB/s
+BenchmarkInfo-8 logs a line that is not a result
+PASS
+`
+
+func TestParseResults(t *testing.T) {
+	res, err := ParseResults(strings.NewReader(sampleOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	runs := res.Samples
+	if got := runs["BenchmarkInfo-8"]["ns/op"]; len(got) != 3 || got[1] != 104 {
+		t.Errorf("Info ns/op = %v", got)
+	}
+	if got := runs["BenchmarkWith-8"]["MB/s"]; len(got) != 1 || got[0] != 12.5 {
+		t.Errorf("With MB/s = %v", got)
+	}
+	if res.CPU != "Test CPU @ 3.00GHz" {
+		t.Errorf("CPU = %q", res.CPU)
+	}
+	if m, n := res.Median("BenchmarkInfo-8", "ns/op"); m != 102 || n != 3 {
+		t.Errorf("Median = %v, %d", m, n)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	base, _ := ParseResults(strings.NewReader(sampleOutput))
+	head := Results{Samples: map[string]map[string][]float64{
+		"BenchmarkInfo-8": {"ns/op": {90, 91, 89}, "B/op": {0}, "allocs/op": {0}},
+		"BenchmarkNew-8":  {"ns/op": {5}},
+	}}
+	deltas := Compare(base, head)
+	var names []string
+	for _, d := range deltas {
+		names = append(names, d.Name)
+	}
+	if strings.Join(names, ",") != "BenchmarkInfo-8,BenchmarkNew-8,BenchmarkWith-8" {
+		t.Fatalf("benchmarks = %v", names)
+	}
+	ns := deltas[0].Metrics[0]
+	if ns.Unit != "ns/op" || ns.Base != 102 || ns.Head != 90 || ns.DeltaPct == nil || int(*ns.DeltaPct*100) != -1176 {
+		t.Errorf("Info ns/op = %+v", ns)
+	}
+	if allocs := deltas[0].Metrics[2]; allocs.Unit != "allocs/op" || allocs.DeltaPct != nil {
+		t.Errorf("Info allocs/op = %+v, want no delta from a zero base", allocs)
+	}
+	if m := deltas[1].Metrics[0]; m.BaseN != 0 || m.DeltaPct != nil {
+		t.Errorf("New ns/op = %+v, want no base samples", m)
+	}
+}
@@ -0,0 +1,187 @@
+package benchmarks
+
+import (
+	"flag"
+	"fmt"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.klarlabs.de/bolt"
+)
+
+// Soak mode runs a moderate, steady load for hours and checks at every
+// checkpoint that heap, file descriptors and latency stay flat, catching
+// slow leaks that short benchmarks cannot see:
+//
+//	go test -run TestSoak -timeout 0 -type=soak -duration=24h
+var (
+	benchType       = flag.String("type", "", `benchmark type; "soak" enables TestSoak`)
+	soakDuration    = flag.Duration("duration", 24*time.Hour, "soak test duration")
+	soakCheckpoint  = flag.Duration("checkpoint", 5*time.Minute, "interval between soak checkpoints")
+	soakRate        = flag.Int("rate", 20000, "soak events per second")
+	soakWorkers     = flag.Int("workers", 8, "soak logging goroutines")
+	soakHeapGrowth  = flag.Float64("max-heap-growth", 2, "allowed heap growth over the baseline checkpoint, as a factor")
+	soakFDGrowth    = flag.Int("max-fd-growth", 8, "allowed growth in open file descriptors over the baseline")
+	soakLatencyGrow = flag.Float64("max-p99-drift", 3, "allowed p99 latency growth over the baseline, as a factor")
+)
+
+// latencyHistogram counts durations in power-of-two nanosecond buckets.
+type latencyHistogram [64]atomic.Uint64
+
+func (h *latencyHistogram) observe(d time.Duration) {
+	h[bits.Len64(uint64(d))].Add(1)
+}
+
+// quantile returns the upper bound of the bucket holding quantile q and
+// resets the histogram.
+func (h *latencyHistogram) quantile(q float64) time.Duration {
+	var counts [64]uint64
+	var total uint64
+	for i := range h {
+		counts[i] = h[i].Swap(0)
+		total += counts[i]
+	}
+	want := uint64(q * float64(total))
+	var seen uint64
+	for i, c := range counts {
+		seen += c
+		if c > 0 && seen >= want {
+			return time.Duration(1) << i
+		}
+	}
+	return 0
+}
+
+// soakSample is the state sampled at one checkpoint.
+type soakSample struct {
+	heap   uint64
+	fds    int
+	p99    time.Duration
+	events uint64
+}
+
+func sampleSoak(hist *latencyHistogram, events *atomic.Uint64) soakSample {
+	runtime.GC()
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	return soakSample{heap: ms.HeapAlloc, fds: openFDs(), p99: hist.quantile(0.99), events: events.Load()}
+}
+
+// openFDs returns the number of open file descriptors, or -1 where
+// /proc is not available.
+func openFDs() int {
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		return -1
+	}
+	return len(entries)
+}
+
+func TestSoak(t *testing.T) {
+	if *benchType != "soak" {
+		t.Skip("soak mode not enabled; run with -type=soak")
+	}
+
+	// Exercise the file lifecycle as production does: write to a shared
+	// file through an async handler and rotate it at every checkpoint.
+	path := filepath.Join(t.TempDir(), "soak.log")
+	file, err := bolt.OpenSharedFile(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	async := bolt.NewAsyncHandler(bolt.NewJSONHandler(file), nil)
+	logger := bolt.New(async).With().Str("service", "soak").Logger()
+
+	var (
+		hist   latencyHistogram
+		events atomic.Uint64
+		stop   = make(chan struct{})
+		wg     sync.WaitGroup
+	)
+	const tick = 10 * time.Millisecond
+	perTick := max(1, *soakRate/(*soakWorkers)/int(time.Second/tick))
+	for w := range *soakWorkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ticker := time.NewTicker(tick)
+			defer ticker.Stop()
+			for n := 0; ; {
+				select {
+				case <-stop:
+					return
+				case <-ticker.C:
+				}
+				for range perTick {
+					start := time.Now()
+					logger.Info().
+						Int("worker", w).
+						Int("seq", n).
+						Str("request_id", "0f8fad5b-d9cb-469f-a165-70867728950e").
+						Bool("cached", n%2 == 0).
+						Msg("soak")
+					hist.observe(time.Since(start))
+					n++
+				}
+				events.Add(uint64(perTick))
+			}
+		}()
+	}
+
+	deadline := time.Now().Add(*soakDuration)
+	var base soakSample
+	for i := 0; time.Now().Before(deadline); i++ {
+		time.Sleep(min(*soakCheckpoint, time.Until(deadline)))
+		if err := rotate(file, path); err != nil {
+			t.Fatalf("rotate: %v", err)
+		}
+		s := sampleSoak(&hist, &events)
+		dropped := async.Dropped(bolt.LaneLow) + async.Dropped(bolt.LaneNormal) + async.Dropped(bolt.LaneHigh)
+		t.Logf("checkpoint %d: events=%d heap=%s fds=%d p99=%v dropped=%d",
+			i, s.events, mib(s.heap), s.fds, s.p99, dropped)
+		if i == 0 {
+			base = s // the first interval includes warm-up
+			continue
+		}
+		if float64(s.heap) > float64(base.heap)**soakHeapGrowth+(16<<20) {
+			t.Errorf("checkpoint %d: heap grew from %s to %s", i, mib(base.heap), mib(s.heap))
+		}
+		if base.fds >= 0 && s.fds > base.fds+*soakFDGrowth {
+			t.Errorf("checkpoint %d: open file descriptors grew from %d to %d", i, base.fds, s.fds)
+		}
+		if base.p99 > 0 && float64(s.p99) > float64(base.p99)**soakLatencyGrow {
+			t.Errorf("checkpoint %d: p99 latency drifted from %v to %v", i, base.p99, s.p99)
+		}
+	}
+
+	close(stop)
+	wg.Wait()
+	if err := async.Close(); err != nil {
+		t.Error(err)
+	}
+	if err := file.Close(); err != nil {
+		t.Error(err)
+	}
+}
+
+// rotate moves the log aside, reopens it and deletes the old file, as
+// logrotate would.
+func rotate(file *bolt.SharedFile, path string) error {
+	if err := os.Rename(path, path+".1"); err != nil {
+		return err
+	}
+	if err := file.Reopen(); err != nil {
+		return err
+	}
+	return os.Remove(path + ".1")
+}
+
+func mib(b uint64) string {
+	return fmt.Sprintf("%.1fMiB", float64(b)/(1<<20))
+}
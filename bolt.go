@@ -51,6 +51,7 @@
 // Environment variables:
 //   - BOLT_LEVEL: Set log level (trace, debug, info, warn, error, fatal)
 //   - BOLT_FORMAT: Set output format (json, console)
+//   - BOLT_DEV_CHECKS: Warn about conflicting loggers sharing an output (1, true)
 //
 // Programmatic configuration:
 //
@@ -101,8 +102,11 @@ package bolt
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"log"
 	"os"
+	"strings"
 	"sync/atomic"
 
 	oteltrace "go.opentelemetry.io/otel/trace"
@@ -118,7 +122,7 @@ const (
 	// event pool. Buffers larger than this are dropped so the pool cannot retain
 	// rare oversized allocations indefinitely.
 	PoolBufferCap = 8192 // 8KB
-	// StackTraceBufferSize is the buffer size for stack traces
+	// StackTraceBufferSize is the largest buffer used to capture stack traces
 	StackTraceBufferSize = 64 * 1024 // 64KB
 	// DefaultFilePermissions for log files
 	DefaultFilePermissions = 0644
@@ -126,6 +130,11 @@ const (
 	MaxKeyLength = 256
 	// MaxValueLength is the maximum allowed value length
 	MaxValueLength = 64 * 1024 // 64KB
+	// MaxRecordSize bounds the longest line bolt writes: a full
+	// MaxBufferSize buffer, the message Msg appends after the buffer
+	// check with every byte escaped as \u00XX, and up to 1KB of framing
+	// such as the closing bytes and envelope or chain fields.
+	MaxRecordSize = MaxBufferSize + 6*MaxValueLength + 1024
 )
 
 // exitFunc is called by Fatal-level events to terminate the process.
@@ -190,6 +199,20 @@ const (
 )
 
 // Handler processes a log event and writes it to an output.
+//
+// Handlers write each event to their output with exactly one Write call
+// carrying the complete record, trailing newline included. Writers that
+// serialize or buffer their input, such as [SharedFile] and
+// [BufferedWriter], rely on this to keep records whole when several
+// handlers or processes share an output; custom handlers must honour it
+// too.
+//
+// Write is called on the logging goroutine, concurrently from every
+// goroutine logging through the handler, so handlers must be safe for
+// concurrent use and keep records whole when calls overlap. The event
+// and its buffer are reused once Write returns: a handler that writes
+// later, as [AsyncHandler] and [JSONHandler] with workers do, must copy
+// the record first.
 type Handler interface {
 	// Write handles the log event, writing it to its destination.
 	// The handler is responsible for returning the event's buffer to the pool.
@@ -235,11 +258,34 @@ type EventHook interface {
 	Run(e *Event, msg string) bool
 }
 
+// HookFunc adapts an ordinary function to the [Hook] interface.
+type HookFunc func(level Level, msg string) bool
+
+// Run calls f(level, msg).
+func (f HookFunc) Run(level Level, msg string) bool { return f(level, msg) }
+
+// EventHookFunc adapts an ordinary function to the [EventHook] interface.
+type EventHookFunc func(e *Event, msg string) bool
+
+// Run calls f(e, msg).
+func (f EventHookFunc) Run(e *Event, msg string) bool { return f(e, msg) }
+
+// SampleRateField is the key under which annotating samplers record the
+// probability with which a kept event was kept, so that downstream
+// analytics can re-weight counts: an event with "sample_rate":0.01
+// stands for 100 events. See [SampleHook.Annotate] and
+// [HashSampler.Annotate]. When several annotating samplers apply to the
+// same event, each adds its own field; the event's weight is the
+// product of their rates.
+const SampleRateField = "sample_rate"
+
 // SampleHook implements Hook to sample log events at a rate of 1 in every N.
-// It uses atomic operations for thread-safe counting.
+// It uses atomic operations for thread-safe counting. Events from loggers
+// elevated through [DebugBaggageKey] bypass it.
 type SampleHook struct {
-	n       uint32
-	counter uint32
+	n        uint32
+	counter  uint32
+	annotate bool
 }
 
 // NewSampleHook creates a SampleHook that passes 1 out of every n events.
@@ -257,19 +303,41 @@ func (h *SampleHook) Run(_ Level, _ string) bool {
 	return c%h.n == 0
 }
 
+// Annotate makes the hook add [SampleRateField] with the rate 1/n to
+// the events it keeps, and returns h. Events bypassing sampling, and all
+// events when n is 0 or 1, are not annotated. Call it before adding the
+// hook to a logger.
+func (h *SampleHook) Annotate() *SampleHook {
+	h.annotate = true
+	return h
+}
+
 // Logger is the main logging interface.
 type Logger struct {
-	handler      Handler
-	level        int64  // Use int64 for atomic operations with Level
-	context      []byte // Pre-formatted context fields for this logger instance.
+	handler      *handlerRef // shared with derived loggers; see SwapHandler
+	level        int64       // Use int64 for atomic operations with Level
+	context      []byte      // Pre-formatted context fields for this logger instance.
 	errorHandler ErrorHandler
 	hooks        []Hook
 	eventHooks   []EventHook
+	keyReplacer  *strings.Replacer
+	stackOpts    *StackOptions
+	callerOpts   *CallerOptions
+	extractors   []ContextExtractor
+	elevated     bool // level lowered per request via DebugBaggageKey
+
+	trackFields bool    // record field offsets; see SetFieldTracking
+	ctxFields   []int32 // offsets of the context's keys when tracking
+
+	devWhere   string      // where the logger was configured; set under SetDevChecks
+	devPending atomic.Bool // configuration not yet checked for drift
 }
 
 // New creates a new logger with the given handler.
 func New(handler Handler) *Logger {
-	return &Logger{handler: handler, errorHandler: defaultErrorHandler}
+	l := &Logger{handler: newHandlerRef(handler), errorHandler: defaultErrorHandler}
+	l.markDrift(1)
+	return l
 }
 
 // SetErrorHandler sets a custom error handler for the logger
@@ -278,6 +346,35 @@ func (l *Logger) SetErrorHandler(eh ErrorHandler) *Logger {
 	return l
 }
 
+// MongoKeyReplacer rewrites the characters MongoDB rejects or treats
+// specially in field names: '.' (path separator) and '$' (operator
+// prefix) both become '_'. Pass it to [Logger.SetKeyReplacer] when the
+// JSON output is persisted to MongoDB or another BSON store.
+var MongoKeyReplacer = strings.NewReplacer(".", "_", "$", "_")
+
+// SetKeyReplacer installs a replacer that is applied to every field key
+// as it is encoded, so that keys stay legal for stores with stricter
+// naming rules than JSON. The reserved "level" and "message" keys are
+// not affected. Pass nil to disable. Like AddHook, it is intended for
+// setup-time configuration and is inherited by child loggers.
+//
+// Replacement only allocates when a key actually contains a character
+// that is rewritten.
+func (l *Logger) SetKeyReplacer(r *strings.Replacer) *Logger {
+	l.keyReplacer = r
+	l.markDrift(1)
+	return l
+}
+
+// appendKey appends a JSON-escaped field key, applying the logger's key
+// replacer if one is configured.
+func (l *Logger) appendKey(buf []byte, key string) []byte {
+	if l.keyReplacer != nil {
+		key = l.keyReplacer.Replace(key)
+	}
+	return appendJSONString(buf, key)
+}
+
 // AddHook adds a hook to the logger. Hooks are called in order during Msg().
 // AddHook is intended for setup-time configuration and is not safe to call
 // concurrently with logging operations.
@@ -295,6 +392,59 @@ func (l *Logger) AddEventHook(hook EventHook) *Logger {
 	return l
 }
 
+// Hook returns a child logger that runs hooks after the receiver's own
+// EventHooks, in the style of zerolog's Logger.Hook. Unlike
+// [Logger.AddEventHook] the receiver is not modified, so a subsystem can
+// attach enrichment or side effects (error forwarding, metrics) without
+// affecting other users of the parent logger:
+//
+//	payments := logger.Hook(bolt.EventHookFunc(func(e *bolt.Event, msg string) bool {
+//		if e.Level() >= bolt.ERROR {
+//			alerts.Notify(msg)
+//		}
+//		e.Str("subsystem", "payments")
+//		return true
+//	}))
+//
+// The child shares the receiver's handler and configuration but has its
+// own level, initially the receiver's.
+//
+// Hooks differ from zerolog's Hook.Run(e, level, msg) in two ways. The
+// name Hook already belongs to the level-and-message interface taken by
+// [Logger.AddHook], so they are EventHooks, which read the level with
+// [Event.Level] instead of receiving it. And Run reports whether to keep
+// the event, so one hook type covers both enrichment and filtering;
+// hooks that only enrich return true.
+func (l *Logger) Hook(hooks ...EventHook) *Logger {
+	c := l.clone()
+	c.eventHooks = append(l.eventHooks[:len(l.eventHooks):len(l.eventHooks)], hooks...)
+	return c
+}
+
+// clone returns a copy of l with the same handler, configuration and
+// level. Every method deriving a logger starts from clone, so a new Logger
+// field only needs to be copied here. The copy keeps l's configuration
+// site for development checks but is not checked again itself.
+func (l *Logger) clone() *Logger {
+	c := &Logger{
+		handler:      l.handler,
+		context:      l.context,
+		errorHandler: l.errorHandler,
+		hooks:        l.hooks,
+		eventHooks:   l.eventHooks,
+		keyReplacer:  l.keyReplacer,
+		stackOpts:    l.stackOpts,
+		callerOpts:   l.callerOpts,
+		extractors:   l.extractors,
+		elevated:     l.elevated,
+		trackFields:  l.trackFields,
+		ctxFields:    l.ctxFields,
+		devWhere:     l.devWhere,
+	}
+	atomic.StoreInt64(&c.level, atomic.LoadInt64(&l.level))
+	return c
+}
+
 // With creates a new Event with the current logger's context.
 func (l *Logger) With() *Event {
 	levelValue := atomic.LoadInt64(&l.level)
@@ -311,18 +461,40 @@ func (l *Logger) With() *Event {
 // Logger returns a new Logger with the event's fields as context.
 
 // Ctx automatically includes OpenTelemetry trace/span IDs if present.
+// If the context's OTel baggage carries [DebugBaggageKey], the returned
+// logger's level is lowered for this request; see [DebugBaggageKey].
+//
+// Each call encodes the IDs into a new logger. Handlers that log many
+// times per span can store the result once with [Logger.CacheCtx].
 func (l *Logger) Ctx(ctx context.Context) *Logger {
-	logger := l // Start with the current logger
+	sc := oteltrace.SpanContextFromContext(ctx)
+	level, elevate := baggageLevel(ctx)
+	if c, ok := ctx.Value(ctxLoggerKey{}).(*cachedCtxLogger); ok && c.matches(l, sc, level, elevate) {
+		return c.logger
+	}
+	return l.deriveCtx(ctx, sc, level, elevate)
+}
 
-	span := oteltrace.SpanFromContext(ctx)
-	if span.SpanContext().IsValid() {
+func (l *Logger) deriveCtx(ctx context.Context, sc oteltrace.SpanContext, level Level, elevate bool) *Logger {
+	logger := l // Start with the current logger
+	if sc.IsValid() {
 		// Create a new logger with trace and span IDs as context
-		logger = logger.With().Str("trace_id", span.SpanContext().TraceID().String()).Str("span_id", span.SpanContext().SpanID().String()).Logger()
+		logger = logger.With().Str("trace_id", sc.TraceID().String()).Str("span_id", sc.SpanID().String()).Logger()
+	}
+	if len(logger.extractors) > 0 {
+		logger = logger.applyExtractors(ctx)
+	}
+	if elevate {
+		logger = logger.elevate(level)
 	}
 	return logger
 }
 
-func (l *Logger) log(level Level) *Event {
+// log starts an event at level, or returns nil if level is disabled.
+// skip is the number of frames between log's caller and the user code
+// that [CallerOptions.Always] reports: 0 when called from the user's
+// call, 1 from a helper such as logf.
+func (l *Logger) log(level Level, skip int) *Event {
 	// Use atomic load to safely read the current level
 	levelValue := atomic.LoadInt64(&l.level)
 	// Ensure level is within valid range (defensive programming)
@@ -335,13 +507,19 @@ func (l *Logger) log(level Level) *Event {
 	if level < currentLevel {
 		return nil
 	}
+	if l.devPending.Load() && l.devPending.CompareAndSwap(true, false) {
+		l.checkDrift()
+	}
 
-	e := eventPool.Get().(*Event)
+	e := getEvent()
 	e.level = level
 	e.l = l
 	e.buf = e.buf[:0] // Reset buffer length but keep capacity
 
 	e.buf = append(e.buf, '{') // Always start with '{'
+	if l.trackFields {
+		e.fields = append(e.fields, 1) // "level"
+	}
 
 	// Add level
 	e.buf = append(e.buf, `"level":"`...)
@@ -351,63 +529,54 @@ func (l *Logger) log(level Level) *Event {
 	// Add logger context if present
 	if len(l.context) > 0 {
 		e.buf = append(e.buf, ',') // Add comma before context
+		if l.trackFields {
+			e.fields = shiftOffsets(e.fields, l.ctxFields, len(e.buf))
+		}
 		e.buf = append(e.buf, l.context...)
 	}
+	if opts := l.callerOpts; opts != nil && opts.Always {
+		e.addCaller(skip + 1)
+	}
 	return e
 }
 
+// start is log for the level methods, returning a no-op Event when level
+// is disabled. skip counts frames as for log.
+func (l *Logger) start(level Level, skip int) *Event {
+	if e := l.log(level, skip+1); e != nil {
+		return e
+	}
+	return &Event{} // Return a no-op Event
+}
+
 // Info starts a new message with the INFO level.
 func (l *Logger) Info() *Event {
-	e := l.log(INFO)
-	if e == nil {
-		return &Event{} // Return a no-op Event
-	}
-	return e
+	return l.start(INFO, 0)
 }
 
 // Error starts a new message with the ERROR level.
 func (l *Logger) Error() *Event {
-	e := l.log(ERROR)
-	if e == nil {
-		return &Event{} // Return a no-op Event
-	}
-	return e
+	return l.start(ERROR, 0)
 }
 
 // Debug starts a new message with the DEBUG level.
 func (l *Logger) Debug() *Event {
-	e := l.log(DEBUG)
-	if e == nil {
-		return &Event{} // Return a no-op Event
-	}
-	return e
+	return l.start(DEBUG, 0)
 }
 
 // Warn starts a new message with the WARN level.
 func (l *Logger) Warn() *Event {
-	e := l.log(WARN)
-	if e == nil {
-		return &Event{} // Return a no-op Event
-	}
-	return e
+	return l.start(WARN, 0)
 }
 
 // Trace starts a new message with the TRACE level.
 func (l *Logger) Trace() *Event {
-	e := l.log(TRACE)
-	if e == nil {
-		return &Event{} // Return a no-op Event
-	}
-	return e
+	return l.start(TRACE, 0)
 }
 
 // Fatal starts a new message with the FATAL level.
 func (l *Logger) Fatal() *Event {
-	e := l.log(FATAL)
-	if e == nil {
-		return &Event{} // Return a no-op Event
-	}
-	return e
+	return l.start(FATAL, 0)
 }
 
 // Str adds a string field to the event with proper JSON escaping and validation.
@@ -478,34 +647,101 @@ func (l *Logger) SetLevel(level Level) *Logger {
 	return l
 }
 
+// GetLevel returns the logger's current minimum level.
+func (l *Logger) GetLevel() Level {
+	levelValue := atomic.LoadInt64(&l.level)
+	if levelValue < int64(TRACE) || levelValue > int64(FATAL) {
+		return INFO
+	}
+	return Level(levelValue) // #nosec G115 - bounds already checked above
+}
+
 // Info starts a new message with the INFO level on the default logger.
 func Info() *Event {
-	return defaultLogger.Info()
+	return defaultLogger.start(INFO, 0)
 }
 
 // Error starts a new message with the ERROR level on the default logger.
 func Error() *Event {
-	return defaultLogger.Error()
+	return defaultLogger.start(ERROR, 0)
 }
 
 // Debug starts a new message with the DEBUG level on the default logger.
 func Debug() *Event {
-	return defaultLogger.Debug()
+	return defaultLogger.start(DEBUG, 0)
 }
 
 // Warn starts a new message with the WARN level on the default logger.
 func Warn() *Event {
-	return defaultLogger.Warn()
+	return defaultLogger.start(WARN, 0)
 }
 
 // Trace starts a new message with the TRACE level on the default logger.
 func Trace() *Event {
-	return defaultLogger.Trace()
+	return defaultLogger.start(TRACE, 0)
 }
 
 // Fatal starts a new message with the FATAL level on the default logger.
 func Fatal() *Event {
-	return defaultLogger.Fatal()
+	return defaultLogger.start(FATAL, 0)
+}
+
+// Debugf logs a message formatted by [fmt.Sprintf] at DEBUG on the
+// default logger. See [Logger.Infof].
+func Debugf(format string, args ...interface{}) {
+	defaultLogger.logf(DEBUG, format, args)
+}
+
+// Infof logs a message formatted by [fmt.Sprintf] at INFO on the default
+// logger. See [Logger.Infof].
+func Infof(format string, args ...interface{}) {
+	defaultLogger.logf(INFO, format, args)
+}
+
+// Warnf logs a message formatted by [fmt.Sprintf] at WARN on the default
+// logger. See [Logger.Infof].
+func Warnf(format string, args ...interface{}) {
+	defaultLogger.logf(WARN, format, args)
+}
+
+// Errorf logs a message formatted by [fmt.Sprintf] at ERROR on the
+// default logger. See [Logger.Infof].
+func Errorf(format string, args ...interface{}) {
+	defaultLogger.logf(ERROR, format, args)
+}
+
+// Debugf logs a message formatted by [fmt.Sprintf] at DEBUG.
+// See [Logger.Infof].
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.logf(DEBUG, format, args)
+}
+
+// Infof logs a message formatted by [fmt.Sprintf] at INFO. It eases
+// migration from logrus and the standard log package; new code should
+// prefer structured fields.
+//
+// The level is checked before formatting, so a disabled call costs no
+// more than the caller's conversion of args to interface values.
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.logf(INFO, format, args)
+}
+
+// Warnf logs a message formatted by [fmt.Sprintf] at WARN.
+// See [Logger.Infof].
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	l.logf(WARN, format, args)
+}
+
+// Errorf logs a message formatted by [fmt.Sprintf] at ERROR.
+// See [Logger.Infof].
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.logf(ERROR, format, args)
+}
+
+func (l *Logger) logf(level Level, format string, args []interface{}) {
+	if e := l.log(level, 1); e != nil {
+		e.Msg(fmt.Sprintf(format, args...))
+	}
 }
 
 // Additional utility methods and performance optimizations
@@ -519,8 +755,9 @@ type levelWriter struct {
 }
 
 // NewLevelWriter returns an io.Writer that logs each Write call as a message
-// at the given level. Trailing newlines are trimmed. This is useful for bridging
-// libraries that expect an io.Writer (such as the standard log package) into Bolt.
+// at the given level. One trailing "\n" or "\r\n" is trimmed. This is
+// useful for bridging libraries that expect an io.Writer (such as the
+// standard log package) into Bolt.
 //
 // The string(p) conversion allocates, which is acceptable since this is a
 // compatibility bridge rather than a hot-path logging method.
@@ -528,15 +765,28 @@ func NewLevelWriter(logger *Logger, level Level) io.Writer {
 	return &levelWriter{logger: logger, level: level}
 }
 
+// Write logs p as one event. A trailing "\n" or "\r\n" is trimmed.
 func (w *levelWriter) Write(p []byte) (int, error) {
 	n := len(p)
 	msg := string(p)
-	if len(msg) > 0 && msg[len(msg)-1] == '\n' {
-		msg = msg[:len(msg)-1]
+	if strings.HasSuffix(msg, "\n") {
+		msg = strings.TrimSuffix(msg[:len(msg)-1], "\r")
 	}
-	e := w.logger.log(w.level)
+	e := w.logger.log(w.level, 0)
 	if e != nil {
 		e.Msg(msg)
 	}
 	return n, nil
 }
+
+// StdLogger returns a standard library [log.Logger] that logs every line
+// written to it as one event at level, for libraries that take a
+// *log.Logger such as [net/http.Server.ErrorLog]:
+//
+//	srv := &http.Server{ErrorLog: logger.StdLogger(bolt.WARN)}
+//
+// The returned logger has no prefix or flags; bolt adds the timestamp
+// and level. See [NewLevelWriter] for the underlying io.Writer.
+func (l *Logger) StdLogger(level Level) *log.Logger {
+	return log.New(NewLevelWriter(l, level), "", 0)
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"math"
 	"net"
 	"regexp"
 	"strings"
@@ -171,6 +172,72 @@ func TestConsoleHandler(t *testing.T) {
 	})
 }
 
+func TestConsoleHandlerMultilineFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewConsoleHandler(&buf).SetMultilineFields("stack", "sql", "absent"))
+
+	logger.Error().
+		Str("sql", "SELECT *\n\tFROM \"users\"").
+		Int("user", 42).
+		Str("stack", "goroutine 1 [running]:\nmain.main()").
+		Msg("query failed")
+
+	lines := strings.Split(buf.String(), "\n")
+	if !strings.HasSuffix(lines[0], "] query failed user=42") {
+		t.Errorf("main line = %q; multiline fields should not be inline", lines[0])
+	}
+	// Blocks follow the order the fields were designated in.
+	want := []string{
+		"  stack:",
+		"    goroutine 1 [running]:",
+		"    main.main()",
+		"  sql:",
+		"    SELECT *",
+		"    \tFROM \"users\"",
+		"",
+	}
+	if got := lines[1:]; strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("blocks =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestAppendJSONUnescaped(t *testing.T) {
+	in := "a\"b\\c\n\t\u00e9\u001F/\\u12"
+	enc := appendJSONString(nil, in)
+	if got := string(appendJSONUnescaped(nil, enc)); got != in {
+		t.Errorf("round trip = %q; want %q", got, in)
+	}
+	if got := string(appendJSONUnescaped(nil, []byte(`\uZZZZ`))); got != `\uZZZZ` {
+		t.Errorf("malformed escape = %q", got)
+	}
+}
+
+func TestAppendJSONBytes(t *testing.T) {
+	for _, s := range []string{"", "plain", `a"b\c`, "tab\tnew\nline\x00\x1f", "ünïcode ✓ \u2028", "bad \xff\xfe utf8", strings.Repeat("long ", 20)} {
+		if got, want := string(appendJSONBytes(nil, []byte(s))), string(appendJSONString(nil, s)); got != want {
+			t.Errorf("appendJSONBytes(%q) = %q; want %q", s, got, want)
+		}
+	}
+}
+
+func TestConsoleHandlerEncoderPath(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewConsoleHandler(&buf))
+	long := strings.Repeat("x", 100)
+	logger.Info().Dict("req", func(d *Event) { d.Str("path", "/x").Int("status", 200) }).Str("q", `a"b`).Str("long", long).Msg("line\nbreak")
+	want := ` line\nbreak req={"path":"/x","status":200} q=a\"b long=` + long + "\n"
+	if got := buf.String(); !strings.HasSuffix(got, "]"+want) {
+		t.Errorf("got %q; want suffix %q", got, want)
+	}
+	if raceDetectorEnabled {
+		t.Skip("allocation counts are not meaningful under -race")
+	}
+	logger = New(NewConsoleHandler(&discardWriter{}))
+	if n := testing.AllocsPerRun(100, func() { logger.Info().Str("long", long).Msg(long) }); n != 0 {
+		t.Errorf("ConsoleHandler allocates %v times per record", n)
+	}
+}
+
 // --- Feature 1: Uint8/Uint16/Uint32 ---
 
 func TestUintFields(t *testing.T) {
@@ -224,6 +291,36 @@ func TestUintFields(t *testing.T) {
 	})
 }
 
+func TestSizedIntFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewJSONHandler(&buf))
+
+	logger.Info().
+		Int64("i64", math.MinInt64).
+		Int32("i32", math.MinInt32).
+		Uint64("u64", math.MaxUint64).
+		Uint32("u32", math.MaxUint32).
+		Dur("d", time.Duration(math.MaxInt64)).
+		Msg("sized")
+	expected := `{"level":"info","i64":-9223372036854775808,"i32":-2147483648,` +
+		`"u64":18446744073709551615,"u32":4294967295,"d":9223372036854775807,"message":"sized"}` + "\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+
+	buf.Reset()
+	if raceDetectorEnabled {
+		t.Skip("allocation counts are not meaningful under -race")
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		buf.Reset()
+		logger.Info().Int64("a", 1<<40).Int32("b", -7).Uint64("c", 1<<63).Uint32("d", 9).Msg("x")
+	})
+	if allocs != 0 {
+		t.Errorf("sized integer fields allocated %.1f times per event", allocs)
+	}
+}
+
 // --- Feature 2: CallerSkip ---
 
 func logHelper(logger *Logger) {
@@ -490,6 +587,52 @@ func TestDict(t *testing.T) {
 	})
 }
 
+type testUser struct {
+	ID    string
+	Roles []string
+	Addr  *testAddr
+}
+
+func (u testUser) MarshalLogObject(e *Event) {
+	e.Str("id", u.ID).Strs("roles", u.Roles)
+	if u.Addr != nil {
+		e.Object("addr", u.Addr)
+	}
+}
+
+type testAddr struct{ City string }
+
+func (a *testAddr) MarshalLogObject(e *Event) { e.Str("city", a.City) }
+
+func TestObject(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewJSONHandler(&buf))
+	user := testUser{ID: "u1", Roles: []string{"admin"}, Addr: &testAddr{City: "Berlin"}}
+
+	logger.Info().Object("user", user).Object("none", nil).Msg("o")
+	expected := `{"level":"info","user":{"id":"u1","roles":["admin"],"addr":{"city":"Berlin"}},"none":null,"message":"o"}` + "\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+
+	buf.Reset()
+	logger.Info().Any("user", user).Msg("o")
+	if !strings.Contains(buf.String(), `"user":{"id":"u1",`) {
+		t.Errorf("Any did not use MarshalLogObject: %q", buf.String())
+	}
+
+	if raceDetectorEnabled {
+		t.Skip("allocation counts are not meaningful under -race")
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		buf.Reset()
+		logger.Info().Object("user", &user).Msg("o")
+	})
+	if allocs != 0 {
+		t.Errorf("Object allocated %.1f times per event", allocs)
+	}
+}
+
 // --- Feature 7: MultiHandler ---
 
 func TestMultiHandler(t *testing.T) {
@@ -657,6 +800,24 @@ func TestSampleHook(t *testing.T) {
 		}
 	})
 
+	t.Run("annotate", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := New(NewJSONHandler(&buf)).AddHook(NewSampleHook(4).Annotate())
+
+		for i := 0; i < 4; i++ {
+			logger.Info().Int("i", i).Msg("sample")
+		}
+		if got, want := buf.String(), `{"level":"info","i":3,"sample_rate":0.25,"message":"sample"}`+"\n"; got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+
+		buf.Reset()
+		New(NewJSONHandler(&buf)).AddHook(NewSampleHook(1).Annotate()).Info().Msg("all")
+		if strings.Contains(buf.String(), SampleRateField) {
+			t.Errorf("n=1 annotated: %s", buf.String())
+		}
+	})
+
 	t.Run("n=0 passes all", func(t *testing.T) {
 		var buf bytes.Buffer
 		hook := NewSampleHook(0)
@@ -749,6 +910,22 @@ func TestNewLevelWriter(t *testing.T) {
 		}
 	})
 
+	t.Run("keeps a carriage return without newline", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := New(NewJSONHandler(&buf))
+		w := NewLevelWriter(logger, INFO)
+
+		_, _ = w.Write([]byte("progress\r"))
+		_, _ = w.Write([]byte("crlf\r\n"))
+
+		if !strings.Contains(buf.String(), `"message":"progress\r"`) {
+			t.Errorf("Expected lone \\r to be kept, got %q", buf.String())
+		}
+		if !strings.Contains(buf.String(), `"message":"crlf"`) {
+			t.Errorf("Expected \\r\\n to be trimmed, got %q", buf.String())
+		}
+	})
+
 	t.Run("respects level filtering", func(t *testing.T) {
 		var buf bytes.Buffer
 		logger := New(NewJSONHandler(&buf)).SetLevel(ERROR)
@@ -783,3 +960,72 @@ func TestNewLevelWriter(t *testing.T) {
 		}
 	})
 }
+
+func TestStdLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewJSONHandler(&buf)).With().Str("component", "http").Logger()
+	stdlog := logger.StdLogger(WARN)
+
+	stdlog.Print("TLS handshake error\r\n")
+	stdlog.Printf("accept: %s", "too many open files")
+
+	want := `{"level":"warn","component":"http","message":"TLS handshake error"}` + "\n" +
+		`{"level":"warn","component":"http","message":"accept: too many open files"}` + "\n"
+	if buf.String() != want {
+		t.Errorf("got  %q\nwant %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	logger.SetLevel(ERROR)
+	stdlog.Print("filtered")
+	if buf.Len() != 0 {
+		t.Errorf("expected no output below ERROR, got %q", buf.String())
+	}
+}
+
+func TestSend(t *testing.T) {
+	var buf bytes.Buffer
+	var hookMsg = "unset"
+	logger := New(NewJSONHandler(&buf)).AddEventHook(EventHookFunc(func(e *Event, msg string) bool {
+		hookMsg = msg
+		return true
+	}))
+
+	logger.Info().Str("user", "alice").Int("items", 3).Send()
+	if got, want := buf.String(), `{"level":"info","user":"alice","items":3}`+"\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if hookMsg != "" {
+		t.Errorf("hook saw message %q, want empty", hookMsg)
+	}
+
+	buf.Reset()
+	logger.SetLevel(ERROR).Info().Str("user", "bob").Send()
+	if buf.Len() != 0 {
+		t.Errorf("disabled Send wrote %q", buf.String())
+	}
+}
+
+func TestLazyEvaluation(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewJSONHandler(&buf)).SetLevel(INFO)
+
+	called := 0
+	logger.Debug().Func(func(e *Event) {
+		called++
+		e.Str("expensive", "x")
+	}).MsgFunc(func() string {
+		called++
+		return "hidden"
+	})
+	if called != 0 || buf.Len() != 0 {
+		t.Fatalf("disabled event evaluated %d callbacks, wrote %q", called, buf.String())
+	}
+
+	logger.Info().Func(func(e *Event) {
+		e.Int("n", 1)
+	}).MsgFunc(func() string { return "computed" })
+	if got, want := buf.String(), `{"level":"info","n":1,"message":"computed"}`+"\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
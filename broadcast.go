@@ -0,0 +1,138 @@
+package bolt
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultSubscriberBuffer is the per-subscriber channel capacity used by
+// [NewBroadcastHandler] when none is configured.
+const DefaultSubscriberBuffer = 256
+
+// Record is a finished log record delivered to a subscriber.
+type Record struct {
+	// Level is the record's level.
+	Level Level
+	// Data is the encoded record, including the trailing newline. It is
+	// shared between subscribers and must not be modified.
+	Data []byte
+	// Dropped is the number of records this subscriber missed since the
+	// previous delivered record because its buffer was full.
+	Dropped uint64
+}
+
+// BroadcastOptions configures [NewBroadcastHandler]. A nil
+// *BroadcastOptions uses the defaults.
+type BroadcastOptions struct {
+	// Buffer is the channel capacity of each subscriber. Defaults to
+	// DefaultSubscriberBuffer.
+	Buffer int
+}
+
+// BroadcastHandler writes every event to the wrapped handler and also
+// fans it out to in-memory subscribers, so admin UIs and debug endpoints
+// can live-stream a filtered view of the logs without touching files.
+//
+// Delivery never blocks logging: each subscriber has a bounded buffer and
+// records that do not fit are dropped for that subscriber only, with the
+// loss reported in the next delivered [Record.Dropped]. With no
+// subscribers the only overhead is an atomic load.
+//
+//	bh := bolt.NewBroadcastHandler(bolt.NewJSONHandler(os.Stdout), nil)
+//	logger := bolt.New(bh)
+//
+//	records, cancel := bh.Subscribe(func(e *bolt.Event) bool {
+//	    return e.Level() >= bolt.WARN
+//	})
+//	defer cancel()
+//	for r := range records {
+//	    ws.Write(r.Data)
+//	}
+type BroadcastHandler struct {
+	next   Handler
+	buffer int
+
+	mu   sync.RWMutex
+	subs map[*subscriber]struct{}
+	n    atomic.Int32
+}
+
+type subscriber struct {
+	filter  func(e *Event) bool
+	ch      chan Record
+	dropped atomic.Uint64
+}
+
+// NewBroadcastHandler returns a BroadcastHandler writing to next. next may
+// be nil to only serve subscribers. If opts is nil, defaults are used.
+func NewBroadcastHandler(next Handler, opts *BroadcastOptions) *BroadcastHandler {
+	buffer := DefaultSubscriberBuffer
+	if opts != nil && opts.Buffer > 0 {
+		buffer = opts.Buffer
+	}
+	return &BroadcastHandler{next: next, buffer: buffer, subs: make(map[*subscriber]struct{})}
+}
+
+// Subscribe registers a subscriber receiving every record for which
+// filter returns true; a nil filter receives everything. filter runs on
+// the logging goroutine with the finished event, so it may use
+// [Event.Level] and [Event.WalkFields] but must be fast and must not
+// retain the event.
+//
+// cancel unsubscribes and closes the channel; it is safe to call more
+// than once.
+func (b *BroadcastHandler) Subscribe(filter func(e *Event) bool) (records <-chan Record, cancel func()) {
+	s := &subscriber{filter: filter, ch: make(chan Record, b.buffer)}
+	b.mu.Lock()
+	b.subs[s] = struct{}{}
+	b.n.Add(1)
+	b.mu.Unlock()
+
+	var once sync.Once
+	return s.ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, s)
+			b.n.Add(-1)
+			close(s.ch)
+			b.mu.Unlock()
+		})
+	}
+}
+
+// Subscribers returns the number of active subscribers.
+func (b *BroadcastHandler) Subscribers() int {
+	return int(b.n.Load())
+}
+
+// Write implements [Handler].
+func (b *BroadcastHandler) Write(e *Event) error {
+	var err error
+	if b.next != nil {
+		err = b.next.Write(e)
+	}
+	if b.n.Load() == 0 {
+		return err
+	}
+
+	var data []byte // copied once, on the first match
+	b.mu.RLock()
+	for s := range b.subs {
+		if s.filter != nil && !s.filter(e) {
+			continue
+		}
+		if data == nil {
+			data = append([]byte(nil), e.buf...)
+		}
+		// Take the count before sending so that drops recorded meanwhile
+		// by concurrent writers are kept for the next record.
+		n := s.dropped.Swap(0)
+		select {
+		case s.ch <- Record{Level: e.level, Data: data, Dropped: n}:
+		default:
+			s.dropped.Add(n + 1)
+		}
+	}
+	b.mu.RUnlock()
+	return err
+}
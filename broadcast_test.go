@@ -0,0 +1,138 @@
+package bolt
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestBroadcastHandler_FanOut(t *testing.T) {
+	var out bytes.Buffer
+	bh := NewBroadcastHandler(NewJSONHandler(&out), nil)
+	logger := New(bh)
+
+	all, cancelAll := bh.Subscribe(nil)
+	defer cancelAll()
+	warn, cancelWarn := bh.Subscribe(func(e *Event) bool { return e.Level() >= WARN })
+	defer cancelWarn()
+
+	logger.Info().Msg("hello")
+	logger.Error().Str("k", "v").Msg("boom")
+
+	if got := strings.Count(out.String(), "\n"); got != 2 {
+		t.Fatalf("next handler got %d lines, want 2", got)
+	}
+	for _, want := range []string{"hello", "boom"} {
+		r := <-all
+		if !bytes.Contains(r.Data, []byte(want)) {
+			t.Errorf("all subscriber got %q, want %q", r.Data, want)
+		}
+	}
+	r := <-warn
+	if r.Level != ERROR || !bytes.Contains(r.Data, []byte(`"k":"v"`)) {
+		t.Errorf("warn subscriber got %v %q", r.Level, r.Data)
+	}
+	select {
+	case r := <-warn:
+		t.Errorf("warn subscriber got unexpected %q", r.Data)
+	default:
+	}
+}
+
+func TestBroadcastHandler_BoundedBuffer(t *testing.T) {
+	bh := NewBroadcastHandler(nil, &BroadcastOptions{Buffer: 2})
+	logger := New(bh)
+	records, cancel := bh.Subscribe(nil)
+	defer cancel()
+
+	for i := 0; i < 5; i++ {
+		logger.Info().Int("i", i).Msg("m")
+	}
+	<-records
+	<-records
+	logger.Info().Int("i", 5).Msg("m")
+	r := <-records
+	if r.Dropped != 3 || !bytes.Contains(r.Data, []byte(`"i":5`)) {
+		t.Errorf("got Dropped=%d %q, want 3 records dropped before i=5", r.Dropped, r.Data)
+	}
+}
+
+func TestBroadcastHandler_DroppedConcurrent(t *testing.T) {
+	bh := NewBroadcastHandler(nil, &BroadcastOptions{Buffer: 4})
+	logger := New(bh)
+	records, cancel := bh.Subscribe(nil)
+	defer cancel()
+
+	var seen uint64 // records received plus drops they report
+	stop, stopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case r := <-records:
+				seen += 1 + r.Dropped
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	const writers, each = 8, 500
+	var wg sync.WaitGroup
+	for range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range each {
+				logger.Info().Msg("m")
+			}
+		}()
+	}
+	wg.Wait()
+	close(stop)
+	<-stopped
+	for len(records) > 0 {
+		r := <-records
+		seen += 1 + r.Dropped
+	}
+
+	// The channel is empty, so this record carries the drops not yet
+	// reported.
+	logger.Info().Msg("last")
+	r := <-records
+	seen += 1 + r.Dropped
+	if seen != writers*each+1 {
+		t.Errorf("records plus drops = %d, want %d", seen, writers*each+1)
+	}
+}
+
+func TestBroadcastHandler_Cancel(t *testing.T) {
+	bh := NewBroadcastHandler(nil, nil)
+	records, cancel := bh.Subscribe(nil)
+	if bh.Subscribers() != 1 {
+		t.Fatalf("Subscribers() = %d, want 1", bh.Subscribers())
+	}
+	cancel()
+	cancel()
+	if _, ok := <-records; ok {
+		t.Error("channel not closed after cancel")
+	}
+	if bh.Subscribers() != 0 {
+		t.Errorf("Subscribers() = %d, want 0", bh.Subscribers())
+	}
+	New(bh).Info().Msg("after cancel") // must not panic
+}
+
+func TestBroadcastHandler_DataOwned(t *testing.T) {
+	bh := NewBroadcastHandler(nil, nil)
+	logger := New(bh)
+	records, cancel := bh.Subscribe(nil)
+	defer cancel()
+
+	logger.Info().Msg("first")
+	logger.Info().Msg("second") // reuses the pooled event buffer
+	if r := <-records; !bytes.Contains(r.Data, []byte("first")) {
+		t.Errorf("record data was overwritten: %q", r.Data)
+	}
+}
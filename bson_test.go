@@ -0,0 +1,80 @@
+package bolt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestSetKeyReplacer(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewJSONHandler(&buf)).SetKeyReplacer(MongoKeyReplacer)
+
+	child := logger.With().Str("svc.name", "api").Logger()
+	child.Info().
+		Str("$where", "x").
+		Int("http.status", 200).
+		Dict("req.meta", func(d *Event) { d.Str("a.b", "c") }).
+		Msg("ok")
+
+	want := `{"level":"info","svc_name":"api","_where":"x","http_status":200,"req_meta":{"a_b":"c"},"message":"ok"}` + "\n"
+	if buf.String() != want {
+		t.Errorf("got  %s\nwant %s", buf.String(), want)
+	}
+
+	buf.Reset()
+	logger.SetKeyReplacer(nil)
+	logger.Info().Str("a.b", "c").Msg("")
+	if !bytes.Contains(buf.Bytes(), []byte(`"a.b":"c"`)) {
+		t.Errorf("replacer not disabled: %s", buf.String())
+	}
+}
+
+func TestBSONHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewBSONHandler(&buf))
+	logger.Info().Str("a.b", "x").Int("n", -2).Bool("ok", true).Msg("hi")
+
+	var want []byte
+	want = append(want, 0, 0, 0, 0)
+	want = append(want, bsonString)
+	want = append(want, "level\x00"...)
+	want = append(want, 5, 0, 0, 0)
+	want = append(want, "info\x00"...)
+	want = append(want, bsonString)
+	want = append(want, "a_b\x00"...)
+	want = append(want, 2, 0, 0, 0)
+	want = append(want, "x\x00"...)
+	want = append(want, bsonInt64)
+	want = append(want, "n\x00"...)
+	want = binary.LittleEndian.AppendUint64(want, ^uint64(1))
+	want = append(want, bsonBool)
+	want = append(want, "ok\x00"...)
+	want = append(want, 1)
+	want = append(want, bsonString)
+	want = append(want, "message\x00"...)
+	want = append(want, 3, 0, 0, 0)
+	want = append(want, "hi\x00"...)
+	want = append(want, 0)
+	binary.LittleEndian.PutUint32(want, uint32(len(want)))
+
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("got  %x\nwant %x", buf.Bytes(), want)
+	}
+}
+
+func TestBSONHandler_NestedAndFloat(t *testing.T) {
+	doc, err := appendBSON(nil, []byte(`{"d":{"$k":[1.5,null]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := int(binary.LittleEndian.Uint32(doc)); got != len(doc) {
+		t.Fatalf("document length prefix = %d; want %d", got, len(doc))
+	}
+	if !bytes.Contains(doc, []byte("_k\x00")) {
+		t.Errorf("nested key not escaped: %q", doc)
+	}
+	if !bytes.Contains(doc, []byte{bsonDouble, '0', 0}) || !bytes.Contains(doc, []byte{bsonNull, '1', 0}) {
+		t.Errorf("array elements not encoded with index names: %q", doc)
+	}
+}
@@ -0,0 +1,88 @@
+package bolt
+
+import (
+	"path"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CallerOptions controls the fields added by [Event.Caller] and
+// [Event.CallerSkip]. A nil *CallerOptions uses the defaults.
+type CallerOptions struct {
+	// Function additionally logs the calling function under "func", as
+	// the package name followed by the function ("httplog.Middleware.func1").
+	Function bool
+
+	// Always adds the caller fields to every event, as if each one
+	// called [Event.Caller]. Each event then pays for a
+	// [runtime.Caller] lookup and a few allocations.
+	Always bool
+}
+
+// SetCallerOptions configures the caller fields. Pass nil to restore the
+// defaults. Like SetKeyReplacer, it is intended for setup-time
+// configuration and is inherited by child loggers.
+func (l *Logger) SetCallerOptions(opts *CallerOptions) *Logger {
+	l.callerOpts = opts
+	return l
+}
+
+// mainModule is the main module's path, read once from the build info.
+var mainModule = sync.OnceValue(func() string {
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		return bi.Main.Path
+	}
+	return ""
+})
+
+// addCaller adds the caller fields for the frame skip levels above its
+// own caller.
+func (e *Event) addCaller(skip int) *Event {
+	pc, file, line, ok := runtime.Caller(skip + 2)
+	if !ok {
+		return e.Str("caller", "unknown")
+	}
+	var fn string
+	if f := runtime.FuncForPC(pc); f != nil {
+		fn = f.Name()
+	}
+	e = e.Str("caller", callerPath(file, fn)+":"+strconv.Itoa(line))
+	if opts := e.l.callerOpts; opts != nil && opts.Function && fn != "" {
+		e = e.Str("func", fn[strings.LastIndexByte(fn, '/')+1:])
+	}
+	return e
+}
+
+// callerPath turns the absolute source path of a frame into one that is
+// stable across build machines: relative to the main module root for the
+// module's own packages ("httplog/httplog.go", "main.go") and prefixed
+// with the import path for everything else ("net/http/server.go").
+func callerPath(file, fn string) string {
+	base := path.Base(file)
+	mod := mainModule()
+
+	// Built with -trimpath: the file is already module-qualified.
+	if mod != "" && strings.HasPrefix(file, mod+"/") {
+		return file[len(mod)+1:]
+	}
+
+	// Otherwise derive the directory from the function's import path.
+	slash := strings.LastIndexByte(fn, '/')
+	dot := strings.IndexByte(fn[slash+1:], '.')
+	if dot < 0 {
+		return base
+	}
+	pkg := fn[:slash+1+dot]
+	switch {
+	case pkg == "main" || pkg == mod:
+		// Package main's import path says nothing about its directory.
+		return base
+	case mod != "" && strings.HasPrefix(pkg, mod+"/"):
+		return pkg[len(mod)+1:] + "/" + base
+	default:
+		return pkg + "/" + base
+	}
+}
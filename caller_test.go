@@ -0,0 +1,55 @@
+package bolt
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCallerPath(t *testing.T) {
+	mod := mainModule()
+	if mod != "go.klarlabs.de/bolt" {
+		t.Skipf("main module is %q", mod)
+	}
+	tests := []struct {
+		file, fn, want string
+	}{
+		{"/home/ci/bolt/event.go", "go.klarlabs.de/bolt.(*Event).Msg", "event.go"},
+		{"/build/src/bolt/httplog/httplog.go", "go.klarlabs.de/bolt/httplog.Middleware.func1", "httplog/httplog.go"},
+		{"/usr/local/go/src/net/http/server.go", "net/http.(*conn).serve", "net/http/server.go"},
+		{"/root/go/pkg/mod/github.com/x/y@v1.2.3/z/z.go", "github.com/x/y/z.F[...]", "github.com/x/y/z/z.go"},
+		{"/src/app/cmd/tool/main.go", "main.main", "main.go"},
+		{"go.klarlabs.de/bolt/cmd/bolt/main.go", "main.run", "cmd/bolt/main.go"},
+		{"/tmp/x.go", "", "x.go"},
+	}
+	for _, tt := range tests {
+		if got := callerPath(tt.file, tt.fn); got != tt.want {
+			t.Errorf("callerPath(%q, %q) = %q, want %q", tt.file, tt.fn, got, tt.want)
+		}
+	}
+}
+
+func TestCallerFunction(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewJSONHandler(&buf))
+
+	logger.Info().Caller().Msg("plain")
+	if strings.Contains(buf.String(), `"func"`) {
+		t.Errorf("func logged without CallerOptions.Function: %s", buf.String())
+	}
+
+	buf.Reset()
+	logger.SetCallerOptions(&CallerOptions{Function: true})
+	logger.With().Str("k", "v").Logger().Info().Caller().Msg("with func")
+	var rec struct{ Caller, Func string }
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(rec.Caller, "caller_test.go:") {
+		t.Errorf("caller = %q", rec.Caller)
+	}
+	if rec.Func != "bolt.TestCallerFunction" {
+		t.Errorf("func = %q, want bolt.TestCallerFunction", rec.Func)
+	}
+}
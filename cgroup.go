@@ -0,0 +1,277 @@
+package bolt
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// cgroupRoot is where the cgroup filesystem is mounted. Overridable for tests.
+var cgroupRoot = "/sys/fs/cgroup"
+
+// procSelf is the /proc directory of the current process. Overridable for
+// tests.
+var procSelf = "/proc/self"
+
+// cgroupV1Unlimited is the smallest value treated as "no limit" in cgroup
+// v1 memory.limit_in_bytes, which reports a page-aligned near-MaxInt64
+// number instead of a sentinel.
+const cgroupV1Unlimited = 1 << 62
+
+// ContainerLimits describes the resource limits imposed on the process by
+// its cgroup. Host-level figures such as runtime.NumCPU or total system
+// memory overstate what a containerized process can actually use, so
+// thresholds should be derived from these values when they are set.
+type ContainerLimits struct {
+	// CgroupVersion is 1 or 2, or 0 when no cgroup filesystem was found.
+	CgroupVersion int
+	// CPUQuota is the CPU limit in cores (e.g. 1.5), or 0 if unlimited.
+	CPUQuota float64
+	// MemoryLimit is the memory limit in bytes, or 0 if unlimited.
+	MemoryLimit int64
+}
+
+// DetectContainerLimits reads the CPU and memory limits of the current
+// process's cgroup, supporting both the v2 unified hierarchy and v1
+// controllers. The cgroup is found through /proc/self/cgroup and
+// /proc/self/mountinfo, so limits are right for systemd services and
+// runtimes without a private cgroup namespace too; the tightest limit of
+// the cgroup and its ancestors wins. On platforms without cgroups it
+// returns the zero value.
+func DetectContainerLimits() ContainerLimits {
+	return detectContainerLimits(cgroupRoot, procSelf)
+}
+
+func detectContainerLimits(root, proc string) ContainerLimits {
+	var l ContainerLimits
+	switch {
+	case fileExists(filepath.Join(root, "cgroup.controllers")):
+		l.CgroupVersion = 2
+		if dir, top, ok := ownCgroupDir(root, proc, ""); ok {
+			l.CPUQuota, _ = cgroupLimit(dir, top, cpuMaxV2)
+			l.MemoryLimit, _ = cgroupLimit(dir, top, memoryMaxV2)
+		}
+	case fileExists(filepath.Join(root, "cpu")) || fileExists(filepath.Join(root, "memory")):
+		l.CgroupVersion = 1
+		if dir, top, ok := ownCgroupDir(root, proc, "cpu"); ok {
+			l.CPUQuota, _ = cgroupLimit(dir, top, cpuQuotaV1)
+		}
+		if dir, top, ok := ownCgroupDir(root, proc, "memory"); ok {
+			l.MemoryLimit, _ = cgroupLimit(dir, top, memoryLimitV1)
+		}
+	}
+	return l
+}
+
+// ownCgroupDir returns the directory of the process's cgroup in the v2
+// hierarchy (controller "") or the v1 hierarchy of controller, and the
+// mount point above which limits are not inherited. It maps the path in
+// proc/cgroup through the mount's root in proc/mountinfo. Without those
+// files it falls back to the hierarchy's mount point under root; it
+// reports false when the process's cgroup is not visible under the mount.
+func ownCgroupDir(root, proc, controller string) (dir, top string, ok bool) {
+	fallback := root
+	if controller != "" {
+		fallback = filepath.Join(root, controller)
+	}
+	path, okPath := ownCgroup(proc, controller)
+	mountRoot, mountPoint, okMount := cgroupMount(proc, root, controller)
+	if !okPath || !okMount {
+		return fallback, fallback, true
+	}
+	rel, ok := strings.CutPrefix(path, strings.TrimSuffix(mountRoot, "/"))
+	if !ok || (rel != "" && rel[0] != '/') {
+		return "", "", false
+	}
+	mountPoint = filepath.Clean(mountPoint)
+	return filepath.Join(mountPoint, rel), mountPoint, true
+}
+
+// ownCgroup returns the process's cgroup path from proc/cgroup, whose
+// lines read "hierarchy-ID:controller-list:path". The v2 hierarchy
+// (controller "") is the line "0::path".
+func ownCgroup(proc, controller string) (string, bool) {
+	b, err := os.ReadFile(filepath.Join(proc, "cgroup")) // #nosec G304 -- fixed procfs path
+	if err != nil {
+		return "", false
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if controller == "" {
+			if parts[0] == "0" && parts[1] == "" {
+				return parts[2], true
+			}
+		} else if slices.Contains(strings.Split(parts[1], ","), controller) {
+			return parts[2], true
+		}
+	}
+	return "", false
+}
+
+// cgroupMount returns the root and mount point of the cgroup2 mount
+// (controller "") or the v1 cgroup mount of controller at or below root,
+// from proc/mountinfo.
+func cgroupMount(proc, root, controller string) (mountRoot, mountPoint string, ok bool) {
+	b, err := os.ReadFile(filepath.Join(proc, "mountinfo")) // #nosec G304 -- fixed procfs path
+	if err != nil {
+		return "", "", false
+	}
+	root = filepath.Clean(root)
+	fstype := "cgroup2"
+	if controller != "" {
+		fstype = "cgroup"
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		// id parent major:minor root mount-point options [optional...] - fstype source super-options
+		fields := strings.Fields(line)
+		sep := slices.Index(fields, "-")
+		if sep < 6 || len(fields) < sep+4 || fields[sep+1] != fstype {
+			continue
+		}
+		if controller != "" && !slices.Contains(strings.Split(fields[sep+3], ","), controller) {
+			continue
+		}
+		mp := filepath.Clean(unescapeMountinfo(fields[4]))
+		if mp == root || strings.HasPrefix(mp, root+"/") {
+			return unescapeMountinfo(fields[3]), mp, true
+		}
+	}
+	return "", "", false
+}
+
+// unescapeMountinfo decodes the \ooo octal escapes mountinfo uses for
+// spaces and other special characters in paths.
+func unescapeMountinfo(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if n, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// cgroupLimit returns the tightest limit read from dir and its ancestors
+// up to top, since a cgroup is bound by every cgroup above it.
+func cgroupLimit[T int64 | float64](dir, top string, read func(dir string) (T, bool)) (T, bool) {
+	var limit T
+	found := false
+	for {
+		if v, ok := read(dir); ok && (!found || v < limit) {
+			limit, found = v, true
+		}
+		if dir == top || !strings.HasPrefix(dir, top+"/") {
+			return limit, found
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// appendFields adds the non-zero limits to e.
+func (l ContainerLimits) appendFields(e *Event) *Event {
+	if l.CgroupVersion == 0 {
+		return e
+	}
+	e = e.Int("cgroup_version", l.CgroupVersion)
+	if l.CPUQuota > 0 {
+		e = e.Float64("cgroup_cpu_quota", l.CPUQuota)
+	}
+	if l.MemoryLimit > 0 {
+		e = e.Int64("cgroup_memory_limit", l.MemoryLimit)
+	}
+	return e
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// cpuMaxV2 returns the CPU limit in cores from the cgroup v2 cpu.max
+// file in dir. It reports false when no limit is set.
+func cpuMaxV2(dir string) (float64, bool) {
+	b, err := os.ReadFile(filepath.Join(dir, "cpu.max")) // #nosec G304 -- cgroupfs path
+	if err != nil {
+		return 0, false
+	}
+	return parseCPUMax(string(b))
+}
+
+// cpuQuotaV1 returns the CPU limit in cores from the cgroup v1 CFS files
+// in dir. It reports false when no limit is set.
+func cpuQuotaV1(dir string) (float64, bool) {
+	quota, err := readCgroupInt(filepath.Join(dir, "cpu.cfs_quota_us"))
+	if err != nil || quota <= 0 {
+		return 0, false
+	}
+	period, err := readCgroupInt(filepath.Join(dir, "cpu.cfs_period_us"))
+	if err != nil || period <= 0 {
+		return 0, false
+	}
+	return float64(quota) / float64(period), true
+}
+
+// parseCPUMax parses the cgroup v2 cpu.max format "$MAX $PERIOD", where
+// $MAX may be the literal "max" for no limit.
+func parseCPUMax(s string) (float64, bool) {
+	fields := strings.Fields(s)
+	if len(fields) != 2 || fields[0] == "max" {
+		return 0, false
+	}
+	quota, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil || quota <= 0 {
+		return 0, false
+	}
+	period, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil || period <= 0 {
+		return 0, false
+	}
+	return quota / period, true
+}
+
+func readCgroupInt(path string) (int64, error) {
+	b, err := os.ReadFile(path) // #nosec G304 -- cgroupfs path
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(b)), 10, 64)
+}
+
+// memoryMaxV2 returns the memory limit in bytes from the cgroup v2
+// memory.max file in dir. It reports false when no limit is set.
+func memoryMaxV2(dir string) (int64, bool) {
+	b, err := os.ReadFile(filepath.Join(dir, "memory.max")) // #nosec G304 -- cgroupfs path
+	if err != nil {
+		return 0, false
+	}
+	s := strings.TrimSpace(string(b))
+	if s == "max" {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	return n, err == nil && n > 0
+}
+
+// memoryLimitV1 returns the memory limit in bytes from the cgroup v1
+// memory.limit_in_bytes file in dir. It reports false when no limit is
+// set.
+func memoryLimitV1(dir string) (int64, bool) {
+	n, err := readCgroupInt(filepath.Join(dir, "memory.limit_in_bytes"))
+	if err != nil || n <= 0 || n >= cgroupV1Unlimited {
+		return 0, false
+	}
+	return n, true
+}
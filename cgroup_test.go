@@ -0,0 +1,169 @@
+package bolt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeCgroupFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseCPUMax(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+		ok   bool
+	}{
+		{"200000 100000\n", 2, true},
+		{"50000 100000", 0.5, true},
+		{"max 100000", 0, false},
+		{"garbage", 0, false},
+		{"0 100000", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseCPUMax(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseCPUMax(%q) = %v, %v; want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCgroupCPUQuota_V1(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) {
+		writeCgroupFile(t, filepath.Join(root, "cpu", name), content)
+	}
+	write("cpu.cfs_period_us", "100000\n")
+
+	write("cpu.cfs_quota_us", "-1\n")
+	if _, ok := cpuQuotaV1(filepath.Join(root, "cpu")); ok {
+		t.Error("unlimited v1 quota reported as a limit")
+	}
+
+	write("cpu.cfs_quota_us", "150000\n")
+	if q, ok := cpuQuotaV1(filepath.Join(root, "cpu")); !ok || q != 1.5 {
+		t.Errorf("v1 quota = %v, %v; want 1.5", q, ok)
+	}
+}
+
+func TestDetectContainerLimits(t *testing.T) {
+	t.Run("v2", func(t *testing.T) {
+		root := t.TempDir()
+		writeCgroupFile(t, filepath.Join(root, "cgroup.controllers"), "cpu memory\n")
+		writeCgroupFile(t, filepath.Join(root, "cpu.max"), "250000 100000\n")
+		writeCgroupFile(t, filepath.Join(root, "memory.max"), "536870912\n")
+
+		got := detectContainerLimits(root, t.TempDir())
+		want := ContainerLimits{CgroupVersion: 2, CPUQuota: 2.5, MemoryLimit: 512 << 20}
+		if got != want {
+			t.Errorf("got %+v; want %+v", got, want)
+		}
+	})
+
+	t.Run("v2 unlimited", func(t *testing.T) {
+		root := t.TempDir()
+		writeCgroupFile(t, filepath.Join(root, "cgroup.controllers"), "")
+		writeCgroupFile(t, filepath.Join(root, "cpu.max"), "max 100000\n")
+		writeCgroupFile(t, filepath.Join(root, "memory.max"), "max\n")
+
+		if got := detectContainerLimits(root, t.TempDir()); got != (ContainerLimits{CgroupVersion: 2}) {
+			t.Errorf("got %+v; want version only", got)
+		}
+	})
+
+	t.Run("v1", func(t *testing.T) {
+		root := t.TempDir()
+		writeCgroupFile(t, filepath.Join(root, "cpu", "cpu.cfs_quota_us"), "-1\n")
+		writeCgroupFile(t, filepath.Join(root, "cpu", "cpu.cfs_period_us"), "100000\n")
+		writeCgroupFile(t, filepath.Join(root, "memory", "memory.limit_in_bytes"), "9223372036854771712\n")
+
+		if got := detectContainerLimits(root, t.TempDir()); got != (ContainerLimits{CgroupVersion: 1}) {
+			t.Errorf("unlimited v1: got %+v", got)
+		}
+
+		writeCgroupFile(t, filepath.Join(root, "memory", "memory.limit_in_bytes"), "268435456\n")
+		if got := detectContainerLimits(root, t.TempDir()); got.MemoryLimit != 256<<20 {
+			t.Errorf("v1 memory limit = %d", got.MemoryLimit)
+		}
+	})
+
+	t.Run("none", func(t *testing.T) {
+		if got := detectContainerLimits(t.TempDir(), t.TempDir()); got != (ContainerLimits{}) {
+			t.Errorf("got %+v; want zero value", got)
+		}
+	})
+}
+
+func TestDetectContainerLimitsOwnCgroup(t *testing.T) {
+	t.Run("v2 systemd service", func(t *testing.T) {
+		root, proc := t.TempDir(), t.TempDir()
+		writeCgroupFile(t, filepath.Join(root, "cgroup.controllers"), "cpu memory\n")
+		writeCgroupFile(t, filepath.Join(root, "system.slice", "memory.max"), "1073741824\n")
+		writeCgroupFile(t, filepath.Join(root, "system.slice", "app.service", "memory.max"), "max\n")
+		writeCgroupFile(t, filepath.Join(root, "system.slice", "app.service", "cpu.max"), "50000 100000\n")
+		writeCgroupFile(t, filepath.Join(root, "user.slice", "cpu.max"), "400000 100000\n")
+		writeCgroupFile(t, filepath.Join(proc, "cgroup"), "0::/system.slice/app.service\n")
+		writeCgroupFile(t, filepath.Join(proc, "mountinfo"),
+			"22 1 0:21 / /proc rw - proc proc rw\n"+
+				"30 23 0:26 / "+root+" rw,nosuid,nodev,noexec shared:4 - cgroup2 cgroup2 rw,nsdelegate\n")
+
+		got := detectContainerLimits(root, proc)
+		want := ContainerLimits{CgroupVersion: 2, CPUQuota: 0.5, MemoryLimit: 1 << 30}
+		if got != want {
+			t.Errorf("got %+v; want %+v", got, want)
+		}
+	})
+
+	t.Run("v2 namespaced mount", func(t *testing.T) {
+		root, proc := t.TempDir(), t.TempDir()
+		writeCgroupFile(t, filepath.Join(root, "cgroup.controllers"), "cpu memory\n")
+		writeCgroupFile(t, filepath.Join(root, "memory.max"), "268435456\n")
+		writeCgroupFile(t, filepath.Join(proc, "cgroup"), "0::/kubepods/pod1/c1\n")
+		writeCgroupFile(t, filepath.Join(proc, "mountinfo"),
+			"30 23 0:26 /kubepods/pod1/c1 "+root+" ro - cgroup2 cgroup2 rw\n")
+
+		if got := detectContainerLimits(root, proc); got.MemoryLimit != 256<<20 {
+			t.Errorf("got %+v; want the mount root's limit", got)
+		}
+	})
+
+	t.Run("v1", func(t *testing.T) {
+		root, proc := t.TempDir(), t.TempDir()
+		writeCgroupFile(t, filepath.Join(root, "cpu,cpuacct", "cpu.cfs_quota_us"), "-1\n")
+		writeCgroupFile(t, filepath.Join(root, "cpu,cpuacct", "docker", "abc", "cpu.cfs_quota_us"), "200000\n")
+		writeCgroupFile(t, filepath.Join(root, "cpu,cpuacct", "docker", "abc", "cpu.cfs_period_us"), "100000\n")
+		writeCgroupFile(t, filepath.Join(root, "memory", "memory.limit_in_bytes"), "9223372036854771712\n")
+		writeCgroupFile(t, filepath.Join(root, "memory", "docker", "abc", "memory.limit_in_bytes"), "268435456\n")
+		writeCgroupFile(t, filepath.Join(proc, "cgroup"), "5:memory:/docker/abc\n4:cpu,cpuacct:/docker/abc\n1:name=systemd:/docker/abc\n")
+		writeCgroupFile(t, filepath.Join(proc, "mountinfo"),
+			"31 25 0:27 / "+root+"/cpu\\054cpuacct rw - cgroup cgroup rw,cpu,cpuacct\n"+
+				"32 25 0:28 / "+root+"/memory rw - cgroup cgroup rw,memory\n")
+
+		got := detectContainerLimits(root, proc)
+		want := ContainerLimits{CgroupVersion: 1, CPUQuota: 2, MemoryLimit: 256 << 20}
+		if got != want {
+			t.Errorf("got %+v; want %+v", got, want)
+		}
+	})
+
+	t.Run("not visible", func(t *testing.T) {
+		root, proc := t.TempDir(), t.TempDir()
+		writeCgroupFile(t, filepath.Join(root, "cgroup.controllers"), "cpu memory\n")
+		writeCgroupFile(t, filepath.Join(root, "memory.max"), "268435456\n")
+		writeCgroupFile(t, filepath.Join(proc, "cgroup"), "0::/other\n")
+		writeCgroupFile(t, filepath.Join(proc, "mountinfo"),
+			"30 23 0:26 /kubepods/pod1 "+root+" ro - cgroup2 cgroup2 rw\n")
+
+		if got := detectContainerLimits(root, proc); got != (ContainerLimits{CgroupVersion: 2}) {
+			t.Errorf("got %+v; want version only", got)
+		}
+	})
+}
@@ -0,0 +1,219 @@
+// Command bolt-benchmark compares the benchmarks of two git refs of a
+// repository, such as two release tags, and writes a signed report.
+//
+// Usage:
+//
+//	bolt-benchmark -compare v1.2.0..v1.3.0 [-bench REGEXP] [-count N]
+//	    [-benchtime D] [-dir DIR] [-pkg PATTERN] [-out FILE]
+//	    [-key FILE | -unsigned]
+//	bolt-benchmark -verify FILE -pub FILE
+//
+// Each ref is checked out into its own temporary worktree and
+// benchmarked with "go test -run=^$ -bench" using a fresh module cache,
+// so neither run can pick up dependencies downloaded for the other. The
+// build cache is shared; its entries are keyed by content. The report (-out, default bench-compare.json) lists
+// every benchmark with the median of each metric for both refs and the
+// change between them; the raw "go test" outputs are kept next to it as
+// FILE.base.txt and FILE.head.txt for benchstat.
+//
+// The report is signed with an Ed25519 private key in PEM (PKCS #8)
+// form, as generated by "openssl genpkey -algorithm ed25519", read from
+// -key or from the BOLT_BENCH_SIGNING_KEY environment variable. The
+// base64 signature is written to FILE.sig. -verify checks a report
+// against its signature with the matching PEM public key.
+//
+// Exit status is 0 on success, 1 when -verify finds a bad signature, and
+// 2 on usage, git, build or I/O errors.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"go.klarlabs.de/bolt/benchkit"
+)
+
+// Exit codes.
+const (
+	exitOK     = 0
+	exitBadSig = 1
+	exitError  = 2
+)
+
+// signingKeyEnv holds the PEM signing key when -key is not given, as CI
+// secrets usually are.
+const signingKeyEnv = "BOLT_BENCH_SIGNING_KEY"
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("bolt-benchmark", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	compare := fs.String("compare", "", "refs to compare, as BASE..HEAD")
+	bench := fs.String("bench", ".", "benchmarks to run (go test -bench)")
+	count := fs.Int("count", 5, "runs per benchmark (go test -count)")
+	benchtime := fs.String("benchtime", "500ms", "time per run (go test -benchtime)")
+	dir := fs.String("dir", ".", "module directory within the repository")
+	pkg := fs.String("pkg", ".", "packages to benchmark")
+	out := fs.String("out", "bench-compare.json", "report file")
+	keyFile := fs.String("key", "", "Ed25519 private key (PEM) to sign the report; defaults to $"+signingKeyEnv)
+	unsigned := fs.Bool("unsigned", false, "write the report without a signature")
+	verify := fs.String("verify", "", "verify a report's signature instead of running benchmarks")
+	pubFile := fs.String("pub", "", "Ed25519 public key (PEM) for -verify")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: bolt-benchmark -compare BASE..HEAD [flags]")
+		fmt.Fprintln(stderr, "       bolt-benchmark -verify FILE -pub FILE")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+
+	if *verify != "" {
+		if err := verifyReport(*verify, *pubFile); err != nil {
+			fmt.Fprintf(stderr, "bolt-benchmark: %v\n", err)
+			if errors.Is(err, errBadSignature) {
+				return exitBadSig
+			}
+			return exitError
+		}
+		fmt.Fprintf(stdout, "%s: signature OK\n", *verify)
+		return exitOK
+	}
+
+	base, head, ok := strings.Cut(*compare, "..")
+	if !ok || base == "" || head == "" {
+		fs.Usage()
+		return exitError
+	}
+	var key []byte
+	if !*unsigned {
+		var err error
+		if key, err = readSigningKey(*keyFile); err != nil {
+			fmt.Fprintf(stderr, "bolt-benchmark: %v (use -unsigned to skip signing)\n", err)
+			return exitError
+		}
+	}
+
+	cfg := benchConfig{bench: *bench, count: *count, benchtime: *benchtime, dir: *dir, pkg: *pkg}
+	rep := &report{
+		Bench:     cfg.bench,
+		Count:     cfg.count,
+		Benchtime: cfg.benchtime,
+		Go:        runtime.Version(),
+		GOOS:      runtime.GOOS,
+		GOARCH:    runtime.GOARCH,
+		Created:   time.Now().UTC(),
+	}
+	var outputs [2][]byte
+	var results [2]benchkit.Results
+	for i, ref := range []string{base, head} {
+		commit, output, err := benchmarkRef(ref, cfg, stderr)
+		if err != nil {
+			fmt.Fprintf(stderr, "bolt-benchmark: %s: %v\n", ref, err)
+			return exitError
+		}
+		outputs[i] = output
+		if results[i], err = benchkit.ParseResults(bytes.NewReader(output)); err != nil {
+			fmt.Fprintf(stderr, "bolt-benchmark: %s: %v\n", ref, err)
+			return exitError
+		}
+		r := refInfo{Ref: ref, Commit: commit}
+		if i == 0 {
+			rep.Base = r
+		} else {
+			rep.Head = r
+		}
+	}
+	rep.CPU = results[1].CPU
+	rep.Benchmarks = benchkit.Compare(results[0], results[1])
+
+	data, err := rep.marshal()
+	if err == nil {
+		err = writeReport(*out, data, outputs, key)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "bolt-benchmark: %v\n", err)
+		return exitError
+	}
+	rep.writeTable(stdout)
+	return exitOK
+}
+
+// benchConfig holds the go test settings shared by both runs.
+type benchConfig struct {
+	bench, benchtime, dir, pkg string
+	count                      int
+}
+
+// benchmarkRef checks ref out into a temporary worktree and runs the
+// benchmarks there with an isolated module cache. It returns the
+// resolved commit and the go test output.
+func benchmarkRef(ref string, cfg benchConfig, stderr io.Writer) (string, []byte, error) {
+	commit, err := git("rev-parse", "--verify", ref+"^{commit}")
+	if err != nil {
+		return "", nil, err
+	}
+	tmp, err := os.MkdirTemp("", "bolt-benchmark-")
+	if err != nil {
+		return "", nil, err
+	}
+	defer os.RemoveAll(tmp)
+	tree := filepath.Join(tmp, "src")
+	if _, err := git("worktree", "add", "--detach", tree, commit); err != nil {
+		return "", nil, err
+	}
+	defer git("worktree", "remove", "--force", tree) //nolint:errcheck // best effort; tmp is removed anyway
+
+	fmt.Fprintf(stderr, "bolt-benchmark: benchmarking %s (%.12s)\n", ref, commit)
+	cmd := exec.Command("go", "test", "-run=^$", "-bench="+cfg.bench, "-benchmem", // #nosec G204 -- operator-supplied flags
+		fmt.Sprintf("-count=%d", cfg.count), "-benchtime="+cfg.benchtime, cfg.pkg)
+	cmd.Dir = filepath.Join(tree, cfg.dir)
+	cmd.Env = append(os.Environ(),
+		"GOMODCACHE="+filepath.Join(tmp, "modcache"),
+		"GOFLAGS=-modcacherw", // lets RemoveAll delete the module cache
+	)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return "", nil, fmt.Errorf("go test: %w\n%s", err, output.Bytes())
+	}
+	return commit, output.Bytes(), nil
+}
+
+// git runs a git command in the current directory and returns its
+// trimmed output.
+func git(args ...string) (string, error) {
+	cmd := exec.Command("git", args...) // #nosec G204 -- fixed command, operator-supplied refs
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// readSigningKey returns the PEM signing key from file, or from the
+// environment when file is empty.
+func readSigningKey(file string) ([]byte, error) {
+	if file != "" {
+		return os.ReadFile(file) // #nosec G304 -- operator-supplied path
+	}
+	if k := os.Getenv(signingKeyEnv); k != "" {
+		return []byte(k), nil
+	}
+	return nil, fmt.Errorf("no signing key: set -key or $%s", signingKeyEnv)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeKeyPair writes a PEM Ed25519 key pair to dir.
+func writeKeyPair(t *testing.T, dir string) (priv, pub string) {
+	t.Helper()
+	pk, sk, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	skDER, _ := x509.MarshalPKCS8PrivateKey(sk)
+	pkDER, _ := x509.MarshalPKIXPublicKey(pk)
+	priv, pub = filepath.Join(dir, "key.pem"), filepath.Join(dir, "key.pub")
+	os.WriteFile(priv, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: skDER}), 0o600)
+	os.WriteFile(pub, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pkDER}), 0o644)
+	return priv, pub
+}
+
+func TestSignAndVerify(t *testing.T) {
+	dir := t.TempDir()
+	priv, pub := writeKeyPair(t, dir)
+	key, _ := os.ReadFile(priv)
+	out := filepath.Join(dir, "report.json")
+	if err := writeReport(out, []byte(`{"ok":true}`+"\n"), [2][]byte{[]byte("a"), []byte("b")}, key); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-verify", out, "-pub", pub}, &stdout, &stderr); code != exitOK {
+		t.Fatalf("verify exit %d: %s", code, stderr.String())
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "report.head.txt")); string(b) != "b" {
+		t.Errorf("head output = %q", b)
+	}
+
+	os.WriteFile(out, []byte(`{"ok":false}`+"\n"), 0o644)
+	if code := run([]string{"-verify", out, "-pub", pub}, &stdout, &stderr); code != exitBadSig {
+		t.Errorf("tampered report: exit %d, want %d", code, exitBadSig)
+	}
+}
+
+func TestCompareRefs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go test twice")
+	}
+	repo := t.TempDir()
+	gitIn := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, body string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repo, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gitIn("init", "-q")
+	write("go.mod", "module example.com/b\n\ngo 1.25\n")
+	write("b_test.go", "package b\n\nimport \"testing\"\n\nfunc BenchmarkA(b *testing.B) {\n\tfor range b.N {\n\t}\n}\n")
+	gitIn("add", ".")
+	gitIn("commit", "-qm", "one")
+	gitIn("tag", "v1")
+	write("c_test.go", "package b\n\nimport \"testing\"\n\nfunc BenchmarkB(b *testing.B) {\n\tfor range b.N {\n\t}\n}\n")
+	gitIn("add", ".")
+	gitIn("commit", "-qm", "two")
+	gitIn("tag", "v2")
+
+	dir := t.TempDir()
+	priv, pub := writeKeyPair(t, dir)
+	out := filepath.Join(dir, "cmp.json")
+	t.Chdir(repo)
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-compare", "v1..v2", "-count", "2", "-benchtime", "10x", "-key", priv, "-out", out}, &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("exit %d: %s", code, stderr.String())
+	}
+
+	var rep report
+	data, _ := os.ReadFile(out)
+	if err := json.Unmarshal(data, &rep); err != nil {
+		t.Fatal(err)
+	}
+	if rep.Base.Ref != "v1" || rep.Head.Ref != "v2" || len(rep.Base.Commit) != 40 {
+		t.Errorf("refs = %+v, %+v", rep.Base, rep.Head)
+	}
+	if len(rep.Benchmarks) != 2 || rep.Benchmarks[1].Metrics[0].BaseN != 0 || rep.Benchmarks[1].Metrics[0].HeadN != 2 {
+		t.Errorf("benchmarks = %+v, want A on both refs and B only on v2", rep.Benchmarks)
+	}
+	if code := run([]string{"-verify", out, "-pub", pub}, &stdout, &stderr); code != exitOK {
+		t.Errorf("verify exit %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "v1 (") {
+		t.Errorf("table missing header:\n%s", stdout.String())
+	}
+}
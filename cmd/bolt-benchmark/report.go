@@ -0,0 +1,173 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"go.klarlabs.de/bolt/benchkit"
+)
+
+// errBadSignature is returned by verifyReport when the signature does
+// not match.
+var errBadSignature = errors.New("signature does not match report")
+
+// report is the comparison written to -out.
+type report struct {
+	Base       refInfo          `json:"base"`
+	Head       refInfo          `json:"head"`
+	Bench      string           `json:"bench"`
+	Count      int              `json:"count"`
+	Benchtime  string           `json:"benchtime"`
+	Go         string           `json:"go"`
+	GOOS       string           `json:"goos"`
+	GOARCH     string           `json:"goarch"`
+	CPU        string           `json:"cpu,omitempty"`
+	Created    time.Time        `json:"created"`
+	Benchmarks []benchkit.Delta `json:"benchmarks"`
+}
+
+// refInfo identifies one side of the comparison.
+type refInfo struct {
+	Ref    string `json:"ref"`
+	Commit string `json:"commit"`
+}
+
+func (r *report) marshal() ([]byte, error) {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// writeTable prints the ns/op, B/op and allocs/op comparison.
+func (r *report) writeTable(w io.Writer) {
+	fmt.Fprintf(w, "%s (%.12s) vs %s (%.12s)\n", r.Base.Ref, r.Base.Commit, r.Head.Ref, r.Head.Commit)
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "benchmark\tunit\tbase\thead\tdelta\t")
+	for _, b := range r.Benchmarks {
+		for _, m := range b.Metrics {
+			if benchkit.UnitRank(m.Unit) > 2 {
+				continue
+			}
+			delta := "~"
+			if m.DeltaPct != nil {
+				delta = fmt.Sprintf("%+.2f%%", *m.DeltaPct)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t\n", b.Name, m.Unit, formatSample(m.Base, m.BaseN), formatSample(m.Head, m.HeadN), delta)
+		}
+	}
+	tw.Flush()
+}
+
+func formatSample(v float64, n int) string {
+	if n == 0 {
+		return "-"
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// writeReport writes the report, the raw outputs and, given a key, the
+// signature.
+func writeReport(path string, data []byte, outputs [2][]byte, key []byte) error {
+	stem := strings.TrimSuffix(path, ".json")
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{path, data},
+		{stem + ".base.txt", outputs[0]},
+		{stem + ".head.txt", outputs[1]},
+		{path + ".sig", nil},
+	}
+	if key != nil {
+		priv, err := parsePrivateKey(key)
+		if err != nil {
+			return err
+		}
+		files[3].data = []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(priv, data)) + "\n")
+	} else {
+		files = files[:3]
+	}
+	for _, f := range files {
+		if err := os.WriteFile(f.name, f.data, 0o644); err != nil { // #nosec G306 -- report artifacts are public
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyReport checks the signature in path+".sig" against the report
+// with the PEM public key in pubFile.
+func verifyReport(path, pubFile string) error {
+	if pubFile == "" {
+		return errors.New("-verify needs -pub")
+	}
+	data, err := os.ReadFile(path) // #nosec G304 -- operator-supplied path
+	if err != nil {
+		return err
+	}
+	sigText, err := os.ReadFile(path + ".sig") // #nosec G304 -- operator-supplied path
+	if err != nil {
+		return err
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sigText)))
+	if err != nil {
+		return fmt.Errorf("%s.sig: %w", path, err)
+	}
+	pemData, err := os.ReadFile(pubFile) // #nosec G304 -- operator-supplied path
+	if err != nil {
+		return err
+	}
+	pub, err := parsePublicKey(pemData)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(pub, data, sig) {
+		return fmt.Errorf("%s: %w", path, errBadSignature)
+	}
+	return nil
+}
+
+func parsePrivateKey(pemData []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, errors.New("signing key: no PEM block")
+	}
+	k, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("signing key: %w", err)
+	}
+	priv, ok := k.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("signing key: %T is not an Ed25519 key", k)
+	}
+	return priv, nil
+}
+
+func parsePublicKey(pemData []byte) (ed25519.PublicKey, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, errors.New("public key: no PEM block")
+	}
+	k, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("public key: %w", err)
+	}
+	pub, ok := k.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key: %T is not an Ed25519 key", k)
+	}
+	return pub, nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"go.klarlabs.de/bolt/reader"
+)
+
+// runCat implements "bolt cat [-fold KEYS] [FILE...]": it renders JSON
+// records in the console layout for reading on a terminal. Fields named
+// in -fold are unfolded into indented blocks with their embedded
+// newlines restored, mirroring ConsoleHandler.SetMultilineFields. Lines
+// that are not bolt records are passed through unchanged.
+func runCat(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("cat", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fold := fs.String("fold", "stack,sql", "comma-separated fields to render as multiline blocks")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: bolt cat [-fold KEYS] [FILE...]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	var folded []string
+	for _, k := range strings.Split(*fold, ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			folded = append(folded, k)
+		}
+	}
+
+	out := bufio.NewWriter(stdout)
+	var buf []byte
+	failed := openInputs(fs.Args(), stdin, stderr, func(name string, r io.Reader) error {
+		return eachLine(r, func(line []byte) error {
+			buf = buf[:0]
+			if rec, err := reader.Parse(bytes.TrimSpace(line)); err == nil {
+				buf = appendRecord(buf, rec, folded)
+			} else {
+				buf = append(append(buf, line...), '\n')
+			}
+			_, err := out.Write(buf)
+			return err
+		}, warnSkipped(stderr, name))
+	})
+	if err := out.Flush(); err != nil {
+		fmt.Fprintf(stderr, "bolt: %v\n", err)
+		return exitError
+	}
+	if failed {
+		return exitError
+	}
+	return exitMatch
+}
+
+// appendRecord renders rec as "level[time] message k=v ..." followed by
+// one indented block per folded field.
+func appendRecord(dst []byte, rec *reader.Record, folded []string) []byte {
+	dst = append(dst, rec.Str("level")...)
+	dst = append(dst, '[')
+	if t, ok := rec.Get("time"); ok {
+		dst = append(dst, t.String()...)
+	} else if t, ok := rec.Get("timestamp"); ok {
+		dst = append(dst, t.String()...)
+	}
+	dst = append(dst, "] "...)
+	dst = append(dst, rec.Message()...)
+
+	for _, f := range rec.Fields() {
+		switch f.Key {
+		case "level", "message", "time", "timestamp":
+			continue
+		}
+		if contains(folded, f.Key) {
+			continue
+		}
+		raw := f.Value.Raw()
+		if f.Value.Kind() == reader.KindString {
+			raw = raw[1 : len(raw)-1] // keep escapes so the line stays single-line
+		}
+		dst = append(dst, ' ')
+		dst = append(dst, f.Key...)
+		dst = append(dst, '=')
+		dst = append(dst, raw...)
+	}
+	dst = append(dst, '\n')
+
+	for _, key := range folded {
+		v, ok := rec.Get(key)
+		if !ok {
+			continue
+		}
+		dst = append(dst, "  "...)
+		dst = append(dst, key...)
+		dst = append(dst, ":\n"...)
+		for _, line := range strings.Split(v.String(), "\n") {
+			dst = append(dst, "    "...)
+			dst = append(dst, line...)
+			dst = append(dst, '\n')
+		}
+	}
+	return dst
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCatUnfoldsMultilineFields(t *testing.T) {
+	input := `{"level":"error","timestamp":"2025-01-02T03:04:05Z","user":42,"note":"a\tb","stack":"goroutine 1 [running]:\nmain.main()","message":"boom"}
+panic: raw runtime output
+{"level":"info","sql":"SELECT 1","message":"ok"}
+`
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"cat"}, strings.NewReader(input), &stdout, &stderr); code != exitMatch {
+		t.Fatalf("exit code = %d, stderr = %s", code, stderr.String())
+	}
+	want := `error[2025-01-02T03:04:05Z] boom user=42 note=a\tb
+  stack:
+    goroutine 1 [running]:
+    main.main()
+panic: raw runtime output
+info[] ok
+  sql:
+    SELECT 1
+`
+	if stdout.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", stdout.String(), want)
+	}
+}
+
+func TestCatFoldFlag(t *testing.T) {
+	input := `{"level":"info","stack":"x\ny","message":"m"}` + "\n"
+	var stdout, stderr bytes.Buffer
+	run([]string{"cat", "-fold", ""}, strings.NewReader(input), &stdout, &stderr)
+	if got := stdout.String(); got != "info[] m stack=x\\ny\n" {
+		t.Errorf("with folding disabled got %q", got)
+	}
+}
+
+func TestCatSkipsOversizedLine(t *testing.T) {
+	input := `{"level":"info","blob":"` + strings.Repeat("x", 2<<20) + `"}` + "\n" +
+		`{"level":"info","message":"after"}` + "\n"
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"cat"}, strings.NewReader(input), &stdout, &stderr); code != exitMatch {
+		t.Fatalf("exit code = %d, stderr = %s", code, stderr.String())
+	}
+	if stdout.String() != "info[] after\n" {
+		t.Errorf("got %q", stdout.String())
+	}
+	if got := stderr.String(); got != "bolt: <stdin>:1: line too long, skipped\n" {
+		t.Errorf("stderr = %q", got)
+	}
+}
@@ -0,0 +1,404 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"go.klarlabs.de/bolt"
+	"go.klarlabs.de/bolt/reader"
+)
+
+// The query expression language:
+//
+//	expr    := or
+//	or      := and { "||" and }
+//	and     := unary { "&&" unary }
+//	unary   := "!" unary | primary
+//	primary := "(" expr ")" | path [ op literal ]
+//	op      := "==" | "!=" | "<" | "<=" | ">" | ">=" | "=~"
+//	literal := string | number | true | false | null | word
+//
+// A path is "level", "message" (or "msg"), "fields.<key>" or a bare
+// field key; dotted keys descend into nested objects. A path without an
+// operator tests for presence. Comparisons against a missing field are
+// false. "level" compares by severity, so level>=warn matches warn,
+// error and fatal. "=~" takes a regular expression string.
+
+// node is a compiled expression.
+type node interface {
+	eval(rec *reader.Record) bool
+}
+
+type andNode struct{ l, r node }
+type orNode struct{ l, r node }
+type notNode struct{ n node }
+type existsNode struct{ path string }
+
+func (n andNode) eval(rec *reader.Record) bool { return n.l.eval(rec) && n.r.eval(rec) }
+func (n orNode) eval(rec *reader.Record) bool  { return n.l.eval(rec) || n.r.eval(rec) }
+func (n notNode) eval(rec *reader.Record) bool { return !n.n.eval(rec) }
+
+func (n existsNode) eval(rec *reader.Record) bool {
+	_, ok := lookup(rec, n.path)
+	return ok
+}
+
+// levelNode compares the record level by severity.
+type levelNode struct {
+	op    string
+	level bolt.Level
+}
+
+func (n levelNode) eval(rec *reader.Record) bool {
+	l, ok := rec.Level()
+	if !ok {
+		return false
+	}
+	return compareOrdered(n.op, int(l), int(n.level))
+}
+
+// cmpNode compares a field against a literal.
+type cmpNode struct {
+	path string
+	op   string
+	lit  literal
+	re   *regexp.Regexp
+}
+
+type literal struct {
+	str  string
+	num  float64
+	kind reader.Kind
+}
+
+func (n cmpNode) eval(rec *reader.Record) bool {
+	v, ok := lookup(rec, n.path)
+	if !ok {
+		return false
+	}
+	if n.re != nil {
+		return n.re.MatchString(v.String())
+	}
+	switch n.lit.kind {
+	case reader.KindNumber:
+		f, ok := v.Float64()
+		if !ok {
+			// Accept numeric strings such as "503".
+			if f, ok = parseFloat(v); !ok {
+				return false
+			}
+		}
+		return compareOrdered(n.op, f, n.lit.num)
+	case reader.KindString:
+		return compareOrdered(n.op, v.String(), n.lit.str)
+	default:
+		eq := bytes.Equal(v.Raw(), []byte(n.lit.str))
+		if n.op == "!=" {
+			return !eq
+		}
+		return n.op == "==" && eq
+	}
+}
+
+func parseFloat(v reader.Value) (float64, bool) {
+	s, ok := v.Str()
+	if !ok {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	return f, err == nil
+}
+
+func compareOrdered[T int | float64 | string](op string, a, b T) bool {
+	switch op {
+	case "==":
+		return a == b
+	case "!=":
+		return a != b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	}
+	return false
+}
+
+func lookup(rec *reader.Record, path string) (reader.Value, bool) {
+	switch path {
+	case "msg":
+		path = "message"
+	default:
+		path = strings.TrimPrefix(path, "fields.")
+	}
+	return rec.Lookup(path)
+}
+
+// compile parses src into an evaluable expression.
+func compile(src string) (node, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.toks) {
+		return nil, fmt.Errorf("unexpected %q at offset %d", p.toks[p.pos].text, p.toks[p.pos].off)
+	}
+	return n, nil
+}
+
+// needles returns byte strings that must appear in any line the
+// expression can match. They come from string equality comparisons that
+// are reachable through && only, and let the scanner skip most lines
+// without decoding them.
+func needles(n node) [][]byte {
+	switch n := n.(type) {
+	case andNode:
+		return append(needles(n.l), needles(n.r)...)
+	case cmpNode:
+		if n.op != "==" || n.re != nil || n.lit.kind != reader.KindString || needsEscape(n.lit.str) {
+			return nil
+		}
+		return [][]byte{[]byte(n.lit.str)}
+	}
+	return nil
+}
+
+// needsEscape reports whether s would be encoded differently inside a
+// JSON string, in which case it cannot be used as a raw-byte needle.
+func needsEscape(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c < 0x20 || c == '"' || c == '\\' || c >= utf8.RuneSelf {
+			return true
+		}
+	}
+	return false
+}
+
+type tokenKind uint8
+
+const (
+	tokIdent tokenKind = iota
+	tokString
+	tokNumber
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	off  int
+}
+
+func lex(src string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '"':
+			j := i + 1
+			for j < len(src) && src[j] != '"' {
+				if src[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("unterminated string at offset %d", i)
+			}
+			s, err := strconv.Unquote(src[i : j+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at offset %d: %w", i, err)
+			}
+			toks = append(toks, token{tokString, s, i})
+			i = j + 1
+		case c == '-' || (c >= '0' && c <= '9'):
+			j := i + 1
+			for j < len(src) && strings.IndexByte("0123456789.eE+-", src[j]) >= 0 {
+				j++
+			}
+			toks = append(toks, token{tokNumber, src[i:j], i})
+			i = j
+		case isIdentByte(c):
+			j := i
+			for j < len(src) && (isIdentByte(src[j]) || (src[j] >= '0' && src[j] <= '9')) {
+				j++
+			}
+			toks = append(toks, token{tokIdent, src[i:j], i})
+			i = j
+		default:
+			op := matchOp(src[i:])
+			if op == "" {
+				return nil, fmt.Errorf("unexpected %q at offset %d", c, i)
+			}
+			toks = append(toks, token{tokOp, op, i})
+			i += len(op)
+		}
+	}
+	return toks, nil
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '.' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func matchOp(s string) string {
+	for _, op := range [...]string{"&&", "||", "==", "!=", "<=", ">=", "=~", "<", ">", "!", "(", ")"} {
+		if strings.HasPrefix(s, op) {
+			return op
+		}
+	}
+	return ""
+}
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.pos >= len(p.toks) {
+		return token{}, false
+	}
+	return p.toks[p.pos], true
+}
+
+func (p *parser) acceptOp(ops ...string) (string, bool) {
+	t, ok := p.peek()
+	if !ok || t.kind != tokOp {
+		return "", false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) parseOr() (node, error) {
+	l, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.acceptOp("||"); !ok {
+			return l, nil
+		}
+		r, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l = orNode{l, r}
+	}
+}
+
+func (p *parser) parseAnd() (node, error) {
+	l, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.acceptOp("&&"); !ok {
+			return l, nil
+		}
+		r, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l = andNode{l, r}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if _, ok := p.acceptOp("!"); ok {
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{n}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	if _, ok := p.acceptOp("("); ok {
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := p.acceptOp(")"); !ok {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		return n, nil
+	}
+	t, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	if t.kind != tokIdent {
+		return nil, fmt.Errorf("expected field name at offset %d, got %q", t.off, t.text)
+	}
+	p.pos++
+	op, ok := p.acceptOp("==", "!=", "<", "<=", ">", ">=", "=~")
+	if !ok {
+		return existsNode{t.text}, nil
+	}
+	lt, ok := p.peek()
+	if !ok || lt.kind == tokOp {
+		return nil, fmt.Errorf("expected value after %q", op)
+	}
+	p.pos++
+	return newComparison(t.text, op, lt)
+}
+
+func newComparison(path, op string, lt token) (node, error) {
+	if path == "level" && op != "=~" {
+		for l := bolt.TRACE; l <= bolt.FATAL; l++ {
+			if l.String() == lt.text {
+				return levelNode{op: op, level: l}, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown level %q", lt.text)
+	}
+
+	n := cmpNode{path: path, op: op, lit: literal{str: lt.text, kind: reader.KindString}}
+	switch {
+	case op == "=~":
+		re, err := regexp.Compile(lt.text)
+		if err != nil {
+			return nil, err
+		}
+		n.re = re
+	case lt.kind == tokNumber:
+		f, err := strconv.ParseFloat(lt.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", lt.text)
+		}
+		n.lit.kind, n.lit.num = reader.KindNumber, f
+	case lt.kind == tokIdent && (lt.text == "true" || lt.text == "false"):
+		n.lit.kind = reader.KindBool
+	case lt.kind == tokIdent && lt.text == "null":
+		n.lit.kind = reader.KindNull
+	}
+	if n.lit.kind == reader.KindBool || n.lit.kind == reader.KindNull {
+		if op != "==" && op != "!=" {
+			return nil, fmt.Errorf("operator %q not supported for %s", op, lt.text)
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	gotoken "go/token"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// boltImportPath is the import path whose users keys scans.
+const boltImportPath = "go.klarlabs.de/bolt"
+
+// fieldMethods maps the bolt methods and key constructors taking a field
+// key as their first argument to the JSON type of the field they write.
+var fieldMethods = map[string]string{
+	"Str": "string", "Stringer": "string", "Hex": "string", "Base64": "string",
+	"Bytes": "string", "IPAddr": "string", "RandID": "string", "EventID": "string",
+	"Time": "time",
+	"Int":  "integer", "Int64": "integer", "Int32": "integer", "Int16": "integer", "Int8": "integer",
+	"Uint": "integer", "Uint64": "integer", "Uint32": "integer", "Uint16": "integer", "Uint8": "integer",
+	"Counter": "integer", "Dur": "duration",
+	"Float64": "number",
+	"Bool":    "boolean",
+	"Ints":    "array", "Strs": "array",
+	"Dict": "object", "Object": "object",
+	"Any": "any", "Interface": "any",
+
+	"StrKey": "string", "IntKey": "integer", "Int64Key": "integer", "Uint64Key": "integer",
+	"Float64Key": "number", "BoolKey": "boolean", "DurKey": "duration",
+}
+
+// keyField is one entry of the field dictionary.
+type keyField struct {
+	Key       string   `json:"key"`
+	Types     []string `json:"types"`
+	Uses      int      `json:"uses"`
+	Locations []string `json:"locations"`
+}
+
+// keyDictionary is the machine-readable output of "bolt keys -json".
+type keyDictionary struct {
+	Fields     []*keyField `json:"fields"`
+	Collisions [][]string  `json:"collisions"`
+}
+
+// runKeys implements "bolt keys [-json] [-tests] [PATH...]": it parses the
+// Go files under each path (default ".") that import bolt, collects the
+// literal field keys passed to bolt's field methods and key constructors,
+// and reports near-duplicate keys: spellings that only differ in case,
+// "_", "-" or "." ("userId", "user_id", "user-id").
+//
+// Keys are string literals or constants declared in the same package.
+// Calls are matched by method name only, so a non-bolt method named Str
+// in a file that imports bolt is counted too.
+//
+// With -json the field dictionary, every key with its types, use count
+// and locations plus the collision groups, is written as JSON for
+// schema tooling. Exit status is 0 when there are no collisions, 1 when
+// there are, and 2 on errors.
+func runKeys(args []string, _ io.Reader, stdout, stderr io.Writer) int {
+	fset := flag.NewFlagSet("keys", flag.ContinueOnError)
+	fset.SetOutput(stderr)
+	asJSON := fset.Bool("json", false, "write the field dictionary as JSON")
+	tests := fset.Bool("tests", false, "include _test.go files")
+	fset.Usage = func() {
+		fmt.Fprintln(stderr, "usage: bolt keys [-json] [-tests] [PATH...]")
+		fset.PrintDefaults()
+	}
+	if err := fset.Parse(args); err != nil {
+		return exitError
+	}
+	paths := fset.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	sc := &keyScanner{fset: gotoken.NewFileSet(), tests: *tests, fields: make(map[string]*keyField)}
+	failed := false
+	for _, p := range paths {
+		if err := sc.scan(p); err != nil {
+			fmt.Fprintf(stderr, "bolt: %v\n", err)
+			failed = true
+		}
+	}
+	dict := sc.dictionary()
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(dict); err != nil {
+			fmt.Fprintf(stderr, "bolt: %v\n", err)
+			return exitError
+		}
+	} else {
+		for _, group := range dict.Collisions {
+			fmt.Fprintf(stdout, "near-duplicate keys: %s\n", strings.Join(group, ", "))
+			for _, k := range group {
+				f := sc.fields[k]
+				fmt.Fprintf(stdout, "  %s (%s) %s\n", k, strings.Join(f.Types, "|"), strings.Join(f.Locations, " "))
+			}
+		}
+		fmt.Fprintf(stdout, "%d keys, %d near-duplicate groups\n", len(dict.Fields), len(dict.Collisions))
+	}
+
+	switch {
+	case failed:
+		return exitError
+	case len(dict.Collisions) > 0:
+		return exitNoMatch
+	}
+	return exitMatch
+}
+
+type keyScanner struct {
+	fset   *gotoken.FileSet
+	tests  bool
+	fields map[string]*keyField
+}
+
+// scan parses the Go packages in every directory under root.
+func (sc *keyScanner) scan(root string) error {
+	info, err := os.Stat(root)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return sc.scanFiles([]string{root})
+	}
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		name := d.Name()
+		if path != root && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+			return filepath.SkipDir
+		}
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return err
+		}
+		var files []string
+		for _, e := range entries {
+			n := e.Name()
+			if e.IsDir() || !strings.HasSuffix(n, ".go") || (!sc.tests && strings.HasSuffix(n, "_test.go")) {
+				continue
+			}
+			files = append(files, filepath.Join(path, n))
+		}
+		return sc.scanFiles(files)
+	})
+}
+
+// scanFiles parses the files of one directory, resolving constants
+// declared anywhere in them.
+func (sc *keyScanner) scanFiles(files []string) error {
+	var parsed []*ast.File
+	consts := make(map[string]string)
+	for _, name := range files {
+		f, err := goparser.ParseFile(sc.fset, name, nil, goparser.SkipObjectResolution)
+		if err != nil {
+			return err
+		}
+		parsed = append(parsed, f)
+		collectConsts(f, consts)
+	}
+	for _, f := range parsed {
+		if !importsBolt(f) {
+			continue
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			typ, ok := fieldMethods[sel.Sel.Name]
+			if !ok {
+				return true
+			}
+			if key, ok := stringValue(call.Args[0], consts); ok {
+				sc.add(key, typ, call.Args[0].Pos())
+			}
+			return true
+		})
+	}
+	return nil
+}
+
+func (sc *keyScanner) add(key, typ string, pos gotoken.Pos) {
+	f, ok := sc.fields[key]
+	if !ok {
+		f = &keyField{Key: key}
+		sc.fields[key] = f
+	}
+	f.Uses++
+	if !slices.Contains(f.Types, typ) {
+		f.Types = append(f.Types, typ)
+		slices.Sort(f.Types)
+	}
+	p := sc.fset.Position(pos)
+	f.Locations = append(f.Locations, filepath.ToSlash(p.Filename)+":"+strconv.Itoa(p.Line))
+}
+
+// dictionary returns the fields sorted by key and the near-duplicate
+// groups, each sorted, ordered by their first key.
+func (sc *keyScanner) dictionary() keyDictionary {
+	dict := keyDictionary{Fields: make([]*keyField, 0, len(sc.fields)), Collisions: [][]string{}}
+	groups := make(map[string][]string)
+	for k, f := range sc.fields {
+		dict.Fields = append(dict.Fields, f)
+		n := normalizeKey(k)
+		groups[n] = append(groups[n], k)
+	}
+	slices.SortFunc(dict.Fields, func(a, b *keyField) int { return strings.Compare(a.Key, b.Key) })
+	for _, g := range groups {
+		if len(g) > 1 {
+			slices.Sort(g)
+			dict.Collisions = append(dict.Collisions, g)
+		}
+	}
+	slices.SortFunc(dict.Collisions, func(a, b []string) int { return strings.Compare(a[0], b[0]) })
+	return dict
+}
+
+// normalizeKey folds the spelling differences that make keys
+// near-duplicates: case and the separators "_", "-" and ".".
+func normalizeKey(k string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(k) {
+		if r != '_' && r != '-' && r != '.' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func importsBolt(f *ast.File) bool {
+	if f.Name.Name == "bolt" {
+		return true
+	}
+	for _, imp := range f.Imports {
+		if p, err := strconv.Unquote(imp.Path.Value); err == nil && p == boltImportPath {
+			return true
+		}
+	}
+	return false
+}
+
+// collectConsts records the string constants declared in f.
+func collectConsts(f *ast.File, consts map[string]string) {
+	ast.Inspect(f, func(n ast.Node) bool {
+		decl, ok := n.(*ast.GenDecl)
+		if !ok || decl.Tok != gotoken.CONST {
+			return true
+		}
+		for _, spec := range decl.Specs {
+			vs := spec.(*ast.ValueSpec)
+			for i, name := range vs.Names {
+				if i < len(vs.Values) {
+					if s, ok := stringValue(vs.Values[i], nil); ok {
+						consts[name.Name] = s
+					}
+				}
+			}
+		}
+		return false
+	})
+}
+
+// stringValue returns the value of a string literal or of an identifier
+// naming a constant in consts.
+func stringValue(expr ast.Expr, consts map[string]string) (string, bool) {
+	switch x := expr.(type) {
+	case *ast.BasicLit:
+		if x.Kind != gotoken.STRING {
+			return "", false
+		}
+		s, err := strconv.Unquote(x.Value)
+		return s, err == nil
+	case *ast.Ident:
+		s, ok := consts[x.Name]
+		return s, ok
+	case *ast.ParenExpr:
+		return stringValue(x.X, consts)
+	}
+	return "", false
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeGoFile(t *testing.T, dir, name, src string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKeysReportsNearDuplicates(t *testing.T) {
+	dir := t.TempDir()
+	writeGoFile(t, dir, "a.go", `package app
+
+import "go.klarlabs.de/bolt"
+
+const keyUser = "user_id"
+
+func a(l *bolt.Logger) {
+	l.Info().Str("userId", "u1").Int("attempts", 2).Msg("login")
+	l.Info().Str(keyUser, "u1").Msg("logout")
+}
+`)
+	writeGoFile(t, dir, "b.go", `package app
+
+import "go.klarlabs.de/bolt"
+
+var userKey = bolt.Int64Key("User-ID")
+`)
+	writeGoFile(t, dir, "plain.go", `package app
+
+func c(b interface{ Str(string, string) }) { b.Str("ignored", "x") }
+`)
+	writeGoFile(t, dir, "a_test.go", `package app
+
+import "go.klarlabs.de/bolt"
+
+func d(l *bolt.Logger) { l.Info().Str("test_only", "x").Send() }
+`)
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"keys", "-json", dir}, nil, &stdout, &stderr)
+	if code != exitNoMatch {
+		t.Fatalf("exit code = %d, want %d; stderr = %s", code, exitNoMatch, stderr.String())
+	}
+	var dict keyDictionary
+	if err := json.Unmarshal(stdout.Bytes(), &dict); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout.String())
+	}
+	var keys []string
+	for _, f := range dict.Fields {
+		keys = append(keys, f.Key)
+	}
+	if got := strings.Join(keys, ","); got != "User-ID,attempts,userId,user_id" {
+		t.Errorf("keys = %s", got)
+	}
+	if len(dict.Collisions) != 1 || strings.Join(dict.Collisions[0], ",") != "User-ID,userId,user_id" {
+		t.Errorf("collisions = %v", dict.Collisions)
+	}
+	for _, f := range dict.Fields {
+		if f.Key == "User-ID" && (f.Types[0] != "integer" || f.Uses != 1 || !strings.HasSuffix(f.Locations[0], "b.go:5")) {
+			t.Errorf("User-ID = %+v", f)
+		}
+	}
+
+	stdout.Reset()
+	run([]string{"keys", "-tests", dir}, nil, &stdout, &stderr)
+	if !strings.Contains(stdout.String(), "near-duplicate keys: User-ID, userId, user_id\n") ||
+		!strings.HasSuffix(stdout.String(), "5 keys, 1 near-duplicate groups\n") {
+		t.Errorf("report:\n%s", stdout.String())
+	}
+}
+
+func TestKeysClean(t *testing.T) {
+	dir := t.TempDir()
+	writeGoFile(t, dir, "a.go", `package app
+
+import "go.klarlabs.de/bolt"
+
+func a(l *bolt.Logger) { l.Info().Str("user_id", "u1").Str("user_id", "u2").Send() }
+`)
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"keys", dir}, nil, &stdout, &stderr); code != exitMatch {
+		t.Errorf("exit code = %d, want %d; output = %s", code, exitMatch, stdout.String())
+	}
+	if code := run([]string{"keys", filepath.Join(dir, "missing")}, nil, &stdout, &stderr); code != exitError {
+		t.Errorf("missing path: exit code = %d, want %d", code, exitError)
+	}
+}
@@ -0,0 +1,140 @@
+// Command bolt is the command-line companion to the bolt logging library.
+//
+// Usage:
+//
+//	bolt <command> [arguments]
+//
+// Commands:
+//
+//	cat      render JSON log files for humans, unfolding multiline fields
+//	keys     list the field keys Go code logs and report near-duplicates
+//	query    filter JSON log files with a small expression language
+//	seqcheck report lost or duplicated records by sequence number
+//
+// Run "bolt <command> -h" for command-specific flags.
+//
+// Exit status follows grep: 0 when at least one record matched, 1 when
+// none did, and 2 on usage or I/O errors. seqcheck exits 1 when it finds
+// gaps or duplicates; keys exits 1 when it finds near-duplicate keys.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"go.klarlabs.de/bolt/reader"
+)
+
+// Exit codes shared by all commands.
+const (
+	exitMatch   = 0
+	exitNoMatch = 1
+	exitError   = 2
+)
+
+// command is the signature every subcommand implements.
+type command func(args []string, stdin io.Reader, stdout, stderr io.Writer) int
+
+var commands = map[string]command{
+	"cat":      runCat,
+	"keys":     runKeys,
+	"query":    runQuery,
+	"seqcheck": runSeqcheck,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "help" {
+		usage(stderr)
+		return exitError
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "bolt: unknown command %q\n", args[0])
+		usage(stderr)
+		return exitError
+	}
+	return cmd(args[1:], stdin, stdout, stderr)
+}
+
+func usage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintln(w, "usage: bolt <command> [arguments]")
+	fmt.Fprintln(w, "commands:")
+	for _, name := range names {
+		fmt.Fprintf(w, "  %s\n", name)
+	}
+}
+
+// openInputs calls fn for each named file, or for stdin when no files are
+// given. Files that cannot be opened are reported on stderr and skipped;
+// the returned bool reports whether any input failed.
+func openInputs(files []string, stdin io.Reader, stderr io.Writer, fn func(name string, r io.Reader) error) bool {
+	if len(files) == 0 {
+		if err := fn("-", stdin); err != nil {
+			fmt.Fprintf(stderr, "bolt: <stdin>: %v\n", err)
+			return true
+		}
+		return false
+	}
+	failed := false
+	for _, name := range files {
+		f, err := os.Open(name) // #nosec G304 -- operator-supplied path
+		if err != nil {
+			fmt.Fprintf(stderr, "bolt: %v\n", err)
+			failed = true
+			continue
+		}
+		err = fn(name, f)
+		_ = f.Close()
+		if err != nil {
+			fmt.Fprintf(stderr, "bolt: %s: %v\n", name, err)
+			failed = true
+		}
+	}
+	return failed
+}
+
+// eachLine calls fn for every line of r, without its line ending. Lines
+// too long to be a bolt record are skipped and reported to skipped with
+// their line number, so one oversized line does not end the input.
+func eachLine(r io.Reader, fn func(line []byte) error, skipped func(line int)) error {
+	rd := reader.New(r)
+	for {
+		line, err := rd.ReadLine()
+		var perr *reader.ParseError
+		switch {
+		case err == io.EOF:
+			return nil
+		case errors.As(err, &perr):
+			skipped(perr.Line)
+			continue
+		case err != nil:
+			return err
+		}
+		if err := fn(line); err != nil {
+			return err
+		}
+	}
+}
+
+// warnSkipped returns a skipped callback for eachLine that reports
+// oversized lines of the named input on stderr.
+func warnSkipped(stderr io.Writer, name string) func(line int) {
+	if name == "-" {
+		name = "<stdin>"
+	}
+	return func(line int) {
+		fmt.Fprintf(stderr, "bolt: %s:%d: line too long, skipped\n", name, line)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+
+	"go.klarlabs.de/bolt/reader"
+)
+
+// runQuery implements "bolt query EXPR [FILE...]". Matching lines are
+// copied to stdout unchanged so the output stays valid NDJSON and can be
+// piped into further tools.
+func runQuery(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("query", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	count := fs.Bool("c", false, "print only the number of matching records")
+	withName := fs.Bool("H", false, "prefix each match with its file name")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, `usage: bolt query [-c] [-H] EXPR [FILE...]
+
+Examples:
+  bolt query 'level>=warn' app.log
+  bolt query 'level>=warn && fields.user_id=="42"' *.log
+  bolt query 'message=~"timeout|deadline" || http.status>=500' app.log`)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitError
+	}
+	expr, err := compile(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "bolt: invalid query: %v\n", err)
+		return exitError
+	}
+	q := &query{expr: expr, needles: needles(expr)}
+
+	out := bufio.NewWriter(stdout)
+	var matched int
+	failed := openInputs(fs.Args()[1:], stdin, stderr, func(name string, r io.Reader) error {
+		prefix := ""
+		if *withName {
+			prefix = name + ":"
+		}
+		n, err := q.scan(r, warnSkipped(stderr, name), func(line []byte) error {
+			if *count {
+				return nil
+			}
+			if _, err := out.WriteString(prefix); err != nil {
+				return err
+			}
+			if _, err := out.Write(line); err != nil {
+				return err
+			}
+			return out.WriteByte('\n')
+		})
+		matched += n
+		return err
+	})
+	if *count {
+		fmt.Fprintln(out, matched)
+	}
+	if err := out.Flush(); err != nil {
+		fmt.Fprintf(stderr, "bolt: %v\n", err)
+		return exitError
+	}
+	switch {
+	case failed:
+		return exitError
+	case matched == 0:
+		return exitNoMatch
+	default:
+		return exitMatch
+	}
+}
+
+type query struct {
+	expr    node
+	needles [][]byte
+}
+
+// scan calls emit for every line of r that satisfies the query and returns
+// the number of matches. Lines that are not bolt records are ignored;
+// lines too long to be one are passed to skipped.
+func (q *query) scan(r io.Reader, skipped func(line int), emit func(line []byte) error) (int, error) {
+	matched := 0
+	err := eachLine(r, func(line []byte) error {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 || !q.mayMatch(line) {
+			return nil
+		}
+		rec, err := reader.Parse(line)
+		if err != nil || !q.expr.eval(rec) {
+			return nil
+		}
+		matched++
+		return emit(line)
+	}, skipped)
+	return matched, err
+}
+
+// mayMatch is a cheap pre-filter that rejects lines missing a required
+// literal before they are decoded.
+func (q *query) mayMatch(line []byte) bool {
+	for _, n := range q.needles {
+		if !bytes.Contains(line, n) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const queryInput = `{"level":"debug","user_id":"42","message":"cache miss"}
+{"level":"warn","user_id":"42","http":{"status":503},"message":"upstream slow"}
+{"level":"error","user_id":42,"message":"request failed"}
+{"level":"error","user_id":"7","retry":true,"message":"deadline exceeded"}
+not json at all
+{"level":"info","user_id":"42","message":"ok"}
+`
+
+func TestQueryExpressions(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []string // expected messages
+	}{
+		{`level>=warn`, []string{"upstream slow", "request failed", "deadline exceeded"}},
+		{`level>=warn && fields.user_id=="42"`, []string{"upstream slow", "request failed"}},
+		{`level == error && !(user_id == "7")`, []string{"request failed"}},
+		{`http.status >= 500`, []string{"upstream slow"}},
+		{`retry`, []string{"deadline exceeded"}},
+		{`retry == true || msg == "ok"`, []string{"deadline exceeded", "ok"}},
+		{`message =~ "^(cache|dead)"`, []string{"cache miss", "deadline exceeded"}},
+		{`user_id != "42"`, []string{"deadline exceeded"}},
+		{`missing == "x"`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run([]string{"query", tt.expr}, strings.NewReader(queryInput), &stdout, &stderr)
+			if stderr.Len() != 0 {
+				t.Fatalf("stderr: %s", stderr.String())
+			}
+			var got []string
+			for _, line := range strings.Split(strings.TrimSpace(stdout.String()), "\n") {
+				if i := strings.Index(line, `"message":"`); i >= 0 {
+					got = append(got, strings.TrimSuffix(line[i+len(`"message":"`):], `"}`))
+				}
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("got %q; want %q", got, tt.want)
+			}
+			wantCode := exitMatch
+			if len(tt.want) == 0 {
+				wantCode = exitNoMatch
+			}
+			if code != wantCode {
+				t.Errorf("exit code = %d; want %d", code, wantCode)
+			}
+		})
+	}
+}
+
+func TestQueryCountAndFiles(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.log")
+	b := filepath.Join(dir, "b.log")
+	if err := os.WriteFile(a, []byte(queryInput), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(b, []byte(queryInput), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"query", "-c", "level==error", a, b}, nil, &stdout, &stderr); code != exitMatch {
+		t.Fatalf("exit code = %d, stderr = %s", code, stderr.String())
+	}
+	if got := strings.TrimSpace(stdout.String()); got != "4" {
+		t.Errorf("count = %s; want 4", got)
+	}
+
+	stdout.Reset()
+	run([]string{"query", "-H", "msg==\"ok\"", a}, nil, &stdout, &stderr)
+	if !strings.HasPrefix(stdout.String(), a+":{") {
+		t.Errorf("missing file name prefix: %q", stdout.String())
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	if code := run([]string{"query", "level==error", filepath.Join(dir, "nope.log")}, nil, &stdout, &stderr); code != exitError {
+		t.Errorf("missing file exit code = %d; want %d", code, exitError)
+	}
+}
+
+func TestQuerySkipsOversizedLine(t *testing.T) {
+	input := `{"level":"info","blob":"` + strings.Repeat("x", 2<<20) + `"}` + "\n" +
+		`{"level":"error","message":"after"}` + "\n"
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"query", "level==error"}, strings.NewReader(input), &stdout, &stderr)
+	if code != exitMatch {
+		t.Fatalf("exit code = %d; want %d (stderr: %s)", code, exitMatch, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), `"message":"after"`) {
+		t.Errorf("match after the oversized line lost: %q", stdout.String())
+	}
+	if got := stderr.String(); got != "bolt: <stdin>:1: line too long, skipped\n" {
+		t.Errorf("stderr = %q", got)
+	}
+}
+
+func TestQueryCompileErrors(t *testing.T) {
+	for _, expr := range []string{
+		`level >= loud`,
+		`(level == warn`,
+		`user_id ==`,
+		`"x" == user_id`,
+		`retry < true`,
+		`message =~ "("`,
+		`a == "unterminated`,
+	} {
+		if _, err := compile(expr); err == nil {
+			t.Errorf("compile(%q) succeeded; want error", expr)
+		}
+	}
+}
+
+func TestQueryNeedles(t *testing.T) {
+	n, err := compile(`level>=warn && user_id=="42" && (a=="x" || b=="y") && c=="q\"uote"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := needles(n)
+	if len(got) != 1 || string(got[0]) != "42" {
+		t.Errorf("needles = %q; want only \"42\"", got)
+	}
+}
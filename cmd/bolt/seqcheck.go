@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.klarlabs.de/bolt"
+	"go.klarlabs.de/bolt/reader"
+)
+
+// maxRanges caps the missing ranges listed per stream.
+const maxRanges = 10
+
+// runSeqcheck implements "bolt seqcheck [-by FIELDS] [FILE...]": it reads
+// records stamped by bolt.SequenceHook and reports, per stream, the
+// sequence numbers that are missing or seen more than once. Streams are
+// identified by seq_stream and labelled with the -by fields (host and
+// service by default). Records without a sequence number are ignored.
+//
+// Loss before the first or after the last record seen cannot be told
+// apart from files that were not passed in, so only gaps between the
+// lowest and highest number are reported. Lines too long to be a
+// record are reported as gaps of their own, since the record they held
+// cannot be checked.
+//
+// Exit status is 0 when every stream is complete, 1 when any has gaps or
+// duplicates, and 2 on errors.
+func runSeqcheck(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("seqcheck", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	by := fs.String("by", "host,service", "comma-separated fields labelling each stream in the report")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: bolt seqcheck [-by FIELDS] [FILE...]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	var labels []string
+	for _, k := range strings.Split(*by, ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			labels = append(labels, k)
+		}
+	}
+
+	streams := make(map[string]*seqStream)
+	var skipped []string
+	failed := openInputs(fs.Args(), stdin, stderr, func(name string, r io.Reader) error {
+		if name == "-" {
+			name = "<stdin>"
+		}
+		return eachLine(r, func(line []byte) error {
+			if !bytes.Contains(line, []byte(`"`+bolt.SeqField+`"`)) {
+				return nil
+			}
+			rec, err := reader.Parse(bytes.TrimSpace(line))
+			if err != nil {
+				return nil
+			}
+			v, ok := rec.Get(bolt.SeqField)
+			if !ok {
+				return nil
+			}
+			n, err := strconv.ParseUint(string(v.Raw()), 10, 64)
+			if err != nil {
+				return nil
+			}
+			id := rec.Str(bolt.SeqStreamField)
+			s, ok := streams[id]
+			if !ok {
+				s = &seqStream{id: id, label: streamLabel(rec, labels)}
+				streams[id] = s
+			}
+			s.seqs = append(s.seqs, n)
+			return nil
+		}, func(line int) {
+			skipped = append(skipped, fmt.Sprintf("%s:%d", name, line))
+		})
+	})
+
+	out := bufio.NewWriter(stdout)
+	ids := make([]string, 0, len(streams))
+	for id := range streams {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	clean := len(skipped) == 0
+	for _, id := range ids {
+		if !streams[id].report(out) {
+			clean = false
+		}
+	}
+	for _, at := range skipped {
+		fmt.Fprintf(out, "skipped %s: line too long, record unchecked\n", at)
+	}
+	if err := out.Flush(); err != nil {
+		fmt.Fprintf(stderr, "bolt: %v\n", err)
+		return exitError
+	}
+	switch {
+	case failed:
+		return exitError
+	case !clean:
+		return exitNoMatch
+	}
+	return exitMatch
+}
+
+// seqStream collects the sequence numbers seen for one stream.
+type seqStream struct {
+	id    string
+	label string
+	seqs  []uint64
+}
+
+func streamLabel(rec *reader.Record, labels []string) string {
+	var b strings.Builder
+	for _, k := range labels {
+		if v, ok := rec.Get(k); ok {
+			fmt.Fprintf(&b, "%s=%s ", k, v.String())
+		}
+	}
+	return b.String()
+}
+
+// report writes one summary line for s, followed by its missing ranges
+// and duplicates, and reports whether the stream is complete.
+func (s *seqStream) report(w io.Writer) bool {
+	slices.Sort(s.seqs)
+	var missing [][2]uint64
+	var missingTotal, dupTotal uint64
+	var dups []uint64
+	for i := 1; i < len(s.seqs); i++ {
+		prev, cur := s.seqs[i-1], s.seqs[i]
+		switch {
+		case cur == prev:
+			dupTotal++
+			if len(dups) == 0 || dups[len(dups)-1] != cur {
+				dups = append(dups, cur)
+			}
+		case cur > prev+1:
+			missing = append(missing, [2]uint64{prev + 1, cur - 1})
+			missingTotal += cur - prev - 1
+		}
+	}
+
+	id := s.id
+	if id == "" {
+		id = "(none)"
+	}
+	fmt.Fprintf(w, "%sstream=%s records=%d seq=%d-%d missing=%d duplicates=%d\n",
+		s.label, id, len(s.seqs), s.seqs[0], s.seqs[len(s.seqs)-1], missingTotal, dupTotal)
+	for i, r := range missing {
+		if i == maxRanges {
+			fmt.Fprintf(w, "  ... %d more ranges\n", len(missing)-maxRanges)
+			break
+		}
+		if r[0] == r[1] {
+			fmt.Fprintf(w, "  missing %d\n", r[0])
+		} else {
+			fmt.Fprintf(w, "  missing %d-%d\n", r[0], r[1])
+		}
+	}
+	for i, d := range dups {
+		if i == maxRanges {
+			fmt.Fprintf(w, "  ... %d more duplicated numbers\n", len(dups)-maxRanges)
+			break
+		}
+		fmt.Fprintf(w, "  duplicate %d\n", d)
+	}
+	return missingTotal == 0 && dupTotal == 0
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSeqcheckReportsGapsAndDuplicates(t *testing.T) {
+	var in strings.Builder
+	for _, n := range []int{1, 2, 5, 3, 3, 9} { // 4, 6-8 missing; 3 twice
+		fmt.Fprintf(&in, `{"level":"info","host":"web-1","seq_stream":"A","seq":%d,"message":"m"}`+"\n", n)
+	}
+	for n := 1; n <= 3; n++ {
+		fmt.Fprintf(&in, `{"level":"info","host":"web-2","seq_stream":"B","seq":%d,"message":"m"}`+"\n", n)
+	}
+	in.WriteString("not json\n{\"level\":\"info\",\"message\":\"unsequenced\"}\n")
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"seqcheck", "-by", "host"}, strings.NewReader(in.String()), &stdout, &stderr)
+	if code != exitNoMatch {
+		t.Fatalf("exit code = %d, want %d; stderr = %s", code, exitNoMatch, stderr.String())
+	}
+	want := `host=web-1 stream=A records=6 seq=1-9 missing=4 duplicates=1
+  missing 4
+  missing 6-8
+  duplicate 3
+host=web-2 stream=B records=3 seq=1-3 missing=0 duplicates=0
+`
+	if stdout.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", stdout.String(), want)
+	}
+}
+
+func TestSeqcheckClean(t *testing.T) {
+	input := `{"seq_stream":"A","seq":2,"message":"m"}
+{"seq_stream":"A","seq":1,"message":"m"}
+`
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"seqcheck"}, strings.NewReader(input), &stdout, &stderr); code != exitMatch {
+		t.Errorf("exit code = %d, want %d; output = %s", code, exitMatch, stdout.String())
+	}
+}
+
+func TestSeqcheckOversizedLine(t *testing.T) {
+	input := `{"seq_stream":"A","seq":1,"message":"m"}` + "\n" +
+		`{"seq_stream":"A","seq":2,"blob":"` + strings.Repeat("x", 2<<20) + `"}` + "\n" +
+		`{"seq_stream":"A","seq":3,"message":"m"}` + "\n" +
+		`{"seq_stream":"A","seq":5,"message":"m"}` + "\n"
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"seqcheck"}, strings.NewReader(input), &stdout, &stderr)
+	if code != exitNoMatch {
+		t.Fatalf("exit code = %d, want %d; stderr = %s", code, exitNoMatch, stderr.String())
+	}
+	want := `stream=A records=3 seq=1-5 missing=2 duplicates=0
+  missing 2
+  missing 4
+skipped <stdin>:2: line too long, record unchecked
+`
+	if stdout.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", stdout.String(), want)
+	}
+}
@@ -0,0 +1,140 @@
+package bolt
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+)
+
+// DefaultConsumerLagInterval is the snapshot interval used by
+// [StartConsumerLag] when none is configured.
+const DefaultConsumerLagInterval = time.Minute
+
+// ConsumerLag is the lag of one partition of a consumer group.
+type ConsumerLag struct {
+	Topic     string
+	Partition int32
+
+	// Offset is the group's committed offset and HighWatermark the offset
+	// of the next message to be produced; Lag is the difference. A
+	// negative Offset means nothing was committed yet and is not logged.
+	Offset, HighWatermark int64
+	Lag                   int64
+
+	// Member is the ID of the group member the partition is assigned to,
+	// or "" if it is unassigned.
+	Member string
+}
+
+// ConsumerLagSource returns the current lag of every partition of a
+// consumer group. It adapts the admin API of a Kafka client, such as
+// sarama's ClusterAdmin or franz-go's kadm, so that bolt does not depend
+// on one.
+type ConsumerLagSource func(ctx context.Context) ([]ConsumerLag, error)
+
+// ConsumerLagOptions configures [StartConsumerLag]. A nil
+// *ConsumerLagOptions uses the defaults.
+type ConsumerLagOptions struct {
+	// Group is the consumer group, logged as "group".
+	Group string
+
+	// Interval between snapshots. Defaults to DefaultConsumerLagInterval.
+	// Each call to the source is bounded by the interval.
+	Interval time.Duration
+
+	// MinLag leaves out partitions lagging less, to keep snapshots of
+	// large groups short. Defaults to 0, logging every partition.
+	MinLag int64
+
+	// WarnLag logs partitions lagging at least this much at WARN instead
+	// of INFO, as are partitions without an assigned member. Defaults to
+	// 0, which never warns on lag alone.
+	WarnLag int64
+}
+
+// StartConsumerLag starts a background goroutine that logs a snapshot of
+// a consumer group's lag every interval until the returned stop function
+// is called, so that lag is visible next to the consumer's own logs
+// without a separate exporter:
+//
+//	stop := bolt.StartConsumerLag(logger, func(ctx context.Context) ([]bolt.ConsumerLag, error) {
+//	    return lagOf(ctx, admin, "orders-service") // adapts the Kafka client's admin API
+//	}, &bolt.ConsumerLagOptions{Group: "orders-service", WarnLag: 10_000})
+//	defer stop()
+//
+// Each snapshot is logged by [LogConsumerLag]; a failing source is
+// logged as a WARN "consumer lag" event with the error. stop blocks until
+// the goroutine has exited and is safe to call more than once.
+func StartConsumerLag(logger *Logger, source ConsumerLagSource, opts *ConsumerLagOptions) (stop func()) {
+	if opts == nil {
+		opts = &ConsumerLagOptions{}
+	}
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = DefaultConsumerLagInterval
+	}
+	return startTicker(interval, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		defer cancel()
+		lags, err := source(ctx)
+		if err != nil {
+			logger.Warn().Str("group", opts.Group).Err(err).Msg("consumer lag")
+			return
+		}
+		LogConsumerLag(logger, opts.Group, lags, opts)
+	})
+}
+
+// LogConsumerLag logs one "consumer lag" event per partition of lags,
+// ordered by topic and partition, followed by a "consumer lag summary"
+// event with the group's total and maximum lag and the partition count:
+//
+//	{"level":"info","group":"orders-service","topic":"orders","partition":3,"lag":1204,"offset":88120,"high_watermark":89324,"member":"consumer-1-5f2c","message":"consumer lag"}
+//	{"level":"info","group":"orders-service","partitions":12,"total_lag":3310,"max_lag":1204,"message":"consumer lag summary"}
+//
+// Only MinLag and WarnLag of opts apply; a nil opts logs every partition
+// at INFO, except unassigned ones, which are logged at WARN. lags is
+// sorted in place.
+func LogConsumerLag(logger *Logger, group string, lags []ConsumerLag, opts *ConsumerLagOptions) {
+	if opts == nil {
+		opts = &ConsumerLagOptions{}
+	}
+	slices.SortFunc(lags, func(a, b ConsumerLag) int {
+		return cmp.Or(cmp.Compare(a.Topic, b.Topic), cmp.Compare(a.Partition, b.Partition))
+	})
+	var total, maxLag int64
+	warn := false
+	for _, p := range lags {
+		total += p.Lag
+		maxLag = max(maxLag, p.Lag)
+		if p.Lag < opts.MinLag {
+			continue
+		}
+		e := logger.Info()
+		if p.Member == "" || opts.WarnLag > 0 && p.Lag >= opts.WarnLag {
+			e = logger.Warn()
+			warn = true
+		}
+		if group != "" {
+			e = e.Str("group", group)
+		}
+		e = e.Str("topic", p.Topic).Int32("partition", p.Partition).Int64("lag", p.Lag)
+		if p.Offset >= 0 {
+			e = e.Int64("offset", p.Offset)
+		}
+		e = e.Int64("high_watermark", p.HighWatermark)
+		if p.Member != "" {
+			e = e.Str("member", p.Member)
+		}
+		e.Msg("consumer lag")
+	}
+	e := logger.Info()
+	if warn {
+		e = logger.Warn()
+	}
+	if group != "" {
+		e = e.Str("group", group)
+	}
+	e.Int("partitions", len(lags)).Int64("total_lag", total).Int64("max_lag", maxLag).Msg("consumer lag summary")
+}
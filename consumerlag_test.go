@@ -0,0 +1,92 @@
+package bolt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogConsumerLag(t *testing.T) {
+	buf := &ThreadSafeBuffer{}
+	logger := New(NewJSONHandler(buf))
+
+	LogConsumerLag(logger, "orders", []ConsumerLag{
+		{Topic: "orders", Partition: 1, Offset: 90, HighWatermark: 100, Lag: 10, Member: "c-2"},
+		{Topic: "orders", Partition: 0, Offset: -1, HighWatermark: 5000, Lag: 5000, Member: "c-1"},
+		{Topic: "audit", Partition: 0, Offset: 7, HighWatermark: 7, Lag: 0},
+		{Topic: "audit", Partition: 1, Offset: 7, HighWatermark: 8, Lag: 1, Member: "c-1"},
+	}, &ConsumerLagOptions{MinLag: 1, WarnLag: 1000})
+
+	var got []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var m map[string]any
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("invalid JSON %q: %v", line, err)
+		}
+		got = append(got, m)
+	}
+	if len(got) != 4 {
+		t.Fatalf("got %d events, want 3 partitions and a summary:\n%s", len(got), buf.String())
+	}
+	for i, want := range []struct {
+		topic     string
+		partition float64
+		level     string
+	}{{"audit", 1, "info"}, {"orders", 0, "warn"}, {"orders", 1, "info"}} {
+		ev := got[i]
+		if ev["topic"] != want.topic || ev["partition"] != want.partition || ev["level"] != want.level ||
+			ev["group"] != "orders" || ev["message"] != "consumer lag" {
+			t.Errorf("event %d = %v", i, ev)
+		}
+	}
+	if _, ok := got[1]["offset"]; ok {
+		t.Errorf("uncommitted offset logged: %v", got[1])
+	}
+	if got[1]["member"] != "c-1" || got[1]["lag"] != float64(5000) {
+		t.Errorf("event = %v", got[1])
+	}
+	sum := got[3]
+	if sum["message"] != "consumer lag summary" || sum["level"] != "warn" || sum["partitions"] != float64(4) ||
+		sum["total_lag"] != float64(5011) || sum["max_lag"] != float64(5000) {
+		t.Errorf("summary = %v", sum)
+	}
+}
+
+func TestLogConsumerLagUnassigned(t *testing.T) {
+	buf := &ThreadSafeBuffer{}
+	LogConsumerLag(New(NewJSONHandler(buf)), "", []ConsumerLag{{Topic: "t", Lag: 3}}, nil)
+	if !strings.Contains(buf.String(), `"level":"warn"`) || strings.Contains(buf.String(), `"group"`) {
+		t.Errorf("got %s", buf.String())
+	}
+}
+
+func TestStartConsumerLag(t *testing.T) {
+	buf := &ThreadSafeBuffer{}
+	logger := New(NewJSONHandler(buf))
+	calls := 0
+	stop := StartConsumerLag(logger, func(ctx context.Context) ([]ConsumerLag, error) {
+		calls++
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("source called without deadline")
+		}
+		if calls == 1 {
+			return nil, errors.New("coordinator not available")
+		}
+		return []ConsumerLag{{Topic: "t", Lag: 1, Member: "m"}}, nil
+	}, &ConsumerLagOptions{Group: "g", Interval: 5 * time.Millisecond})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(buf.String(), "consumer lag summary") && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	stop()
+	stop() // idempotent
+
+	out := buf.String()
+	if !strings.Contains(out, `"error":"coordinator not available"`) || !strings.Contains(out, "consumer lag summary") {
+		t.Errorf("got %s", out)
+	}
+}
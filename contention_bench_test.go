@@ -0,0 +1,144 @@
+package bolt
+
+import (
+	"io"
+	"math/bits"
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// BenchmarkContendedWriters measures throughput and per-call latency with
+// many goroutines sharing one sink, the situation single-sink benchmarks
+// hide: every handler serializes on a mutex, and fan-out or async
+// wrappers change where that contention lands.
+//
+// Besides ns/op (aggregate throughput), each case reports p50-ns and
+// p99-ns, the latency of a single log call as seen by one goroutine.
+// Percentiles are resolved to a power of two, which is enough to tell a
+// mutex convoy from an uncontended write. Async and worker cases also
+// report dropped/op. The Slow cases write to an output taking at least
+// 20µs per record, to show how much of a slow writer each design passes on to
+// the caller: inline writes queue callers on the handler mutex, while
+// JSONHandler workers take the wait off the logging goroutine.
+//
+//	go test -run '^$' -bench ContendedWriters -cpu 4,16
+func BenchmarkContendedWriters(b *testing.B) {
+	sinks := []struct {
+		name string
+		new  func() (Handler, func() float64)
+	}{
+		{"JSON", func() (Handler, func() float64) {
+			return NewJSONHandler(io.Discard), nil
+		}},
+		{"JSON/io.MultiWriter", func() (Handler, func() float64) {
+			return NewJSONHandler(io.MultiWriter(io.Discard, io.Discard)), nil
+		}},
+		{"MultiHandler", func() (Handler, func() float64) {
+			return MultiHandler(NewJSONHandler(io.Discard), NewJSONHandler(io.Discard)), nil
+		}},
+		{"Async", func() (Handler, func() float64) {
+			h := NewAsyncHandler(NewJSONHandler(io.Discard), nil)
+			return h, func() float64 {
+				_ = h.Close()
+				return float64(h.Dropped(LaneLow) + h.Dropped(LaneNormal) + h.Dropped(LaneHigh))
+			}
+		}},
+		{"Async/Block", func() (Handler, func() float64) {
+			h := NewAsyncHandler(NewJSONHandler(io.Discard), &AsyncOptions{
+				Classify:  func(*Event) Lane { return LaneHigh },
+				BlockHigh: true,
+			})
+			return h, func() float64 { _ = h.Close(); return float64(h.Dropped(LaneHigh)) }
+		}},
+		{"JSON/Workers=4", func() (Handler, func() float64) {
+			h := NewJSONHandlerWithOptions(io.Discard, &JSONOptions{Workers: 4})
+			return h, func() float64 { _ = h.Close(); return float64(h.Dropped()) }
+		}},
+		{"Slow/JSON", func() (Handler, func() float64) {
+			return NewJSONHandler(slowWriter{}), nil
+		}},
+		{"Slow/Workers=4", func() (Handler, func() float64) {
+			h := NewJSONHandlerWithOptions(slowWriter{}, &JSONOptions{Workers: 4, ConcurrentWrites: true})
+			return h, func() float64 { _ = h.Close(); return float64(h.Dropped()) }
+		}},
+		{"Slow/Workers=4/Drop", func() (Handler, func() float64) {
+			h := NewJSONHandlerWithOptions(slowWriter{}, &JSONOptions{Workers: 4, ConcurrentWrites: true, DropWhenFull: true})
+			return h, func() float64 { _ = h.Close(); return float64(h.Dropped()) }
+		}},
+	}
+	for _, goroutines := range []int{64, 256} {
+		for _, sink := range sinks {
+			b.Run(sink.name+"/g="+strconv.Itoa(goroutines), func(b *testing.B) {
+				h, finish := sink.new()
+				logger := New(h)
+				var hist latencyHistogram
+				b.SetParallelism((goroutines + runtime.GOMAXPROCS(0) - 1) / runtime.GOMAXPROCS(0))
+				b.ReportAllocs()
+				b.ResetTimer()
+				b.RunParallel(func(pb *testing.PB) {
+					var local latencyHistogram
+					for pb.Next() {
+						start := time.Now()
+						logger.Info().Str("component", "bench").Int("n", 42).Msg("contended")
+						local.observe(time.Since(start))
+					}
+					hist.merge(&local)
+				})
+				b.StopTimer()
+				if finish != nil {
+					b.ReportMetric(finish()/float64(b.N), "dropped/op")
+				}
+				b.ReportMetric(hist.quantile(0.50), "p50-ns")
+				b.ReportMetric(hist.quantile(0.99), "p99-ns")
+			})
+		}
+	}
+}
+
+// slowWriter simulates an output, such as a congested pipe or network
+// filesystem, that takes at least 20µs per record; timer granularity
+// often makes it longer.
+type slowWriter struct{}
+
+func (slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(20 * time.Microsecond)
+	return len(p), nil
+}
+
+// latencyHistogram counts durations in power-of-two nanosecond buckets.
+type latencyHistogram struct {
+	mu      sync.Mutex
+	buckets [64]uint64
+	n       uint64
+}
+
+func (h *latencyHistogram) observe(d time.Duration) {
+	h.buckets[bits.Len64(uint64(d))]++ // #nosec G115 -- durations are non-negative
+	h.n++
+}
+
+func (h *latencyHistogram) merge(o *latencyHistogram) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, c := range o.buckets {
+		h.buckets[i] += c
+	}
+	h.n += o.n
+}
+
+// quantile returns the upper bound, in nanoseconds, of the bucket holding
+// quantile q.
+func (h *latencyHistogram) quantile(q float64) float64 {
+	target := uint64(q * float64(h.n))
+	var seen uint64
+	for i, c := range h.buckets {
+		seen += c
+		if seen > target {
+			return float64(uint64(1) << i)
+		}
+	}
+	return 0
+}
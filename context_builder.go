@@ -0,0 +1,101 @@
+package bolt
+
+import "time"
+
+// ContextBuilder builds the context of a child logger, like
+// [Logger.With], with less copying. With starts from a fresh copy of the
+// parent's context and grows it field by field, reallocating as it goes,
+// and the child keeps the oversized result. A ContextBuilder encodes
+// into a pooled scratch buffer instead and [ContextBuilder.Logger] copies
+// the finished context into one allocation of exactly its size, which
+// matters for services deriving a logger per request:
+//
+//	reqLogger := logger.Context().
+//	    Str("request_id", id).
+//	    Str("method", r.Method).
+//	    Str("path", r.URL.Path).
+//	    Logger()
+//
+// A ContextBuilder must not be used after Logger returns.
+type ContextBuilder Event
+
+// Context starts building a child logger with l's context.
+func (l *Logger) Context() *ContextBuilder {
+	e := getEvent()
+	e.l = l
+	e.level = l.GetLevel()
+	e.buf = append(e.buf[:0], l.context...)
+	e.fields = e.fields[:0] // the builder's keys are not a record's fields
+	return (*ContextBuilder)(e)
+}
+
+// Str adds a string field.
+func (b *ContextBuilder) Str(key, value string) *ContextBuilder {
+	(*Event)(b).Str(key, value)
+	return b
+}
+
+// Strs adds a string array field.
+func (b *ContextBuilder) Strs(key string, values []string) *ContextBuilder {
+	(*Event)(b).Strs(key, values)
+	return b
+}
+
+// Int adds an integer field.
+func (b *ContextBuilder) Int(key string, value int) *ContextBuilder {
+	(*Event)(b).Int(key, value)
+	return b
+}
+
+// Int64 adds a 64-bit integer field.
+func (b *ContextBuilder) Int64(key string, value int64) *ContextBuilder {
+	(*Event)(b).Int64(key, value)
+	return b
+}
+
+// Bool adds a boolean field.
+func (b *ContextBuilder) Bool(key string, value bool) *ContextBuilder {
+	(*Event)(b).Bool(key, value)
+	return b
+}
+
+// Float64 adds a float field.
+func (b *ContextBuilder) Float64(key string, value float64) *ContextBuilder {
+	(*Event)(b).Float64(key, value)
+	return b
+}
+
+// Dur adds a duration field.
+func (b *ContextBuilder) Dur(key string, value time.Duration) *ContextBuilder {
+	(*Event)(b).Dur(key, value)
+	return b
+}
+
+// Any adds a field of any type, encoded like [Event.Any].
+func (b *ContextBuilder) Any(key string, value interface{}) *ContextBuilder {
+	(*Event)(b).Any(key, value)
+	return b
+}
+
+// Logger returns the child logger and recycles the builder.
+func (b *ContextBuilder) Logger() *Logger {
+	e := (*Event)(b)
+	buf := e.buf
+	if len(buf) > 0 && buf[0] == ',' {
+		buf = buf[1:]
+	}
+	var context []byte
+	if len(buf) > 0 {
+		context = append(make([]byte, 0, len(buf)), buf...)
+	}
+	l := e.l.derive(context)
+
+	if cap(e.buf) > PoolBufferCap {
+		e.buf = nil
+	} else {
+		e.buf = e.buf[:0]
+	}
+	e.l = nil
+	putEvent(e)
+	return l
+}
@@ -0,0 +1,88 @@
+package bolt
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestContextBuilder(t *testing.T) {
+	var buf bytes.Buffer
+	parent := New(NewJSONHandler(&buf)).With().Str("service", "api").Logger()
+
+	child := parent.Context().
+		Str("request_id", "r-1").
+		Strs("roles", []string{"admin", "ops"}).
+		Int("attempt", 2).
+		Bool("retry", true).
+		Logger()
+	want := parent.With().
+		Str("request_id", "r-1").
+		Strs("roles", []string{"admin", "ops"}).
+		Int("attempt", 2).
+		Bool("retry", true).
+		Logger()
+	if string(child.context) != string(want.context) {
+		t.Errorf("context = %s, want %s", child.context, want.context)
+	}
+	if cap(child.context) != len(child.context) {
+		t.Errorf("context cap = %d, want exactly len %d", cap(child.context), len(child.context))
+	}
+
+	// A builder on a logger without context must not leave a leading comma.
+	bare := New(NewJSONHandler(&buf)).Context().Str("k", "v").Logger()
+	if string(bare.context) != `"k":"v"` {
+		t.Errorf("context = %s, want \"k\":\"v\"", bare.context)
+	}
+
+	// The scratch buffer is recycled; the child's context must not share it.
+	parent.Context().Str("other", "x").Logger()
+	child.Info().Msg("hi")
+	if got := buf.String(); !bytes.Contains([]byte(got), []byte(`"service":"api","request_id":"r-1"`)) {
+		t.Errorf("output %s lost the child's context", got)
+	}
+}
+
+func TestContextBuilderAllocs(t *testing.T) {
+	if raceDetectorEnabled {
+		t.Skip("allocation counts are not meaningful under -race")
+	}
+	logger := New(NewJSONHandler(io.Discard)).With().Str("service", "api").Logger()
+	allocs := testing.AllocsPerRun(100, func() {
+		logger.Context().Str("request_id", "r-1").Str("method", "GET").Str("path", "/users").Logger()
+	})
+	// One allocation for the context, one for the Logger.
+	if allocs > 2 {
+		t.Errorf("Context().Logger() allocated %v times, want at most 2", allocs)
+	}
+}
+
+// BenchmarkChildLogger compares deriving a per-request logger through
+// With and through Context.
+func BenchmarkChildLogger(b *testing.B) {
+	logger := New(NewJSONHandler(io.Discard)).With().Str("service", "api").Logger()
+
+	b.Run("With", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			logger.With().
+				Str("request_id", "0f8fad5b-d9cb-469f-a165-70867728950e").
+				Str("method", "GET").
+				Str("path", "/api/v1/users").
+				Str("user_agent", "Mozilla/5.0").
+				Logger()
+		}
+	})
+
+	b.Run("Context", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			logger.Context().
+				Str("request_id", "0f8fad5b-d9cb-469f-a165-70867728950e").
+				Str("method", "GET").
+				Str("path", "/api/v1/users").
+				Str("user_agent", "Mozilla/5.0").
+				Logger()
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package bolt
+
+import (
+	"context"
+
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+type ctxLoggerKey struct{}
+
+// cachedCtxLogger is the result of Logger.Ctx stored by CacheCtx, with
+// the inputs it was derived from.
+type cachedCtxLogger struct {
+	base     *Logger
+	traceID  oteltrace.TraceID
+	spanID   oteltrace.SpanID
+	level    Level
+	elevated bool
+	logger   *Logger
+}
+
+func (c *cachedCtxLogger) matches(base *Logger, sc oteltrace.SpanContext, level Level, elevated bool) bool {
+	return c.base == base &&
+		c.traceID == sc.TraceID() &&
+		c.spanID == sc.SpanID() &&
+		c.elevated == elevated &&
+		(!elevated || c.level == level)
+}
+
+// CacheCtx returns a copy of ctx that carries l.Ctx(ctx), so later calls
+// to l.Ctx with that context, or with any context derived from it inside
+// the same span, return the stored logger instead of re-encoding the
+// trace and span IDs. Call it once where a span starts:
+//
+//	ctx, span := tracer.Start(ctx, "handle")
+//	defer span.End()
+//	ctx = logger.CacheCtx(ctx)
+//	...
+//	logger.Ctx(ctx).Info().Msg("step") // no re-encoding
+//
+// The cache is keyed on the logger, the span and the [DebugBaggageKey]
+// override, so starting a child span, changing the baggage or calling Ctx
+// on a different logger falls back to deriving a fresh logger.
+func (l *Logger) CacheCtx(ctx context.Context) context.Context {
+	sc := oteltrace.SpanContextFromContext(ctx)
+	level, elevate := baggageLevel(ctx)
+	return context.WithValue(ctx, ctxLoggerKey{}, &cachedCtxLogger{
+		base:     l,
+		traceID:  sc.TraceID(),
+		spanID:   sc.SpanID(),
+		level:    level,
+		elevated: elevate,
+		logger:   l.deriveCtx(ctx, sc, level, elevate),
+	})
+}
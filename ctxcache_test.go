@@ -0,0 +1,72 @@
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func spanCtx(parent context.Context, spanByte byte) context.Context {
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+		SpanID:  trace.SpanID{1, 2, 3, 4, 5, 6, 7, spanByte},
+	})
+	return trace.ContextWithSpanContext(parent, sc)
+}
+
+func TestCacheCtxReusesLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewJSONHandler(&buf))
+	ctx := logger.CacheCtx(spanCtx(context.Background(), 1))
+
+	type key struct{}
+	child := context.WithValue(ctx, key{}, "v") // same span
+	if logger.Ctx(ctx) != logger.Ctx(child) {
+		t.Error("Ctx did not reuse the cached logger within the span")
+	}
+	logger.Ctx(child).Info().Msg("cached")
+	if !strings.Contains(buf.String(), `"span_id":"0102030405060701"`) {
+		t.Errorf("cached logger lost trace fields: %s", buf.String())
+	}
+
+	if logger.Ctx(spanCtx(ctx, 2)) == logger.Ctx(ctx) {
+		t.Error("child span reused the parent span's logger")
+	}
+	other := New(NewJSONHandler(io.Discard))
+	if other.Ctx(ctx) == logger.Ctx(ctx) {
+		t.Error("cached logger returned for a different base logger")
+	}
+	if logger.Ctx(withDebugBaggageIn(t, ctx, "1")) == logger.Ctx(ctx) {
+		t.Error("baggage override ignored by the cache")
+	}
+}
+
+// withDebugBaggageIn adds the debug baggage member to parent, keeping its
+// span and cached logger.
+func withDebugBaggageIn(t *testing.T, parent context.Context, value string) context.Context {
+	t.Helper()
+	return baggage.ContextWithBaggage(parent, baggage.FromContext(withDebugBaggage(t, value)))
+}
+
+func BenchmarkCtx(b *testing.B) {
+	logger := New(NewJSONHandler(io.Discard))
+	ctx := spanCtx(context.Background(), 1)
+	b.Run("uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			logger.Ctx(ctx).Info().Msg("x")
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		cached := logger.CacheCtx(ctx)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			logger.Ctx(cached).Info().Msg("x")
+		}
+	})
+}
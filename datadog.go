@@ -0,0 +1,145 @@
+package bolt
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// DatadogOptions configures [NewDatadogHandler] and [Datadog]. A nil
+// *DatadogOptions uses the defaults.
+type DatadogOptions struct {
+	// Service, Env and Version are the unified service tags written as
+	// "dd.service", "dd.env" and "dd.version". They default to $DD_SERVICE,
+	// $DD_ENV and $DD_VERSION; empty tags are omitted.
+	Service, Env, Version string
+
+	// Severities provides the level to status mapping. Defaults to
+	// DefaultSeverities.
+	Severities *Severities
+}
+
+// DatadogHandler writes records in the JSON layout the Datadog Agent
+// correlates with APM traces:
+//
+//	{"status":"error","dd.service":"billing","dd.env":"prod","dd.version":"1.4.2",
+//	 "dd.trace_id":"11803532876627986230","dd.span_id":"67667974448284343","message":"charge failed",...}
+//
+// "level" becomes "status", mapped through [Severities].Datadog. The
+// hexadecimal OpenTelemetry "trace_id" and "span_id" added by
+// [Logger.Ctx] become "dd.trace_id" and "dd.span_id" in Datadog's
+// decimal 64-bit form: the span ID as is and the low 64 bits of the
+// 128-bit trace ID, as Datadog tracers do. IDs that are not hexadecimal
+// are kept under their original keys. All other fields are written
+// unchanged. Like [JSONHandler], DatadogHandler is safe for concurrent use
+// and writes each record with one Write call.
+type DatadogHandler struct {
+	mu     sync.Mutex
+	out    io.Writer
+	tags   []byte // pre-encoded unified service tags
+	status SeverityMap[string]
+
+	line  []byte
+	spans []FieldSpan
+}
+
+// NewDatadogHandler returns a DatadogHandler writing to out, usually
+// os.Stdout for the Agent to tail.
+func NewDatadogHandler(out io.Writer, opts *DatadogOptions) *DatadogHandler {
+	if opts == nil {
+		opts = &DatadogOptions{}
+	}
+	sev := opts.Severities
+	if sev == nil {
+		sev = &DefaultSeverities
+	}
+	h := &DatadogHandler{out: out, status: sev.Datadog}
+	for _, t := range []struct{ key, value, env string }{
+		{"dd.service", opts.Service, "DD_SERVICE"},
+		{"dd.env", opts.Env, "DD_ENV"},
+		{"dd.version", opts.Version, "DD_VERSION"},
+	} {
+		if t.value == "" {
+			t.value = os.Getenv(t.env)
+		}
+		if t.value != "" {
+			h.tags = append(h.tags, ",\""+t.key+"\":\""...)
+			h.tags = appendJSONString(h.tags, t.value)
+			h.tags = append(h.tags, '"')
+		}
+	}
+	return h
+}
+
+// Datadog returns a logger configured like [Production] that writes the
+// Datadog layout of [NewDatadogHandler] to stdout.
+func Datadog(opts *DatadogOptions) *Logger {
+	return production(NewDatadogHandler(os.Stdout, opts))
+}
+
+// Write implements [Handler].
+func (h *DatadogHandler) Write(e *Event) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	buf := e.buf
+	h.spans = appendFieldSpans(h.spans[:0], buf, 1)
+
+	line := append(h.line[:0], `{"status":"`...)
+	line = append(line, h.status.Of(e.level)...)
+	line = append(line, '"')
+	line = append(line, h.tags...)
+	for _, sp := range h.spans {
+		key := sp.Key(buf)
+		switch string(key) {
+		case "level":
+			continue
+		case "trace_id", "span_id":
+			if id, ok := datadogID(sp.Value(buf)); ok {
+				line = append(line, `,"dd.`...)
+				line = append(line, key...)
+				line = append(line, `":"`...)
+				line = strconv.AppendUint(line, id, 10)
+				line = append(line, '"')
+				continue
+			}
+		}
+		line = append(line, ',')
+		line = append(line, buf[sp.Start:sp.End]...)
+	}
+	line = append(line, '}', '\n')
+	h.line = line
+
+	if _, err := h.out.Write(line); err != nil {
+		return fmt.Errorf("failed to write record: %w", err)
+	}
+	return nil
+}
+
+// datadogID converts a quoted hexadecimal OpenTelemetry ID to Datadog's
+// 64-bit form: the low 64 bits, which for a span ID are the whole ID.
+func datadogID(v []byte) (uint64, bool) {
+	if len(v) < 3 || v[0] != '"' || v[len(v)-1] != '"' {
+		return 0, false
+	}
+	hex := v[1 : len(v)-1]
+	if len(hex) > 32 {
+		return 0, false
+	}
+	var id uint64 // shifting drops the high bits of 128-bit IDs
+	for _, c := range hex {
+		switch {
+		case c >= '0' && c <= '9':
+			c -= '0'
+		case c >= 'a' && c <= 'f':
+			c -= 'a' - 10
+		case c >= 'A' && c <= 'F':
+			c -= 'A' - 10
+		default:
+			return 0, false
+		}
+		id = id<<4 | uint64(c)
+	}
+	return id, true
+}
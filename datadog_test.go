@@ -0,0 +1,46 @@
+package bolt
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDatadogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewDatadogHandler(&buf, &DatadogOptions{Service: "billing", Env: "prod", Version: "1.4.2"}))
+
+	logger.Error().
+		Str("trace_id", "4bf92f3577b34da6a3ce929d0e0e4736").
+		Str("span_id", "00f067aa0ba902b7").
+		Str("order_id", "o-17").
+		Msg("charge failed")
+	logger.Trace().Str("trace_id", "not-hex").Msg("kept")
+
+	want := `{"status":"error","dd.service":"billing","dd.env":"prod","dd.version":"1.4.2",` +
+		`"dd.trace_id":"11803532876627986230","dd.span_id":"67667974448284343","order_id":"o-17","message":"charge failed"}` + "\n" +
+		`{"status":"debug","dd.service":"billing","dd.env":"prod","dd.version":"1.4.2","trace_id":"not-hex","message":"kept"}` + "\n"
+	if buf.String() != want {
+		t.Errorf("got  %s\nwant %s", buf.String(), want)
+	}
+}
+
+func TestDatadogHandlerEnvTags(t *testing.T) {
+	t.Setenv("DD_SERVICE", "api")
+	t.Setenv("DD_ENV", "")
+	t.Setenv("DD_VERSION", "")
+	var buf bytes.Buffer
+	New(NewDatadogHandler(&buf, nil)).Info().Msg("up")
+	if want := `{"status":"info","dd.service":"api","message":"up"}` + "\n"; buf.String() != want {
+		t.Errorf("got %s, want %s", buf.String(), want)
+	}
+}
+
+func TestDatadogPreset(t *testing.T) {
+	logger := Datadog(nil)
+	if logger.GetLevel() != INFO {
+		t.Errorf("level = %v, want INFO", logger.GetLevel())
+	}
+	if _, ok := logger.handler.load().(*DatadogHandler); !ok {
+		t.Errorf("handler = %T, want *DatadogHandler", logger.handler.load())
+	}
+}
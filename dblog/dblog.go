@@ -0,0 +1,189 @@
+// Package dblog writes one structured bolt event per database command,
+// with the same fields and levels whatever the client library.
+//
+// The client integrations build on it: [NewElasticsearchLogger] here for
+// go-elasticsearch, and the redislog and mongolog modules for go-redis
+// and the MongoDB driver, which live in their own go.mod files so that
+// the bolt core does not depend on the drivers.
+//
+// Every command is logged as
+//
+//	{"level":"debug","db":"redis","operation":"SET","statement":"SET user:42 ?","duration":412000,"message":"db command"}
+//
+// Successful commands are logged at DEBUG, so they cost nothing until
+// the logger is turned down. Failed commands are logged at ERROR with "error" and an "error_class"
+// from [Classify]; commands slower than [Options.SlowThreshold] at WARN.
+// Values are redacted by default: statements keep their shape and keys
+// but show "?" for values, unless [Options.LogValues] is set.
+package dblog
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"time"
+
+	"go.klarlabs.de/bolt"
+)
+
+// DefaultSlowThreshold is the duration above which a command is logged at
+// WARN when no threshold is configured.
+const DefaultSlowThreshold = 200 * time.Millisecond
+
+// Redacted replaces values in statements when values are not logged.
+const Redacted = "?"
+
+// Error classes reported as "error_class".
+const (
+	ClassTimeout   = "timeout"
+	ClassCanceled  = "canceled"
+	ClassNetwork   = "network"
+	ClassNotFound  = "not_found"
+	ClassConflict  = "conflict"
+	ClassThrottled = "throttled"
+	ClassAuth      = "auth"
+	ClassClient    = "client"
+	ClassServer    = "server"
+	ClassOther     = "other"
+)
+
+// Options configures [New]. A nil *Options uses the defaults.
+type Options struct {
+	// SlowThreshold logs successful commands slower than this at WARN.
+	// Defaults to DefaultSlowThreshold; a negative value disables it.
+	SlowThreshold time.Duration
+
+	// LogValues logs statements with their values. Off by default
+	// because values routinely hold personal data and secrets.
+	LogValues bool
+
+	// Classify overrides the error classification; returning "" falls
+	// back to [Classify].
+	Classify func(err error) string
+}
+
+// Command is one database command to log.
+type Command struct {
+	System     string // "redis", "mongodb", "elasticsearch", ...
+	Operation  string // command name, such as "GET" or "find"
+	Statement  string // the command, already redacted unless values are logged
+	Database   string
+	Collection string // collection, index or table, if any
+	Duration   time.Duration
+	Err        error
+
+	// ErrClass, if set, is the error class, as a driver integration
+	// knows it better than [Classify] can, for example "not_found" for
+	// redis.Nil. Commands whose class is "not_found" are logged as
+	// successful.
+	ErrClass string
+}
+
+// Logger logs database commands to a bolt logger.
+type Logger struct {
+	logger *bolt.Logger
+	slow   time.Duration
+	values bool
+	class  func(err error) string
+}
+
+// New returns a Logger writing to logger.
+func New(logger *bolt.Logger, opts *Options) *Logger {
+	if opts == nil {
+		opts = &Options{}
+	}
+	l := &Logger{logger: logger, slow: opts.SlowThreshold, values: opts.LogValues, class: opts.Classify}
+	if l.slow == 0 {
+		l.slow = DefaultSlowThreshold
+	}
+	return l
+}
+
+// LogValues reports whether statements should carry their values.
+// Integrations consult it when building [Command.Statement].
+func (l *Logger) LogValues() bool {
+	return l.values
+}
+
+// Log writes c as one event. ctx links the event to the surrounding
+// work with [bolt.Event.Link].
+func (l *Logger) Log(ctx context.Context, c Command) {
+	class := c.ErrClass
+	if c.Err != nil && class == "" && l.class != nil {
+		class = l.class(c.Err)
+	}
+	if c.Err != nil && class == "" {
+		class = Classify(c.Err)
+	}
+	failed := c.Err != nil && class != ClassNotFound
+
+	var e *bolt.Event
+	switch {
+	case failed:
+		e = l.logger.Error()
+	case l.slow > 0 && c.Duration > l.slow:
+		e = l.logger.Warn()
+	default:
+		e = l.logger.Debug()
+	}
+	e = e.Link(ctx).Str("db", c.System).Str("operation", c.Operation)
+	if c.Statement != "" {
+		e = e.Str("statement", c.Statement)
+	}
+	if c.Database != "" {
+		e = e.Str("database", c.Database)
+	}
+	if c.Collection != "" {
+		e = e.Str("collection", c.Collection)
+	}
+	e = e.Dur("duration", c.Duration)
+	if c.Err != nil {
+		e = e.Err(c.Err).Str("error_class", class)
+	}
+	e.Msg("db command")
+}
+
+// Classify returns the class of a command error: timeout, canceled or
+// network for the failures every driver shares, otherwise "other".
+// Integrations refine it with driver-specific classes.
+func Classify(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, os.ErrDeadlineExceeded):
+		return ClassTimeout
+	case errors.Is(err, context.Canceled):
+		return ClassCanceled
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return ClassTimeout
+	case errors.As(err, &netErr), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.EPIPE):
+		return ClassNetwork
+	}
+	return ClassOther
+}
+
+// ClassifyStatus returns the error class of an HTTP status, for clients
+// of HTTP-based stores, or "" for success statuses.
+func ClassifyStatus(status int) string {
+	switch {
+	case status < 400:
+		return ""
+	case status == 404:
+		return ClassNotFound
+	case status == 409:
+		return ClassConflict
+	case status == 429:
+		return ClassThrottled
+	case status == 401 || status == 403:
+		return ClassAuth
+	case status == 408 || status == 504:
+		return ClassTimeout
+	case status >= 500:
+		return ClassServer
+	default:
+		return ClassClient
+	}
+}
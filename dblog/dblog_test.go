@@ -0,0 +1,144 @@
+package dblog_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.klarlabs.de/bolt"
+	"go.klarlabs.de/bolt/dblog"
+)
+
+func events(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var m map[string]any
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("invalid JSON %q: %v", line, err)
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+func newLogger(buf *bytes.Buffer, opts *dblog.Options) *dblog.Logger {
+	return dblog.New(bolt.New(bolt.NewJSONHandler(buf)).SetLevel(bolt.DEBUG), opts)
+}
+
+func TestLogLevels(t *testing.T) {
+	var buf bytes.Buffer
+	l := newLogger(&buf, nil)
+	ctx := context.Background()
+	l.Log(ctx, dblog.Command{System: "redis", Operation: "GET", Statement: "GET ?", Duration: time.Millisecond})
+	l.Log(ctx, dblog.Command{System: "redis", Operation: "GET", Duration: time.Second})
+	l.Log(ctx, dblog.Command{System: "redis", Operation: "GET", Err: context.DeadlineExceeded})
+	l.Log(ctx, dblog.Command{System: "redis", Operation: "GET", Err: errors.New("redis: nil"), ErrClass: dblog.ClassNotFound})
+
+	evs := events(t, &buf)
+	if len(evs) != 4 {
+		t.Fatalf("got %d events: %s", len(evs), buf.String())
+	}
+	for i, want := range []struct{ level, class string }{
+		{"debug", ""}, {"warn", ""}, {"error", "timeout"}, {"debug", "not_found"},
+	} {
+		ev := evs[i]
+		if ev["level"] != want.level || ev["message"] != "db command" || ev["db"] != "redis" || ev["operation"] != "GET" {
+			t.Errorf("event %d = %v", i, ev)
+		}
+		if class, _ := ev["error_class"].(string); class != want.class {
+			t.Errorf("event %d error_class = %q, want %q", i, class, want.class)
+		}
+		if _, ok := ev["duration"]; !ok {
+			t.Errorf("event %d has no duration", i)
+		}
+	}
+	if evs[0]["statement"] != "GET ?" {
+		t.Errorf("statement = %v", evs[0]["statement"])
+	}
+}
+
+func TestLogOptions(t *testing.T) {
+	var buf bytes.Buffer
+	l := newLogger(&buf, &dblog.Options{
+		SlowThreshold: -1,
+		Classify: func(err error) string {
+			if err.Error() == "busy" {
+				return dblog.ClassThrottled
+			}
+			return ""
+		},
+	})
+	l.Log(context.Background(), dblog.Command{System: "sql", Operation: "select", Duration: time.Hour})
+	l.Log(context.Background(), dblog.Command{System: "sql", Operation: "select", Err: errors.New("busy")})
+	l.Log(context.Background(), dblog.Command{System: "sql", Operation: "select", Err: io.EOF})
+
+	evs := events(t, &buf)
+	if evs[0]["level"] != "debug" {
+		t.Errorf("negative threshold still logged slow command: %v", evs[0])
+	}
+	if evs[1]["error_class"] != "throttled" || evs[2]["error_class"] != "network" {
+		t.Errorf("classes = %v, %v", evs[1]["error_class"], evs[2]["error_class"])
+	}
+	if l.LogValues() {
+		t.Error("values logged by default")
+	}
+}
+
+func TestClassifyStatus(t *testing.T) {
+	for status, want := range map[int]string{
+		200: "", 404: "not_found", 409: "conflict", 429: "throttled", 403: "auth",
+		504: "timeout", 500: "server", 400: "client",
+	} {
+		if got := dblog.ClassifyStatus(status); got != want {
+			t.Errorf("ClassifyStatus(%d) = %q, want %q", status, got, want)
+		}
+	}
+}
+
+func TestElasticsearchLogger(t *testing.T) {
+	var buf bytes.Buffer
+	es := dblog.NewElasticsearchLogger(newLogger(&buf, nil))
+	if es.RequestBodyEnabled() || es.ResponseBodyEnabled() {
+		t.Error("bodies enabled")
+	}
+
+	get := httptest.NewRequest(http.MethodGet, "/users/_doc/42?routing=eu&refresh=true", nil)
+	_ = es.LogRoundTrip(get, &http.Response{StatusCode: 404, Status: "404 Not Found"}, nil, time.Now(), time.Millisecond)
+	search := httptest.NewRequest(http.MethodPost, "/users/_search", nil)
+	_ = es.LogRoundTrip(search, &http.Response{StatusCode: 503, Status: "503 Service Unavailable"}, nil, time.Now(), time.Millisecond)
+	bulk := httptest.NewRequest(http.MethodPost, "/_bulk", nil)
+	_ = es.LogRoundTrip(bulk, nil, io.ErrUnexpectedEOF, time.Now(), time.Millisecond)
+
+	evs := events(t, &buf)
+	if len(evs) != 3 {
+		t.Fatalf("got %d events: %s", len(evs), buf.String())
+	}
+	if ev := evs[0]; ev["level"] != "debug" || ev["operation"] != "get" || ev["collection"] != "users" ||
+		ev["statement"] != "GET /users/_doc/??refresh=?&routing=?" || ev["error_class"] != "not_found" {
+		t.Errorf("get = %v", ev)
+	}
+	if ev := evs[1]; ev["level"] != "error" || ev["operation"] != "search" || ev["error_class"] != "server" {
+		t.Errorf("search = %v", ev)
+	}
+	if ev := evs[2]; ev["level"] != "error" || ev["operation"] != "bulk" || ev["error_class"] != "network" {
+		t.Errorf("bulk = %v", ev)
+	}
+}
+
+func TestElasticsearchLoggerValues(t *testing.T) {
+	var buf bytes.Buffer
+	es := dblog.NewElasticsearchLogger(newLogger(&buf, &dblog.Options{LogValues: true}))
+	req := httptest.NewRequest(http.MethodDelete, "/users/_doc/42", nil)
+	_ = es.LogRoundTrip(req, &http.Response{StatusCode: 200}, nil, time.Now(), time.Millisecond)
+	if ev := events(t, &buf)[0]; ev["statement"] != "DELETE /users/_doc/42" || ev["operation"] != "delete" {
+		t.Errorf("event = %v", ev)
+	}
+}
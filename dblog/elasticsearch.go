@@ -0,0 +1,120 @@
+package dblog
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ElasticsearchLogger logs the requests of a go-elasticsearch client. It
+// implements the elastictransport.Logger interface, so it plugs into the
+// client configuration without this package importing the client:
+//
+//	es, err := elasticsearch.NewClient(elasticsearch.Config{
+//	    Logger: dblog.NewElasticsearchLogger(dblog.New(logger, nil)),
+//	})
+//
+// The operation is the API called, such as "search", "bulk" or "get",
+// and the collection the index. Document IDs and query parameter values
+// are redacted unless values are logged; request and response bodies
+// are never logged.
+type ElasticsearchLogger struct {
+	l *Logger
+}
+
+// NewElasticsearchLogger returns an ElasticsearchLogger writing to l.
+func NewElasticsearchLogger(l *Logger) *ElasticsearchLogger {
+	return &ElasticsearchLogger{l: l}
+}
+
+// LogRoundTrip logs one request. Responses with an error status are
+// logged as failed commands, classified by [ClassifyStatus].
+func (e *ElasticsearchLogger) LogRoundTrip(req *http.Request, resp *http.Response, err error, _ time.Time, d time.Duration) error {
+	c := Command{System: "elasticsearch", Duration: d, Err: err}
+	ctx := context.Background()
+	if req != nil {
+		ctx = req.Context()
+		c.Operation, c.Collection = esOperation(req.Method, req.URL.Path)
+		c.Statement = e.statement(req)
+	}
+	if err == nil && resp != nil {
+		if class := ClassifyStatus(resp.StatusCode); class != "" {
+			c.Err, c.ErrClass = fmt.Errorf("elasticsearch: %s", resp.Status), class
+		}
+	}
+	e.l.Log(ctx, c)
+	return nil
+}
+
+// RequestBodyEnabled implements elastictransport.Logger. Bodies are not
+// logged.
+func (e *ElasticsearchLogger) RequestBodyEnabled() bool { return false }
+
+// ResponseBodyEnabled implements elastictransport.Logger. Bodies are not
+// logged.
+func (e *ElasticsearchLogger) ResponseBodyEnabled() bool { return false }
+
+// statement returns the method and path of req, redacted unless values
+// are logged.
+func (e *ElasticsearchLogger) statement(req *http.Request) string {
+	if e.l.LogValues() {
+		return req.Method + " " + req.URL.RequestURI()
+	}
+	segs := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	for i, seg := range segs {
+		// Document IDs follow an endpoint such as _doc; names starting
+		// with "_" are endpoints, the first segment is the index.
+		if i > 0 && !strings.HasPrefix(seg, "_") {
+			segs[i] = Redacted
+		}
+	}
+	s := req.Method + " /" + strings.Join(segs, "/")
+	if q := req.URL.Query(); len(q) > 0 {
+		keys := make([]string, 0, len(q))
+		for k := range q {
+			keys = append(keys, url.QueryEscape(k)+"="+Redacted)
+		}
+		sort.Strings(keys)
+		s += "?" + strings.Join(keys, "&")
+	}
+	return s
+}
+
+// esOperation returns the API called by method and path, and the index
+// it targets.
+func esOperation(method, path string) (op, index string) {
+	var endpoint string
+	segs := strings.Split(strings.Trim(path, "/"), "/")
+	for i, seg := range segs {
+		if strings.HasPrefix(seg, "_") {
+			endpoint = seg[1:]
+			break
+		}
+		if i == 0 {
+			index = seg
+		}
+	}
+	switch {
+	case endpoint == "doc" || endpoint == "create":
+		switch method {
+		case http.MethodGet, http.MethodHead:
+			return "get", index
+		case http.MethodDelete:
+			return "delete", index
+		}
+		return "index", index
+	case endpoint != "":
+		return endpoint, index
+	case index == "":
+		return "info", ""
+	case method == http.MethodDelete:
+		return "delete_index", index
+	case method == http.MethodPut:
+		return "create_index", index
+	}
+	return "get_index", index
+}
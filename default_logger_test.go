@@ -14,8 +14,8 @@ func TestDefaultLogger_FormatEnvVar(t *testing.T) {
 		os.Setenv("BOLT_FORMAT", "json")
 		initDefaultLogger()
 
-		if _, ok := defaultLogger.handler.(*JSONHandler); !ok {
-			t.Errorf("Expected JSONHandler, got %T", defaultLogger.handler)
+		if _, ok := defaultLogger.handler.load().(*JSONHandler); !ok {
+			t.Errorf("Expected JSONHandler, got %T", defaultLogger.handler.load())
 		}
 	})
 
@@ -23,8 +23,8 @@ func TestDefaultLogger_FormatEnvVar(t *testing.T) {
 		os.Setenv("BOLT_FORMAT", "console")
 		initDefaultLogger()
 
-		if _, ok := defaultLogger.handler.(*ConsoleHandler); !ok {
-			t.Errorf("Expected ConsoleHandler, got %T", defaultLogger.handler)
+		if _, ok := defaultLogger.handler.load().(*ConsoleHandler); !ok {
+			t.Errorf("Expected ConsoleHandler, got %T", defaultLogger.handler.load())
 		}
 	})
 
@@ -46,8 +46,8 @@ func TestDefaultLogger_Isatty(t *testing.T) {
 		isTerminal = func(*os.File) bool { return true }
 		initDefaultLogger()
 
-		if _, ok := defaultLogger.handler.(*ConsoleHandler); !ok {
-			t.Errorf("Expected ConsoleHandler when isatty is true, got %T", defaultLogger.handler)
+		if _, ok := defaultLogger.handler.load().(*ConsoleHandler); !ok {
+			t.Errorf("Expected ConsoleHandler when isatty is true, got %T", defaultLogger.handler.load())
 		}
 	})
 
@@ -56,8 +56,8 @@ func TestDefaultLogger_Isatty(t *testing.T) {
 		isTerminal = func(*os.File) bool { return false }
 		initDefaultLogger()
 
-		if _, ok := defaultLogger.handler.(*JSONHandler); !ok {
-			t.Errorf("Expected JSONHandler when isatty is false, got %T", defaultLogger.handler)
+		if _, ok := defaultLogger.handler.load().(*JSONHandler); !ok {
+			t.Errorf("Expected JSONHandler when isatty is false, got %T", defaultLogger.handler.load())
 		}
 	})
 }
@@ -296,3 +296,35 @@ func BenchmarkDefaultLoggerConcurrent(b *testing.B) {
 		}
 	})
 }
+
+func TestFormattedMessages(t *testing.T) {
+	var buf bytes.Buffer
+	defaultLogger = New(NewJSONHandler(&buf)).SetLevel(INFO)
+
+	Infof("user %s logged in %d times", "alice", 3)
+	Errorf("disk %d%% full", 95)
+	Debugf("hidden %d", 1)
+	defaultLogger.Warnf("retry %d", 2)
+	defaultLogger.Info().Str("k", "v").Msgf("%s-%s", "a", "b")
+
+	want := []string{
+		`{"level":"info","message":"user alice logged in 3 times"}`,
+		`{"level":"error","message":"disk 95% full"}`,
+		`{"level":"warn","message":"retry 2"}`,
+		`{"level":"info","k":"v","message":"a-b"}`,
+	}
+	got := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if raceDetectorEnabled {
+		t.Skip("allocation counts are not meaningful under -race")
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		defaultLogger.Debugf("hidden %s", "x")
+	})
+	if allocs != 0 {
+		t.Errorf("disabled Debugf allocated %v times", allocs)
+	}
+}
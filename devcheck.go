@@ -0,0 +1,196 @@
+package bolt
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"weak"
+)
+
+// DevChecksEnv is the environment variable that enables development
+// checks at startup, reporting to os.Stderr, when set to "1" or "true".
+const DevChecksEnv = "BOLT_DEV_CHECKS"
+
+// devChecks holds the *io.Writer development warnings go to; nil when the
+// checks are off.
+var devChecks atomic.Pointer[io.Writer]
+
+func init() {
+	if v := os.Getenv(DevChecksEnv); v == "1" || v == "true" {
+		SetDevChecks(os.Stderr)
+	}
+}
+
+// SetDevChecks turns development-mode configuration checks on, writing
+// warnings to w, or off when w is nil. The checks are meant for local
+// runs and CI, not production: they record where every logger was
+// created and check each logger's configuration when it writes its first
+// event.
+//
+// While enabled, bolt warns when two loggers with conflicting
+// configurations — different output formats or envelope versions, or
+// with and without a key replacer — write to the same output, since interleaving them in one
+// stream breaks downstream parsers. Each conflicting pair is reported
+// once:
+//
+//	bolt: dev check: loggers configured at main.go:21 (json) and
+//	worker/pool.go:40 (console) write to /dev/stdout with different configurations
+//
+// Outputs are matched by file name for *os.File and by identity for other
+// pointer writers; writers of other kinds are not tracked.
+//
+// It also reports context extractors that return values too large or
+// impossible to encode; see [Logger.AddContextExtractor].
+func SetDevChecks(w io.Writer) {
+	if w == nil {
+		devChecks.Store(nil)
+		return
+	}
+	devChecks.Store(&w)
+}
+
+// outputDescriber is implemented by handlers that can report the outputs
+// they write to and the record format used for each.
+type outputDescriber interface {
+	describeOutputs(yield func(out io.Writer, format string))
+}
+
+func (h *JSONHandler) describeOutputs(yield func(io.Writer, string))    { yield(h.out, "json") }
+func (h *ConsoleHandler) describeOutputs(yield func(io.Writer, string)) { yield(h.out, "console") }
+func (h *BSONHandler) describeOutputs(yield func(io.Writer, string))    { yield(h.out, "bson") }
+func (h *GCPHandler) describeOutputs(yield func(io.Writer, string))     { yield(h.out, "gcp") }
+func (h *DatadogHandler) describeOutputs(yield func(io.Writer, string)) { yield(h.out, "datadog") }
+func (h *ECSHandler) describeOutputs(yield func(io.Writer, string))     { yield(h.out, "ecs") }
+
+func (h *EncoderHandler) describeOutputs(yield func(io.Writer, string)) {
+	if _, ok := h.enc.(JSONEncoder); ok {
+		yield(h.out, "json")
+		return
+	}
+	yield(h.out, fmt.Sprintf("%T", h.enc))
+}
+
+func (h *AsyncHandler) describeOutputs(yield func(io.Writer, string)) {
+	describeOutputs(h.next, yield)
+}
+
+func (b *BroadcastHandler) describeOutputs(yield func(io.Writer, string)) {
+	describeOutputs(b.next, yield)
+}
+
+func (m *multiHandler) describeOutputs(yield func(io.Writer, string)) {
+	for _, h := range m.handlers {
+		describeOutputs(h, yield)
+	}
+}
+
+func (h *EnvelopeHandler) describeOutputs(yield func(io.Writer, string)) {
+	describeOutputs(h.next, func(out io.Writer, format string) {
+		yield(out, format+" envelope "+string(h.prefix[len(`{"v":`):len(h.prefix)-len(`,"data":`)]))
+	})
+}
+
+func describeOutputs(h Handler, yield func(io.Writer, string)) {
+	if d, ok := h.(outputDescriber); ok {
+		d.describeOutputs(yield)
+	}
+}
+
+// outputUse is the configuration a logger writes to an output with.
+// Loggers are tracked through weak pointers so the registry does not keep
+// short-lived loggers alive.
+type outputUse struct {
+	config string
+	where  string // location the logger was configured at
+}
+
+var devRegistry struct {
+	mu       sync.Mutex
+	outputs  map[any]map[weak.Pointer[Logger]]outputUse
+	reported map[[2]string]bool
+}
+
+// markDrift schedules a drift check for l's next event when development
+// checks are on, remembering the caller skip frames up as the place l
+// was configured.
+func (l *Logger) markDrift(skip int) {
+	if devChecks.Load() == nil {
+		return
+	}
+	l.devWhere = "unknown"
+	if pc, file, line, ok := runtime.Caller(skip + 1); ok {
+		var fn string
+		if f := runtime.FuncForPC(pc); f != nil {
+			fn = f.Name()
+		}
+		l.devWhere = callerPath(file, fn) + ":" + strconv.Itoa(line)
+	}
+	l.devPending.Store(true)
+}
+
+// checkDrift records l's outputs and warns about outputs already used
+// with a different configuration. It runs on l's first event after
+// markDrift, once the configuration is complete.
+func (l *Logger) checkDrift() {
+	wp := devChecks.Load()
+	if wp == nil || l.handler.load() == nil {
+		return
+	}
+	where := l.devWhere
+
+	self := weak.Make(l)
+	devRegistry.mu.Lock()
+	defer devRegistry.mu.Unlock()
+	if devRegistry.outputs == nil {
+		devRegistry.outputs = make(map[any]map[weak.Pointer[Logger]]outputUse)
+		devRegistry.reported = make(map[[2]string]bool)
+	}
+	describeOutputs(l.handler.load(), func(out io.Writer, format string) {
+		key, name, ok := outputKey(out)
+		if !ok {
+			return
+		}
+		use := outputUse{config: format, where: where}
+		if l.keyReplacer != nil {
+			use.config += " with key replacer"
+		}
+		uses := devRegistry.outputs[key]
+		if uses == nil {
+			uses = make(map[weak.Pointer[Logger]]outputUse)
+			devRegistry.outputs[key] = uses
+		}
+		uses[self] = use
+		for other, prev := range uses {
+			if other.Value() == nil {
+				delete(uses, other)
+				continue
+			}
+			if other == self || prev.config == use.config {
+				continue
+			}
+			pair := [2]string{prev.where + prev.config, use.where + use.config}
+			if devRegistry.reported[pair] {
+				continue
+			}
+			devRegistry.reported[pair] = true
+			fmt.Fprintf(*wp, "bolt: dev check: loggers configured at %s (%s) and %s (%s) write to %s with different configurations\n",
+				prev.where, prev.config, use.where, use.config, name)
+		}
+	})
+}
+
+// outputKey returns a comparable identity for out and a name for it.
+func outputKey(out io.Writer) (key any, name string, ok bool) {
+	if f, isFile := out.(*os.File); isFile {
+		return "file:" + f.Name(), f.Name(), true
+	}
+	if v := reflect.ValueOf(out); v.Kind() == reflect.Pointer && !v.IsNil() {
+		return out, fmt.Sprintf("%T(%p)", out, out), true
+	}
+	return nil, "", false
+}
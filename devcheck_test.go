@@ -0,0 +1,87 @@
+package bolt
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func withDevChecks(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var warnings bytes.Buffer
+	SetDevChecks(&warnings)
+	t.Cleanup(func() { SetDevChecks(nil) })
+	return &warnings
+}
+
+func TestDevChecksConflictingFormats(t *testing.T) {
+	warnings := withDevChecks(t)
+	var out bytes.Buffer
+
+	jsonLogger := New(NewJSONHandler(&out))
+	jsonLogger2 := New(NewJSONHandler(&out))
+	jsonLogger.Info().Msg("a")
+	jsonLogger2.Info().Msg("b")
+	if warnings.Len() != 0 {
+		t.Fatalf("identical configurations reported: %s", warnings)
+	}
+
+	async := NewAsyncHandler(NewConsoleHandler(&out), nil)
+	defer async.Close()
+	for range 3 {
+		l := New(async)
+		l.Info().Msg("c")
+		l.Info().Msg("d")
+	}
+	got := warnings.String()
+	if strings.Count(got, "\n") != 2 {
+		t.Errorf("want one warning per json logger, got %q", got)
+	}
+	if !strings.Contains(got, "(json)") || !strings.Contains(got, "(console)") ||
+		!strings.Contains(got, "devcheck_test.go:") {
+		t.Errorf("warning = %q", got)
+	}
+	runtime.KeepAlive(jsonLogger)
+	runtime.KeepAlive(jsonLogger2)
+}
+
+func TestDevChecksKeyReplacerAndEnvelope(t *testing.T) {
+	warnings := withDevChecks(t)
+	var out, other bytes.Buffer
+
+	replaced := New(NewJSONHandler(&out)).SetKeyReplacer(MongoKeyReplacer)
+	replaced2 := New(NewJSONHandler(&out)).SetKeyReplacer(MongoKeyReplacer)
+	replaced.Info().Msg("a")
+	replaced2.Info().Msg("b")
+	if warnings.Len() != 0 {
+		t.Fatalf("replacer set after New reported as drift: %s", warnings)
+	}
+
+	plain := New(NewJSONHandler(&out))
+	plain.Info().Msg("c")
+	if got := warnings.String(); strings.Count(got, "(json with key replacer) and") != 2 {
+		t.Errorf("missing key replacer warnings: %q", got)
+	}
+
+	warnings.Reset()
+	bare := New(NewJSONHandler(&other))
+	bare.Info().Msg("d")
+	enveloped := New(MultiHandler(NewJSONHandler(&out), NewEnvelopeHandler(NewJSONHandler(&other), 2)))
+	enveloped.Info().Msg("e")
+	if got := warnings.String(); !strings.Contains(got, "(json envelope 2)") {
+		t.Errorf("warning = %q", got)
+	}
+	runtime.KeepAlive(replaced)
+	runtime.KeepAlive(replaced2)
+	runtime.KeepAlive(plain)
+	runtime.KeepAlive(bare)
+}
+
+func TestDevChecksDisabled(t *testing.T) {
+	var out bytes.Buffer
+	l := New(NewJSONHandler(&out))
+	if l.devPending.Load() || l.devWhere != "" {
+		t.Error("logger marked for drift checks with dev checks off")
+	}
+}
@@ -0,0 +1,160 @@
+package bolt
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultDocumentKey is the field that names the documents of a
+// [DocumentHandler] when no key is configured, as written by [Event.Link].
+const DefaultDocumentKey = "event_id"
+
+// ErrDocumentName is returned, wrapped with the name, by [DirStore] for
+// names that are not a plain file name, such as "../x" or "a/b".
+var ErrDocumentName = errors.New("bolt: invalid document name")
+
+// DocumentStore stores one named document per event, for example as a
+// file or an object in a bucket.
+type DocumentStore interface {
+	// Put stores doc under name. doc must not be retained after Put
+	// returns.
+	Put(name string, doc []byte) error
+}
+
+// DocumentStoreFunc adapts a function to [DocumentStore], for example to
+// upload each document as an S3 object:
+//
+//	store := bolt.DocumentStoreFunc(func(name string, doc []byte) error {
+//	    _, err := s3Client.PutObject(ctx, &s3.PutObjectInput{
+//	        Bucket:      aws.String("audit-review"),
+//	        Key:         aws.String("events/" + name + ".json"),
+//	        Body:        bytes.NewReader(doc),
+//	        ContentType: aws.String("application/json"),
+//	        IfNoneMatch: aws.String("*"),
+//	    })
+//	    return err
+//	})
+type DocumentStoreFunc func(name string, doc []byte) error
+
+// Put implements [DocumentStore].
+func (f DocumentStoreFunc) Put(name string, doc []byte) error { return f(name, doc) }
+
+// DirStore is a [DocumentStore] writing each document to the file
+// name+".json" in a directory. Files are created with mode 0600 and
+// appear complete or not at all; an existing document is never replaced,
+// so a second document with the same name fails with an error wrapping
+// [os.ErrExist].
+type DirStore string
+
+// Put implements [DocumentStore].
+func (d DirStore) Put(name string, doc []byte) error {
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") || !filepath.IsLocal(name) {
+		return fmt.Errorf("%w %q", ErrDocumentName, name)
+	}
+	path := filepath.Join(string(d), name+".json")
+	tmp, err := os.CreateTemp(string(d), "."+name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(doc); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	// A hard link, unlike a rename, fails if the document exists.
+	return os.Link(tmp.Name(), path)
+}
+
+// DocumentOptions configures [NewDocumentHandler]. A nil
+// *DocumentOptions uses the defaults.
+type DocumentOptions struct {
+	// Key is the top-level field whose value names each document.
+	// Events without it are named by a new [EventID]. Defaults to
+	// DefaultDocumentKey.
+	Key string
+
+	// Indent is the indentation of each nesting level. Defaults to two
+	// spaces.
+	Indent string
+}
+
+// DocumentHandler writes every event as its own indented JSON document,
+// for review portals and archives that ingest individual documents
+// rather than newline-delimited streams. Documents are named by the
+// event's event_id, so that one audit event is one reviewable file or
+// object:
+//
+//	docs := bolt.NewDocumentHandler(bolt.DirStore("/var/log/audit-review"), nil)
+//	logger := bolt.New(bolt.NewRouter([]bolt.Route{
+//	    {Name: "review", Match: bolt.RouteField("audit", "true"), Handler: docs},
+//	    {Name: "stdout", Handler: bolt.NewJSONHandler(os.Stdout)},
+//	}, nil))
+//
+//	logger.Info().Link(ctx).Bool("audit", true).Str("actor", "ana").Msg("role granted")
+//
+// writes /var/log/audit-review/01J9Z3M5A1QW4Y7R2K8D6E0FXC.json:
+//
+//	{
+//	  "level": "info",
+//	  "event_id": "01J9Z3M5A1QW4Y7R2K8D6E0FXC",
+//	  "audit": true,
+//	  "actor": "ana",
+//	  "message": "role granted"
+//	}
+//
+// Every event is a store call, so DocumentHandler suits low-volume
+// streams such as audit events; route high-volume logs elsewhere.
+// DocumentHandler is safe for concurrent use if its store is.
+type DocumentHandler struct {
+	store  DocumentStore
+	key    string
+	indent string
+}
+
+// NewDocumentHandler returns a DocumentHandler writing to store.
+func NewDocumentHandler(store DocumentStore, opts *DocumentOptions) *DocumentHandler {
+	if opts == nil {
+		opts = &DocumentOptions{}
+	}
+	h := &DocumentHandler{store: store, key: opts.Key, indent: opts.Indent}
+	if h.key == "" {
+		h.key = DefaultDocumentKey
+	}
+	if h.indent == "" {
+		h.indent = "  "
+	}
+	return h
+}
+
+// Write implements [Handler].
+func (h *DocumentHandler) Write(e *Event) error {
+	var name string
+	e.WalkFields(func(k, v []byte) bool {
+		if string(k) != h.key {
+			return true
+		}
+		if len(v) > 1 && v[0] == '"' {
+			v = v[1 : len(v)-1]
+		}
+		name = string(v)
+		return false
+	})
+	if name == "" {
+		name = NewEventID().String()
+	}
+	var doc bytes.Buffer
+	doc.Grow(len(e.buf) * 2)
+	if err := json.Indent(&doc, bytes.TrimRight(e.buf, "\n"), "", h.indent); err != nil {
+		return fmt.Errorf("bolt: document %s: %w", name, err)
+	}
+	doc.WriteByte('\n')
+	return h.store.Put(name, doc.Bytes())
+}
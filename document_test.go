@@ -0,0 +1,72 @@
+package bolt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDocumentHandlerDirStore(t *testing.T) {
+	dir := t.TempDir()
+	var errs []error
+	logger := New(NewDocumentHandler(DirStore(dir), nil)).SetErrorHandler(func(err error) { errs = append(errs, err) })
+	ctx := ChildEvent(context.Background())
+	eid, _, _ := EventLinkFromContext(ctx)
+	id := eid.String()
+
+	logger.Info().Link(ctx).Str("actor", "ana").Dict("resource", func(d *Event) { d.Str("kind", "role") }).Msg("role granted")
+	data, err := os.ReadFile(filepath.Join(dir, id+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "{\n  \"level\": \"info\",\n") || !strings.Contains(string(data), "\n    \"kind\": \"role\"\n") {
+		t.Errorf("document =\n%s", data)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil || doc["event_id"] != id || doc["message"] != "role granted" {
+		t.Errorf("document = %v, %v", doc, err)
+	}
+
+	// An existing document is never replaced.
+	logger.Info().EventID("event_id", eid).Msg("again")
+	if len(errs) != 1 || !errors.Is(errs[0], os.ErrExist) {
+		t.Fatalf("errors = %v", errs)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("directory holds %d entries", len(entries))
+	}
+}
+
+func TestDocumentHandlerKey(t *testing.T) {
+	docs := map[string]string{}
+	store := DocumentStoreFunc(func(name string, doc []byte) error {
+		docs[name] = string(doc)
+		return nil
+	})
+	logger := New(NewDocumentHandler(store, &DocumentOptions{Key: "case", Indent: "\t"}))
+	logger.Info().Int("case", 42).Msg("numbered")
+	logger.Info().Msg("unnamed")
+
+	if len(docs) != 2 || !strings.Contains(docs["42"], "\n\t\"case\": 42,\n") {
+		t.Fatalf("documents = %v", docs)
+	}
+	for name := range docs {
+		if name != "42" && len(name) != 26 {
+			t.Errorf("unnamed event stored as %q", name)
+		}
+	}
+}
+
+func TestDirStoreRejectsPaths(t *testing.T) {
+	store := DirStore(t.TempDir())
+	for _, name := range []string{"", "../escape", "a/b", ".hidden"} {
+		if err := store.Put(name, []byte("{}")); !errors.Is(err, ErrDocumentName) {
+			t.Errorf("Put(%q) = %v", name, err)
+		}
+	}
+}
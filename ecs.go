@@ -0,0 +1,173 @@
+package bolt
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// ECSVersion is the Elastic Common Schema version ECSHandler declares in
+// "ecs.version".
+const ECSVersion = "8.11.0"
+
+// ecsRenames maps bolt's conventional keys to their ECS fields.
+var ecsRenames = map[string]string{
+	"trace_id": "trace.id",
+	"span_id":  "span.id",
+	"error":    "error.message",
+	"stack":    "error.stack_trace",
+	"func":     "log.origin.function",
+}
+
+// ECSOptions configures [NewECSHandler]. A nil *ECSOptions uses the
+// defaults.
+type ECSOptions struct {
+	// Service, Version and Env are written as "service.name",
+	// "service.version" and "service.environment" when set.
+	Service, Version, Env string
+}
+
+// ECSHandler writes records in Elastic Common Schema layout for
+// Elasticsearch and Filebeat:
+//
+//	{"@timestamp":"2024-05-01T12:00:00.123Z","log.level":"error","message":"charge failed",
+//	 "ecs.version":"8.11.0","service.name":"billing","trace.id":"4bf92f...","error.message":"card declined","order_id":"o-17"}
+//
+// "timestamp" or "time" becomes "@timestamp", added with the time of
+// writing when the record has neither; "level" becomes "log.level";
+// "trace_id", "span_id", "error", "stack" and "func" become "trace.id",
+// "span.id", "error.message", "error.stack_trace" and
+// "log.origin.function"; and "caller" is split into
+// "log.origin.file.name" and "log.origin.file.line". All other fields are
+// written unchanged. Like [JSONHandler], ECSHandler is safe for
+// concurrent use and writes each record with one Write call.
+type ECSHandler struct {
+	mu  sync.Mutex
+	out io.Writer
+	enc ecsEncoder
+	rec recordEncoder
+}
+
+// NewECSHandler returns an ECSHandler writing to out.
+func NewECSHandler(out io.Writer, opts *ECSOptions) *ECSHandler {
+	if opts == nil {
+		opts = &ECSOptions{}
+	}
+	h := &ECSHandler{out: out, enc: ecsEncoder{now: time.Now}}
+	for _, f := range []struct{ key, value string }{
+		{"service.name", opts.Service}, {"service.version", opts.Version}, {"service.environment", opts.Env},
+	} {
+		if f.value != "" {
+			h.enc.service = append(h.enc.service, ",\""+f.key+"\":\""...)
+			h.enc.service = appendJSONString(h.enc.service, f.value)
+			h.enc.service = append(h.enc.service, '"')
+		}
+	}
+	return h
+}
+
+// Write implements [Handler].
+func (h *ECSHandler) Write(e *Event) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, err := h.out.Write(h.rec.encode(&h.enc, e)); err != nil {
+		return fmt.Errorf("failed to write record: %w", err)
+	}
+	return nil
+}
+
+// ecsEncoder is the [Encoder] behind [ECSHandler]. ECS puts
+// "@timestamp", "log.level" and "message" first, so AppendField collects
+// the other fields and AppendEnd writes the whole record.
+type ecsEncoder struct {
+	service []byte // pre-encoded service fields
+	now     func() time.Time
+
+	ts     []byte // encoded "timestamp" or "time" value
+	hasTS  bool
+	fields []byte // encoded remaining fields, each with a leading comma
+}
+
+// AppendBegin implements [Encoder].
+func (c *ecsEncoder) AppendBegin(dst []byte, _ Level, _ []byte) []byte {
+	c.hasTS, c.fields = false, c.fields[:0]
+	return dst
+}
+
+// AppendField implements [Encoder].
+func (c *ecsEncoder) AppendField(dst []byte, kind FieldKind, key, value []byte) []byte {
+	switch string(key) {
+	case "timestamp", "time":
+		if !c.hasTS {
+			c.ts, c.hasTS = appendECSValue(c.ts[:0], kind, value), true
+			return dst
+		}
+	case "caller":
+		if kind == KindString {
+			if f, ok := appendECSOrigin(c.fields, value); ok {
+				c.fields = f
+				return dst
+			}
+		}
+	}
+	c.fields = append(c.fields, ',', '"')
+	if name, ok := ecsRenames[string(key)]; ok {
+		c.fields = append(c.fields, name...)
+	} else {
+		c.fields = appendJSONBytes(c.fields, key)
+	}
+	c.fields = append(c.fields, '"', ':')
+	c.fields = appendECSValue(c.fields, kind, value)
+	return dst
+}
+
+// AppendEnd implements [Encoder].
+func (c *ecsEncoder) AppendEnd(dst []byte, level Level, msg []byte) []byte {
+	dst = append(dst, `{"@timestamp":`...)
+	if c.hasTS {
+		dst = append(dst, c.ts...)
+	} else {
+		dst = append(dst, '"')
+		dst = c.now().UTC().AppendFormat(dst, time.RFC3339Nano)
+		dst = append(dst, '"')
+	}
+	dst = append(dst, `,"log.level":"`...)
+	dst = append(dst, level.String()...)
+	dst = append(dst, `","message":"`...)
+	dst = appendJSONBytes(dst, msg)
+	dst = append(dst, `","ecs.version":"`+ECSVersion+`"`...)
+	dst = append(dst, c.service...)
+	dst = append(dst, c.fields...)
+	return append(dst, '}', '\n')
+}
+
+// appendECSValue appends value as JSON: quoted and escaped for strings,
+// verbatim otherwise.
+func appendECSValue(dst []byte, kind FieldKind, value []byte) []byte {
+	if kind != KindString {
+		return append(dst, value...)
+	}
+	dst = append(dst, '"')
+	dst = appendJSONBytes(dst, value)
+	return append(dst, '"')
+}
+
+// appendECSOrigin splits a "file:line" caller value into the ECS origin
+// fields.
+func appendECSOrigin(line, v []byte) ([]byte, bool) {
+	i := bytes.LastIndexByte(v, ':')
+	if i < 0 || i == len(v)-1 {
+		return line, false
+	}
+	for _, c := range v[i+1:] {
+		if c < '0' || c > '9' {
+			return line, false
+		}
+	}
+	line = append(line, `,"log.origin.file.name":"`...)
+	line = appendJSONBytes(line, v[:i])
+	line = append(line, `","log.origin.file.line":`...)
+	return append(line, v[i+1:]...), true
+}
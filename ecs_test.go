@@ -0,0 +1,58 @@
+package bolt
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestECSHandler(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewECSHandler(&buf, &ECSOptions{Service: "billing", Env: "prod"})
+	h.enc.now = func() time.Time { return time.Date(2024, 5, 1, 12, 0, 0, 123e6, time.UTC) }
+	logger := New(h)
+
+	logger.Error().
+		Str("trace_id", "4bf92f3577b34da6a3ce929d0e0e4736").
+		Str("caller", "billing/charge.go:42").
+		Err(errors.New("card declined")).
+		Str("order_id", "o-17").
+		Msg("charge failed")
+	logger.Info().Str("time", "2024-01-02T03:04:05Z").Str("caller", "unknown").Msg("m")
+
+	want := `{"@timestamp":"2024-05-01T12:00:00.123Z","log.level":"error","message":"charge failed","ecs.version":"8.11.0",` +
+		`"service.name":"billing","service.environment":"prod","trace.id":"4bf92f3577b34da6a3ce929d0e0e4736",` +
+		`"log.origin.file.name":"billing/charge.go","log.origin.file.line":42,"error.message":"card declined","order_id":"o-17"}` + "\n" +
+		`{"@timestamp":"2024-01-02T03:04:05Z","log.level":"info","message":"m","ecs.version":"8.11.0",` +
+		`"service.name":"billing","service.environment":"prod","caller":"unknown"}` + "\n"
+	if buf.String() != want {
+		t.Errorf("got  %s\nwant %s", buf.String(), want)
+	}
+}
+
+func TestMultiHandlerPerSinkFormats(t *testing.T) {
+	var jsonOut, consoleOut, ecsOut bytes.Buffer
+	builds := 0
+	logger := New(MultiHandler(
+		NewJSONHandler(&jsonOut),
+		NewConsoleHandler(&consoleOut),
+		NewECSHandler(&ecsOut, nil),
+	)).AddEventHook(EventHookFunc(func(*Event, string) bool { builds++; return true }))
+
+	logger.Warn().Str("user", "ada").Msg("slow")
+
+	if builds != 1 {
+		t.Errorf("event built %d times, want once", builds)
+	}
+	if want := `{"level":"warn","user":"ada","message":"slow"}` + "\n"; jsonOut.String() != want {
+		t.Errorf("json = %s", jsonOut.String())
+	}
+	if !strings.Contains(consoleOut.String(), "slow") || strings.HasPrefix(consoleOut.String(), "{") {
+		t.Errorf("console = %q", consoleOut.String())
+	}
+	if !strings.Contains(ecsOut.String(), `"log.level":"warn","message":"slow"`) || !strings.Contains(ecsOut.String(), `"user":"ada"`) {
+		t.Errorf("ecs = %s", ecsOut.String())
+	}
+}
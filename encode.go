@@ -16,6 +16,13 @@ var hexDigits = "0123456789ABCDEF"
 
 // appendInt appends an integer to the buffer without allocations
 func appendInt(buf []byte, i int) []byte {
+	return appendInt64(buf, int64(i))
+}
+
+// appendInt64 appends a 64-bit integer to the buffer without allocations.
+// It is the path for every signed integer so that values wider than int
+// are not truncated on 32-bit platforms.
+func appendInt64(buf []byte, i int64) []byte {
 	if i == 0 {
 		return append(buf, '0')
 	}
@@ -108,6 +115,14 @@ func appendBool(buf []byte, b bool) []byte {
 func appendJSONString(buf []byte, s string) []byte {
 	// Fast path: iterate once, handling both UTF-8 validation and JSON escaping
 	for i := 0; i < len(s); {
+		// Copy runs of bytes that need no escaping in one append.
+		if n := plainRunLen(s[i:]); n > 0 {
+			buf = append(buf, s[i:i+n]...)
+			i += n
+			if i == len(s) {
+				break
+			}
+		}
 		c := s[i]
 
 		// Fast path for ASCII characters (most common case)
@@ -154,6 +169,86 @@ func appendJSONString(buf []byte, s string) []byte {
 	return buf
 }
 
+// appendJSONBytes is appendJSONString for a byte slice. Plain runs are
+// copied directly and each remaining character is escaped by
+// appendJSONString, so the two agree byte for byte without converting
+// the whole slice to a string.
+func appendJSONBytes(buf, b []byte) []byte {
+	for i := 0; i < len(b); {
+		j := i
+		for j < len(b) && b[j] >= 0x20 && b[j] != '"' && b[j] != '\\' && b[j] < utf8.RuneSelf {
+			j++
+		}
+		buf = append(buf, b[i:j]...)
+		if j == len(b) {
+			break
+		}
+		_, size := utf8.DecodeRune(b[j:])
+		buf = appendJSONString(buf, string(b[j:j+size]))
+		i = j + size
+	}
+	return buf
+}
+
+// appendJSONUnescaped appends the decoded form of the body of a JSON
+// string (without surrounding quotes) to dst. It is the inverse of
+// appendJSONString and is used to render multiline fields for humans.
+// Malformed escapes are copied through verbatim.
+func appendJSONUnescaped(dst, src []byte) []byte {
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		if c != '\\' || i+1 >= len(src) {
+			dst = append(dst, c)
+			continue
+		}
+		i++
+		switch src[i] {
+		case 'n':
+			dst = append(dst, '\n')
+		case 't':
+			dst = append(dst, '\t')
+		case 'r':
+			dst = append(dst, '\r')
+		case 'b':
+			dst = append(dst, '\b')
+		case 'f':
+			dst = append(dst, '\f')
+		case 'u':
+			if r, ok := parseHex4(src[i+1:]); ok {
+				dst = utf8.AppendRune(dst, r)
+				i += 4
+				continue
+			}
+			dst = append(dst, '\\', 'u')
+		default: // '"', '\\', '/'
+			dst = append(dst, src[i])
+		}
+	}
+	return dst
+}
+
+// parseHex4 decodes the four hex digits of a \u escape.
+func parseHex4(b []byte) (rune, bool) {
+	if len(b) < 4 {
+		return 0, false
+	}
+	var r rune
+	for _, c := range b[:4] {
+		switch {
+		case c >= '0' && c <= '9':
+			c -= '0'
+		case c >= 'a' && c <= 'f':
+			c = c - 'a' + 10
+		case c >= 'A' && c <= 'F':
+			c = c - 'A' + 10
+		default:
+			return 0, false
+		}
+		r = r<<4 | rune(c)
+	}
+	return r, true
+}
+
 // appendIP appends an IP address to the buffer without allocations.
 // IPv4 addresses use dotted-decimal notation, IPv6 uses colon-hex notation.
 func appendIP(buf []byte, ip net.IP) []byte {
@@ -0,0 +1,18 @@
+//go:build !bolt_unsafe
+
+package bolt
+
+import "unicode/utf8"
+
+// plainRunLen returns the length of the longest prefix of s made of
+// printable ASCII other than '"' and '\\', which appendJSONString copies
+// verbatim. Builds with the bolt_unsafe tag scan eight bytes at a time;
+// see encode_run_unsafe.go.
+func plainRunLen(s string) int {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c < 0x20 || c == '"' || c == '\\' || c >= utf8.RuneSelf {
+			return i
+		}
+	}
+	return len(s)
+}
@@ -0,0 +1,83 @@
+package bolt
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// plainRunLenRef is the byte-at-a-time definition both plainRunLen
+// implementations must agree with.
+func plainRunLenRef(s string) int {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c < 0x20 || c == '"' || c == '\\' || c >= 0x80 {
+			return i
+		}
+	}
+	return len(s)
+}
+
+func TestPlainRunLen(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"short",
+		"exactly8",
+		"sixteen bytes!!!",
+		"a longer plain ascii string without specials",
+		"quote at 9: abcdefgh\"",
+		"backslash late in word 2: 01234567\\89",
+		"\x1f at start",
+		"tab\tinside",
+		"ünïcode",
+		"0123456\x7f", // DEL is printable for JSON
+		strings.Repeat("x", 63) + "\"",
+		strings.Repeat("!", 8) + "\x00",
+		"##########",       // 0x23, adjacent to '"'
+		"[[[[[[[[]]]]]]]]", // neighbours of '\\'
+	} {
+		if got, want := plainRunLen(s), plainRunLenRef(s); got != want {
+			t.Errorf("plainRunLen(%q) = %d, want %d", s, got, want)
+		}
+	}
+}
+
+func FuzzPlainRunLen(f *testing.F) {
+	f.Add("plain text")
+	f.Add("with \"quotes\" and \\ backslashes")
+	f.Add("ctrl\x01\x02 and ünïcode")
+	f.Add(strings.Repeat("abcdefgh", 9) + "\n")
+	f.Fuzz(func(t *testing.T, s string) {
+		if got, want := plainRunLen(s), plainRunLenRef(s); got != want {
+			t.Fatalf("plainRunLen(%q) = %d, want %d", s, got, want)
+		}
+		out := appendJSONString([]byte{'"'}, s)
+		out = append(out, '"')
+		var decoded string
+		if err := json.Unmarshal(out, &decoded); err != nil {
+			t.Fatalf("appendJSONString(%q) produced invalid JSON %q: %v", s, out, err)
+		}
+		if utf8.ValidString(s) && decoded != s {
+			t.Fatalf("round trip of %q gave %q", s, decoded)
+		}
+	})
+}
+
+// BenchmarkAppendJSONString compares the portable and bolt_unsafe run
+// scanners; run it with and without -tags bolt_unsafe.
+func BenchmarkAppendJSONString(b *testing.B) {
+	for _, tc := range []struct{ name, s string }{
+		{"Short", "user_id"},
+		{"Plain", "GET /api/v1/users/12345/orders?limit=50 completed successfully"},
+		{"Escaped", "line one\nline \"two\"\twith\\escapes"},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			buf := make([]byte, 0, 256)
+			b.SetBytes(int64(len(tc.s)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				buf = appendJSONString(buf[:0], tc.s)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+//go:build bolt_unsafe
+
+package bolt
+
+import (
+	"encoding/binary"
+	"unicode/utf8"
+	"unsafe"
+)
+
+// SWAR constants: one per byte lane.
+const (
+	lanes01 = 0x0101010101010101
+	lanes20 = 0x2020202020202020
+	lanes22 = 0x2222222222222222 // '"'
+	lanes5C = 0x5C5C5C5C5C5C5C5C // '\\'
+	lanes80 = 0x8080808080808080
+)
+
+// plainRunLen returns the length of the longest prefix of s made of
+// printable ASCII other than '"' and '\\'. This variant, enabled by the
+// bolt_unsafe build tag, views the string's bytes in place through
+// unsafe.StringData and tests eight bytes per step; words that contain a
+// byte needing attention are finished one byte at a time. The view is
+// only read, and never outlives the call, so string immutability holds.
+//
+// It pays off for long values with few escapes (request paths, IDs, SQL)
+// and costs a few nanoseconds on short or escape-heavy strings; measure
+// with BenchmarkAppendJSONString before enabling it.
+func plainRunLen(s string) int {
+	b := unsafe.Slice(unsafe.StringData(s), len(s))
+	i := 0
+	for ; i+8 <= len(b); i += 8 {
+		w := binary.LittleEndian.Uint64(b[i:])
+		special := (w - lanes20) & ^w // bytes below 0x20
+		special |= hasZero(w ^ lanes22)
+		special |= hasZero(w ^ lanes5C)
+		if (special|w)&lanes80 != 0 {
+			break
+		}
+	}
+	for ; i < len(b); i++ {
+		if c := b[i]; c < 0x20 || c == '"' || c == '\\' || c >= utf8.RuneSelf {
+			return i
+		}
+	}
+	return len(b)
+}
+
+// hasZero sets the high bit of every lane of w that may be zero. It can
+// flag a lane after a true zero, which only ends the word scan early.
+func hasZero(w uint64) uint64 {
+	return (w - lanes01) & ^w
+}
@@ -0,0 +1,235 @@
+package bolt
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"unicode/utf8"
+)
+
+// FieldKind classifies a field value passed to an [Encoder].
+type FieldKind uint8
+
+// Field kinds.
+const (
+	KindString FieldKind = iota // the decoded string
+	KindNumber                  // the number as JSON text
+	KindBool                    // "true" or "false"
+	KindNull                    // "null"
+	KindObject                  // a nested object as raw JSON
+	KindArray                   // an array as raw JSON
+)
+
+// Encoder renders a record in one output format. Bolt builds every event
+// once as JSON; an [EncoderHandler] walks that record and calls the
+// encoder with the level and message and each field in order, so a new
+// format needs neither changes to [Event] nor a parser of its own:
+//
+//	dst = enc.AppendBegin(dst, level, msg)
+//	dst = enc.AppendField(dst, kind, key, value) // for every field
+//	dst = enc.AppendEnd(dst, level, msg)
+//
+// AppendBegin and AppendEnd both receive the level and the decoded
+// message, so the encoder can place them where its format wants them.
+// msg is nil for records sent without a message ([Event.Send]) and
+// non-nil, possibly empty, for Msg("").
+// Keys and string values are decoded; other values are their JSON text.
+// The slices are only valid during the call. The result of AppendEnd is
+// written as one record and should include any record terminator, such
+// as a newline.
+type Encoder interface {
+	AppendBegin(dst []byte, level Level, msg []byte) []byte
+	AppendField(dst []byte, kind FieldKind, key, value []byte) []byte
+	AppendEnd(dst []byte, level Level, msg []byte) []byte
+}
+
+// EncoderHandler writes each record through an [Encoder]. Like
+// [JSONHandler] it is safe for concurrent use and writes each record with
+// one Write call. [ConsoleHandler] and [ECSHandler] are built the same
+// way on their own encoders.
+type EncoderHandler struct {
+	mu  sync.Mutex
+	out io.Writer
+	enc Encoder
+	rec recordEncoder
+}
+
+// NewEncoderHandler returns a handler writing records encoded by enc to
+// out.
+func NewEncoderHandler(out io.Writer, enc Encoder) *EncoderHandler {
+	return &EncoderHandler{out: out, enc: enc}
+}
+
+// Write implements [Handler].
+func (h *EncoderHandler) Write(e *Event) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, err := h.out.Write(h.rec.encode(h.enc, e)); err != nil {
+		return fmt.Errorf("failed to write record: %w", err)
+	}
+	return nil
+}
+
+// recordEncoder is the field path shared by every [Encoder]-based
+// handler: it walks an event's JSON record and calls the encoder with the
+// level and message and each other field in order. It reuses its buffers
+// between records and is not safe for concurrent use.
+type recordEncoder struct {
+	line, msg, key, value []byte
+	spans                 []FieldSpan
+}
+
+// encode renders e with enc. The result is valid until the next call.
+func (r *recordEncoder) encode(enc Encoder, e *Event) []byte {
+	buf := e.buf
+	r.spans = appendFieldSpans(r.spans[:0], buf, 1)
+	var msg []byte // nil when the record has no message
+	msgIdx := -1
+	for i := len(r.spans) - 1; i >= 0; i-- {
+		if string(r.spans[i].Key(buf)) == "message" {
+			msgIdx = i
+			if r.msg == nil {
+				r.msg = make([]byte, 0, 64)
+			}
+			msg = r.msg[:0]
+			if v := r.spans[i].Value(buf); len(v) >= 2 && v[0] == '"' {
+				msg = appendJSONUnescaped(msg, v[1:len(v)-1])
+			}
+			r.msg = msg
+			break
+		}
+	}
+
+	line := enc.AppendBegin(r.line[:0], e.level, msg)
+	for i, sp := range r.spans {
+		if i == msgIdx {
+			continue
+		}
+		r.key = appendJSONUnescaped(r.key[:0], sp.Key(buf))
+		if i == 0 && string(r.key) == "level" {
+			continue
+		}
+		v := sp.Value(buf)
+		var kind FieldKind
+		switch {
+		case len(v) == 0:
+			continue
+		case v[0] == '"':
+			kind = KindString
+			r.value = appendJSONUnescaped(r.value[:0], v[1:max(1, len(v)-1)])
+			v = r.value
+		case v[0] == '{':
+			kind = KindObject
+		case v[0] == '[':
+			kind = KindArray
+		case v[0] == 't' || v[0] == 'f':
+			kind = KindBool
+		case v[0] == 'n':
+			kind = KindNull
+		default:
+			kind = KindNumber
+		}
+		line = enc.AppendField(line, kind, r.key, v)
+	}
+	r.line = enc.AppendEnd(line, e.level, msg)
+	return r.line
+}
+
+// JSONEncoder is the [Encoder] for bolt's own JSON layout. It produces
+// the same records as [JSONHandler], which remains the faster choice;
+// JSONEncoder serves as the reference for custom encoders.
+type JSONEncoder struct{}
+
+// AppendBegin implements [Encoder].
+func (JSONEncoder) AppendBegin(dst []byte, level Level, _ []byte) []byte {
+	dst = append(dst, `{"level":"`...)
+	dst = append(dst, level.String()...)
+	return append(dst, '"')
+}
+
+// AppendField implements [Encoder].
+func (JSONEncoder) AppendField(dst []byte, kind FieldKind, key, value []byte) []byte {
+	dst = append(dst, ',', '"')
+	dst = appendJSONBytes(dst, key)
+	dst = append(dst, '"', ':')
+	if kind == KindString {
+		dst = append(dst, '"')
+		dst = appendJSONBytes(dst, value)
+		return append(dst, '"')
+	}
+	return append(dst, value...)
+}
+
+// AppendEnd implements [Encoder].
+func (JSONEncoder) AppendEnd(dst []byte, _ Level, msg []byte) []byte {
+	if msg == nil {
+		return append(dst, '}', '\n')
+	}
+	dst = append(dst, `,"message":"`...)
+	dst = appendJSONBytes(dst, msg)
+	return append(dst, '"', '}', '\n')
+}
+
+// LogfmtEncoder is the [Encoder] for logfmt, the key=value lines read by
+// Loki, Heroku and many command-line tools:
+//
+//	level=info user=ada latency_ms=12 msg="request served"
+//
+// Values containing spaces, '=', quotes or non-printable characters are
+// quoted with Go string escaping; nested objects and arrays are written
+// as quoted JSON. Characters in keys that logfmt cannot represent become
+// '_'.
+type LogfmtEncoder struct{}
+
+// AppendBegin implements [Encoder].
+func (LogfmtEncoder) AppendBegin(dst []byte, level Level, _ []byte) []byte {
+	dst = append(dst, "level="...)
+	return append(dst, level.String()...)
+}
+
+// AppendField implements [Encoder].
+func (LogfmtEncoder) AppendField(dst []byte, _ FieldKind, key, value []byte) []byte {
+	dst = append(dst, ' ')
+	for _, c := range key {
+		if c <= ' ' || c == '=' || c == '"' || c == 0x7f {
+			c = '_'
+		}
+		dst = append(dst, c)
+	}
+	dst = append(dst, '=')
+	return appendLogfmtValue(dst, value)
+}
+
+// AppendEnd implements [Encoder].
+func (LogfmtEncoder) AppendEnd(dst []byte, _ Level, msg []byte) []byte {
+	if msg == nil {
+		return append(dst, '\n')
+	}
+	dst = append(dst, " msg="...)
+	dst = appendLogfmtValue(dst, msg)
+	return append(dst, '\n')
+}
+
+// appendLogfmtValue appends v, quoted if logfmt requires it.
+func appendLogfmtValue(dst, v []byte) []byte {
+	if len(v) == 0 {
+		return append(dst, `""`...)
+	}
+	for i := 0; i < len(v); {
+		c := v[i]
+		if c < utf8.RuneSelf {
+			if c <= ' ' || c == '=' || c == '"' || c == '\\' || c == 0x7f {
+				return strconv.AppendQuote(dst, string(v))
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRune(v[i:])
+		if r == utf8.RuneError || !strconv.IsPrint(r) {
+			return strconv.AppendQuote(dst, string(v))
+		}
+		i += size
+	}
+	return append(dst, v...)
+}
@@ -0,0 +1,90 @@
+package bolt
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// logSample writes events covering every field kind.
+func logSample(logger *Logger) {
+	logger.Info().Str("user", `ada "the" \ first`).Int("n", -3).Float64("ratio", 0.25).Bool("ok", true).Msg("plain")
+	logger.Warn().Any("tags", []string{"a", "b"}).Dict("req", func(d *Event) { d.Str("path", "/x").Int("status", 200) }).Msg("with\nnewline")
+	logger.Error().Err(errors.New("boom")).Any("nothing", nil).Dur("d", time.Second).Msg("")
+	logger.Debug().Str("unicode", "héllo\u2028✓").Msg("ünïcode")
+}
+
+func TestJSONEncoderMatchesJSONHandler(t *testing.T) {
+	var want, got bytes.Buffer
+	logSample(New(NewJSONHandler(&want)))
+	logSample(New(NewEncoderHandler(&got, JSONEncoder{})))
+	if got.String() != want.String() {
+		t.Errorf("JSONEncoder output differs:\ngot  %s\nwant %s", got.String(), want.String())
+	}
+}
+
+func TestEncoderHandlerSend(t *testing.T) {
+	var want, got, logfmt bytes.Buffer
+	for _, l := range []*Logger{New(NewJSONHandler(&want)), New(NewEncoderHandler(&got, JSONEncoder{}))} {
+		l.Info().Str("k", "v").Send()
+		l.Info().Msg("")
+	}
+	if got.String() != want.String() {
+		t.Errorf("JSONEncoder output differs:\ngot  %s\nwant %s", got.String(), want.String())
+	}
+
+	l := New(NewEncoderHandler(&logfmt, LogfmtEncoder{}))
+	l.Info().Str("k", "v").Send()
+	l.Info().Msg("")
+	if want := "level=info k=v\nlevel=info msg=\"\"\n"; logfmt.String() != want {
+		t.Errorf("logfmt got %q, want %q", logfmt.String(), want)
+	}
+}
+
+func TestLogfmtEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	logSample(New(NewEncoderHandler(&buf, LogfmtEncoder{})))
+	want := []string{
+		`level=info user="ada \"the\" \\ first" n=-3 ratio=0.25 ok=true msg=plain`,
+		`level=warn tags="[\"a\",\"b\"]" req="{\"path\":\"/x\",\"status\":200}" msg="with\nnewline"`,
+		`level=error error=boom nothing=null d=1000000000 msg=""`,
+		`level=debug unicode="héllo\u2028✓" msg=ünïcode`,
+	}
+	if got := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+// recordingEncoder records the calls it receives.
+type recordingEncoder struct{ calls *[]string }
+
+func (r recordingEncoder) AppendBegin(dst []byte, level Level, msg []byte) []byte {
+	*r.calls = append(*r.calls, "begin "+level.String()+" "+string(msg))
+	return dst
+}
+
+func (r recordingEncoder) AppendField(dst []byte, kind FieldKind, key, value []byte) []byte {
+	*r.calls = append(*r.calls, "field "+string(rune('0'+kind))+" "+string(key)+"="+string(value))
+	return dst
+}
+
+func (r recordingEncoder) AppendEnd(dst []byte, level Level, msg []byte) []byte {
+	*r.calls = append(*r.calls, "end")
+	return append(dst, '\n')
+}
+
+func TestEncoderHandlerCalls(t *testing.T) {
+	var calls []string
+	logger := New(NewEncoderHandler(&bytes.Buffer{}, recordingEncoder{&calls}))
+	logger.Info().Str("s", `a"b`).Int("i", 1).Bool("b", false).Any("z", nil).Any("o", map[string]int{"k": 1}).Any("a", []int{1}).Msg("m")
+	want := []string{
+		"begin info m",
+		`field 0 s=a"b`, "field 1 i=1", "field 2 b=false", "field 3 z=null", `field 4 o={"k":1}`, "field 5 a=[1]",
+		"end",
+	}
+	if strings.Join(calls, "|") != strings.Join(want, "|") {
+		t.Errorf("calls = %q\nwant    %q", calls, want)
+	}
+}
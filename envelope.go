@@ -0,0 +1,57 @@
+package bolt
+
+import "strconv"
+
+// EnvelopeHandler wraps every record in a versioned envelope before
+// passing it on:
+//
+//	{"v":2,"data":{"level":"info","message":"..."}}
+//
+// Consumers switch on "v" to pick a parser, so the record layout can
+// evolve (renamed fields, ECS mapping, new nesting) without breaking
+// dashboards built on the old one. Wrap only the sinks whose consumers
+// expect envelopes:
+//
+//	logger := bolt.New(bolt.MultiHandler(
+//	    bolt.NewJSONHandler(os.Stdout),                                 // bare records
+//	    bolt.NewEnvelopeHandler(bolt.NewJSONHandler(kafkaWriter), 2), // enveloped
+//	))
+//
+// The reader package unwraps envelopes transparently and reports the
+// version through reader.Record.Version. EnvelopeHandler expects JSON
+// records and is not useful in front of ConsoleHandler.
+type EnvelopeHandler struct {
+	next   Handler
+	prefix []byte
+}
+
+// NewEnvelopeHandler returns an EnvelopeHandler tagging records with
+// version and writing them to next.
+func NewEnvelopeHandler(next Handler, version int) *EnvelopeHandler {
+	prefix := append([]byte(`{"v":`), strconv.Itoa(version)...)
+	prefix = append(prefix, `,"data":`...)
+	return &EnvelopeHandler{next: next, prefix: prefix}
+}
+
+// Write implements [Handler].
+func (h *EnvelopeHandler) Write(e *Event) error {
+	record := e.buf
+	if n := len(record); n > 0 && record[n-1] == '\n' {
+		record = record[:n-1]
+	}
+	env := getEvent()
+	env.level, env.l, env.ctx = e.level, e.l, e.ctx
+	env.buf = append(env.buf[:0], h.prefix...)
+	env.buf = append(env.buf, record...)
+	env.buf = append(env.buf, '}', '\n')
+	err := h.next.Write(env)
+
+	if cap(env.buf) > PoolBufferCap {
+		env.buf = nil
+	} else {
+		env.buf = env.buf[:0]
+	}
+	env.l = nil
+	putEvent(env)
+	return err
+}
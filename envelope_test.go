@@ -0,0 +1,42 @@
+package bolt
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+)
+
+func TestEnvelopeHandler(t *testing.T) {
+	var bare, wrapped bytes.Buffer
+	logger := New(MultiHandler(
+		NewJSONHandler(&bare),
+		NewEnvelopeHandler(NewJSONHandler(&wrapped), 2),
+	))
+	logger.Info().Str("k", "v").Msg("hello")
+
+	want := `{"v":2,"data":` + string(bytes.TrimSuffix(bare.Bytes(), []byte("\n"))) + "}\n"
+	if wrapped.String() != want {
+		t.Errorf("got %q, want %q", wrapped.String(), want)
+	}
+	var env struct {
+		V    int             `json:"v"`
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(wrapped.Bytes(), &env); err != nil || env.V != 2 {
+		t.Errorf("envelope does not decode: %v, %+v", err, env)
+	}
+}
+
+func TestEnvelopeHandlerZeroAlloc(t *testing.T) {
+	if raceDetectorEnabled {
+		t.Skip("allocation counts are not meaningful under -race")
+	}
+	logger := New(NewEnvelopeHandler(NewJSONHandler(io.Discard), 1))
+	allocs := testing.AllocsPerRun(100, func() {
+		logger.Info().Str("k", "v").Msg("m")
+	})
+	if allocs != 0 {
+		t.Errorf("allocs = %v, want 0", allocs)
+	}
+}
@@ -0,0 +1,244 @@
+package bolt
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Defaults for [ErrorRateOptions].
+const (
+	DefaultErrorRateWindow    = time.Minute
+	DefaultErrorRateThreshold = 100
+	DefaultAlertTopN          = 5
+	DefaultAlertSamples       = 3
+)
+
+// errorRateMaxFingerprints caps the distinct fingerprints tracked per
+// window; further ones are counted under ErrorFingerprintOther.
+const errorRateMaxFingerprints = 256
+
+// ErrorFingerprintOther collects errors beyond the per-window tracking
+// limit.
+const ErrorFingerprintOther = "(other)"
+
+// ErrorRateOptions configures [NewErrorRateHook]. A nil *ErrorRateOptions
+// uses the defaults.
+type ErrorRateOptions struct {
+	// Window is the counting window. Defaults to DefaultErrorRateWindow.
+	Window time.Duration
+
+	// Threshold is the number of ERROR and FATAL events within one
+	// window that counts as a breach. Defaults to
+	// DefaultErrorRateThreshold.
+	Threshold int
+
+	// TopN is the number of fingerprints reported. Defaults to
+	// DefaultAlertTopN.
+	TopN int
+
+	// Samples is the number of correlation IDs kept per fingerprint.
+	// Defaults to DefaultAlertSamples.
+	Samples int
+
+	// CorrelationKey names the field sampled as evidence. Defaults to
+	// "correlation_id".
+	CorrelationKey string
+
+	// OnBreach, if set, is called with the alert context after it has
+	// been logged, for example to increment a Prometheus counter.
+	OnBreach func(AlertContext)
+}
+
+// ErrorFingerprint groups errors that differ only in numbers, such as
+// IDs, ports or durations embedded in the text.
+type ErrorFingerprint struct {
+	Fingerprint    string   `json:"fingerprint"`
+	Message        string   `json:"message"`
+	Error          string   `json:"error,omitempty"`
+	Count          int      `json:"count"`
+	CorrelationIDs []string `json:"correlation_ids,omitempty"`
+}
+
+// AlertContext is the evidence gathered for one error-rate breach.
+type AlertContext struct {
+	Start     time.Time          `json:"start"`
+	Errors    int                `json:"errors"`
+	Threshold int                `json:"threshold"`
+	Top       []ErrorFingerprint `json:"top_errors"`
+}
+
+// ErrorRateHook is an [EventHook] that watches the rate of ERROR and FATAL
+// events and, the first time a window crosses the threshold, logs an
+// "alert context" event at WARN naming the most frequent error
+// fingerprints with sample correlation IDs. An alert fired from the
+// metrics side can then link straight to concrete log evidence:
+//
+//	logger.AddEventHook(bolt.NewErrorRateHook(logger, &bolt.ErrorRateOptions{
+//	    Threshold: 50,
+//	    OnBreach:  func(bolt.AlertContext) { breaches.Inc() },
+//	}))
+//
+// The alert event carries "alert":"error_rate", "errors", "threshold",
+// "window" and "top_errors". At most one alert is logged per window, and
+// none if logger's level is above WARN. ErrorRateHook never suppresses
+// events.
+type ErrorRateHook struct {
+	logger *Logger
+	opts   ErrorRateOptions
+
+	mu      sync.Mutex
+	start   time.Time
+	errors  int
+	alerted bool
+	prints  map[uint64]*ErrorFingerprint
+}
+
+// NewErrorRateHook returns an ErrorRateHook that logs alerts to logger.
+// If opts is nil, defaults are used.
+func NewErrorRateHook(logger *Logger, opts *ErrorRateOptions) *ErrorRateHook {
+	var o ErrorRateOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.Window <= 0 {
+		o.Window = DefaultErrorRateWindow
+	}
+	if o.Threshold <= 0 {
+		o.Threshold = DefaultErrorRateThreshold
+	}
+	if o.TopN <= 0 {
+		o.TopN = DefaultAlertTopN
+	}
+	if o.Samples <= 0 {
+		o.Samples = DefaultAlertSamples
+	}
+	if o.CorrelationKey == "" {
+		o.CorrelationKey = "correlation_id"
+	}
+	return &ErrorRateHook{
+		logger: logger,
+		opts:   o,
+		start:  time.Now(),
+		prints: make(map[uint64]*ErrorFingerprint),
+	}
+}
+
+// Run implements [EventHook].
+func (h *ErrorRateHook) Run(e *Event, msg string) bool {
+	if e.Level() < ERROR {
+		return true
+	}
+	var errText, correlation []byte
+	e.WalkFields(func(key, value []byte) bool {
+		switch string(key) {
+		case "error":
+			errText = value
+		case h.opts.CorrelationKey:
+			correlation = value
+		}
+		return errText == nil || correlation == nil
+	})
+	sum := fingerprint(msg, errText)
+
+	h.mu.Lock()
+	now := time.Now()
+	if now.Sub(h.start) >= h.opts.Window {
+		h.start, h.errors, h.alerted = now, 0, false
+		clear(h.prints)
+	}
+	h.errors++
+	fp, ok := h.prints[sum]
+	if !ok {
+		if len(h.prints) >= errorRateMaxFingerprints {
+			sum = 0
+			fp = h.prints[0]
+		}
+		if fp == nil {
+			fp = &ErrorFingerprint{Fingerprint: strconv.FormatUint(sum, 16), Message: msg, Error: string(errText)}
+			if sum == 0 {
+				fp.Fingerprint, fp.Message, fp.Error = ErrorFingerprintOther, "", ""
+			}
+			h.prints[sum] = fp
+		}
+	}
+	fp.Count++
+	if len(correlation) > 0 && len(fp.CorrelationIDs) < h.opts.Samples {
+		fp.CorrelationIDs = append(fp.CorrelationIDs, string(correlation))
+	}
+	var alert *AlertContext
+	if !h.alerted && h.errors >= h.opts.Threshold {
+		h.alerted = true
+		alert = h.context()
+	}
+	h.mu.Unlock()
+
+	if alert != nil {
+		// Logged outside the lock: the alert event passes through this
+		// hook too (as WARN, so it is not counted).
+		h.logger.Warn().
+			Str("alert", "error_rate").
+			Int("errors", alert.Errors).
+			Int("threshold", alert.Threshold).
+			Dur("window", h.opts.Window).
+			Any("top_errors", alert.Top).
+			Msg("error rate threshold exceeded")
+		if h.opts.OnBreach != nil {
+			h.opts.OnBreach(*alert)
+		}
+	}
+	return true
+}
+
+// context snapshots the current window. Called with h.mu held.
+func (h *ErrorRateHook) context() *AlertContext {
+	top := make([]ErrorFingerprint, 0, len(h.prints))
+	for _, fp := range h.prints {
+		c := *fp
+		c.CorrelationIDs = append([]string(nil), fp.CorrelationIDs...)
+		top = append(top, c)
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+		return top[i].Fingerprint < top[j].Fingerprint
+	})
+	if len(top) > h.opts.TopN {
+		top = top[:h.opts.TopN]
+	}
+	return &AlertContext{Start: h.start, Errors: h.errors, Threshold: h.opts.Threshold, Top: top}
+}
+
+// fingerprint hashes the message and error text with every run of digits
+// collapsed, so "timeout after 30s on conn 7" and "timeout after 31s on
+// conn 9" group together. 0 is reserved for ErrorFingerprintOther.
+func fingerprint(msg string, errText []byte) uint64 {
+	var scratch [256]byte
+	b := appendNormalized(scratch[:0], msg)
+	b = append(b, 0)
+	b = appendNormalized(b, string(errText))
+	if h := hash64(b); h != 0 {
+		return h
+	}
+	return 1
+}
+
+// appendNormalized appends s with every run of digits replaced by '#'.
+func appendNormalized(dst []byte, s string) []byte {
+	digits := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= '0' && c <= '9' {
+			if !digits {
+				dst = append(dst, '#')
+			}
+			digits = true
+			continue
+		}
+		digits = false
+		dst = append(dst, c)
+	}
+	return dst
+}
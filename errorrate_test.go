@@ -0,0 +1,71 @@
+package bolt
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestErrorRateHook(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewJSONHandler(&buf))
+	var breaches []AlertContext
+	logger.AddEventHook(NewErrorRateHook(logger, &ErrorRateOptions{
+		Threshold: 5,
+		TopN:      2,
+		Samples:   2,
+		OnBreach:  func(a AlertContext) { breaches = append(breaches, a) },
+	}))
+
+	logger.Info().Msg("not counted")
+	for i := 0; i < 3; i++ {
+		logger.Error().Err(errors.New("dial tcp 10.0.0."+string(rune('1'+i))+":5432: timeout")).
+			Str("correlation_id", "req-"+string(rune('a'+i))).Msg("db unavailable")
+	}
+	logger.Error().Str("correlation_id", "req-x").Msg("cache miss storm")
+	logger.Error().Msg("disk full")
+	logger.Error().Msg("disk full") // past the threshold: no second alert
+
+	if len(breaches) != 1 {
+		t.Fatalf("got %d breaches, want 1", len(breaches))
+	}
+	a := breaches[0]
+	if a.Errors != 5 || a.Threshold != 5 || len(a.Top) != 2 {
+		t.Fatalf("alert = %+v", a)
+	}
+	db := a.Top[0]
+	if db.Message != "db unavailable" || db.Count != 3 || strings.Join(db.CorrelationIDs, ",") != "req-a,req-b" {
+		t.Errorf("top fingerprint = %+v; want db unavailable x3 with 2 samples", db)
+	}
+
+	var alert struct {
+		Level     string             `json:"level"`
+		Alert     string             `json:"alert"`
+		Errors    int                `json:"errors"`
+		TopErrors []ErrorFingerprint `json:"top_errors"`
+	}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if strings.Contains(line, `"alert":"error_rate"`) {
+			if err := json.Unmarshal([]byte(line), &alert); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if alert.Level != "warn" || alert.Errors != 5 || len(alert.TopErrors) != 2 || alert.TopErrors[0].Fingerprint != db.Fingerprint {
+		t.Errorf("alert event = %+v", alert)
+	}
+}
+
+func TestFingerprintIgnoresNumbers(t *testing.T) {
+	a := fingerprint("timeout after 30s", []byte("conn 7"))
+	b := fingerprint("timeout after 451s", []byte("conn 12"))
+	c := fingerprint("timeout after s", []byte("conn 7"))
+	if a != b {
+		t.Error("fingerprints differ only by numbers but do not match")
+	}
+	if a == c {
+		t.Error("removing a number entirely should change the fingerprint")
+	}
+}
@@ -1,23 +1,25 @@
 package bolt
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net"
-	"runtime"
-	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 )
 
 type Event struct {
-	buf   []byte // The raw buffer for building the log line.
-	level Level
-	l     *Logger
+	buf    []byte // The raw buffer for building the log line.
+	level  Level
+	noExit bool // set by Event.NoExit
+	l      *Logger
+	fields []int32         // offsets of top-level keys; see Logger.SetFieldTracking
+	ctx    context.Context // set by Event.Ctx; not encoded
 }
 
 // Global pool for event objects.
@@ -36,10 +38,17 @@ func (e *Event) Logger() *Logger {
 	if len(contextBuf) > 0 && contextBuf[0] == ',' {
 		contextBuf = contextBuf[1:]
 	}
-	// Create new logger with atomic level
-	newLogger := &Logger{handler: e.l.handler, context: contextBuf, errorHandler: e.l.errorHandler, hooks: e.l.hooks, eventHooks: e.l.eventHooks}
-	atomic.StoreInt64(&newLogger.level, atomic.LoadInt64(&e.l.level))
-	return newLogger
+	return e.l.derive(contextBuf)
+}
+
+// derive returns a child of l with the given context.
+func (l *Logger) derive(context []byte) *Logger {
+	c := l.clone()
+	c.context, c.ctxFields = context, nil
+	if l.trackFields {
+		c.ctxFields = appendFieldOffsets(nil, context, 0)
+	}
+	return c
 }
 
 func (e *Event) Str(key, value string) *Event {
@@ -71,7 +80,7 @@ func (e *Event) Str(key, value string) *Event {
 
 	e.buf = append(e.buf, ',')
 	e.buf = append(e.buf, '"')
-	e.buf = appendJSONString(e.buf, key)
+	e.buf = e.appendKey(key)
 	e.buf = append(e.buf, `":"`...)
 	e.buf = appendJSONString(e.buf, value)
 	e.buf = append(e.buf, '"')
@@ -93,7 +102,7 @@ func (e *Event) Stringer(key string, val fmt.Stringer) *Event {
 		}
 		e.buf = append(e.buf, ',')
 		e.buf = append(e.buf, '"')
-		e.buf = appendJSONString(e.buf, key)
+		e.buf = e.appendKey(key)
 		e.buf = append(e.buf, `":null`...)
 		return e
 	}
@@ -116,7 +125,7 @@ func (e *Event) Int(key string, value int) *Event {
 
 	e.buf = append(e.buf, ',')
 	e.buf = append(e.buf, '"')
-	e.buf = appendJSONString(e.buf, key)
+	e.buf = e.appendKey(key)
 	e.buf = append(e.buf, `":`...)
 	e.buf = appendInt(e.buf, value)
 	return e
@@ -138,7 +147,7 @@ func (e *Event) Bool(key string, value bool) *Event {
 
 	e.buf = append(e.buf, ',')
 	e.buf = append(e.buf, '"')
-	e.buf = appendJSONString(e.buf, key)
+	e.buf = e.appendKey(key)
 	e.buf = append(e.buf, `":`...)
 	e.buf = appendBool(e.buf, value)
 	return e
@@ -183,7 +192,7 @@ func (e *Event) Float64(key string, value float64) *Event {
 
 	e.buf = append(e.buf, ',')
 	e.buf = append(e.buf, '"')
-	e.buf = appendJSONString(e.buf, key)
+	e.buf = e.appendKey(key)
 	e.buf = append(e.buf, `":`...)
 	e.buf = appendFloat64(e.buf, value)
 	return e
@@ -204,7 +213,7 @@ func (e *Event) Time(key string, value time.Time) *Event {
 
 	e.buf = append(e.buf, ',')
 	e.buf = append(e.buf, '"')
-	e.buf = appendJSONString(e.buf, key)
+	e.buf = e.appendKey(key)
 	e.buf = append(e.buf, `":"`...)
 	e.buf = appendRFC3339(e.buf, value)
 	e.buf = append(e.buf, '"')
@@ -226,9 +235,9 @@ func (e *Event) Dur(key string, value time.Duration) *Event {
 
 	e.buf = append(e.buf, ',')
 	e.buf = append(e.buf, '"')
-	e.buf = appendJSONString(e.buf, key)
+	e.buf = e.appendKey(key)
 	e.buf = append(e.buf, `":`...)
-	e.buf = appendInt(e.buf, int(value.Nanoseconds()))
+	e.buf = appendInt64(e.buf, value.Nanoseconds())
 	return e
 }
 
@@ -247,16 +256,25 @@ func (e *Event) Uint(key string, value uint) *Event {
 
 	e.buf = append(e.buf, ',')
 	e.buf = append(e.buf, '"')
-	e.buf = appendJSONString(e.buf, key)
+	e.buf = e.appendKey(key)
 	e.buf = append(e.buf, `":`...)
 	e.buf = appendUint(e.buf, uint64(value))
 	return e
 }
 
+// Any adds a field with value encoded as JSON. Strings, numbers, bools,
+// time.Time, []byte, common slices and maps, json.RawMessage and
+// [LogObjectMarshaler] values are encoded without allocating; other types
+// go through a reflection encoder with cached struct layouts that matches
+// encoding/json's output. Values it cannot encode — NaN, cycles, exotic
+// struct tags — fall back to encoding/json itself.
 func (e *Event) Any(key string, value interface{}) *Event {
 	if e.l == nil {
 		return e
 	}
+	if obj, ok := value.(LogObjectMarshaler); ok {
+		return e.Object(key, obj)
+	}
 
 	// Validate key for security
 	if err := validateKey(key); err != nil {
@@ -268,8 +286,14 @@ func (e *Event) Any(key string, value interface{}) *Event {
 
 	e.buf = append(e.buf, ',')
 	e.buf = append(e.buf, '"')
-	e.buf = appendJSONString(e.buf, key)
+	e.buf = e.appendKey(key)
 	e.buf = append(e.buf, `":`...)
+	start := len(e.buf)
+	if buf, ok := appendAny(e.buf, value, 0); ok {
+		e.buf = buf
+		return e
+	}
+	e.buf = e.buf[:start]
 	marshaledValue, err := json.Marshal(value)
 	if err != nil {
 		// Handle error with proper JSON escaping
@@ -290,9 +314,30 @@ func (e *Event) Err(err error) *Event {
 	return e.Str("error", err.Error())
 }
 
+// NoExit keeps a FATAL event from exiting the process once it is
+// written, for adapters whose host decides how to terminate, such as
+// zap's fatal hook. It has no effect on other levels.
+func (e *Event) NoExit() *Event {
+	if e.l == nil {
+		return e
+	}
+	e.noExit = true
+	return e
+}
+
 // Msg sends the event to the handler for processing.
 // This is always the final method in the chain.
 func (e *Event) Msg(message string) {
+	e.send(message, true)
+}
+
+// Send sends the event without a "message" field, for records that are
+// fully described by their fields. Hooks see an empty message.
+func (e *Event) Send() {
+	e.send("", false)
+}
+
+func (e *Event) send(message string, withMessage bool) {
 	if e.l == nil {
 		return // No-op for disabled events
 	}
@@ -307,12 +352,19 @@ func (e *Event) Msg(message string) {
 
 	// Run legacy hooks first; if any returns false, suppress the event.
 	for _, hook := range e.l.hooks {
+		sample, isSample := hook.(*SampleHook)
+		if isSample && e.l.elevated {
+			continue // requests elevated via baggage are never sampled out
+		}
 		if !hook.Run(e.level, message) {
 			e.buf = e.buf[:0]
 			e.l = nil
-			eventPool.Put(e)
+			putEvent(e)
 			return
 		}
+		if isSample && sample.annotate && sample.n > 1 {
+			e.Float64(SampleRateField, 1/float64(sample.n))
+		}
 	}
 
 	// Run field-aware hooks. Same suppression semantics as legacy hooks.
@@ -322,11 +374,15 @@ func (e *Event) Msg(message string) {
 		if !hook.Run(e, message) {
 			e.buf = e.buf[:0]
 			e.l = nil
-			eventPool.Put(e)
+			putEvent(e)
 			return
 		}
 	}
 
+	if e.ctx != nil {
+		e.markCtxErr()
+	}
+
 	// Check buffer size before finalizing
 	if err := checkBufferSize(e.buf); err != nil {
 		if e.l.errorHandler != nil {
@@ -336,21 +392,31 @@ func (e *Event) Msg(message string) {
 	}
 
 	// Add message with proper JSON escaping
-	e.buf = append(e.buf, `,"message":"`...)
-	e.buf = appendJSONString(e.buf, message)
-	e.buf = append(e.buf, '"')
+	if withMessage {
+		if e.l.trackFields {
+			e.fields = append(e.fields, int32(len(e.buf)+1)) // #nosec G115 - bounded by MaxBufferSize
+		}
+		e.buf = append(e.buf, `,"message":"`...)
+		e.buf = appendJSONString(e.buf, message)
+		e.buf = append(e.buf, '"')
+	}
 
 	// Finalize JSON and add newline
 	e.buf = append(e.buf, '}')
 	e.buf = append(e.buf, '\n')
 
 	// Pass the event to the handler with proper error handling
-	if err := e.l.handler.Write(e); err != nil && e.l.errorHandler != nil {
+	// Release the slot before the error handler runs: an error handler
+	// that swaps to a fallback handler would otherwise wait on this write.
+	slot, stripe := e.l.handler.acquire()
+	err := slot.h.Write(e)
+	slot.release(stripe)
+	if err != nil && e.l.errorHandler != nil {
 		e.l.errorHandler(fmt.Errorf("handler write failed: %w", err))
 	}
 
 	// Capture FATAL before recycling so we can exit after the buffer is freed.
-	fatal := e.level == FATAL
+	fatal := e.level == FATAL && !e.noExit
 
 	// Reset the buffer and put the event back into the pool. Drop oversized
 	// buffers so the pool cannot retain rare 1MB allocations forever.
@@ -360,7 +426,7 @@ func (e *Event) Msg(message string) {
 		e.buf = e.buf[:0]
 	}
 	e.l = nil // Clear logger reference
-	eventPool.Put(e)
+	putEvent(e)
 
 	if fatal {
 		exitFunc(1)
@@ -382,7 +448,7 @@ func (e *Event) Hex(key string, value []byte) *Event {
 
 	e.buf = append(e.buf, ',')
 	e.buf = append(e.buf, '"')
-	e.buf = appendJSONString(e.buf, key)
+	e.buf = e.appendKey(key)
 	e.buf = append(e.buf, `":"`...)
 	e.buf = append(e.buf, hex.EncodeToString(value)...)
 	e.buf = append(e.buf, '"')
@@ -405,7 +471,7 @@ func (e *Event) Base64(key string, value []byte) *Event {
 
 	e.buf = append(e.buf, ',')
 	e.buf = append(e.buf, '"')
-	e.buf = appendJSONString(e.buf, key)
+	e.buf = e.appendKey(key)
 	e.buf = append(e.buf, `":"`...)
 	e.buf = append(e.buf, base64.StdEncoding.EncodeToString(value)...)
 	e.buf = append(e.buf, '"')
@@ -428,13 +494,13 @@ func (e *Event) IPAddr(key string, ip net.IP) *Event {
 	if ip == nil {
 		e.buf = append(e.buf, ',')
 		e.buf = append(e.buf, '"')
-		e.buf = appendJSONString(e.buf, key)
+		e.buf = e.appendKey(key)
 		e.buf = append(e.buf, `":null`...)
 		return e
 	}
 	e.buf = append(e.buf, ',')
 	e.buf = append(e.buf, '"')
-	e.buf = appendJSONString(e.buf, key)
+	e.buf = e.appendKey(key)
 	e.buf = append(e.buf, `":"`...)
 	e.buf = appendIP(e.buf, ip)
 	e.buf = append(e.buf, '"')
@@ -449,47 +515,35 @@ func (e *Event) Bytes(key string, value []byte) *Event {
 	return e.Str(key, string(value))
 }
 
-// Stack adds a stack trace field to the event.
+// Stack adds the calling goroutine's stack trace under "stack", one
+// function and file:line pair per frame. Runtime and vendored frames are
+// skipped and at most DefaultStackFrames are kept unless configured
+// otherwise with [Logger.SetStackOptions].
 func (e *Event) Stack() *Event {
 	if e.l == nil {
 		return e
 	}
-	buf := make([]byte, StackTraceBufferSize)
-	n := runtime.Stack(buf, false)
-	return e.Str("stack", string(buf[:n]))
+	return e.Str("stack", captureStack(e.l.stackOpts))
 }
 
-// Caller adds caller information (file:line) to the event.
+// Caller adds the caller's location under "caller" as a module-relative
+// "dir/file.go:line", so it is identical on every build machine. See
+// [Logger.SetCallerOptions] to also log the function name.
 func (e *Event) Caller() *Event {
 	if e.l == nil {
 		return e
 	}
-	_, file, line, ok := runtime.Caller(1)
-	if !ok {
-		return e.Str("caller", "unknown")
-	}
-	// Extract just the filename
-	if idx := strings.LastIndex(file, "/"); idx >= 0 {
-		file = file[idx+1:]
-	}
-	return e.Str("caller", fmt.Sprintf("%s:%d", file, line))
+	return e.addCaller(0)
 }
 
-// CallerSkip adds caller information (file:line) to the event, skipping the
-// specified number of additional stack frames. This is useful when Bolt is
-// wrapped in helper functions and you need the caller of the wrapper.
+// CallerSkip is like [Event.Caller] but skips the specified number of
+// additional stack frames. This is useful when Bolt is wrapped in helper
+// functions and you need the caller of the wrapper.
 func (e *Event) CallerSkip(skip int) *Event {
 	if e.l == nil {
 		return e
 	}
-	_, file, line, ok := runtime.Caller(1 + skip)
-	if !ok {
-		return e.Str("caller", "unknown")
-	}
-	if idx := strings.LastIndex(file, "/"); idx >= 0 {
-		file = file[idx+1:]
-	}
-	return e.Str("caller", fmt.Sprintf("%s:%d", file, line))
+	return e.addCaller(skip)
 }
 
 // RandID adds a random ID field to the event for request tracing.
@@ -528,7 +582,7 @@ func (e *Event) Ints(key string, values []int) *Event {
 	}
 	e.buf = append(e.buf, ',')
 	e.buf = append(e.buf, '"')
-	e.buf = appendJSONString(e.buf, key)
+	e.buf = e.appendKey(key)
 	e.buf = append(e.buf, `":[`...)
 	for i, v := range values {
 		if i > 0 {
@@ -554,7 +608,7 @@ func (e *Event) Strs(key string, values []string) *Event {
 	}
 	e.buf = append(e.buf, ',')
 	e.buf = append(e.buf, '"')
-	e.buf = appendJSONString(e.buf, key)
+	e.buf = e.appendKey(key)
 	e.buf = append(e.buf, `":[`...)
 	for i, v := range values {
 		if i > 0 {
@@ -581,14 +635,27 @@ func (e *Event) Dict(key string, fn func(d *Event)) *Event {
 		}
 		return e
 	}
-	sub := eventPool.Get().(*Event)
+	sub := e.subEvent()
+	fn(sub)
+	return e.embedObject(key, sub)
+}
+
+// subEvent returns a pooled event collecting the fields of a nested
+// object. Pass it to embedObject when done.
+func (e *Event) subEvent() *Event {
+	sub := getEvent()
 	sub.buf = sub.buf[:0]
 	sub.level = e.level
 	sub.l = e.l
-	fn(sub)
+	return sub
+}
+
+// embedObject appends sub's fields as a JSON object under key and
+// recycles sub.
+func (e *Event) embedObject(key string, sub *Event) *Event {
 	e.buf = append(e.buf, ',')
 	e.buf = append(e.buf, '"')
-	e.buf = appendJSONString(e.buf, key)
+	e.buf = e.appendKey(key)
 	e.buf = append(e.buf, `":{`...)
 	subBuf := sub.buf
 	if len(subBuf) > 0 && subBuf[0] == ',' {
@@ -598,10 +665,51 @@ func (e *Event) Dict(key string, fn func(d *Event)) *Event {
 	e.buf = append(e.buf, '}')
 	sub.buf = sub.buf[:0]
 	sub.l = nil
-	eventPool.Put(sub)
+	putEvent(sub)
 	return e
 }
 
+// LogObjectMarshaler is implemented by types that encode themselves as a
+// nested object, field by field, straight into the event buffer — no
+// reflection and no intermediate map:
+//
+//	func (u User) MarshalLogObject(e *bolt.Event) {
+//	    e.Str("id", u.ID).Str("role", u.Role)
+//	}
+//
+//	logger.Info().Object("user", user).Msg("login")
+//
+// [Event.Any] uses the interface too, so such values can be passed
+// anywhere a field value is accepted. Pass pointers to avoid the
+// allocation of boxing a struct value in the interface.
+type LogObjectMarshaler interface {
+	MarshalLogObject(e *Event)
+}
+
+// Object adds obj as a nested JSON object under key. A nil obj is encoded
+// as null.
+func (e *Event) Object(key string, obj LogObjectMarshaler) *Event {
+	if e.l == nil {
+		return e
+	}
+	if err := validateKey(key); err != nil {
+		if e.l.errorHandler != nil {
+			e.l.errorHandler(fmt.Errorf("invalid key in Object(): %w", err))
+		}
+		return e
+	}
+	if obj == nil {
+		e.buf = append(e.buf, ',')
+		e.buf = append(e.buf, '"')
+		e.buf = e.appendKey(key)
+		e.buf = append(e.buf, `":null`...)
+		return e
+	}
+	sub := e.subEvent()
+	obj.MarshalLogObject(sub)
+	return e.embedObject(key, sub)
+}
+
 // Int64 adds a 64-bit integer field to the event.
 func (e *Event) Int64(key string, value int64) *Event {
 	if e.l == nil {
@@ -618,9 +726,9 @@ func (e *Event) Int64(key string, value int64) *Event {
 
 	e.buf = append(e.buf, ',')
 	e.buf = append(e.buf, '"')
-	e.buf = appendJSONString(e.buf, key)
+	e.buf = e.appendKey(key)
 	e.buf = append(e.buf, `":`...)
-	e.buf = appendInt(e.buf, int(value))
+	e.buf = appendInt64(e.buf, int64(value))
 	return e
 }
 
@@ -640,9 +748,9 @@ func (e *Event) Int32(key string, value int32) *Event {
 
 	e.buf = append(e.buf, ',')
 	e.buf = append(e.buf, '"')
-	e.buf = appendJSONString(e.buf, key)
+	e.buf = e.appendKey(key)
 	e.buf = append(e.buf, `":`...)
-	e.buf = appendInt(e.buf, int(value))
+	e.buf = appendInt64(e.buf, int64(value))
 	return e
 }
 
@@ -662,9 +770,9 @@ func (e *Event) Int16(key string, value int16) *Event {
 
 	e.buf = append(e.buf, ',')
 	e.buf = append(e.buf, '"')
-	e.buf = appendJSONString(e.buf, key)
+	e.buf = e.appendKey(key)
 	e.buf = append(e.buf, `":`...)
-	e.buf = appendInt(e.buf, int(value))
+	e.buf = appendInt64(e.buf, int64(value))
 	return e
 }
 
@@ -684,9 +792,9 @@ func (e *Event) Int8(key string, value int8) *Event {
 
 	e.buf = append(e.buf, ',')
 	e.buf = append(e.buf, '"')
-	e.buf = appendJSONString(e.buf, key)
+	e.buf = e.appendKey(key)
 	e.buf = append(e.buf, `":`...)
-	e.buf = appendInt(e.buf, int(value))
+	e.buf = appendInt64(e.buf, int64(value))
 	return e
 }
 
@@ -706,7 +814,7 @@ func (e *Event) Uint64(key string, value uint64) *Event {
 
 	e.buf = append(e.buf, ',')
 	e.buf = append(e.buf, '"')
-	e.buf = appendJSONString(e.buf, key)
+	e.buf = e.appendKey(key)
 	e.buf = append(e.buf, `":`...)
 	e.buf = appendUint(e.buf, value)
 	return e
@@ -725,7 +833,7 @@ func (e *Event) Uint32(key string, value uint32) *Event {
 	}
 	e.buf = append(e.buf, ',')
 	e.buf = append(e.buf, '"')
-	e.buf = appendJSONString(e.buf, key)
+	e.buf = e.appendKey(key)
 	e.buf = append(e.buf, `":`...)
 	e.buf = appendUint(e.buf, uint64(value))
 	return e
@@ -744,7 +852,7 @@ func (e *Event) Uint16(key string, value uint16) *Event {
 	}
 	e.buf = append(e.buf, ',')
 	e.buf = append(e.buf, '"')
-	e.buf = appendJSONString(e.buf, key)
+	e.buf = e.appendKey(key)
 	e.buf = append(e.buf, `":`...)
 	e.buf = appendUint(e.buf, uint64(value))
 	return e
@@ -763,7 +871,7 @@ func (e *Event) Uint8(key string, value uint8) *Event {
 	}
 	e.buf = append(e.buf, ',')
 	e.buf = append(e.buf, '"')
-	e.buf = appendJSONString(e.buf, key)
+	e.buf = e.appendKey(key)
 	e.buf = append(e.buf, `":`...)
 	e.buf = appendUint(e.buf, uint64(value))
 	return e
@@ -795,22 +903,47 @@ func (e *Event) Timestamp() *Event {
 	return e.Time("timestamp", time.Now())
 }
 
+// Func calls fn with the event if it is enabled, so fields that are
+// expensive to compute are skipped for disabled levels:
+//
+//	logger.Debug().Func(func(e *bolt.Event) {
+//	    e.Str("plan", plan.Explain())
+//	}).Msg("query planned")
+func (e *Event) Func(fn func(e *Event)) *Event {
+	if e.l == nil {
+		return e
+	}
+	fn(e)
+	return e
+}
+
 // Interface adds an interface{} field to the event (alias for Any).
 func (e *Event) Interface(key string, value interface{}) *Event {
 	return e.Any(key, value)
 }
 
-// Printf adds a formatted message to the event.
-func (e *Event) Printf(format string, args ...interface{}) {
+// Msgf sends the event with a message formatted by [fmt.Sprintf]. The
+// message is only formatted if the event is enabled.
+func (e *Event) Msgf(format string, args ...interface{}) {
 	if e.l == nil {
 		return
 	}
 	e.Msg(fmt.Sprintf(format, args...))
 }
 
-// Send is an alias for Msg for consistency with other logging libraries.
-func (e *Event) Send() {
-	e.Msg("")
+// MsgFunc sends the event with the message returned by fn. fn is only
+// called if the event is enabled at its level; hooks that sample or drop
+// the event still run after it, since they receive the message.
+func (e *Event) MsgFunc(fn func() string) {
+	if e.l == nil {
+		return
+	}
+	e.Msg(fn())
+}
+
+// Printf is an alias for [Event.Msgf].
+func (e *Event) Printf(format string, args ...interface{}) {
+	e.Msgf(format, args...)
 }
 
 // Level returns the event's log level. Intended for [EventHook]
@@ -819,6 +952,13 @@ func (e *Event) Level() Level {
 	return e.level
 }
 
+// Elevated reports whether the event comes from a logger whose level was
+// lowered for this request through [DebugBaggageKey]. Sampling hooks
+// should keep elevated events, as [HashSampler] and [SampleHook] do.
+func (e *Event) Elevated() bool {
+	return e.l != nil && e.l.elevated
+}
+
 // Buffer returns the in-flight JSON buffer. Intended for [EventHook]
 // implementations that need to inspect the encoded record before it
 // is written.
@@ -847,37 +987,49 @@ func (e *Event) Buffer() []byte {
 // so prefer using it from sampling/redaction hooks rather than from a
 // per-event metric counter.
 func (e *Event) WalkFields(fn func(key, value []byte) bool) int {
-	if len(e.buf) == 0 || e.buf[0] != '{' {
+	buf := e.buf
+	if len(buf) == 0 || buf[0] != '{' {
 		return 0
 	}
 	count := 0
 	i := 1 // skip opening '{'
-	for i < len(e.buf) {
-		i = skipWhitespace(e.buf, i)
-		if i >= len(e.buf) || e.buf[i] == '}' {
+	for i < len(buf) {
+		i = skipWhitespace(buf, i)
+		if i >= len(buf) || buf[i] == '}' {
 			break
 		}
-		key, ni := extractJSONKey(e.buf, i)
-		if key == nil {
+		if buf[i] == ',' {
 			i++
 			continue
 		}
-		i = ni
-		i = skipWhitespace(e.buf, i)
-		if i < len(e.buf) && e.buf[i] == ':' {
-			i++
+		if buf[i] != '"' {
+			break // not JSON
 		}
-		i = skipWhitespace(e.buf, i)
-		if i >= len(e.buf) {
+		keyEnd := scanJSONString(buf, i)
+		if keyEnd >= len(buf) {
 			break
 		}
-		value, ni := extractJSONValue(e.buf, i)
-		i = ni
+		key := buf[i+1 : keyEnd-1]
+		i = skipWhitespace(buf, keyEnd)
+		if i >= len(buf) || buf[i] != ':' {
+			break
+		}
+		i = skipWhitespace(buf, i+1)
+		if i >= len(buf) {
+			break
+		}
+		// Nested objects and arrays are skipped whole, so only top-level
+		// fields are visited.
+		end := scanJSONValue(buf, i)
+		value := buf[i:end]
+		if len(value) >= 2 && value[0] == '"' {
+			value = value[1 : len(value)-1]
+		}
+		i = end
 		count++
 		if !fn(key, value) {
 			return count
 		}
-		i = skipCommaIfPresent(e.buf, i)
 	}
 	return count
 }
@@ -0,0 +1,105 @@
+package bolt
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Batch collects events from a bulk producer and hands them to the
+// logger's handler in a single Write call, so a run of events costs one
+// handler lock and, for writers such as files, one system call instead of
+// one per event.
+//
+// Batch embeds a [Logger] configured like the one it was created from;
+// events logged through it, or through loggers derived from it, are
+// built, hooked and sampled as usual but held until Flush:
+//
+//	b := logger.NewBatch()
+//	for _, rec := range records {
+//	    b.Info().Str("id", rec.ID).Int("bytes", rec.Size).Msg("ingested")
+//	}
+//	if err := b.Flush(); err != nil {
+//	    // handle the write error
+//	}
+//
+// The handler receives the records as one event with the highest level
+// among them, as with [BatchHandler]. A FATAL event flushes the batch
+// before the process exits. Under a [SetMemoryBudget] budget, the batch
+// flushes early rather than hold more than the budget allows. A Batch is
+// safe for concurrent use.
+type Batch struct {
+	*Logger
+
+	mu    sync.Mutex
+	to    *handlerRef // the handler Flush writes to
+	buf   []byte
+	level Level
+	n     int
+	ev    Event // reused to pass the batch to the handler; guarded by mu
+}
+
+// NewBatch returns an empty Batch flushing to the logger's handler.
+func (l *Logger) NewBatch() *Batch {
+	b := &Batch{to: l.handler}
+	b.Logger = l.Hook()
+	b.Logger.handler = newHandlerRef(batchCollector{b})
+	return b
+}
+
+// batchCollector is the handler of a Batch's logger.
+type batchCollector struct{ b *Batch }
+
+func (c batchCollector) Write(e *Event) error {
+	b := c.b
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !memReserve(len(e.buf)) {
+		if err := b.flush(); err != nil {
+			return err
+		}
+		memCharge(len(e.buf))
+	}
+	if b.n == 0 || e.level > b.level {
+		b.level = e.level
+	}
+	b.buf = append(b.buf, e.buf...)
+	b.n++
+	if e.level == FATAL {
+		return b.flush()
+	}
+	return nil
+}
+
+// Len returns the number of events waiting in the batch.
+func (b *Batch) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.n
+}
+
+// Flush writes the collected events to the handler in one call and
+// empties the batch, which can then be reused. The batch is emptied even
+// if the write fails.
+func (b *Batch) Flush() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.flush()
+}
+
+// flush writes and empties the batch. Called with b.mu held.
+func (b *Batch) flush() error {
+	if b.n == 0 {
+		return nil
+	}
+	b.ev.buf, b.ev.level = b.buf, b.level
+	slot, stripe := b.to.acquire()
+	err := slot.h.Write(&b.ev)
+	slot.release(stripe)
+	b.ev.buf = nil
+	memRelease(len(b.buf))
+	b.buf, b.n = b.buf[:0], 0
+	if err != nil {
+		return fmt.Errorf("handler write failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package bolt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// countingWriter counts Write calls.
+type countingWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+func TestBatchFlushesInOneWrite(t *testing.T) {
+	var out countingWriter
+	logger := New(NewJSONHandler(&out)).SetLevel(INFO)
+	b := logger.NewBatch()
+
+	for i := range 100 {
+		b.Info().Int("i", i).Msg("row")
+	}
+	b.Debug().Msg("filtered by the logger's level")
+	b.With().Str("part", "tail").Logger().Warn().Msg("derived")
+	if out.writes != 0 || b.Len() != 101 {
+		t.Fatalf("before Flush: %d writes, %d queued", out.writes, b.Len())
+	}
+	if err := b.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if out.writes != 1 {
+		t.Errorf("got %d writes, want 1", out.writes)
+	}
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 101 || lines[0] != `{"level":"info","i":0,"message":"row"}` ||
+		lines[100] != `{"level":"warn","part":"tail","message":"derived"}` {
+		t.Errorf("got %d lines, first %s, last %s", len(lines), lines[0], lines[len(lines)-1])
+	}
+	if b.Len() != 0 {
+		t.Errorf("Len = %d after Flush, want 0", b.Len())
+	}
+	if err := b.Flush(); err != nil || out.writes != 1 {
+		t.Errorf("empty Flush: err %v, %d writes", err, out.writes)
+	}
+}
+
+func TestBatchLevelAndErrors(t *testing.T) {
+	rec := &batchRecorder{}
+	b := New(rec).NewBatch()
+	b.Info().Msg("a")
+	b.Error().Msg("b")
+	b.Warn().Msg("c")
+	if err := b.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.levels) != 1 || rec.levels[0] != ERROR {
+		t.Errorf("levels = %v, want one write at ERROR", rec.levels)
+	}
+
+	b = New(&failingTestHandler{shouldFail: true}).NewBatch()
+	b.Info().Msg("lost")
+	if err := b.Flush(); err == nil {
+		t.Error("Flush = nil, want the handler's error")
+	}
+	if b.Len() != 0 {
+		t.Errorf("Len = %d after a failed Flush, want 0", b.Len())
+	}
+}
+
+func TestBatchFlushesOnFatal(t *testing.T) {
+	var out countingWriter
+	b := New(NewJSONHandler(&out)).NewBatch()
+	b.Info().Msg("before")
+	b.Fatal().Msg("dying")
+	if out.writes != 1 || strings.Count(out.String(), "\n") != 2 {
+		t.Errorf("got %d writes of %q, want the batch flushed", out.writes, out.String())
+	}
+}
@@ -0,0 +1,85 @@
+package bolt
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ContextErrField is the field that marks events whose context was
+// already done when they were logged; see [Event.Ctx].
+const ContextErrField = "ctx_err"
+
+// Ctx attaches ctx to the event for the handlers, without encoding
+// anything, so that sinks can honor the request's cancellation and
+// deadline:
+//
+//	logger.Info().Ctx(r.Context()).Str("order", orderID).Msg("charged")
+//
+// A handler that would wait to write an event, because its queue is full
+// or its connection is slow, waits no longer than ctx allows: a
+// [BatchHandler] with Block, an [AsyncHandler] with BlockHigh and a
+// [JSONHandler] with workers give up when ctx is done and return their
+// queue-full error wrapping ctx.Err(), and a [SyslogHandler] bounds its
+// socket write by ctx's deadline. Events without a context wait as
+// before.
+//
+// An event whose ctx is already done when it is logged, such as the
+// error of a request that timed out, is still written, with
+// [ContextErrField] set to ctx.Err(), for example
+// "ctx_err":"context deadline exceeded", so the record shows it was
+// logged after the caller had given up. Such an event is not waited for:
+// where a handler would block, it is dropped.
+//
+// A BatchHandler, which writes many events at once, does not pass ctx on
+// to the handler it wraps. An AsyncHandler passes on ctx's values, such
+// as a trace span, but not its cancellation or deadline, since by the
+// time it writes the event the caller is no longer waiting.
+func (e *Event) Ctx(ctx context.Context) *Event {
+	if e.l == nil {
+		return e
+	}
+	e.ctx = ctx
+	return e
+}
+
+// Context returns the context attached with [Event.Ctx], or
+// [context.Background] if there is none. Handlers use it to bound how
+// long they wait for the event.
+func (e *Event) Context() context.Context {
+	if e.ctx == nil {
+		return context.Background()
+	}
+	return e.ctx
+}
+
+// done returns the channel closed when the event's context is done, or
+// nil, which blocks forever, if the event has no context.
+func (e *Event) done() <-chan struct{} {
+	if e.ctx == nil {
+		return nil
+	}
+	return e.ctx.Done()
+}
+
+// deadline returns the deadline of the event's context if it has one that
+// has not passed.
+func (e *Event) deadline() (time.Time, bool) {
+	if e.ctx == nil || e.ctx.Err() != nil {
+		return time.Time{}, false
+	}
+	return e.ctx.Deadline()
+}
+
+// ctxErr returns the error for an event dropped because its context
+// ended while a handler waited to write it.
+func (e *Event) ctxErr(full error) error {
+	return fmt.Errorf("%w: %w", full, e.ctx.Err())
+}
+
+// markCtxErr adds ContextErrField if the event's context is done.
+func (e *Event) markCtxErr() {
+	if err := e.ctx.Err(); err != nil {
+		e.Str(ContextErrField, err.Error())
+	}
+}
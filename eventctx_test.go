@@ -0,0 +1,112 @@
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ctxRecorder records the events it is given and the last one's context.
+type ctxRecorder struct {
+	buf  bytes.Buffer
+	seen context.Context
+}
+
+func (h *ctxRecorder) Write(e *Event) error {
+	h.seen = e.Context()
+	h.buf.Write(e.buf)
+	return nil
+}
+
+func TestEventCtxMarksDoneContext(t *testing.T) {
+	h := &ctxRecorder{}
+	logger := New(h)
+
+	logger.Info().Msg("plain")
+	if h.seen != context.Background() {
+		t.Errorf("Context() without Ctx = %v", h.seen)
+	}
+
+	live, stop := context.WithCancel(context.Background())
+	defer stop()
+	logger.Info().Ctx(live).Msg("live")
+	if h.seen != live {
+		t.Errorf("Context() = %v, want the attached context", h.seen)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	logger.Error().Ctx(ctx).Msg("timed out")
+
+	lines := strings.Split(strings.TrimSpace(h.buf.String()), "\n")
+	if strings.Contains(lines[1], ContextErrField) {
+		t.Errorf("live context marked: %s", lines[1])
+	}
+	if want := `"ctx_err":"context deadline exceeded","message":"timed out"}`; !strings.HasSuffix(lines[2], want) {
+		t.Errorf("got %s, want suffix %s", lines[2], want)
+	}
+}
+
+func TestEventCtxBoundsBlockingWrites(t *testing.T) {
+	record := strings.Repeat("x", 64)
+	cases := []struct {
+		name string
+		full error
+		head int // events before the writer stalls; a batch is queued by the next one
+		make func(w *stallWriter) (Handler, func())
+	}{
+		{"Batch", ErrBatchFull, 2, func(w *stallWriter) (Handler, func()) {
+			h := NewBatchHandler(NewJSONHandler(w), &BatchOptions{MaxBytes: 1, Queue: 1, Block: true})
+			return h, func() { _ = h.Close() }
+		}},
+		{"Async", ErrQueueFull, 1, func(w *stallWriter) (Handler, func()) {
+			h := NewAsyncHandler(NewJSONHandler(w), &AsyncOptions{
+				QueueSize: 1,
+				BlockHigh: true,
+				Classify:  func(*Event) Lane { return LaneHigh },
+			})
+			return h, func() { _ = h.Close() }
+		}},
+		{"JSONWorkers", ErrQueueFull, 1, func(w *stallWriter) (Handler, func()) {
+			h := NewJSONHandlerWithOptions(w, &JSONOptions{Workers: 1, QueueSize: 1})
+			return h, func() { _ = h.Close() }
+		}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := &stallWriter{started: make(chan struct{}), release: make(chan struct{})}
+			h, closeFn := tc.make(w)
+			var errs []error
+			logger := New(h).SetErrorHandler(func(err error) { errs = append(errs, err) })
+
+			// Stall the writer, then fill the queue behind it.
+			for range tc.head {
+				logger.Info().Msg(record)
+			}
+			<-w.started
+			logger.Info().Msg(record)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			start := time.Now()
+			logger.Info().Ctx(ctx).Msg(record)
+			if time.Since(start) > 5*time.Second {
+				t.Fatal("write outlived its context")
+			}
+			if len(errs) == 0 {
+				t.Fatal("no error for the event that gave up")
+			}
+			last := errs[len(errs)-1]
+			if !errors.Is(last, tc.full) || !errors.Is(last, context.DeadlineExceeded) {
+				t.Errorf("error = %v", last)
+			}
+
+			close(w.release)
+			closeFn()
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package bolt
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// EventID identifies a single log record. IDs are ULIDs: a 48-bit
+// millisecond timestamp followed by 80 random bits, so they sort by
+// creation time and render as 26 Crockford base32 characters.
+//
+// The zero EventID means "no ID".
+type EventID [16]byte
+
+// crockford is the ULID base32 alphabet (no I, L, O, U).
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewEventID returns a new ULID stamped with the current time.
+func NewEventID() EventID {
+	var id EventID
+	ms := uint64(time.Now().UnixMilli()) // #nosec G115 -- post-1970 clock
+	id[0] = byte(ms >> 40)
+	id[1] = byte(ms >> 32)
+	id[2] = byte(ms >> 24)
+	id[3] = byte(ms >> 16)
+	id[4] = byte(ms >> 8)
+	id[5] = byte(ms)
+	_, _ = rand.Read(id[6:]) // crypto/rand.Read never fails
+	return id
+}
+
+// IsZero reports whether id is the zero EventID.
+func (id EventID) IsZero() bool {
+	return id == EventID{}
+}
+
+// String returns the 26-character ULID encoding of id.
+func (id EventID) String() string {
+	return string(id.appendText(make([]byte, 0, 26)))
+}
+
+// appendText appends the ULID encoding of id: 128 bits read as a
+// big-endian number, split into 26 five-bit groups (the first holding
+// only the top three bits).
+func (id EventID) appendText(dst []byte) []byte {
+	hi := uint64(id[0])<<56 | uint64(id[1])<<48 | uint64(id[2])<<40 | uint64(id[3])<<32 |
+		uint64(id[4])<<24 | uint64(id[5])<<16 | uint64(id[6])<<8 | uint64(id[7])
+	lo := uint64(id[8])<<56 | uint64(id[9])<<48 | uint64(id[10])<<40 | uint64(id[11])<<32 |
+		uint64(id[12])<<24 | uint64(id[13])<<16 | uint64(id[14])<<8 | uint64(id[15])
+	n := len(dst)
+	dst = append(dst, "00000000000000000000000000"...)
+	out := dst[n:]
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return dst
+}
+
+// eventLink is the ID of the current unit of work and of its parent,
+// carried in a context. claimed is set once a record has used id.
+type eventLink struct {
+	id, parent EventID
+	claimed    atomic.Bool
+}
+
+type eventLinkKey struct{}
+
+// ChildEvent returns a copy of ctx carrying a fresh EventID for a new
+// unit of work, whose parent is the unit already in ctx, if any. Call it
+// once per unit of work (an incoming request, each downstream call, each
+// retry) and log that work's events with [Event.Link] to record the
+// causal chain:
+//
+//	ctx = bolt.ChildEvent(r.Context())          // request
+//	callCtx := bolt.ChildEvent(ctx)             // downstream call
+//	for attempt := 1; ; attempt++ {
+//	    tryCtx := bolt.ChildEvent(callCtx)      // each retry
+//	    logger.Warn().Link(tryCtx).Int("attempt", attempt).Msg("retrying")
+//	}
+func ChildEvent(ctx context.Context) context.Context {
+	link := &eventLink{id: NewEventID()}
+	if parent, ok := ctx.Value(eventLinkKey{}).(*eventLink); ok {
+		link.parent = parent.id
+	}
+	return context.WithValue(ctx, eventLinkKey{}, link)
+}
+
+// EventLinkFromContext returns the EventID placed in ctx by [ChildEvent]
+// and its parent. parent is zero for the root of a chain; ok is false
+// when ctx carries no EventID.
+func EventLinkFromContext(ctx context.Context) (id, parent EventID, ok bool) {
+	link, ok := ctx.Value(eventLinkKey{}).(*eventLink)
+	if !ok {
+		return EventID{}, EventID{}, false
+	}
+	return link.id, link.parent, true
+}
+
+// Link adds the "event_id" and "parent_event_id" fields for the unit of
+// work in ctx, as set by [ChildEvent], and is a no-op when ctx carries
+// none. Every record gets its own event_id:
+//
+//   - The first record linked to a unit takes the unit's EventID, with
+//     the parent unit's as parent_event_id (omitted for the root of a
+//     chain), so the chain of units is recorded.
+//   - Every later record gets a new EventID, with the unit's as
+//     parent_event_id.
+func (e *Event) Link(ctx context.Context) *Event {
+	if e.l == nil {
+		return e
+	}
+	link, ok := ctx.Value(eventLinkKey{}).(*eventLink)
+	if !ok {
+		return e
+	}
+	id, parent := link.id, link.parent
+	if !link.claimed.CompareAndSwap(false, true) {
+		id, parent = NewEventID(), link.id
+	}
+	e.appendEventID("event_id", id)
+	if !parent.IsZero() {
+		e.appendEventID("parent_event_id", parent)
+	}
+	return e
+}
+
+// EventID adds an EventID field to the event.
+func (e *Event) EventID(key string, id EventID) *Event {
+	if e.l == nil {
+		return e
+	}
+	if err := validateKey(key); err != nil {
+		if e.l.errorHandler != nil {
+			e.l.errorHandler(fmt.Errorf("invalid key in EventID(): %w", err))
+		}
+		return e
+	}
+	e.appendEventID(key, id)
+	return e
+}
+
+func (e *Event) appendEventID(key string, id EventID) {
+	e.buf = append(e.buf, ',')
+	e.buf = append(e.buf, '"')
+	e.buf = e.appendKey(key)
+	e.buf = append(e.buf, `":"`...)
+	e.buf = id.appendText(e.buf)
+	e.buf = append(e.buf, '"')
+}
@@ -0,0 +1,126 @@
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventIDEncoding(t *testing.T) {
+	var max EventID
+	for i := range max {
+		max[i] = 0xff
+	}
+	if got := (EventID{}).String(); got != "00000000000000000000000000" {
+		t.Errorf("zero = %s", got)
+	}
+	if got := max.String(); got != "7ZZZZZZZZZZZZZZZZZZZZZZZZZ" {
+		t.Errorf("max = %s", got)
+	}
+	// Canonical example from the ULID spec.
+	id := EventID{0x01, 0x56, 0x3d, 0xf3, 0x64, 0x81} // 1469918176385 ms
+	if got := id.String()[:10]; got != "01ARYZ6S41" {
+		t.Errorf("timestamp prefix = %s; want 01ARYZ6S41", got)
+	}
+}
+
+func TestNewEventIDSortsByTime(t *testing.T) {
+	a := NewEventID()
+	time.Sleep(2 * time.Millisecond)
+	b := NewEventID()
+	if a.IsZero() || a == b {
+		t.Fatalf("ids not unique: %s %s", a, b)
+	}
+	if a.String() >= b.String() {
+		t.Errorf("later id %s does not sort after %s", b, a)
+	}
+}
+
+func TestChildEventLink(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewJSONHandler(&buf))
+
+	root := ChildEvent(context.Background())
+	call := ChildEvent(root)
+	retry := ChildEvent(call)
+
+	logger.Info().Link(context.Background()).Msg("unlinked")
+	logger.Info().Link(root).Msg("request")
+	logger.Info().Link(call).Msg("call")
+	logger.Info().Link(retry).Msg("retry")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	var events []map[string]any
+	for _, line := range lines {
+		var m map[string]any
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("invalid JSON %q: %v", line, err)
+		}
+		events = append(events, m)
+	}
+	if _, ok := events[0]["event_id"]; ok {
+		t.Errorf("context without link produced event_id: %s", lines[0])
+	}
+	if _, ok := events[1]["parent_event_id"]; ok {
+		t.Errorf("root event has parent: %s", lines[1])
+	}
+	for i := 2; i < 4; i++ {
+		if events[i]["parent_event_id"] != events[i-1]["event_id"] {
+			t.Errorf("event %d not linked to %d: %s / %s", i, i-1, lines[i], lines[i-1])
+		}
+	}
+
+	id, parent, ok := EventLinkFromContext(retry)
+	callID, _, _ := EventLinkFromContext(call)
+	if !ok || parent != callID || events[3]["event_id"] != id.String() {
+		t.Errorf("EventLinkFromContext = %s, %s, %v", id, parent, ok)
+	}
+}
+
+func TestLinkGivesEveryRecordItsOwnID(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewJSONHandler(&buf))
+	parent := ChildEvent(context.Background())
+	ctx := ChildEvent(parent)
+	unit, up, _ := EventLinkFromContext(ctx)
+
+	for range 3 {
+		logger.Info().Link(ctx).Msg("step")
+	}
+	type record struct {
+		ID     string `json:"event_id"`
+		Parent string `json:"parent_event_id"`
+	}
+	var recs []record
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var r record
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatal(err)
+		}
+		recs = append(recs, r)
+	}
+	if recs[0].ID != unit.String() || recs[0].Parent != up.String() {
+		t.Errorf("first record = %+v, want the unit %s under %s", recs[0], unit, up)
+	}
+	for _, r := range recs[1:] {
+		if r.ID == unit.String() || r.ID == recs[0].ID || r.Parent != unit.String() {
+			t.Errorf("later record = %+v, want a new ID under %s", r, unit)
+		}
+	}
+	if recs[1].ID == recs[2].ID {
+		t.Errorf("records share event_id %s", recs[1].ID)
+	}
+}
+
+func TestEventIDField(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewJSONHandler(&buf))
+	id := NewEventID()
+	logger.Info().EventID("caused_by", id).Msg("x")
+	if !strings.Contains(buf.String(), `"caused_by":"`+id.String()+`"`) {
+		t.Errorf("got %s", buf.String())
+	}
+}
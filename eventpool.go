@@ -0,0 +1,21 @@
+//go:build !bolt_arena || race
+
+package bolt
+
+// getEvent takes an event from the pool. Builds with the bolt_arena tag
+// use per-P arenas instead (see eventpool_arena.go), except under the
+// race detector, which cannot see the ordering that P pinning provides.
+func getEvent() *Event {
+	return eventPool.Get().(*Event)
+}
+
+// putEvent returns e to the pool.
+func putEvent(e *Event) {
+	e.fields = e.fields[:0]
+	e.ctx = nil
+	e.noExit = false
+	if cap(e.buf) > poolRetainCap() {
+		e.buf = nil
+	}
+	eventPool.Put(e)
+}
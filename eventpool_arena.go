@@ -0,0 +1,103 @@
+//go:build bolt_arena && !race
+
+// Experimental: per-P event arenas, enabled with -tags bolt_arena.
+//
+// Each P owns a fixed stack of events whose buffers are carved from one
+// contiguous region allocated at startup. Acquiring and releasing an
+// event pins the goroutine to its P for a push or pop, the same trick
+// sync.Pool uses for its private slot, but without the interface
+// conversion, the victim cache or the GC-driven clearing that makes
+// sync.Pool refill after every collection. An event is released to the
+// arena of whichever P the goroutine runs on at the time, so arenas drift
+// but never need locks. Empty arenas fall back to eventPool, and full
+// ones hand surplus events to it. Race-enabled builds ignore the tag.
+//
+// The arenas recycle an event as soon as putEvent returns it, with no
+// epoch-based grace period. Epochs defer reuse until no reader can still
+// hold a reference, but an event has a single owner from getEvent until
+// send releases it, and handlers must copy any record they keep past
+// Write (see Handler), so there is no reader to wait for. An epoch scheme
+// would add a global epoch load and a per-P retire list to every release
+// and recycle events later, buying nothing here.
+//
+// BenchmarkEventAcquire, go1.27.1 linux/amd64, GOMAXPROCS=1, median of
+// six runs:
+//
+//	                     sync.Pool   arena
+//	GetPut               25.4ns      12.3ns
+//	GetPut/Parallel      25.0ns      12.1ns
+//	Log/Parallel         213ns       138ns
+//
+// None of the configurations allocate. The parallel numbers need
+// confirming on a multi-core machine before considering this for general
+// use.
+
+package bolt
+
+import "runtime"
+
+// arenaEvents is the number of events preallocated per P.
+const arenaEvents = 32
+
+type eventArena struct {
+	free [arenaEvents]*Event
+	n    int
+	_    [64]byte // keep neighbouring arenas off this cache line
+}
+
+// arenas is sized at startup. Ps added later by raising GOMAXPROCS use
+// eventPool.
+var arenas = newArenas(runtime.GOMAXPROCS(0))
+
+func newArenas(procs int) []eventArena {
+	a := make([]eventArena, procs)
+	region := make([]byte, procs*arenaEvents*DefaultBufferSize)
+	events := make([]Event, procs*arenaEvents)
+	for p := range a {
+		for i := range a[p].free {
+			k := p*arenaEvents + i
+			off := k * DefaultBufferSize
+			events[k].buf = region[off : off : off+DefaultBufferSize]
+			a[p].free[i] = &events[k]
+		}
+		a[p].n = arenaEvents
+	}
+	return a
+}
+
+func getEvent() *Event {
+	var e *Event
+	if pid := procPin(); pid < len(arenas) {
+		a := &arenas[pid]
+		if a.n > 0 {
+			a.n--
+			e = a.free[a.n]
+			a.free[a.n] = nil
+		}
+	}
+	procUnpin()
+	if e == nil {
+		e = eventPool.Get().(*Event)
+	}
+	return e
+}
+
+func putEvent(e *Event) {
+	e.fields = e.fields[:0]
+	e.ctx = nil
+	e.noExit = false
+	if cap(e.buf) > poolRetainCap() {
+		e.buf = nil
+	}
+	if pid := procPin(); pid < len(arenas) {
+		a := &arenas[pid]
+		if a.n < arenaEvents {
+			a.free[a.n] = e
+			a.n++
+			procUnpin()
+			return
+		}
+	}
+	procUnpin()
+	eventPool.Put(e)
+}
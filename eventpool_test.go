@@ -0,0 +1,62 @@
+package bolt
+
+import (
+	"io"
+	"testing"
+)
+
+// BenchmarkEventAcquire measures the event recycling path on its own and
+// in a full log call. Run it with and without -tags bolt_arena to compare
+// sync.Pool against the experimental per-P arenas:
+//
+//	go test -run '^$' -bench EventAcquire -count 10 > pool.txt
+//	go test -run '^$' -bench EventAcquire -count 10 -tags bolt_arena > arena.txt
+//	benchstat pool.txt arena.txt
+func BenchmarkEventAcquire(b *testing.B) {
+	b.Run("GetPut", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			putEvent(getEvent())
+		}
+	})
+	b.Run("GetPut/Parallel", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				putEvent(getEvent())
+			}
+		})
+	})
+	b.Run("Log/Parallel", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			logger := New(NewJSONHandler(io.Discard)) // per goroutine: no handler contention
+			for pb.Next() {
+				logger.Info().Str("k", "v").Int("n", 1).Msg("m")
+			}
+		})
+	})
+}
+
+func TestEventRecycling(t *testing.T) {
+	// Holding more events than an arena holds must spill to the fallback
+	// pool and come back intact.
+	held := make([]*Event, 0, 100)
+	for i := 0; i < cap(held); i++ {
+		e := getEvent()
+		if len(e.buf) != 0 {
+			t.Fatalf("event %d has stale buffer %q", i, e.buf)
+		}
+		e.buf = append(e.buf, 'x')
+		held = append(held, e)
+	}
+	seen := make(map[*Event]bool)
+	for _, e := range held {
+		if seen[e] {
+			t.Fatal("same event handed out twice")
+		}
+		seen[e] = true
+		e.buf = e.buf[:0]
+		putEvent(e)
+	}
+}
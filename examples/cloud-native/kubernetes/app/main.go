@@ -8,11 +8,11 @@ import (
 	"fmt"
 	"net/http"
 	"os"
-	"os/signal"
 	"syscall"
 	"time"
 
 	"go.klarlabs.de/bolt"
+	"go.klarlabs.de/bolt/lifecycle"
 	"github.com/google/uuid"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -463,31 +463,17 @@ func parseLogLevel(level string) bolt.Level {
 func main() {
 	app := NewApplication()
 
-	// Handle shutdown gracefully
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	// Handle shutdown gracefully, logged phase by phase
+	shutdown := lifecycle.New(
+		app.logger.With().Str("pod_name", app.config.PodName).Logger(),
+		&lifecycle.Options{Timeout: 30 * time.Second},
+	)
+	shutdown.Add("http server", app.Shutdown)
 
 	go func() {
-		sig := <-sigCh
-		app.logger.Info().
-			Str("signal", sig.String()).
-			Str("pod_name", app.config.PodName).
-			Msg("Received shutdown signal")
-
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
-
-		if err := app.Shutdown(ctx); err != nil {
-			app.logger.Error().
-				Err(err).
-				Str("pod_name", app.config.PodName).
-				Msg("Error during shutdown")
+		if err := shutdown.RunOnSignal(context.Background(), syscall.SIGINT, syscall.SIGTERM); err != nil {
 			os.Exit(1)
 		}
-
-		app.logger.Info().
-			Str("pod_name", app.config.PodName).
-			Msg("Application shutdown complete")
 		os.Exit(0)
 	}()
 
@@ -13,11 +13,11 @@ import (
 	"fmt"
 	"net"
 	"os"
-	"os/signal"
 	"syscall"
 	"time"
 
 	"go.klarlabs.de/bolt"
+	"go.klarlabs.de/bolt/lifecycle"
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -312,15 +312,23 @@ func main() {
 		}
 	}()
 
-	// Wait for interrupt
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-
-	logger.Info().Msg("shutting down grpc server")
-
-	// Graceful stop
-	grpcServer.GracefulStop()
-
-	logger.Info().Msg("grpc server stopped")
+	// Graceful stop on SIGINT/SIGTERM, logged phase by phase
+	shutdown := lifecycle.New(logger, nil)
+	shutdown.Add("grpc server", func(ctx context.Context) error {
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+			return nil
+		case <-ctx.Done():
+			grpcServer.Stop() // abort in-flight RPCs
+			return ctx.Err()
+		}
+	})
+	if err := shutdown.RunOnSignal(context.Background(), syscall.SIGINT, syscall.SIGTERM); err != nil {
+		os.Exit(1)
+	}
 }
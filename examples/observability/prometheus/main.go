@@ -545,12 +545,6 @@ func (app *Application) startMetricsUpdater() {
 			app.metrics.customGauge.WithLabelValues("system", "cpu").Set(rand.Float64() * 100)
 			app.metrics.customGauge.WithLabelValues("system", "memory").Set(rand.Float64() * 100)
 			app.metrics.processingQueue.Set(float64(rand.Intn(10)))
-
-			app.logger.Debug().
-				Float64("cpu_usage", rand.Float64()*100).
-				Float64("memory_usage", rand.Float64()*100).
-				Int("queue_size", rand.Intn(10)).
-				Msg("Metrics updated")
 		}
 	}()
 }
@@ -591,6 +585,10 @@ func main() {
 	// Start background metrics updater
 	app.startMetricsUpdater()
 
+	// Periodic heap/GC/goroutine stats in the log stream
+	stopStats := bolt.StartRuntimeStats(app.logger, &bolt.RuntimeStatsOptions{Interval: 10 * time.Second})
+	defer stopStats()
+
 	// Setup HTTP routes
 	mux := http.NewServeMux()
 
@@ -14,11 +14,11 @@ import (
 	"fmt"
 	"net/http"
 	"os"
-	"os/signal"
 	"syscall"
 	"time"
 
 	"go.klarlabs.de/bolt"
+	"go.klarlabs.de/bolt/lifecycle"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -309,22 +309,10 @@ func main() {
 		}
 	}()
 
-	// Wait for interrupt signal
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-
-	logger.Info().Msg("shutting down server")
-
-	// Graceful shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	if err := api.server.Shutdown(ctx); err != nil {
-		logger.Error().
-			Str("error", err.Error()).
-			Msg("server forced to shutdown")
+	// Graceful shutdown on SIGINT/SIGTERM, logged phase by phase
+	shutdown := lifecycle.New(logger, &lifecycle.Options{Timeout: 30 * time.Second})
+	shutdown.Add("http server", api.server.Shutdown)
+	if err := shutdown.RunOnSignal(context.Background(), syscall.SIGINT, syscall.SIGTERM); err != nil {
+		os.Exit(1)
 	}
-
-	logger.Info().Msg("server stopped")
 }
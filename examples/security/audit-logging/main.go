@@ -9,47 +9,51 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"go.klarlabs.de/bolt"
+	"go.klarlabs.de/bolt/audit"
 	"github.com/google/uuid"
 )
 
 // AuditEvent represents a structured audit event
 type AuditEvent struct {
-	EventID       string    `json:"event_id"`
-	Timestamp     time.Time `json:"timestamp"`
-	EventType     string    `json:"event_type"`
-	Action        string    `json:"action"`
-	Resource      string    `json:"resource"`
-	ResourceID    string    `json:"resource_id,omitempty"`
-	UserID        string    `json:"user_id"`
-	UserEmail     string    `json:"user_email,omitempty"`
-	SessionID     string    `json:"session_id"`
-	IPAddress     string    `json:"ip_address"`
-	UserAgent     string    `json:"user_agent"`
-	Result        string    `json:"result"`
-	ResultCode    int       `json:"result_code"`
-	ErrorMessage  string    `json:"error_message,omitempty"`
-	DataChanged   bool      `json:"data_changed"`
-	BeforeHash    string    `json:"before_hash,omitempty"`
-	AfterHash     string    `json:"after_hash,omitempty"`
-	ComplianceTag string    `json:"compliance_tag"`
-	Severity      string    `json:"severity"`
-	Category      string    `json:"category"`
+	EventType     string `json:"event_type"`
+	Action        string `json:"action"`
+	Resource      string `json:"resource"`
+	ResourceID    string `json:"resource_id,omitempty"`
+	UserID        string `json:"user_id"`
+	UserEmail     string `json:"user_email,omitempty"`
+	SessionID     string `json:"session_id"`
+	IPAddress     string `json:"ip_address"`
+	UserAgent     string `json:"user_agent"`
+	Result        string `json:"result"`
+	ResultCode    int    `json:"result_code"`
+	ErrorMessage  string `json:"error_message,omitempty"`
+	DataChanged   bool   `json:"data_changed"`
+	BeforeHash    string `json:"before_hash,omitempty"`
+	AfterHash     string `json:"after_hash,omitempty"`
+	ComplianceTag string `json:"compliance_tag"`
+	Severity      string `json:"severity"`
+	Category      string `json:"category"`
 }
 
 // AuditLogger handles enterprise audit logging
 type AuditLogger struct {
 	logger          *bolt.Logger
+	events          *audit.Logger
 	sensitiveFields []string
 }
 
-// NewAuditLogger creates a new audit logger
+// NewAuditLogger creates a new audit logger. Records are hash-chained
+// with audit.ChainWriter so the trail is tamper-evident; check it with
+// audit.Verify.
 func NewAuditLogger() *AuditLogger {
 	// Configure structured logging for audit trail
-	logger := bolt.New(bolt.NewJSONHandler(os.Stdout)).
+	logger := bolt.New(bolt.NewJSONHandler(audit.NewChainWriter(os.Stdout, ""))).
 		With().
 		Str("service", "audit-logger").
 		Str("version", "v1.0.0").
@@ -59,6 +63,7 @@ func NewAuditLogger() *AuditLogger {
 
 	return &AuditLogger{
 		logger: logger,
+		events: audit.New(logger, nil),
 		sensitiveFields: []string{
 			"password", "ssn", "credit_card", "bank_account",
 			"api_key", "token", "secret", "private_key",
@@ -66,47 +71,49 @@ func NewAuditLogger() *AuditLogger {
 	}
 }
 
-// LogAuditEvent logs a structured audit event
+// LogAuditEvent logs a structured audit event. audit.Event adds the
+// event ID and timestamp and rejects events without an actor, action,
+// resource or outcome.
 func (al *AuditLogger) LogAuditEvent(event AuditEvent) {
-	// Ensure event ID is set
-	if event.EventID == "" {
-		event.EventID = uuid.New().String()
-	}
-
-	// Set timestamp if not provided
-	if event.Timestamp.IsZero() {
-		event.Timestamp = time.Now().UTC()
+	err := al.events.Event(event.EventType).
+		Actor(event.UserID).
+		Action(event.Action).
+		Resource(event.Resource).
+		Outcome(event.Result).
+		Session(event.SessionID).
+		SourceIP(event.IPAddress).
+		Reason(event.ErrorMessage).
+		Str("resource_id", event.ResourceID).
+		Str("user_email", event.UserEmail).
+		Str("user_agent", event.UserAgent).
+		Str("result_code", strconv.Itoa(event.ResultCode)).
+		Str("data_changed", strconv.FormatBool(event.DataChanged)).
+		Str("before_hash", event.BeforeHash).
+		Str("after_hash", event.AfterHash).
+		Str("severity", event.Severity).
+		Str("category", event.Category).
+		Compliance(event.ComplianceTag).
+		Log("Audit event recorded")
+	if err != nil {
+		al.logger.Error().Err(err).Str("event_type", event.EventType).Msg("Audit event rejected")
 	}
+}
 
-	// Log structured audit event
-	al.logger.Info().
-		Str("audit_event_id", event.EventID).
-		Time("audit_timestamp", event.Timestamp).
-		Str("audit_event_type", event.EventType).
-		Str("audit_action", event.Action).
-		Str("audit_resource", event.Resource).
-		Str("audit_resource_id", event.ResourceID).
-		Str("audit_user_id", event.UserID).
-		Str("audit_user_email", event.UserEmail).
-		Str("audit_session_id", event.SessionID).
-		Str("audit_ip_address", event.IPAddress).
-		Str("audit_user_agent", event.UserAgent).
-		Str("audit_result", event.Result).
-		Int("audit_result_code", event.ResultCode).
-		Str("audit_error_message", event.ErrorMessage).
-		Bool("audit_data_changed", event.DataChanged).
-		Str("audit_before_hash", event.BeforeHash).
-		Str("audit_after_hash", event.AfterHash).
-		Str("audit_compliance_tag", event.ComplianceTag).
-		Str("audit_severity", event.Severity).
-		Str("audit_category", event.Category).
-		Msg("Audit event recorded")
+// RuntimeConfig is the configuration that can be changed while the
+// service runs. Changes are recorded with audit.LogConfigChange.
+type RuntimeConfig struct {
+	RateLimit int    `json:"rate_limit"`
+	LogLevel  string `json:"log_level"`
+	APIKey    string `json:"api_key" audit:"redact"`
 }
 
 // Application represents the main application
 type Application struct {
 	auditLogger *AuditLogger
 	logger      *bolt.Logger
+
+	mu     sync.Mutex
+	config RuntimeConfig
 }
 
 // NewApplication creates a new application with audit logging
@@ -122,6 +129,11 @@ func NewApplication() *Application {
 	return &Application{
 		auditLogger: auditLogger,
 		logger:      logger,
+		config: RuntimeConfig{
+			RateLimit: 100,
+			LogLevel:  "info",
+			APIKey:    getEnv("API_KEY", "dev-key"),
+		},
 	}
 }
 
@@ -539,6 +551,7 @@ func (app *Application) systemEventHandler(w http.ResponseWriter, r *http.Reques
 		action = "SYSTEM_CONFIGURATION_UPDATED"
 		severity = "MEDIUM"
 		category = "CONFIGURATION"
+		app.updateConfig(r)
 	default:
 		auditEventType = "SYSTEM_EVENT"
 		action = "UNKNOWN_SYSTEM_EVENT"
@@ -574,6 +587,27 @@ func (app *Application) systemEventHandler(w http.ResponseWriter, r *http.Reques
 	}`, eventType, correlationID)
 }
 
+// updateConfig applies the rate_limit, log_level and api_key query
+// parameters and records the change set; the API key is redacted.
+func (app *Application) updateConfig(r *http.Request) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	updated := app.config
+	q := r.URL.Query()
+	if v, err := strconv.Atoi(q.Get("rate_limit")); err == nil {
+		updated.RateLimit = v
+	}
+	if v := q.Get("log_level"); v != "" {
+		updated.LogLevel = v
+	}
+	if v := q.Get("api_key"); v != "" {
+		updated.APIKey = v
+	}
+	audit.LogConfigChange(app.auditLogger.logger, app.config, updated)
+	app.config = updated
+}
+
 // Utility functions
 func getOrCreateCorrelationID(r *http.Request) string {
 	if correlationID := r.Header.Get("X-Correlation-ID"); correlationID != "" {
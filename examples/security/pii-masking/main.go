@@ -1,6 +1,10 @@
 // Package main demonstrates PII masking and data protection with Bolt logging.
 // This example shows how to handle sensitive data in logs while maintaining
 // compliance with GDPR, CCPA, HIPAA, and other privacy regulations.
+//
+// For key deny-lists and email, SSN and card detection without custom
+// code, wrap the handler in bolt.NewRedactionHandler; this example shows
+// the policy-driven masking that goes beyond it.
 package main
 
 import (
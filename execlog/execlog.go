@@ -0,0 +1,211 @@
+// Package execlog turns a child process's stdout and stderr into bolt
+// events, one per line, instead of raw byte dumps.
+//
+// Every line becomes an event whose message is the line and which carries
+// "stream" ("stdout" or "stderr") and "cmd" (the program name). Lines
+// longer than [Options.MaxLineLength] are truncated and flagged with
+// "truncated":true. With [Options.JSON], lines that are themselves JSON
+// log records (a child using bolt, zap, slog's JSON handler...) are
+// passed through structurally: their level and message are lifted onto
+// the event and the full record is embedded under "record".
+//
+// Example:
+//
+//	cmd := exec.CommandContext(ctx, "pg_dump", "-Fc", db)
+//	if err := execlog.Run(cmd, logger, &execlog.Options{JSON: true}); err != nil {
+//	    logger.Error().Err(err).Msg("backup failed")
+//	}
+package execlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"go.klarlabs.de/bolt"
+	"go.klarlabs.de/bolt/reader"
+)
+
+// DefaultMaxLineLength is the line length, in bytes, beyond which lines
+// are truncated when no limit is configured.
+const DefaultMaxLineLength = 8 * 1024
+
+// Options configures the bridge. A nil *Options uses the defaults.
+type Options struct {
+	// MaxLineLength truncates longer lines. Defaults to
+	// DefaultMaxLineLength.
+	MaxLineLength int
+
+	// Level picks the level for a plain-text line. Defaults to INFO for
+	// stdout and WARN for stderr.
+	Level func(stream string, line []byte) bolt.Level
+
+	// JSON detects lines that are JSON objects and passes them through:
+	// the record's "level" and "message" (or "msg") become the event's,
+	// and the record itself is embedded under "record".
+	JSON bool
+}
+
+func (o *Options) level(stream string, line []byte) bolt.Level {
+	if o.Level != nil {
+		return o.Level(stream, line)
+	}
+	if stream == "stderr" {
+		return bolt.WARN
+	}
+	return bolt.INFO
+}
+
+// Run attaches the bridge to cmd, runs it and waits for it to exit. The
+// last line is logged even if it has no trailing newline.
+func Run(cmd *exec.Cmd, logger *bolt.Logger, opts *Options) error {
+	flush := Attach(cmd, logger, opts)
+	err := cmd.Run()
+	flush()
+	return err
+}
+
+// Attach sets cmd.Stdout and cmd.Stderr to line writers that log to
+// logger. Call the returned flush after cmd.Wait to log an unterminated
+// final line; it is a no-op otherwise.
+func Attach(cmd *exec.Cmd, logger *bolt.Logger, opts *Options) (flush func()) {
+	name := filepath.Base(cmd.Path)
+	stdout := NewWriter(logger.With().Str("cmd", name).Logger(), "stdout", opts)
+	stderr := NewWriter(logger.With().Str("cmd", name).Logger(), "stderr", opts)
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	return func() {
+		stdout.Flush()
+		stderr.Flush()
+	}
+}
+
+// Writer is an io.Writer that logs every line written to it. It is safe
+// for concurrent use.
+type Writer struct {
+	logger *bolt.Logger
+	stream string
+	opts   *Options
+	max    int
+
+	mu        sync.Mutex
+	buf       []byte
+	truncated bool // buf was cut at max; skip input until the next newline
+}
+
+// NewWriter returns a Writer logging lines to logger with "stream" set to
+// stream. If opts is nil, defaults are used.
+func NewWriter(logger *bolt.Logger, stream string, opts *Options) *Writer {
+	if opts == nil {
+		opts = &Options{}
+	}
+	maxLen := opts.MaxLineLength
+	if maxLen <= 0 {
+		maxLen = DefaultMaxLineLength
+	}
+	return &Writer{logger: logger, stream: stream, opts: opts, max: maxLen}
+}
+
+// Write logs each complete line in p and buffers the remainder.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		chunk := p
+		if i >= 0 {
+			chunk = p[:i]
+		}
+		if !w.truncated {
+			room := w.max - len(w.buf)
+			if len(chunk) > room {
+				chunk = chunk[:room]
+				w.truncated = true
+			}
+			w.buf = append(w.buf, chunk...)
+		}
+		if i < 0 {
+			break
+		}
+		w.emit()
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+// Flush logs a buffered partial line, if any.
+func (w *Writer) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.buf) > 0 || w.truncated {
+		w.emit()
+	}
+}
+
+// emit logs the buffered line and resets the buffer. Called with w.mu held.
+func (w *Writer) emit() {
+	line := bytes.TrimSuffix(w.buf, []byte{'\r'})
+	truncated := w.truncated
+	w.buf, w.truncated = w.buf[:0], false
+
+	if w.opts.JSON && !truncated && len(line) > 0 && line[0] == '{' {
+		if rec, err := reader.Parse(line); err == nil {
+			w.emitRecord(rec, line)
+			return
+		}
+	}
+	e := w.event(w.opts.level(w.stream, line)).Str("stream", w.stream)
+	if truncated {
+		e = e.Bool("truncated", true)
+	}
+	e.Msg(string(line))
+}
+
+func (w *Writer) emitRecord(rec *reader.Record, line []byte) {
+	level, ok := parseLevel(rec.Str("level"))
+	if !ok {
+		level = w.opts.level(w.stream, line)
+	}
+	msg := rec.Message()
+	if msg == "" {
+		msg = rec.Str("msg") // slog and zap
+	}
+	w.event(level).
+		Str("stream", w.stream).
+		Any("record", json.RawMessage(line)).
+		Msg(msg)
+}
+
+// parseLevel accepts bolt's lower-case names as well as slog's
+// upper-case ones and the common "warning" spelling.
+func parseLevel(s string) (bolt.Level, bool) {
+	s = strings.ToLower(s)
+	if s == "warning" {
+		return bolt.WARN, true
+	}
+	for l := bolt.TRACE; l <= bolt.FATAL; l++ {
+		if l.String() == s {
+			return l, true
+		}
+	}
+	return bolt.INFO, false
+}
+
+func (w *Writer) event(level bolt.Level) *bolt.Event {
+	switch level {
+	case bolt.TRACE:
+		return w.logger.Trace()
+	case bolt.DEBUG:
+		return w.logger.Debug()
+	case bolt.WARN:
+		return w.logger.Warn()
+	case bolt.ERROR, bolt.FATAL:
+		// A child's FATAL must not terminate the parent.
+		return w.logger.Error()
+	default:
+		return w.logger.Info()
+	}
+}
@@ -0,0 +1,104 @@
+package execlog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"go.klarlabs.de/bolt"
+	"go.klarlabs.de/bolt/execlog"
+)
+
+func events(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var m map[string]any
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("invalid JSON %q: %v", line, err)
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+func TestWriterSplitsLinesAcrossWrites(t *testing.T) {
+	var buf bytes.Buffer
+	w := execlog.NewWriter(bolt.New(bolt.NewJSONHandler(&buf)), "stdout", nil)
+	for _, chunk := range []string{"hel", "lo\r\nwor", "ld\n", "tail"} {
+		if _, err := w.Write([]byte(chunk)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w.Flush()
+
+	evs := events(t, &buf)
+	if len(evs) != 3 {
+		t.Fatalf("got %d events: %s", len(evs), buf.String())
+	}
+	for i, want := range []string{"hello", "world", "tail"} {
+		if evs[i]["message"] != want || evs[i]["stream"] != "stdout" || evs[i]["level"] != "info" {
+			t.Errorf("event %d = %v", i, evs[i])
+		}
+	}
+}
+
+func TestWriterTruncatesLongLines(t *testing.T) {
+	var buf bytes.Buffer
+	w := execlog.NewWriter(bolt.New(bolt.NewJSONHandler(&buf)), "stderr", &execlog.Options{MaxLineLength: 5})
+	_, _ = w.Write([]byte("abcdefghij"))
+	_, _ = w.Write([]byte("klm\nok\n"))
+
+	evs := events(t, &buf)
+	if len(evs) != 2 {
+		t.Fatalf("got %d events: %s", len(evs), buf.String())
+	}
+	if evs[0]["message"] != "abcde" || evs[0]["truncated"] != true || evs[0]["level"] != "warn" {
+		t.Errorf("truncated event = %v", evs[0])
+	}
+	if evs[1]["message"] != "ok" || evs[1]["truncated"] != nil {
+		t.Errorf("following line = %v", evs[1])
+	}
+}
+
+func TestWriterJSONPassthrough(t *testing.T) {
+	var buf bytes.Buffer
+	w := execlog.NewWriter(bolt.New(bolt.NewJSONHandler(&buf)), "stderr", &execlog.Options{JSON: true})
+	_, _ = w.Write([]byte(`{"time":"2025-01-01T00:00:00Z","level":"ERROR","msg":"db down","attempt":3}` + "\n"))
+	_, _ = w.Write([]byte("{not json\n"))
+
+	evs := events(t, &buf)
+	if evs[0]["level"] != "error" || evs[0]["message"] != "db down" {
+		t.Errorf("passthrough event = %v", evs[0])
+	}
+	if rec, ok := evs[0]["record"].(map[string]any); !ok || rec["attempt"] != float64(3) {
+		t.Errorf("record not embedded: %v", evs[0])
+	}
+	if evs[1]["message"] != "{not json" || evs[1]["record"] != nil {
+		t.Errorf("invalid JSON line not logged as text: %v", evs[1])
+	}
+}
+
+func TestRun(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	var buf bytes.Buffer
+	cmd := exec.Command("sh", "-c", `echo out; echo err >&2; printf partial`)
+	if err := execlog.Run(cmd, bolt.New(bolt.NewJSONHandler(&buf)), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]string{}
+	for _, ev := range events(t, &buf) {
+		if ev["cmd"] != "sh" {
+			t.Errorf("cmd = %v", ev["cmd"])
+		}
+		got[ev["message"].(string)] = ev["stream"].(string)
+	}
+	if got["out"] != "stdout" || got["err"] != "stderr" || got["partial"] != "stdout" {
+		t.Errorf("events = %v", got)
+	}
+}
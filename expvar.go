@@ -0,0 +1,86 @@
+package bolt
+
+import (
+	"encoding/json"
+	"expvar"
+	"runtime/debug"
+	"time"
+)
+
+// DefaultExpvarInterval is the emission interval used by
+// [StartExpvarSnapshots] when none is configured.
+const DefaultExpvarInterval = time.Minute
+
+// ExpvarOptions configures [StartExpvarSnapshots].
+type ExpvarOptions struct {
+	// Interval between events. Defaults to DefaultExpvarInterval.
+	Interval time.Duration
+
+	// Vars names the expvar variables to include. Empty means every
+	// published variable except "cmdline" and "memstats", which are large
+	// and already covered by [StartRuntimeStats].
+	Vars []string
+
+	// GCStats adds a "gc" object from [debug.ReadGCStats]: the collection
+	// count, total and median pause, longest recent pause and the time of
+	// the last collection.
+	GCStats bool
+}
+
+// StartExpvarSnapshots starts a background goroutine that logs an
+// "expvar snapshot" INFO event every interval until the returned stop
+// function is called, so services that only publish expvar counters can
+// be observed through the log pipeline. If opts is nil, defaults are
+// used.
+//
+// Variables are logged under a "vars" object with their JSON values
+// embedded as is:
+//
+//	{"level":"info","vars":{"requests":1042,"queue":{"depth":3}},"message":"expvar snapshot"}
+//
+// Variables that are not published (yet) are omitted. stop blocks until
+// the goroutine has exited and is safe to call more than once.
+func StartExpvarSnapshots(logger *Logger, opts *ExpvarOptions) (stop func()) {
+	if opts == nil {
+		opts = &ExpvarOptions{}
+	}
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = DefaultExpvarInterval
+	}
+	return startTicker(interval, func() { logExpvarSnapshot(logger, opts) })
+}
+
+func logExpvarSnapshot(logger *Logger, opts *ExpvarOptions) {
+	e := logger.Info().Dict("vars", func(d *Event) {
+		if len(opts.Vars) > 0 {
+			for _, name := range opts.Vars {
+				if v := expvar.Get(name); v != nil {
+					d.Any(name, json.RawMessage(v.String()))
+				}
+			}
+			return
+		}
+		expvar.Do(func(kv expvar.KeyValue) {
+			if kv.Key != "cmdline" && kv.Key != "memstats" {
+				d.Any(kv.Key, json.RawMessage(kv.Value.String()))
+			}
+		})
+	})
+	if opts.GCStats {
+		e = e.Dict("gc", appendGCStats)
+	}
+	e.Msg("expvar snapshot")
+}
+
+func appendGCStats(d *Event) {
+	// Quantiles of the recent pauses: min, median and max.
+	stats := debug.GCStats{PauseQuantiles: make([]time.Duration, 3)}
+	debug.ReadGCStats(&stats)
+	d.Int64("count", stats.NumGC).Dur("pause_total", stats.PauseTotal)
+	if stats.NumGC > 0 {
+		d.Dur("pause_p50", stats.PauseQuantiles[1]).
+			Dur("pause_max", stats.PauseQuantiles[2]).
+			Time("last", stats.LastGC)
+	}
+}
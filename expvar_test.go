@@ -0,0 +1,68 @@
+package bolt
+
+import (
+	"encoding/json"
+	"expvar"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpvarSnapshot(t *testing.T) {
+	requests := expvar.NewInt("bolt_test_requests")
+	requests.Add(42)
+	queue := expvar.NewMap("bolt_test_queue")
+	queue.Add("depth", 3)
+	runtime.GC()
+
+	buf := &ThreadSafeBuffer{}
+	logger := New(NewJSONHandler(buf))
+	logExpvarSnapshot(logger, &ExpvarOptions{
+		Vars:    []string{"bolt_test_requests", "bolt_test_queue", "bolt_test_missing"},
+		GCStats: true,
+	})
+
+	var got struct {
+		Message string
+		Vars    map[string]json.RawMessage
+		GC      map[string]any
+	}
+	if err := json.Unmarshal([]byte(buf.String()), &got); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	if got.Message != "expvar snapshot" {
+		t.Errorf("message = %q", got.Message)
+	}
+	if string(got.Vars["bolt_test_requests"]) != "42" || string(got.Vars["bolt_test_queue"]) != `{"depth":3}` {
+		t.Errorf("vars = %s", buf.String())
+	}
+	if _, ok := got.Vars["bolt_test_missing"]; ok {
+		t.Error("unpublished variable logged")
+	}
+	for _, key := range []string{"count", "pause_total", "pause_p50", "pause_max", "last"} {
+		if _, ok := got.GC[key]; !ok {
+			t.Errorf("gc.%s missing in %s", key, buf.String())
+		}
+	}
+}
+
+func TestStartExpvarSnapshotsDefaults(t *testing.T) {
+	expvar.NewString("bolt_test_version").Set("1.2.3")
+	buf := &ThreadSafeBuffer{}
+	stop := StartExpvarSnapshots(New(NewJSONHandler(buf)), &ExpvarOptions{Interval: 5 * time.Millisecond})
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(buf.String(), "expvar snapshot") && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	stop()
+	stop()
+
+	line, _, _ := strings.Cut(buf.String(), "\n")
+	if !strings.Contains(line, `"bolt_test_version":"1.2.3"`) {
+		t.Errorf("published variable missing: %s", line)
+	}
+	if strings.Contains(line, `"memstats"`) || strings.Contains(line, `"cmdline"`) {
+		t.Errorf("default snapshot includes memstats or cmdline: %s", line)
+	}
+}
@@ -0,0 +1,101 @@
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// DefaultExtractorMaxBytes caps the encoded size of a value returned by a
+// [ContextExtractor]. Larger values are replaced by a placeholder.
+const DefaultExtractorMaxBytes = 1024
+
+// ContextExtractor returns a field carried by ctx, such as a tenant or
+// request ID, for [Logger.Ctx] to add to the derived logger. ok is false
+// when ctx does not carry the field.
+type ContextExtractor func(ctx context.Context) (key string, value any, ok bool)
+
+// AddContextExtractor registers fn to run on every [Logger.Ctx] call and
+// returns l. Extractors run in order and are inherited by child loggers.
+// Like AddHook, it is intended for setup-time configuration.
+//
+// An extractor that returns something it should not, such as a whole
+// request struct, would copy that value into every event of the request.
+// Values that cannot be encoded as JSON or whose encoding exceeds
+// DefaultExtractorMaxBytes are therefore replaced by a short placeholder
+// naming the type:
+//
+//	{"request":"[omitted: *http.Request, 18432 bytes]"}
+//
+// With [SetDevChecks] enabled, each such extractor key is also reported
+// once as a dev check warning.
+//
+// Fields stored by [Logger.CacheCtx] are extracted when the cache is
+// created, so later contexts inside the same span reuse them.
+func (l *Logger) AddContextExtractor(fn ContextExtractor) *Logger {
+	l.extractors = append(l.extractors[:len(l.extractors):len(l.extractors)], fn)
+	return l
+}
+
+// applyExtractors returns a child of l with the fields of its extractors
+// added to the context, or l if none applies.
+func (l *Logger) applyExtractors(ctx context.Context) *Logger {
+	var b *ContextBuilder
+	for _, fn := range l.extractors {
+		key, value, ok := fn(ctx)
+		if !ok {
+			continue
+		}
+		if b == nil {
+			b = l.Context()
+		}
+		appendExtracted((*Event)(b), key, value)
+	}
+	if b == nil {
+		return l
+	}
+	return b.Logger()
+}
+
+// appendExtracted adds an extracted field, replacing values that do not
+// encode or encode too large.
+func appendExtracted(e *Event, key string, value any) {
+	start := len(e.buf)
+	e.Any(key, value)
+	var spans [1]FieldSpan
+	sp := appendFieldSpans(spans[:0], e.buf, start)
+	if len(sp) == 0 {
+		return // invalid key, reported by Any
+	}
+	v := sp[0].Value(e.buf)
+	var reason string
+	switch {
+	case bytes.HasPrefix(v, []byte(`"!ERROR: `)):
+		reason = "not serializable"
+	case len(v) > DefaultExtractorMaxBytes:
+		reason = strconv.Itoa(len(v)) + " bytes"
+	default:
+		return
+	}
+	e.buf = e.buf[:sp[0].Colon+1]
+	e.buf = append(e.buf, '"')
+	e.buf = appendJSONString(e.buf, fmt.Sprintf("[omitted: %T, %s]", value, reason))
+	e.buf = append(e.buf, '"')
+	warnExtractor(key, value, reason)
+}
+
+// extractorWarned records the extractor keys already reported.
+var extractorWarned sync.Map
+
+func warnExtractor(key string, value any, reason string) {
+	wp := devChecks.Load()
+	if wp == nil {
+		return
+	}
+	if _, dup := extractorWarned.LoadOrStore(key, true); dup {
+		return
+	}
+	fmt.Fprintf(*wp, "bolt: dev check: context extractor for %q returned %T (%s); logged as a placeholder\n", key, value, reason)
+}
@@ -0,0 +1,62 @@
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+type tenantKey struct{}
+
+func TestContextExtractor(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewJSONHandler(&buf)).AddContextExtractor(func(ctx context.Context) (string, any, bool) {
+		v, ok := ctx.Value(tenantKey{}).(string)
+		return "tenant", v, ok
+	})
+
+	logger.Ctx(context.WithValue(context.Background(), tenantKey{}, "acme")).Info().Msg("with")
+	logger.Ctx(context.Background()).Info().Msg("without")
+	logger.With().Str("svc", "api").Logger().
+		Ctx(context.WithValue(context.Background(), tenantKey{}, "beta")).Info().Msg("child")
+
+	want := `{"level":"info","tenant":"acme","message":"with"}` + "\n" +
+		`{"level":"info","message":"without"}` + "\n" +
+		`{"level":"info","svc":"api","tenant":"beta","message":"child"}` + "\n"
+	if buf.String() != want {
+		t.Errorf("got  %s\nwant %s", buf.String(), want)
+	}
+}
+
+func TestContextExtractorGuard(t *testing.T) {
+	type request struct{ Body string }
+	var warnings bytes.Buffer
+	SetDevChecks(&warnings)
+	defer SetDevChecks(nil)
+
+	var buf bytes.Buffer
+	logger := New(NewJSONHandler(&buf)).
+		AddContextExtractor(func(context.Context) (string, any, bool) {
+			return "guard_request", &request{Body: strings.Repeat("x", 2*DefaultExtractorMaxBytes)}, true
+		}).
+		AddContextExtractor(func(context.Context) (string, any, bool) {
+			return "guard_callback", func() {}, true
+		}).
+		AddContextExtractor(func(context.Context) (string, any, bool) {
+			return "ok", 42, true
+		})
+
+	for range 2 {
+		logger.Ctx(context.Background()).Info().Msg("m")
+	}
+	line, _, _ := strings.Cut(buf.String(), "\n")
+	want := `{"level":"info","guard_request":"[omitted: *bolt.request, 2059 bytes]","guard_callback":"[omitted: func(), not serializable]","ok":42,"message":"m"}`
+	if line != want {
+		t.Errorf("got  %s\nwant %s", line, want)
+	}
+	w := warnings.String()
+	if strings.Count(w, "\n") != 2 || !strings.Contains(w, `extractor for "guard_request" returned *bolt.request`) {
+		t.Errorf("warnings = %q, want one per key", w)
+	}
+}
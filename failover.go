@@ -0,0 +1,215 @@
+package bolt
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultFailoverRetry is how long a [FailoverHandler] writes to its
+	// secondary before probing the primary again, when no interval is
+	// configured.
+	DefaultFailoverRetry = 10 * time.Second
+	// DefaultFailoverSpillSize is how many bytes of spilled records a
+	// [FailoverHandler] keeps for replay when no size is configured.
+	DefaultFailoverSpillSize = 16 << 20 // 16 MiB
+)
+
+// FailoverOptions configures [NewFailoverHandler]. A nil
+// *FailoverOptions uses the defaults.
+type FailoverOptions struct {
+	// Retry is how long after a failure the primary is probed again.
+	// Defaults to DefaultFailoverRetry.
+	Retry time.Duration
+
+	// Probe checks whether the primary has recovered, for example by
+	// dialing the collector. Nil probes by writing the next event to the
+	// primary.
+	Probe func() error
+
+	// Replay keeps the records spilled to the secondary and writes them
+	// to the primary, oldest first, once it recovers.
+	Replay bool
+
+	// SpillSize is how many bytes of spilled records are kept for
+	// replay; the oldest are discarded beyond it. Defaults to
+	// DefaultFailoverSpillSize.
+	SpillSize int
+
+	// ErrorHandler, if set, receives the primary's errors, which Write
+	// does not return while the secondary takes the events.
+	ErrorHandler ErrorHandler
+}
+
+// FailoverStats are the counters of a [FailoverHandler].
+type FailoverStats struct {
+	Primary    uint64 // events written to the primary
+	Secondary  uint64 // events spilled to the secondary
+	Replayed   uint64 // spilled events replayed to the primary
+	Discarded  uint64 // spilled events discarded from the replay buffer
+	Failovers  uint64 // switches from the primary to the secondary
+	FailedOver bool   // whether events currently go to the secondary
+}
+
+// FailoverHandler writes to a primary handler, typically a network sink,
+// and spills to a secondary, typically a local file, while the primary
+// fails:
+//
+//	h := bolt.NewFailoverHandler(httpHandler, bolt.NewJSONHandler(spillFile), &bolt.FailoverOptions{
+//	    Replay: true,
+//	})
+//	logger := bolt.New(h)
+//
+// The first failed write switches to the secondary, with the failed event
+// spilled too. Every Retry the primary is probed, with Probe or else with
+// the next event, and once it succeeds events go to the primary again.
+// With Replay, the spilled records, up to SpillSize bytes, are first
+// written to the primary in order; replay runs on the goroutine whose
+// event finds the primary recovered, and a failure during it switches
+// back to the secondary with the rest kept.
+//
+// Write returns an error only when the secondary fails too. Writes are
+// serialized. FailoverHandler is safe for concurrent use.
+type FailoverHandler struct {
+	primary   Handler
+	secondary Handler
+	retry     time.Duration
+	probe     func() error
+	replay    bool
+	maxSpill  int
+	onError   ErrorHandler
+	now       func() time.Time
+
+	mu        sync.Mutex
+	failed    bool
+	probeAt   time.Time
+	spill     []asyncRecord // oldest first, starting at head
+	head      int
+	spillSize int
+	ev        Event // reused to replay records
+	stats     FailoverStats
+}
+
+// NewFailoverHandler returns a FailoverHandler writing to primary and
+// failing over to secondary.
+func NewFailoverHandler(primary, secondary Handler, opts *FailoverOptions) *FailoverHandler {
+	if opts == nil {
+		opts = &FailoverOptions{}
+	}
+	h := &FailoverHandler{
+		primary:   primary,
+		secondary: secondary,
+		retry:     opts.Retry,
+		probe:     opts.Probe,
+		replay:    opts.Replay,
+		maxSpill:  opts.SpillSize,
+		onError:   opts.ErrorHandler,
+		now:       time.Now,
+	}
+	if h.retry <= 0 {
+		h.retry = DefaultFailoverRetry
+	}
+	if h.maxSpill <= 0 {
+		h.maxSpill = DefaultFailoverSpillSize
+	}
+	return h
+}
+
+// Write implements [Handler].
+func (h *FailoverHandler) Write(e *Event) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.failed && !h.recovered() {
+		return h.spillEvent(e)
+	}
+	if err := h.primary.Write(e); err != nil {
+		h.failover(err)
+		return h.spillEvent(e)
+	}
+	h.stats.Primary++
+	return nil
+}
+
+// recovered probes the primary if it is due and, if it answers, replays
+// the spill buffer and reports whether the primary takes events again.
+// Called with h.mu held.
+func (h *FailoverHandler) recovered() bool {
+	if h.now().Before(h.probeAt) {
+		return false
+	}
+	if h.probe != nil {
+		if err := h.probe(); err != nil {
+			h.probeAt = h.now().Add(h.retry)
+			h.report(err)
+			return false
+		}
+	}
+	for h.head < len(h.spill) {
+		rec := h.spill[h.head]
+		h.ev.level, h.ev.buf = rec.level, rec.buf
+		err := h.primary.Write(&h.ev)
+		h.ev.buf = nil
+		if err != nil {
+			h.probeAt = h.now().Add(h.retry)
+			h.report(err)
+			return false
+		}
+		h.spill[h.head] = asyncRecord{}
+		h.head++
+		h.spillSize -= len(rec.buf)
+		h.stats.Replayed++
+	}
+	h.spill, h.head, h.spillSize = h.spill[:0], 0, 0
+	h.failed = false
+	h.stats.FailedOver = false
+	return true
+}
+
+// failover switches to the secondary. Called with h.mu held.
+func (h *FailoverHandler) failover(err error) {
+	h.failed = true
+	h.probeAt = h.now().Add(h.retry)
+	h.stats.Failovers++
+	h.stats.FailedOver = true
+	h.report(err)
+}
+
+// spillEvent writes e to the secondary and keeps it for replay. Called
+// with h.mu held.
+func (h *FailoverHandler) spillEvent(e *Event) error {
+	h.stats.Secondary++
+	if h.replay && len(e.buf) <= h.maxSpill {
+		h.spillSize += len(e.buf)
+		for h.spillSize > h.maxSpill {
+			h.spillSize -= len(h.spill[h.head].buf)
+			h.spill[h.head] = asyncRecord{}
+			h.head++
+			h.stats.Discarded++
+		}
+		if h.head > len(h.spill)/2 {
+			n := copy(h.spill, h.spill[h.head:])
+			clear(h.spill[n:])
+			h.spill, h.head = h.spill[:n], 0
+		}
+		h.spill = append(h.spill, asyncRecord{level: e.level, buf: bytes.Clone(e.buf)})
+	}
+	if err := h.secondary.Write(e); err != nil {
+		return fmt.Errorf("bolt: failover secondary: %w", err)
+	}
+	return nil
+}
+
+func (h *FailoverHandler) report(err error) {
+	if h.onError != nil {
+		h.onError(err)
+	}
+}
+
+// Stats returns the handler's counters.
+func (h *FailoverHandler) Stats() FailoverStats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.stats
+}
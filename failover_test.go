@@ -0,0 +1,137 @@
+package bolt
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// flakyHandler writes to buf unless down.
+type flakyHandler struct {
+	buf  bytes.Buffer
+	down bool
+}
+
+func (h *flakyHandler) Write(e *Event) error {
+	if h.down {
+		return errors.New("connection refused")
+	}
+	h.buf.Write(e.buf)
+	return nil
+}
+
+func recordMessages(s string) []string {
+	var msgs []string
+	for _, line := range strings.Split(strings.TrimSpace(s), "\n") {
+		if _, msg, ok := strings.Cut(line, `"message":"`); ok {
+			msg, _, _ = strings.Cut(msg, `"`)
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs
+}
+
+func TestFailoverHandlerReplay(t *testing.T) {
+	primary := &flakyHandler{}
+	var secondary bytes.Buffer
+	var reported []error
+	h := NewFailoverHandler(primary, NewJSONHandler(&secondary), &FailoverOptions{
+		Retry:        time.Minute,
+		Replay:       true,
+		ErrorHandler: func(err error) { reported = append(reported, err) },
+	})
+	now := time.Unix(0, 0)
+	h.now = func() time.Time { return now }
+	logger := New(h).SetErrorHandler(func(err error) { t.Error(err) })
+
+	logger.Info().Msg("a")
+	primary.down = true
+	logger.Info().Msg("b")
+	logger.Info().Msg("c")
+	if got := strings.Join(recordMessages(secondary.String()), ","); got != "b,c" {
+		t.Errorf("secondary got %s", got)
+	}
+	if len(reported) != 1 || !h.Stats().FailedOver {
+		t.Fatalf("reported %v, stats %+v", reported, h.Stats())
+	}
+
+	// Not probed again before Retry, even though the primary is back.
+	primary.down = false
+	logger.Info().Msg("d")
+	if got := strings.Join(recordMessages(primary.buf.String()), ","); got != "a" {
+		t.Errorf("primary got %s before retry", got)
+	}
+
+	now = now.Add(time.Minute)
+	logger.Info().Msg("e")
+	if got := strings.Join(recordMessages(primary.buf.String()), ","); got != "a,b,c,d,e" {
+		t.Errorf("primary got %s after recovery", got)
+	}
+	want := FailoverStats{Primary: 2, Secondary: 3, Replayed: 3, Failovers: 1}
+	if s := h.Stats(); s != want {
+		t.Errorf("stats = %+v, want %+v", s, want)
+	}
+}
+
+func TestFailoverHandlerProbe(t *testing.T) {
+	primary := &flakyHandler{down: true}
+	var secondary bytes.Buffer
+	probeErr := errors.New("dial failed")
+	probes := 0
+	h := NewFailoverHandler(primary, NewJSONHandler(&secondary), &FailoverOptions{
+		Retry: time.Second,
+		Probe: func() error { probes++; return probeErr },
+	})
+	now := time.Unix(0, 0)
+	h.now = func() time.Time { return now }
+	logger := New(h)
+
+	logger.Info().Msg("a")
+	now = now.Add(time.Second)
+	logger.Info().Msg("b")
+	logger.Info().Msg("c")
+	if probes != 1 {
+		t.Errorf("probed %d times", probes)
+	}
+
+	primary.down = false
+	probeErr = nil
+	now = now.Add(time.Second)
+	logger.Info().Msg("d")
+	if got := strings.Join(recordMessages(primary.buf.String()), ","); got != "d" {
+		t.Errorf("primary got %s; spill kept without Replay", got)
+	}
+	if s := h.Stats(); s.Secondary != 3 || s.Replayed != 0 || s.FailedOver {
+		t.Errorf("stats = %+v", s)
+	}
+}
+
+func TestFailoverHandlerSpillBounded(t *testing.T) {
+	primary := &flakyHandler{down: true}
+	h := NewFailoverHandler(primary, NewJSONHandler(&bytes.Buffer{}), &FailoverOptions{Replay: true, SpillSize: 200})
+	h.now = func() time.Time { return time.Unix(0, 0) }
+	logger := New(h)
+	for i := range 20 {
+		logger.Info().Int("i", i).Msg("spilled")
+	}
+	h.mu.Lock()
+	size, n := h.spillSize, len(h.spill)-h.head
+	h.mu.Unlock()
+	if size > 200 || n == 0 {
+		t.Errorf("spill holds %d records of %d bytes", n, size)
+	}
+	if s := h.Stats(); s.Discarded != uint64(20-n) {
+		t.Errorf("stats = %+v with %d kept", s, n)
+	}
+}
+
+func TestFailoverHandlerSecondaryError(t *testing.T) {
+	h := NewFailoverHandler(&flakyHandler{down: true}, &flakyHandler{down: true}, nil)
+	var got error
+	New(h).SetErrorHandler(func(err error) { got = err }).Info().Msg("lost")
+	if got == nil || !strings.Contains(got.Error(), "bolt: failover secondary: connection refused") {
+		t.Errorf("error = %v", got)
+	}
+}
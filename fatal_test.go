@@ -79,3 +79,26 @@ func TestFatal_TerminatesProcess(t *testing.T) {
 		t.Errorf("subprocess output missing fatal record:\n%s", out)
 	}
 }
+
+func TestFatal_NoExit(t *testing.T) {
+	var called bool
+	prev := exitFunc
+	exitFunc = func(int) { called = true }
+	t.Cleanup(func() { exitFunc = prev })
+
+	var buf bytes.Buffer
+	logger := New(NewJSONHandler(&buf))
+	logger.Fatal().NoExit().Msg("handled elsewhere")
+	if called {
+		t.Error("exitFunc invoked for a NoExit FATAL event")
+	}
+	if !strings.Contains(buf.String(), `"level":"fatal"`) {
+		t.Errorf("FATAL record missing from output: %q", buf.String())
+	}
+
+	// The flag does not survive event reuse.
+	logger.Fatal().Msg("exits")
+	if !called {
+		t.Error("exitFunc not invoked for a FATAL event after a NoExit one")
+	}
+}
@@ -0,0 +1,146 @@
+package bolt
+
+// FieldSpan locates one top-level field of an event in its buffer:
+// buf[Start:End] is the encoded `"key":value`, without the separating
+// comma. Obtain spans with [Event.FieldSpans] and slice the buffer from
+// [Event.Buffer] with them.
+type FieldSpan struct {
+	Start int // index of the opening quote of the key
+	Colon int // index of the ':' between key and value
+	End   int // index after the value
+}
+
+// Key returns the field's key as encoded, without quotes. Keys that
+// needed escaping are returned escaped.
+func (s FieldSpan) Key(buf []byte) []byte {
+	return buf[s.Start+1 : scanJSONString(buf, s.Start)-1]
+}
+
+// Value returns the field's raw JSON value, with quotes for strings.
+func (s FieldSpan) Value(buf []byte) []byte {
+	return buf[skipWhitespace(buf, s.Colon+1):s.End]
+}
+
+// SetFieldTracking makes the logger record the offset of every
+// top-level field as it is encoded, so that [Event.FieldCount] and
+// [Event.FieldSpans] cost O(fields) instead of a scan of the buffer. It
+// adds a few nanoseconds per field; enable it for loggers whose handlers
+// or hooks use the spans on every event, for example to truncate records
+// to their first N fields, move standard fields to the front, or drop
+// keys. Like SetKeyReplacer, it is intended for setup-time configuration
+// and is inherited by child loggers.
+func (l *Logger) SetFieldTracking(on bool) *Logger {
+	l.trackFields = on
+	l.ctxFields = nil
+	if on {
+		l.ctxFields = appendFieldOffsets(nil, l.context, 0)
+	}
+	return l
+}
+
+// appendKey is [Logger.appendKey] for a field of e, recording the
+// field's offset when tracking is on. The caller has appended the comma
+// and the key's opening quote.
+func (e *Event) appendKey(key string) []byte {
+	if e.l.trackFields {
+		e.fields = append(e.fields, int32(len(e.buf)-1)) // #nosec G115 - bounded by MaxBufferSize
+	}
+	return e.l.appendKey(e.buf, key)
+}
+
+// FieldCount returns the number of top-level fields encoded so far,
+// including "level" and, once Msg has run, "message".
+func (e *Event) FieldCount() int {
+	if len(e.fields) > 0 {
+		return len(e.fields)
+	}
+	var spans [32]FieldSpan
+	return len(appendFieldSpans(spans[:0], e.buf, 1))
+}
+
+// FieldSpans appends the position of each top-level field, in encoding
+// order, to dst and returns it. It does not allocate when dst has room.
+//
+// The spans index [Event.Buffer] and are valid until the event is
+// modified. A handler that keeps only the first n fields:
+//
+//	spans := e.FieldSpans(h.spans[:0])
+//	buf := e.Buffer()
+//	out := append(h.out[:0], '{')
+//	for i, s := range spans[:min(n, len(spans))] {
+//	    if i > 0 {
+//	        out = append(out, ',')
+//	    }
+//	    out = append(out, buf[s.Start:s.End]...)
+//	}
+//	out = append(out, "}\n"...)
+//
+// Offsets come from [Logger.SetFieldTracking] when enabled and from a
+// scan of the buffer otherwise, for example for events created by
+// wrapper handlers.
+func (e *Event) FieldSpans(dst []FieldSpan) []FieldSpan {
+	if len(e.fields) == 0 {
+		return appendFieldSpans(dst, e.buf, 1)
+	}
+	for i, start := range e.fields {
+		s := FieldSpan{Start: int(start)}
+		s.Colon = scanJSONString(e.buf, s.Start)
+		if i+1 < len(e.fields) {
+			s.End = int(e.fields[i+1]) - 1 // the comma before the next key
+		} else {
+			s.End = scanJSONValue(e.buf, s.Colon+1)
+		}
+		dst = append(dst, s)
+	}
+	return dst
+}
+
+// appendFieldOffsets appends to dst the offset, plus base, of each key
+// in buf, a comma-separated run of fields such as a logger's context.
+func appendFieldOffsets(dst []int32, buf []byte, base int) []int32 {
+	var spans [16]FieldSpan
+	for _, s := range appendFieldSpans(spans[:0], buf, 0) {
+		dst = append(dst, int32(base+s.Start)) // #nosec G115 - bounded by MaxBufferSize
+	}
+	return dst
+}
+
+// shiftOffsets appends each of offs plus base to dst.
+func shiftOffsets(dst, offs []int32, base int) []int32 {
+	for _, o := range offs {
+		dst = append(dst, o+int32(base)) // #nosec G115 - bounded by MaxBufferSize
+	}
+	return dst
+}
+
+// appendFieldSpans scans the fields of buf starting at i, after an
+// object's '{' or at the start of a bare run of fields, and appends
+// their spans to dst.
+func appendFieldSpans(dst []FieldSpan, buf []byte, i int) []FieldSpan {
+	if i > len(buf) || (i == 1 && buf[0] != '{') {
+		return dst
+	}
+	for i < len(buf) {
+		i = skipWhitespace(buf, i)
+		if i >= len(buf) || buf[i] == '}' {
+			break
+		}
+		if buf[i] == ',' {
+			i++
+			continue
+		}
+		if buf[i] != '"' {
+			break // not JSON
+		}
+		s := FieldSpan{Start: i}
+		i = skipWhitespace(buf, scanJSONString(buf, i))
+		if i >= len(buf) || buf[i] != ':' {
+			break
+		}
+		s.Colon = i
+		s.End = scanJSONValue(buf, skipWhitespace(buf, i+1))
+		dst = append(dst, s)
+		i = s.End
+	}
+	return dst
+}
@@ -0,0 +1,78 @@
+package bolt
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+// spanRecorder records the keys and values FieldSpans reports for every
+// event it writes.
+type spanRecorder struct {
+	fields []string
+	count  int
+}
+
+func (r *spanRecorder) Write(e *Event) error {
+	r.fields = r.fields[:0]
+	buf := e.Buffer()
+	for _, s := range e.FieldSpans(nil) {
+		if string(buf[s.Start:s.End]) != `"`+string(s.Key(buf))+`":`+string(s.Value(buf)) {
+			return nil // leaves fields empty so the test fails
+		}
+		r.fields = append(r.fields, string(s.Key(buf))+"="+string(s.Value(buf)))
+	}
+	r.count = e.FieldCount()
+	return nil
+}
+
+func TestFieldSpans(t *testing.T) {
+	want := `level="info",service="api",req="r-1",user={"id":7,"tags":["a","b"]},n=3,message="done"`
+	for _, track := range []bool{false, true} {
+		rec := &spanRecorder{}
+		logger := New(rec).With().Str("service", "api").Logger()
+		logger.SetFieldTracking(track)
+		logger.Context().Str("req", "r-1").Logger().Info().
+			Dict("user", func(d *Event) { d.Int("id", 7).Strs("tags", []string{"a", "b"}) }).
+			Int("n", 3).
+			Msg("done")
+
+		if got := strings.Join(rec.fields, ","); got != want {
+			t.Errorf("tracking=%v: fields\n got %s\nwant %s", track, got, want)
+		}
+		if rec.count != 6 {
+			t.Errorf("tracking=%v: FieldCount() = %d, want 6", track, rec.count)
+		}
+	}
+}
+
+func TestFieldSpansInHook(t *testing.T) {
+	var seen []string
+	logger := New(NewJSONHandler(io.Discard)).SetFieldTracking(true)
+	logger.AddEventHook(EventHookFunc(func(e *Event, _ string) bool {
+		buf := e.Buffer()
+		for _, s := range e.FieldSpans(nil) {
+			seen = append(seen, string(s.Key(buf))+"="+string(s.Value(buf)))
+		}
+		return true
+	}))
+	logger.Warn().Dict("d", func(d *Event) { d.Str("x", "y") }).Msg("m")
+	if got := strings.Join(seen, ","); got != `level="warn",d={"x":"y"}` {
+		t.Errorf("fields before Msg = %s", got)
+	}
+}
+
+func TestFieldTrackingAllocs(t *testing.T) {
+	if raceDetectorEnabled {
+		t.Skip("allocation counts are not meaningful under -race")
+	}
+	logger := New(NewJSONHandler(io.Discard)).SetFieldTracking(true).
+		With().Str("service", "api").Logger()
+	logger.Info().Str("a", "b").Int("n", 1).Msg("warm up")
+	allocs := testing.AllocsPerRun(100, func() {
+		logger.Info().Str("a", "b").Int("n", 1).Msg("hello")
+	})
+	if allocs != 0 {
+		t.Errorf("tracked event allocated %v times, want 0", allocs)
+	}
+}
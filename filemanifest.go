@@ -0,0 +1,116 @@
+package bolt
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"time"
+)
+
+// ManifestSuffix is appended to a log file's name to form the name of its
+// manifest. See [SharedFileOptions.Manifest] and
+// [RotatingFileOptions.Manifest].
+const ManifestSuffix = ".manifest.json"
+
+// FileManifest describes the complete contents of a log file at the time
+// bolt stopped writing to it, so shipping pipelines can verify that what
+// arrived is what was written.
+type FileManifest struct {
+	File   string    `json:"file"`   // base name of the log file
+	Lines  uint64    `json:"lines"`  // number of newline characters
+	Bytes  uint64    `json:"bytes"`  // file size
+	SHA256 string    `json:"sha256"` // hex-encoded digest of the contents
+	Closed time.Time `json:"closed"`
+}
+
+// fileDigest accumulates a FileManifest as records are written.
+type fileDigest struct {
+	h     hash.Hash
+	lines uint64
+	bytes uint64
+}
+
+// newFileDigest starts a digest for the file at path, covering whatever
+// it already contains.
+func newFileDigest(path string) (*fileDigest, error) {
+	d := &fileDigest{h: sha256.New()}
+	f, err := os.Open(path) // #nosec G304 -- caller-supplied log path
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if _, err := io.Copy(d, f); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *fileDigest) Write(p []byte) (int, error) {
+	d.h.Write(p)
+	d.lines += uint64(bytes.Count(p, []byte{'\n'})) // #nosec G115 -- count is non-negative
+	d.bytes += uint64(len(p))
+	return len(p), nil
+}
+
+func (d *fileDigest) manifest(file string, closed time.Time) FileManifest {
+	return FileManifest{
+		File:   file,
+		Lines:  d.lines,
+		Bytes:  d.bytes,
+		SHA256: hex.EncodeToString(d.h.Sum(nil)),
+		Closed: closed.UTC(),
+	}
+}
+
+// writeManifest writes m next to the log file at name, via a temporary
+// file so readers never see a partial manifest.
+func writeManifest(name string, m FileManifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := name + ManifestSuffix + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, name+ManifestSuffix)
+}
+
+// ReadManifest reads the manifest written for the log file at path.
+func ReadManifest(path string) (FileManifest, error) {
+	var m FileManifest
+	data, err := os.ReadFile(path + ManifestSuffix) // #nosec G304 -- caller-supplied log path
+	if err != nil {
+		return m, err
+	}
+	err = json.Unmarshal(data, &m)
+	return m, err
+}
+
+// ErrManifestMismatch is returned by [VerifyManifest] when a file does
+// not match its manifest, typically because it was truncated in transit.
+var ErrManifestMismatch = errors.New("bolt: log file does not match its manifest")
+
+// VerifyManifest checks the log file at path against its manifest.
+func VerifyManifest(path string) error {
+	m, err := ReadManifest(path)
+	if err != nil {
+		return err
+	}
+	d, err := newFileDigest(path)
+	if err != nil {
+		return err
+	}
+	got := d.manifest(m.File, m.Closed)
+	if got.Bytes != m.Bytes || got.Lines != m.Lines || got.SHA256 != m.SHA256 {
+		return fmt.Errorf("%w: %s has %d bytes, %d lines, sha256 %s; manifest says %d bytes, %d lines, sha256 %s",
+			ErrManifestMismatch, path, got.Bytes, got.Lines, got.SHA256, m.Bytes, m.Lines, m.SHA256)
+	}
+	return nil
+}
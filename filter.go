@@ -0,0 +1,195 @@
+package bolt
+
+import (
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultFilterSummaryInterval is the interval between the per-rule
+// summaries of a [FilterHook] when none is configured.
+const DefaultFilterSummaryInterval = time.Hour
+
+// FilterRule names a class of events to drop, such as health-check
+// requests. Match reports whether the event, with its message, is one of
+// them.
+type FilterRule struct {
+	Name  string
+	Match func(e *Event, msg string) bool
+}
+
+// MatchField returns a [FilterRule] matcher for events with a top-level
+// field key whose value, as encoded (string contents without the quotes,
+// raw JSON text for other types), equals value.
+func MatchField(key, value string) func(e *Event, msg string) bool {
+	return func(e *Event, _ string) bool {
+		found := false
+		e.WalkFields(func(k, v []byte) bool {
+			if string(k) != key {
+				return true
+			}
+			found = string(v) == value
+			return false
+		})
+		return found
+	}
+}
+
+// MatchMessage returns a [FilterRule] matcher for events with the message
+// msg.
+func MatchMessage(msg string) func(e *Event, msg string) bool {
+	return func(_ *Event, m string) bool { return m == msg }
+}
+
+// FilterOptions configures [NewFilterHook]. A nil *FilterOptions uses the
+// defaults.
+type FilterOptions struct {
+	// Summary receives an INFO "filter summary" event per rule every
+	// SummaryInterval, so the log itself shows what was filtered. Nil
+	// disables summaries.
+	Summary *Logger
+
+	// SummaryInterval defaults to DefaultFilterSummaryInterval.
+	SummaryInterval time.Duration
+}
+
+// FilterRuleStats are the counters of one rule of a [FilterHook].
+type FilterRuleStats struct {
+	Rule        string    `json:"rule"`
+	Dropped     uint64    `json:"dropped"`
+	LastDropped time.Time `json:"last_dropped,omitzero"`
+	LastMessage string    `json:"last_message,omitempty"` // message of the last dropped event
+}
+
+// FilterStats are the counters of a [FilterHook].
+type FilterStats struct {
+	Seen   uint64            `json:"seen"`   // events the hook inspected
+	Passed uint64            `json:"passed"` // events no rule matched
+	Rules  []FilterRuleStats `json:"rules"`
+}
+
+// FilterHook is an [EventHook] that drops events matching any of its
+// rules and counts, per rule, what it dropped, so that filters can be
+// shown not to swallow real traffic:
+//
+//	filter := bolt.NewFilterHook([]bolt.FilterRule{
+//	    {Name: "healthz", Match: bolt.MatchField("path", "/healthz")},
+//	}, &bolt.FilterOptions{Summary: logger})
+//	defer filter.Close()
+//	logger.AddEventHook(filter)
+//	expvar.Publish("bolt_filter", filter)
+//
+// Rules are tried in order and an event is counted against the first one
+// that matches. [Event.Elevated] events are never dropped. The counters
+// are available from [FilterHook.Stats], and FilterHook is an
+// [expvar.Var] serving them as JSON. With a summary logger, every rule
+// that dropped events in the interval is reported as
+//
+//	{"level":"info","rule":"healthz","dropped":3541,"total":98112,"interval":3600000000000,"last_message":"request","message":"filter summary"}
+//
+// A summary logger that carries the hook itself is fine as long as no
+// rule matches the summary events.
+type FilterHook struct {
+	rules  []filterRule
+	seen   atomic.Uint64
+	passed atomic.Uint64
+	stop   func()
+}
+
+// filterRule is a FilterRule with its counters.
+type filterRule struct {
+	FilterRule
+	dropped  atomic.Uint64
+	reported uint64 // dropped count at the last summary; summary goroutine only
+
+	mu          sync.Mutex
+	lastDropped time.Time
+	lastMessage string
+}
+
+// NewFilterHook returns a FilterHook applying rules. Rules without a
+// Match function never match. Call Close to stop the summaries.
+func NewFilterHook(rules []FilterRule, opts *FilterOptions) *FilterHook {
+	if opts == nil {
+		opts = &FilterOptions{}
+	}
+	f := &FilterHook{rules: make([]filterRule, len(rules)), stop: func() {}}
+	for i, r := range rules {
+		f.rules[i].FilterRule = r
+	}
+	if opts.Summary != nil {
+		interval := opts.SummaryInterval
+		if interval <= 0 {
+			interval = DefaultFilterSummaryInterval
+		}
+		f.stop = startTicker(interval, func() { f.summarize(opts.Summary, interval) })
+	}
+	return f
+}
+
+// Run implements [EventHook].
+func (f *FilterHook) Run(e *Event, msg string) bool {
+	f.seen.Add(1)
+	if !e.Elevated() {
+		for i := range f.rules {
+			r := &f.rules[i]
+			if r.Match == nil || !r.Match(e, msg) {
+				continue
+			}
+			r.dropped.Add(1)
+			r.mu.Lock()
+			r.lastDropped = time.Now()
+			r.lastMessage = msg
+			r.mu.Unlock()
+			return false
+		}
+	}
+	f.passed.Add(1)
+	return true
+}
+
+// Stats returns the hook's counters since it was created.
+func (f *FilterHook) Stats() FilterStats {
+	s := FilterStats{Seen: f.seen.Load(), Passed: f.passed.Load(), Rules: make([]FilterRuleStats, len(f.rules))}
+	for i := range f.rules {
+		r := &f.rules[i]
+		r.mu.Lock()
+		s.Rules[i] = FilterRuleStats{Rule: r.Name, Dropped: r.dropped.Load(), LastDropped: r.lastDropped, LastMessage: r.lastMessage}
+		r.mu.Unlock()
+	}
+	return s
+}
+
+// String returns [FilterHook.Stats] as JSON, making the hook an
+// [expvar.Var].
+func (f *FilterHook) String() string {
+	b, _ := json.Marshal(f.Stats()) // cannot fail for these types
+	return string(b)
+}
+
+// Close stops the summaries. It is safe to call more than once.
+func (f *FilterHook) Close() error {
+	f.stop()
+	return nil
+}
+
+// summarize logs one event per rule that dropped events since the last
+// summary.
+func (f *FilterHook) summarize(logger *Logger, interval time.Duration) {
+	for i, s := range f.Stats().Rules {
+		r := &f.rules[i]
+		n := s.Dropped - r.reported
+		if n == 0 {
+			continue
+		}
+		r.reported = s.Dropped
+		logger.Info().
+			Str("rule", s.Rule).
+			Uint64("dropped", n).
+			Uint64("total", s.Dropped).
+			Dur("interval", interval).
+			Str("last_message", s.LastMessage).
+			Msg("filter summary")
+	}
+}
@@ -0,0 +1,86 @@
+package bolt
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFilterHookCountsPerRule(t *testing.T) {
+	var buf bytes.Buffer
+	filter := NewFilterHook([]FilterRule{
+		{Name: "healthz", Match: MatchField("path", "/healthz")},
+		{Name: "ping", Match: MatchMessage("ping")},
+		{Name: "nil"},
+	}, nil)
+	defer filter.Close()
+	logger := New(NewJSONHandler(&buf)).AddEventHook(filter)
+
+	logger.Info().Str("path", "/healthz").Msg("request")
+	logger.Info().Str("path", "/healthz").Msg("ping") // first matching rule wins
+	logger.Info().Str("path", "/api/orders").Msg("request")
+	logger.Info().Msg("ping")
+
+	if got := strings.Count(buf.String(), "\n"); got != 1 || !strings.Contains(buf.String(), "/api/orders") {
+		t.Fatalf("output = %q, want only the /api/orders event", buf.String())
+	}
+	s := filter.Stats()
+	if s.Seen != 4 || s.Passed != 1 {
+		t.Errorf("Seen, Passed = %d, %d, want 4, 1", s.Seen, s.Passed)
+	}
+	if r := s.Rules[0]; r.Rule != "healthz" || r.Dropped != 2 || r.LastMessage != "ping" || r.LastDropped.IsZero() {
+		t.Errorf("healthz = %+v", r)
+	}
+	if r := s.Rules[1]; r.Dropped != 1 {
+		t.Errorf("ping = %+v", r)
+	}
+	if r := s.Rules[2]; r.Dropped != 0 {
+		t.Errorf("rule without Match = %+v", r)
+	}
+
+	var decoded FilterStats
+	if err := json.Unmarshal([]byte(filter.String()), &decoded); err != nil || decoded.Rules[0].Dropped != 2 {
+		t.Errorf("String() = %s, %v", filter.String(), err)
+	}
+}
+
+func TestFilterHookKeepsElevated(t *testing.T) {
+	var buf bytes.Buffer
+	filter := NewFilterHook([]FilterRule{{Name: "all", Match: func(*Event, string) bool { return true }}}, nil)
+	logger := New(NewJSONHandler(&buf)).SetLevel(INFO).AddEventHook(filter)
+	logger.Ctx(withDebugBaggage(t, "1")).Debug().Msg("traced")
+	if !strings.Contains(buf.String(), "traced") {
+		t.Errorf("elevated event dropped: %q", buf.String())
+	}
+}
+
+func TestFilterHookSummary(t *testing.T) {
+	var buf bytes.Buffer
+	summary := New(NewJSONHandler(&buf))
+	filter := NewFilterHook([]FilterRule{
+		{Name: "healthz", Match: MatchField("path", "/healthz")},
+		{Name: "idle", Match: MatchMessage("never")},
+	}, &FilterOptions{Summary: summary, SummaryInterval: time.Hour})
+	defer filter.Close()
+	logger := New(NewJSONHandler(&bytes.Buffer{})).AddEventHook(filter)
+
+	for range 3 {
+		logger.Info().Str("path", "/healthz").Msg("request")
+	}
+	filter.summarize(summary, time.Hour)
+	filter.summarize(summary, time.Hour) // nothing new: no event
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("summaries = %q, want one for healthz", lines)
+	}
+	var got map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["rule"] != "healthz" || got["dropped"] != 3.0 || got["total"] != 3.0 || got["message"] != "filter summary" {
+		t.Errorf("summary = %s", lines[0])
+	}
+}
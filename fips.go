@@ -0,0 +1,68 @@
+package bolt
+
+import (
+	"crypto"
+	"crypto/fips140"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrNotFIPSApproved is returned by [CheckHash] for algorithms that may
+// not be used in FIPS mode.
+var ErrNotFIPSApproved = errors.New("bolt: hash algorithm is not FIPS-approved")
+
+var fipsMode atomic.Bool
+
+// SetFIPSMode restricts bolt to FIPS-approved algorithms. Call it during
+// start-up, before loggers and hooks are created. In FIPS mode:
+//
+//   - [HashSampler] and [ErrorRateHook] hash with SHA-256, truncated to
+//     64 bits, instead of FNV-1a. Sampling decisions therefore differ
+//     from services running without FIPS mode.
+//   - [CheckHash], and every bolt API that lets callers choose a hash
+//     (such as httplog's request signer), reject MD5, SHA-1 and other
+//     non-approved algorithms.
+//
+// The SHA-256 digests and HMACs bolt computes elsewhere (file manifests,
+// TLS pins, request signatures, [MaskHash] redaction) are approved and
+// unaffected.
+func SetFIPSMode(on bool) {
+	fipsMode.Store(on)
+}
+
+// FIPSMode reports whether FIPS mode is on, either through [SetFIPSMode]
+// or because the Go runtime runs its FIPS 140-3 module
+// (GODEBUG=fips140=on).
+func FIPSMode() bool {
+	return fipsMode.Load() || fips140.Enabled()
+}
+
+// CheckHash reports whether h may be used: it must be linked into the
+// binary, and in FIPS mode it must be a SHA-2 or SHA-3 function.
+func CheckHash(h crypto.Hash) error {
+	if !h.Available() {
+		return fmt.Errorf("bolt: hash algorithm %v is not linked into the binary", h)
+	}
+	if !FIPSMode() {
+		return nil
+	}
+	switch h {
+	case crypto.SHA224, crypto.SHA256, crypto.SHA384, crypto.SHA512, crypto.SHA512_224, crypto.SHA512_256,
+		crypto.SHA3_224, crypto.SHA3_256, crypto.SHA3_384, crypto.SHA3_512:
+		return nil
+	}
+	return fmt.Errorf("%w: %v", ErrNotFIPSApproved, h)
+}
+
+// hash64 is the 64-bit hash used for sampling and fingerprinting: FNV-1a,
+// or the first eight bytes of SHA-256 (big-endian) in FIPS mode.
+func hash64(b []byte) uint64 {
+	if FIPSMode() {
+		sum := sha256.Sum256(b)
+		return binary.BigEndian.Uint64(sum[:8])
+	}
+	return fnv1a64(b)
+}
@@ -0,0 +1,50 @@
+package bolt
+
+import (
+	"crypto"
+	_ "crypto/md5" // linked so CheckHash reaches the FIPS check
+	_ "crypto/sha256"
+	"errors"
+	"testing"
+)
+
+func withFIPSMode(t *testing.T) {
+	t.Helper()
+	SetFIPSMode(true)
+	t.Cleanup(func() { SetFIPSMode(false) })
+}
+
+func TestCheckHash(t *testing.T) {
+	if err := CheckHash(crypto.MD5); err != nil {
+		t.Errorf("MD5 rejected outside FIPS mode: %v", err)
+	}
+	withFIPSMode(t)
+	if !FIPSMode() {
+		t.Fatal("FIPSMode() = false after SetFIPSMode(true)")
+	}
+	if err := CheckHash(crypto.MD5); !errors.Is(err, ErrNotFIPSApproved) {
+		t.Errorf("CheckHash(MD5) = %v; want ErrNotFIPSApproved", err)
+	}
+	if err := CheckHash(crypto.SHA256); err != nil {
+		t.Errorf("CheckHash(SHA256) = %v", err)
+	}
+	if err := CheckHash(crypto.Hash(0)); err == nil {
+		t.Error("CheckHash accepted an unknown hash")
+	}
+}
+
+func TestHash64FIPSMode(t *testing.T) {
+	v := []byte("user-42")
+	plain := hash64(v)
+	if plain != fnv1a64(v) {
+		t.Error("hash64 is not FNV-1a outside FIPS mode")
+	}
+	withFIPSMode(t)
+	fips := hash64(v)
+	if fips == plain || fips != hash64(v) {
+		t.Errorf("FIPS hash64 = %x (plain %x); want a different, stable value", fips, plain)
+	}
+	if fingerprint("timeout 30s", nil) != fingerprint("timeout 9s", nil) {
+		t.Error("FIPS fingerprints no longer ignore numbers")
+	}
+}
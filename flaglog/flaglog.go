@@ -0,0 +1,187 @@
+// Package flaglog logs feature flag evaluation decisions in the
+// OpenTelemetry feature_flag.* schema, so rollouts can be audited from
+// logs:
+//
+//	{"level":"info","feature_flag.key":"new-checkout",
+//	 "feature_flag.result.variant":"on","feature_flag.result.reason":"TARGETING_MATCH",
+//	 "feature_flag.provider.name":"flagd","feature_flag.context.id":"3f1c...",
+//	 "message":"feature flag evaluated"}
+//
+// Targeting keys usually identify a user, so they are never logged in
+// the clear: "feature_flag.context.id" holds their SHA-256 digest, or an
+// HMAC-SHA-256 when [Options.HashKey] is set, which stops a reader from
+// confirming a guessed user ID. Decisions are sampled by that digest, so
+// every evaluation for one targeting key is either kept or dropped
+// together. Failed evaluations are always logged, at WARN.
+//
+// The package does not import the OpenFeature SDK. Register a small hook
+// that forwards to a [Recorder]:
+//
+//	type auditHook struct {
+//	    openfeature.UnimplementedHook
+//	    rec *flaglog.Recorder
+//	}
+//
+//	func (h auditHook) After(ctx context.Context, hc openfeature.HookContext,
+//	    d openfeature.InterfaceEvaluationDetails, _ openfeature.HookHints) error {
+//	    h.rec.Record(ctx, flaglog.Decision{
+//	        Flag:         d.FlagKey,
+//	        Variant:      d.Variant,
+//	        Reason:       string(d.Reason),
+//	        Provider:     hc.ProviderMetadata().Name,
+//	        TargetingKey: hc.EvaluationContext().TargetingKey(),
+//	    })
+//	    return nil
+//	}
+//
+//	func (h auditHook) Error(ctx context.Context, hc openfeature.HookContext,
+//	    err error, _ openfeature.HookHints) {
+//	    h.rec.Record(ctx, flaglog.Decision{
+//	        Flag:         hc.FlagKey(),
+//	        Provider:     hc.ProviderMetadata().Name,
+//	        TargetingKey: hc.EvaluationContext().TargetingKey(),
+//	        Err:          err,
+//	    })
+//	}
+//
+//	openfeature.AddHooks(auditHook{rec: flaglog.New(logger, &flaglog.Options{SampleRate: 0.1})})
+package flaglog
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"math/rand/v2"
+
+	"go.klarlabs.de/bolt"
+)
+
+// Message is the message of every decision event.
+const Message = "feature flag evaluated"
+
+// Field names, from the OpenTelemetry semantic conventions for feature
+// flags.
+const (
+	KeyField      = "feature_flag.key"
+	VariantField  = "feature_flag.result.variant"
+	ReasonField   = "feature_flag.result.reason"
+	ValueField    = "feature_flag.result.value"
+	ProviderField = "feature_flag.provider.name"
+	ContextField  = "feature_flag.context.id"
+	ErrorField    = "error.type"
+)
+
+// Decision is one flag evaluation.
+type Decision struct {
+	Flag         string
+	Variant      string
+	Reason       string // "STATIC", "TARGETING_MATCH", "DEFAULT", ...
+	Provider     string
+	TargetingKey string // hashed before logging
+	Value        any    // logged only with Options.LogValue
+	Err          error  // set for failed evaluations
+}
+
+// Options configures [New]. A nil *Options uses the defaults.
+type Options struct {
+	// SampleRate is the fraction of successful decisions logged, chosen
+	// per targeting key. Values outside (0, 1) mean 1: log everything.
+	SampleRate float64
+
+	// HashKey, if set, hashes targeting keys with HMAC-SHA-256 instead
+	// of plain SHA-256.
+	HashKey []byte
+
+	// LogValue logs the evaluated value under "feature_flag.result.value".
+	// Off by default because flag values can carry configuration secrets.
+	LogValue bool
+}
+
+// Recorder logs decisions to a bolt logger. It is safe for concurrent
+// use.
+type Recorder struct {
+	l         *bolt.Logger
+	hashKey   []byte
+	threshold uint64
+	all       bool
+	logValue  bool
+}
+
+// New returns a Recorder writing to logger.
+func New(logger *bolt.Logger, opts *Options) *Recorder {
+	r := &Recorder{l: logger, all: true}
+	if opts == nil {
+		return r
+	}
+	r.hashKey = opts.HashKey
+	r.logValue = opts.LogValue
+	if rate := opts.SampleRate; rate > 0 && rate < 1 {
+		r.all = false
+		r.threshold = uint64(rate * (1 << 64))
+	}
+	return r
+}
+
+// Record logs d, unless it is a successful evaluation that sampling
+// drops. Trace and span IDs are taken from ctx as by [bolt.Logger.Ctx].
+func (r *Recorder) Record(ctx context.Context, d Decision) {
+	var digest []byte
+	if d.TargetingKey != "" {
+		digest = r.hash(d.TargetingKey)
+	}
+	if d.Err == nil && !r.keep(digest) {
+		return
+	}
+
+	logger := r.l.Ctx(ctx)
+	var e *bolt.Event
+	if d.Err != nil {
+		e = logger.Warn().Str(ErrorField, d.Err.Error())
+	} else {
+		e = logger.Info()
+	}
+	e = e.Str(KeyField, d.Flag)
+	if d.Variant != "" {
+		e = e.Str(VariantField, d.Variant)
+	}
+	if d.Reason != "" {
+		e = e.Str(ReasonField, d.Reason)
+	}
+	if r.logValue && d.Value != nil {
+		e = e.Any(ValueField, d.Value)
+	}
+	if d.Provider != "" {
+		e = e.Str(ProviderField, d.Provider)
+	}
+	if digest != nil {
+		e = e.Str(ContextField, hex.EncodeToString(digest))
+	}
+	e.Msg(Message)
+}
+
+func (r *Recorder) hash(key string) []byte {
+	var h hash.Hash
+	if r.hashKey != nil {
+		h = hmac.New(sha256.New, r.hashKey)
+	} else {
+		h = sha256.New()
+	}
+	h.Write([]byte(key))
+	return h.Sum(nil)
+}
+
+// keep reports whether a successful decision is logged: when the first
+// eight bytes of the targeting key digest fall under the sample rate, or
+// by chance for decisions without a targeting key.
+func (r *Recorder) keep(digest []byte) bool {
+	if r.all {
+		return true
+	}
+	if digest == nil {
+		return rand.Uint64() < r.threshold // #nosec G404 -- sampling, not security
+	}
+	return binary.BigEndian.Uint64(digest) < r.threshold
+}
@@ -0,0 +1,89 @@
+package flaglog_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"go.klarlabs.de/bolt"
+	"go.klarlabs.de/bolt/flaglog"
+)
+
+func TestRecord(t *testing.T) {
+	var buf bytes.Buffer
+	rec := flaglog.New(bolt.New(bolt.NewJSONHandler(&buf)), &flaglog.Options{HashKey: []byte("k")})
+
+	rec.Record(context.Background(), flaglog.Decision{
+		Flag:         "new-checkout",
+		Variant:      "on",
+		Reason:       "TARGETING_MATCH",
+		Provider:     "flagd",
+		TargetingKey: "user-42",
+		Value:        true,
+	})
+
+	var m map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	mac := hmac.New(sha256.New, []byte("k"))
+	mac.Write([]byte("user-42"))
+	want := map[string]any{
+		"level":               "info",
+		"message":             flaglog.Message,
+		flaglog.KeyField:      "new-checkout",
+		flaglog.VariantField:  "on",
+		flaglog.ReasonField:   "TARGETING_MATCH",
+		flaglog.ProviderField: "flagd",
+		flaglog.ContextField:  hex.EncodeToString(mac.Sum(nil)),
+	}
+	for k, v := range want {
+		if m[k] != v {
+			t.Errorf("%s = %v, want %v", k, m[k], v)
+		}
+	}
+	if _, ok := m[flaglog.ValueField]; ok {
+		t.Error("value logged without LogValue")
+	}
+	if strings.Contains(buf.String(), "user-42") {
+		t.Error("targeting key logged in the clear")
+	}
+}
+
+func TestRecordSampling(t *testing.T) {
+	var buf bytes.Buffer
+	rec := flaglog.New(bolt.New(bolt.NewJSONHandler(&buf)), &flaglog.Options{SampleRate: 0.25})
+
+	kept := map[string]int{}
+	for i := range 400 {
+		for range 3 {
+			key := fmt.Sprintf("user-%d", i)
+			buf.Reset()
+			rec.Record(context.Background(), flaglog.Decision{Flag: "f", TargetingKey: key})
+			if buf.Len() > 0 {
+				kept[key]++
+			}
+		}
+	}
+	for key, n := range kept {
+		if n != 3 {
+			t.Errorf("%s kept %d of 3 evaluations; want all or none", key, n)
+		}
+	}
+	if n := len(kept); n < 60 || n > 140 {
+		t.Errorf("kept %d of 400 targeting keys at rate 0.25", n)
+	}
+
+	buf.Reset()
+	rec.Record(context.Background(), flaglog.Decision{Flag: "f", TargetingKey: "any", Err: errors.New("FLAG_NOT_FOUND")})
+	if !strings.Contains(buf.String(), `"level":"warn"`) || !strings.Contains(buf.String(), `"error.type":"FLAG_NOT_FOUND"`) {
+		t.Errorf("failed evaluation not logged: %s", buf.String())
+	}
+}
@@ -0,0 +1,159 @@
+package bolt
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Flag names registered by [Flags] and [BindFlags].
+const (
+	FlagLogLevel  = "log-level"
+	FlagLogFormat = "log-format"
+	FlagLogOutput = "log-output"
+)
+
+// LogFlags holds the standard logging command-line flags. Defaults come
+// from the BOLT_LEVEL, BOLT_FORMAT and BOLT_OUTPUT environment variables,
+// so the same binary can be configured either way; flags win.
+type LogFlags struct {
+	level  levelFlag
+	format formatFlag
+	output outputFlag
+}
+
+// NewLogFlags returns LogFlags initialized from the environment without
+// registering them anywhere. Most programs want [Flags] instead.
+func NewLogFlags() *LogFlags {
+	f := &LogFlags{
+		level:  levelFlag(INFO),
+		format: "auto",
+		output: "stdout",
+	}
+	if v := os.Getenv("BOLT_LEVEL"); v != "" {
+		f.level = levelFlag(ParseLevel(v))
+	}
+	if v := os.Getenv("BOLT_FORMAT"); v != "" && f.format.Set(v) != nil {
+		f.format = "auto"
+	}
+	if v := os.Getenv("BOLT_OUTPUT"); v != "" {
+		f.output = outputFlag(v)
+	}
+	return f
+}
+
+// Flags registers -log-level, -log-format and -log-output on fs and
+// returns the LogFlags to build the logger from once fs has been parsed:
+//
+//	lf := bolt.Flags(flag.CommandLine)
+//	flag.Parse()
+//	logger, closeLog, err := lf.New()
+//	if err != nil {
+//	    fmt.Fprintln(os.Stderr, err)
+//	    os.Exit(2)
+//	}
+//	defer closeLog()
+//
+// Invalid values are rejected by fs.Parse.
+func Flags(fs *flag.FlagSet) *LogFlags {
+	f := NewLogFlags()
+	BindFlags(f, fs.Var)
+	return f
+}
+
+// BindFlags registers f's flags through varFn, the Var method of a flag
+// set. It accepts any flag package whose values are String/Set/Type, so
+// the same flags work with spf13/pflag and cobra without bolt depending
+// on them:
+//
+//	lf := bolt.NewLogFlags()
+//	bolt.BindFlags(lf, rootCmd.PersistentFlags().Var)
+func BindFlags[V flag.Value](f *LogFlags, varFn func(value V, name, usage string)) {
+	varFn(any(&f.level).(V), FlagLogLevel, "minimum log level: trace, debug, info, warn, error or fatal")
+	varFn(any(&f.format).(V), FlagLogFormat, "log format: json, console or auto (console on a terminal)")
+	varFn(any(&f.output).(V), FlagLogOutput, "log destination: stdout, stderr or a file path (appended to)")
+}
+
+// Level returns the configured minimum level.
+func (f *LogFlags) Level() Level { return Level(f.level) }
+
+// New builds a Logger from the flags. A -log-output file is opened with
+// [OpenSharedFile], so several processes may log to it at once. The
+// returned close function closes that file and is a no-op otherwise.
+func (f *LogFlags) New() (logger *Logger, closeFn func() error, err error) {
+	var out io.Writer
+	closeFn = func() error { return nil }
+	switch f.output {
+	case "stdout", "-":
+		out = os.Stdout
+	case "stderr":
+		out = os.Stderr
+	default:
+		file, err := OpenSharedFile(string(f.output), nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("bolt: open -%s: %w", FlagLogOutput, err)
+		}
+		out, closeFn = file, file.Close
+	}
+
+	format := string(f.format)
+	if format == "auto" {
+		format = "json"
+		if file, ok := out.(*os.File); ok && isTerminal(file) {
+			format = consoleStr
+		}
+	}
+	var h Handler
+	if format == consoleStr {
+		h = NewConsoleHandler(out)
+	} else {
+		h = NewJSONHandler(out)
+	}
+	return New(h).SetLevel(Level(f.level)), closeFn, nil
+}
+
+// levelFlag is a Level that parses strictly: unknown names are an error
+// rather than silently INFO as with ParseLevel.
+type levelFlag Level
+
+func (l *levelFlag) String() string { return Level(*l).String() }
+func (l *levelFlag) Type() string   { return "level" }
+
+func (l *levelFlag) Set(s string) error {
+	s = strings.ToLower(strings.TrimSpace(s))
+	lvl := ParseLevel(s)
+	if lvl == INFO && s != infoStr {
+		return fmt.Errorf("unknown log level %q", s)
+	}
+	*l = levelFlag(lvl)
+	return nil
+}
+
+type formatFlag string
+
+func (f *formatFlag) String() string { return string(*f) }
+func (f *formatFlag) Type() string   { return "format" }
+
+func (f *formatFlag) Set(s string) error {
+	switch s = strings.ToLower(strings.TrimSpace(s)); s {
+	case "json", consoleStr, "auto":
+		*f = formatFlag(s)
+		return nil
+	}
+	return fmt.Errorf("unknown log format %q (want json, console or auto)", s)
+}
+
+type outputFlag string
+
+func (o *outputFlag) String() string { return string(*o) }
+func (o *outputFlag) Type() string   { return "string" }
+
+func (o *outputFlag) Set(s string) error {
+	if s == "" {
+		return fmt.Errorf("empty log output")
+	}
+	*o = outputFlag(s)
+	return nil
+}
@@ -0,0 +1,89 @@
+package bolt
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFlagsBuildLogger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	lf := Flags(fs)
+	if err := fs.Parse([]string{"-log-level=WARN", "-log-format", "json", "-log-output", path}); err != nil {
+		t.Fatal(err)
+	}
+	logger, closeLog, err := lf.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info().Msg("filtered")
+	logger.Warn().Msg("kept")
+	if err := closeLog(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); strings.Contains(got, "filtered") || !strings.Contains(got, `"message":"kept"`) {
+		t.Errorf("log file = %q", got)
+	}
+	if lf.Level() != WARN {
+		t.Errorf("Level() = %v", lf.Level())
+	}
+}
+
+func TestFlagsRejectInvalidValues(t *testing.T) {
+	for _, args := range [][]string{{"-log-level=verbose"}, {"-log-format=xml"}, {"-log-output="}} {
+		fs := flag.NewFlagSet("app", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		Flags(fs)
+		if err := fs.Parse(args); err == nil {
+			t.Errorf("%v accepted", args)
+		}
+	}
+}
+
+func TestFlagsEnvDefaults(t *testing.T) {
+	t.Setenv("BOLT_LEVEL", "debug")
+	t.Setenv("BOLT_FORMAT", "console")
+	t.Setenv("BOLT_OUTPUT", "stderr")
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	Flags(fs)
+	for name, want := range map[string]string{FlagLogLevel: "debug", FlagLogFormat: "console", FlagLogOutput: "stderr"} {
+		if got := fs.Lookup(name).DefValue; got != want {
+			t.Errorf("-%s default = %q; want %q", name, got, want)
+		}
+	}
+}
+
+// pflagValue mirrors spf13/pflag.Value, which adds Type to flag.Value.
+type pflagValue interface {
+	String() string
+	Set(string) error
+	Type() string
+}
+
+type pflagSet map[string]pflagValue
+
+func (s pflagSet) Var(v pflagValue, name, _ string) { s[name] = v }
+
+func TestBindFlagsPflagCompatible(t *testing.T) {
+	set := pflagSet{}
+	lf := NewLogFlags()
+	BindFlags(lf, set.Var)
+	if len(set) != 3 {
+		t.Fatalf("registered %d flags", len(set))
+	}
+	if err := set[FlagLogLevel].Set("error"); err != nil {
+		t.Fatal(err)
+	}
+	if lf.Level() != ERROR || set[FlagLogLevel].Type() != "level" {
+		t.Errorf("level = %v, type = %s", lf.Level(), set[FlagLogLevel].Type())
+	}
+}
@@ -0,0 +1,290 @@
+package bolt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultFlightRecorderSize is how many bytes of records a
+// [FlightRecorder] keeps when no size is configured.
+const DefaultFlightRecorderSize = 8 << 20 // 8 MiB
+
+// FlightRecorderOptions configures [NewFlightRecorder]. A nil
+// *FlightRecorderOptions uses the defaults.
+type FlightRecorderOptions struct {
+	// Size is how many bytes of records are kept; the oldest are
+	// discarded beyond it. Defaults to DefaultFlightRecorderSize.
+	Size int
+
+	// Authorize is called before [FlightRecorder.ServeHTTP] dumps
+	// anything. A non-nil error rejects the request:
+	// ErrDumpUnauthorized (or an error wrapping it) yields 401, anything
+	// else 403. The error text is not sent to the client. A nil
+	// Authorize denies every request.
+	Authorize func(r *http.Request) error
+}
+
+// ErrDumpUnauthorized may be returned by [FlightRecorderOptions.Authorize]
+// to reject a dump request with 401 instead of 403.
+var ErrDumpUnauthorized = errors.New("bolt: flight recorder dump unauthorized")
+
+// FlightFilter selects the records written by [FlightRecorder.Dump].
+// The zero FlightFilter selects every record.
+type FlightFilter struct {
+	// Since keeps records captured within this long of the dump.
+	Since time.Duration
+
+	// Level keeps records at or above this level.
+	Level Level
+
+	// Fields keeps records whose top-level field of each key has one of
+	// the listed values. String values are compared unquoted, others as
+	// their JSON text.
+	Fields map[string][]string
+
+	// Limit keeps only the most recent matching records.
+	Limit int
+}
+
+// flightRecord is one captured record.
+type flightRecord struct {
+	at    int64 // UnixNano
+	level Level
+	data  []byte
+}
+
+// FlightRecorder keeps the most recent records in memory, at every level
+// the logger emits, and passes on only those at or above a level. Set the
+// logger to DEBUG or TRACE and the recorder's level to what the sink
+// should normally receive: the detail stays in memory, and on-call
+// engineers dump it when something goes wrong, without turning on
+// permanent debug logging.
+//
+//	rec := bolt.NewFlightRecorder(bolt.NewJSONHandler(os.Stdout), bolt.INFO, &bolt.FlightRecorderOptions{
+//	    Authorize: func(r *http.Request) error {
+//	        if r.Header.Get("Authorization") != "Bearer "+token {
+//	            return bolt.ErrDumpUnauthorized
+//	        }
+//	        return nil
+//	    },
+//	})
+//	logger := bolt.New(rec).SetLevel(bolt.DEBUG)
+//	debugMux.Handle("/debug/bolt/dump", rec)
+//
+// The dump holds DEBUG and TRACE detail, so the endpoint denies every
+// request unless Authorize allows it. Records are captured after hooks
+// have run, so redaction applies to the dump as to the sink; still,
+// mount the endpoint only on an internal admin listener. Memory is
+// bounded by Size; buffers of discarded records are reused, so a steady
+// stream of similar records does not allocate.
+// FlightRecorder is safe for concurrent use.
+type FlightRecorder struct {
+	next      Handler
+	level     Level
+	max       int
+	now       func() time.Time
+	authorize func(r *http.Request) error
+
+	mu      sync.Mutex
+	records []flightRecord // oldest first, starting at head
+	head    int
+	size    int
+	spare   []byte
+	spans   []FieldSpan
+}
+
+// NewFlightRecorder returns a FlightRecorder passing records at or above
+// level to next. A nil next only records.
+func NewFlightRecorder(next Handler, level Level, opts *FlightRecorderOptions) *FlightRecorder {
+	if opts == nil {
+		opts = &FlightRecorderOptions{}
+	}
+	r := &FlightRecorder{next: next, level: level, max: opts.Size, now: time.Now, authorize: opts.Authorize}
+	if r.max <= 0 {
+		r.max = DefaultFlightRecorderSize
+	}
+	return r
+}
+
+// Write implements [Handler].
+func (r *FlightRecorder) Write(e *Event) error {
+	r.mu.Lock()
+	r.record(e.level, e.buf)
+	r.mu.Unlock()
+	if r.next == nil || e.level < r.level {
+		return nil
+	}
+	return r.next.Write(e)
+}
+
+// record appends a copy of buf, discarding the oldest records beyond the
+// size limit. Called with r.mu held.
+func (r *FlightRecorder) record(level Level, buf []byte) {
+	if len(buf) > r.max {
+		return
+	}
+	r.size += len(buf)
+	for r.size > r.max {
+		old := &r.records[r.head]
+		r.size -= len(old.data)
+		if cap(old.data) > cap(r.spare) {
+			r.spare = old.data
+		}
+		*old = flightRecord{}
+		r.head++
+	}
+	if r.head > len(r.records)/2 {
+		// Compact, so the slice does not grow while records rotate.
+		n := copy(r.records, r.records[r.head:])
+		clear(r.records[n:])
+		r.records, r.head = r.records[:n], 0
+	}
+	var data []byte
+	if cap(r.spare) >= len(buf) {
+		data, r.spare = append(r.spare[:0], buf...), nil
+	} else {
+		data = bytes.Clone(buf)
+	}
+	r.records = append(r.records, flightRecord{at: r.now().UnixNano(), level: level, data: data})
+}
+
+// Dump writes the records selected by f, oldest first, as
+// newline-delimited JSON and returns how many it wrote. A nil f selects
+// every record. Records stay in the recorder.
+func (r *FlightRecorder) Dump(w io.Writer, f *FlightFilter) (int, error) {
+	if f == nil {
+		f = &FlightFilter{}
+	}
+	var out []byte
+	var ends []int // end of each selected record in out
+	r.mu.Lock()
+	var since int64
+	if f.Since > 0 {
+		since = r.now().Add(-f.Since).UnixNano()
+	}
+	for _, rec := range r.records[r.head:] {
+		if rec.at < since || rec.level < f.Level || !r.match(rec.data, f.Fields) {
+			continue
+		}
+		out = append(out, rec.data...)
+		if len(rec.data) > 0 && rec.data[len(rec.data)-1] != '\n' {
+			out = append(out, '\n')
+		}
+		ends = append(ends, len(out))
+	}
+	r.mu.Unlock()
+
+	n := len(ends)
+	if f.Limit > 0 && n > f.Limit {
+		out = out[ends[n-f.Limit-1]:]
+		n = f.Limit
+	}
+	_, err := w.Write(out)
+	return n, err
+}
+
+// match reports whether record has, for every key of fields, a
+// top-level field with one of its values. Called with r.mu held.
+func (r *FlightRecorder) match(record []byte, fields map[string][]string) bool {
+	if len(fields) == 0 {
+		return true
+	}
+	r.spans = appendFieldSpans(r.spans[:0], record, 1)
+	for key, values := range fields {
+		found := false
+		for _, s := range r.spans {
+			if string(s.Key(record)) != key {
+				continue
+			}
+			v := s.Value(record)
+			if len(v) > 1 && v[0] == '"' {
+				v = v[1 : len(v)-1]
+			}
+			for _, want := range values {
+				if string(v) == want {
+					found = true
+					break
+				}
+			}
+			break
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// ServeHTTP serves [FlightRecorder.Dump] as application/x-ndjson. Query
+// parameters select the records:
+//
+//	since   a duration, such as 5m, keeping records newer than that
+//	level   the minimum level, such as debug
+//	limit   the number of most recent records
+//
+// Any other parameter is a field filter, so that
+//
+//	GET /debug/bolt/dump?since=5m&request_id=7f3a
+//
+// returns the last five minutes of records of one request. Repeated
+// parameters match any of their values. Requests are first checked with
+// [FlightRecorderOptions.Authorize]; without it every request is denied.
+func (r *FlightRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if r.authorize == nil {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	if err := r.authorize(req); err != nil {
+		status := http.StatusForbidden
+		if errors.Is(err, ErrDumpUnauthorized) {
+			status = http.StatusUnauthorized
+		}
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	f, err := parseFlightFilter(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	_, _ = r.Dump(w, f) // nothing useful to do if the client went away
+}
+
+func parseFlightFilter(req *http.Request) (*FlightFilter, error) {
+	f := &FlightFilter{}
+	for key, values := range req.URL.Query() {
+		v := values[0]
+		switch key {
+		case "since":
+			d, err := time.ParseDuration(v)
+			if err != nil || d < 0 {
+				return nil, fmt.Errorf("invalid since %q", v)
+			}
+			f.Since = d
+		case "level":
+			if ParseLevel(v).String() != v {
+				return nil, fmt.Errorf("invalid level %q", v)
+			}
+			f.Level = ParseLevel(v)
+		case "limit":
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid limit %q", v)
+			}
+			f.Limit = n
+		default:
+			if f.Fields == nil {
+				f.Fields = make(map[string][]string)
+			}
+			f.Fields[key] = values
+		}
+	}
+	return f, nil
+}
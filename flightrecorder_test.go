@@ -0,0 +1,161 @@
+package bolt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFlightRecorderForwardsAboveLevel(t *testing.T) {
+	var sink bytes.Buffer
+	rec := NewFlightRecorder(NewJSONHandler(&sink), INFO, nil)
+	logger := New(rec).SetLevel(DEBUG)
+
+	logger.Debug().Str("request_id", "a").Msg("cache miss")
+	logger.Info().Str("request_id", "a").Msg("served")
+
+	if strings.Contains(sink.String(), "cache miss") || !strings.Contains(sink.String(), "served") {
+		t.Errorf("sink got %s", sink.String())
+	}
+	var dump bytes.Buffer
+	n, err := rec.Dump(&dump, nil)
+	if err != nil || n != 2 {
+		t.Fatalf("Dump = %d, %v", n, err)
+	}
+	lines := strings.Split(strings.TrimSpace(dump.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "cache miss") || !strings.Contains(lines[1], "served") {
+		t.Errorf("dump = %s", dump.String())
+	}
+}
+
+func TestFlightRecorderFilter(t *testing.T) {
+	rec := NewFlightRecorder(nil, INFO, nil)
+	now := time.Unix(1000, 0)
+	rec.now = func() time.Time { return now }
+	logger := New(rec).SetLevel(TRACE)
+
+	logger.Debug().Str("request_id", "old").Msg("one")
+	now = now.Add(10 * time.Minute)
+	logger.Trace().Str("request_id", "a").Msg("two")
+	logger.Debug().Str("request_id", "b").Int("status", 500).Msg("three")
+	logger.Warn().Str("request_id", "a").Msg("four")
+
+	for _, tc := range []struct {
+		name string
+		f    *FlightFilter
+		want []string
+	}{
+		{"since", &FlightFilter{Since: 5 * time.Minute}, []string{"two", "three", "four"}},
+		{"level", &FlightFilter{Level: DEBUG}, []string{"one", "three", "four"}},
+		{"field", &FlightFilter{Fields: map[string][]string{"request_id": {"a"}}}, []string{"two", "four"}},
+		{"any value", &FlightFilter{Fields: map[string][]string{"request_id": {"a", "b"}}, Since: time.Minute}, []string{"two", "three", "four"}},
+		{"number", &FlightFilter{Fields: map[string][]string{"status": {"500"}}}, []string{"three"}},
+		{"limit", &FlightFilter{Limit: 2}, []string{"three", "four"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			n, _ := rec.Dump(&buf, tc.f)
+			var got []string
+			for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+				if line == "" {
+					continue
+				}
+				_, msg, _ := strings.Cut(line, `"message":"`)
+				msg, _, _ = strings.Cut(msg, `"`)
+				got = append(got, msg)
+			}
+			if n != len(got) || strings.Join(got, ",") != strings.Join(tc.want, ",") {
+				t.Errorf("Dump = %d %v, want %v", n, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFlightRecorderBounded(t *testing.T) {
+	rec := NewFlightRecorder(nil, INFO, &FlightRecorderOptions{Size: 1000})
+	logger := New(rec).SetLevel(DEBUG)
+	for i := range 1000 {
+		logger.Debug().Int("i", i).Msg("tick")
+	}
+	rec.mu.Lock()
+	size, n := rec.size, len(rec.records)-rec.head
+	rec.mu.Unlock()
+	if size > 1000 || n == 0 || len(rec.records) > 2*n+1 {
+		t.Errorf("size = %d with %d records in a slice of %d", size, n, len(rec.records))
+	}
+	var buf bytes.Buffer
+	_, _ = rec.Dump(&buf, &FlightFilter{Limit: 1})
+	if !strings.Contains(buf.String(), `"i":999`) {
+		t.Errorf("newest record missing: %s", buf.String())
+	}
+
+	if raceDetectorEnabled {
+		t.Skip("allocation counts are not meaningful under -race")
+	}
+	allocs := testing.AllocsPerRun(100, func() { logger.Debug().Int("i", 1).Msg("tick") })
+	if allocs > 0 {
+		t.Errorf("steady-state recording allocates %.1f times per event", allocs)
+	}
+}
+
+func TestFlightRecorderServeHTTP(t *testing.T) {
+	rec := NewFlightRecorder(nil, INFO, &FlightRecorderOptions{
+		Authorize: func(*http.Request) error { return nil },
+	})
+	logger := New(rec).SetLevel(DEBUG)
+	logger.Debug().Str("request_id", "a").Msg("one")
+	logger.Debug().Str("request_id", "b").Msg("two")
+
+	w := httptest.NewRecorder()
+	rec.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/bolt/dump?since=5m&level=debug&request_id=b", nil))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/x-ndjson" {
+		t.Fatalf("status %d, content type %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	if body := w.Body.String(); strings.Count(body, "\n") != 1 || !strings.Contains(body, `"message":"two"`) {
+		t.Errorf("body = %s", body)
+	}
+
+	for _, q := range []string{"since=soon", "level=loud", "limit=-1"} {
+		w := httptest.NewRecorder()
+		rec.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/bolt/dump?"+q, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d", q, w.Code)
+		}
+	}
+}
+
+func TestFlightRecorderServeHTTPDenied(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts *FlightRecorderOptions
+		want int
+	}{
+		{"nil options", nil, http.StatusForbidden},
+		{"nil Authorize", &FlightRecorderOptions{}, http.StatusForbidden},
+		{"unauthorized", &FlightRecorderOptions{
+			Authorize: func(*http.Request) error { return fmt.Errorf("no token: %w", ErrDumpUnauthorized) },
+		}, http.StatusUnauthorized},
+		{"forbidden", &FlightRecorderOptions{
+			Authorize: func(*http.Request) error { return errors.New("wrong team") },
+		}, http.StatusForbidden},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := NewFlightRecorder(nil, INFO, tc.opts)
+			New(rec).SetLevel(DEBUG).Debug().Str("password", "hunter2").Msg("secret")
+
+			w := httptest.NewRecorder()
+			rec.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/bolt/dump", nil))
+			if w.Code != tc.want {
+				t.Errorf("status %d, want %d", w.Code, tc.want)
+			}
+			if strings.Contains(w.Body.String(), "hunter2") || strings.Contains(w.Body.String(), "wrong team") {
+				t.Errorf("denied response leaked: %s", w.Body.String())
+			}
+		})
+	}
+}
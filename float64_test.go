@@ -179,7 +179,7 @@ func BenchmarkFloat64Precision(b *testing.B) {
 		}
 	})
 
-	b.Run("Any_full_precision_allocates", func(b *testing.B) {
+	b.Run("Any_full_precision", func(b *testing.B) {
 		b.ReportAllocs()
 		for i := 0; i < b.N; i++ {
 			buf.Reset()
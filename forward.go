@@ -0,0 +1,114 @@
+package bolt
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrInvalidRecord is returned by [Logger.Forward] for input that is not
+// exactly one JSON object.
+var ErrInvalidRecord = errors.New("bolt: invalid forwarded record")
+
+// rawField is one top-level field of a forwarded record.
+type rawField struct {
+	key   string
+	value json.RawMessage
+}
+
+// Forward logs a pre-formatted JSON record produced by an upstream
+// system, one NDJSON line, through the logger as if it had been built
+// with the field methods: level filtering, hooks (redaction, sampling),
+// key replacement and handlers all apply.
+//
+// The record must be a single JSON object, optionally followed by a
+// newline; anything else, including a second record on the same line,
+// fails with [ErrInvalidRecord] and nothing is logged. The level is read
+// from the record's "level" field (case-insensitive, INFO when absent or
+// unknown) and a string "message" field becomes the event message, seen
+// by hooks and written last. The logger's context fields follow the
+// level; the remaining fields keep their order and values, compacted.
+//
+// Forward returns nil for records below the logger's level. FATAL
+// records exit like any FATAL event.
+func (l *Logger) Forward(line []byte) error {
+	level, message, hasMessage, fields, err := parseForwarded(line)
+	if err != nil {
+		return err
+	}
+	e := l.log(level, 0)
+	if e == nil {
+		return nil
+	}
+	for _, f := range fields {
+		e.buf = append(e.buf, ',', '"')
+		e.buf = e.appendKey(f.key)
+		e.buf = append(e.buf, '"', ':')
+		e.buf = append(e.buf, f.value...)
+	}
+	e.send(message, hasMessage)
+	return nil
+}
+
+// parseForwarded splits a forwarded record into its level, message and
+// remaining fields.
+func parseForwarded(line []byte) (level Level, message string, hasMessage bool, fields []rawField, err error) {
+	if len(line) > MaxBufferSize {
+		return 0, "", false, nil, fmt.Errorf("%w: exceeds %d bytes", ErrInvalidRecord, MaxBufferSize)
+	}
+	line = bytes.TrimSuffix(bytes.TrimSuffix(line, []byte("\n")), []byte("\r"))
+	if bytes.ContainsAny(line, "\r\n") {
+		return 0, "", false, nil, fmt.Errorf("%w: embedded newline", ErrInvalidRecord)
+	}
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return 0, "", false, nil, fmt.Errorf("%w: not a JSON object", ErrInvalidRecord)
+	}
+	level = INFO
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return 0, "", false, nil, fmt.Errorf("%w: %v", ErrInvalidRecord, err)
+		}
+		key := tok.(string) // object keys are always strings
+		if err := validateKey(key); err != nil {
+			return 0, "", false, nil, fmt.Errorf("%w: %v", ErrInvalidRecord, err)
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return 0, "", false, nil, fmt.Errorf("%w: %v", ErrInvalidRecord, err)
+		}
+		switch key {
+		case "level":
+			var s string
+			if json.Unmarshal(raw, &s) == nil {
+				level = ParseLevel(strings.ToLower(s))
+			}
+			continue
+		case "message":
+			if json.Unmarshal(raw, &message) == nil {
+				if err := validateValue(message); err != nil {
+					return 0, "", false, nil, fmt.Errorf("%w: %v", ErrInvalidRecord, err)
+				}
+				hasMessage = true
+				continue
+			}
+		}
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, raw); err != nil {
+			return 0, "", false, nil, fmt.Errorf("%w: %v", ErrInvalidRecord, err)
+		}
+		fields = append(fields, rawField{key: key, value: compact.Bytes()})
+	}
+	if _, err := dec.Token(); err != nil {
+		return 0, "", false, nil, fmt.Errorf("%w: %v", ErrInvalidRecord, err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return 0, "", false, nil, fmt.Errorf("%w: trailing data after record", ErrInvalidRecord)
+	}
+	return level, message, hasMessage, fields, nil
+}
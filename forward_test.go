@@ -0,0 +1,70 @@
+package bolt
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestForward(t *testing.T) {
+	var buf strings.Builder
+	var hookMsg string
+	logger := New(NewJSONHandler(&buf)).
+		SetKeyReplacer(MongoKeyReplacer).
+		AddEventHook(EventHookFunc(func(e *Event, msg string) bool {
+			hookMsg = msg
+			return true
+		})).
+		With().Str("source", "edge").Logger()
+
+	line := `{ "ts": "2026-10-16T09:00:00Z", "message": "upstream done", "level": "WARN",` +
+		` "http.status": 503, "tags": [ "a", "b" ], "nested": {"k": "v\n"} }` + "\n"
+	if err := logger.Forward([]byte(line)); err != nil {
+		t.Fatalf("Forward: %v", err)
+	}
+	want := `{"level":"warn","source":"edge","ts":"2026-10-16T09:00:00Z","http_status":503,` +
+		`"tags":["a","b"],"nested":{"k":"v\n"},"message":"upstream done"}` + "\n"
+	if buf.String() != want {
+		t.Errorf("got  %s\nwant %s", buf.String(), want)
+	}
+	if hookMsg != "upstream done" {
+		t.Errorf("hook saw message %q", hookMsg)
+	}
+
+	buf.Reset()
+	if err := logger.Forward([]byte(`{"n":1}`)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), `{"level":"info","source":"edge","n":1}`+"\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	logger.SetLevel(ERROR)
+	if err := logger.Forward([]byte(`{"level":"info","n":1}`)); err != nil || buf.Len() != 0 {
+		t.Errorf("filtered record: err = %v, output = %q", err, buf.String())
+	}
+}
+
+func TestForwardRejectsInvalidFrames(t *testing.T) {
+	var buf strings.Builder
+	logger := New(NewJSONHandler(&buf))
+	for _, line := range []string{
+		``,
+		`not json`,
+		`["a"]`,
+		`{"a":1`,
+		`{"a":1}{"b":2}`,
+		`{"a":1}` + "\n" + `{"b":2}`,
+		"{\"a\":\n1}",
+		`{"a":1} trailing`,
+		`{"` + strings.Repeat("k", MaxKeyLength+1) + `":1}`,
+	} {
+		if err := logger.Forward([]byte(line)); !errors.Is(err, ErrInvalidRecord) {
+			t.Errorf("Forward(%q) = %v, want ErrInvalidRecord", line, err)
+		}
+	}
+	if buf.Len() != 0 {
+		t.Errorf("invalid records were logged: %q", buf.String())
+	}
+}
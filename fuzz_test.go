@@ -25,8 +25,8 @@ func FuzzJSONHandler(f *testing.F) {
 	f.Add("test message", 42, true)
 	f.Add("unicode: 你好世界", -1, false)
 	f.Add("special\n\t\"chars\\", 0, true)
-	f.Add("", math.MaxInt64, false)
-	f.Add(strings.Repeat("A", 1000), math.MinInt64, true)
+	f.Add("", math.MaxInt, false)
+	f.Add(strings.Repeat("A", 1000), math.MinInt, true)
 
 	f.Fuzz(func(t *testing.T, msg string, num int, flag bool) {
 		var buf bytes.Buffer
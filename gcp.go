@@ -0,0 +1,262 @@
+package bolt
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// GCPOptions configures [NewGCPHandler]. A nil *GCPOptions uses the
+// defaults.
+type GCPOptions struct {
+	// ProjectID qualifies trace IDs as
+	// "projects/<ProjectID>/traces/<trace_id>", the form Cloud Logging
+	// correlates with Cloud Trace. Defaults to $GOOGLE_CLOUD_PROJECT; if
+	// both are empty the bare trace ID is written.
+	ProjectID string
+
+	// Severities provides the level to LogSeverity mapping. Defaults to
+	// DefaultSeverities.
+	Severities *Severities
+}
+
+// GCPHandler writes records in the structured JSON form that Google
+// Cloud Logging's agents (GKE, Cloud Run, the Ops Agent) read from
+// stdout, so severity filtering and trace correlation work without a
+// parser configuration:
+//
+//	{"severity":"ERROR","message":"charge failed","timestamp":"2024-05-01T12:00:00.123Z",
+//	 "logging.googleapis.com/trace":"projects/shop/traces/4bf92f...","logging.googleapis.com/spanId":"00f067aa0ba902b7",
+//	 "logging.googleapis.com/sourceLocation":{"file":"billing/charge.go","line":"42","function":"billing.Charge"},
+//	 "order_id":"o-17"}
+//
+// The record is rewritten field by field:
+//
+//   - "level" becomes "severity", mapped through [Severities].GCP.
+//   - "timestamp" or "time" becomes "timestamp"; records without either
+//     get the time of writing.
+//   - "trace_id" and "span_id", as added by [Logger.Ctx], become the
+//     trace and spanId special fields.
+//   - "caller" and "func", as added by caller reporting, become
+//     sourceLocation.
+//   - Records with "method" and "status" fields, such as those of the
+//     httplog middleware, get an "httpRequest" object built from
+//     "method", "path", "status", "bytes" and "duration". A record that
+//     already carries an "httpRequest" field keeps it as is.
+//
+// All other fields are written unchanged. Like [JSONHandler], GCPHandler
+// is safe for concurrent use and writes each record with one Write call.
+type GCPHandler struct {
+	mu        sync.Mutex
+	out       io.Writer
+	traceBase []byte // `"projects/<id>/traces/`, or `"` without a project
+	severity  SeverityMap[string]
+	now       func() time.Time
+
+	line  []byte
+	spans []FieldSpan
+}
+
+// NewGCPHandler returns a GCPHandler writing to out, usually os.Stdout.
+func NewGCPHandler(out io.Writer, opts *GCPOptions) *GCPHandler {
+	if opts == nil {
+		opts = &GCPOptions{}
+	}
+	sev := opts.Severities
+	if sev == nil {
+		sev = &DefaultSeverities
+	}
+	project := opts.ProjectID
+	if project == "" {
+		project = os.Getenv("GOOGLE_CLOUD_PROJECT")
+	}
+	base := []byte{'"'}
+	if project != "" {
+		base = append(base, "projects/"...)
+		base = appendJSONString(base, project)
+		base = append(base, "/traces/"...)
+	}
+	return &GCPHandler{out: out, traceBase: base, severity: sev.GCP, now: time.Now}
+}
+
+// gcpFields are the positions of the fields GCPHandler rewrites; -1 when
+// absent.
+type gcpFields struct {
+	message, timestamp, trace, span, caller, fn int
+	method, path, status, bytes, duration, http int
+}
+
+// Write implements [Handler].
+func (h *GCPHandler) Write(e *Event) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	buf := e.buf
+	h.spans = appendFieldSpans(h.spans[:0], buf, 1)
+	f := gcpFields{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1}
+	for i, sp := range h.spans {
+		switch string(sp.Key(buf)) {
+		case "message":
+			f.message = i
+		case "timestamp", "time":
+			if f.timestamp < 0 {
+				f.timestamp = i
+			}
+		case "trace_id":
+			f.trace = i
+		case "span_id":
+			f.span = i
+		case "caller":
+			f.caller = i
+		case "func":
+			f.fn = i
+		case "method":
+			f.method = i
+		case "path":
+			f.path = i
+		case "status":
+			f.status = i
+		case "bytes":
+			f.bytes = i
+		case "duration":
+			f.duration = i
+		case "httpRequest":
+			f.http = i
+		}
+	}
+	folded := f.http < 0 && f.method >= 0 && f.status >= 0
+
+	line := append(h.line[:0], `{"severity":"`...)
+	line = append(line, h.severity.Of(e.level)...)
+	line = append(line, '"')
+	if f.message >= 0 {
+		line = append(line, `,"message":`...)
+		line = append(line, h.value(f.message, buf)...)
+	}
+	line = append(line, `,"timestamp":`...)
+	if f.timestamp >= 0 {
+		line = append(line, h.value(f.timestamp, buf)...)
+	} else {
+		line = append(line, '"')
+		line = h.now().UTC().AppendFormat(line, time.RFC3339Nano)
+		line = append(line, '"')
+	}
+	if f.trace >= 0 {
+		if v := h.value(f.trace, buf); len(v) > 1 && v[0] == '"' {
+			line = append(line, `,"logging.googleapis.com/trace":`...)
+			line = append(line, h.traceBase...)
+			line = append(line, v[1:]...)
+		}
+	}
+	if f.span >= 0 {
+		line = append(line, `,"logging.googleapis.com/spanId":`...)
+		line = append(line, h.value(f.span, buf)...)
+	}
+	if f.caller >= 0 {
+		line = h.appendSourceLocation(line, buf, f)
+	}
+	if folded {
+		line = h.appendHTTPRequest(line, buf, f)
+	}
+	for i, sp := range h.spans {
+		switch i {
+		case f.message, f.timestamp, f.trace, f.span, f.caller:
+			continue
+		case f.fn:
+			if f.caller >= 0 {
+				continue
+			}
+		case f.method, f.path, f.status, f.bytes, f.duration:
+			if folded {
+				continue
+			}
+		}
+		if i == 0 && string(sp.Key(buf)) == "level" {
+			continue
+		}
+		line = append(line, ',')
+		line = append(line, buf[sp.Start:sp.End]...)
+	}
+	line = append(line, '}', '\n')
+	h.line = line
+
+	if _, err := h.out.Write(line); err != nil {
+		return fmt.Errorf("failed to write record: %w", err)
+	}
+	return nil
+}
+
+func (h *GCPHandler) value(i int, buf []byte) []byte {
+	return h.spans[i].Value(buf)
+}
+
+// appendSourceLocation converts "caller":"file:line" and "func" into the
+// sourceLocation special field.
+func (h *GCPHandler) appendSourceLocation(line, buf []byte, f gcpFields) []byte {
+	v := h.value(f.caller, buf)
+	if len(v) < 2 || v[0] != '"' {
+		return line
+	}
+	v = v[1 : len(v)-1]
+	file, lineNo := v, []byte(nil)
+	if i := bytes.LastIndexByte(v, ':'); i >= 0 {
+		file, lineNo = v[:i], v[i+1:]
+	}
+	line = append(line, `,"logging.googleapis.com/sourceLocation":{"file":"`...)
+	line = append(line, file...)
+	line = append(line, '"')
+	if len(lineNo) > 0 {
+		line = append(line, `,"line":"`...)
+		line = append(line, lineNo...)
+		line = append(line, '"')
+	}
+	if f.fn >= 0 {
+		line = append(line, `,"function":`...)
+		line = append(line, h.value(f.fn, buf)...)
+	}
+	return append(line, '}')
+}
+
+// appendHTTPRequest builds the httpRequest special field from the
+// fields the httplog middleware writes.
+func (h *GCPHandler) appendHTTPRequest(line, buf []byte, f gcpFields) []byte {
+	line = append(line, `,"httpRequest":{"requestMethod":`...)
+	line = append(line, h.value(f.method, buf)...)
+	if f.path >= 0 {
+		line = append(line, `,"requestUrl":`...)
+		line = append(line, h.value(f.path, buf)...)
+	}
+	line = append(line, `,"status":`...)
+	line = append(line, h.value(f.status, buf)...)
+	if f.bytes >= 0 {
+		line = append(line, `,"responseSize":"`...)
+		line = append(line, h.value(f.bytes, buf)...)
+		line = append(line, '"')
+	}
+	if f.duration >= 0 {
+		if ns, err := strconv.ParseInt(string(h.value(f.duration, buf)), 10, 64); err == nil && ns >= 0 {
+			line = append(line, `,"latency":"`...)
+			line = appendSeconds(line, ns)
+			line = append(line, `s"`...)
+		}
+	}
+	return append(line, '}')
+}
+
+// appendSeconds appends ns as decimal seconds without trailing zeros, the
+// form of a protobuf Duration in JSON.
+func appendSeconds(dst []byte, ns int64) []byte {
+	dst = strconv.AppendInt(dst, ns/1e9, 10)
+	frac := ns % 1e9
+	if frac == 0 {
+		return dst
+	}
+	var digits [10]byte
+	b := strconv.AppendInt(digits[:0], 1e9+frac, 10) // leading 1 keeps the zeros
+	b = bytes.TrimRight(b[1:], "0")
+	dst = append(dst, '.')
+	return append(dst, b...)
+}
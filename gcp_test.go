@@ -0,0 +1,83 @@
+package bolt
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestGCPHandler(buf *bytes.Buffer, opts *GCPOptions) *GCPHandler {
+	h := NewGCPHandler(buf, opts)
+	h.now = func() time.Time { return time.Date(2024, 5, 1, 12, 0, 0, 123e6, time.UTC) }
+	return h
+}
+
+func TestGCPHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(newTestGCPHandler(&buf, &GCPOptions{ProjectID: "shop"}))
+
+	logger.Error().
+		Str("trace_id", "4bf92f3577b34da6a3ce929d0e0e4736").
+		Str("span_id", "00f067aa0ba902b7").
+		Str("caller", "billing/charge.go:42").
+		Str("func", "billing.Charge").
+		Str("order_id", "o-17").
+		Msg("charge failed")
+	logger.Warn().Str("timestamp", "2024-01-02T03:04:05Z").Msg("late")
+
+	want := `{"severity":"ERROR","message":"charge failed","timestamp":"2024-05-01T12:00:00.123Z",` +
+		`"logging.googleapis.com/trace":"projects/shop/traces/4bf92f3577b34da6a3ce929d0e0e4736",` +
+		`"logging.googleapis.com/spanId":"00f067aa0ba902b7",` +
+		`"logging.googleapis.com/sourceLocation":{"file":"billing/charge.go","line":"42","function":"billing.Charge"},` +
+		`"order_id":"o-17"}` + "\n" +
+		`{"severity":"WARNING","message":"late","timestamp":"2024-01-02T03:04:05Z"}` + "\n"
+	if buf.String() != want {
+		t.Errorf("got  %s\nwant %s", buf.String(), want)
+	}
+}
+
+func TestGCPHandlerHTTPRequest(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(newTestGCPHandler(&buf, nil))
+	logger.Warn().
+		Str("method", "GET").
+		Str("route", "/users/{id}").
+		Str("path", "/users/7").
+		Int("status", 404).
+		Int("bytes", 19).
+		Dur("duration", 1500*time.Microsecond).
+		Msg("http request")
+	logger.Info().Str("method", "cache").Msg("no status: not folded")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	var rec struct {
+		HTTPRequest map[string]any `json:"httpRequest"`
+		Route       string         `json:"route"`
+		Method      string         `json:"method"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &rec); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{"requestMethod": "GET", "requestUrl": "/users/7", "status": 404.0, "responseSize": "19", "latency": "0.0015s"}
+	for k, v := range want {
+		if rec.HTTPRequest[k] != v {
+			t.Errorf("httpRequest[%s] = %v, want %v (%s)", k, rec.HTTPRequest[k], v, lines[0])
+		}
+	}
+	if rec.Route != "/users/{id}" || rec.Method != "" {
+		t.Errorf("route, method = %q, %q: want route kept and method folded", rec.Route, rec.Method)
+	}
+	if !strings.Contains(lines[1], `"method":"cache"`) || strings.Contains(lines[1], "httpRequest") {
+		t.Errorf("unfolded record = %s", lines[1])
+	}
+}
+
+func TestAppendSeconds(t *testing.T) {
+	for ns, want := range map[int64]string{0: "0", 2e9: "2", 1500e6: "1.5", 1: "0.000000001", 12345678901: "12.345678901"} {
+		if got := string(appendSeconds(nil, ns)); got != want {
+			t.Errorf("appendSeconds(%d) = %s, want %s", ns, got, want)
+		}
+	}
+}
@@ -93,6 +93,10 @@ type AdaptiveSampler struct {
 	N                  uint32
 	AlwaysKeepLevel    bolt.Level
 	AlwaysKeepPrefixes []string
+	// Annotate adds bolt.SampleRateField with 1/N to events kept by
+	// sampling, so downstream counts can be re-weighted. Always-kept
+	// events are not annotated.
+	Annotate bool
 
 	counter uint32
 }
@@ -137,5 +141,11 @@ func (s *AdaptiveSampler) Run(e *bolt.Event, _ string) bool {
 		return true
 	}
 	c := atomic.AddUint32(&s.counter, 1)
-	return c%s.N == 0
+	if c%s.N != 0 {
+		return false
+	}
+	if s.Annotate {
+		e.Float64(bolt.SampleRateField, 1/float64(s.N))
+	}
+	return true
 }
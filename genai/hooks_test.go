@@ -97,6 +97,24 @@ func TestAdaptiveSampler_LowImportanceSampled(t *testing.T) {
 	}
 }
 
+func TestAdaptiveSampler_Annotate(t *testing.T) {
+	var buf bytes.Buffer
+	s := genai.NewAdaptiveSampler(4)
+	s.Annotate = true
+	logger := bolt.New(bolt.NewJSONHandler(&buf)).AddEventHook(s)
+
+	for i := 0; i < 4; i++ {
+		logger.Debug().Msg("noise")
+	}
+	logger.Debug().Str("gen_ai.system", "openai").Msg("kept")
+
+	want := `{"level":"debug","sample_rate":0.25,"message":"noise"}` + "\n" +
+		`{"level":"debug","gen_ai.system":"openai","message":"kept"}` + "\n"
+	if buf.String() != want {
+		t.Errorf("got  %s\nwant %s", buf.String(), want)
+	}
+}
+
 func TestAdaptiveSampler_NoSamplingWhenN0Or1(t *testing.T) {
 	for _, n := range []uint32{0, 1} {
 		var buf bytes.Buffer
@@ -0,0 +1,25 @@
+module go.klarlabs.de/bolt/grpclog
+
+go 1.25.0
+
+require (
+	go.klarlabs.de/bolt v1.4.0
+	google.golang.org/grpc v1.81.1
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	go.opentelemetry.io/otel v1.43.0 // indirect
+	go.opentelemetry.io/otel/trace v1.43.0 // indirect
+	golang.org/x/net v0.51.0 // indirect
+	golang.org/x/sys v0.44.0 // indirect
+	golang.org/x/term v0.43.0 // indirect
+	golang.org/x/text v0.34.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20260226221140-a57be14db171 // indirect
+	google.golang.org/protobuf v1.36.11 // indirect
+)
+
+// Local development — pin to the in-tree bolt module. CI consumers
+// override this via `go work` or by removing the directive in their
+// own checkouts.
+replace go.klarlabs.de/bolt => ../
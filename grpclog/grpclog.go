@@ -0,0 +1,142 @@
+// Package grpclog provides gRPC server interceptors that write one bolt
+// access-log event per RPC.
+//
+// Infrastructure chatter is filtered by [Rule]s matched against the full
+// method name. By default, [DefaultRules] drop grpc.health.v1 checks and
+// demote server-reflection calls to DEBUG, because load-balancer health
+// probes otherwise dominate access logs. Rules only apply to calls that
+// succeed: a failing health check is still logged at ERROR.
+//
+// Each call is given an EventID with [bolt.ChildEvent]. The access-log
+// event carries it as "event_id"; handlers that log with
+// [bolt.Event.Link] on a context derived from the call's are linked back
+// to it through "parent_event_id".
+//
+// The package lives in its own go.mod so that the bolt core does not
+// depend on google.golang.org/grpc.
+//
+// Example:
+//
+//	logger := bolt.New(bolt.NewJSONHandler(os.Stdout))
+//	srv := grpc.NewServer(
+//	    grpc.UnaryInterceptor(grpclog.UnaryServerInterceptor(logger, nil)),
+//	    grpc.StreamInterceptor(grpclog.StreamServerInterceptor(logger, nil)),
+//	)
+package grpclog
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.klarlabs.de/bolt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Action is what a [Rule] does with a matching, successful call.
+type Action uint8
+
+const (
+	// Log logs the call normally. Useful to carve an exception out of a
+	// broader rule listed after it.
+	Log Action = iota
+	// Demote logs the call at DEBUG instead of INFO.
+	Demote
+	// Drop does not log the call.
+	Drop
+)
+
+// Rule matches gRPC methods by prefix of the full method name
+// ("/package.Service/Method"). A prefix ending in "/" matches every
+// method of a service.
+type Rule struct {
+	Prefix string
+	Action Action
+}
+
+// DefaultRules silence the standard health and reflection services.
+var DefaultRules = []Rule{
+	{Prefix: "/grpc.health.v1.Health/", Action: Drop},
+	{Prefix: "/grpc.reflection.v1.ServerReflection/", Action: Demote},
+	{Prefix: "/grpc.reflection.v1alpha.ServerReflection/", Action: Demote},
+}
+
+// Options configures the interceptors. A nil *Options uses the defaults.
+type Options struct {
+	// Rules are evaluated in order; the first matching prefix wins. A nil
+	// slice means DefaultRules; use an empty, non-nil slice to log every
+	// call unfiltered.
+	Rules []Rule
+}
+
+func (o *Options) action(method string) Action {
+	rules := DefaultRules
+	if o != nil && o.Rules != nil {
+		rules = o.Rules
+	}
+	for _, r := range rules {
+		if strings.HasPrefix(method, r.Prefix) {
+			return r.Action
+		}
+	}
+	return Log
+}
+
+// UnaryServerInterceptor returns an interceptor that logs unary RPCs.
+func UnaryServerInterceptor(logger *bolt.Logger, opts *Options) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		ctx = bolt.ChildEvent(ctx)
+		resp, err := handler(ctx, req)
+		logCall(ctx, logger, opts, info.FullMethod, "unary", start, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns an interceptor that logs streaming RPCs
+// once the stream has finished.
+func StreamServerInterceptor(logger *bolt.Logger, opts *Options) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ss = &serverStream{ServerStream: ss, ctx: bolt.ChildEvent(ss.Context())}
+		err := handler(srv, ss)
+		logCall(ss.Context(), logger, opts, info.FullMethod, "stream", start, err)
+		return err
+	}
+}
+
+// serverStream overrides the stream context so handlers see the call's
+// EventID.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context { return s.ctx }
+
+func logCall(ctx context.Context, logger *bolt.Logger, opts *Options, method, kind string, start time.Time, err error) {
+	code := status.Code(err)
+	var e *bolt.Event
+	switch {
+	case code != codes.OK:
+		e = logger.Error()
+	default:
+		switch opts.action(method) {
+		case Drop:
+			return
+		case Demote:
+			e = logger.Debug()
+		default:
+			e = logger.Info()
+		}
+	}
+	e.Link(ctx).
+		Str("method", method).
+		Str("type", kind).
+		Str("code", code.String()).
+		Dur("duration", time.Since(start)).
+		Err(err).
+		Msg("grpc request")
+}
@@ -0,0 +1,135 @@
+package grpclog_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"go.klarlabs.de/bolt"
+	"go.klarlabs.de/bolt/grpclog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func callUnary(t *testing.T, ic grpc.UnaryServerInterceptor, method string, err error) {
+	t.Helper()
+	info := &grpc.UnaryServerInfo{FullMethod: method}
+	_, _ = ic(context.Background(), nil, info, func(context.Context, any) (any, error) {
+		return nil, err
+	})
+}
+
+func TestUnary_DefaultRules(t *testing.T) {
+	var buf bytes.Buffer
+	logger := bolt.New(bolt.NewJSONHandler(&buf)).SetLevel(bolt.INFO)
+	ic := grpclog.UnaryServerInterceptor(logger, nil)
+
+	callUnary(t, ic, "/grpc.health.v1.Health/Check", nil)
+	if buf.Len() != 0 {
+		t.Errorf("health check logged: %s", buf.String())
+	}
+
+	callUnary(t, ic, "/grpc.reflection.v1.ServerReflection/ServerReflectionInfo", nil)
+	if buf.Len() != 0 {
+		t.Errorf("reflection not demoted below INFO: %s", buf.String())
+	}
+
+	callUnary(t, ic, "/users.v1.Users/Get", nil)
+	if !strings.Contains(buf.String(), `"method":"/users.v1.Users/Get"`) || !strings.Contains(buf.String(), `"code":"OK"`) {
+		t.Errorf("regular call not logged: %s", buf.String())
+	}
+}
+
+func TestUnary_FailedHealthCheckStillLogged(t *testing.T) {
+	var buf bytes.Buffer
+	logger := bolt.New(bolt.NewJSONHandler(&buf))
+	ic := grpclog.UnaryServerInterceptor(logger, nil)
+
+	callUnary(t, ic, "/grpc.health.v1.Health/Check", status.Error(codes.Unavailable, "db down"))
+	out := buf.String()
+	if !strings.Contains(out, `"level":"error"`) || !strings.Contains(out, `"code":"Unavailable"`) {
+		t.Errorf("failed health check not logged at error: %s", out)
+	}
+}
+
+func TestUnary_DemoteVisibleAtDebug(t *testing.T) {
+	var buf bytes.Buffer
+	logger := bolt.New(bolt.NewJSONHandler(&buf)).SetLevel(bolt.DEBUG)
+	ic := grpclog.UnaryServerInterceptor(logger, nil)
+
+	callUnary(t, ic, "/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo", nil)
+	if !strings.Contains(buf.String(), `"level":"debug"`) {
+		t.Errorf("reflection call not demoted to debug: %s", buf.String())
+	}
+}
+
+func TestUnary_CustomRules(t *testing.T) {
+	var buf bytes.Buffer
+	logger := bolt.New(bolt.NewJSONHandler(&buf))
+
+	// Empty non-nil rules: log everything.
+	callUnary(t, grpclog.UnaryServerInterceptor(logger, &grpclog.Options{Rules: []grpclog.Rule{}}),
+		"/grpc.health.v1.Health/Check", nil)
+	if !strings.Contains(buf.String(), "Health/Check") {
+		t.Errorf("empty rules should log health checks: %s", buf.String())
+	}
+
+	buf.Reset()
+	opts := &grpclog.Options{Rules: []grpclog.Rule{
+		{Prefix: "/metrics.v1.Metrics/Push", Action: grpclog.Log},
+		{Prefix: "/metrics.v1.Metrics/", Action: grpclog.Drop},
+	}}
+	ic := grpclog.UnaryServerInterceptor(logger, opts)
+	callUnary(t, ic, "/metrics.v1.Metrics/Scrape", nil)
+	callUnary(t, ic, "/metrics.v1.Metrics/Push", nil)
+	if strings.Contains(buf.String(), "Scrape") || !strings.Contains(buf.String(), "Push") {
+		t.Errorf("first-match rule ordering broken: %s", buf.String())
+	}
+}
+
+type fakeStream struct{ grpc.ServerStream }
+
+func (fakeStream) Context() context.Context { return context.Background() }
+
+func TestStream_DefaultRules(t *testing.T) {
+	var buf bytes.Buffer
+	logger := bolt.New(bolt.NewJSONHandler(&buf))
+	ic := grpclog.StreamServerInterceptor(logger, nil)
+	ok := func(any, grpc.ServerStream) error { return nil }
+
+	_ = ic(nil, fakeStream{}, &grpc.StreamServerInfo{FullMethod: "/grpc.health.v1.Health/Watch"}, ok)
+	if buf.Len() != 0 {
+		t.Errorf("health watch logged: %s", buf.String())
+	}
+	_ = ic(nil, fakeStream{}, &grpc.StreamServerInfo{FullMethod: "/chat.v1.Chat/Stream"}, ok)
+	if !strings.Contains(buf.String(), `"type":"stream"`) {
+		t.Errorf("stream call not logged: %s", buf.String())
+	}
+}
+
+func TestUnary_EventLinking(t *testing.T) {
+	var buf bytes.Buffer
+	logger := bolt.New(bolt.NewJSONHandler(&buf))
+	ic := grpclog.UnaryServerInterceptor(logger, nil)
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/users.v1.Users/Get"}
+	_, _ = ic(context.Background(), nil, info, func(ctx context.Context, _ any) (any, error) {
+		logger.Info().Link(bolt.ChildEvent(ctx)).Msg("db query")
+		return nil, nil
+	})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("want 2 events, got %d: %s", len(lines), buf.String())
+	}
+	i := strings.Index(lines[1], `"event_id":"`)
+	if i < 0 {
+		t.Fatalf("access event has no event_id: %s", lines[1])
+	}
+	id := lines[1][i+len(`"event_id":"`) : i+len(`"event_id":"`)+26]
+	if !strings.Contains(lines[0], `"parent_event_id":"`+id+`"`) {
+		t.Errorf("handler event not linked to call %s: %s", id, lines[0])
+	}
+}
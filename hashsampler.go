@@ -0,0 +1,85 @@
+package bolt
+
+import "math"
+
+// HashSampler is an [EventHook] that keeps or drops events by hashing the
+// value of one field, so every event sharing that value — all events of
+// one request (correlation_id) or one user (user_id) — gets the same
+// decision. Unlike [SampleHook], the decision is reproducible in every
+// service that samples on the same key and rate, so a sampled request is
+// kept end to end.
+//
+// The hash is 64-bit FNV-1a over the field value as encoded in the event
+// (string contents without the surrounding quotes, raw JSON text for
+// other types). An event is kept when hash < rate × 2⁶⁴. Services written
+// in other languages can reproduce the decision with the same rule. In
+// [FIPSMode] the hash is the first eight bytes of SHA-256, big-endian.
+//
+// Events that do not carry the key are kept, as are events whose key only
+// appears inside a nested object; HashSampler only inspects top-level
+// fields, including those from logger context. [Event.Elevated] events
+// are always kept.
+type HashSampler struct {
+	key       string
+	threshold uint64
+	all       bool
+	rate      float64
+	annotate  bool
+}
+
+// NewHashSampler returns a HashSampler keyed on key that keeps the given
+// fraction of values. rate is clamped to [0, 1]; 1 keeps everything and 0
+// drops every event carrying the key.
+func NewHashSampler(key string, rate float64) *HashSampler {
+	s := &HashSampler{key: key, rate: rate}
+	switch {
+	case rate >= 1 || math.IsNaN(rate):
+		s.all = true
+	case rate > 0:
+		s.threshold = uint64(rate * (1 << 64))
+	}
+	return s
+}
+
+// Run implements [EventHook].
+func (s *HashSampler) Run(e *Event, _ string) bool {
+	if s.all || e.Elevated() {
+		return true
+	}
+	keep, sampled := true, false
+	e.WalkFields(func(key, value []byte) bool {
+		if string(key) != s.key {
+			return true
+		}
+		keep, sampled = hash64(value) < s.threshold, true
+		return false
+	})
+	if keep && sampled && s.annotate {
+		e.Float64(SampleRateField, s.rate)
+	}
+	return keep
+}
+
+// Annotate makes the sampler add [SampleRateField] with its rate to the
+// events it keeps by hash, and returns s. Events without the key,
+// elevated events, and all events at rate 1 are not annotated. Call it
+// before adding the sampler to a logger.
+func (s *HashSampler) Annotate() *HashSampler {
+	s.annotate = true
+	return s
+}
+
+// fnv1a64 is the 64-bit FNV-1a hash, inlined to avoid the hash.Hash64
+// allocation on the logging path.
+func fnv1a64(b []byte) uint64 {
+	const (
+		offset64 = 14695981039346656037
+		prime64  = 1099511628211
+	)
+	h := uint64(offset64)
+	for _, c := range b {
+		h ^= uint64(c)
+		h *= prime64
+	}
+	return h
+}
@@ -0,0 +1,94 @@
+package bolt
+
+import (
+	"bytes"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestFNV1a64MatchesStdlib(t *testing.T) {
+	for _, s := range []string{"", "a", "req-7f3a9c", "user:42"} {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(s))
+		if got, want := fnv1a64([]byte(s)), h.Sum64(); got != want {
+			t.Errorf("fnv1a64(%q) = %x; want %x", s, got, want)
+		}
+	}
+}
+
+func TestHashSamplerConsistentPerKey(t *testing.T) {
+	var a, b bytes.Buffer
+	svcA := New(NewJSONHandler(&a)).AddEventHook(NewHashSampler("correlation_id", 0.5))
+	svcB := New(NewJSONHandler(&b)).AddEventHook(NewHashSampler("correlation_id", 0.5))
+
+	kept := 0
+	const n = 2000
+	for i := 0; i < n; i++ {
+		id := "req-" + strconv.Itoa(i)
+		a.Reset()
+		b.Reset()
+		req := svcA.With().Str("correlation_id", id).Logger()
+		req.Info().Msg("received")
+		req.Info().Str("step", "db").Msg("queried")
+		svcB.Info().Str("correlation_id", id).Msg("downstream")
+
+		linesA := strings.Count(a.String(), "\n")
+		linesB := strings.Count(b.String(), "\n")
+		if linesA != 0 && linesA != 2 {
+			t.Fatalf("%s: request split by sampler: %s", id, a.String())
+		}
+		if (linesA == 2) != (linesB == 1) {
+			t.Fatalf("%s: services disagree: A=%d B=%d", id, linesA, linesB)
+		}
+		if linesB == 1 {
+			kept++
+		}
+	}
+	if kept < n*4/10 || kept > n*6/10 {
+		t.Errorf("kept %d of %d at rate 0.5", kept, n)
+	}
+}
+
+func TestHashSamplerEdgeRates(t *testing.T) {
+	var buf bytes.Buffer
+	drop := New(NewJSONHandler(&buf)).AddEventHook(NewHashSampler("user_id", 0))
+	drop.Info().Int("user_id", 42).Msg("dropped")
+	drop.Info().Msg("no key, kept")
+	if got := buf.String(); strings.Contains(got, "dropped") || !strings.Contains(got, "no key, kept") {
+		t.Errorf("rate 0: %s", got)
+	}
+
+	buf.Reset()
+	keep := New(NewJSONHandler(&buf)).AddEventHook(NewHashSampler("user_id", 1))
+	for i := 0; i < 10; i++ {
+		keep.Info().Int("user_id", i).Msg("kept")
+	}
+	if got := strings.Count(buf.String(), "\n"); got != 10 {
+		t.Errorf("rate 1 kept %d of 10", got)
+	}
+}
+
+func TestHashSamplerAnnotate(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewJSONHandler(&buf)).AddEventHook(NewHashSampler("user_id", 0.5).Annotate())
+
+	for i := 0; i < 20; i++ {
+		logger.Info().Int("user_id", i).Msg("sampled")
+	}
+	logger.Info().Msg("no key")
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) < 2 || len(lines) > 20 {
+		t.Fatalf("kept %d lines", len(lines))
+	}
+	for _, line := range lines[:len(lines)-1] {
+		if !strings.Contains(line, `"sample_rate":0.5,"message":"sampled"`) {
+			t.Errorf("kept event not annotated: %s", line)
+		}
+	}
+	if last := lines[len(lines)-1]; strings.Contains(last, SampleRateField) {
+		t.Errorf("event without key annotated: %s", last)
+	}
+}
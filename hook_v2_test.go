@@ -191,14 +191,39 @@ func TestEventHook_CoexistsWithLegacyHook(t *testing.T) {
 	}
 }
 
-// HookFunc is a function-typed adapter for [Hook]. Defined here in the
-// test file rather than the public API; promote to public if user demand
-// emerges.
-type HookFunc func(level Level, msg string) bool
-
-func (f HookFunc) Run(level Level, msg string) bool { return f(level, msg) }
-
-// EventHookFunc is a function-typed adapter for [EventHook]. Test-only.
-type EventHookFunc func(e *Event, msg string) bool
+func TestLoggerHookScopesToChild(t *testing.T) {
+	var buf bytes.Buffer
+	var parentRuns, childRuns int
+	parent := New(NewJSONHandler(&buf)).AddEventHook(EventHookFunc(func(e *Event, _ string) bool {
+		parentRuns++
+		return true
+	}))
+	child := parent.Hook(EventHookFunc(func(e *Event, msg string) bool {
+		childRuns++
+		e.Str("subsystem", "payments")
+		return msg != "drop"
+	}))
+
+	child.Info().Msg("charged")
+	child.Info().Msg("drop")
+	parent.Info().Msg("untouched")
+
+	want := `{"level":"info","subsystem":"payments","message":"charged"}` + "\n" +
+		`{"level":"info","message":"untouched"}` + "\n"
+	if buf.String() != want {
+		t.Errorf("got  %s\nwant %s", buf.String(), want)
+	}
+	if parentRuns != 3 || childRuns != 2 {
+		t.Errorf("parent hook runs = %d, child hook runs = %d; want 3, 2", parentRuns, childRuns)
+	}
 
-func (f EventHookFunc) Run(e *Event, msg string) bool { return f(e, msg) }
+	// Hooks added to siblings must not share a backing array.
+	a := parent.Hook(EventHookFunc(func(*Event, string) bool { return true }))
+	b := parent.Hook(EventHookFunc(func(*Event, string) bool { return false }))
+	buf.Reset()
+	a.Info().Msg("a")
+	b.Info().Msg("b")
+	if got := buf.String(); got != `{"level":"info","message":"a"}`+"\n" {
+		t.Errorf("sibling hooks interfered: %q", got)
+	}
+}
@@ -0,0 +1,323 @@
+package bolt
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultHTTPRetries is how many times an HTTPHandler retries a batch
+	// when none is configured.
+	DefaultHTTPRetries = 5
+	// DefaultHTTPBackoff is the delay before the first retry when none is
+	// configured. It doubles with every attempt.
+	DefaultHTTPBackoff = 500 * time.Millisecond
+	// DefaultHTTPMaxBackoff caps the retry delay when no cap is
+	// configured.
+	DefaultHTTPMaxBackoff = 30 * time.Second
+	// DefaultHTTPBreakerThreshold is how many batches in a row must fail
+	// before an HTTPHandler stops calling the endpoint, when no threshold
+	// is configured.
+	DefaultHTTPBreakerThreshold = 5
+	// DefaultHTTPBreakerCooldown is how long an open circuit stays open
+	// when no cooldown is configured.
+	DefaultHTTPBreakerCooldown = 30 * time.Second
+	// httpTimeout bounds each request of the default client.
+	httpTimeout = 10 * time.Second
+)
+
+var (
+	// ErrHTTPRejected is returned, wrapped with the HTTP status and
+	// response body, when the endpoint rejects a batch with a status that
+	// retrying cannot fix, such as 400, 401 or 413.
+	ErrHTTPRejected = errors.New("bolt: http endpoint rejected batch")
+
+	// ErrCircuitOpen is returned for batches not sent because the
+	// endpoint failed repeatedly and the handler is waiting out its
+	// cooldown.
+	ErrCircuitOpen = errors.New("bolt: http circuit open")
+)
+
+// HTTPOptions configures [NewHTTPHandler]. A nil *HTTPOptions uses the
+// defaults.
+type HTTPOptions struct {
+	// Header is added to every request, for example for authorization.
+	Header http.Header
+
+	// ContentType is the request's Content-Type. Defaults to
+	// "application/x-ndjson".
+	ContentType string
+
+	// Gzip compresses request bodies and sets "Content-Encoding: gzip".
+	Gzip bool
+
+	// Batch configures batching and backpressure; see [BatchOptions].
+	// Its ErrorHandler receives batches that could not be delivered.
+	Batch *BatchOptions
+
+	// MaxRetries is how many times a batch is retried after a network
+	// error, a 5xx or a 429 response. Defaults to DefaultHTTPRetries;
+	// negative disables retries.
+	MaxRetries int
+
+	// Backoff is the delay before the first retry, doubled for every
+	// further attempt with random jitter, up to MaxBackoff. Defaults to
+	// DefaultHTTPBackoff and DefaultHTTPMaxBackoff.
+	Backoff, MaxBackoff time.Duration
+
+	// BreakerThreshold is how many batches in a row must fail, after
+	// their retries, to open the circuit: for BreakerCooldown, batches
+	// fail at once with ErrCircuitOpen instead of waiting on a dead
+	// endpoint. The first batch after the cooldown probes the endpoint
+	// without retries. Defaults to DefaultHTTPBreakerThreshold and
+	// DefaultHTTPBreakerCooldown; a negative threshold disables the
+	// breaker.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+
+	// TLS configures HTTPS for the default client. Ignored if Client is
+	// set.
+	TLS *TLSConfig
+
+	// Client sends the requests. Defaults to a client with a 10s timeout.
+	Client *http.Client
+}
+
+// HTTPStats are the delivery counters of an [HTTPHandler].
+type HTTPStats struct {
+	Sent        uint64 // events accepted by the endpoint
+	Failed      uint64 // events in batches given up after retries, rejected or short-circuited
+	Dropped     uint64 // events dropped because the batch queue was full
+	Retries     uint64 // batch retries
+	Queued      int    // full batches waiting to be sent
+	CircuitOpen bool   // whether batches are currently short-circuited
+}
+
+// HTTPHandler posts batches of records to an HTTP endpoint, for the many
+// backends that accept JSON lines over HTTP (Loki's and Elasticsearch's
+// bulk gateways, Vector, Fluent Bit, Logstash, custom collectors).
+// Events are collected into batches by an embedded [BatchHandler] and
+// each batch is posted as one newline-delimited request body, optionally
+// gzip-compressed:
+//
+//	h, err := bolt.NewHTTPHandler("https://logs.example.com/ingest", &bolt.HTTPOptions{
+//	    Header: http.Header{"Authorization": {"Bearer " + token}},
+//	    Gzip:   true,
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	defer h.Close()
+//	logger := bolt.New(h)
+//
+// Batches failing with a network error, a 5xx or a 429 response are
+// retried with exponential backoff; other failures return ErrHTTPRejected
+// to the batch error handler. When BreakerThreshold batches in a row
+// fail, the circuit opens and batches fail with ErrCircuitOpen until the
+// cooldown ends. Retries block later batches, which queue and are then
+// dropped or block the logger as [BatchOptions] configures. Close flushes
+// pending events and waits for their delivery.
+type HTTPHandler struct {
+	*BatchHandler
+	sender *httpSender
+}
+
+// NewHTTPHandler returns a handler posting to the http or https URL
+// rawURL.
+func NewHTTPHandler(rawURL string, opts *HTTPOptions) (*HTTPHandler, error) {
+	if opts == nil {
+		opts = &HTTPOptions{}
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("bolt: http url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("bolt: http url %q: scheme must be http or https", rawURL)
+	}
+	s := &httpSender{
+		url:         u.String(),
+		header:      opts.Header.Clone(),
+		gzip:        opts.Gzip,
+		maxRetries:  opts.MaxRetries,
+		backoff:     opts.Backoff,
+		maxBackoff:  opts.MaxBackoff,
+		threshold:   opts.BreakerThreshold,
+		cooldown:    opts.BreakerCooldown,
+		client:      opts.Client,
+		now:         time.Now,
+		contentType: opts.ContentType,
+	}
+	if s.header == nil {
+		s.header = http.Header{}
+	}
+	if s.contentType == "" {
+		s.contentType = "application/x-ndjson"
+	}
+	if s.maxRetries == 0 {
+		s.maxRetries = DefaultHTTPRetries
+	}
+	if s.backoff <= 0 {
+		s.backoff = DefaultHTTPBackoff
+	}
+	if s.maxBackoff <= 0 {
+		s.maxBackoff = DefaultHTTPMaxBackoff
+	}
+	if s.threshold == 0 {
+		s.threshold = DefaultHTTPBreakerThreshold
+	}
+	if s.cooldown <= 0 {
+		s.cooldown = DefaultHTTPBreakerCooldown
+	}
+	if s.client == nil {
+		cfg, err := opts.TLS.Build()
+		if err != nil {
+			return nil, err
+		}
+		s.client = &http.Client{
+			Timeout:   httpTimeout,
+			Transport: &http.Transport{TLSClientConfig: cfg, Proxy: http.ProxyFromEnvironment},
+		}
+	}
+	return &HTTPHandler{BatchHandler: NewBatchHandler(s, opts.Batch), sender: s}, nil
+}
+
+// Stats returns the handler's delivery counters.
+func (h *HTTPHandler) Stats() HTTPStats {
+	return HTTPStats{
+		Sent:        h.sender.sent.Load(),
+		Failed:      h.sender.failed.Load(),
+		Dropped:     h.Dropped(),
+		Retries:     h.sender.retried.Load(),
+		Queued:      h.Queued(),
+		CircuitOpen: h.sender.openUntil.Load() > h.sender.now().UnixNano(),
+	}
+}
+
+// httpSender is the handler behind the batcher. It only runs on the
+// batch goroutine, so its buffers and breaker state need no locking;
+// openUntil is atomic for Stats.
+type httpSender struct {
+	url         string
+	header      http.Header
+	contentType string
+	gzip        bool
+	maxRetries  int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+	threshold   int
+	cooldown    time.Duration
+	client      *http.Client
+	now         func() time.Time
+
+	body      bytes.Buffer
+	zw        *gzip.Writer
+	failures  int          // batches failed in a row
+	probing   bool         // the next batch is the first after a cooldown
+	openUntil atomic.Int64 // UnixNano the circuit stays open until
+
+	sent, failed, retried atomic.Uint64
+}
+
+// Write posts a batch of newline-separated records.
+func (s *httpSender) Write(e *Event) error {
+	n := uint64(bytes.Count(e.buf, []byte{'\n'}))
+	if len(e.buf) > 0 && e.buf[len(e.buf)-1] != '\n' {
+		n++
+	}
+	if n == 0 {
+		return nil
+	}
+	if until := s.openUntil.Load(); until != 0 {
+		if s.now().UnixNano() < until {
+			s.failed.Add(n)
+			return fmt.Errorf("http: %d events not delivered: %w", n, ErrCircuitOpen)
+		}
+		s.openUntil.Store(0)
+		s.probing = true
+	}
+
+	body := e.buf
+	if s.gzip {
+		s.body.Reset()
+		if s.zw == nil {
+			s.zw = gzip.NewWriter(&s.body)
+		} else {
+			s.zw.Reset(&s.body)
+		}
+		_, _ = s.zw.Write(e.buf) // writes to a bytes.Buffer cannot fail
+		_ = s.zw.Close()
+		body = s.body.Bytes()
+	}
+
+	maxRetries := s.maxRetries
+	if s.probing {
+		maxRetries = 0
+	}
+	for attempt := 0; ; attempt++ {
+		retry, err := s.post(body)
+		if err == nil {
+			s.sent.Add(n)
+			s.failures, s.probing = 0, false
+			return nil
+		}
+		if !retry || attempt >= maxRetries {
+			s.failed.Add(n)
+			s.failures++
+			if s.threshold > 0 && (s.probing || s.failures >= s.threshold) {
+				s.openUntil.Store(s.now().Add(s.cooldown).UnixNano())
+			}
+			s.probing = false
+			return fmt.Errorf("http: %d events not delivered: %w", n, err)
+		}
+		s.retried.Add(1)
+		time.Sleep(backoffDelay(s.backoff, s.maxBackoff, attempt))
+	}
+}
+
+// post sends body once and reports whether a failure is worth retrying.
+func (s *httpSender) post(body []byte) (retry bool, err error) {
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	for k, v := range s.header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", s.contentType)
+	if s.gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		_, _ = io.Copy(io.Discard, resp.Body) // reuse the connection
+		return false, nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		return true, fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return false, fmt.Errorf("%w: %s: %s", ErrHTTPRejected, resp.Status, bytes.TrimSpace(msg))
+}
+
+// backoffDelay returns the delay before retry attempt+1: base doubled
+// per attempt, capped at limit, with up to 50% jitter.
+func backoffDelay(base, limit time.Duration, attempt int) time.Duration {
+	d := base << min(attempt, 30)
+	if d <= 0 || d > limit {
+		d = limit
+	}
+	return d/2 + rand.N(d/2+1)
+}
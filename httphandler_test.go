@@ -0,0 +1,156 @@
+package bolt
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ingestServer records request bodies, decompressing gzip, and answers
+// with the queued status codes, then 200.
+type ingestServer struct {
+	mu       sync.Mutex
+	bodies   []string
+	headers  []http.Header
+	statuses []int
+	calls    int
+}
+
+func (s *ingestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body = zr
+	}
+	b, _ := io.ReadAll(body)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	s.headers = append(s.headers, r.Header.Clone())
+	if len(s.statuses) > 0 {
+		status := s.statuses[0]
+		s.statuses = s.statuses[1:]
+		w.WriteHeader(status)
+		io.WriteString(w, "unavailable")
+		return
+	}
+	s.bodies = append(s.bodies, string(b))
+}
+
+func TestHTTPHandlerGzipBatch(t *testing.T) {
+	srv := &ingestServer{}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	h, err := NewHTTPHandler(ts.URL+"/ingest", &HTTPOptions{
+		Header: http.Header{"Authorization": {"Bearer t0ken"}},
+		Gzip:   true,
+		Batch:  &BatchOptions{MaxDelay: time.Hour},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := New(h)
+	logger.Info().Str("user", "ada").Msg("one")
+	logger.Warn().Msg("two")
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"level":"info","user":"ada","message":"one"}` + "\n" + `{"level":"warn","message":"two"}` + "\n"
+	if len(srv.bodies) != 1 || srv.bodies[0] != want {
+		t.Errorf("bodies = %q, want one batch %q", srv.bodies, want)
+	}
+	hdr := srv.headers[0]
+	if hdr.Get("Authorization") != "Bearer t0ken" || hdr.Get("Content-Type") != "application/x-ndjson" || hdr.Get("Content-Encoding") != "gzip" {
+		t.Errorf("headers = %v", hdr)
+	}
+	if st := h.Stats(); st.Sent != 2 || st.Failed != 0 {
+		t.Errorf("Stats() = %+v", st)
+	}
+}
+
+func TestHTTPHandlerRetriesAndRejects(t *testing.T) {
+	srv := &ingestServer{statuses: []int{http.StatusServiceUnavailable, http.StatusBadRequest}}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	var errs []error
+	h, err := NewHTTPHandler(ts.URL, &HTTPOptions{
+		Backoff: time.Millisecond,
+		Batch:   &BatchOptions{MaxDelay: time.Hour, ErrorHandler: func(err error) { errs = append(errs, err) }},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	New(h).Info().Msg("rejected after a retry")
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], ErrHTTPRejected) {
+		t.Errorf("errors = %v, want ErrHTTPRejected", errs)
+	}
+	if st := h.Stats(); st.Retries != 1 || st.Failed != 1 {
+		t.Errorf("Stats() = %+v", st)
+	}
+}
+
+func TestHTTPHandlerCircuitBreaker(t *testing.T) {
+	srv := &ingestServer{statuses: []int{500, 500, 500}}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	var mu sync.Mutex
+	var errs []error
+	h, err := NewHTTPHandler(ts.URL, &HTTPOptions{
+		MaxRetries:       -1,
+		BreakerThreshold: 2,
+		BreakerCooldown:  time.Hour,
+		Batch: &BatchOptions{MaxDelay: time.Hour, ErrorHandler: func(err error) {
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	h.sender.now = func() time.Time { return now }
+	logger := New(h)
+	for range 3 {
+		logger.Info().Msg("down")
+		h.Flush()
+	}
+	if srv.calls != 2 || !h.Stats().CircuitOpen {
+		t.Fatalf("calls = %d, stats = %+v; want the third batch short-circuited", srv.calls, h.Stats())
+	}
+	if !errors.Is(errs[2], ErrCircuitOpen) {
+		t.Errorf("third error = %v, want ErrCircuitOpen", errs[2])
+	}
+
+	// After the cooldown one probe goes out; its failure reopens the
+	// circuit at once.
+	now = now.Add(2 * time.Hour)
+	logger.Info().Msg("probe")
+	h.Flush()
+	if srv.calls != 3 || !h.Stats().CircuitOpen {
+		t.Errorf("calls = %d, stats = %+v after a failed probe", srv.calls, h.Stats())
+	}
+	now = now.Add(2 * time.Hour)
+	logger.Info().Msg("recovered")
+	h.Flush()
+	if srv.calls != 4 || h.Stats().CircuitOpen || len(srv.bodies) != 1 {
+		t.Errorf("calls = %d, stats = %+v after a successful probe", srv.calls, h.Stats())
+	}
+	h.Close()
+}
@@ -0,0 +1,286 @@
+// Package httplog provides net/http access-log middleware for bolt.
+//
+// The middleware logs one event per request with the route template that
+// matched ("/users/{id}") instead of the raw URL path, so per-route
+// dashboards keep a bounded number of series no matter how many distinct
+// IDs appear in paths.
+//
+// With the Go 1.22+ [http.ServeMux] no configuration is needed when the
+// middleware wraps the mux directly; the pattern is read from
+// [http.Request.Pattern] after routing. If other middleware sits in
+// between and clones the request, pass the mux in [Options.Mux] so the
+// pattern is resolved up front.
+//
+// Third-party routers are supported through [Options.Route], evaluated
+// after the handler returns. Install the middleware with the router's own
+// Use method so the route is visible on the request:
+//
+//	// chi
+//	r.Use(httplog.Middleware(logger, &httplog.Options{
+//	    Route: func(r *http.Request) string {
+//	        return chi.RouteContext(r.Context()).RoutePattern()
+//	    },
+//	}))
+//
+//	// gorilla/mux
+//	router.Use(httplog.Middleware(logger, &httplog.Options{
+//	    Route: func(r *http.Request) string {
+//	        t, _ := mux.CurrentRoute(r).GetPathTemplate()
+//	        return t
+//	    },
+//	}))
+//
+// Handlers can also report the route themselves with [SetRoute].
+//
+// [NewTransport] logs outbound requests the same way, and forwards the
+// trace and correlation IDs of the request being served.
+package httplog
+
+import (
+	"context"
+	"net/http"
+	"runtime/pprof"
+	"strings"
+	"time"
+
+	"go.klarlabs.de/bolt"
+)
+
+// Options configures [Middleware]. A nil *Options uses the defaults.
+type Options struct {
+	// Mux, if set, is consulted before the request is served to resolve
+	// the matching pattern via [http.ServeMux.Handler].
+	Mux *http.ServeMux
+
+	// Route, if set, extracts the route template after the handler has
+	// run. It takes precedence over Mux and Request.Pattern.
+	Route func(r *http.Request) string
+
+	// LogPath additionally logs the raw URL path under "path". Off by
+	// default because paths are unbounded in cardinality.
+	LogPath bool
+
+	// PprofLabels tags the goroutine serving each request with pprof
+	// labels, so CPU and goroutine profiles can be sliced by the same
+	// dimensions as the logs:
+	//   - "endpoint": the route template when Mux is set or once a
+	//     handler calls SetRoute, otherwise "unmatched"; never the raw
+	//     path, which would give each ID its own label value
+	//   - "event_id": the request's event ID, as logged
+	//   - "correlation_id": the X-Correlation-ID request header, if any
+	//
+	// Labels apply to the handler goroutine and goroutines it starts.
+	PprofLabels bool
+
+	// Signature logs a canonical request signature under
+	// "request_signature" for audit trails: a SHA-256 over the method,
+	// cleaned path, sorted query keys and body hash. See
+	// [RequestSignature]. The body is buffered, up to MaxSignatureBody,
+	// before the handler runs.
+	Signature bool
+
+	// Signer chooses the signature algorithm, for example HMAC-SHA-384
+	// via [NewSigner]. Defaults to plain SHA-256.
+	Signer *Signer
+
+	// MaxSignatureBody caps the body bytes buffered for Signature.
+	// Larger bodies are signed as [UnsignedPayload]. Defaults to
+	// DefaultMaxSignatureBody.
+	MaxSignatureBody int64
+
+	// SessionReplayHeader is the request header carrying the frontend's
+	// session replay ID. Defaults to SessionReplayHeader; see
+	// [bolt.ContextWithSessionReplayID].
+	SessionReplayHeader string
+}
+
+// CorrelationHeader is the request header read for the "correlation_id"
+// pprof label. Middleware stores it in the request context, from which
+// [NewTransport] forwards it to outbound requests.
+const CorrelationHeader = "X-Correlation-ID"
+
+// SessionReplayHeader is the default request header read for the
+// "session_replay_id" field.
+const SessionReplayHeader = "X-Session-Replay-ID"
+
+type routeKey struct{}
+
+// routeHolder is a mutable slot placed in the request context so inner
+// handlers can report the route to the middleware.
+type routeHolder struct {
+	route string
+	ctx   context.Context // labelled request context when PprofLabels is on
+}
+
+// SetRoute records the route template for the current request. It is a
+// no-op when the request was not served through [Middleware].
+func SetRoute(r *http.Request, route string) {
+	if h, ok := r.Context().Value(routeKey{}).(*routeHolder); ok {
+		h.route = route
+		if h.ctx != nil {
+			pprof.SetGoroutineLabels(pprof.WithLabels(h.ctx, pprof.Labels("endpoint", routeTemplate(route))))
+		}
+	}
+}
+
+// Middleware returns net/http middleware that logs every request to
+// logger. Requests with a 5xx status are logged at ERROR, 4xx at WARN and
+// everything else at INFO.
+//
+// Each request is given an EventID with [bolt.ChildEvent]; the access-log
+// event carries it as "event_id", and handlers that derive child contexts
+// from r.Context() and log with [bolt.Event.Link] are linked back to it
+// through "parent_event_id". A handler record linked to r.Context()
+// itself before the access log is written takes the request's EventID
+// instead, and the access log then links to it.
+//
+// A valid session replay ID in the SessionReplayHeader request header is
+// stored in the request context and logged as "session_replay_id";
+// handlers add it to their own events with [bolt.Event.SessionReplay].
+func Middleware(logger *bolt.Logger, opts *Options) func(http.Handler) http.Handler {
+	if opts == nil {
+		opts = &Options{}
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			holder := &routeHolder{}
+			if opts.Mux != nil {
+				_, holder.route = opts.Mux.Handler(r)
+			}
+			ctx := bolt.ChildEvent(context.WithValue(r.Context(), routeKey{}, holder))
+			if cid := r.Header.Get(CorrelationHeader); cid != "" {
+				ctx = ContextWithCorrelationID(ctx, cid)
+			}
+			if id := r.Header.Get(opts.sessionReplayHeader()); bolt.ValidSessionReplayID(id) {
+				ctx = bolt.ContextWithSessionReplayID(ctx, id)
+			}
+			r = r.WithContext(ctx)
+
+			var signature string
+			if opts.Signature {
+				limit := opts.MaxSignatureBody
+				if limit <= 0 {
+					limit = DefaultMaxSignatureBody
+				}
+				signer := opts.Signer
+				if signer == nil {
+					signer = defaultSigner
+				}
+				signature = signer.signRequest(r, limit)
+			}
+
+			rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+			if opts.PprofLabels {
+				r = serveLabelled(next, rw, r, holder)
+			} else {
+				next.ServeHTTP(rw, r)
+			}
+
+			route := holder.route
+			if opts.Route != nil {
+				if rt := opts.Route(r); rt != "" {
+					route = rt
+				}
+			} else if r.Pattern != "" && route == "" {
+				route = r.Pattern
+			}
+
+			var e *bolt.Event
+			switch {
+			case rw.status >= 500:
+				e = logger.Error()
+			case rw.status >= 400:
+				e = logger.Warn()
+			default:
+				e = logger.Info()
+			}
+			e = e.Link(ctx).SessionReplay(ctx).Str("method", r.Method)
+			if route != "" {
+				e = e.Str("route", routeTemplate(route))
+			}
+			if opts.LogPath {
+				e = e.Str("path", r.URL.Path)
+			}
+			if signature != "" {
+				e = e.Str(SignatureField, signature)
+			}
+			e.Int("status", rw.status).
+				Int("bytes", rw.bytes).
+				Dur("duration", time.Since(start)).
+				Msg("http request")
+		})
+	}
+}
+
+func (o *Options) sessionReplayHeader() string {
+	if o.SessionReplayHeader != "" {
+		return o.SessionReplayHeader
+	}
+	return SessionReplayHeader
+}
+
+// unmatchedEndpoint is the "endpoint" pprof label of requests with no
+// known route template.
+const unmatchedEndpoint = "unmatched"
+
+// serveLabelled runs next with the request's pprof labels applied to the
+// current goroutine, restoring the previous labels afterwards. It returns
+// the request as served, whose Pattern the mux may have set.
+func serveLabelled(next http.Handler, w http.ResponseWriter, r *http.Request, holder *routeHolder) *http.Request {
+	endpoint := unmatchedEndpoint
+	if holder.route != "" {
+		endpoint = routeTemplate(holder.route)
+	}
+	labels := []string{"endpoint", endpoint}
+	if id, _, ok := bolt.EventLinkFromContext(r.Context()); ok {
+		labels = append(labels, "event_id", id.String())
+	}
+	if cid := r.Header.Get(CorrelationHeader); cid != "" {
+		labels = append(labels, "correlation_id", cid)
+	}
+	pprof.Do(r.Context(), pprof.Labels(labels...), func(ctx context.Context) {
+		holder.ctx = ctx
+		r = r.WithContext(ctx)
+		next.ServeHTTP(w, r)
+	})
+	return r
+}
+
+// routeTemplate strips the optional method prefix from a ServeMux pattern
+// ("GET /users/{id}" -> "/users/{id}"); the method is logged separately.
+func routeTemplate(pattern string) string {
+	if i := strings.IndexByte(pattern, ' '); i >= 0 {
+		return strings.TrimLeft(pattern[i+1:], " \t")
+	}
+	return pattern
+}
+
+// responseWriter captures the status code and body size.
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+func (rw *responseWriter) WriteHeader(code int) {
+	if !rw.wroteHeader {
+		rw.status = code
+		rw.wroteHeader = true
+	}
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	rw.wroteHeader = true
+	n, err := rw.ResponseWriter.Write(b)
+	rw.bytes += n
+	return n, err
+}
+
+// Unwrap exposes the underlying writer to [http.ResponseController].
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -0,0 +1,215 @@
+package httplog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime/pprof"
+	"strings"
+	"testing"
+
+	"go.klarlabs.de/bolt"
+	"go.klarlabs.de/bolt/httplog"
+)
+
+func decode(t *testing.T, raw []byte) map[string]any {
+	t.Helper()
+	var m map[string]any
+	if err := json.Unmarshal(raw, &m); err != nil {
+		t.Fatalf("invalid JSON %q: %v", raw, err)
+	}
+	return m
+}
+
+func TestMiddleware_ServeMuxPattern(t *testing.T) {
+	var buf bytes.Buffer
+	logger := bolt.New(bolt.NewJSONHandler(&buf))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("hi"))
+	})
+	h := httplog.Middleware(logger, nil)(mux)
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/12345", nil))
+
+	m := decode(t, buf.Bytes())
+	if m["route"] != "/users/{id}" {
+		t.Errorf("route = %v; want /users/{id}", m["route"])
+	}
+	if _, ok := m["path"]; ok {
+		t.Errorf("path logged without LogPath: %v", m["path"])
+	}
+	if m["method"] != "GET" || m["status"] != float64(200) || m["bytes"] != float64(2) || m["level"] != "info" {
+		t.Errorf("unexpected fields: %v", m)
+	}
+}
+
+func TestMiddleware_MuxOptionSurvivesRequestClone(t *testing.T) {
+	var buf bytes.Buffer
+	logger := bolt.New(bolt.NewJSONHandler(&buf))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orders/{id}/items", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	// Middleware in between that clones the request hides r.Pattern from
+	// the outer access logger.
+	cloning := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.ServeHTTP(w, r.Clone(r.Context()))
+	})
+	h := httplog.Middleware(logger, &httplog.Options{Mux: mux, LogPath: true})(cloning)
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders/9/items", nil))
+
+	m := decode(t, buf.Bytes())
+	if m["route"] != "/orders/{id}/items" || m["path"] != "/orders/9/items" {
+		t.Errorf("route/path = %v/%v", m["route"], m["path"])
+	}
+	if m["level"] != "warn" {
+		t.Errorf("404 logged at %v; want warn", m["level"])
+	}
+}
+
+func TestMiddleware_RouteFuncAndSetRoute(t *testing.T) {
+	var buf bytes.Buffer
+	logger := bolt.New(bolt.NewJSONHandler(&buf))
+
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		httplog.SetRoute(r, "/reported/{x}")
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	httplog.Middleware(logger, nil)(inner).
+		ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/reported/1", nil))
+	m := decode(t, buf.Bytes())
+	if m["route"] != "/reported/{x}" || m["level"] != "error" {
+		t.Errorf("SetRoute: route=%v level=%v", m["route"], m["level"])
+	}
+
+	buf.Reset()
+	opts := &httplog.Options{Route: func(*http.Request) string { return "/adapter/{x}" }}
+	httplog.Middleware(logger, opts)(inner).
+		ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/reported/1", nil))
+	if m := decode(t, buf.Bytes()); m["route"] != "/adapter/{x}" {
+		t.Errorf("Route func: route=%v", m["route"])
+	}
+}
+
+func TestMiddleware_UnmatchedRouteOmitted(t *testing.T) {
+	var buf bytes.Buffer
+	logger := bolt.New(bolt.NewJSONHandler(&buf))
+	h := httplog.Middleware(logger, nil)(http.NewServeMux())
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/nope/123", nil))
+	if m := decode(t, buf.Bytes()); m["route"] != nil {
+		t.Errorf("unmatched request logged route %v", m["route"])
+	}
+}
+
+func TestMiddleware_EventLinking(t *testing.T) {
+	var buf bytes.Buffer
+	logger := bolt.New(bolt.NewJSONHandler(&buf))
+
+	h := httplog.Middleware(logger, nil)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		logger.Info().Link(bolt.ChildEvent(r.Context())).Msg("calling downstream")
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("want 2 events, got %d: %s", len(lines), buf.String())
+	}
+	child, access := decode(t, lines[0]), decode(t, lines[1])
+	if access["event_id"] == nil || access["parent_event_id"] != nil {
+		t.Errorf("access event should be a root: %v", access)
+	}
+	if child["parent_event_id"] != access["event_id"] || child["event_id"] == access["event_id"] {
+		t.Errorf("child not linked to request: child=%v access=%v", child, access)
+	}
+}
+
+func TestMiddleware_SessionReplay(t *testing.T) {
+	var buf bytes.Buffer
+	logger := bolt.New(bolt.NewJSONHandler(&buf))
+
+	h := httplog.Middleware(logger, nil)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		logger.Info().SessionReplay(r.Context()).Msg("handled")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(httplog.SessionReplayHeader, "sess_01HX:42")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		if got := decode(t, line)[bolt.SessionReplayField]; got != "sess_01HX:42" {
+			t.Errorf("session_replay_id = %v in %s", got, line)
+		}
+	}
+
+	buf.Reset()
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(httplog.SessionReplayHeader, `bad"id`+strings.Repeat("x", 200))
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if strings.Contains(buf.String(), bolt.SessionReplayField) {
+		t.Errorf("invalid session replay ID logged: %s", buf.String())
+	}
+}
+
+func TestMiddleware_PprofLabels(t *testing.T) {
+	var buf bytes.Buffer
+	logger := bolt.New(bolt.NewJSONHandler(&buf))
+
+	labels := map[string]string{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /orders/{id}", func(_ http.ResponseWriter, r *http.Request) {
+		pprof.ForLabels(r.Context(), func(k, v string) bool {
+			labels[k] = v
+			return true
+		})
+	})
+	h := httplog.Middleware(logger, &httplog.Options{Mux: mux, PprofLabels: true})(mux)
+
+	req := httptest.NewRequest(http.MethodGet, "/orders/981", nil)
+	req.Header.Set(httplog.CorrelationHeader, "corr-1")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	m := decode(t, buf.Bytes())
+	if labels["endpoint"] != "/orders/{id}" || labels["correlation_id"] != "corr-1" {
+		t.Errorf("labels = %v", labels)
+	}
+	if labels["event_id"] == "" || labels["event_id"] != m["event_id"] {
+		t.Errorf("event_id label %q does not match logged %v", labels["event_id"], m["event_id"])
+	}
+	if m["route"] != "/orders/{id}" {
+		t.Errorf("route lost with PprofLabels: %v", m)
+	}
+}
+
+func TestMiddleware_PprofLabelsSetRoute(t *testing.T) {
+	logger := bolt.New(bolt.NewJSONHandler(&bytes.Buffer{}))
+	var profile bytes.Buffer
+	h := httplog.Middleware(logger, &httplog.Options{PprofLabels: true})(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		httplog.SetRoute(r, "GET /items/{sku}")
+		// Goroutine labels are only observable through a profile.
+		_ = pprof.Lookup("goroutine").WriteTo(&profile, 1)
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items/abc", nil))
+
+	if !strings.Contains(profile.String(), `"endpoint":"/items/{sku}"`) {
+		t.Errorf("SetRoute did not relabel the goroutine:\n%s", profile.String())
+	}
+}
+
+func TestMiddleware_PprofLabelsUnmatched(t *testing.T) {
+	logger := bolt.New(bolt.NewJSONHandler(&bytes.Buffer{}))
+	var endpoint string
+	h := httplog.Middleware(logger, &httplog.Options{PprofLabels: true})(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		endpoint, _ = pprof.Label(r.Context(), "endpoint")
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/4711/orders", nil))
+
+	if endpoint != "unmatched" {
+		t.Errorf("endpoint label = %q, want the constant for unknown routes", endpoint)
+	}
+}
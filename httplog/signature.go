@@ -0,0 +1,126 @@
+package httplog
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/hmac"
+	_ "crypto/sha256" // register hashes for NewSigner
+	_ "crypto/sha512"
+	"encoding/hex"
+	"hash"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"go.klarlabs.de/bolt"
+)
+
+// DefaultMaxSignatureBody is the largest request body hashed into a
+// signature when [Options.MaxSignatureBody] is not set.
+const DefaultMaxSignatureBody = 1 << 20
+
+// UnsignedPayload stands in for the body hash of requests whose body
+// exceeds the configured limit, following the AWS SigV4 convention.
+const UnsignedPayload = "UNSIGNED-PAYLOAD"
+
+// SignatureField is the access-log field holding the request signature.
+const SignatureField = "request_signature"
+
+// CanonicalRequest returns the canonical form of a request used by
+// [RequestSignature], one component per line:
+//
+//	POST
+//	/orders/42
+//	currency,dry_run
+//	<hex digest of the body under the signer's hash, or UNSIGNED-PAYLOAD>
+//
+// The path is cleaned ("/a/./b/" becomes "/a/b"), and query parameters
+// contribute their sorted, de-duplicated keys only, so values such as
+// tokens never reach the audit log. bodyHash is the hex digest of the
+// body under the signer's hash (SHA-256 for [RequestSignature]) or
+// [UnsignedPayload].
+func CanonicalRequest(r *http.Request, bodyHash string) string {
+	p := path.Clean("/" + r.URL.Path)
+	keys := make([]string, 0, len(r.URL.Query()))
+	for k := range r.URL.Query() {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return strings.Join([]string{r.Method, p, strings.Join(keys, ","), bodyHash}, "\n")
+}
+
+// RequestSignature returns "sha256:" followed by the hex SHA-256 of the
+// request's canonical form (see [CanonicalRequest]) with body as its
+// payload. Two requests with the same signature had the same method,
+// normalized path, query keys and body, which lets audit trails prove
+// which request caused a change without logging the body itself.
+func RequestSignature(r *http.Request, body []byte) string {
+	return defaultSigner.Sign(r, body)
+}
+
+var defaultSigner = &Signer{hash: crypto.SHA256, name: "sha256"}
+
+// Signer computes request signatures with a chosen hash and, optionally,
+// an HMAC key, so signatures cannot be forged by anyone who can read the
+// audit log. Use it through [Options.Signer].
+type Signer struct {
+	hash crypto.Hash
+	key  []byte
+	name string
+}
+
+// NewSigner returns a Signer using h for both the body hash and the
+// signature. With a non-empty key the signature is an HMAC and is
+// prefixed "hmac-<hash>:" instead of "<hash>:". h must pass
+// [bolt.CheckHash], which rejects MD5 and SHA-1 in [bolt.FIPSMode].
+func NewSigner(h crypto.Hash, key []byte) (*Signer, error) {
+	if err := bolt.CheckHash(h); err != nil {
+		return nil, err
+	}
+	name := strings.ToLower(strings.ReplaceAll(h.String(), "-", ""))
+	if len(key) > 0 {
+		name = "hmac-" + name
+	}
+	return &Signer{hash: h, key: append([]byte(nil), key...), name: name}, nil
+}
+
+// Sign returns the signature of r with body as its payload.
+func (s *Signer) Sign(r *http.Request, body []byte) string {
+	h := s.hash.New()
+	h.Write(body)
+	return s.signCanonical(CanonicalRequest(r, hex.EncodeToString(h.Sum(nil))))
+}
+
+func (s *Signer) signCanonical(canonical string) string {
+	var h hash.Hash
+	if len(s.key) > 0 {
+		h = hmac.New(s.hash.New, s.key)
+	} else {
+		h = s.hash.New()
+	}
+	h.Write([]byte(canonical))
+	return s.name + ":" + hex.EncodeToString(h.Sum(nil))
+}
+
+// signRequest computes the signature for the middleware. It buffers up
+// to limit bytes of the body and replaces r.Body so the handler still
+// sees the whole body; larger bodies are signed as UnsignedPayload.
+func (s *Signer) signRequest(r *http.Request, limit int64) string {
+	if r.Body == nil || r.Body == http.NoBody {
+		return s.Sign(r, nil)
+	}
+	buf, err := io.ReadAll(io.LimitReader(r.Body, limit+1))
+	body := r.Body
+	r.Body = readCloser{io.MultiReader(bytes.NewReader(buf), body), body}
+	if err != nil || int64(len(buf)) > limit {
+		return s.signCanonical(CanonicalRequest(r, UnsignedPayload))
+	}
+	return s.Sign(r, buf)
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
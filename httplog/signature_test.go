@@ -0,0 +1,117 @@
+package httplog_test
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/hmac"
+	_ "crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.klarlabs.de/bolt"
+	"go.klarlabs.de/bolt/httplog"
+)
+
+func TestCanonicalRequest(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/orders/./42/?dry_run=1&currency=EUR&currency=USD", nil)
+	got := httplog.CanonicalRequest(r, "abc")
+	want := "POST\n/orders/42\ncurrency,dry_run\nabc"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRequestSignature(t *testing.T) {
+	sig := func(target, body string) string {
+		return httplog.RequestSignature(httptest.NewRequest(http.MethodPut, target, nil), []byte(body))
+	}
+	base := sig("/a?x=1&y=2", `{"n":1}`)
+	if !strings.HasPrefix(base, "sha256:") || len(base) != len("sha256:")+64 {
+		t.Fatalf("malformed signature %q", base)
+	}
+	if got := sig("/a/?y=secret&x=9", `{"n":1}`); got != base {
+		t.Error("query values or key order changed the signature")
+	}
+	if got := sig("/a?x=1&y=2", `{"n":2}`); got == base {
+		t.Error("body change did not change the signature")
+	}
+	if got := sig("/b?x=1&y=2", `{"n":1}`); got == base {
+		t.Error("path change did not change the signature")
+	}
+}
+
+func TestMiddleware_Signature(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		body     string
+		unsigned bool
+	}{
+		{"small body", `{"amount":10}`, false},
+		{"oversized body", strings.Repeat("x", 64), true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := bolt.New(bolt.NewJSONHandler(&buf))
+			var seen string
+			h := httplog.Middleware(logger, &httplog.Options{Signature: true, MaxSignatureBody: 32})(
+				http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+					b, _ := io.ReadAll(r.Body)
+					seen = string(b)
+				}))
+			req := httptest.NewRequest(http.MethodPost, "/pay?token=t", strings.NewReader(tc.body))
+			h.ServeHTTP(httptest.NewRecorder(), req)
+
+			if seen != tc.body {
+				t.Errorf("handler saw body %q, want %q", seen, tc.body)
+			}
+			ref := httptest.NewRequest(http.MethodPost, "/pay?token=t", nil)
+			want := httplog.RequestSignature(ref, []byte(tc.body))
+			if tc.unsigned {
+				sum := sha256.Sum256([]byte(httplog.CanonicalRequest(ref, httplog.UnsignedPayload)))
+				want = "sha256:" + hex.EncodeToString(sum[:])
+			}
+			if got := decode(t, buf.Bytes())[httplog.SignatureField]; got != want {
+				t.Errorf("%s = %v, want %s", httplog.SignatureField, got, want)
+			}
+		})
+	}
+}
+
+func TestSigner(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/transfer?id=1", nil)
+	body := []byte(`{"amount":5}`)
+
+	s, err := httplog.NewSigner(crypto.SHA384, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := s.Sign(r, body)
+
+	bodySum := sha512.Sum384(body)
+	mac := hmac.New(sha512.New384, []byte("secret"))
+	mac.Write([]byte(httplog.CanonicalRequest(r, hex.EncodeToString(bodySum[:]))))
+	if want := "hmac-sha384:" + hex.EncodeToString(mac.Sum(nil)); got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+	if other, _ := httplog.NewSigner(crypto.SHA384, []byte("other")); other.Sign(r, body) == got {
+		t.Error("signature does not depend on the key")
+	}
+}
+
+func TestNewSignerFIPSMode(t *testing.T) {
+	bolt.SetFIPSMode(true)
+	defer bolt.SetFIPSMode(false)
+	if _, err := httplog.NewSigner(crypto.SHA1, nil); !errors.Is(err, bolt.ErrNotFIPSApproved) {
+		t.Errorf("NewSigner(SHA1) = %v; want ErrNotFIPSApproved", err)
+	}
+	if _, err := httplog.NewSigner(crypto.SHA256, []byte("k")); err != nil {
+		t.Errorf("NewSigner(SHA256) = %v", err)
+	}
+}
@@ -0,0 +1,235 @@
+package httplog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"go.klarlabs.de/bolt"
+)
+
+// DefaultRetryBackoff is the delay before a transport's first retry when
+// none is configured. It doubles with every attempt.
+const DefaultRetryBackoff = 100 * time.Millisecond
+
+// TransportOptions configures [NewTransport]. A nil *TransportOptions
+// uses the defaults.
+type TransportOptions struct {
+	// Route, if set, returns the route template of an outbound request,
+	// such as "/users/{id}", logged as "route". Requests whose context
+	// carries a route from [ContextWithRoute] use that instead.
+	Route func(r *http.Request) string
+
+	// LogPath additionally logs the raw URL path under "path". Off by
+	// default because paths are unbounded in cardinality.
+	LogPath bool
+
+	// MaxRetries is how many times an idempotent request is retried
+	// after a network error or a 429, 502, 503 or 504 response. A request
+	// is idempotent if its method is, or it has an Idempotency-Key
+	// header; its body must be replayable through GetBody. Defaults to
+	// no retries.
+	MaxRetries int
+
+	// Backoff is the delay before the first retry, doubled for every
+	// further attempt. Defaults to DefaultRetryBackoff.
+	Backoff time.Duration
+
+	// NoPropagation stops the transport from adding the traceparent and
+	// X-Correlation-ID headers to outbound requests.
+	NoPropagation bool
+}
+
+type outboundRouteKey struct{}
+
+// ContextWithRoute returns a copy of ctx carrying the route template for
+// outbound requests made with it, logged by [NewTransport] as "route":
+//
+//	req, _ := http.NewRequestWithContext(httplog.ContextWithRoute(ctx, "/users/{id}"), "GET", url, nil)
+func ContextWithRoute(ctx context.Context, route string) context.Context {
+	return context.WithValue(ctx, outboundRouteKey{}, route)
+}
+
+type correlationKey struct{}
+
+// ContextWithCorrelationID returns a copy of ctx carrying a correlation
+// ID, sent by [NewTransport] in the CorrelationHeader of outbound
+// requests. [Middleware] stores the inbound request's header this way.
+func ContextWithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationKey{}, id)
+}
+
+// CorrelationIDFromContext returns the correlation ID stored in ctx by
+// [ContextWithCorrelationID], or "".
+func CorrelationIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(correlationKey{}).(string)
+	return id
+}
+
+// transport is the RoundTripper returned by NewTransport.
+type transport struct {
+	next   http.RoundTripper
+	logger *bolt.Logger
+	opts   *TransportOptions
+}
+
+// NewTransport returns an [http.RoundTripper] that logs every outbound
+// request made through next, the client-side counterpart of
+// [Middleware]:
+//
+//	client := &http.Client{Transport: httplog.NewTransport(nil, logger, &httplog.TransportOptions{MaxRetries: 2})}
+//
+//	{"level":"info","event_id":"…","parent_event_id":"…","method":"GET","host":"api.example.com",
+//	 "route":"/users/{id}","status":200,"duration":1532000,"message":"http client request"}
+//
+// Requests failing with an error or a 5xx status are logged at ERROR, 4xx
+// at WARN and everything else at INFO; "retries" is added when the
+// request was retried and "error" when it failed. Each request is given
+// an EventID with [bolt.ChildEvent], linked to the EventID of the
+// inbound request being served, if any.
+//
+// Unless NoPropagation is set, outbound requests carry the W3C
+// traceparent header of the OpenTelemetry span in their context and the
+// correlation ID from [ContextWithCorrelationID], in the
+// CorrelationHeader; headers already set on the request are kept. A nil
+// next uses [http.DefaultTransport].
+func NewTransport(next http.RoundTripper, logger *bolt.Logger, opts *TransportOptions) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if opts == nil {
+		opts = &TransportOptions{}
+	}
+	return &transport{next: next, logger: logger, opts: opts}
+}
+
+// RoundTrip implements [http.RoundTripper].
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	ctx := bolt.ChildEvent(req.Context())
+	if !t.opts.NoPropagation {
+		req = propagate(req)
+	}
+
+	resp, retries, err := t.roundTrip(req)
+
+	var e *bolt.Event
+	switch {
+	case err != nil || resp.StatusCode >= 500:
+		e = t.logger.Error()
+	case resp.StatusCode >= 400:
+		e = t.logger.Warn()
+	default:
+		e = t.logger.Info()
+	}
+	e = e.Link(ctx).Str("method", req.Method).Str("host", req.URL.Host)
+	if route := t.route(req); route != "" {
+		e = e.Str("route", route)
+	}
+	if t.opts.LogPath {
+		e = e.Str("path", req.URL.Path)
+	}
+	if resp != nil {
+		e = e.Int("status", resp.StatusCode)
+	}
+	if retries > 0 {
+		e = e.Int("retries", retries)
+	}
+	e.Err(err).Dur("duration", time.Since(start)).Msg("http client request")
+	return resp, err
+}
+
+// roundTrip sends req, retrying as configured, and returns the final
+// response and the number of retries.
+func (t *transport) roundTrip(req *http.Request) (*http.Response, int, error) {
+	backoff := t.opts.Backoff
+	if backoff <= 0 {
+		backoff = DefaultRetryBackoff
+	}
+	for attempt := 0; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		if attempt >= t.opts.MaxRetries || !retryable(req, resp, err) {
+			return resp, attempt, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10)) // reuse the connection
+			resp.Body.Close()
+		}
+		if req.GetBody != nil {
+			body, gerr := req.GetBody()
+			if gerr != nil {
+				return nil, attempt, fmt.Errorf("httplog: replaying request body: %w", gerr)
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+		select {
+		case <-time.After(backoff << min(attempt, 20)):
+		case <-req.Context().Done():
+			return nil, attempt, req.Context().Err()
+		}
+	}
+}
+
+// retryable reports whether the outcome of req is worth another attempt.
+func retryable(req *http.Request, resp *http.Response, err error) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+	default:
+		if req.Header.Get("Idempotency-Key") == "" {
+			return false
+		}
+	}
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// route returns the route template of req, if known.
+func (t *transport) route(req *http.Request) string {
+	if route, _ := req.Context().Value(outboundRouteKey{}).(string); route != "" {
+		return route
+	}
+	if t.opts.Route != nil {
+		return t.opts.Route(req)
+	}
+	return ""
+}
+
+// propagate returns req with the trace and correlation headers from its
+// context added, cloning it only if a header is added.
+func propagate(req *http.Request) *http.Request {
+	ctx := req.Context()
+	var traceparent string
+	if sc := oteltrace.SpanContextFromContext(ctx); sc.IsValid() && req.Header.Get("traceparent") == "" {
+		traceparent = fmt.Sprintf("00-%s-%s-%02x", sc.TraceID(), sc.SpanID(), byte(sc.TraceFlags()))
+	}
+	cid := CorrelationIDFromContext(ctx)
+	if cid != "" && req.Header.Get(CorrelationHeader) != "" {
+		cid = ""
+	}
+	if traceparent == "" && cid == "" {
+		return req
+	}
+	req = req.Clone(ctx)
+	if traceparent != "" {
+		req.Header.Set("traceparent", traceparent)
+	}
+	if cid != "" {
+		req.Header.Set(CorrelationHeader, cid)
+	}
+	return req
+}
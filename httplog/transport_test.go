@@ -0,0 +1,139 @@
+package httplog_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"go.klarlabs.de/bolt"
+	"go.klarlabs.de/bolt/httplog"
+)
+
+func TestTransportLogsAndPropagates(t *testing.T) {
+	var gotHeader http.Header
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Clone()
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer backend.Close()
+
+	var buf bytes.Buffer
+	client := &http.Client{Transport: httplog.NewTransport(nil, bolt.New(bolt.NewJSONHandler(&buf)), nil)}
+
+	sc := oteltrace.NewSpanContext(oteltrace.SpanContextConfig{
+		TraceID:    oteltrace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     oteltrace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: oteltrace.FlagsSampled,
+	})
+	ctx := oteltrace.ContextWithSpanContext(context.Background(), sc)
+	ctx = httplog.ContextWithCorrelationID(ctx, "corr-1")
+	ctx = httplog.ContextWithRoute(ctx, "/users/{id}")
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, backend.URL+"/users/42", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if got := gotHeader.Get("traceparent"); got != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Errorf("traceparent = %q", got)
+	}
+	if got := gotHeader.Get(httplog.CorrelationHeader); got != "corr-1" {
+		t.Errorf("%s = %q", httplog.CorrelationHeader, got)
+	}
+	if req.Header.Get("traceparent") != "" {
+		t.Error("caller's request was modified")
+	}
+	m := decode(t, buf.Bytes())
+	if m["level"] != "warn" || m["method"] != "GET" || m["host"] != strings.TrimPrefix(backend.URL, "http://") ||
+		m["route"] != "/users/{id}" || m["status"] != float64(404) || m["message"] != "http client request" || m["event_id"] == nil {
+		t.Errorf("unexpected fields: %v", m)
+	}
+	if _, ok := m["retries"]; ok {
+		t.Errorf("retries logged for a single attempt: %v", m)
+	}
+}
+
+func TestTransportRetries(t *testing.T) {
+	var calls atomic.Int32
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	var buf bytes.Buffer
+	client := &http.Client{Transport: httplog.NewTransport(nil, bolt.New(bolt.NewJSONHandler(&buf)),
+		&httplog.TransportOptions{MaxRetries: 3, Backoff: time.Millisecond})}
+
+	req, _ := http.NewRequest(http.MethodPut, backend.URL, strings.NewReader("payload"))
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	m := decode(t, buf.Bytes())
+	if m["status"] != float64(200) || m["retries"] != float64(2) || m["level"] != "info" {
+		t.Errorf("unexpected fields: %v", m)
+	}
+
+	// POST without an Idempotency-Key is never retried.
+	calls.Store(0)
+	buf.Reset()
+	resp, err = client.Post(backend.URL, "text/plain", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if calls.Load() != 1 {
+		t.Errorf("POST sent %d times, want 1", calls.Load())
+	}
+	if m := decode(t, buf.Bytes()); m["level"] != "error" || m["status"] != float64(503) {
+		t.Errorf("unexpected fields: %v", m)
+	}
+}
+
+func TestTransportError(t *testing.T) {
+	var buf bytes.Buffer
+	client := &http.Client{Transport: httplog.NewTransport(nil, bolt.New(bolt.NewJSONHandler(&buf)), nil)}
+	if _, err := client.Get("http://127.0.0.1:1/"); err == nil {
+		t.Fatal("expected a connection error")
+	}
+	m := decode(t, buf.Bytes())
+	if m["level"] != "error" || m["error"] == nil || m["status"] != nil {
+		t.Errorf("unexpected fields: %v", m)
+	}
+}
+
+func TestMiddlewareForwardsCorrelationID(t *testing.T) {
+	var got string
+	backend := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get(httplog.CorrelationHeader)
+	}))
+	defer backend.Close()
+
+	logger := bolt.New(bolt.NewJSONHandler(&bytes.Buffer{}))
+	client := &http.Client{Transport: httplog.NewTransport(nil, logger, nil)}
+	h := httplog.Middleware(logger, nil)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		req, _ := http.NewRequestWithContext(r.Context(), http.MethodGet, backend.URL, nil)
+		if resp, err := client.Do(req); err == nil {
+			resp.Body.Close()
+		}
+	}))
+	in := httptest.NewRequest(http.MethodGet, "/", nil)
+	in.Header.Set(httplog.CorrelationHeader, "corr-9")
+	h.ServeHTTP(httptest.NewRecorder(), in)
+	if got != "corr-9" {
+		t.Errorf("forwarded correlation ID = %q, want corr-9", got)
+	}
+}
@@ -0,0 +1,54 @@
+package bolt
+
+// IdempotencyKeyField is the field added by [IdempotencyKeyHook].
+const IdempotencyKeyField = "idempotency_key"
+
+// IdempotencyKeyHook is an [EventHook] that stamps every event with a
+// unique, time-sortable [EventID] under "idempotency_key". The key is part
+// of the encoded record, so every redelivery of that record by an
+// at-least-once sink (Kafka producer retries, Splunk HEC resends, an
+// agent re-shipping a file after a crash) carries the same key, and
+// consumers can drop duplicates without hashing the whole record.
+//
+// Register it after any sampling or filtering hooks so keys are only
+// generated for events that are written. Events that already carry the
+// field keep their key. See docs/how-to/deduplicate-events.md for
+// sink-side guidance.
+type IdempotencyKeyHook struct{}
+
+// NewIdempotencyKeyHook returns an IdempotencyKeyHook.
+func NewIdempotencyKeyHook() *IdempotencyKeyHook {
+	return &IdempotencyKeyHook{}
+}
+
+// Run implements [EventHook].
+func (*IdempotencyKeyHook) Run(e *Event, _ string) bool {
+	if _, ok := idempotencyKey(e); !ok {
+		e.appendEventID(IdempotencyKeyField, NewEventID())
+	}
+	return true
+}
+
+// IdempotencyKey returns the idempotency key of a finished JSON record,
+// for sinks to use as a message key or document ID. It reports false if
+// the record has no top-level field named IdempotencyKeyField.
+// [NATSHandler] uses it as the JetStream message ID.
+func IdempotencyKey(record []byte) (string, bool) {
+	e := Event{buf: record}
+	key, ok := idempotencyKey(&e)
+	return string(key), ok
+}
+
+// idempotencyKey returns the raw, still escaped key of e. Only top-level
+// fields count, so an object that happens to nest a field of the same
+// name does not hide a missing key.
+func idempotencyKey(e *Event) (key []byte, ok bool) {
+	e.WalkFields(func(k, v []byte) bool {
+		if string(k) != IdempotencyKeyField {
+			return true
+		}
+		key, ok = v, len(v) > 0
+		return false
+	})
+	return key, ok
+}
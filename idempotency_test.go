@@ -0,0 +1,61 @@
+package bolt
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestIdempotencyKeyHook(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewJSONHandler(&buf)).AddEventHook(NewIdempotencyKeyHook())
+
+	logger.Info().Msg("a")
+	logger.Info().Msg("b")
+	logger.Info().Str(IdempotencyKeyField, "caller-supplied").Msg("c")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	k1, ok1 := IdempotencyKey([]byte(lines[0]))
+	k2, ok2 := IdempotencyKey([]byte(lines[1]))
+	if !ok1 || !ok2 || len(k1) != 26 || k1 == k2 {
+		t.Errorf("keys = %q, %q", k1, k2)
+	}
+	if k, _ := IdempotencyKey([]byte(lines[2])); k != "caller-supplied" || strings.Count(lines[2], IdempotencyKeyField) != 1 {
+		t.Errorf("existing key overwritten or duplicated: %s", lines[2])
+	}
+	if _, ok := IdempotencyKey([]byte(`{"level":"info","message":"x"}`)); ok {
+		t.Error("key reported for record without one")
+	}
+}
+
+func TestIdempotencyKeyTopLevelOnly(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewJSONHandler(&buf)).AddEventHook(NewIdempotencyKeyHook())
+
+	logger.Info().Dict("upstream", func(d *Event) { d.Str(IdempotencyKeyField, "nested") }).Msg("a")
+	k, ok := IdempotencyKey(buf.Bytes())
+	if !ok || k == "nested" || len(k) != 26 {
+		t.Errorf("key = %q, %v in %s", k, ok, buf.String())
+	}
+	if _, ok := IdempotencyKey([]byte(`{"level":"info","req":{"idempotency_key":"x"}}`)); ok {
+		t.Error("nested key reported as the record's key")
+	}
+}
+
+func TestIdempotencyKeyHookAllocs(t *testing.T) {
+	if raceDetectorEnabled {
+		t.Skip("allocation counts are not meaningful under -race")
+	}
+	logger := New(NewJSONHandler(io.Discard)).AddEventHook(NewIdempotencyKeyHook())
+	allocs := testing.AllocsPerRun(100, func() {
+		logger.Info().Str("k", "v").Msg("m")
+	})
+	if allocs != 0 {
+		t.Errorf("allocs per event = %v, want 0", allocs)
+	}
+	record := []byte(`{"level":"info","idempotency_key":"01J9Z3M5A1QW4Y7R2K8D6E0FXC"}`)
+	if allocs := testing.AllocsPerRun(100, func() { _, _ = IdempotencyKey(record) }); allocs > 1 {
+		t.Errorf("IdempotencyKey allocated %v times, want at most the string", allocs)
+	}
+}
@@ -0,0 +1,156 @@
+package bolt
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// callRecorder records every Write call separately.
+type callRecorder struct {
+	mu    sync.Mutex
+	calls [][]byte
+}
+
+func (r *callRecorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	r.calls = append(r.calls, append([]byte(nil), p...))
+	r.mu.Unlock()
+	return len(p), nil
+}
+
+func (r *callRecorder) joined() []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return bytes.Join(r.calls, nil)
+}
+
+// checkWholeRecords fails unless every Write call ends a record.
+func checkWholeRecords(t *testing.T, r *callRecorder) {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, c := range r.calls {
+		if len(c) == 0 || c[len(c)-1] != '\n' {
+			t.Fatalf("write %d does not end on a record boundary: %q", i, c)
+		}
+	}
+}
+
+func hammer(n int, log func(g, i int)) {
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range n {
+				log(g, i)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestHandlersWriteOneCallPerRecord(t *testing.T) {
+	t.Run("console", func(t *testing.T) {
+		var rec callRecorder
+		h := NewConsoleHandler(&rec).SetMultilineFields("sql")
+		logger := New(h)
+		hammer(200, func(g, i int) {
+			logger.Info().Int("g", g).Str("sql", "SELECT 1\nFROM t").Msg("query")
+		})
+		checkWholeRecords(t, &rec)
+		if got := len(rec.calls); got != 8*200 {
+			t.Errorf("got %d writes for %d records", got, 8*200)
+		}
+	})
+
+	t.Run("slog", func(t *testing.T) {
+		var rec callRecorder
+		logger := slog.New(NewSlogHandler(&rec, nil))
+		hammer(200, func(g, i int) { logger.Info("msg", "g", g, "i", i) })
+		checkWholeRecords(t, &rec)
+	})
+
+	t.Run("buffered", func(t *testing.T) {
+		var rec callRecorder
+		bw := NewBufferedWriter(&rec, &BufferedWriterOptions{Size: 1000, FlushInterval: time.Millisecond})
+		a := New(NewJSONHandler(bw))
+		b := New(NewJSONHandler(bw))
+		hammer(300, func(g, i int) {
+			l := a
+			if g%2 == 1 {
+				l = b
+			}
+			l.Info().Int("g", g).Int("i", i).Str("pad", strings.Repeat("x", i%97)).Msg("record")
+		})
+		if err := bw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		checkWholeRecords(t, &rec)
+		lines := bytes.Split(bytes.TrimSuffix(rec.joined(), []byte{'\n'}), []byte{'\n'})
+		if len(lines) != 8*300 {
+			t.Fatalf("got %d records, want %d", len(lines), 8*300)
+		}
+		for _, line := range lines {
+			if !json.Valid(line) {
+				t.Fatalf("torn record %q", line)
+			}
+		}
+	})
+}
+
+func TestBufferedWriter(t *testing.T) {
+	var rec callRecorder
+	bw := NewBufferedWriter(&rec, &BufferedWriterOptions{Size: 16, FlushInterval: -1})
+
+	_, _ = bw.Write([]byte("aaaaa\n"))
+	_, _ = bw.Write([]byte("bbbbb\n"))
+	if len(rec.calls) != 0 {
+		t.Fatalf("flushed early: %q", rec.calls)
+	}
+	_, _ = bw.Write([]byte("ccccc\n")) // does not fit: flushes a+b first
+	_, _ = bw.Write([]byte(strings.Repeat("d", 20) + "\n"))
+	if err := bw.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"aaaaa\nbbbbb\n", "ccccc\n", strings.Repeat("d", 20) + "\n"}
+	if len(rec.calls) != len(want) {
+		t.Fatalf("writes = %q, want %q", rec.calls, want)
+	}
+	for i, w := range want {
+		if string(rec.calls[i]) != w {
+			t.Errorf("write %d = %q, want %q", i, rec.calls[i], w)
+		}
+	}
+
+	_, _ = bw.Write([]byte("e\n"))
+	if err := bw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if last := rec.calls[len(rec.calls)-1]; string(last) != "e\n" {
+		t.Errorf("Close did not flush, last write %q", last)
+	}
+	if _, err := bw.Write([]byte("f\n")); !errors.Is(err, ErrWriterClosed) {
+		t.Errorf("Write after Close = %v", err)
+	}
+}
+
+func TestBufferedWriterInterval(t *testing.T) {
+	var rec callRecorder
+	bw := NewBufferedWriter(&rec, &BufferedWriterOptions{FlushInterval: 5 * time.Millisecond})
+	defer bw.Close()
+	_, _ = bw.Write([]byte("x\n"))
+	deadline := time.Now().Add(2 * time.Second)
+	for len(rec.joined()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("record not flushed by the interval")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
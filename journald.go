@@ -0,0 +1,266 @@
+//go:build linux
+
+package bolt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// DefaultJournaldSocket is the socket of the journal's native protocol.
+const DefaultJournaldSocket = "/run/systemd/journal/socket"
+
+// JournaldOptions configures [NewJournaldHandler]. A nil
+// *JournaldOptions uses the defaults.
+type JournaldOptions struct {
+	// Socket is the path of the journal socket. Defaults to
+	// DefaultJournaldSocket.
+	Socket string
+
+	// Identifier is sent as SYSLOG_IDENTIFIER, by which journalctl -t
+	// filters. Defaults to the executable name.
+	Identifier string
+
+	// Severities maps levels to PRIORITY with its Syslog map. Defaults to
+	// DefaultSeverities.
+	Severities *Severities
+}
+
+// JournaldHandler writes events to the systemd journal over its native
+// protocol, so that they keep their fields instead of arriving as lines
+// of text on stdout:
+//
+//	if bolt.JournaldConnected() {
+//	    h, err := bolt.NewJournaldHandler(nil)
+//	    ...
+//	}
+//
+// The message is sent as MESSAGE and the level as PRIORITY, mapped
+// through [Severities.Syslog]. Every other field is sent under its key
+// in upper case, with characters outside A-Z, 0-9 and '_' replaced by
+// '_', so that user_id becomes USER_ID; strings are sent unquoted and
+// other values as JSON. A "caller" field of the form file:line is sent as
+// CODE_FILE and CODE_LINE.
+//
+// Entries too large for a datagram are written to a sealed memfd whose
+// descriptor is passed to the journal, as sd_journal_send does.
+// JournaldHandler is safe for concurrent use. It is only available on
+// Linux.
+type JournaldHandler struct {
+	addr  *net.UnixAddr
+	ident []byte
+	sev   *SeverityMap[int]
+
+	mu     sync.Mutex
+	conn   *net.UnixConn
+	closed bool
+	msg    []byte
+	val    []byte
+	spans  []FieldSpan
+}
+
+// NewJournaldHandler returns a handler writing to the journal. It fails
+// if the journal socket cannot be reached.
+func NewJournaldHandler(opts *JournaldOptions) (*JournaldHandler, error) {
+	if opts == nil {
+		opts = &JournaldOptions{}
+	}
+	socket := opts.Socket
+	if socket == "" {
+		socket = DefaultJournaldSocket
+	}
+	h := &JournaldHandler{
+		addr: &net.UnixAddr{Name: socket, Net: "unixgram"},
+		sev:  &DefaultSeverities.Syslog,
+	}
+	ident := opts.Identifier
+	if ident == "" {
+		ident = filepath.Base(os.Args[0])
+	}
+	h.ident = []byte(ident)
+	if opts.Severities != nil {
+		h.sev = &opts.Severities.Syslog
+	}
+	conn, err := net.DialUnix("unixgram", nil, h.addr)
+	if err != nil {
+		return nil, fmt.Errorf("bolt: journald: %w", err)
+	}
+	h.conn = conn
+	return h, nil
+}
+
+// JournaldConnected reports whether stderr is connected to the journal,
+// as it is for services started by systemd, by comparing it with the
+// JOURNAL_STREAM environment variable. Programs use it to switch to a
+// [JournaldHandler] when they run under systemd.
+func JournaldConnected() bool {
+	dev, ino, ok := strings.Cut(os.Getenv("JOURNAL_STREAM"), ":")
+	if !ok {
+		return false
+	}
+	var st unix.Stat_t
+	if err := unix.Fstat(int(os.Stderr.Fd()), &st); err != nil {
+		return false
+	}
+	return strconv.FormatUint(st.Dev, 10) == dev && strconv.FormatUint(st.Ino, 10) == ino
+}
+
+// Write implements [Handler].
+func (h *JournaldHandler) Write(e *Event) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return ErrHandlerClosed
+	}
+	h.msg = h.appendEntry(h.msg[:0], e)
+	err := h.send(h.msg)
+	if err != nil && !errors.Is(err, syscall.EMSGSIZE) && !errors.Is(err, syscall.ENOBUFS) {
+		// The journal may have restarted; its socket is then a new one.
+		if conn, derr := net.DialUnix("unixgram", nil, h.addr); derr == nil {
+			h.conn.Close()
+			h.conn = conn
+			err = h.send(h.msg)
+		}
+	}
+	if errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS) {
+		err = h.sendMemfd(h.msg)
+	}
+	if err != nil {
+		return fmt.Errorf("bolt: journald: %w", err)
+	}
+	return nil
+}
+
+func (h *JournaldHandler) send(msg []byte) error {
+	_, err := h.conn.Write(msg)
+	return err
+}
+
+// sendMemfd passes msg in a sealed memory file, for entries larger than
+// the socket accepts in one datagram.
+func (h *JournaldHandler) sendMemfd(msg []byte) error {
+	fd, err := unix.MemfdCreate("bolt-journal", unix.MFD_CLOEXEC|unix.MFD_ALLOW_SEALING)
+	if err != nil {
+		return err
+	}
+	f := os.NewFile(uintptr(fd), "bolt-journal")
+	defer f.Close()
+	if _, err := f.Write(msg); err != nil {
+		return err
+	}
+	if _, err := unix.FcntlInt(f.Fd(), unix.F_ADD_SEALS, unix.F_SEAL_SHRINK|unix.F_SEAL_GROW|unix.F_SEAL_WRITE|unix.F_SEAL_SEAL); err != nil {
+		return err
+	}
+	// WriteMsgUnix refuses connected datagram sockets.
+	rc, err := h.conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var serr error
+	if err := rc.Write(func(fd uintptr) bool {
+		serr = unix.Sendmsg(int(fd), nil, unix.UnixRights(int(f.Fd())), nil, 0)
+		return serr != unix.EAGAIN
+	}); err != nil {
+		return err
+	}
+	return serr
+}
+
+// Close closes the socket. Writes after Close return ErrHandlerClosed.
+func (h *JournaldHandler) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil
+	}
+	h.closed = true
+	return h.conn.Close()
+}
+
+// appendEntry appends e in the journal's native format.
+func (h *JournaldHandler) appendEntry(dst []byte, e *Event) []byte {
+	dst = append(dst, "PRIORITY="...)
+	dst = strconv.AppendInt(dst, int64(h.sev.Of(e.Level())), 10)
+	dst = append(dst, "\nSYSLOG_IDENTIFIER"...)
+	dst = appendJournalValue(dst, h.ident)
+
+	buf := e.Buffer()
+	h.spans = e.FieldSpans(h.spans[:0])
+	for _, s := range h.spans {
+		key, value := s.Key(buf), s.Value(buf)
+		h.val = h.val[:0]
+		if len(value) > 1 && value[0] == '"' {
+			h.val = appendJSONUnescaped(h.val, value[1:len(value)-1])
+		} else {
+			h.val = append(h.val, value...)
+		}
+		switch string(key) {
+		case "level":
+			continue
+		case "message":
+			dst = append(dst, "MESSAGE"...)
+			dst = appendJournalValue(dst, h.val)
+			continue
+		case "caller":
+			if i := bytes.LastIndexByte(h.val, ':'); i > 0 {
+				dst = append(dst, "CODE_FILE"...)
+				dst = appendJournalValue(dst, h.val[:i])
+				dst = append(dst, "CODE_LINE"...)
+				dst = appendJournalValue(dst, h.val[i+1:])
+				continue
+			}
+		}
+		dst = appendJournalName(dst, key)
+		dst = appendJournalValue(dst, h.val)
+	}
+	return dst
+}
+
+// appendJournalName appends key as a journal field name: at most 64
+// characters from A-Z, 0-9 and '_', starting with a letter. Names
+// starting with '_' are reserved for fields the journal adds itself.
+func appendJournalName(dst, key []byte) []byte {
+	start := len(dst)
+	if len(key) == 0 || !(key[0] >= 'a' && key[0] <= 'z' || key[0] >= 'A' && key[0] <= 'Z') {
+		dst = append(dst, "F_"...)
+	}
+	for _, c := range key {
+		if len(dst)-start == 64 {
+			break
+		}
+		switch {
+		case c >= 'a' && c <= 'z':
+			c -= 'a' - 'A'
+		case c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		default:
+			c = '_'
+		}
+		dst = append(dst, c)
+	}
+	return dst
+}
+
+// appendJournalValue appends the value of a field whose name was just
+// appended, in the binary form when it contains a newline.
+func appendJournalValue(dst, value []byte) []byte {
+	if bytes.IndexByte(value, '\n') < 0 {
+		dst = append(dst, '=')
+		dst = append(dst, value...)
+		return append(dst, '\n')
+	}
+	dst = append(dst, '\n')
+	dst = binary.LittleEndian.AppendUint64(dst, uint64(len(value)))
+	dst = append(dst, value...)
+	return append(dst, '\n')
+}
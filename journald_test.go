@@ -0,0 +1,166 @@
+//go:build linux
+
+package bolt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// fakeJournal listens on a unixgram socket and decodes native-protocol
+// entries, reading memfd-passed entries from their descriptor.
+func fakeJournal(t *testing.T) (string, func() map[string]string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "journal.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	buf := make([]byte, 1<<20)
+	oob := make([]byte, unix.CmsgSpace(4))
+	return path, func() map[string]string {
+		t.Helper()
+		_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data := buf[:n]
+		if oobn > 0 {
+			msgs, err := unix.ParseSocketControlMessage(oob[:oobn])
+			if err != nil {
+				t.Fatal(err)
+			}
+			fds, err := unix.ParseUnixRights(&msgs[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			f := os.NewFile(uintptr(fds[0]), "memfd")
+			defer f.Close()
+			// The journal maps the file; its offset is at the end.
+			if data, err = io.ReadAll(io.NewSectionReader(f, 0, 1<<30)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return parseJournalEntry(t, data)
+	}
+}
+
+func parseJournalEntry(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	fields := map[string]string{}
+	for len(data) > 0 {
+		i := bytes.IndexAny(data, "=\n")
+		if i < 0 {
+			t.Fatalf("malformed entry %q", data)
+		}
+		name := string(data[:i])
+		if data[i] == '=' {
+			end := bytes.IndexByte(data, '\n')
+			fields[name] = string(data[i+1 : end])
+			data = data[end+1:]
+			continue
+		}
+		n := binary.LittleEndian.Uint64(data[i+1:])
+		fields[name] = string(data[i+9 : i+9+int(n)])
+		data = data[i+9+int(n)+1:]
+	}
+	return fields
+}
+
+func TestJournaldHandler(t *testing.T) {
+	path, read := fakeJournal(t)
+	h, err := NewJournaldHandler(&JournaldOptions{Socket: path, Identifier: "api"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	logger := New(h)
+
+	logger.Warn().Str("user_id", "42").Int("attempt", 3).Str("trace", "line1\nline2").Str("caller", "auth/login.go:87").Msg("login failed")
+	got := read()
+	want := map[string]string{
+		"PRIORITY":          "4",
+		"SYSLOG_IDENTIFIER": "api",
+		"MESSAGE":           "login failed",
+		"USER_ID":           "42",
+		"ATTEMPT":           "3",
+		"TRACE":             "line1\nline2",
+		"CODE_FILE":         "auth/login.go",
+		"CODE_LINE":         "87",
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+	if _, ok := got["LEVEL"]; ok {
+		t.Error("level sent as a field")
+	}
+
+	logger.Error().Str("_private", "x").Str("http.status-code", "500").Msg("")
+	got = read()
+	if got["PRIORITY"] != "3" || got["F__PRIVATE"] != "x" || got["HTTP_STATUS_CODE"] != "500" {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestJournaldHandlerMemfd(t *testing.T) {
+	path, read := fakeJournal(t)
+	h, err := NewJournaldHandler(&JournaldOptions{Socket: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+
+	// Beyond the default datagram size limit of about 208 KiB.
+	big := strings.Repeat("x", 60<<10)
+	New(h).SetErrorHandler(func(err error) { t.Error(err) }).Info().Str("a", big).Str("b", big).Str("c", big).Str("d", big).Str("e", big).Msg("large")
+	got := read()
+	if got["MESSAGE"] != "large" || got["A"] != big || got["E"] != big {
+		t.Errorf("got MESSAGE %q and %d-byte fields", got["MESSAGE"], len(got["A"]))
+	}
+}
+
+func TestJournaldHandlerClosed(t *testing.T) {
+	path, _ := fakeJournal(t)
+	h, err := NewJournaldHandler(&JournaldOptions{Socket: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Write(&Event{}); err != ErrHandlerClosed {
+		t.Errorf("Write after Close = %v", err)
+	}
+	if _, err := NewJournaldHandler(&JournaldOptions{Socket: filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Error("missing socket accepted")
+	}
+}
+
+func TestJournaldConnected(t *testing.T) {
+	var st unix.Stat_t
+	if err := unix.Fstat(int(os.Stderr.Fd()), &st); err != nil {
+		t.Skip(err)
+	}
+	t.Setenv("JOURNAL_STREAM", "")
+	if JournaldConnected() {
+		t.Error("connected without JOURNAL_STREAM")
+	}
+	t.Setenv("JOURNAL_STREAM", strconv.FormatUint(st.Dev, 10)+":"+strconv.FormatUint(st.Ino, 10))
+	if !JournaldConnected() {
+		t.Error("not connected with matching JOURNAL_STREAM")
+	}
+}
@@ -0,0 +1,120 @@
+package bolt
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestJSONHandlerWorkersKeepLoggerOrder(t *testing.T) {
+	var buf ThreadSafeBuffer
+	h := NewJSONHandlerWithOptions(&buf, &JSONOptions{Workers: 3, QueueSize: 4})
+	root := New(h)
+
+	var wg sync.WaitGroup
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		logger := root.With().Str("logger", name).Logger()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 200 {
+				logger.Info().Int("seq", i).Msg("step")
+			}
+		}()
+	}
+	wg.Wait()
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	next := map[string]int{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var rec struct {
+			Logger string `json:"logger"`
+			Seq    int    `json:"seq"`
+		}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("torn record %q: %v", line, err)
+		}
+		if rec.Seq != next[rec.Logger] {
+			t.Fatalf("logger %s: seq %d, want %d", rec.Logger, rec.Seq, next[rec.Logger])
+		}
+		next[rec.Logger]++
+	}
+	if len(next) != 5 || next["a"] != 200 || next["e"] != 200 {
+		t.Errorf("records per logger = %v", next)
+	}
+	if err := h.Write(&Event{}); err != ErrHandlerClosed {
+		t.Errorf("Write after Close = %v", err)
+	}
+}
+
+// stallWriter blocks every write until release is closed.
+type stallWriter struct {
+	ThreadSafeBuffer
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func (w *stallWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() { close(w.started) })
+	<-w.release
+	return w.ThreadSafeBuffer.Write(p)
+}
+
+func TestJSONHandlerWorkersDropWhenFull(t *testing.T) {
+	w := &stallWriter{started: make(chan struct{}), release: make(chan struct{})}
+	h := NewJSONHandlerWithOptions(w, &JSONOptions{Workers: 1, QueueSize: 2, DropWhenFull: true})
+	var errs []error
+	logger := New(h).SetErrorHandler(func(err error) { errs = append(errs, err) })
+
+	logger.Info().Msg("first")
+	<-w.started // the worker holds "first" in a stalled write
+	for range 9 {
+		logger.Info().Msg("more")
+	}
+	if h.Dropped() != 7 || len(errs) != 7 || !errors.Is(errs[0], ErrQueueFull) {
+		t.Errorf("dropped %d, errors %v", h.Dropped(), errs)
+	}
+
+	close(w.release)
+	h.Flush()
+	if n := strings.Count(w.String(), "\n"); n != 3 {
+		t.Errorf("wrote %d records, want 3", n)
+	}
+	_ = h.Close()
+}
+
+func TestJSONHandlerWorkersFatalAndErrors(t *testing.T) {
+	var buf ThreadSafeBuffer
+	var reported []error
+	h := NewJSONHandlerWithOptions(&buf, &JSONOptions{
+		Workers:      2,
+		ErrorHandler: func(err error) { reported = append(reported, err) },
+	})
+	defer h.Close()
+	logger := New(h)
+
+	for range 50 {
+		logger.Info().Msg("queued")
+	}
+	logger.Fatal().Msg("fatal") // exitFunc is stubbed for tests
+	out := buf.String()
+	if strings.Count(out, "queued") != 50 || !strings.HasSuffix(out, "\"message\":\"fatal\"}\n") {
+		t.Errorf("FATAL written before the queue drained:\n%s", out)
+	}
+
+	failing := NewJSONHandlerWithOptions(errWriter{}, &JSONOptions{Workers: 1, ErrorHandler: func(err error) { reported = append(reported, err) }})
+	New(failing).Info().Msg("lost")
+	_ = failing.Close()
+	if len(reported) != 1 || reported[0].Error() != "disk full" {
+		t.Errorf("reported = %v", reported)
+	}
+}
+
+type errWriter struct{}
+
+func (errWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
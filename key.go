@@ -0,0 +1,121 @@
+package bolt
+
+import (
+	"math"
+	"time"
+)
+
+// Key is a field key bound to a value type, so commonly used fields are
+// declared once and misuse is a compile error:
+//
+//	var (
+//	    UserID = bolt.IntKey("user_id")
+//	    Tenant = bolt.StrKey("tenant")
+//	)
+//
+//	logger.Info().Field(UserID.Value(42)).Field(Tenant.Value("acme")).Msg("login")
+//	logger.Info().Field(UserID.Value("42")) // does not compile
+//
+// Go methods cannot take type parameters, so the typed value is formed by
+// the key and then passed to [Event.Field]. Both steps compile down to the
+// ordinary field methods and do not allocate. Create keys with the
+// constructors below; the zero Key is not usable.
+type Key[T any] struct {
+	name string
+	mk   func(key string, v T) Field
+}
+
+// Name returns the key's JSON name.
+func (k Key[T]) Name() string { return k.name }
+
+// Value pairs the key with v for [Event.Field].
+func (k Key[T]) Value(v T) Field { return k.mk(k.name, v) }
+
+// Field is a key and a typed value produced by [Key.Value].
+type Field struct {
+	key  string
+	kind fieldKind
+	num  uint64
+	str  string
+}
+
+type fieldKind uint8
+
+const (
+	fieldStr fieldKind = iota
+	fieldInt
+	fieldInt64
+	fieldUint64
+	fieldFloat64
+	fieldBool
+	fieldDur
+)
+
+// StrKey returns a string-valued key.
+func StrKey(name string) Key[string] {
+	return Key[string]{name, func(k, v string) Field { return Field{key: k, kind: fieldStr, str: v} }}
+}
+
+// IntKey returns an int-valued key.
+func IntKey(name string) Key[int] {
+	return Key[int]{name, func(k string, v int) Field {
+		return Field{key: k, kind: fieldInt, num: uint64(v)} // #nosec G115 -- bit pattern restored in Field
+	}}
+}
+
+// Int64Key returns an int64-valued key.
+func Int64Key(name string) Key[int64] {
+	return Key[int64]{name, func(k string, v int64) Field {
+		return Field{key: k, kind: fieldInt64, num: uint64(v)} // #nosec G115 -- bit pattern restored in Field
+	}}
+}
+
+// Uint64Key returns a uint64-valued key.
+func Uint64Key(name string) Key[uint64] {
+	return Key[uint64]{name, func(k string, v uint64) Field { return Field{key: k, kind: fieldUint64, num: v} }}
+}
+
+// Float64Key returns a float64-valued key.
+func Float64Key(name string) Key[float64] {
+	return Key[float64]{name, func(k string, v float64) Field {
+		return Field{key: k, kind: fieldFloat64, num: math.Float64bits(v)}
+	}}
+}
+
+// BoolKey returns a bool-valued key.
+func BoolKey(name string) Key[bool] {
+	return Key[bool]{name, func(k string, v bool) Field {
+		f := Field{key: k, kind: fieldBool}
+		if v {
+			f.num = 1
+		}
+		return f
+	}}
+}
+
+// DurKey returns a time.Duration-valued key.
+func DurKey(name string) Key[time.Duration] {
+	return Key[time.Duration]{name, func(k string, v time.Duration) Field {
+		return Field{key: k, kind: fieldDur, num: uint64(v)} // #nosec G115 -- bit pattern restored in Field
+	}}
+}
+
+// Field adds a typed field built with [Key.Value].
+func (e *Event) Field(f Field) *Event {
+	switch f.kind {
+	case fieldInt:
+		return e.Int(f.key, int(f.num)) // #nosec G115 -- round trip of IntKey
+	case fieldInt64:
+		return e.Int64(f.key, int64(f.num)) // #nosec G115 -- round trip of Int64Key
+	case fieldUint64:
+		return e.Uint64(f.key, f.num)
+	case fieldFloat64:
+		return e.Float64(f.key, math.Float64frombits(f.num))
+	case fieldBool:
+		return e.Bool(f.key, f.num != 0)
+	case fieldDur:
+		return e.Dur(f.key, time.Duration(f.num)) // #nosec G115 -- round trip of DurKey
+	default:
+		return e.Str(f.key, f.str)
+	}
+}
@@ -0,0 +1,60 @@
+package bolt
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"testing"
+	"time"
+)
+
+var (
+	testUserID  = IntKey("user_id")
+	testTenant  = StrKey("tenant")
+	testOffset  = Int64Key("offset")
+	testSeq     = Uint64Key("seq")
+	testRatio   = Float64Key("ratio")
+	testCached  = BoolKey("cached")
+	testLatency = DurKey("latency")
+)
+
+func TestKeyFieldsMatchFieldMethods(t *testing.T) {
+	var typed, plain bytes.Buffer
+	New(NewJSONHandler(&typed)).Info().
+		Field(testUserID.Value(-42)).
+		Field(testTenant.Value("a\"b")).
+		Field(testOffset.Value(math.MinInt64)).
+		Field(testSeq.Value(math.MaxUint64)).
+		Field(testRatio.Value(0.25)).
+		Field(testCached.Value(true)).
+		Field(testLatency.Value(1500 * time.Millisecond)).
+		Msg("m")
+	New(NewJSONHandler(&plain)).Info().
+		Int("user_id", -42).
+		Str("tenant", "a\"b").
+		Int64("offset", math.MinInt64).
+		Uint64("seq", math.MaxUint64).
+		Float64("ratio", 0.25).
+		Bool("cached", true).
+		Dur("latency", 1500*time.Millisecond).
+		Msg("m")
+	if typed.String() != plain.String() {
+		t.Errorf("typed keys:\n%s\nfield methods:\n%s", typed.String(), plain.String())
+	}
+	if testUserID.Name() != "user_id" {
+		t.Errorf("Name() = %q", testUserID.Name())
+	}
+}
+
+func TestKeyFieldZeroAlloc(t *testing.T) {
+	if raceDetectorEnabled {
+		t.Skip("allocation counts are not meaningful under -race")
+	}
+	logger := New(NewJSONHandler(io.Discard))
+	allocs := testing.AllocsPerRun(100, func() {
+		logger.Info().Field(testUserID.Value(42)).Field(testTenant.Value("acme")).Msg("m")
+	})
+	if allocs != 0 {
+		t.Errorf("allocs = %v, want 0", allocs)
+	}
+}
@@ -0,0 +1,212 @@
+// Package lifecycle logs a process's shutdown as a sequence of named
+// phases with standardized events, so every service reports shutdown the
+// same way and slow or failing phases stand out in the logs.
+//
+// Register phases in the order they should run, then call Run (or
+// RunOnSignal) once:
+//
+//	sd := lifecycle.New(logger, nil)
+//	sd.Add("http server", srv.Shutdown)
+//	sd.Add("grpc server", func(ctx context.Context) error {
+//	    grpcSrv.GracefulStop()
+//	    return nil
+//	})
+//	sd.AddWithTimeout("flush telemetry", 5*time.Second, tp.Shutdown)
+//	if err := sd.RunOnSignal(context.Background(), os.Interrupt, syscall.SIGTERM); err != nil {
+//	    os.Exit(1)
+//	}
+//
+// Every phase logs "shutdown phase started" and "shutdown phase finished"
+// with "phase", "step" (1-based) and "steps"; the finish event adds
+// "duration" and "status" ("ok", "error" or "timeout") and is logged at
+// ERROR unless the status is ok. A final "shutdown complete" event
+// carries the total "duration", "phases" and "failed", plus "skipped"
+// with [Options.StopOnError], at WARN when any phase failed.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"go.klarlabs.de/bolt"
+)
+
+// DefaultTimeout bounds the whole shutdown when no timeout is configured.
+const DefaultTimeout = 30 * time.Second
+
+// Phase statuses logged under "status".
+const (
+	StatusOK      = "ok"
+	StatusError   = "error"
+	StatusTimeout = "timeout"
+)
+
+// Options configures [New]. A nil *Options uses the defaults.
+type Options struct {
+	// Timeout bounds the whole shutdown. Phases still running when it
+	// expires are reported as timed out. Defaults to DefaultTimeout.
+	Timeout time.Duration
+
+	// StopOnError skips the remaining phases after a phase fails. By
+	// default every phase runs regardless.
+	StopOnError bool
+}
+
+// Shutdown is an ordered list of shutdown phases. It is safe for
+// concurrent use; phases added after Run has started are ignored.
+type Shutdown struct {
+	logger *bolt.Logger
+	opts   Options
+
+	mu     sync.Mutex
+	phases []phase
+
+	once sync.Once
+	err  error
+}
+
+type phase struct {
+	name    string
+	timeout time.Duration
+	fn      func(ctx context.Context) error
+}
+
+// New returns an empty Shutdown logging to logger. If opts is nil,
+// defaults are used.
+func New(logger *bolt.Logger, opts *Options) *Shutdown {
+	s := &Shutdown{logger: logger}
+	if opts != nil {
+		s.opts = *opts
+	}
+	if s.opts.Timeout <= 0 {
+		s.opts.Timeout = DefaultTimeout
+	}
+	return s
+}
+
+// Add appends a phase. fn should return once ctx is done.
+func (s *Shutdown) Add(name string, fn func(ctx context.Context) error) {
+	s.AddWithTimeout(name, 0, fn)
+}
+
+// AddWithTimeout appends a phase with its own deadline, within the
+// overall shutdown timeout. A timeout of zero means no phase deadline.
+func (s *Shutdown) AddWithTimeout(name string, timeout time.Duration, fn func(ctx context.Context) error) {
+	s.mu.Lock()
+	s.phases = append(s.phases, phase{name: name, timeout: timeout, fn: fn})
+	s.mu.Unlock()
+}
+
+// RunOnSignal waits for one of sigs, or for ctx to be done, logs
+// "shutdown signal received" with the "signal" and then calls Run. With
+// no sigs it waits for os.Interrupt.
+func (s *Shutdown) RunOnSignal(ctx context.Context, sigs ...os.Signal) error {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{os.Interrupt}
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+	defer signal.Stop(ch)
+
+	select {
+	case sig := <-ch:
+		s.logger.Info().Str("signal", sig.String()).Msg("shutdown signal received")
+	case <-ctx.Done():
+		s.logger.Info().Str("signal", "context").Msg("shutdown signal received")
+	}
+	return s.Run(context.WithoutCancel(ctx))
+}
+
+// Run executes the phases in order and logs the summary. It returns the
+// phases' errors joined, or nil when all succeeded. Only the first call
+// runs the phases; later calls return the same result.
+func (s *Shutdown) Run(ctx context.Context) error {
+	s.once.Do(func() { s.err = s.run(ctx) })
+	return s.err
+}
+
+func (s *Shutdown) run(ctx context.Context) error {
+	s.mu.Lock()
+	phases := s.phases
+	s.phases = nil
+	s.mu.Unlock()
+
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, s.opts.Timeout)
+	defer cancel()
+
+	var errs []error
+	failed, ran := 0, 0
+	for i, p := range phases {
+		ran++
+		if err := s.runPhase(ctx, p, i+1, len(phases)); err != nil {
+			failed++
+			errs = append(errs, fmt.Errorf("%s: %w", p.name, err))
+			if s.opts.StopOnError {
+				break
+			}
+		}
+	}
+
+	e := s.logger.Info()
+	if failed > 0 {
+		e = s.logger.Warn()
+	}
+	if skipped := len(phases) - ran; skipped > 0 {
+		e = e.Int("skipped", skipped)
+	}
+	e.Dur("duration", time.Since(start)).
+		Int("phases", len(phases)).
+		Int("failed", failed).
+		Msg("shutdown complete")
+	return errors.Join(errs...)
+}
+
+func (s *Shutdown) runPhase(ctx context.Context, p phase, step, steps int) error {
+	s.logger.Info().
+		Str("phase", p.name).Int("step", step).Int("steps", steps).
+		Msg("shutdown phase started")
+
+	start := time.Now()
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+
+	// Run the phase on its own goroutine so one that ignores ctx cannot
+	// hold up the rest of the shutdown.
+	done := make(chan error, 1)
+	go func() { done <- p.fn(ctx) }()
+
+	var err error
+	status := StatusOK
+	select {
+	case err = <-done:
+		switch {
+		case err == nil:
+		case ctx.Err() != nil && errors.Is(err, ctx.Err()):
+			status = StatusTimeout
+		default:
+			status = StatusError
+		}
+	case <-ctx.Done():
+		err = ctx.Err()
+		status = StatusTimeout
+	}
+
+	e := s.logger.Info()
+	if err != nil {
+		e = s.logger.Error().Err(err)
+	}
+	e.Str("phase", p.name).Int("step", step).Int("steps", steps).
+		Dur("duration", time.Since(start)).
+		Str("status", status).
+		Msg("shutdown phase finished")
+	return err
+}
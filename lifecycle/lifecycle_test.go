@@ -0,0 +1,120 @@
+package lifecycle_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.klarlabs.de/bolt"
+	"go.klarlabs.de/bolt/lifecycle"
+	"go.klarlabs.de/bolt/reader"
+)
+
+func records(t *testing.T, buf *bytes.Buffer) []*reader.Record {
+	t.Helper()
+	var out []*reader.Record
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		rec, err := reader.Parse([]byte(line))
+		if err != nil {
+			t.Fatalf("parse %q: %v", line, err)
+		}
+		out = append(out, rec)
+	}
+	return out
+}
+
+func intField(r *reader.Record, key string) int64 {
+	v, _ := r.Get(key)
+	n, _ := v.Int64()
+	return n
+}
+
+func TestRun(t *testing.T) {
+	var buf bytes.Buffer
+	sd := lifecycle.New(bolt.New(bolt.NewJSONHandler(&buf)), nil)
+
+	var order []string
+	boom := errors.New("boom")
+	sd.Add("http", func(context.Context) error { order = append(order, "http"); return nil })
+	sd.Add("db", func(context.Context) error { order = append(order, "db"); return boom })
+	sd.AddWithTimeout("stuck", 10*time.Millisecond, func(context.Context) error {
+		select {} // ignores ctx
+	})
+	sd.Add("metrics", func(context.Context) error { order = append(order, "metrics"); return nil })
+
+	err := sd.Run(context.Background())
+	if !errors.Is(err, boom) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Run = %v; want boom and deadline errors", err)
+	}
+	if strings.Join(order, ",") != "http,db,metrics" {
+		t.Errorf("order = %v", order)
+	}
+
+	recs := records(t, &buf)
+	if len(recs) != 9 {
+		t.Fatalf("got %d events, want 9:\n%s", len(recs), buf.String())
+	}
+	wantStatus := map[string]string{"http": "ok", "db": "error", "stuck": "timeout", "metrics": "ok"}
+	for _, r := range recs[:8] {
+		if r.Message() != "shutdown phase finished" {
+			continue
+		}
+		phase := r.Str("phase")
+		if r.Str("status") != wantStatus[phase] {
+			t.Errorf("phase %s status = %q, want %q", phase, r.Str("status"), wantStatus[phase])
+		}
+		if _, ok := r.Get("duration"); !ok {
+			t.Errorf("phase %s has no duration", phase)
+		}
+	}
+	sum := recs[8]
+	if lvl, _ := sum.Level(); sum.Message() != "shutdown complete" || lvl != bolt.WARN {
+		t.Errorf("summary = %s %q", lvl, sum.Message())
+	}
+	if n := intField(sum, "failed"); n != 2 {
+		t.Errorf("failed = %d, want 2", n)
+	}
+
+	buf.Reset()
+	if again := sd.Run(context.Background()); again != err || buf.Len() != 0 {
+		t.Errorf("second Run = %v and logged %q", again, buf.String())
+	}
+}
+
+func TestStopOnError(t *testing.T) {
+	var buf bytes.Buffer
+	sd := lifecycle.New(bolt.New(bolt.NewJSONHandler(&buf)), &lifecycle.Options{StopOnError: true})
+	sd.Add("a", func(context.Context) error { return errors.New("fail") })
+	sd.Add("b", func(context.Context) error { t.Error("phase b ran"); return nil })
+	if err := sd.Run(context.Background()); err == nil {
+		t.Fatal("Run succeeded")
+	}
+	recs := records(t, &buf)
+	if n := intField(recs[len(recs)-1], "skipped"); n != 1 {
+		t.Errorf("skipped = %d, want 1", n)
+	}
+}
+
+func TestRunOnSignalContext(t *testing.T) {
+	var buf bytes.Buffer
+	sd := lifecycle.New(bolt.New(bolt.NewJSONHandler(&buf)), nil)
+	ran := false
+	sd.Add("only", func(ctx context.Context) error {
+		ran = ctx.Err() == nil
+		return nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := sd.RunOnSignal(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !ran {
+		t.Error("phase did not run with a live context")
+	}
+	if first := records(t, &buf)[0]; first.Message() != "shutdown signal received" {
+		t.Errorf("first event = %q", first.Message())
+	}
+}
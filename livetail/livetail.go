@@ -0,0 +1,206 @@
+// Package livetail serves a bolt [bolt.BroadcastHandler] as a live log
+// stream over Server-Sent Events, for debug dashboards that would
+// otherwise tail container logs.
+//
+// Every matching record is sent as one SSE "data:" line holding the JSON
+// record. When a slow client misses records, a "dropped" event carrying
+// the count precedes the next record. Clients filter with query
+// parameters:
+//
+//	level=warn        minimum level
+//	match=key=value   top-level field equality; repeatable, all must match
+//
+// Access is controlled by [Options.Authorize]; logs routinely contain
+// data that must not leak, so a nil Authorize denies every request:
+//
+//	bh := bolt.NewBroadcastHandler(bolt.NewJSONHandler(os.Stdout), nil)
+//	logger := bolt.New(bh)
+//	debugMux.Handle("/debug/logs", livetail.Handler(bh, &livetail.Options{
+//	    Authorize: func(r *http.Request) error {
+//	        if r.Header.Get("Authorization") != "Bearer "+token {
+//	            return livetail.ErrUnauthorized
+//	        }
+//	        return nil
+//	    },
+//	}))
+//
+// In a browser, new EventSource("/debug/logs?level=error") is enough to
+// consume the stream. WebSocket is not supported; SSE needs no extra
+// dependency and passes through ordinary HTTP proxies.
+package livetail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.klarlabs.de/bolt"
+)
+
+// DefaultHeartbeat is the interval of keep-alive comments sent while no
+// records flow, so idle proxies do not close the stream.
+const DefaultHeartbeat = 15 * time.Second
+
+// ErrUnauthorized may be returned by [Options.Authorize] to reject a
+// request with 401 instead of 403.
+var ErrUnauthorized = errors.New("livetail: unauthorized")
+
+// Options configures [Handler]. A nil *Options denies every request.
+type Options struct {
+	// Authorize is called before streaming starts. A non-nil error
+	// rejects the request: ErrUnauthorized (or an error wrapping it)
+	// yields 401, anything else 403. The error text is not sent to the
+	// client.
+	Authorize func(r *http.Request) error
+
+	// Filter, if set, further restricts what this request may see, for
+	// example to the tenant of the authenticated caller. It is combined
+	// with the query parameter filters.
+	Filter func(r *http.Request) func(e *bolt.Event) bool
+
+	// Heartbeat is the keep-alive interval. Defaults to DefaultHeartbeat.
+	Heartbeat time.Duration
+}
+
+// Handler returns an http.Handler streaming bh's records to each client.
+func Handler(bh *bolt.BroadcastHandler, opts *Options) http.Handler {
+	if opts == nil {
+		opts = &Options{}
+	}
+	heartbeat := opts.Heartbeat
+	if heartbeat <= 0 {
+		heartbeat = DefaultHeartbeat
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := authorize(opts, r); err != nil {
+			status := http.StatusForbidden
+			if errors.Is(err, ErrUnauthorized) {
+				status = http.StatusUnauthorized
+			}
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+		f, err := parseFilter(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if opts.Filter != nil {
+			f.extra = opts.Filter(r)
+		}
+		stream(w, r, bh, f.match, heartbeat)
+	})
+}
+
+func authorize(opts *Options, r *http.Request) error {
+	if opts.Authorize == nil {
+		return errors.New("livetail: no Authorize configured")
+	}
+	return opts.Authorize(r)
+}
+
+func stream(w http.ResponseWriter, r *http.Request, bh *bolt.BroadcastHandler, filter func(*bolt.Event) bool, heartbeat time.Duration) {
+	rc := http.NewResponseController(w)
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("X-Accel-Buffering", "no") // nginx
+	w.WriteHeader(http.StatusOK)
+	if rc.Flush() != nil {
+		return
+	}
+
+	records, cancel := bh.Subscribe(filter)
+	defer cancel()
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	var buf []byte
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			buf = append(buf[:0], ": ping\n\n"...)
+		case rec, ok := <-records:
+			if !ok {
+				return
+			}
+			buf = appendRecord(buf[:0], rec)
+		}
+		if _, err := w.Write(buf); err != nil {
+			return
+		}
+		if rc.Flush() != nil {
+			return
+		}
+	}
+}
+
+// appendRecord encodes rec as SSE events. bolt records are single-line
+// JSON, so one data line suffices.
+func appendRecord(dst []byte, rec bolt.Record) []byte {
+	if rec.Dropped > 0 {
+		dst = fmt.Appendf(dst, "event: dropped\ndata: %d\n\n", rec.Dropped)
+	}
+	dst = append(dst, "data: "...)
+	dst = append(dst, bytes.TrimRight(rec.Data, "\n")...)
+	return append(dst, "\n\n"...)
+}
+
+// filter is the per-request record filter built from the query string.
+type filter struct {
+	level    bolt.Level
+	hasLevel bool
+	matches  []fieldMatch
+	extra    func(*bolt.Event) bool
+}
+
+type fieldMatch struct {
+	key   string
+	value string
+}
+
+func parseFilter(r *http.Request) (*filter, error) {
+	q := r.URL.Query()
+	f := &filter{}
+	if s := q.Get("level"); s != "" {
+		s = strings.ToLower(s)
+		lvl := bolt.ParseLevel(s)
+		if lvl.String() != s {
+			return nil, fmt.Errorf("unknown level %q", s)
+		}
+		f.level, f.hasLevel = lvl, true
+	}
+	for _, m := range q["match"] {
+		key, value, ok := strings.Cut(m, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("match %q: want key=value", m)
+		}
+		f.matches = append(f.matches, fieldMatch{key, value})
+	}
+	return f, nil
+}
+
+func (f *filter) match(e *bolt.Event) bool {
+	if f.hasLevel && e.Level() < f.level {
+		return false
+	}
+	for _, m := range f.matches {
+		found := false
+		e.WalkFields(func(key, value []byte) bool {
+			if string(key) != m.key {
+				return true
+			}
+			found = string(value) == m.value
+			return false
+		})
+		if !found {
+			return false
+		}
+	}
+	return f.extra == nil || f.extra(e)
+}
@@ -0,0 +1,141 @@
+package livetail
+
+import (
+	"bufio"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.klarlabs.de/bolt"
+)
+
+func allowAll(*http.Request) error { return nil }
+
+// open connects to the stream and waits until its subscription is live.
+func open(t *testing.T, srv *httptest.Server, bh *bolt.BroadcastHandler, query string) *bufio.Reader {
+	t.Helper()
+	resp, err := http.Get(srv.URL + query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for bh.Subscribers() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("subscription not established")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return bufio.NewReader(resp.Body)
+}
+
+// next returns the next non-empty, non-comment SSE line.
+func next(t *testing.T, r *bufio.Reader) string {
+	t.Helper()
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line != "" && !strings.HasPrefix(line, ":") {
+			return line
+		}
+	}
+}
+
+func TestHandler_StreamsFilteredRecords(t *testing.T) {
+	bh := bolt.NewBroadcastHandler(nil, nil)
+	logger := bolt.New(bh)
+	srv := httptest.NewServer(Handler(bh, &Options{Authorize: allowAll}))
+	t.Cleanup(srv.Close)
+
+	body := open(t, srv, bh, "/?level=warn&match=tenant=acme")
+	logger.Error().Str("tenant", "other").Msg("skip tenant")
+	logger.Info().Str("tenant", "acme").Msg("skip level")
+	logger.Warn().Str("tenant", "acme").Msg("wanted")
+
+	line := next(t, body)
+	if !strings.HasPrefix(line, "data: {") || !strings.Contains(line, `"message":"wanted"`) {
+		t.Errorf("got %q", line)
+	}
+}
+
+func TestHandler_Authorize(t *testing.T) {
+	bh := bolt.NewBroadcastHandler(nil, nil)
+	tests := []struct {
+		name string
+		opts *Options
+		want int
+	}{
+		{"nil options", nil, http.StatusForbidden},
+		{"no authorize", &Options{}, http.StatusForbidden},
+		{"unauthorized", &Options{Authorize: func(*http.Request) error {
+			return ErrUnauthorized
+		}}, http.StatusUnauthorized},
+		{"forbidden", &Options{Authorize: func(*http.Request) error {
+			return errors.New("wrong team")
+		}}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			Handler(bh, tt.opts).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if strings.Contains(rec.Body.String(), "wrong team") {
+				t.Error("authorization error leaked to client")
+			}
+		})
+	}
+}
+
+func TestHandler_BadQuery(t *testing.T) {
+	bh := bolt.NewBroadcastHandler(nil, nil)
+	h := Handler(bh, &Options{Authorize: allowAll})
+	for _, q := range []string{"/?level=loud", "/?match=novalue"} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", q, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", q, rec.Code)
+		}
+	}
+}
+
+func TestHandler_DroppedEvent(t *testing.T) {
+	rec := bolt.Record{Level: bolt.INFO, Data: []byte("{\"a\":1}\n"), Dropped: 3}
+	got := string(appendRecord(nil, rec))
+	want := "event: dropped\ndata: 3\n\ndata: {\"a\":1}\n\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestHandler_OptionsFilter(t *testing.T) {
+	bh := bolt.NewBroadcastHandler(nil, nil)
+	logger := bolt.New(bh)
+	srv := httptest.NewServer(Handler(bh, &Options{
+		Authorize: allowAll,
+		Filter: func(*http.Request) func(*bolt.Event) bool {
+			return func(e *bolt.Event) bool { return e.Level() == bolt.ERROR }
+		},
+	}))
+	t.Cleanup(srv.Close)
+
+	body := open(t, srv, bh, "/")
+	logger.Info().Msg("hidden")
+	logger.Error().Msg("shown")
+	if line := next(t, body); !strings.Contains(line, "shown") {
+		t.Errorf("got %q", line)
+	}
+}
@@ -0,0 +1,186 @@
+// Package logrsink implements [logr.LogSink] on top of bolt, so
+// Kubernetes operators built with controller-runtime or kubebuilder can
+// log through bolt end to end:
+//
+//	logger := bolt.New(bolt.NewJSONHandler(os.Stdout)).SetLevel(bolt.INFO)
+//	ctrl.SetLogger(logrsink.New(logger, nil))
+//
+// logr verbosity is mapped onto bolt levels by [DefaultVerbosity]: V(0)
+// is INFO, V(1) is DEBUG and anything higher is TRACE, so the bolt
+// logger's level decides which V-levels are emitted. Error always logs
+// at ERROR with the error under "error".
+//
+// Key/value pairs become fields, [logr.Marshaler] values are logged as
+// the result of MarshalLog, and logger names set with WithName are
+// joined with "." and logged under "logger", matching zapr's output.
+//
+// client-go logs through klog, which can be routed to the same sink:
+//
+//	klog.SetLogger(logrsink.New(logger, nil))
+package logrsink
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"go.klarlabs.de/bolt"
+)
+
+// NameKey is the field holding the logr logger name.
+const NameKey = "logger"
+
+// BadKey is logged as the key of a trailing value without a key.
+const BadKey = "!BADKEY"
+
+// DefaultVerbosity maps logr V-levels to bolt levels.
+func DefaultVerbosity(v int) bolt.Level {
+	switch {
+	case v <= 0:
+		return bolt.INFO
+	case v == 1:
+		return bolt.DEBUG
+	default:
+		return bolt.TRACE
+	}
+}
+
+// Options configures [New] and [NewSink]. A nil *Options uses the
+// defaults.
+type Options struct {
+	// Verbosity maps a logr V-level to a bolt level. Defaults to
+	// DefaultVerbosity.
+	Verbosity func(v int) bolt.Level
+
+	// Caller adds the "caller" field, pointing at the code that called
+	// the logr.Logger, with helper frames removed via WithCallDepth.
+	Caller bool
+}
+
+// Sink is a [logr.LogSink] writing to a bolt logger.
+type Sink struct {
+	l         *bolt.Logger
+	name      string
+	verbosity func(v int) bolt.Level
+	caller    bool
+	depth     int
+}
+
+var _ logr.CallDepthLogSink = (*Sink)(nil)
+
+// New returns a logr.Logger backed by logger.
+func New(logger *bolt.Logger, opts *Options) logr.Logger {
+	return logr.New(NewSink(logger, opts))
+}
+
+// NewSink returns a Sink writing to logger.
+func NewSink(logger *bolt.Logger, opts *Options) *Sink {
+	s := &Sink{l: logger, verbosity: DefaultVerbosity}
+	if opts != nil {
+		if opts.Verbosity != nil {
+			s.verbosity = opts.Verbosity
+		}
+		s.caller = opts.Caller
+	}
+	return s
+}
+
+// Init implements [logr.LogSink], recording how many logr frames sit
+// between the caller and the sink.
+func (s *Sink) Init(info logr.RuntimeInfo) {
+	s.depth += info.CallDepth
+}
+
+// WithCallDepth implements [logr.CallDepthLogSink]. It returns a Sink
+// whose "caller" skips depth more frames, for logging helpers.
+func (s *Sink) WithCallDepth(depth int) logr.LogSink {
+	c := *s
+	c.depth += depth
+	return &c
+}
+
+// Enabled reports whether V-level v is enabled on the bolt logger.
+func (s *Sink) Enabled(v int) bool {
+	return s.verbosity(v) >= s.l.GetLevel()
+}
+
+// Info logs msg at the bolt level mapped from V-level v.
+func (s *Sink) Info(v int, msg string, keysAndValues ...any) {
+	var e *bolt.Event
+	switch s.verbosity(v) {
+	case bolt.TRACE:
+		e = s.l.Trace()
+	case bolt.DEBUG:
+		e = s.l.Debug()
+	case bolt.WARN:
+		e = s.l.Warn()
+	case bolt.ERROR:
+		e = s.l.Error()
+	default:
+		e = s.l.Info()
+	}
+	s.send(e, msg, keysAndValues)
+}
+
+// Error logs msg at ERROR with err under "error".
+func (s *Sink) Error(err error, msg string, keysAndValues ...any) {
+	s.send(s.l.Error().Err(err), msg, keysAndValues)
+}
+
+// WithValues returns a Sink that adds keysAndValues to every record.
+func (s *Sink) WithValues(keysAndValues ...any) logr.LogSink {
+	if len(keysAndValues) == 0 {
+		return s
+	}
+	c := *s
+	c.l = appendKeyValues(s.l.With(), keysAndValues).Logger()
+	return &c
+}
+
+// WithName returns a Sink whose name has name appended, separated by ".".
+func (s *Sink) WithName(name string) logr.LogSink {
+	c := *s
+	if c.name == "" {
+		c.name = name
+	} else {
+		c.name += "." + name
+	}
+	return &c
+}
+
+// send must be called directly from Info or Error so that the caller
+// frame arithmetic holds.
+func (s *Sink) send(e *bolt.Event, msg string, keysAndValues []any) {
+	if s.caller {
+		e = e.CallerSkip(2 + s.depth) // send, Info/Error, then the logr frames
+	}
+	if s.name != "" {
+		e = e.Str(NameKey, s.name)
+	}
+	appendKeyValues(e, keysAndValues).Msg(msg)
+}
+
+// appendKeyValues adds logr key/value pairs to e. Keys that are not
+// strings are formatted with fmt; a trailing value without a key is
+// logged under BadKey.
+func appendKeyValues(e *bolt.Event, keysAndValues []any) *bolt.Event {
+	for i := 0; i < len(keysAndValues); i += 2 {
+		if i+1 == len(keysAndValues) {
+			return e.Any(BadKey, keysAndValues[i])
+		}
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysAndValues[i])
+		}
+		switch v := keysAndValues[i+1].(type) {
+		case logr.Marshaler:
+			e = e.Any(key, v.MarshalLog())
+		case error:
+			e = e.Str(key, v.Error())
+		case fmt.Stringer:
+			e = e.Stringer(key, v)
+		default:
+			e = e.Any(key, v)
+		}
+	}
+	return e
+}
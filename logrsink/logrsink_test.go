@@ -0,0 +1,114 @@
+package logrsink_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"go.klarlabs.de/bolt"
+	"go.klarlabs.de/bolt/logrsink"
+)
+
+type secret string
+
+func (secret) MarshalLog() any { return "[redacted]" }
+
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var m map[string]any
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("invalid JSON %q: %v", line, err)
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+func TestVerbosity(t *testing.T) {
+	var buf bytes.Buffer
+	log := logrsink.New(bolt.New(bolt.NewJSONHandler(&buf)).SetLevel(bolt.DEBUG), nil)
+
+	if !log.V(1).Enabled() || log.V(2).Enabled() {
+		t.Errorf("V(1) enabled = %v, V(2) enabled = %v at DEBUG", log.V(1).Enabled(), log.V(2).Enabled())
+	}
+	log.Info("zero")
+	log.V(1).Info("one")
+	log.V(2).Info("two")
+
+	evs := decodeLines(t, &buf)
+	if len(evs) != 2 {
+		t.Fatalf("got %d events: %s", len(evs), buf.String())
+	}
+	if evs[0]["level"] != "info" || evs[1]["level"] != "debug" {
+		t.Errorf("levels = %v, %v", evs[0]["level"], evs[1]["level"])
+	}
+}
+
+func TestNamesValuesAndErrors(t *testing.T) {
+	var buf bytes.Buffer
+	log := logrsink.New(bolt.New(bolt.NewJSONHandler(&buf)), nil).
+		WithName("controller").WithName("pod").
+		WithValues("namespace", "default")
+
+	log.Error(errors.New("conflict"), "reconcile failed",
+		"pod", "web-0", "token", secret("hunter2"), "requeue", true, 42)
+
+	evs := decodeLines(t, &buf)
+	want := map[string]any{
+		"level":     "error",
+		"error":     "conflict",
+		"logger":    "controller.pod",
+		"namespace": "default",
+		"pod":       "web-0",
+		"token":     "[redacted]",
+		"requeue":   true,
+		"!BADKEY":   float64(42),
+		"message":   "reconcile failed",
+	}
+	for k, v := range want {
+		if evs[0][k] != v {
+			t.Errorf("%s = %v, want %v", k, evs[0][k], v)
+		}
+	}
+}
+
+func TestCustomVerbosity(t *testing.T) {
+	var buf bytes.Buffer
+	log := logrsink.New(bolt.New(bolt.NewJSONHandler(&buf)).SetLevel(bolt.INFO), &logrsink.Options{
+		Verbosity: func(int) bolt.Level { return bolt.WARN },
+	})
+	log.V(5).Info("loud")
+	if !strings.Contains(buf.String(), `"level":"warn"`) {
+		t.Errorf("custom verbosity not applied: %s", buf.String())
+	}
+}
+
+func logFromHelper(log logr.Logger) {
+	log.WithCallDepth(1).Info("from helper")
+}
+
+func TestCaller(t *testing.T) {
+	var buf bytes.Buffer
+	log := logrsink.New(bolt.New(bolt.NewJSONHandler(&buf)), &logrsink.Options{Caller: true})
+
+	_, _, line, _ := runtime.Caller(0)
+	log.Info("direct")
+	logFromHelper(log)
+
+	evs := decodeLines(t, &buf)
+	for i, want := range []string{
+		"/logrsink_test.go:" + strconv.Itoa(line+1),
+		"/logrsink_test.go:" + strconv.Itoa(line+2),
+	} {
+		if got, _ := evs[i]["caller"].(string); !strings.HasSuffix(got, want) {
+			t.Errorf("event %d caller = %q, want suffix %q", i, got, want)
+		}
+	}
+}
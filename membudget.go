@@ -0,0 +1,118 @@
+package bolt
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// ErrMemoryBudget is returned by handlers that dropped an event because
+// holding it would exceed the budget set with [SetMemoryBudget].
+var ErrMemoryBudget = errors.New("bolt: memory budget exhausted")
+
+// MemoryStats reports the memory accounted to the logging subsystem.
+type MemoryStats struct {
+	Budget int64  // configured budget in bytes; 0 when unlimited
+	Used   int64  // bytes currently held
+	Peak   int64  // highest Used since the process started
+	Denied uint64 // reservations refused because of the budget
+}
+
+var memBudget struct {
+	limit  atomic.Int64
+	used   atomic.Int64
+	peak   atomic.Int64
+	denied atomic.Uint64
+}
+
+// SetMemoryBudget caps the memory bolt holds on to between log calls,
+// for builds that must stay within a strict footprint. A budget of 0 or
+// less removes the cap. Accounting is always on, so [ReadMemoryStats]
+// reports usage either way.
+//
+// The budget covers the memory that outlives a log call:
+//   - [AsyncHandler] queues drop events that do not fit, with
+//     ErrMemoryBudget, except on a blocking high lane (see
+//     [AsyncOptions.BlockHigh]), which is accounted but never drops;
+//   - [BatchHandler] batches drop events other than FATAL that do not
+//     fit, with ErrMemoryBudget;
+//   - [BufferedWriter] buffers are sized down to what the budget has
+//     left when they are created, down to writing through unbuffered;
+//   - the event pool keeps only buffers of up to DefaultBufferSize
+//     instead of PoolBufferCap.
+//
+// Memory in use while an event is being built is not counted, so the
+// process may briefly exceed the budget by the events in flight.
+//
+//	bolt.SetMemoryBudget(8 << 20) // 8 MiB
+func SetMemoryBudget(bytes int64) {
+	memBudget.limit.Store(max(bytes, 0))
+}
+
+// ReadMemoryStats returns the current memory accounting.
+func ReadMemoryStats() MemoryStats {
+	return MemoryStats{
+		Budget: memBudget.limit.Load(),
+		Used:   memBudget.used.Load(),
+		Peak:   memBudget.peak.Load(),
+		Denied: memBudget.denied.Load(),
+	}
+}
+
+// memReserve accounts n bytes, reporting false and accounting nothing if
+// that would exceed the budget.
+func memReserve(n int) bool {
+	used := memBudget.used.Add(int64(n))
+	if limit := memBudget.limit.Load(); limit > 0 && used > limit {
+		memBudget.used.Add(-int64(n))
+		memBudget.denied.Add(1)
+		return false
+	}
+	memPeak(used)
+	return true
+}
+
+// memCharge accounts n bytes regardless of the budget.
+func memCharge(n int) {
+	memPeak(memBudget.used.Add(int64(n)))
+}
+
+// memReserveUpTo accounts and returns up to n bytes, as many as the
+// budget has left.
+func memReserveUpTo(n int) int {
+	for {
+		used := memBudget.used.Load()
+		grant := int64(n)
+		if limit := memBudget.limit.Load(); limit > 0 && used+grant > limit {
+			grant = max(limit-used, 0)
+		}
+		if memBudget.used.CompareAndSwap(used, used+grant) {
+			if grant < int64(n) {
+				memBudget.denied.Add(1)
+			}
+			memPeak(used + grant)
+			return int(grant)
+		}
+	}
+}
+
+// memRelease returns n accounted bytes.
+func memRelease(n int) {
+	memBudget.used.Add(-int64(n))
+}
+
+func memPeak(used int64) {
+	for {
+		p := memBudget.peak.Load()
+		if used <= p || memBudget.peak.CompareAndSwap(p, used) {
+			return
+		}
+	}
+}
+
+// poolRetainCap returns the largest event buffer the pool may keep.
+func poolRetainCap() int {
+	if memBudget.limit.Load() > 0 {
+		return DefaultBufferSize
+	}
+	return PoolBufferCap
+}
@@ -0,0 +1,104 @@
+package bolt
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestMemoryBudgetAsync(t *testing.T) {
+	defer SetMemoryBudget(0)
+	g := newGatedHandler()
+	h := NewAsyncHandler(g, &AsyncOptions{QueueSize: 100})
+	logger := New(h)
+
+	// Room for a couple of records on top of what is already accounted.
+	SetMemoryBudget(ReadMemoryStats().Used + 100)
+	var budgetErrs int
+	logger.SetErrorHandler(func(err error) {
+		if errors.Is(err, ErrMemoryBudget) {
+			budgetErrs++
+		}
+	})
+	for range 10 {
+		logger.Info().Str("k", "0123456789").Msg("queued")
+	}
+	if budgetErrs == 0 {
+		t.Fatal("no event was dropped for the budget")
+	}
+	if got := h.Dropped(LaneNormal); got != uint64(budgetErrs) {
+		t.Errorf("Dropped = %d, want %d", got, budgetErrs)
+	}
+	if s := ReadMemoryStats(); s.Used > s.Budget || s.Denied == 0 {
+		t.Errorf("stats = %+v", s)
+	}
+
+	close(g.gate)
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if s := ReadMemoryStats(); s.Used > s.Budget-100 {
+		t.Errorf("Used = %d after draining, want the queue's memory released", s.Used)
+	}
+}
+
+func TestMemoryBudgetBatch(t *testing.T) {
+	defer SetMemoryBudget(0)
+	rec := &batchRecorder{}
+	h := NewBatchHandler(rec, &BatchOptions{MaxDelay: time.Hour})
+	logger := New(h)
+	SetMemoryBudget(ReadMemoryStats().Used + 100)
+
+	var errs []error
+	logger.SetErrorHandler(func(err error) { errs = append(errs, err) })
+	for range 5 {
+		logger.Info().Str("k", "0123456789").Msg("batched")
+	}
+	if len(errs) == 0 || !errors.Is(errs[0], ErrMemoryBudget) {
+		t.Fatalf("errors = %v, want ErrMemoryBudget", errs)
+	}
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(rec.snapshot()); got != 1 {
+		t.Errorf("got %d batches, want the records that fit", got)
+	}
+}
+
+func TestMemoryBudgetBufferedWriter(t *testing.T) {
+	defer SetMemoryBudget(0)
+	used := ReadMemoryStats().Used
+	SetMemoryBudget(used + 64)
+
+	var out bytes.Buffer
+	w := NewBufferedWriter(&out, &BufferedWriterOptions{FlushInterval: -1})
+	if got := cap(w.buf); got != 64 {
+		t.Errorf("buffer = %d bytes, want the 64 left in the budget", got)
+	}
+	starved := NewBufferedWriter(&out, &BufferedWriterOptions{FlushInterval: -1})
+	if _, err := io.WriteString(starved, "through\n"); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "through\n" {
+		t.Errorf("out = %q, want an unbuffered write-through", out.String())
+	}
+
+	w.Close()
+	starved.Close()
+	if got := ReadMemoryStats().Used; got != used {
+		t.Errorf("Used = %d after Close, want %d", got, used)
+	}
+}
+
+func TestMemoryBudgetPool(t *testing.T) {
+	defer SetMemoryBudget(0)
+	if poolRetainCap() != PoolBufferCap {
+		t.Errorf("unbudgeted pool keeps up to %d bytes, want %d", poolRetainCap(), PoolBufferCap)
+	}
+	SetMemoryBudget(8 << 20)
+	if poolRetainCap() != DefaultBufferSize {
+		t.Errorf("budgeted pool keeps up to %d bytes, want %d", poolRetainCap(), DefaultBufferSize)
+	}
+}
@@ -0,0 +1,211 @@
+// Package mongolog provides a MongoDB command monitor that writes one
+// bolt event per command, in the format of
+// [go.klarlabs.de/bolt/dblog].
+//
+// The statement is the shape of the command document: its keys, nested
+// documents and operators are kept and every value is replaced with "?",
+// so that a query such as
+//
+//	{"find":"users","filter":{"email":"a@example.com","age":{"$gt":21}}}
+//
+// is logged as {"find":"users","filter":{"email":"?","age":{"$gt":"?"}}}.
+// Session and cluster bookkeeping fields such as lsid and $clusterTime are
+// left out. With values logged, the statement is the command as extended
+// JSON.
+//
+// The package lives in its own go.mod so that the bolt core does not
+// depend on go.mongodb.org/mongo-driver.
+//
+// Example:
+//
+//	client, err := mongo.Connect(options.Client().
+//	    ApplyURI(uri).
+//	    SetMonitor(mongolog.NewMonitor(dblog.New(logger, nil))))
+package mongolog
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/event"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/x/mongo/driver"
+
+	"go.klarlabs.de/bolt/dblog"
+)
+
+const (
+	// maxStatementLength truncates longer statements, such as inserts of
+	// many documents.
+	maxStatementLength = 2048
+	// maxShapeElements bounds the elements shown per array.
+	maxShapeElements = 3
+)
+
+// started is what a command's Started event leaves for its Succeeded or
+// Failed event.
+type started struct {
+	statement  string
+	collection string
+}
+
+// monitor correlates the events of a command by request ID.
+type monitor struct {
+	l       *dblog.Logger
+	pending sync.Map // request ID -> started
+}
+
+// NewMonitor returns a command monitor logging to l; install it with
+// options.Client().SetMonitor.
+func NewMonitor(l *dblog.Logger) *event.CommandMonitor {
+	m := &monitor{l: l}
+	return &event.CommandMonitor{Started: m.started, Succeeded: m.succeeded, Failed: m.failed}
+}
+
+func (m *monitor) started(_ context.Context, e *event.CommandStartedEvent) {
+	s := started{}
+	if elems, err := e.Command.Elements(); err == nil && len(elems) > 0 {
+		// The first element names the command; its value is the
+		// collection for commands that have one, except getMore.
+		s.collection, _ = elems[0].Value().StringValueOK()
+		if e.CommandName == "getMore" {
+			s.collection, _ = e.Command.Lookup("collection").StringValueOK()
+		}
+	}
+	if m.l.LogValues() {
+		s.statement = e.Command.String()
+	} else {
+		var sb strings.Builder
+		appendShape(&sb, e.Command, true)
+		s.statement = sb.String()
+	}
+	if len(s.statement) > maxStatementLength {
+		s.statement = s.statement[:maxStatementLength] + "…"
+	}
+	m.pending.Store(e.RequestID, s)
+}
+
+func (m *monitor) succeeded(ctx context.Context, e *event.CommandSucceededEvent) {
+	m.log(ctx, &e.CommandFinishedEvent, nil)
+}
+
+func (m *monitor) failed(ctx context.Context, e *event.CommandFailedEvent) {
+	m.log(ctx, &e.CommandFinishedEvent, e.Failure)
+}
+
+func (m *monitor) log(ctx context.Context, e *event.CommandFinishedEvent, err error) {
+	v, _ := m.pending.LoadAndDelete(e.RequestID)
+	s, _ := v.(started)
+	m.l.Log(ctx, dblog.Command{
+		System:     "mongodb",
+		Operation:  e.CommandName,
+		Statement:  s.statement,
+		Database:   e.DatabaseName,
+		Collection: s.collection,
+		Duration:   e.Duration,
+		Err:        err,
+		ErrClass:   classify(err),
+	})
+}
+
+// appendShape writes the keys of doc with every value redacted. The
+// command document itself keeps its first value, the collection name,
+// and drops driver bookkeeping.
+func appendShape(sb *strings.Builder, doc bson.Raw, command bool) {
+	elems, err := doc.Elements()
+	if err != nil {
+		sb.WriteString(dblog.Redacted)
+		return
+	}
+	sb.WriteByte('{')
+	n := 0
+	for i, el := range elems {
+		key := el.Key()
+		if command && bookkeeping(key) {
+			continue
+		}
+		if n > 0 {
+			sb.WriteByte(',')
+		}
+		n++
+		sb.WriteString(strconv.Quote(key))
+		sb.WriteByte(':')
+		if name, ok := el.Value().StringValueOK(); command && i == 0 && ok {
+			sb.WriteString(strconv.Quote(name))
+			continue
+		}
+		appendValueShape(sb, el.Value())
+	}
+	sb.WriteByte('}')
+}
+
+func appendValueShape(sb *strings.Builder, v bson.RawValue) {
+	switch v.Type {
+	case bson.TypeEmbeddedDocument:
+		appendShape(sb, v.Document(), false)
+	case bson.TypeArray:
+		values, err := v.Array().Values()
+		if err != nil {
+			sb.WriteString(dblog.Redacted)
+			return
+		}
+		sb.WriteByte('[')
+		for i, elem := range values {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			if i == maxShapeElements {
+				sb.WriteString(`"…"`)
+				break
+			}
+			appendValueShape(sb, elem)
+		}
+		sb.WriteByte(']')
+	default:
+		sb.WriteString(strconv.Quote(dblog.Redacted))
+	}
+}
+
+// bookkeeping reports whether key is a field the driver adds to every
+// command.
+func bookkeeping(key string) bool {
+	switch key {
+	case "lsid", "txnNumber", "$clusterTime", "$db", "$readPreference", "apiVersion", "apiStrict", "apiDeprecationErrors":
+		return true
+	}
+	return false
+}
+
+// classify returns the class of MongoDB errors, or "" to leave err to
+// [dblog.Classify]. Command monitors receive the driver's own error type,
+// so server errors are classified by their code.
+func classify(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case mongo.IsTimeout(err):
+		return dblog.ClassTimeout
+	case mongo.IsNetworkError(err):
+		return dblog.ClassNetwork
+	}
+	var de driver.Error
+	if errors.As(err, &de) {
+		switch de.Code {
+		case 11000, 11001, 12582, 112: // DuplicateKey, WriteConflict
+			return dblog.ClassConflict
+		case 13, 18: // Unauthorized, AuthenticationFailed
+			return dblog.ClassAuth
+		case 50, 262: // MaxTimeMSExpired, ExceededTimeLimit
+			return dblog.ClassTimeout
+		}
+		return dblog.ClassServer
+	}
+	if mongo.IsDuplicateKeyError(err) {
+		return dblog.ClassConflict
+	}
+	return ""
+}
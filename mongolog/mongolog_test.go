@@ -0,0 +1,114 @@
+package mongolog_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/event"
+	"go.mongodb.org/mongo-driver/v2/x/mongo/driver"
+
+	"go.klarlabs.de/bolt"
+	"go.klarlabs.de/bolt/dblog"
+	"go.klarlabs.de/bolt/mongolog"
+)
+
+func events(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var m map[string]any
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("invalid JSON %q: %v", line, err)
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+func newMonitor(buf *bytes.Buffer, opts *dblog.Options) *event.CommandMonitor {
+	return mongolog.NewMonitor(dblog.New(bolt.New(bolt.NewJSONHandler(buf)).SetLevel(bolt.DEBUG), opts))
+}
+
+func raw(t *testing.T, doc bson.D) bson.Raw {
+	t.Helper()
+	b, err := bson.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func finished(id int64, name string) event.CommandFinishedEvent {
+	return event.CommandFinishedEvent{RequestID: id, CommandName: name, DatabaseName: "app", Duration: 3 * time.Millisecond}
+}
+
+func TestMonitorRedactsCommand(t *testing.T) {
+	var buf bytes.Buffer
+	m := newMonitor(&buf, nil)
+	ctx := context.Background()
+	cmd := raw(t, bson.D{
+		{Key: "find", Value: "users"},
+		{Key: "filter", Value: bson.D{
+			{Key: "email", Value: "a@example.com"},
+			{Key: "age", Value: bson.D{{Key: "$gt", Value: 21}}},
+			{Key: "tags", Value: bson.A{"x", "y", "z", "w"}},
+		}},
+		{Key: "lsid", Value: bson.D{{Key: "id", Value: "session"}}},
+		{Key: "$db", Value: "app"},
+	})
+	m.Started(ctx, &event.CommandStartedEvent{Command: cmd, CommandName: "find", DatabaseName: "app", RequestID: 7})
+	m.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: finished(7, "find")})
+
+	ev := events(t, &buf)[0]
+	want := `{"find":"users","filter":{"email":"?","age":{"$gt":"?"},"tags":["?","?","?","…"]}}`
+	if ev["statement"] != want {
+		t.Errorf("statement = %v, want %s", ev["statement"], want)
+	}
+	if ev["db"] != "mongodb" || ev["operation"] != "find" || ev["database"] != "app" || ev["collection"] != "users" || ev["level"] != "debug" {
+		t.Errorf("event = %v", ev)
+	}
+	if strings.Contains(buf.String(), "example.com") {
+		t.Errorf("value leaked: %s", buf.String())
+	}
+}
+
+func TestMonitorLogValues(t *testing.T) {
+	var buf bytes.Buffer
+	m := newMonitor(&buf, &dblog.Options{LogValues: true})
+	ctx := context.Background()
+	cmd := raw(t, bson.D{{Key: "delete", Value: "users"}, {Key: "q", Value: "a@example.com"}})
+	m.Started(ctx, &event.CommandStartedEvent{Command: cmd, CommandName: "delete", RequestID: 1})
+	m.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: finished(1, "delete")})
+	if ev := events(t, &buf)[0]; !strings.Contains(ev["statement"].(string), "a@example.com") {
+		t.Errorf("event = %v", ev)
+	}
+}
+
+func TestMonitorFailures(t *testing.T) {
+	var buf bytes.Buffer
+	m := newMonitor(&buf, nil)
+	ctx := context.Background()
+	for i, err := range []error{
+		driver.Error{Code: 11000, Message: "E11000 duplicate key error"},
+		driver.Error{Code: 13, Message: "not authorized"},
+		driver.Error{Code: 2, Message: "bad value"},
+		driver.Error{Message: "connection closed", Labels: []string{"NetworkError"}},
+		context.DeadlineExceeded,
+	} {
+		id := int64(i + 1)
+		m.Started(ctx, &event.CommandStartedEvent{Command: raw(t, bson.D{{Key: "insert", Value: "users"}}), CommandName: "insert", RequestID: id})
+		m.Failed(ctx, &event.CommandFailedEvent{CommandFinishedEvent: finished(id, "insert"), Failure: err})
+	}
+
+	evs := events(t, &buf)
+	for i, want := range []string{"conflict", "auth", "server", "network", "timeout"} {
+		if evs[i]["level"] != "error" || evs[i]["error_class"] != want || evs[i]["error"] == nil {
+			t.Errorf("event %d = %v, want class %s", i, evs[i], want)
+		}
+	}
+}
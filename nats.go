@@ -0,0 +1,637 @@
+package bolt
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultNATSURL is the server a [NATSHandler] connects to when no
+	// URL is configured.
+	DefaultNATSURL = "nats://127.0.0.1:4222"
+	// DefaultNATSBuffer is how many bytes of messages a NATSHandler holds
+	// while the server is unreachable when no size is configured.
+	DefaultNATSBuffer = 1 << 20 // 1 MiB
+	// DefaultNATSRetry is the minimum time between reconnection attempts
+	// when no delay is configured.
+	DefaultNATSRetry = time.Second
+	// DefaultNATSMaxInFlight is how many JetStream publishes may await
+	// their acknowledgement when no limit is configured.
+	DefaultNATSMaxInFlight = 1024
+	// natsTimeout bounds each connection attempt, each write and the wait
+	// for outstanding acknowledgements in Close.
+	natsTimeout = 5 * time.Second
+)
+
+// ErrNATSNoResponders is reported for a JetStream publish to a subject
+// no stream captures.
+var ErrNATSNoResponders = errors.New("bolt: nats: no stream for subject")
+
+// NATSOptions configures [NewNATSHandler].
+type NATSOptions struct {
+	// URL is the server, as nats://[user:password@]host[:port]. A tls://
+	// scheme requires TLS; credentials without a password are sent as a
+	// token. Defaults to DefaultNATSURL.
+	URL string
+
+	// Subject is the subject every record is published to. Required.
+	Subject string
+
+	// JetStream publishes to a JetStream stream capturing Subject and
+	// tracks the stream's acknowledgements. Publishes carry a
+	// Nats-Msg-Id header, so those resent after a reconnect are
+	// deduplicated by the stream. The ID is the record's
+	// [IdempotencyKey] if it has one, which also deduplicates records
+	// shipped again by another process, and otherwise unique to the
+	// handler.
+	JetStream bool
+
+	// Token authenticates with a server token.
+	Token string
+
+	// Name identifies the connection in server monitoring. Defaults to
+	// the executable name.
+	Name string
+
+	// TLS, if set, encrypts the connection. Servers that require TLS get
+	// it with the system roots even when TLS is nil.
+	TLS *TLSConfig
+
+	// Dial, if set, opens connections to the server's host:port in place
+	// of a net.Dialer, for proxies or in-process transports; TLS is
+	// negotiated over the connection it returns.
+	Dial func(network, addr string) (net.Conn, error)
+
+	// BufferSize is how many bytes of messages are held while the server
+	// is unreachable. The oldest are dropped beyond it. Defaults to
+	// DefaultNATSBuffer.
+	BufferSize int
+
+	// RetryDelay is the minimum time between reconnection attempts.
+	// Defaults to DefaultNATSRetry.
+	RetryDelay time.Duration
+
+	// MaxInFlight bounds how many JetStream publishes may await their
+	// acknowledgement; later records are held until acks arrive.
+	// Defaults to DefaultNATSMaxInFlight.
+	MaxInFlight int
+
+	// ErrorHandler, if set, receives errors that happen off the logging
+	// goroutine: rejected JetStream publishes and lost connections.
+	ErrorHandler ErrorHandler
+}
+
+// natsMsg is a framed publish waiting to be sent or acknowledged.
+type natsMsg struct {
+	seq   uint64
+	frame []byte
+}
+
+// NATSHandler publishes each record as one message to a NATS subject or,
+// with [NATSOptions.JetStream], to a JetStream stream:
+//
+//	h, err := bolt.NewNATSHandler(&bolt.NATSOptions{
+//	    URL:       "nats://nats.internal:4222",
+//	    Subject:   "logs.billing",
+//	    JetStream: true,
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	defer h.Close()
+//	logger := bolt.New(h)
+//
+// JetStream acknowledgements are processed asynchronously: Write returns
+// once the message is sent, and rejected publishes are counted by Failed
+// and reported to the ErrorHandler.
+//
+// When the connection fails, the handler keeps the unsent and
+// unacknowledged messages and reconnects on a later Write, at most once
+// per RetryDelay, sending them first in order. Up to BufferSize bytes are
+// held; older messages are dropped and counted by Dropped. Write returns
+// the connection error when an attempt fails, even though the message is
+// kept. NATSHandler is safe for concurrent use.
+type NATSHandler struct {
+	addr      string
+	host      string
+	tls       *tls.Config
+	dialer    func(network, addr string) (net.Conn, error)
+	subject   string
+	jetStream bool
+	connect   []byte // CONNECT and SUB commands sent on every connection
+	id        string // inbox and message ID prefix
+	maxBuf    int
+	retry     time.Duration
+	inFlight  int
+	onError   ErrorHandler
+	now       func() time.Time
+
+	mu       sync.Mutex
+	conn     net.Conn
+	lastDial time.Time
+	seq      uint64
+	pending  []natsMsg
+	pendingN int
+	unacked  map[uint64][]byte
+	closed   bool
+	dropped  atomic.Uint64
+	failed   atomic.Uint64
+	out      []byte
+}
+
+// NewNATSHandler connects to the server described by opts and returns a
+// handler publishing to it. It fails if the first connection cannot be
+// made.
+func NewNATSHandler(opts *NATSOptions) (*NATSHandler, error) {
+	if opts == nil || opts.Subject == "" || strings.ContainsAny(opts.Subject, " \t\r\n*>") {
+		subject := ""
+		if opts != nil {
+			subject = opts.Subject
+		}
+		return nil, fmt.Errorf("bolt: nats: invalid subject %q", subject)
+	}
+	raw := opts.URL
+	if raw == "" {
+		raw = DefaultNATSURL
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("bolt: nats: %w", err)
+	}
+	if u.Scheme != "nats" && u.Scheme != "tls" {
+		return nil, fmt.Errorf("bolt: nats: unsupported URL scheme %q", u.Scheme)
+	}
+	var id [12]byte
+	_, _ = rand.Read(id[:])
+	h := &NATSHandler{
+		addr:      u.Host,
+		host:      u.Hostname(),
+		subject:   opts.Subject,
+		dialer:    opts.Dial,
+		jetStream: opts.JetStream,
+		id:        hex.EncodeToString(id[:]),
+		maxBuf:    opts.BufferSize,
+		retry:     opts.RetryDelay,
+		inFlight:  opts.MaxInFlight,
+		onError:   opts.ErrorHandler,
+		now:       time.Now,
+		unacked:   make(map[uint64][]byte),
+	}
+	if u.Port() == "" {
+		h.addr = net.JoinHostPort(h.host, "4222")
+	}
+	if h.maxBuf <= 0 {
+		h.maxBuf = DefaultNATSBuffer
+	}
+	if h.retry <= 0 {
+		h.retry = DefaultNATSRetry
+	}
+	if h.inFlight <= 0 {
+		h.inFlight = DefaultNATSMaxInFlight
+	}
+	if opts.TLS != nil || u.Scheme == "tls" {
+		cfg, err := opts.TLS.Build()
+		if err != nil {
+			return nil, err
+		}
+		h.tls = cfg
+	}
+
+	name := opts.Name
+	if name == "" {
+		name = filepath.Base(os.Args[0])
+	}
+	c := struct {
+		Verbose      bool   `json:"verbose"`
+		Pedantic     bool   `json:"pedantic"`
+		TLSRequired  bool   `json:"tls_required"`
+		Name         string `json:"name"`
+		Lang         string `json:"lang"`
+		Version      string `json:"version"`
+		Protocol     int    `json:"protocol"`
+		Headers      bool   `json:"headers"`
+		NoResponders bool   `json:"no_responders"`
+		User         string `json:"user,omitempty"`
+		Pass         string `json:"pass,omitempty"`
+		Token        string `json:"auth_token,omitempty"`
+	}{TLSRequired: h.tls != nil, Name: name, Lang: "go", Version: "bolt", Protocol: 1,
+		Headers: opts.JetStream, NoResponders: opts.JetStream, Token: opts.Token}
+	if pass, ok := u.User.Password(); ok {
+		c.User, c.Pass = u.User.Username(), pass
+	} else if u.User != nil {
+		c.Token = u.User.Username()
+	}
+	js, _ := json.Marshal(c) // plain strings and bools always marshal
+	h.connect = append(append([]byte("CONNECT "), js...), "\r\n"...)
+	if h.jetStream {
+		h.connect = append(h.connect, "SUB _INBOX."+h.id+".* 1\r\n"...)
+	}
+
+	h.lastDial = h.now()
+	conn, r, err := h.dial()
+	if err != nil {
+		return nil, fmt.Errorf("bolt: nats: %w", err)
+	}
+	h.conn = conn
+	go h.readLoop(conn, r)
+	return h, nil
+}
+
+// dial connects and completes the handshake: the server's INFO, an
+// optional TLS upgrade, then CONNECT answered by a PONG.
+func (h *NATSHandler) dial() (net.Conn, *bufio.Reader, error) {
+	dial := h.dialer
+	if dial == nil {
+		dial = (&net.Dialer{Timeout: natsTimeout}).Dial
+	}
+	conn, err := dial("tcp", h.addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	conn.SetDeadline(time.Now().Add(natsTimeout))
+	fail := func(err error) (net.Conn, *bufio.Reader, error) {
+		conn.Close()
+		return nil, nil, err
+	}
+	r := bufio.NewReader(conn)
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return fail(err)
+	}
+	var info struct {
+		TLSRequired bool `json:"tls_required"`
+		Headers     bool `json:"headers"`
+	}
+	if rest, ok := strings.CutPrefix(line, "INFO "); !ok || json.Unmarshal([]byte(rest), &info) != nil {
+		return fail(fmt.Errorf("unexpected greeting %q", strings.TrimSpace(line)))
+	}
+	if h.jetStream && !info.Headers {
+		return fail(errors.New("server does not support headers, required for JetStream"))
+	}
+	if h.tls != nil || info.TLSRequired {
+		cfg := h.tls
+		if cfg == nil {
+			cfg = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		if cfg.ServerName == "" {
+			cfg = cfg.Clone()
+			cfg.ServerName = h.host
+		}
+		tc := tls.Client(conn, cfg)
+		if err := tc.Handshake(); err != nil {
+			return fail(err)
+		}
+		conn, r = tc, bufio.NewReader(tc)
+	}
+	if _, err := conn.Write(append(h.connect[:len(h.connect):len(h.connect)], "PING\r\n"...)); err != nil {
+		return fail(err)
+	}
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return fail(err)
+		}
+		switch {
+		case strings.HasPrefix(line, "PONG"):
+			conn.SetDeadline(time.Time{})
+			return conn, r, nil
+		case strings.HasPrefix(line, "-ERR"):
+			return fail(fmt.Errorf("server error: %s", strings.TrimSpace(line[4:])))
+		}
+	}
+}
+
+// Write implements [Handler].
+func (h *NATSHandler) Write(e *Event) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return ErrHandlerClosed
+	}
+	h.seq++
+	m := natsMsg{seq: h.seq, frame: h.frame(e.buf)}
+
+	if h.conn == nil {
+		if err := h.reconnect(); err != nil {
+			h.hold(m)
+			return err
+		}
+		if h.conn == nil {
+			h.hold(m) // waiting for RetryDelay
+			return nil
+		}
+	}
+	if len(h.pending) > 0 || (h.jetStream && len(h.unacked) >= h.inFlight) {
+		h.hold(m)
+		return h.drain()
+	}
+	return h.send(m)
+}
+
+// frame returns the publish command for record. JetStream publishes ask
+// for an acknowledgement on the handler's inbox and carry a message ID.
+func (h *NATSHandler) frame(record []byte) []byte {
+	record = bytes.TrimSuffix(record, []byte("\n"))
+	if !h.jetStream {
+		f := h.out[:0]
+		f = append(f, "PUB "...)
+		f = append(f, h.subject...)
+		f = append(f, ' ')
+		f = strconv.AppendInt(f, int64(len(record)), 10)
+		f = append(f, "\r\n"...)
+		f = append(f, record...)
+		f = append(f, "\r\n"...)
+		h.out = f
+		return f
+	}
+	seq := strconv.FormatUint(h.seq, 10)
+	id := h.id + "-" + seq
+	if key, ok := IdempotencyKey(record); ok && validMsgID(key) {
+		id = key
+	}
+	hdr := "NATS/1.0\r\nNats-Msg-Id: " + id + "\r\n\r\n"
+	f := make([]byte, 0, len(h.subject)+len(h.id)+len(hdr)+len(record)+64)
+	f = append(f, "HPUB "...)
+	f = append(f, h.subject...)
+	f = append(f, " _INBOX."...)
+	f = append(f, h.id...)
+	f = append(f, '.')
+	f = append(f, seq...)
+	f = append(f, ' ')
+	f = strconv.AppendInt(f, int64(len(hdr)), 10)
+	f = append(f, ' ')
+	f = strconv.AppendInt(f, int64(len(hdr)+len(record)), 10)
+	f = append(f, "\r\n"...)
+	f = append(f, hdr...)
+	f = append(f, record...)
+	return append(f, "\r\n"...)
+}
+
+// validMsgID reports whether id can be sent as a header value as is:
+// printable ASCII without spaces or JSON escapes, so a caller-supplied key
+// cannot break the frame.
+func validMsgID(id string) bool {
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' || id[i] == '\\' {
+			return false
+		}
+	}
+	return id != ""
+}
+
+// reconnect dials again if RetryDelay has passed since the last attempt
+// and sends the held messages. It leaves h.conn nil if it did not try.
+func (h *NATSHandler) reconnect() error {
+	if h.now().Sub(h.lastDial) < h.retry {
+		return nil
+	}
+	h.lastDial = h.now()
+	conn, r, err := h.dial()
+	if err != nil {
+		return fmt.Errorf("bolt: nats: %w", err)
+	}
+	h.conn = conn
+	go h.readLoop(conn, r)
+	return h.drain()
+}
+
+// drain sends held messages in order while the in-flight limit allows.
+func (h *NATSHandler) drain() error {
+	for len(h.pending) > 0 && h.conn != nil && (!h.jetStream || len(h.unacked) < h.inFlight) {
+		m := h.pending[0]
+		h.pending[0] = natsMsg{}
+		h.pending = h.pending[1:]
+		h.pendingN -= len(m.frame)
+		if err := h.send(m); err != nil {
+			return err
+		}
+	}
+	if len(h.pending) == 0 {
+		h.pending = nil
+	}
+	return nil
+}
+
+// send writes one message. On failure it disconnects and keeps the
+// message, ahead of anything held.
+func (h *NATSHandler) send(m natsMsg) error {
+	if h.jetStream {
+		h.unacked[m.seq] = m.frame
+	}
+	h.conn.SetWriteDeadline(time.Now().Add(natsTimeout))
+	if _, err := h.conn.Write(m.frame); err != nil {
+		if !h.jetStream {
+			m.frame = slices.Clone(m.frame)
+			h.pending = append([]natsMsg{m}, h.pending...)
+			h.pendingN += len(m.frame)
+		}
+		h.disconnect()
+		return fmt.Errorf("bolt: nats: %w", err)
+	}
+	return nil
+}
+
+// disconnect closes the connection and moves unacknowledged messages
+// back in front of the held ones, to be resent after reconnecting.
+func (h *NATSHandler) disconnect() {
+	h.conn.Close()
+	h.conn = nil
+	if len(h.unacked) == 0 {
+		h.trim()
+		return
+	}
+	resend := make([]natsMsg, 0, len(h.unacked)+len(h.pending))
+	for seq, frame := range h.unacked {
+		resend = append(resend, natsMsg{seq, frame})
+		h.pendingN += len(frame)
+	}
+	clear(h.unacked)
+	slices.SortFunc(resend, func(a, b natsMsg) int { return cmp.Compare(a.seq, b.seq) })
+	h.pending = append(resend, h.pending...)
+	h.trim()
+}
+
+// hold keeps a copy of m to send later.
+func (h *NATSHandler) hold(m natsMsg) {
+	if !h.jetStream {
+		m.frame = slices.Clone(m.frame) // JetStream frames are already private
+	}
+	h.pending = append(h.pending, m)
+	h.pendingN += len(m.frame)
+	h.trim()
+}
+
+// trim drops the oldest held messages beyond the buffer size.
+func (h *NATSHandler) trim() {
+	for h.pendingN > h.maxBuf && len(h.pending) > 0 {
+		h.pendingN -= len(h.pending[0].frame)
+		h.pending[0] = natsMsg{}
+		h.pending = h.pending[1:]
+		h.dropped.Add(1)
+	}
+}
+
+// readLoop serves one connection: it answers server pings and processes
+// JetStream acknowledgements until the connection fails.
+func (h *NATSHandler) readLoop(conn net.Conn, r *bufio.Reader) {
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			h.lost(conn, err)
+			return
+		}
+		switch {
+		case strings.HasPrefix(line, "PING"):
+			h.mu.Lock()
+			if h.conn == conn {
+				conn.SetWriteDeadline(time.Now().Add(natsTimeout))
+				conn.Write([]byte("PONG\r\n"))
+			}
+			h.mu.Unlock()
+		case strings.HasPrefix(line, "MSG "), strings.HasPrefix(line, "HMSG "):
+			// MSG <subject> <sid> [reply] <size>
+			// HMSG <subject> <sid> [reply] <header size> <total size>
+			args := strings.Fields(line)
+			hdrLen := 0
+			total, err := strconv.Atoi(args[len(args)-1])
+			if err == nil && args[0] == "HMSG" {
+				hdrLen, err = strconv.Atoi(args[len(args)-2])
+			}
+			if err != nil || hdrLen > total {
+				h.lost(conn, fmt.Errorf("malformed %q", strings.TrimSpace(line)))
+				return
+			}
+			body := make([]byte, total+2) // with the trailing CRLF
+			if _, err := io.ReadFull(r, body); err != nil {
+				h.lost(conn, err)
+				return
+			}
+			h.ack(conn, args[1], body[:hdrLen], body[hdrLen:total])
+		case strings.HasPrefix(line, "-ERR"):
+			h.report(fmt.Errorf("bolt: nats: server error: %s", strings.TrimSpace(line[4:])))
+		}
+	}
+}
+
+// ack settles the publish an acknowledgement on subject answers.
+func (h *NATSHandler) ack(conn net.Conn, subject string, hdr, payload []byte) {
+	seq, err := strconv.ParseUint(subject[strings.LastIndexByte(subject, '.')+1:], 10, 64)
+	if err != nil {
+		return
+	}
+	var ackErr error
+	var reply struct {
+		Error *struct {
+			Code        int    `json:"code"`
+			Description string `json:"description"`
+		} `json:"error"`
+	}
+	if bytes.HasPrefix(hdr, []byte("NATS/1.0 503")) {
+		ackErr = fmt.Errorf("%w %q", ErrNATSNoResponders, h.subject)
+	} else if json.Unmarshal(payload, &reply) == nil && reply.Error != nil {
+		ackErr = fmt.Errorf("bolt: nats: publish rejected: %s (%d)", reply.Error.Description, reply.Error.Code)
+	}
+
+	h.mu.Lock()
+	if _, ok := h.unacked[seq]; ok {
+		delete(h.unacked, seq)
+		if ackErr != nil {
+			h.failed.Add(1)
+		}
+	} else {
+		ackErr = nil // late ack for a message already resent
+	}
+	var drainErr error
+	if h.conn == conn {
+		drainErr = h.drain()
+	}
+	h.mu.Unlock()
+	h.report(ackErr)
+	h.report(drainErr)
+}
+
+// lost handles a failed connection unless it was already replaced or
+// closed.
+func (h *NATSHandler) lost(conn net.Conn, err error) {
+	h.mu.Lock()
+	current := h.conn == conn
+	if current {
+		h.disconnect()
+	}
+	closed := h.closed
+	h.mu.Unlock()
+	if current && !closed {
+		h.report(fmt.Errorf("bolt: nats: connection lost: %w", err))
+	}
+}
+
+// report passes err, if any, to the error handler.
+func (h *NATSHandler) report(err error) {
+	if err != nil && h.onError != nil {
+		h.onError(err)
+	}
+}
+
+// Dropped returns the number of messages dropped because the buffer was
+// full while the server was unreachable.
+func (h *NATSHandler) Dropped() uint64 {
+	return h.dropped.Load()
+}
+
+// Failed returns the number of JetStream publishes the server rejected.
+func (h *NATSHandler) Failed() uint64 {
+	return h.failed.Load()
+}
+
+// Close tries once more to send held messages, waits briefly for
+// outstanding JetStream acknowledgements and closes the connection.
+// Writes after Close return ErrHandlerClosed.
+func (h *NATSHandler) Close() error {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return nil
+	}
+	h.closed = true
+	var err error
+	if h.conn == nil && len(h.pending) > 0 {
+		h.lastDial = time.Time{}
+		err = h.reconnect()
+	}
+	deadline := time.Now().Add(natsTimeout)
+	for h.conn != nil && (len(h.unacked) > 0 || len(h.pending) > 0) && time.Now().Before(deadline) {
+		h.mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		h.mu.Lock()
+	}
+	if n := len(h.unacked) + len(h.pending); n > 0 && err == nil {
+		err = fmt.Errorf("bolt: nats: %d messages not delivered at close", n)
+	}
+	if h.conn != nil {
+		conn := h.conn
+		h.conn = nil
+		if cerr := conn.Close(); err == nil {
+			err = cerr
+		}
+	}
+	h.mu.Unlock()
+	return err
+}
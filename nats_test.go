@@ -0,0 +1,265 @@
+package bolt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeNATS is a minimal NATS server recording published payloads. It
+// acknowledges JetStream publishes unless reject is set.
+type fakeNATS struct {
+	ln net.Listener
+
+	mu       sync.Mutex
+	reject   string // JetStream error description to reply with
+	conns    []net.Conn
+	connects []string
+	payloads []string
+	headers  []string
+}
+
+func newFakeNATS(t *testing.T) *fakeNATS {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeNATS{ln: ln}
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.conns = append(s.conns, c)
+			s.mu.Unlock()
+			go s.serve(c)
+		}
+	}()
+	t.Cleanup(func() { s.close() })
+	return s
+}
+
+func (s *fakeNATS) url() string { return "nats://" + s.ln.Addr().String() }
+
+func (s *fakeNATS) serve(c net.Conn) {
+	defer c.Close()
+	io.WriteString(c, `INFO {"server_id":"fake","headers":true,"max_payload":1048576}`+"\r\n")
+	r := bufio.NewReader(c)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		args := strings.Fields(line)
+		switch args[0] {
+		case "CONNECT":
+			s.mu.Lock()
+			s.connects = append(s.connects, strings.TrimSpace(line[len("CONNECT "):]))
+			s.mu.Unlock()
+		case "PING":
+			io.WriteString(c, "PONG\r\n")
+		case "PUB", "HPUB":
+			total, _ := strconv.Atoi(args[len(args)-1])
+			hdrLen := 0
+			if args[0] == "HPUB" {
+				hdrLen, _ = strconv.Atoi(args[len(args)-2])
+			}
+			body := make([]byte, total+2)
+			if _, err := io.ReadFull(r, body); err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.headers = append(s.headers, string(body[:hdrLen]))
+			s.payloads = append(s.payloads, string(body[hdrLen:total]))
+			seq, reject := len(s.payloads), s.reject
+			s.mu.Unlock()
+			if args[0] == "HPUB" {
+				ack := fmt.Sprintf(`{"stream":"LOGS","seq":%d}`, seq)
+				if reject != "" {
+					ack = `{"error":{"code":503,"description":"` + reject + `"}}`
+				}
+				fmt.Fprintf(c, "MSG %s 1 %d\r\n%s\r\n", args[2], len(ack), ack)
+			}
+		}
+	}
+}
+
+// drop closes every client connection.
+func (s *fakeNATS) drop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.conns {
+		c.Close()
+	}
+	s.conns = nil
+}
+
+func (s *fakeNATS) close() {
+	s.ln.Close()
+	s.drop()
+}
+
+func (s *fakeNATS) received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.payloads...)
+}
+
+func TestNATSHandlerPublishes(t *testing.T) {
+	srv := newFakeNATS(t)
+	h, err := NewNATSHandler(&NATSOptions{URL: strings.Replace(srv.url(), "nats://", "nats://ada:secret@", 1), Subject: "logs.api", Name: "api"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := New(h)
+	logger.Info().Str("user", "ada").Msg("signed in")
+	logger.Warn().Msg("slow")
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor(t, func() bool { return len(srv.received()) == 2 })
+	want := []string{`{"level":"info","user":"ada","message":"signed in"}`, `{"level":"warn","message":"slow"}`}
+	if got := srv.received(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if c := srv.connects[0]; !strings.Contains(c, `"name":"api"`) || !strings.Contains(c, `"user":"ada","pass":"secret"`) {
+		t.Errorf("CONNECT %s", c)
+	}
+	if err := h.Write(&Event{}); !errors.Is(err, ErrHandlerClosed) {
+		t.Errorf("Write after Close = %v", err)
+	}
+}
+
+func TestNATSHandlerJetStreamAcks(t *testing.T) {
+	srv := newFakeNATS(t)
+	h, err := NewNATSHandler(&NATSOptions{URL: srv.url(), Subject: "logs.api", JetStream: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := New(h)
+	for i := range 3 {
+		logger.Info().Int("i", i).Msg("ingested")
+	}
+	if err := h.Close(); err != nil {
+		t.Fatalf("Close = %v, want all publishes acknowledged", err)
+	}
+	if got := len(srv.received()); got != 3 {
+		t.Errorf("got %d messages, want 3", got)
+	}
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if hdr := srv.headers[0]; !strings.HasPrefix(hdr, "NATS/1.0\r\nNats-Msg-Id: "+h.id+"-1\r\n") {
+		t.Errorf("header = %q", hdr)
+	}
+	if h.Failed() != 0 {
+		t.Errorf("Failed = %d", h.Failed())
+	}
+}
+
+func TestNATSHandlerJetStreamIdempotencyKey(t *testing.T) {
+	srv := newFakeNATS(t)
+	h, err := NewNATSHandler(&NATSOptions{URL: srv.url(), Subject: "logs.api", JetStream: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := New(h)
+	logger.AddEventHook(NewIdempotencyKeyHook()).Info().Msg("keyed")
+	logger.Info().Str(IdempotencyKeyField, "bad\r\nkey").Msg("unsafe")
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	key, ok := IdempotencyKey([]byte(srv.payloads[0]))
+	if !ok || !strings.HasPrefix(srv.headers[0], "NATS/1.0\r\nNats-Msg-Id: "+key+"\r\n") {
+		t.Errorf("header %q does not carry the key of %s", srv.headers[0], srv.payloads[0])
+	}
+	if !strings.HasPrefix(srv.headers[1], "NATS/1.0\r\nNats-Msg-Id: "+h.id+"-2\r\n") {
+		t.Errorf("unsafe key used as message ID: %q", srv.headers[1])
+	}
+}
+
+func TestNATSHandlerJetStreamRejected(t *testing.T) {
+	srv := newFakeNATS(t)
+	srv.mu.Lock()
+	srv.reject = "stream storage full"
+	srv.mu.Unlock()
+	errs := make(chan error, 1)
+	h, err := NewNATSHandler(&NATSOptions{
+		URL: srv.url(), Subject: "logs.api", JetStream: true,
+		ErrorHandler: func(err error) { errs <- err },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	New(h).Error().Msg("lost")
+	select {
+	case err := <-errs:
+		if !strings.Contains(err.Error(), "stream storage full") {
+			t.Errorf("error = %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("rejection not reported")
+	}
+	if h.Failed() != 1 {
+		t.Errorf("Failed = %d, want 1", h.Failed())
+	}
+}
+
+func TestNATSHandlerReconnects(t *testing.T) {
+	srv := newFakeNATS(t)
+	h, err := NewNATSHandler(&NATSOptions{URL: srv.url(), Subject: "logs.api", RetryDelay: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	logger := New(h)
+	logger.Info().Msg("one")
+	waitFor(t, func() bool { return len(srv.received()) == 1 })
+
+	srv.drop()
+	waitFor(t, func() bool {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		return h.conn == nil
+	})
+	time.Sleep(2 * time.Millisecond)
+	logger.Info().Msg("two")
+	waitFor(t, func() bool { return len(srv.received()) == 2 })
+	if got := srv.received()[1]; got != `{"level":"info","message":"two"}` {
+		t.Errorf("after reconnect got %s", got)
+	}
+}
+
+func TestNATSHandlerInvalidSubject(t *testing.T) {
+	for _, subject := range []string{"", "logs.*", "a b"} {
+		if _, err := NewNATSHandler(&NATSOptions{Subject: subject}); err == nil {
+			t.Errorf("subject %q accepted", subject)
+		}
+	}
+}
+
+// waitFor polls cond for up to two seconds.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(2 * time.Second); !cond(); {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
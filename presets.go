@@ -0,0 +1,117 @@
+package bolt
+
+import (
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// Sampling applied by [Production] to events below WARN: within each
+// second the first ProductionSampleFirst events with a given level and
+// message pass, then one in every ProductionSampleThereafter.
+const (
+	ProductionSampleFirst      = 100
+	ProductionSampleThereafter = 100
+)
+
+// presetStackSkip drops the Go runtime, vendored code and bolt's own
+// frames from the stack traces the presets attach, so traces start at
+// the code that logged.
+var presetStackSkip = append(append([]string(nil), DefaultStackSkip...), "go.klarlabs.de/bolt.")
+
+// Production returns a logger with defaults for production services:
+//   - JSON to stdout at INFO;
+//   - events below WARN sampled per second and per level and message
+//     (see ProductionSampleFirst), except from loggers elevated through
+//     [DebugBaggageKey];
+//   - a "stack" trace on ERROR and FATAL events;
+//   - no "caller", which costs a stack lookup per event.
+//
+// The result is an ordinary Logger; adjust it with SetLevel, AddHook and
+// the other setters as needed.
+func Production() *Logger {
+	return production(NewJSONHandler(os.Stdout))
+}
+
+// production applies the Production configuration to a logger writing
+// to h.
+func production(h Handler) *Logger {
+	return New(h).
+		SetLevel(INFO).
+		SetStackOptions(&StackOptions{Skip: presetStackSkip}).
+		AddEventHook(&burstSampler{first: ProductionSampleFirst, thereafter: ProductionSampleThereafter, period: time.Second}).
+		AddEventHook(stackHook{min: ERROR})
+}
+
+// Development returns a logger for local development: colored console
+// output to stdout at DEBUG, "caller" and "func" on every event, a
+// "stack" trace from WARN up, and no sampling.
+func Development() *Logger {
+	return New(NewConsoleHandler(os.Stdout)).
+		SetLevel(DEBUG).
+		SetCallerOptions(&CallerOptions{Always: true, Function: true}).
+		SetStackOptions(&StackOptions{Skip: presetStackSkip}).
+		AddEventHook(stackHook{min: WARN})
+}
+
+// Test returns a logger for tests that records every event, TRACE
+// included, as JSON in the returned buffer. Nothing is sampled and no
+// caller or stack fields are added, so output is deterministic.
+func Test() (*Logger, *ThreadSafeBuffer) {
+	buf := &ThreadSafeBuffer{}
+	return New(NewJSONHandler(buf)).SetLevel(TRACE), buf
+}
+
+// stackHook adds a "stack" field to events at or above min.
+type stackHook struct {
+	min Level
+}
+
+func (h stackHook) Run(e *Event, _ string) bool {
+	if e.Level() >= h.min {
+		e.Stack()
+	}
+	return true
+}
+
+// burstSamplerSlots is the number of counters per level. Messages are
+// hashed onto them, so a rare message only shares its budget with the
+// few that collide with it.
+const burstSamplerSlots = 4096
+
+// burstSampler passes the first events of each period below WARN and one
+// in thereafter after that, counting each level and message separately,
+// as zap's sampler does. WARN and above, and elevated events, always
+// pass.
+type burstSampler struct {
+	first, thereafter uint64
+	period            time.Duration
+
+	counters [WARN][burstSamplerSlots]burstCounter
+}
+
+// burstCounter counts the events of one level and message slot in the
+// current period.
+type burstCounter struct {
+	start atomic.Int64 // current period start, unix nanoseconds
+	count atomic.Uint64
+}
+
+func (s *burstSampler) Run(e *Event, message string) bool {
+	level := e.Level()
+	if level >= WARN || level < TRACE || e.Elevated() {
+		return true
+	}
+	h := uint64(14695981039346656037) // FNV-1a; not security relevant
+	for i := 0; i < len(message); i++ {
+		h ^= uint64(message[i])
+		h *= 1099511628211
+	}
+	c := &s.counters[level][h%burstSamplerSlots]
+	now := time.Now().UnixNano()
+	if start := c.start.Load(); now-start >= int64(s.period) && c.start.CompareAndSwap(start, now) {
+		c.count.Store(0)
+	}
+	n := c.count.Add(1)
+	return n <= s.first || (n-s.first)%s.thereafter == 0
+}
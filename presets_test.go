@@ -0,0 +1,109 @@
+package bolt
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTestPreset(t *testing.T) {
+	logger, buf := Test()
+	logger.Trace().Msg("fine detail")
+	if got, want := buf.String(), `{"level":"trace","message":"fine detail"}`+"\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestProductionPreset(t *testing.T) {
+	logger := Production()
+	if logger.GetLevel() != INFO {
+		t.Errorf("level = %v, want INFO", logger.GetLevel())
+	}
+	var buf bytes.Buffer
+	logger.handler = newHandlerRef(NewJSONHandler(&buf))
+
+	for range ProductionSampleFirst + 2*ProductionSampleThereafter {
+		logger.Info().Msg("tick")
+	}
+	if n := strings.Count(buf.String(), "\n"); n != ProductionSampleFirst+2 {
+		t.Errorf("kept %d info events, want %d", n, ProductionSampleFirst+2)
+	}
+	if strings.Contains(buf.String(), `"stack"`) {
+		t.Error("stack added below ERROR")
+	}
+
+	buf.Reset()
+	logger.Error().Msg("failed")
+	var rec struct{ Stack string }
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatal(err)
+	}
+	// The test itself is in package bolt, so every bolt frame is skipped
+	// and the trace starts at the test runner.
+	if !strings.HasPrefix(rec.Stack, "testing.tRunner") {
+		t.Errorf("stack contains bolt or runtime frames:\n%s", rec.Stack)
+	}
+}
+
+func TestBurstSamplerPeriod(t *testing.T) {
+	s := &burstSampler{first: 1, thereafter: 1000, period: 20 * time.Millisecond}
+	e := &Event{level: INFO}
+	if !s.Run(e, "") || s.Run(e, "") {
+		t.Fatal("want first event kept and second dropped")
+	}
+	time.Sleep(25 * time.Millisecond)
+	if !s.Run(e, "") {
+		t.Error("first event of a new period dropped")
+	}
+	if !s.Run(&Event{level: WARN}, "") {
+		t.Error("WARN event sampled")
+	}
+}
+
+func TestBurstSamplerPerMessage(t *testing.T) {
+	s := &burstSampler{first: 2, thereafter: 1000, period: time.Hour}
+	info, debug := &Event{level: INFO}, &Event{level: DEBUG}
+	for range 10 {
+		s.Run(info, "chatty")
+	}
+	if s.Run(info, "chatty") {
+		t.Error("chatty message not sampled")
+	}
+	if !s.Run(info, "rare") {
+		t.Error("rare message dropped because of a chatty one")
+	}
+	if !s.Run(debug, "chatty") {
+		t.Error("DEBUG event dropped because of INFO events with the same message")
+	}
+}
+
+func TestCallerAlways(t *testing.T) {
+	var buf bytes.Buffer
+	logger := Development()
+	logger.handler = newHandlerRef(NewJSONHandler(&buf))
+	old := defaultLogger
+	defaultLogger = logger
+	defer func() { defaultLogger = old }()
+
+	_, _, line, _ := runtime.Caller(0)
+	logger.Info().Msg("method")
+	logger.Infof("formatted %d", 1)
+	Info().Msg("package")
+	Warnf("package formatted")
+	_, _ = NewLevelWriter(logger, INFO).Write([]byte("writer\n"))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("got %d events: %s", len(lines), buf.String())
+	}
+	for i, l := range lines {
+		want := `"caller":"presets_test.go:` + strconv.Itoa(line+1+i) + `","func":"bolt.TestCallerAlways"`
+		if !strings.Contains(l, want) {
+			t.Errorf("event %d: want %s in %s", i, want, l)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package bolt
+
+import _ "unsafe" // for go:linkname
+
+// procPin pins the calling goroutine to its P and returns the P's ID,
+// until procUnpin. sync.Pool uses the same runtime hooks for its per-P
+// slots.
+//
+//go:linkname procPin runtime.procPin
+func procPin() int
+
+//go:linkname procUnpin runtime.procUnpin
+func procUnpin()
@@ -0,0 +1,164 @@
+package bolt
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// LoggerNameField is the field under which [Quotas.Logger] names the
+// loggers it returns, as logrsink and zapbolt do.
+const LoggerNameField = "logger"
+
+// QuotaLimit is the emission quota of one named logger. Zero fields are
+// unlimited.
+type QuotaLimit struct {
+	// EventsPerMinute is the sustained number of events per minute.
+	EventsPerMinute int
+	// BytesPerMinute is the sustained number of encoded bytes per
+	// minute.
+	BytesPerMinute int
+}
+
+// QuotaOptions configures [NewQuotas]. A nil *QuotaOptions leaves every
+// logger unlimited.
+type QuotaOptions struct {
+	// Default is the quota of names not listed in Limits.
+	Default QuotaLimit
+
+	// Limits overrides the quota of individual names.
+	Limits map[string]QuotaLimit
+}
+
+// QuotaStats are the counters of one named logger.
+type QuotaStats struct {
+	Name         string `json:"name"`
+	Events       uint64 `json:"events"`        // events passed
+	Bytes        uint64 `json:"bytes"`         // bytes passed
+	Dropped      uint64 `json:"dropped"`       // events dropped over quota
+	DroppedBytes uint64 `json:"dropped_bytes"` // bytes of dropped events
+}
+
+// Quotas limits how much each named subsystem may log, so that one noisy
+// component cannot starve a shared [AsyncHandler] queue or spend the log
+// budget of the whole binary. Each name gets two token buckets, for
+// events and for bytes, that refill continuously at the per-minute rate
+// and hold up to one minute's worth, so short bursts pass while a
+// sustained flood is cut to the quota:
+//
+//	quotas := bolt.NewQuotas(&bolt.QuotaOptions{
+//	    Default: bolt.QuotaLimit{EventsPerMinute: 6000, BytesPerMinute: 4 << 20},
+//	    Limits:  map[string]bolt.QuotaLimit{"cache": {EventsPerMinute: 600}},
+//	})
+//	cacheLog := quotas.Logger(logger, "cache")
+//
+// Events over quota are dropped before they reach the handler and
+// counted in [Quotas.Stats]. FATAL events always pass. Bytes are
+// measured as the encoded size of the event.
+type Quotas struct {
+	opts QuotaOptions
+	now  func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*quotaBucket
+}
+
+// quotaBucket is the state of one name, shared by all loggers with it.
+type quotaBucket struct {
+	name  string
+	limit QuotaLimit
+	now   func() time.Time
+
+	mu     sync.Mutex
+	last   time.Time
+	events float64 // available tokens
+	bytes  float64
+	stats  QuotaStats
+}
+
+// NewQuotas returns quotas configured by opts.
+func NewQuotas(opts *QuotaOptions) *Quotas {
+	q := &Quotas{now: time.Now, buckets: make(map[string]*quotaBucket)}
+	if opts != nil {
+		q.opts = *opts
+	}
+	return q
+}
+
+// Logger returns a child of l that carries name under LoggerNameField
+// and is subject to the quota of name. Loggers returned for the same name
+// share one quota; derive further loggers from the result to keep it.
+func (q *Quotas) Logger(l *Logger, name string) *Logger {
+	return l.With().Str(LoggerNameField, name).Logger().Hook(q.bucket(name))
+}
+
+func (q *Quotas) bucket(name string) *quotaBucket {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	b, ok := q.buckets[name]
+	if !ok {
+		limit, ok := q.opts.Limits[name]
+		if !ok {
+			limit = q.opts.Default
+		}
+		b = &quotaBucket{
+			name:   name,
+			limit:  limit,
+			now:    q.now,
+			last:   q.now(),
+			events: float64(limit.EventsPerMinute),
+			bytes:  float64(limit.BytesPerMinute),
+		}
+		b.stats.Name = name
+		q.buckets[name] = b
+	}
+	return b
+}
+
+// Stats returns the counters of every name, sorted by name.
+func (q *Quotas) Stats() []QuotaStats {
+	q.mu.Lock()
+	buckets := make([]*quotaBucket, 0, len(q.buckets))
+	for _, b := range q.buckets {
+		buckets = append(buckets, b)
+	}
+	q.mu.Unlock()
+
+	stats := make([]QuotaStats, len(buckets))
+	for i, b := range buckets {
+		b.mu.Lock()
+		stats[i] = b.stats
+		b.mu.Unlock()
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats
+}
+
+// Run implements [EventHook].
+func (b *quotaBucket) Run(e *Event, msg string) bool {
+	// Final size once Msg appends `,"message":"…"}` and the newline.
+	size := len(e.Buffer()) + len(`,"message":""}`) + len(msg) + 1
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	elapsed := now.Sub(b.last).Minutes()
+	b.last = now
+	if n := b.limit.EventsPerMinute; n > 0 {
+		b.events = min(b.events+elapsed*float64(n), float64(n))
+	}
+	if n := b.limit.BytesPerMinute; n > 0 {
+		b.bytes = min(b.bytes+elapsed*float64(n), float64(n))
+	}
+	if e.Level() < FATAL &&
+		(b.limit.EventsPerMinute > 0 && b.events < 1 || b.limit.BytesPerMinute > 0 && b.bytes < float64(size)) {
+		b.stats.Dropped++
+		b.stats.DroppedBytes += uint64(size) // #nosec G115 -- size is non-negative
+		return false
+	}
+	b.events--
+	b.bytes -= float64(size)
+	b.stats.Events++
+	b.stats.Bytes += uint64(size) // #nosec G115 -- size is non-negative
+	return true
+}
@@ -0,0 +1,88 @@
+package bolt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQuotasLimitEvents(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewJSONHandler(&buf))
+	q := NewQuotas(&QuotaOptions{
+		Default: QuotaLimit{EventsPerMinute: 3},
+		Limits:  map[string]QuotaLimit{"quiet": {EventsPerMinute: 1}},
+	})
+	now := time.Unix(0, 0)
+	q.now = func() time.Time { return now }
+
+	noisy := q.Logger(logger, "noisy")
+	quiet := q.Logger(logger, "quiet")
+	for range 5 {
+		noisy.Info().Msg("spam")
+		quiet.Info().Msg("hello")
+	}
+	if n := strings.Count(buf.String(), `"logger":"noisy"`); n != 3 {
+		t.Errorf("noisy passed %d events, want 3", n)
+	}
+	if n := strings.Count(buf.String(), `"logger":"quiet"`); n != 1 {
+		t.Errorf("quiet passed %d events, want 1", n)
+	}
+
+	// Tokens refill at the per-minute rate.
+	now = now.Add(20 * time.Second)
+	noisy.Info().Msg("spam")
+	noisy.Info().Msg("spam")
+	if n := strings.Count(buf.String(), `"logger":"noisy"`); n != 4 {
+		t.Errorf("noisy passed %d events after refill, want 4", n)
+	}
+
+	// Loggers for one name share its quota; FATAL always passes.
+	q.Logger(logger, "noisy").Info().Msg("spam")
+	noisy.Fatal().Msg("fatal")
+	if n := strings.Count(buf.String(), `"logger":"noisy"`); n != 5 || !strings.Contains(buf.String(), "fatal") {
+		t.Errorf("got %s", buf.String())
+	}
+
+	stats := q.Stats()
+	if len(stats) != 2 || stats[0].Name != "noisy" || stats[1].Name != "quiet" {
+		t.Fatalf("stats = %+v", stats)
+	}
+	if s := stats[0]; s.Events != 5 || s.Dropped != 4 || s.Bytes == 0 || s.DroppedBytes == 0 {
+		t.Errorf("noisy stats = %+v", s)
+	}
+	if s := stats[1]; s.Events != 1 || s.Dropped != 4 {
+		t.Errorf("quiet stats = %+v", s)
+	}
+}
+
+func TestQuotasLimitBytes(t *testing.T) {
+	var buf bytes.Buffer
+	q := NewQuotas(&QuotaOptions{Default: QuotaLimit{BytesPerMinute: 250}})
+	q.now = func() time.Time { return time.Unix(0, 0) }
+	logger := q.Logger(New(NewJSONHandler(&buf)), "payloads")
+
+	logger.Info().Str("blob", strings.Repeat("x", 100)).Msg("big")
+	logger.Info().Msg("small")
+	logger.Info().Str("blob", strings.Repeat("x", 100)).Msg("big")
+
+	if strings.Count(buf.String(), "big") != 1 || !strings.Contains(buf.String(), "small") {
+		t.Errorf("got %s", buf.String())
+	}
+	if s := q.Stats()[0]; s.Dropped != 1 || s.Bytes > 250 {
+		t.Errorf("stats = %+v", s)
+	}
+}
+
+func TestQuotasUnlimited(t *testing.T) {
+	var buf bytes.Buffer
+	q := NewQuotas(nil)
+	logger := q.Logger(New(NewJSONHandler(&buf)), "free")
+	for range 100 {
+		logger.Info().Msg("x")
+	}
+	if n := strings.Count(buf.String(), "\n"); n != 100 {
+		t.Errorf("passed %d events", n)
+	}
+}
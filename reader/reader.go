@@ -0,0 +1,233 @@
+// Package reader stream-parses the newline-delimited JSON produced by
+// bolt's JSONHandler into typed records.
+//
+// Every tool that consumes bolt output (the CLI, replay, verification)
+// should go through this package instead of hand-rolling a parser. The
+// reader is line-oriented: each call to [Reader.Read] consumes exactly one
+// line and returns either a [*Record] or a [*ParseError]. A ParseError does
+// not poison the stream, so callers can skip corrupt lines and keep going,
+// the same way encoding/csv behaves. That includes lines too long to be a
+// bolt record, which are skipped and reported as [ErrLineTooLong].
+//
+// Only the JSON wire format is supported today. Lines that do not start
+// with '{' are reported as [ErrUnsupportedFormat] so that future binary
+// encodings can be detected rather than mis-parsed.
+//
+// Example:
+//
+//	r := reader.New(f)
+//	for {
+//	    rec, err := r.Read()
+//	    if err == io.EOF {
+//	        break
+//	    }
+//	    var perr *reader.ParseError
+//	    if errors.As(err, &perr) {
+//	        continue // skip the corrupt line
+//	    }
+//	    if err != nil {
+//	        return err
+//	    }
+//	    fmt.Println(rec.Message(), rec.Str("user_id"))
+//	}
+package reader
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"go.klarlabs.de/bolt"
+)
+
+// maxLineSize bounds a single record: the longest line bolt writes.
+const maxLineSize = bolt.MaxRecordSize
+
+// ErrUnsupportedFormat is returned (wrapped in a [*ParseError]) for lines
+// that are not JSON objects.
+var ErrUnsupportedFormat = errors.New("reader: unsupported record format")
+
+// ErrLineTooLong is returned (wrapped in a [*ParseError]) for lines longer
+// than any record bolt writes. The rest of the line is skipped.
+var ErrLineTooLong = errors.New("reader: line too long")
+
+// ParseError describes a line that could not be decoded. The reader
+// remains usable after a ParseError is returned.
+type ParseError struct {
+	Line int   // 1-based line number within the stream
+	Err  error // underlying decode error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("reader: line %d: %v", e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Reader reads bolt records from an input stream.
+type Reader struct {
+	br   *bufio.Reader
+	buf  []byte // the current line
+	line int
+}
+
+// New returns a Reader that reads from r.
+func New(r io.Reader) *Reader {
+	return &Reader{br: bufio.NewReaderSize(r, bolt.DefaultBufferSize)}
+}
+
+// Read returns the next record. Blank lines are skipped. At end of input
+// Read returns (nil, io.EOF).
+func (r *Reader) Read() (*Record, error) {
+	for {
+		line, tooLong, err := r.readLine()
+		if err != nil {
+			return nil, err
+		}
+		r.line++
+		if tooLong {
+			return nil, &ParseError{Line: r.line, Err: ErrLineTooLong}
+		}
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		rec, err := Parse(line)
+		if err != nil {
+			return nil, &ParseError{Line: r.line, Err: err}
+		}
+		rec.line = r.line
+		return rec, nil
+	}
+}
+
+// ReadLine returns the next line, without its line ending and without
+// parsing it, for tools that filter or pass lines through before
+// decoding them. Blank lines are returned too. A line too long to be a
+// bolt record is skipped and reported as a [*ParseError] wrapping
+// [ErrLineTooLong]. The returned slice is only valid until the next call.
+// At end of input ReadLine returns (nil, io.EOF).
+func (r *Reader) ReadLine() ([]byte, error) {
+	line, tooLong, err := r.readLine()
+	if err != nil {
+		return nil, err
+	}
+	r.line++
+	if tooLong {
+		return nil, &ParseError{Line: r.line, Err: ErrLineTooLong}
+	}
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	return bytes.TrimSuffix(line, []byte("\r")), nil
+}
+
+// readLine reads the next line in chunks of the reader's buffer. A line
+// longer than maxLineSize is read to its end but not kept, and reported
+// by tooLong.
+func (r *Reader) readLine() (line []byte, tooLong bool, err error) {
+	r.buf = r.buf[:0]
+	for {
+		chunk, rerr := r.br.ReadSlice('\n')
+		if !tooLong && len(r.buf)+len(chunk) > maxLineSize {
+			tooLong, r.buf = true, r.buf[:0]
+		}
+		if !tooLong {
+			r.buf = append(r.buf, chunk...)
+		}
+		switch {
+		case rerr == bufio.ErrBufferFull:
+			continue
+		case rerr == io.EOF && (len(r.buf) > 0 || tooLong):
+			return r.buf, tooLong, nil // last line without a newline
+		case rerr != nil:
+			return nil, false, rerr
+		}
+		return r.buf, tooLong, nil
+	}
+}
+
+// Line returns the line number of the most recently read line.
+func (r *Reader) Line() int {
+	return r.line
+}
+
+// Parse decodes a single bolt record. The input is copied, so the caller
+// may reuse it after Parse returns.
+func Parse(line []byte) (*Record, error) {
+	if len(line) == 0 || line[0] != '{' {
+		return nil, ErrUnsupportedFormat
+	}
+	raw := append([]byte(nil), line...)
+	fields, err := decodeObject(raw)
+	if err != nil {
+		return nil, err
+	}
+	rec := &Record{raw: raw, fields: fields}
+	if err := rec.unwrap(); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// unwrap replaces the fields of a bolt.EnvelopeHandler envelope,
+// {"v":N,"data":{...}}, with those of the enveloped record.
+func (r *Record) unwrap() error {
+	if len(r.fields) != 2 || r.fields[0].Key != "v" || r.fields[1].Key != "data" ||
+		r.fields[1].Value.Kind() != KindObject {
+		return nil
+	}
+	v, ok := r.fields[0].Value.Int64()
+	if !ok || v < 1 {
+		return nil
+	}
+	fields, err := decodeObject(r.fields[1].Value.raw)
+	if err != nil {
+		return err
+	}
+	r.fields, r.version = fields, int(v)
+	return nil
+}
+
+// errTrailingData is returned for a line with more than one JSON value.
+var errTrailingData = errors.New("reader: data after the JSON object")
+
+// decodeObject splits a JSON object into its top-level members, preserving
+// the order in which they were encoded.
+func decodeObject(raw []byte) ([]Field, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '{' {
+		return nil, ErrUnsupportedFormat
+	}
+	var fields []Field
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return nil, fmt.Errorf("unexpected token %v", tok)
+		}
+		var v json.RawMessage
+		if err := dec.Decode(&v); err != nil {
+			return nil, err
+		}
+		fields = append(fields, Field{Key: key, Value: Value{raw: v}})
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(raw[dec.InputOffset():])) != 0 {
+		return nil, errTrailingData
+	}
+	return fields, nil
+}
@@ -0,0 +1,269 @@
+package reader_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"go.klarlabs.de/bolt"
+	"go.klarlabs.de/bolt/reader"
+)
+
+func TestRead_RoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	logger := bolt.New(bolt.NewJSONHandler(&buf))
+	ts := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	logger.Warn().
+		Str("user_id", "42").
+		Int("status", 503).
+		Float64("ratio", 0.25).
+		Bool("retry", true).
+		Time("timestamp", ts).
+		Dict("http", func(d *bolt.Event) {
+			d.Str("method", "GET").Int("code", 200)
+		}).
+		Msg("upstream slow")
+
+	r := reader.New(&buf)
+	rec, err := r.Read()
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+
+	if lvl, ok := rec.Level(); !ok || lvl != bolt.WARN {
+		t.Errorf("Level = %v, %v; want warn", lvl, ok)
+	}
+	if got := rec.Message(); got != "upstream slow" {
+		t.Errorf("Message = %q", got)
+	}
+	if got := rec.Str("user_id"); got != "42" {
+		t.Errorf("user_id = %q", got)
+	}
+	if v, _ := rec.Get("status"); v.Kind() != reader.KindNumber {
+		t.Errorf("status kind = %v", v.Kind())
+	} else if n, ok := v.Int64(); !ok || n != 503 {
+		t.Errorf("status = %d, %v", n, ok)
+	}
+	ratio, _ := rec.Get("ratio")
+	if f, ok := ratio.Float64(); !ok || f != 0.25 {
+		t.Errorf("ratio = %s", ratio.Raw())
+	}
+	retry, _ := rec.Get("retry")
+	if b, ok := retry.Bool(); !ok || !b {
+		t.Errorf("retry = %s", retry.Raw())
+	}
+	if got, ok := rec.Time(); !ok || !got.Equal(ts) {
+		t.Errorf("Time = %v, %v; want %v", got, ok, ts)
+	}
+	if v, ok := rec.Lookup("http.code"); !ok || v.String() != "200" {
+		t.Errorf("http.code = %q, %v", v.String(), ok)
+	}
+	if rec.Line() != 1 {
+		t.Errorf("Line = %d", rec.Line())
+	}
+
+	keys := make([]string, 0, len(rec.Fields()))
+	for _, f := range rec.Fields() {
+		keys = append(keys, f.Key)
+	}
+	want := "level,user_id,status,ratio,retry,timestamp,http,message"
+	if got := strings.Join(keys, ","); got != want {
+		t.Errorf("field order = %s; want %s", got, want)
+	}
+
+	if _, err := r.Read(); err != io.EOF {
+		t.Errorf("second Read err = %v; want io.EOF", err)
+	}
+}
+
+func TestRead_SkipsCorruptLines(t *testing.T) {
+	input := strings.Join([]string{
+		`{"level":"info","message":"one"}`,
+		``,
+		`{"level":"info","message":`,
+		`plain text line`,
+		`{"level":"info","message":"three"}garbage`,
+		`{"level":"error","message":"two"}  `,
+	}, "\n")
+	r := reader.New(strings.NewReader(input))
+
+	var msgs []string
+	var parseErrs []*reader.ParseError
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		var perr *reader.ParseError
+		if errors.As(err, &perr) {
+			parseErrs = append(parseErrs, perr)
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Read: %v", err)
+		}
+		msgs = append(msgs, rec.Message())
+	}
+
+	if strings.Join(msgs, ",") != "one,two" {
+		t.Errorf("messages = %v", msgs)
+	}
+	if len(parseErrs) != 3 {
+		t.Fatalf("got %d parse errors; want 3", len(parseErrs))
+	}
+	if parseErrs[0].Line != 3 || parseErrs[1].Line != 4 || parseErrs[2].Line != 5 {
+		t.Errorf("error lines = %d,%d,%d; want 3,4,5", parseErrs[0].Line, parseErrs[1].Line, parseErrs[2].Line)
+	}
+	if !errors.Is(parseErrs[1], reader.ErrUnsupportedFormat) {
+		t.Errorf("plain text line err = %v; want ErrUnsupportedFormat", parseErrs[1])
+	}
+}
+
+func TestRead_SkipsOversizedLine(t *testing.T) {
+	huge := `{"level":"info","blob":"` + strings.Repeat("x", 2*bolt.MaxBufferSize) + `"}`
+	input := strings.Join([]string{
+		`{"level":"info","message":"before"}`,
+		huge,
+		`{"level":"info","message":"after"}`,
+		huge, // last line, without a newline
+	}, "\n")
+	r := reader.New(strings.NewReader(input))
+
+	var msgs []string
+	var lines []int
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		var perr *reader.ParseError
+		if errors.As(err, &perr) && errors.Is(err, reader.ErrLineTooLong) {
+			lines = append(lines, perr.Line)
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Read: %v", err)
+		}
+		msgs = append(msgs, rec.Message())
+	}
+	if strings.Join(msgs, ",") != "before,after" {
+		t.Errorf("messages = %v", msgs)
+	}
+	if len(lines) != 2 || lines[0] != 2 || lines[1] != 4 {
+		t.Errorf("oversized lines reported at %v; want [2 4]", lines)
+	}
+}
+
+func TestRead_LargestRecord(t *testing.T) {
+	var buf bytes.Buffer
+	var logErr error
+	logger := bolt.New(bolt.NewJSONHandler(&buf)).SetErrorHandler(func(err error) { logErr = err })
+	e := logger.Info()
+	value := strings.Repeat("y", bolt.MaxValueLength)
+	for i := 0; i < bolt.MaxBufferSize/bolt.MaxValueLength-1; i++ {
+		e = e.Str(fmt.Sprint("k", i), value)
+	}
+	e.Msg(strings.Repeat("\x01", bolt.MaxValueLength)) // escaped as \u0001
+	if logErr != nil {
+		t.Fatal(logErr)
+	}
+
+	size := buf.Len()
+	rec, err := reader.New(&buf).Read()
+	if err != nil {
+		t.Fatalf("Read of a %d-byte record bolt wrote: %v", size, err)
+	}
+	if len(rec.Message()) != bolt.MaxValueLength {
+		t.Errorf("message length = %d", len(rec.Message()))
+	}
+}
+
+func TestReadLine(t *testing.T) {
+	huge := strings.Repeat("x", 2*bolt.MaxBufferSize)
+	r := reader.New(strings.NewReader("plain text\r\n\n" + huge + "\n{\"level\":\"info\"}"))
+
+	var got []string
+	for {
+		line, err := r.ReadLine()
+		if err == io.EOF {
+			break
+		}
+		if errors.Is(err, reader.ErrLineTooLong) {
+			got = append(got, "<skipped>")
+			continue
+		}
+		if err != nil {
+			t.Fatalf("ReadLine: %v", err)
+		}
+		got = append(got, string(line))
+	}
+	want := []string{"plain text", "", "<skipped>", `{"level":"info"}`}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("lines = %q; want %q", got, want)
+	}
+	if r.Line() != 4 {
+		t.Errorf("Line() = %d; want 4", r.Line())
+	}
+}
+
+func TestValue_SpecialFloats(t *testing.T) {
+	rec, err := reader.Parse([]byte(`{"a":"NaN","b":"-Inf","c":"nope"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, _ := rec.Get("a")
+	if f, ok := a.Float64(); !ok || !math.IsNaN(f) {
+		t.Error("NaN not decoded")
+	}
+	b, _ := rec.Get("b")
+	if f, ok := b.Float64(); !ok || !math.IsInf(f, -1) {
+		t.Error("-Inf not decoded")
+	}
+	c, _ := rec.Get("c")
+	if _, ok := c.Float64(); ok {
+		t.Error("arbitrary string decoded as float")
+	}
+}
+
+func TestLookup_DottedKeyPreferred(t *testing.T) {
+	rec, err := reader.Parse([]byte(`{"gen_ai.system":"openai","gen_ai":{"system":"other"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := rec.Lookup("gen_ai.system"); v.String() != "openai" {
+		t.Errorf("Lookup = %q; want exact key match", v.String())
+	}
+	if _, ok := rec.Lookup("missing.path"); ok {
+		t.Error("Lookup of missing path reported ok")
+	}
+}
+
+func TestParse_Envelope(t *testing.T) {
+	var buf bytes.Buffer
+	bolt.New(bolt.NewEnvelopeHandler(bolt.NewJSONHandler(&buf), 3)).Info().Str("user", "ada").Msg("hi")
+	rec, err := reader.Parse(bytes.TrimSpace(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Version() != 3 || rec.Message() != "hi" || rec.Str("user") != "ada" {
+		t.Errorf("got version %d, message %q, user %q", rec.Version(), rec.Message(), rec.Str("user"))
+	}
+	if !bytes.HasPrefix(rec.Raw(), []byte(`{"v":3,`)) {
+		t.Errorf("Raw() = %s; want the envelope", rec.Raw())
+	}
+
+	// An ordinary record that happens to have these keys is not unwrapped.
+	rec, err = reader.Parse([]byte(`{"v":"x","data":{"a":1}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Version() != 0 || len(rec.Fields()) != 2 {
+		t.Errorf("non-envelope unwrapped: version %d, %d fields", rec.Version(), len(rec.Fields()))
+	}
+}
@@ -0,0 +1,241 @@
+package reader
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.klarlabs.de/bolt"
+)
+
+// Kind identifies the JSON type of a [Value].
+type Kind uint8
+
+// Value kinds.
+const (
+	KindInvalid Kind = iota
+	KindString
+	KindNumber
+	KindBool
+	KindNull
+	KindObject
+	KindArray
+)
+
+// Field is a single top-level key/value pair of a record.
+type Field struct {
+	Key   string
+	Value Value
+}
+
+// Value is a raw JSON value with typed accessors. The zero Value is
+// KindInvalid and every accessor reports ok == false for it.
+type Value struct {
+	raw json.RawMessage
+}
+
+// Kind reports the JSON type of the value.
+func (v Value) Kind() Kind {
+	if len(v.raw) == 0 {
+		return KindInvalid
+	}
+	switch c := v.raw[0]; {
+	case c == '"':
+		return KindString
+	case c == '{':
+		return KindObject
+	case c == '[':
+		return KindArray
+	case c == 't' || c == 'f':
+		return KindBool
+	case c == 'n':
+		return KindNull
+	default:
+		return KindNumber
+	}
+}
+
+// Raw returns the value's JSON encoding. The slice must not be modified.
+func (v Value) Raw() []byte {
+	return v.raw
+}
+
+// Str returns the decoded string. ok is false if the value is not a
+// JSON string.
+func (v Value) Str() (s string, ok bool) {
+	if v.Kind() != KindString {
+		return "", false
+	}
+	if err := json.Unmarshal(v.raw, &s); err != nil {
+		return "", false
+	}
+	return s, true
+}
+
+// Int64 returns the value as an int64. ok is false for non-numbers and
+// for numbers with a fractional part.
+func (v Value) Int64() (int64, bool) {
+	if v.Kind() != KindNumber {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(string(v.raw), 10, 64)
+	return n, err == nil
+}
+
+// Float64 returns the value as a float64. Bolt encodes NaN and ±Inf as
+// the strings "NaN", "+Inf" and "-Inf"; those are decoded here as well.
+func (v Value) Float64() (float64, bool) {
+	switch v.Kind() {
+	case KindNumber:
+		f, err := strconv.ParseFloat(string(v.raw), 64)
+		return f, err == nil
+	case KindString:
+		s, _ := v.Str()
+		switch s {
+		case "NaN", "+Inf", "-Inf":
+			f, err := strconv.ParseFloat(s, 64)
+			return f, err == nil
+		}
+	}
+	return 0, false
+}
+
+// Bool returns the value as a bool. ok is false for non-booleans.
+func (v Value) Bool() (b bool, ok bool) {
+	if v.Kind() != KindBool {
+		return false, false
+	}
+	return bytes.Equal(v.raw, []byte("true")), true
+}
+
+// Time parses a string value as RFC 3339.
+func (v Value) Time() (time.Time, bool) {
+	s, ok := v.Str()
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	return t, err == nil
+}
+
+// String renders the value for display: strings are unquoted, everything
+// else is returned as its JSON literal.
+func (v Value) String() string {
+	if s, ok := v.Str(); ok {
+		return s
+	}
+	return string(v.raw)
+}
+
+// Record is one decoded log line.
+type Record struct {
+	raw     []byte
+	fields  []Field
+	line    int
+	version int
+}
+
+// Raw returns the record exactly as read, without the trailing newline.
+func (r *Record) Raw() []byte {
+	return r.raw
+}
+
+// Version returns the envelope version of a record written through
+// bolt.EnvelopeHandler, or 0 for a bare record. Enveloped records are
+// unwrapped: Fields, Get and the other accessors see the inner record,
+// while Raw still returns the line as read.
+func (r *Record) Version() int {
+	return r.version
+}
+
+// Line returns the 1-based line number the record was read from, or 0 if
+// it was produced by [Parse] directly.
+func (r *Record) Line() int {
+	return r.line
+}
+
+// Fields returns the record's top-level fields in encoding order,
+// including the reserved level and message fields.
+func (r *Record) Fields() []Field {
+	return r.fields
+}
+
+// Get returns the top-level field with the given key. If the key occurs
+// more than once the last occurrence wins, matching encoding/json.
+func (r *Record) Get(key string) (Value, bool) {
+	for i := len(r.fields) - 1; i >= 0; i-- {
+		if r.fields[i].Key == key {
+			return r.fields[i].Value, true
+		}
+	}
+	return Value{}, false
+}
+
+// Lookup resolves a dotted path such as "http.status" through nested
+// objects produced by [bolt.Event.Dict]. An exact top-level key match is
+// tried first so that dotted field names (e.g. "gen_ai.system") resolve
+// without traversal.
+func (r *Record) Lookup(path string) (Value, bool) {
+	if v, ok := r.Get(path); ok {
+		return v, true
+	}
+	head, rest, found := strings.Cut(path, ".")
+	if !found {
+		return Value{}, false
+	}
+	v, ok := r.Get(head)
+	for ok && v.Kind() == KindObject {
+		sub, err := decodeObject(v.raw)
+		if err != nil {
+			return Value{}, false
+		}
+		nested := Record{fields: sub}
+		if v, ok = nested.Get(rest); ok {
+			return v, true
+		}
+		head, rest, found = strings.Cut(rest, ".")
+		if !found {
+			return Value{}, false
+		}
+		v, ok = nested.Get(head)
+	}
+	return Value{}, false
+}
+
+// Str returns the string field for key, or "" if it is absent or not a
+// string.
+func (r *Record) Str(key string) string {
+	v, _ := r.Get(key)
+	s, _ := v.Str()
+	return s
+}
+
+// Message returns the record's message field.
+func (r *Record) Message() string {
+	return r.Str("message")
+}
+
+// Level returns the record's level. ok is false if the level field is
+// missing or not one of bolt's level names.
+func (r *Record) Level() (bolt.Level, bool) {
+	s := r.Str("level")
+	for l := bolt.TRACE; l <= bolt.FATAL; l++ {
+		if l.String() == s {
+			return l, true
+		}
+	}
+	return bolt.INFO, false
+}
+
+// Time returns the record's timestamp, looking at the "time" field written
+// by SlogHandler and the "timestamp" field written by Event.Timestamp.
+func (r *Record) Time() (time.Time, bool) {
+	for _, key := range [...]string{"time", "timestamp"} {
+		if v, ok := r.Get(key); ok {
+			return v.Time()
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,461 @@
+package bolt
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"regexp"
+)
+
+// RedactedValue replaces values masked with [MaskFull].
+const RedactedValue = "[REDACTED]"
+
+// Masking selects how [RedactionHandler] masks a sensitive value.
+type Masking int
+
+const (
+	// MaskFull replaces the value with RedactedValue.
+	MaskFull Masking = iota
+	// MaskPartial keeps the last four characters and replaces the rest
+	// with '*', as is customary for card numbers. Values of four
+	// characters or fewer become "****".
+	MaskPartial
+	// MaskHash replaces the value with "hmac-sha256:" and the first 16
+	// hex digits of its HMAC-SHA-256 under [RedactionOptions.HashKey], so
+	// equal values can still be correlated. The key keeps low-entropy
+	// values such as SSNs from being recovered by hashing every
+	// candidate.
+	MaskHash
+	// MaskDrop removes the field. Array elements become null.
+	MaskDrop
+)
+
+// Detector finds sensitive text inside string values.
+type Detector struct {
+	// Pattern matches the sensitive text.
+	Pattern *regexp.Regexp
+	// Valid, if set, confirms a match, for checks a regular expression
+	// cannot express such as checksums.
+	Valid func(match []byte) bool
+	// Mask is applied to each match; MaskDrop removes the whole field.
+	Mask Masking
+}
+
+// Built-in detectors. Copy one and change Mask to mask differently:
+//
+//	email := bolt.EmailDetector
+//	email.Mask = bolt.MaskHash
+var (
+	// EmailDetector matches email addresses.
+	EmailDetector = Detector{
+		Pattern: regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`),
+	}
+	// SSNDetector matches US social security numbers written as
+	// 123-45-6789.
+	SSNDetector = Detector{
+		Pattern: regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`),
+	}
+	// CardDetector matches payment card numbers of 13 to 19 digits,
+	// optionally grouped by spaces or dashes, that pass the Luhn check.
+	// It keeps the last four digits.
+	CardDetector = Detector{
+		Pattern: regexp.MustCompile(`\b\d(?:[ \-]?\d){12,18}\b`),
+		Valid:   luhnValid,
+		Mask:    MaskPartial,
+	}
+)
+
+// DefaultDetectors are the detectors [NewRedactionHandler] uses when
+// [RedactionOptions.Detectors] is nil.
+var DefaultDetectors = []Detector{EmailDetector, SSNDetector, CardDetector}
+
+// DefaultRedactKeys are the field keys [NewRedactionHandler] masks when
+// [RedactionOptions.Keys] is nil.
+var DefaultRedactKeys = []string{
+	"password", "passwd", "secret", "client_secret", "token", "access_token",
+	"refresh_token", "api_key", "apikey", "authorization", "cookie", "set-cookie",
+	"private_key",
+}
+
+// ErrRedactHashKey is returned by [NewRedactionHandler] when [MaskHash]
+// is used without [RedactionOptions.HashKey].
+var ErrRedactHashKey = errors.New("bolt: MaskHash requires RedactionOptions.HashKey")
+
+// RedactionOptions configures [NewRedactionHandler]. A nil
+// *RedactionOptions uses the defaults.
+type RedactionOptions struct {
+	// Keys lists field keys whose values are always masked, matched
+	// case-insensitively at any nesting depth. nil uses
+	// DefaultRedactKeys; an empty non-nil slice masks no keys.
+	Keys []string
+	// KeyMask is the masking applied to the values of Keys. Defaults to
+	// MaskFull.
+	KeyMask Masking
+	// Detectors scan every string value, the message included, for
+	// sensitive text. nil uses DefaultDetectors; an empty non-nil slice
+	// disables detection.
+	Detectors []Detector
+	// HashKey is the HMAC key for [MaskHash], required when KeyMask or
+	// a detector uses it. Keep it secret and share it only between
+	// services whose masked values must correlate.
+	HashKey []byte
+}
+
+// RedactionHandler masks sensitive values in every record before
+// passing it on, replacing hand-rolled masking around the logger:
+//
+//	h, _ := bolt.NewRedactionHandler(bolt.NewJSONHandler(os.Stdout), nil)
+//	logger := bolt.New(h)
+//	logger.Info().Str("password", pw).Str("contact", "ann@example.com").Msg("signup")
+//	// {"level":"info","password":"[REDACTED]","contact":"[REDACTED]","message":"signup"}
+//
+// Values are masked when their key is deny-listed or a detector matches
+// inside a string value. Records without sensitive values are passed on
+// unchanged and without allocating; only records that are masked are
+// copied. Detectors run regular expressions over every string value, so
+// their cost grows with the amount of text logged.
+//
+// RedactionHandler expects JSON records, such as those built by bolt or
+// accepted by [Logger.Forward]; it may wrap any handler, ConsoleHandler
+// included.
+type RedactionHandler struct {
+	next      Handler
+	keys      [][]byte
+	keyMask   Masking
+	detectors []Detector
+	hashKey   []byte
+}
+
+// NewRedactionHandler returns a RedactionHandler writing to next. If
+// opts is nil, defaults are used. It returns [ErrRedactHashKey] if
+// [MaskHash] is used without a HashKey.
+func NewRedactionHandler(next Handler, opts *RedactionOptions) (*RedactionHandler, error) {
+	h := &RedactionHandler{next: next, detectors: DefaultDetectors}
+	keys := DefaultRedactKeys
+	if opts != nil {
+		if opts.Keys != nil {
+			keys = opts.Keys
+		}
+		if opts.Detectors != nil {
+			h.detectors = opts.Detectors
+		}
+		h.keyMask = opts.KeyMask
+		h.hashKey = opts.HashKey
+	}
+	if len(h.hashKey) == 0 {
+		hashed := h.keyMask == MaskHash
+		for _, d := range h.detectors {
+			hashed = hashed || d.Mask == MaskHash
+		}
+		if hashed {
+			return nil, ErrRedactHashKey
+		}
+	}
+	for _, k := range keys {
+		h.keys = append(h.keys, []byte(k))
+	}
+	return h, nil
+}
+
+// Write implements [Handler].
+func (h *RedactionHandler) Write(e *Event) error {
+	r := redactor{h: h, src: e.buf}
+	if len(r.src) > 0 && r.src[0] == '{' {
+		r.object(0)
+	}
+	if r.env == nil {
+		return h.next.Write(e)
+	}
+
+	env := r.env
+	env.level, env.l, env.ctx = e.level, e.l, e.ctx
+	env.buf = append(r.dst, r.src[r.copied:]...)
+	err := h.next.Write(env)
+
+	if cap(env.buf) > PoolBufferCap {
+		env.buf = nil
+	} else {
+		env.buf = env.buf[:0]
+	}
+	env.l = nil
+	putEvent(env)
+	return err
+}
+
+func (h *RedactionHandler) denied(key []byte) bool {
+	for _, k := range h.keys {
+		if bytes.EqualFold(key, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactor walks one record, copying it to dst from the first edit on.
+type redactor struct {
+	h      *RedactionHandler
+	src    []byte
+	env    *Event // holds dst's storage once a value is masked
+	dst    []byte
+	copied int // src[:copied] has been copied or replaced in dst
+}
+
+// replace replaces src[start:end] with repl.
+func (r *redactor) replace(start, end int, repl []byte) {
+	if r.env == nil {
+		r.env = getEvent()
+		r.dst = r.env.buf[:0]
+	}
+	r.dst = append(r.dst, r.src[r.copied:start]...)
+	r.dst = append(r.dst, repl...)
+	r.copied = end
+}
+
+// object walks the object at src[i] and returns the index after it.
+func (r *redactor) object(i int) int {
+	i++ // '{'
+	for i < len(r.src) {
+		i = skipWhitespace(r.src, i)
+		if i >= len(r.src) {
+			break
+		}
+		switch r.src[i] {
+		case '}':
+			return i + 1
+		case ',':
+			i++
+			continue
+		case '"':
+		default:
+			return len(r.src) // not JSON; leave the rest alone
+		}
+		field := i
+		keyEnd := scanJSONString(r.src, i)
+		key := r.src[i+1 : keyEnd-1]
+		i = skipWhitespace(r.src, keyEnd)
+		if i < len(r.src) && r.src[i] == ':' {
+			i++
+		}
+		i = skipWhitespace(r.src, i)
+		end := scanJSONValue(r.src, i)
+		if r.h.denied(key) {
+			r.mask(field, i, end, r.h.keyMask)
+		} else {
+			r.inspect(field, i, end)
+		}
+		i = end
+	}
+	return i
+}
+
+// array walks the array at src[i] and returns the index after it.
+func (r *redactor) array(i int) int {
+	i++ // '['
+	for i < len(r.src) {
+		i = skipWhitespace(r.src, i)
+		if i >= len(r.src) {
+			break
+		}
+		switch r.src[i] {
+		case ']':
+			return i + 1
+		case ',':
+			i++
+			continue
+		}
+		end := scanJSONValue(r.src, i)
+		r.inspect(-1, i, end)
+		i = end
+	}
+	return i
+}
+
+// inspect looks for sensitive values in src[start:end], the value of
+// the field starting at field, or an array element if field is -1.
+func (r *redactor) inspect(field, start, end int) {
+	if start >= end {
+		return
+	}
+	switch r.src[start] {
+	case '{':
+		r.object(start)
+	case '[':
+		r.array(start)
+	case '"':
+		r.detect(field, start, end)
+	}
+}
+
+// detect runs the detectors over the string at src[start:end].
+func (r *redactor) detect(field, start, end int) {
+	text := r.src[start+1 : end-1]
+	hit := false
+	for i := range r.h.detectors {
+		if r.h.detectors[i].Pattern.Match(text) {
+			hit = true
+			break
+		}
+	}
+	if !hit {
+		return
+	}
+
+	out := append([]byte(nil), text...)
+	changed := false
+	for i := range r.h.detectors {
+		d := &r.h.detectors[i]
+		var masked []byte
+		last := 0
+		for _, loc := range d.Pattern.FindAllIndex(out, -1) {
+			if d.Valid != nil && !d.Valid(out[loc[0]:loc[1]]) {
+				continue
+			}
+			if d.Mask == MaskDrop {
+				r.drop(field, start, end)
+				return
+			}
+			masked = append(masked, out[last:loc[0]]...)
+			masked = append(masked, maskValue(out[loc[0]:loc[1]], d.Mask, r.h.hashKey)...)
+			last = loc[1]
+		}
+		if masked != nil {
+			out = append(masked, out[last:]...)
+			changed = true
+		}
+	}
+	if changed {
+		r.replace(start+1, end-1, out)
+	}
+}
+
+// mask masks the whole value at src[start:end].
+func (r *redactor) mask(field, start, end int, m Masking) {
+	if m == MaskDrop {
+		r.drop(field, start, end)
+		return
+	}
+	value := r.src[start:end]
+	if len(value) >= 2 && value[0] == '"' {
+		value = value[1 : len(value)-1]
+	} else if len(value) > 0 && (value[0] == '{' || value[0] == '[') && m == MaskPartial {
+		m = MaskFull
+	}
+	repl := append([]byte{'"'}, maskValue(value, m, r.h.hashKey)...)
+	r.replace(start, end, append(repl, '"'))
+}
+
+// drop removes the field starting at field with its value ending at
+// end, or replaces an array element at src[start:end] with null.
+func (r *redactor) drop(field, start, end int) {
+	if field < 0 {
+		r.replace(start, end, []byte("null"))
+		return
+	}
+	// Remove the preceding comma, or the following one if this is now
+	// the object's first field.
+	if p := field - 1; p >= r.copied && r.src[p] == ',' {
+		r.replace(p, end, nil)
+		return
+	}
+	next := skipWhitespace(r.src, end)
+	if next < len(r.src) && r.src[next] == ',' {
+		end = next + 1
+	}
+	r.replace(max(field, r.copied), end, nil)
+}
+
+// maskValue returns the masked form of the JSON-escaped text v. key is
+// the HMAC key for MaskHash.
+func maskValue(v []byte, m Masking, key []byte) []byte {
+	switch m {
+	case MaskPartial:
+		if len(v) <= 4 {
+			return []byte("****")
+		}
+		if bytes.IndexByte(v[max(0, len(v)-9):], '\\') >= 0 {
+			return []byte(RedactedValue) // the tail may split an escape sequence
+		}
+		tail := v[len(v)-4:]
+		return append(bytes.Repeat([]byte{'*'}, len(v)-4), tail...)
+	case MaskHash:
+		mac := hmac.New(sha256.New, key)
+		mac.Write(v)
+		return append([]byte("hmac-sha256:"), hex.EncodeToString(mac.Sum(nil)[:8])...)
+	default:
+		return []byte(RedactedValue)
+	}
+}
+
+// scanJSONString returns the index after the string starting at
+// buf[i] == '"'.
+func scanJSONString(buf []byte, i int) int {
+	for i++; i < len(buf); i++ {
+		switch buf[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(buf)
+}
+
+// scanJSONValue returns the index after the value starting at buf[i].
+func scanJSONValue(buf []byte, i int) int {
+	if i >= len(buf) {
+		return i
+	}
+	switch buf[i] {
+	case '"':
+		return scanJSONString(buf, i)
+	case '{', '[':
+		depth := 0
+		for i < len(buf) {
+			switch buf[i] {
+			case '"':
+				i = scanJSONString(buf, i)
+				continue
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+				if depth == 0 {
+					return i + 1
+				}
+			}
+			i++
+		}
+		return i
+	}
+	for i < len(buf) {
+		switch buf[i] {
+		case ',', '}', ']', ' ', '\t', '\n', '\r':
+			return i
+		}
+		i++
+	}
+	return i
+}
+
+// luhnValid reports whether the digits in s pass the Luhn checksum.
+func luhnValid(s []byte) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n > 0 && sum%10 == 0
+}
@@ -0,0 +1,145 @@
+package bolt
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRedactionHandlerDefaults(t *testing.T) {
+	var buf bytes.Buffer
+	h, err := NewRedactionHandler(NewJSONHandler(&buf), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := New(h)
+
+	logger.Info().
+		Str("Password", "hunter2").
+		Str("contact", "mail ann@example.com or bob@example.org").
+		Str("ssn", "123-45-6789").
+		Str("card", "4111 1111 1111 1111").
+		Str("order", "4111111111111112"). // fails the Luhn check
+		Dict("req", func(d *Event) { d.Str("authorization", "Bearer x").Int("n", 1) }).
+		Msg("signup by ann@example.com")
+
+	want := `{"level":"info","Password":"[REDACTED]","contact":"mail [REDACTED] or [REDACTED]",` +
+		`"ssn":"[REDACTED]","card":"***************1111","order":"4111111111111112",` +
+		`"req":{"authorization":"[REDACTED]","n":1},"message":"signup by [REDACTED]"}` + "\n"
+	if buf.String() != want {
+		t.Errorf("got  %s\nwant %s", buf.String(), want)
+	}
+}
+
+func TestRedactionHandlerMasks(t *testing.T) {
+	var buf bytes.Buffer
+	email := EmailDetector
+	email.Mask = MaskHash
+	drop := Detector{Pattern: regexp.MustCompile(`sk_live_\w+`), Mask: MaskDrop}
+	h, err := NewRedactionHandler(NewJSONHandler(&buf), &RedactionOptions{
+		Keys:      []string{"pin", "secret", "blob"},
+		KeyMask:   MaskDrop,
+		Detectors: []Detector{email, drop},
+		HashKey:   []byte("test-key"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := New(h)
+
+	logger.Info().
+		Str("user", "ann@example.com").
+		Int("pin", 1234).
+		Str("note", "key sk_live_abc").
+		Strs("tags", []string{"ok", "sk_live_def"}).
+		Any("blob", map[string]int{"a": 1}).
+		Msg("done")
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	if u, _ := got["user"].(string); !strings.HasPrefix(u, "hmac-sha256:") || len(u) != len("hmac-sha256:")+16 {
+		t.Errorf("user = %q, want hmac-sha256 hash", got["user"])
+	}
+	for _, k := range []string{"pin", "note", "blob"} {
+		if _, ok := got[k]; ok {
+			t.Errorf("%s not dropped: %s", k, buf.String())
+		}
+	}
+	if tags, _ := got["tags"].([]any); len(tags) != 2 || tags[0] != "ok" || tags[1] != nil {
+		t.Errorf("tags = %v, want [ok <nil>]", got["tags"])
+	}
+
+	// Dropping the first fields of an object keeps it valid.
+	buf.Reset()
+	logger.Info().Dict("d", func(d *Event) { d.Str("secret", "a").Int("pin", 1).Int("n", 2) }).
+		Dict("e", func(d *Event) { d.Str("secret", "a") }).Send()
+	if want := `{"level":"info","d":{"n":2},"e":{}}` + "\n"; buf.String() != want {
+		t.Errorf("got  %s\nwant %s", buf.String(), want)
+	}
+}
+
+func TestMaskValue(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		m    Masking
+		want string
+	}{
+		{"4111111111111111", MaskPartial, "************1111"},
+		{"abc", MaskPartial, "****"},
+		{`ab\ncdefghijk`, MaskPartial, "*********hijk"},
+		{`abcdefgh\n`, MaskPartial, RedactedValue},
+		{"x", MaskFull, RedactedValue},
+	} {
+		if got := string(maskValue([]byte(tc.in), tc.m, nil)); got != tc.want {
+			t.Errorf("maskValue(%q, %d) = %q, want %q", tc.in, tc.m, got, tc.want)
+		}
+	}
+}
+
+func TestRedactionHandlerHashKey(t *testing.T) {
+	email := EmailDetector
+	email.Mask = MaskHash
+	if _, err := NewRedactionHandler(NewJSONHandler(io.Discard), &RedactionOptions{Detectors: []Detector{email}}); !errors.Is(err, ErrRedactHashKey) {
+		t.Errorf("detector MaskHash without key: err = %v", err)
+	}
+	if _, err := NewRedactionHandler(NewJSONHandler(io.Discard), &RedactionOptions{KeyMask: MaskHash}); !errors.Is(err, ErrRedactHashKey) {
+		t.Errorf("KeyMask MaskHash without key: err = %v", err)
+	}
+
+	// The digest depends on the key, so it cannot be precomputed.
+	a := maskValue([]byte("123-45-6789"), MaskHash, []byte("key-a"))
+	b := maskValue([]byte("123-45-6789"), MaskHash, []byte("key-b"))
+	if bytes.Equal(a, b) {
+		t.Errorf("digests under different keys are equal: %s", a)
+	}
+	if !bytes.Equal(a, maskValue([]byte("123-45-6789"), MaskHash, []byte("key-a"))) {
+		t.Error("digest not stable for equal values")
+	}
+}
+
+func TestRedactionHandlerCleanRecordDoesNotAllocate(t *testing.T) {
+	var buf bytes.Buffer
+	h, _ := NewRedactionHandler(NewJSONHandler(&buf), nil)
+	e := &Event{buf: []byte(`{"level":"info","user":"ann","n":3,"tags":["a","b"],"req":{"path":"/x"},"message":"ok"}` + "\n"), level: INFO}
+	_ = h.Write(e)
+	if buf.String() != string(e.buf) {
+		t.Errorf("clean record changed: %s", buf.String())
+	}
+
+	if raceDetectorEnabled {
+		t.Skip("allocation counts are not meaningful under -race")
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		buf.Reset()
+		_ = h.Write(e)
+	})
+	if allocs != 0 {
+		t.Errorf("allocs per clean record = %v, want 0", allocs)
+	}
+}
@@ -0,0 +1,19 @@
+module go.klarlabs.de/bolt/redislog
+
+go 1.25.0
+
+require (
+	github.com/redis/go-redis/v9 v9.22.0
+	go.klarlabs.de/bolt v1.4.0
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	go.opentelemetry.io/otel v1.43.0 // indirect
+	go.opentelemetry.io/otel/trace v1.43.0 // indirect
+	go.uber.org/atomic v1.11.0 // indirect
+	golang.org/x/sys v0.44.0 // indirect
+	golang.org/x/term v0.43.0 // indirect
+)
+
+replace go.klarlabs.de/bolt => ../
@@ -0,0 +1,177 @@
+// Package redislog provides a go-redis hook that writes one bolt event
+// per Redis command, pipeline and dial, in the format of
+// [go.klarlabs.de/bolt/dblog].
+//
+// Values are redacted by default: the statement keeps the command name
+// and its key and replaces every other argument with "?". A missing key
+// (redis.Nil) is logged as a successful command with error_class
+// "not_found".
+//
+// The package lives in its own go.mod so that the bolt core does not
+// depend on github.com/redis/go-redis.
+//
+// Example:
+//
+//	rdb := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+//	rdb.AddHook(redislog.NewHook(dblog.New(logger, nil)))
+package redislog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"go.klarlabs.de/bolt/dblog"
+)
+
+// maxStatementArgs bounds the arguments written per command, so that
+// commands such as MSET or large pipelines stay readable.
+const maxStatementArgs = 16
+
+// keyless lists commands whose first argument is not a key and may be a
+// secret or a script, so all their arguments are redacted.
+var keyless = map[string]bool{
+	"auth": true, "hello": true, "acl": true, "config": true, "client": true,
+	"migrate": true, "script": true, "function": true,
+	"eval": true, "evalsha": true, "eval_ro": true, "evalsha_ro": true, "fcall": true, "fcall_ro": true,
+}
+
+// Hook is a [redis.Hook] logging to a [dblog.Logger].
+type Hook struct {
+	l *dblog.Logger
+}
+
+var _ redis.Hook = (*Hook)(nil)
+
+// NewHook returns a hook logging to l; install it with AddHook on a
+// client, cluster client or ring.
+func NewHook(l *dblog.Logger) *Hook {
+	return &Hook{l: l}
+}
+
+// DialHook implements [redis.Hook]. It logs every new connection as the
+// operation "dial".
+func (h *Hook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		start := time.Now()
+		conn, err := next(ctx, network, addr)
+		h.l.Log(ctx, dblog.Command{
+			System:    "redis",
+			Operation: "dial",
+			Statement: network + " " + addr,
+			Duration:  time.Since(start),
+			Err:       err,
+			ErrClass:  classify(err),
+		})
+		return conn, err
+	}
+}
+
+// ProcessHook implements [redis.Hook].
+func (h *Hook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		h.l.Log(ctx, dblog.Command{
+			System:    "redis",
+			Operation: strings.ToUpper(cmd.FullName()),
+			Statement: h.statement(cmd),
+			Duration:  time.Since(start),
+			Err:       err,
+			ErrClass:  classify(err),
+		})
+		return err
+	}
+}
+
+// ProcessPipelineHook implements [redis.Hook]. A pipeline or transaction
+// is logged as one event, with the operation "pipeline" and the
+// statements of its commands separated by "; ". Its error is that of the
+// first failed command.
+func (h *Hook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		var sb strings.Builder
+		for i, cmd := range cmds {
+			if i == maxStatementArgs {
+				fmt.Fprintf(&sb, "; … %d more", len(cmds)-i)
+				break
+			}
+			if i > 0 {
+				sb.WriteString("; ")
+			}
+			sb.WriteString(h.statement(cmd))
+		}
+		c := dblog.Command{System: "redis", Operation: "pipeline", Statement: sb.String(), Duration: time.Since(start), Err: err}
+		if c.Err == nil {
+			for _, cmd := range cmds {
+				if cmd.Err() != nil && cmd.Err() != redis.Nil {
+					c.Err = cmd.Err()
+					break
+				}
+			}
+		}
+		c.ErrClass = classify(c.Err)
+		h.l.Log(ctx, c)
+		return err
+	}
+}
+
+// statement returns cmd as text, with values redacted unless the logger
+// logs them.
+func (h *Hook) statement(cmd redis.Cmder) string {
+	args := cmd.Args()
+	var sb strings.Builder
+	for i, arg := range args {
+		if i == maxStatementArgs {
+			fmt.Fprintf(&sb, " … %d more", len(args)-i)
+			break
+		}
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		switch {
+		case i == 0:
+			sb.WriteString(strings.ToUpper(fmt.Sprint(arg)))
+		case h.l.LogValues() || i == 1 && !keyless[cmd.Name()]:
+			fmt.Fprint(&sb, arg)
+		default:
+			sb.WriteString(dblog.Redacted)
+		}
+	}
+	return sb.String()
+}
+
+// classify returns the class of the Redis-specific errors, or "" to leave
+// err to [dblog.Classify].
+func classify(err error) string {
+	if err == nil {
+		return ""
+	}
+	if err == redis.Nil {
+		return dblog.ClassNotFound
+	}
+	if errors.Is(err, redis.ErrPoolTimeout) {
+		return dblog.ClassTimeout
+	}
+	var rerr redis.Error
+	if errors.As(err, &rerr) {
+		msg := rerr.Error()
+		switch {
+		case strings.HasPrefix(msg, "NOAUTH"), strings.HasPrefix(msg, "WRONGPASS"), strings.HasPrefix(msg, "NOPERM"):
+			return dblog.ClassAuth
+		case strings.HasPrefix(msg, "BUSY"), strings.HasPrefix(msg, "LOADING"), strings.HasPrefix(msg, "TRYAGAIN"):
+			return dblog.ClassThrottled
+		case strings.HasPrefix(msg, "ERR"), strings.HasPrefix(msg, "WRONGTYPE"):
+			return dblog.ClassClient
+		}
+		return dblog.ClassServer
+	}
+	return ""
+}
@@ -0,0 +1,128 @@
+package redislog_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+
+	"go.klarlabs.de/bolt"
+	"go.klarlabs.de/bolt/dblog"
+	"go.klarlabs.de/bolt/redislog"
+)
+
+func events(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var m map[string]any
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("invalid JSON %q: %v", line, err)
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+func newHook(buf *bytes.Buffer, opts *dblog.Options) *redislog.Hook {
+	return redislog.NewHook(dblog.New(bolt.New(bolt.NewJSONHandler(buf)).SetLevel(bolt.DEBUG), opts))
+}
+
+func TestProcessHookRedactsValues(t *testing.T) {
+	var buf bytes.Buffer
+	h := newHook(&buf, nil)
+	ctx := context.Background()
+	process := h.ProcessHook(func(ctx context.Context, cmd redis.Cmder) error { return cmd.Err() })
+
+	_ = process(ctx, redis.NewStatusCmd(ctx, "set", "user:42", "secret", "ex", 60))
+	_ = process(ctx, redis.NewStatusCmd(ctx, "auth", "admin", "hunter2"))
+	get := redis.NewStringCmd(ctx, "get", "user:43")
+	get.SetErr(redis.Nil)
+	if err := process(ctx, get); err != redis.Nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	evs := events(t, &buf)
+	if len(evs) != 3 {
+		t.Fatalf("got %d events: %s", len(evs), buf.String())
+	}
+	if ev := evs[0]; ev["db"] != "redis" || ev["operation"] != "SET" || ev["statement"] != "SET user:42 ? ? ?" || ev["level"] != "debug" {
+		t.Errorf("set = %v", ev)
+	}
+	if ev := evs[1]; ev["statement"] != "AUTH ? ?" {
+		t.Errorf("auth = %v", ev)
+	}
+	if ev := evs[2]; ev["level"] != "debug" || ev["error_class"] != "not_found" {
+		t.Errorf("get = %v", ev)
+	}
+	if strings.Contains(buf.String(), "secret") || strings.Contains(buf.String(), "hunter2") {
+		t.Errorf("values leaked: %s", buf.String())
+	}
+}
+
+func TestProcessHookLogValues(t *testing.T) {
+	var buf bytes.Buffer
+	h := newHook(&buf, &dblog.Options{LogValues: true})
+	ctx := context.Background()
+	_ = h.ProcessHook(func(context.Context, redis.Cmder) error { return nil })(ctx, redis.NewStatusCmd(ctx, "set", "k", "v"))
+	if ev := events(t, &buf)[0]; ev["statement"] != "SET k v" {
+		t.Errorf("event = %v", ev)
+	}
+}
+
+func TestProcessHookErrors(t *testing.T) {
+	var buf bytes.Buffer
+	h := newHook(&buf, nil)
+	ctx := context.Background()
+	for _, err := range []error{
+		context.DeadlineExceeded,
+		redis.ErrPoolTimeout,
+		&net.OpError{Op: "read", Err: errors.New("connection reset by peer")},
+	} {
+		_ = h.ProcessHook(func(context.Context, redis.Cmder) error { return err })(ctx, redis.NewStringCmd(ctx, "get", "k"))
+	}
+
+	evs := events(t, &buf)
+	for i, want := range []string{"timeout", "timeout", "network"} {
+		if evs[i]["level"] != "error" || evs[i]["error_class"] != want {
+			t.Errorf("event %d = %v, want class %s", i, evs[i], want)
+		}
+	}
+}
+
+func TestProcessPipelineHook(t *testing.T) {
+	var buf bytes.Buffer
+	h := newHook(&buf, nil)
+	ctx := context.Background()
+	incr := redis.NewIntCmd(ctx, "incr", "hits")
+	hget := redis.NewStringCmd(ctx, "hget", "user:42", "email")
+	hget.SetErr(errors.New("boom"))
+	pipeline := h.ProcessPipelineHook(func(context.Context, []redis.Cmder) error { return nil })
+	if err := pipeline(ctx, []redis.Cmder{incr, hget}); err != nil {
+		t.Fatal(err)
+	}
+
+	ev := events(t, &buf)[0]
+	if ev["operation"] != "pipeline" || ev["statement"] != "INCR hits; HGET user:42 ?" || ev["level"] != "error" || ev["error"] != "boom" {
+		t.Errorf("event = %v", ev)
+	}
+}
+
+func TestDialHook(t *testing.T) {
+	var buf bytes.Buffer
+	h := newHook(&buf, nil)
+	dial := h.DialHook(func(context.Context, string, string) (net.Conn, error) {
+		return nil, &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+	})
+	if _, err := dial(context.Background(), "tcp", "localhost:6379"); err == nil {
+		t.Fatal("dial succeeded")
+	}
+	if ev := events(t, &buf)[0]; ev["operation"] != "dial" || ev["statement"] != "tcp localhost:6379" || ev["error_class"] != "network" {
+		t.Errorf("event = %v", ev)
+	}
+}
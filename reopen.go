@@ -0,0 +1,90 @@
+package bolt
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+)
+
+// Reopener is implemented by sinks that can close and reopen their
+// underlying file, such as [SharedFile] and [RotatingFile].
+type Reopener interface {
+	Reopen() error
+}
+
+// ReopenOnSignal reopens sinks whenever the process receives one of sigs,
+// so logrotate (or any tool that renames the file and signals the
+// process) can rotate logs without copytruncate and without a restart.
+// If sigs is empty, SIGHUP and SIGUSR1 are used; platforms without those
+// signals (Windows) get no default and ReopenOnSignal does nothing.
+//
+// After each signal it logs an INFO "log files reopened" event carrying
+// the signal and the reopened file names, which lands at the top of the
+// new files. Sinks that fail to reopen keep writing to the old file and
+// are reported in an ERROR "log file reopen failed" event.
+//
+// A typical logrotate stanza:
+//
+//	/var/log/app/*.log {
+//	    daily
+//	    rotate 7
+//	    postrotate
+//	        pkill -USR1 -x app
+//	    endscript
+//	}
+//
+// stop unregisters the signals and waits for an in-progress reopen to
+// finish; it is safe to call more than once.
+func ReopenOnSignal(logger *Logger, sinks []Reopener, sigs ...os.Signal) (stop func()) {
+	if len(sigs) == 0 {
+		sigs = defaultReopenSignals
+	}
+	if len(sigs) == 0 {
+		return func() {}
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		for {
+			select {
+			case sig := <-ch:
+				reopenSinks(logger, sinks, sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+			<-exited
+		})
+	}
+}
+
+func reopenSinks(logger *Logger, sinks []Reopener, sig os.Signal) {
+	names := make([]string, 0, len(sinks))
+	for _, s := range sinks {
+		name := sinkName(s)
+		if err := s.Reopen(); err != nil {
+			logger.Error().Str("signal", sig.String()).Str("file", name).Err(err).Msg("log file reopen failed")
+			continue
+		}
+		names = append(names, name)
+	}
+	logger.Info().Str("signal", sig.String()).Strs("files", names).Msg("log files reopened")
+}
+
+func sinkName(s Reopener) string {
+	if n, ok := s.(interface{ Name() string }); ok {
+		return n.Name()
+	}
+	return "unknown"
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package bolt
+
+import "os"
+
+// No SIGHUP/SIGUSR1 outside Unix; callers must pass signals explicitly.
+var defaultReopenSignals []os.Signal
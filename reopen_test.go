@@ -0,0 +1,114 @@
+//go:build unix
+
+package bolt
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestReopenOnSignalAfterRename(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	f, err := OpenSharedFile(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	logger := New(NewJSONHandler(f))
+	stop := ReopenOnSignal(logger, []Reopener{f})
+	defer stop()
+
+	logger.Info().Msg("before rotation")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	var current []byte
+	for time.Now().Before(deadline) {
+		current, _ = os.ReadFile(path)
+		if bytes.Contains(current, []byte("log files reopened")) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !bytes.Contains(current, []byte(`"signal":"user defined signal 1"`)) || !strings.Contains(string(current), path) {
+		t.Fatalf("rotation event missing from new file: %q", current)
+	}
+	logger.Info().Msg("after rotation")
+
+	rotated, _ := os.ReadFile(path + ".1")
+	current, _ = os.ReadFile(path)
+	if !bytes.Contains(rotated, []byte("before rotation")) || bytes.Contains(rotated, []byte("after rotation")) {
+		t.Errorf("rotated file = %q", rotated)
+	}
+	if !bytes.Contains(current, []byte("after rotation")) {
+		t.Errorf("new file = %q", current)
+	}
+
+	stop()
+	stop()
+}
+
+type failingReopener struct{}
+
+func (failingReopener) Reopen() error { return errors.New("disk full") }
+
+func TestReopenSinksReportsFailures(t *testing.T) {
+	var buf bytes.Buffer
+	reopenSinks(New(NewJSONHandler(&buf)), []Reopener{failingReopener{}}, syscall.SIGHUP)
+	out := buf.String()
+	if !strings.Contains(out, `"level":"error"`) || !strings.Contains(out, "disk full") || !strings.Contains(out, `"file":"unknown"`) {
+		t.Errorf("failure not reported: %s", out)
+	}
+	if !strings.Contains(out, `"files":[]`) {
+		t.Errorf("summary event should list no files: %s", out)
+	}
+}
+
+func TestReopenOnSighupRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	f, err := OpenRotatingFile(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	logger := New(NewJSONHandler(f))
+	stop := ReopenOnSignal(logger, []Reopener{f}, syscall.SIGHUP)
+	defer stop()
+
+	logger.Info().Msg("before rotation")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	var current []byte
+	for time.Now().Before(deadline) {
+		current, _ = os.ReadFile(path)
+		if bytes.Contains(current, []byte("log files reopened")) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !bytes.Contains(current, []byte(`"signal":"hangup"`)) {
+		t.Fatalf("rotation event missing from new file: %q", current)
+	}
+	rotated, _ := os.ReadFile(path + ".1")
+	if !bytes.Contains(rotated, []byte("before rotation")) || bytes.Contains(rotated, []byte("reopened")) {
+		t.Errorf("rotated file = %q", rotated)
+	}
+}
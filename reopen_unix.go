@@ -0,0 +1,10 @@
+//go:build unix
+
+package bolt
+
+import (
+	"os"
+	"syscall"
+)
+
+var defaultReopenSignals = []os.Signal{syscall.SIGHUP, syscall.SIGUSR1}
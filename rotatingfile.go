@@ -0,0 +1,500 @@
+package bolt
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRotateTimeFormat is the layout of the timestamp in the names of
+// rotated files when none is configured.
+const DefaultRotateTimeFormat = "2006-01-02T15-04-05"
+
+// RotatingFileOptions configures [OpenRotatingFile]. A nil
+// *RotatingFileOptions never rotates. Set MaxSize, Interval or both.
+type RotatingFileOptions struct {
+	// MaxSize rotates the file before a write would take it beyond this
+	// many bytes. Zero disables size rotation.
+	MaxSize int64
+
+	// Interval rotates the file at every interval boundary: time.Hour
+	// rotates on the hour, 24*time.Hour at midnight. Boundaries count from
+	// midnight in Location, so intervals should divide a day; intervals
+	// above a day rotate daily. Zero disables time rotation.
+	Interval time.Duration
+
+	// Location is the time zone of the boundaries and of the timestamps
+	// in rotated file names. Defaults to time.Local; use time.UTC for UTC
+	// boundaries.
+	Location *time.Location
+
+	// TimeFormat is the [time.Time.Format] layout of the timestamp in
+	// rotated file names. Defaults to DefaultRotateTimeFormat.
+	TimeFormat string
+
+	// Compress gzips rotated files in the background, adding ".gz" to
+	// their names.
+	Compress bool
+
+	// MaxAge deletes rotated files last written more than MaxAge ago.
+	// Zero keeps them regardless of age.
+	MaxAge time.Duration
+
+	// MaxBackups deletes the oldest rotated files beyond this many. Zero
+	// keeps them regardless of number.
+	MaxBackups int
+
+	// Manifest writes a sidecar "<file>.manifest.json" ([FileManifest])
+	// for every rotated file, named after its uncompressed name, so
+	// shipping pipelines can detect truncation (see [VerifyManifest]). It
+	// is written right after the rename and removed along with the file by
+	// MaxAge and MaxBackups. The manifest covers the whole file, including
+	// content present before it was opened, and so is only meaningful when
+	// this RotatingFile is the file's sole writer. Files rotated externally
+	// and renamed before [RotatingFile.Reopen] get no manifest.
+	Manifest bool
+
+	// ErrorHandler, if set, receives the errors of background compression
+	// and cleanup, which happen after the write that rotated the file, and
+	// of writing manifests.
+	ErrorHandler ErrorHandler
+}
+
+// RotatingFile is a log file that rotates itself by size, by time or by
+// both, whichever limit is reached first:
+//
+//	f, err := bolt.OpenRotatingFile("/var/log/app/app.log", &bolt.RotatingFileOptions{
+//	    MaxSize:  100 << 20,      // 100 MiB
+//	    Interval: 24 * time.Hour, // and at midnight
+//	    Location: time.UTC,
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	defer f.Close()
+//	logger := bolt.New(bolt.NewJSONHandler(f))
+//
+// The active file keeps its path. On rotation it is renamed with the
+// start of its period, or with its opening time without an Interval,
+// inserted before the extension, such as
+// app-2026-10-16T00-00-00.log; files rotated by size within one period
+// get a counter, as in app-2026-10-16T00-00-00.1.log. Rotation happens on
+// the first write past a limit, so an idle file is renamed only when it
+// is next written. A file that exists when it is opened is appended to
+// and dated by its modification time, so a stale file from a previous
+// period rotates on the first write.
+//
+// Records are never split across files. After each rotation a background
+// goroutine gzips rotated files if Compress is set and deletes those
+// beyond MaxAge or MaxBackups, so no external tool is needed; files are
+// matched by the naming scheme above, including ones from earlier runs.
+// RotatingFile is safe for concurrent use and, as a [Reopener], also
+// works with [ReopenOnSignal] and external rotation.
+type RotatingFile struct {
+	path     string
+	maxSize  int64
+	interval time.Duration
+	loc      *time.Location
+	layout   string
+	compress bool
+	maxAge   time.Duration
+	backups  int
+	manifest bool
+	onError  ErrorHandler
+	now      func() time.Time
+
+	// mill wakes the background goroutine compressing and deleting rotated
+	// files; nil if there is nothing to do. milled is closed when it exits.
+	mill   chan struct{}
+	milled chan struct{}
+
+	mu     sync.Mutex
+	f      *os.File // nil after a failed rotation; reopened on the next write
+	closed bool
+	size   int64
+	start  time.Time   // start of the file's period, or its opening time
+	next   time.Time   // next boundary; zero without an Interval
+	digest *fileDigest // nil unless manifest
+}
+
+// OpenRotatingFile opens path for appending, creating it with mode 0600
+// if it does not exist.
+func OpenRotatingFile(path string, opts *RotatingFileOptions) (*RotatingFile, error) {
+	if opts == nil {
+		opts = &RotatingFileOptions{}
+	}
+	r := &RotatingFile{
+		path:     path,
+		maxSize:  opts.MaxSize,
+		interval: min(opts.Interval, 24*time.Hour),
+		loc:      opts.Location,
+		layout:   opts.TimeFormat,
+		compress: opts.Compress,
+		maxAge:   opts.MaxAge,
+		backups:  opts.MaxBackups,
+		manifest: opts.Manifest,
+		onError:  opts.ErrorHandler,
+		now:      time.Now,
+	}
+	if r.loc == nil {
+		r.loc = time.Local
+	}
+	if r.layout == "" {
+		r.layout = DefaultRotateTimeFormat
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	if r.compress || r.maxAge > 0 || r.backups > 0 {
+		r.mill = make(chan struct{}, 1)
+		r.milled = make(chan struct{})
+		go r.millRun()
+	}
+	return r, nil
+}
+
+// open opens r.path and computes its size and period and, with manifests
+// enabled, digests its existing contents. Called with r.mu held or before
+// r is shared.
+func (r *RotatingFile) open() error {
+	f, err := openAppend(r.path)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	r.digest = nil
+	if r.manifest {
+		if r.digest, err = newFileDigest(r.path); err != nil {
+			_ = f.Close()
+			return err
+		}
+	}
+	started := r.now()
+	if fi.Size() > 0 {
+		started = fi.ModTime()
+	}
+	r.f, r.size = f, fi.Size()
+	r.start, r.next = r.period(started)
+	return nil
+}
+
+// period returns the start of the interval containing t and the next
+// boundary after it, or t and the zero time without an Interval.
+func (r *RotatingFile) period(t time.Time) (start, next time.Time) {
+	t = t.In(r.loc)
+	if r.interval <= 0 {
+		return t, time.Time{}
+	}
+	// Boundaries are wall-clock times, so that daily rotation stays at
+	// midnight on days with 23 or 25 hours.
+	y, m, d := t.Date()
+	wall := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second + time.Duration(t.Nanosecond())
+	k := wall / r.interval
+	at := func(offset time.Duration) time.Time {
+		return time.Date(y, m, d, 0, 0, int(offset/time.Second), int(offset%time.Second), r.loc)
+	}
+	return at(k * r.interval), at(min((k+1)*r.interval, 24*time.Hour))
+}
+
+// Write appends p, rotating first if p would cross a limit.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.ready(); err != nil {
+		return 0, err
+	}
+	if r.size > 0 && (!r.next.IsZero() && !r.now().Before(r.next) ||
+		r.maxSize > 0 && r.size+int64(len(p)) > r.maxSize) {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	if r.digest != nil {
+		_, _ = r.digest.Write(p[:n])
+	}
+	return n, err
+}
+
+// ready reopens the file after a failed rotation. Called with r.mu held.
+func (r *RotatingFile) ready() error {
+	if r.closed {
+		return os.ErrClosed
+	}
+	if r.f == nil {
+		return r.open()
+	}
+	return nil
+}
+
+// Rotate rotates the file now, if it is not empty.
+func (r *RotatingFile) Rotate() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.ready(); err != nil {
+		return err
+	}
+	if r.size == 0 {
+		return nil
+	}
+	return r.rotate()
+}
+
+// rotate renames the active file, writes its manifest if enabled, and
+// opens a new one. If the rename fails, writing continues to the old
+// file; if the open fails, the next write tries again. Called with r.mu
+// held.
+func (r *RotatingFile) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return err
+	}
+	name, err := r.rotatedName()
+	if err == nil {
+		err = os.Rename(r.path, name)
+	}
+	if err == nil && r.digest != nil {
+		merr := writeManifest(name, r.digest.manifest(filepath.Base(name), r.now()))
+		if merr != nil && r.onError != nil {
+			r.onError(fmt.Errorf("bolt: write manifest for %s: %w", name, merr))
+		}
+	}
+	if oerr := r.open(); oerr != nil {
+		r.f, r.digest = nil, nil
+		return errors.Join(err, oerr)
+	}
+	if err != nil {
+		return fmt.Errorf("bolt: rotate %s: %w", r.path, err)
+	}
+	if r.mill != nil {
+		select {
+		case r.mill <- struct{}{}:
+		default: // a pass is already pending
+		}
+	}
+	return nil
+}
+
+// rotatedName returns the first name for the active file that is free,
+// compressed or not: <base>-<start><ext>, then <base>-<start>.1<ext> and
+// so on.
+func (r *RotatingFile) rotatedName() (string, error) {
+	ext := filepath.Ext(r.path)
+	base := strings.TrimSuffix(r.path, ext) + "-" + r.start.Format(r.layout)
+	name := base + ext
+	for i := 1; ; i++ {
+		free, err := isFree(name)
+		if err == nil && free {
+			free, err = isFree(name + ".gz")
+		}
+		if err != nil {
+			return "", err
+		}
+		if free {
+			return name, nil
+		}
+		name = base + "." + strconv.Itoa(i) + ext
+	}
+}
+
+func isFree(name string) (bool, error) {
+	_, err := os.Lstat(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return true, nil
+	}
+	return false, err
+}
+
+// Reopen opens the file's path again and closes the old file, for
+// external rotation. See [ReopenOnSignal]. Writes move to the new file
+// even if closing the old one fails; that error is returned.
+func (r *RotatingFile) Reopen() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return os.ErrClosed
+	}
+	old := r.f
+	if err := r.open(); err != nil {
+		return err
+	}
+	if old != nil {
+		return old.Close()
+	}
+	return nil
+}
+
+// Name returns the path of the active file.
+func (r *RotatingFile) Name() string {
+	return r.path
+}
+
+// Sync commits the file's contents to stable storage.
+func (r *RotatingFile) Sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.ready(); err != nil {
+		return err
+	}
+	return r.f.Sync()
+}
+
+// Close closes the file, without rotating it, and waits for pending
+// compression and cleanup.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	var err error
+	if r.f != nil {
+		err = r.f.Close()
+	}
+	r.f, r.closed = nil, true
+	if r.mill != nil {
+		close(r.mill)
+	}
+	r.mu.Unlock()
+	if r.milled != nil {
+		<-r.milled
+	}
+	return err
+}
+
+// millRun compresses and deletes rotated files after every rotation
+// until Close.
+func (r *RotatingFile) millRun() {
+	defer close(r.milled)
+	for range r.mill {
+		if err := r.millOnce(); err != nil && r.onError != nil {
+			r.onError(err)
+		}
+	}
+}
+
+// rotatedFile is a rotated file found by millOnce.
+type rotatedFile struct {
+	path    string
+	modTime time.Time
+}
+
+// millOnce deletes the rotated files beyond MaxAge and MaxBackups and
+// compresses the rest if Compress is set.
+func (r *RotatingFile) millOnce() error {
+	dir := filepath.Dir(r.path)
+	ext := filepath.Ext(r.path)
+	prefix := strings.TrimSuffix(filepath.Base(r.path), ext) + "-"
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("bolt: clean up %s: %w", r.path, err)
+	}
+	var files []rotatedFile
+	for _, e := range entries {
+		name := e.Name()
+		if !e.Type().IsRegular() || !r.isRotated(name, prefix, ext) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue // deleted meanwhile
+		}
+		files = append(files, rotatedFile{filepath.Join(dir, name), info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.After(files[j].modTime) })
+
+	var errs []error
+	cutoff := r.now().Add(-r.maxAge)
+	for i, f := range files {
+		if r.backups > 0 && i >= r.backups || r.maxAge > 0 && f.modTime.Before(cutoff) {
+			for _, name := range []string{f.path, strings.TrimSuffix(f.path, ".gz") + ManifestSuffix} {
+				if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+					errs = append(errs, err)
+				}
+			}
+			continue
+		}
+		if r.compress && !strings.HasSuffix(f.path, ".gz") {
+			if err := compressFile(f.path, f.modTime); err != nil {
+				errs = append(errs, fmt.Errorf("bolt: compress %s: %w", f.path, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// isRotated reports whether name is a file rotatedName could have
+// produced: prefix, a timestamp in the configured layout, an optional
+// ".N", ext and an optional ".gz". Other files sharing the prefix, such
+// as app-audit.log next to app.log, are left alone.
+func (r *RotatingFile) isRotated(name, prefix, ext string) bool {
+	stamp, ok := strings.CutPrefix(strings.TrimSuffix(name, ".gz"), prefix)
+	if !ok {
+		return false
+	}
+	if stamp, ok = strings.CutSuffix(stamp, ext); !ok {
+		return false
+	}
+	if _, err := time.Parse(r.layout, stamp); err == nil {
+		return true
+	}
+	i := strings.LastIndexByte(stamp, '.')
+	if i < 0 {
+		return false
+	}
+	if n, err := strconv.Atoi(stamp[i+1:]); err != nil || n < 1 {
+		return false
+	}
+	_, err := time.Parse(r.layout, stamp[:i])
+	return err == nil
+}
+
+// compressFile replaces path with path+".gz", keeping its modification
+// time so that MaxAge still applies to when it was last written.
+func compressFile(path string, modTime time.Time) (err error) {
+	src, err := os.Open(path) // #nosec G304 -- rotated log file
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.gz.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			dst.Close()
+			os.Remove(dst.Name())
+		}
+	}()
+	zw := gzip.NewWriter(dst)
+	if _, err = io.Copy(zw, src); err != nil {
+		return err
+	}
+	if err = zw.Close(); err != nil {
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	if err = os.Chtimes(dst.Name(), modTime, modTime); err != nil {
+		return err
+	}
+	if err = os.Rename(dst.Name(), path+".gz"); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
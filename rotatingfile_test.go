@@ -0,0 +1,344 @@
+package bolt
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func dirFiles(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{}
+	for _, e := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[e.Name()] = string(data)
+	}
+	return files
+}
+
+func TestRotatingFileInterval(t *testing.T) {
+	dir := t.TempDir()
+	f, err := OpenRotatingFile(filepath.Join(dir, "app.log"), &RotatingFileOptions{Interval: time.Hour, Location: time.UTC})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	now := time.Date(2026, 10, 16, 13, 59, 0, 0, time.UTC)
+	f.now = func() time.Time { return now }
+	f.start, f.next = f.period(now)
+	logger := New(NewJSONHandler(f))
+
+	logger.Info().Msg("one")
+	now = now.Add(2 * time.Minute)
+	logger.Info().Msg("two")
+	now = now.Add(3 * time.Hour) // idle hours leave no empty files
+	logger.Info().Msg("three")
+
+	files := dirFiles(t, dir)
+	if len(files) != 3 {
+		t.Fatalf("files = %v", files)
+	}
+	for name, want := range map[string]string{
+		"app-2026-10-16T13-00-00.log": "one",
+		"app-2026-10-16T14-00-00.log": "two",
+		"app.log":                     "three",
+	} {
+		if !strings.Contains(files[name], want) || strings.Count(files[name], "\n") != 1 {
+			t.Errorf("%s = %q, want %s", name, files[name], want)
+		}
+	}
+}
+
+func TestRotatingFileSize(t *testing.T) {
+	dir := t.TempDir()
+	f, err := OpenRotatingFile(filepath.Join(dir, "app.log"), &RotatingFileOptions{
+		MaxSize:  100,
+		Interval: 24 * time.Hour,
+		Location: time.UTC,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	now := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	f.now = func() time.Time { return now }
+	f.start, f.next = f.period(now)
+	logger := New(NewJSONHandler(f))
+
+	for range 5 {
+		logger.Info().Str("pad", strings.Repeat("x", 30)).Msg("record") // 63 bytes
+	}
+	now = now.Add(24 * time.Hour)
+	logger.Info().Msg("tomorrow")
+
+	files := dirFiles(t, dir)
+	var names []string
+	for name, data := range files {
+		names = append(names, name)
+		if len(data) > 100 {
+			t.Errorf("%s holds %d bytes", name, len(data))
+		}
+	}
+	sort.Strings(names)
+	want := "app-2026-10-16T00-00-00.1.log,app-2026-10-16T00-00-00.2.log,app-2026-10-16T00-00-00.3.log,app-2026-10-16T00-00-00.4.log,app-2026-10-16T00-00-00.log,app.log"
+	if strings.Join(names, ",") != want {
+		t.Errorf("files = %v", names)
+	}
+	if !strings.Contains(files["app.log"], "tomorrow") {
+		t.Errorf("app.log = %q", files["app.log"])
+	}
+}
+
+func TestRotatingFilePeriod(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+	r := &RotatingFile{interval: 24 * time.Hour, loc: berlin}
+	// The day the clocks go back has 25 hours; the boundary stays at midnight.
+	start, next := r.period(time.Date(2026, 10, 25, 12, 0, 0, 0, berlin))
+	if !start.Equal(time.Date(2026, 10, 25, 0, 0, 0, 0, berlin)) || !next.Equal(time.Date(2026, 10, 26, 0, 0, 0, 0, berlin)) {
+		t.Errorf("period = %v, %v", start, next)
+	}
+	r.interval = 6 * time.Hour
+	start, next = r.period(time.Date(2026, 10, 16, 17, 5, 0, 0, time.UTC))
+	if !start.Equal(time.Date(2026, 10, 16, 18, 0, 0, 0, berlin)) || !next.Equal(time.Date(2026, 10, 17, 0, 0, 0, 0, berlin)) {
+		t.Errorf("6h period = %v, %v", start, next)
+	}
+}
+
+func TestRotatingFileExisting(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(path, []byte("{}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Date(2026, 10, 15, 22, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+	f, err := OpenRotatingFile(path, &RotatingFileOptions{Interval: 24 * time.Hour, Location: time.UTC})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("{\"message\":\"today\"}\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	files := dirFiles(t, dir)
+	if files["app-2026-10-15T00-00-00.log"] != "{}\n" || !strings.Contains(files["app.log"], "today") {
+		t.Errorf("files = %v", files)
+	}
+	if _, err := f.Write([]byte("x")); err != os.ErrClosed {
+		t.Errorf("Write after Close = %v", err)
+	}
+}
+
+func TestRotatingFileReopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	f, err := OpenRotatingFile(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := os.Rename(path, path+".1"); err != nil { // external rotation
+		t.Fatal(err)
+	}
+	_ = f.f.Close() // make closing the old file fail
+	if err := f.Reopen(); err == nil {
+		t.Error("Reopen did not report the close error")
+	}
+	if _, err := f.Write([]byte("{\"message\":\"after\"}\n")); err != nil {
+		t.Fatalf("Write after Reopen: %v", err)
+	}
+	if files := dirFiles(t, dir); !strings.Contains(files["app.log"], "after") {
+		t.Errorf("files = %v", files)
+	}
+}
+
+func TestRotatingFileCompressAndClean(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	// A leftover from an earlier run, past MaxAge, and an unrelated file.
+	stale := filepath.Join(dir, "app-2026-09-01T00-00-00.log.gz")
+	for _, name := range []string{stale, filepath.Join(dir, "other.log")} {
+		if err := os.WriteFile(name, []byte("old"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	month := time.Now().Add(-30 * 24 * time.Hour)
+	if err := os.Chtimes(stale, month, month); err != nil {
+		t.Fatal(err)
+	}
+
+	var errs []error
+	f, err := OpenRotatingFile(path, &RotatingFileOptions{
+		MaxSize:      40,
+		Compress:     true,
+		MaxAge:       7 * 24 * time.Hour,
+		MaxBackups:   2,
+		ErrorHandler: func(err error) { errs = append(errs, err) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := New(NewJSONHandler(f))
+	for i := range 4 {
+		logger.Info().Int("i", i).Msg("record") // 38 bytes each
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	files := dirFiles(t, dir)
+	var rotated []string
+	for name, data := range files {
+		if name == "app.log" || name == "other.log" {
+			continue
+		}
+		if !strings.HasSuffix(name, ".log.gz") {
+			t.Errorf("%s not compressed", name)
+			continue
+		}
+		zr, err := gzip.NewReader(strings.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		plain, _ := io.ReadAll(zr)
+		rotated = append(rotated, string(plain))
+	}
+	sort.Strings(rotated)
+	if len(rotated) != 2 || !strings.Contains(rotated[0], `"i":1`) || !strings.Contains(rotated[1], `"i":2`) {
+		t.Errorf("rotated files hold %q", rotated)
+	}
+	if _, ok := files["app-2026-09-01T00-00-00.log.gz"]; ok || files["other.log"] != "old" || !strings.Contains(files["app.log"], `"i":3`) {
+		t.Errorf("files = %v", files)
+	}
+}
+
+func TestRotatingFileKeepsSiblings(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	// Files sharing the "app-" prefix that rotation did not produce, old
+	// enough for MaxAge and numerous enough for MaxBackups.
+	siblings := []string{"app-audit.log", "app-audit.log.gz", "app-2026-09-01.log", "app-2026-09-01T00-00-00.x.log"}
+	month := time.Now().Add(-30 * 24 * time.Hour)
+	for _, name := range siblings {
+		name = filepath.Join(dir, name)
+		if err := os.WriteFile(name, []byte("old"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(name, month, month); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f, err := OpenRotatingFile(path, &RotatingFileOptions{
+		MaxSize:      40,
+		Compress:     true,
+		MaxAge:       7 * 24 * time.Hour,
+		MaxBackups:   1,
+		ErrorHandler: func(err error) { t.Error(err) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := New(NewJSONHandler(f))
+	for i := range 3 {
+		logger.Info().Int("i", i).Msg("record")
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	files := dirFiles(t, dir)
+	for _, name := range siblings {
+		if files[name] != "old" {
+			t.Errorf("%s was touched: %v", name, files)
+		}
+	}
+	if len(files) != len(siblings)+2 {
+		t.Errorf("files = %v, want the siblings, app.log and one backup", files)
+	}
+}
+
+func TestRotatingFileManifest(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(path, []byte("existing\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := OpenRotatingFile(path, &RotatingFileOptions{
+		MaxSize:      100,
+		MaxBackups:   1,
+		Manifest:     true,
+		ErrorHandler: func(err error) { t.Error(err) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := New(NewJSONHandler(f))
+	for i := range 2 {
+		logger.Info().Int("i", i).Msg("line") // 36 bytes each
+	}
+	if err := f.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	backups, _ := filepath.Glob(filepath.Join(dir, "app-*.log"))
+	if len(backups) != 1 {
+		t.Fatalf("backups = %v", backups)
+	}
+	first := backups[0]
+	hour := time.Now().Add(-time.Hour) // older than the next backup, for MaxBackups
+	if err := os.Chtimes(first, hour, hour); err != nil {
+		t.Fatal(err)
+	}
+	for i := range 3 {
+		logger.Info().Int("i", i).Msg("line")
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each rotated file has a manifest; the one of the backup deleted by
+	// MaxBackups went with it, and the active file has none.
+	var rotated []string
+	for name := range dirFiles(t, dir) {
+		if strings.HasSuffix(name, ManifestSuffix) {
+			rotated = append(rotated, strings.TrimSuffix(name, ManifestSuffix))
+		}
+	}
+	if len(rotated) != 1 || filepath.Join(dir, rotated[0]) == first {
+		t.Fatalf("manifests for %v", rotated)
+	}
+	name := filepath.Join(dir, rotated[0])
+	m, err := ReadManifest(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(name)
+	if m.File != rotated[0] || m.Lines != 2 || m.Bytes != uint64(len(data)) {
+		t.Errorf("manifest = %+v; want %s, 2 lines, %d bytes", m, rotated[0], len(data))
+	}
+	if err := VerifyManifest(name); err != nil {
+		t.Errorf("VerifyManifest: %v", err)
+	}
+}
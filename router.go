@@ -0,0 +1,149 @@
+package bolt
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// Route is one destination of a [Router].
+type Route struct {
+	// Name identifies the route in a [RouteError] and in [Router.Stats].
+	Name string
+
+	// Handler receives the events the route matches. A nil Handler
+	// discards them, which together with Final drops them.
+	Handler Handler
+
+	// Match reports whether the route takes the event. It runs with the
+	// finished event, so it may use [Event.Level] and [Event.WalkFields],
+	// but must not retain the event. Nil matches every event.
+	Match func(e *Event) bool
+
+	// Final stops routing after this route for the events it matches,
+	// so that later routes, and the fallback, do not see them.
+	Final bool
+}
+
+// RouteLevels returns a [Route] matcher for events from level min to
+// level max, inclusive.
+func RouteLevels(min, max Level) func(e *Event) bool {
+	return func(e *Event) bool { return e.level >= min && e.level <= max }
+}
+
+// RouteField returns a [Route] matcher for events with a top-level field
+// key whose value, as encoded (string contents without the quotes, raw
+// JSON text for other types), equals value.
+func RouteField(key, value string) func(e *Event) bool {
+	match := MatchField(key, value)
+	return func(e *Event) bool { return match(e, "") }
+}
+
+// RouterOptions configures [NewRouter]. A nil *RouterOptions uses the
+// defaults.
+type RouterOptions struct {
+	// Fallback receives the events no route matched. Nil discards them.
+	Fallback Handler
+}
+
+// RouteError is the error of one route of a [Router].
+type RouteError struct {
+	Route string
+	Err   error
+}
+
+func (e *RouteError) Error() string { return "bolt: route " + e.Route + ": " + e.Err.Error() }
+
+func (e *RouteError) Unwrap() error { return e.Err }
+
+// RouteStats are the counters of one route of a [Router].
+type RouteStats struct {
+	Route  string `json:"route"`
+	Events uint64 `json:"events"` // events the route matched
+	Errors uint64 `json:"errors"` // failed writes to its handler
+}
+
+// Router sends each event to the handlers of the routes that match it,
+// so that one logger can feed sinks with different needs:
+//
+//	logger := bolt.New(bolt.NewRouter([]bolt.Route{
+//	    {Name: "stderr", Match: bolt.RouteLevels(bolt.WARN, bolt.FATAL), Handler: bolt.NewConsoleHandler(os.Stderr)},
+//	    {Name: "pager", Match: bolt.RouteLevels(bolt.ERROR, bolt.FATAL), Handler: pagerHandler},
+//	    {Name: "audit", Match: bolt.RouteField("audit", "true"), Handler: auditHandler, Final: true},
+//	    {Name: "file", Handler: bolt.NewJSONHandler(file)},
+//	}, nil))
+//
+// Routes are tried in order and every matching route receives the event,
+// up to the first matching Final route. Routes are isolated from each
+// other: a failing handler does not keep the event from the routes after
+// it. Write returns the failures as [*RouteError], joined with
+// [errors.Join] when more than one route failed, and counts them in
+// [Router.Stats]. As in a [MultiHandler], the event is encoded once and
+// handlers must not modify it.
+type Router struct {
+	routes   []route
+	fallback Handler
+}
+
+// route is a Route with its counters.
+type route struct {
+	Route
+	events atomic.Uint64
+	errors atomic.Uint64
+}
+
+// NewRouter returns a Router over routes. The routes slice is copied.
+func NewRouter(routes []Route, opts *RouterOptions) *Router {
+	r := &Router{routes: make([]route, len(routes))}
+	for i, rt := range routes {
+		r.routes[i].Route = rt
+	}
+	if opts != nil {
+		r.fallback = opts.Fallback
+	}
+	return r
+}
+
+// Write implements [Handler].
+func (r *Router) Write(e *Event) error {
+	var errs []error
+	matched := false
+	for i := range r.routes {
+		rt := &r.routes[i]
+		if rt.Match != nil && !rt.Match(e) {
+			continue
+		}
+		matched = true
+		rt.events.Add(1)
+		if rt.Handler != nil {
+			if err := rt.Handler.Write(e); err != nil {
+				rt.errors.Add(1)
+				errs = append(errs, &RouteError{Route: rt.Name, Err: err})
+			}
+		}
+		if rt.Final {
+			break
+		}
+	}
+	if !matched && r.fallback != nil {
+		if err := r.fallback.Write(e); err != nil {
+			errs = append(errs, &RouteError{Route: "fallback", Err: err})
+		}
+	}
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+	return errors.Join(errs...)
+}
+
+// Stats returns the counters of every route, in route order.
+func (r *Router) Stats() []RouteStats {
+	stats := make([]RouteStats, len(r.routes))
+	for i := range r.routes {
+		rt := &r.routes[i]
+		stats[i] = RouteStats{Route: rt.Name, Events: rt.events.Load(), Errors: rt.errors.Load()}
+	}
+	return stats
+}
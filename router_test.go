@@ -0,0 +1,78 @@
+package bolt
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRouterFanOut(t *testing.T) {
+	var stderr, file, fallback bytes.Buffer
+	errPager := errors.New("pager down")
+	router := NewRouter([]Route{
+		{Name: "stderr", Match: RouteLevels(WARN, FATAL), Handler: NewJSONHandler(&stderr)},
+		{Name: "pager", Match: RouteLevels(ERROR, FATAL), Handler: &failingHandler{errPager}},
+		{Name: "file", Match: RouteLevels(INFO, FATAL), Handler: NewJSONHandler(&file)},
+	}, &RouterOptions{Fallback: NewJSONHandler(&fallback)})
+	var got []error
+	logger := New(router).SetLevel(DEBUG).SetErrorHandler(func(err error) { got = append(got, err) })
+
+	logger.Debug().Msg("detail")
+	logger.Info().Msg("started")
+	logger.Warn().Msg("slow")
+	logger.Error().Msg("failed")
+
+	if s := stderr.String(); strings.Contains(s, "started") || !strings.Contains(s, "slow") || !strings.Contains(s, "failed") {
+		t.Errorf("stderr got %s", s)
+	}
+	if s := file.String(); strings.Count(s, "\n") != 3 || !strings.Contains(s, "failed") {
+		t.Errorf("file got %s", s)
+	}
+	if s := fallback.String(); strings.Count(s, "\n") != 1 || !strings.Contains(s, "detail") {
+		t.Errorf("fallback got %s", s)
+	}
+
+	// The failing route does not keep the event from the file route.
+	var re *RouteError
+	if len(got) != 1 || !errors.As(got[0], &re) || re.Route != "pager" || !errors.Is(got[0], errPager) {
+		t.Fatalf("errors = %v", got)
+	}
+	stats := router.Stats()
+	if len(stats) != 3 || stats[0] != (RouteStats{"stderr", 2, 0}) || stats[1] != (RouteStats{"pager", 1, 1}) || stats[2] != (RouteStats{"file", 3, 0}) {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestRouterFinal(t *testing.T) {
+	var audit, all bytes.Buffer
+	router := NewRouter([]Route{
+		{Name: "health", Match: RouteField("path", "/healthz"), Final: true},
+		{Name: "audit", Match: RouteField("audit", "true"), Handler: NewJSONHandler(&audit), Final: true},
+		{Name: "all", Handler: NewJSONHandler(&all)},
+	}, nil)
+	logger := New(router)
+
+	logger.Info().Str("path", "/healthz").Msg("request")
+	logger.Info().Bool("audit", true).Str("user", "ana").Msg("role granted")
+	logger.Info().Str("path", "/users").Msg("request")
+
+	if s := audit.String(); strings.Count(s, "\n") != 1 || !strings.Contains(s, "role granted") {
+		t.Errorf("audit got %s", s)
+	}
+	if s := all.String(); strings.Count(s, "\n") != 1 || !strings.Contains(s, "/users") {
+		t.Errorf("all got %s", s)
+	}
+}
+
+func TestRouterJoinsErrors(t *testing.T) {
+	router := NewRouter([]Route{
+		{Name: "a", Handler: &failingHandler{errors.New("a")}},
+		{Name: "b", Handler: &failingHandler{errors.New("b")}},
+	}, nil)
+	var got error
+	New(router).SetErrorHandler(func(err error) { got = err }).Info().Msg("x")
+	if got == nil || !strings.HasSuffix(got.Error(), "bolt: route a: a\nbolt: route b: b") {
+		t.Errorf("error = %v", got)
+	}
+}
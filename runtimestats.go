@@ -0,0 +1,102 @@
+package bolt
+
+import (
+	"os"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// DefaultRuntimeStatsInterval is the emission interval used by
+// [StartRuntimeStats] when none is configured.
+const DefaultRuntimeStatsInterval = 30 * time.Second
+
+// RuntimeStatsOptions configures [StartRuntimeStats].
+type RuntimeStatsOptions struct {
+	// Interval between events. Defaults to DefaultRuntimeStatsInterval.
+	Interval time.Duration
+}
+
+// StartRuntimeStats starts a background goroutine that logs a "runtime
+// stats" INFO event every interval until the returned stop function is
+// called. If opts is nil, defaults are used.
+//
+// Each event records heap usage, GC activity, goroutine count,
+// GOMAXPROCS and, where the platform exposes them, the number of open
+// file descriptors and the container limits from [DetectContainerLimits].
+// Fields that cannot be read are omitted rather than reported as zero.
+//
+// Collecting the stats calls [runtime.ReadMemStats], which briefly stops
+// the world; keep the interval in the tens of seconds in production.
+// stop blocks until the goroutine has exited and is safe to call more
+// than once.
+func StartRuntimeStats(logger *Logger, opts *RuntimeStatsOptions) (stop func()) {
+	interval := DefaultRuntimeStatsInterval
+	if opts != nil && opts.Interval > 0 {
+		interval = opts.Interval
+	}
+	return startTicker(interval, func() { logRuntimeStats(logger) })
+}
+
+// startTicker calls fn every interval on a background goroutine until
+// the returned stop function is called. stop blocks until the goroutine
+// has exited and is safe to call more than once.
+func startTicker(interval time.Duration, fn func()) (stop func()) {
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				fn()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-exited
+		})
+	}
+}
+
+func logRuntimeStats(logger *Logger) {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	e := logger.Info().
+		Int("goroutines", runtime.NumGoroutine()).
+		Int("gomaxprocs", runtime.GOMAXPROCS(0)).
+		Uint64("heap_alloc", ms.HeapAlloc).
+		Uint64("heap_sys", ms.HeapSys).
+		Uint64("heap_objects", ms.HeapObjects).
+		Uint64("next_gc", ms.NextGC).
+		Uint32("gc_count", ms.NumGC).
+		Dur("gc_pause_total", time.Duration(ms.PauseTotalNs)) // #nosec G115 -- cumulative pause fits in int64 ns for centuries
+	if ms.NumGC > 0 {
+		e = e.Dur("gc_pause_last", time.Duration(ms.PauseNs[(ms.NumGC+255)%256])) // #nosec G115 -- single pause fits in int64 ns
+	}
+	if n, ok := openFDs(); ok {
+		e = e.Int("open_fds", n)
+	}
+	e = DetectContainerLimits().appendFields(e)
+	e.Msg("runtime stats")
+}
+
+// openFDs counts the process's open file descriptors via /proc. It
+// reports false on platforms without procfs.
+func openFDs() (int, bool) {
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		return 0, false
+	}
+	// ReadDir itself holds one descriptor open while listing.
+	return len(entries) - 1, true
+}
@@ -0,0 +1,39 @@
+package bolt
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStartRuntimeStats(t *testing.T) {
+	buf := &ThreadSafeBuffer{}
+	logger := New(NewJSONHandler(buf))
+
+	stop := StartRuntimeStats(logger, &RuntimeStatsOptions{Interval: 5 * time.Millisecond})
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(buf.String(), "runtime stats") && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	stop()
+	stop() // idempotent
+
+	line, _, _ := strings.Cut(buf.String(), "\n")
+	var got map[string]any
+	if err := json.Unmarshal([]byte(line), &got); err != nil {
+		t.Fatalf("invalid JSON %q: %v", line, err)
+	}
+	for _, key := range []string{"goroutines", "gomaxprocs", "heap_alloc", "heap_sys", "next_gc", "gc_count", "gc_pause_total"} {
+		if _, ok := got[key]; !ok {
+			t.Errorf("missing field %q in %s", key, line)
+		}
+	}
+
+	// No further events after stop returns.
+	n := len(buf.String())
+	time.Sleep(20 * time.Millisecond)
+	if len(buf.String()) != n {
+		t.Error("events emitted after stop")
+	}
+}
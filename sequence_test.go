@@ -0,0 +1,35 @@
+package bolt
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestSequenceHook(t *testing.T) {
+	var buf bytes.Buffer
+	seq := NewSequenceHook()
+	logger := New(NewJSONHandler(&buf)).AddEventHook(seq)
+	child := logger.With().Str("component", "db").Logger()
+
+	logger.Info().Msg("a")
+	child.Info().Msg("b")
+	logger.Info().Msg("c")
+
+	dec := json.NewDecoder(&buf)
+	for want := uint64(1); want <= 3; want++ {
+		var rec struct {
+			Seq    uint64 `json:"seq"`
+			Stream string `json:"seq_stream"`
+		}
+		if err := dec.Decode(&rec); err != nil {
+			t.Fatal(err)
+		}
+		if rec.Seq != want || rec.Stream != seq.Stream() {
+			t.Errorf("record %d: seq=%d stream=%q; want %d %q", want, rec.Seq, rec.Stream, want, seq.Stream())
+		}
+	}
+	if NewSequenceHook().Stream() == seq.Stream() {
+		t.Error("two hooks share a stream ID")
+	}
+}
@@ -0,0 +1,59 @@
+package bolt
+
+import "context"
+
+// SessionReplayField is the field holding a frontend session replay ID.
+const SessionReplayField = "session_replay_id"
+
+// MaxSessionReplayIDLength bounds the IDs accepted by
+// [ValidSessionReplayID].
+const MaxSessionReplayIDLength = 128
+
+type sessionReplayKey struct{}
+
+// ContextWithSessionReplayID returns a copy of ctx carrying the session
+// replay ID of the frontend session that caused the current work. Log it
+// with [Event.SessionReplay] so support engineers can jump from a
+// backend error to the replay of what the customer saw. httplog's
+// middleware reads it from the X-Session-Replay-ID request header.
+func ContextWithSessionReplayID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, sessionReplayKey{}, id)
+}
+
+// SessionReplayIDFromContext returns the ID stored in ctx by
+// [ContextWithSessionReplayID].
+func SessionReplayIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(sessionReplayKey{}).(string)
+	return id, ok && id != ""
+}
+
+// SessionReplay adds the "session_replay_id" field from ctx. It is a
+// no-op when ctx carries no session replay ID.
+func (e *Event) SessionReplay(ctx context.Context) *Event {
+	if e.l == nil {
+		return e
+	}
+	if id, ok := SessionReplayIDFromContext(ctx); ok {
+		return e.Str(SessionReplayField, id)
+	}
+	return e
+}
+
+// ValidSessionReplayID reports whether id looks like a replay tool's
+// session ID: 1 to MaxSessionReplayIDLength ASCII letters, digits and
+// "-", "_", ".", ":". IDs arriving in request headers are client input
+// and should be checked before they reach the logs.
+func ValidSessionReplayID(id string) bool {
+	if id == "" || len(id) > MaxSessionReplayIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		switch c := id[i]; {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9',
+			c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,36 @@
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestSessionReplay(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewJSONHandler(&buf))
+
+	logger.Info().SessionReplay(context.Background()).Msg("none")
+	ctx := ContextWithSessionReplayID(context.Background(), "abc-123")
+	logger.Info().SessionReplay(ctx).Msg("linked")
+
+	want := `{"level":"info","message":"none"}` + "\n" +
+		`{"level":"info","session_replay_id":"abc-123","message":"linked"}` + "\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+
+	for id, valid := range map[string]bool{
+		"abc-123":      true,
+		"0192f.e4:a_B": true,
+		"":             false,
+		"has space":    false,
+		`quote"`:       false,
+		strings.Repeat("a", MaxSessionReplayIDLength+1): false,
+	} {
+		if ValidSessionReplayID(id) != valid {
+			t.Errorf("ValidSessionReplayID(%q) = %v", id, !valid)
+		}
+	}
+}
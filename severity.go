@@ -0,0 +1,91 @@
+package bolt
+
+// SeverityMap translates bolt levels into one destination's severity
+// scale, indexed by [Level]. Declare a map once and share it between the
+// sinks writing to that destination instead of hardcoding a table in
+// each:
+//
+//	var mySeverity = bolt.SeverityMap[string]{"TRACE", "DEBUG", "INFO", "WARN", "ERROR", "PANIC"}
+type SeverityMap[T any] [FATAL + 1]T
+
+// Of returns the severity for l. Levels outside TRACE..FATAL map like
+// the nearest valid level.
+func (m *SeverityMap[T]) Of(l Level) T {
+	switch {
+	case l < TRACE:
+		l = TRACE
+	case l > FATAL:
+		l = FATAL
+	}
+	return m[l]
+}
+
+// Standard severity maps.
+var (
+	// SyslogSeverity maps to RFC 5424 severity numbers: debug (7),
+	// informational (6), warning (4), error (3) and critical (2).
+	SyslogSeverity = SeverityMap[int]{7, 7, 6, 4, 3, 2}
+	// GCPSeverity maps to Google Cloud Logging LogSeverity names.
+	GCPSeverity = SeverityMap[string]{"DEBUG", "DEBUG", "INFO", "WARNING", "ERROR", "CRITICAL"}
+	// DatadogStatus maps to Datadog log status names.
+	DatadogStatus = SeverityMap[string]{"debug", "debug", "info", "warn", "error", "critical"}
+	// OTelSeverity maps to the first OpenTelemetry SeverityNumber of
+	// each range: TRACE 1, DEBUG 5, INFO 9, WARN 13, ERROR 17, FATAL 21.
+	OTelSeverity = SeverityMap[int]{1, 5, 9, 13, 17, 21}
+	// WindowsEventType maps to Windows event log types:
+	// EVENTLOG_INFORMATION_TYPE (4), EVENTLOG_WARNING_TYPE (2) and
+	// EVENTLOG_ERROR_TYPE (1).
+	WindowsEventType = SeverityMap[uint16]{4, 4, 4, 2, 1, 1}
+)
+
+// Severities bundles the severity maps of the destinations a deployment
+// writes to, so the mapping is configured once and every sink reads it
+// from the same place. Sinks take a *Severities in their options and use
+// DefaultSeverities when it is nil.
+type Severities struct {
+	Syslog  SeverityMap[int]
+	GCP     SeverityMap[string]
+	Datadog SeverityMap[string]
+	OTel    SeverityMap[int]
+	Windows SeverityMap[uint16]
+}
+
+// DefaultSeverities holds the standard maps. Copy and adjust it, rather
+// than modifying it, to change a mapping:
+//
+//	sev := bolt.DefaultSeverities
+//	sev.GCP[bolt.FATAL] = "EMERGENCY"
+var DefaultSeverities = Severities{
+	Syslog:  SyslogSeverity,
+	GCP:     GCPSeverity,
+	Datadog: DatadogStatus,
+	OTel:    OTelSeverity,
+	Windows: WindowsEventType,
+}
+
+// severityHook adds a severity field to every event.
+type severityHook[T string | int | uint16] struct {
+	key string
+	m   SeverityMap[T]
+}
+
+// SeverityField returns an [EventHook] adding key with m's severity for
+// the event's level, for destinations that read the severity from the
+// record itself, such as Cloud Logging's "severity" field:
+//
+//	logger.AddEventHook(bolt.SeverityField("severity", bolt.GCPSeverity))
+func SeverityField[T string | int | uint16](key string, m SeverityMap[T]) EventHook {
+	return &severityHook[T]{key: key, m: m}
+}
+
+func (h *severityHook[T]) Run(e *Event, _ string) bool {
+	switch v := any(h.m.Of(e.Level())).(type) {
+	case string:
+		e.Str(h.key, v)
+	case int:
+		e.Int(h.key, v)
+	case uint16:
+		e.Uint16(h.key, v)
+	}
+	return true
+}
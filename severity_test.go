@@ -0,0 +1,48 @@
+package bolt
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSeverityMapOf(t *testing.T) {
+	if got := SyslogSeverity.Of(WARN); got != 4 {
+		t.Errorf("syslog WARN = %d, want 4", got)
+	}
+	if got := OTelSeverity.Of(Level(42)); got != 21 {
+		t.Errorf("otel out-of-range = %d, want FATAL's 21", got)
+	}
+	if got := GCPSeverity.Of(Level(-3)); got != "DEBUG" {
+		t.Errorf("gcp below TRACE = %q, want DEBUG", got)
+	}
+
+	sev := DefaultSeverities
+	sev.GCP[FATAL] = "EMERGENCY"
+	if DefaultSeverities.GCP.Of(FATAL) != "CRITICAL" || sev.GCP.Of(FATAL) != "EMERGENCY" {
+		t.Error("copying DefaultSeverities did not copy its maps")
+	}
+}
+
+func TestSeverityField(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewJSONHandler(&buf)).
+		AddEventHook(SeverityField("severity", GCPSeverity)).
+		AddEventHook(SeverityField("syslog", SyslogSeverity))
+
+	logger.Warn().Msg("disk almost full")
+	want := `{"level":"warn","severity":"WARNING","syslog":4,"message":"disk almost full"}` + "\n"
+	if buf.String() != want {
+		t.Errorf("got  %s\nwant %s", buf.String(), want)
+	}
+
+	if raceDetectorEnabled {
+		t.Skip("allocation counts are not meaningful under -race")
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		buf.Reset()
+		logger.Info().Msg("ok")
+	})
+	if allocs != 0 {
+		t.Errorf("allocs per event = %v, want 0", allocs)
+	}
+}
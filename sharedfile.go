@@ -0,0 +1,208 @@
+package bolt
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultSharedFileMaxWrite is the largest single write a [SharedFile]
+// accepts when no limit is configured: [MaxRecordSize], so every record
+// a bolt logger builds fits. O_APPEND writes of that size do not tear on
+// local regular files; set [SharedFileOptions.MaxWrite] lower for pipes
+// (PIPE_BUF) or network filesystems.
+const DefaultSharedFileMaxWrite = MaxRecordSize
+
+// ErrWriteTooLarge is returned by [SharedFile.Write] for a record larger
+// than the configured maximum. The record is rejected whole rather than
+// split, because a split write can interleave with another process.
+var ErrWriteTooLarge = errors.New("bolt: record exceeds shared file write limit")
+
+// SharedFileOptions configures [OpenSharedFile]. A nil *SharedFileOptions
+// uses the defaults.
+type SharedFileOptions struct {
+	// MaxWrite caps the size of a single record. Defaults to
+	// DefaultSharedFileMaxWrite.
+	MaxWrite int
+
+	// Lock takes an advisory exclusive lock on the file around every
+	// write (flock on Unix, LockFileEx on Windows). Enable it when the
+	// file lives on a filesystem without atomic appends, such as NFS, or
+	// when other writers may issue short writes. It costs two system
+	// calls per record and is ignored on platforms without file locking.
+	Lock bool
+
+	// Manifest writes a sidecar "<file>.manifest.json" ([FileManifest])
+	// whenever the file is closed or given up by [SharedFile.Reopen],
+	// recording its line count, size and SHA-256 so shipping pipelines
+	// can detect truncation (see [VerifyManifest]). The manifest covers
+	// the whole file, including content present before it was opened, and
+	// so is only meaningful when this SharedFile is the file's sole
+	// writer.
+	//
+	// After a rotation the manifest is written next to the file under its
+	// new name. Resolving that name needs Linux; elsewhere, if the file
+	// was renamed, the manifest is named after the original path and the
+	// close time instead.
+	Manifest bool
+}
+
+// SharedFile is an append-only log file that several processes can write
+// to at once — prefork servers, cron jobs and CLI invocations sharing one
+// log — without interleaving partial lines.
+//
+// Every record is written with a single write(2) to a file opened with
+// O_APPEND, which the OS appends atomically on local filesystems. Records
+// above the size cap are rejected rather than split, and with
+// [SharedFileOptions.Lock] each write additionally holds an advisory
+// lock, so cooperating writers never see a torn line even where appends
+// are not atomic.
+//
+// Pass it to a handler such as [NewJSONHandler], which writes one record
+// per call.
+type SharedFile struct {
+	mu       sync.Mutex
+	path     string
+	f        *os.File
+	maxWrite int
+	lock     bool
+	manifest bool
+	digest   *fileDigest // nil unless manifest
+}
+
+// OpenSharedFile opens path for appending, creating it with mode 0600 if
+// it does not exist. If opts is nil, defaults are used.
+func OpenSharedFile(path string, opts *SharedFileOptions) (*SharedFile, error) {
+	if opts == nil {
+		opts = &SharedFileOptions{}
+	}
+	maxWrite := opts.MaxWrite
+	if maxWrite <= 0 {
+		maxWrite = DefaultSharedFileMaxWrite
+	}
+	s := &SharedFile{path: path, maxWrite: maxWrite, lock: opts.Lock, manifest: opts.Manifest}
+	f, digest, err := s.open()
+	if err != nil {
+		return nil, err
+	}
+	s.f, s.digest = f, digest
+	return s, nil
+}
+
+// open opens s.path for appending and, with manifests enabled, digests
+// its existing contents.
+func (s *SharedFile) open() (*os.File, *fileDigest, error) {
+	f, err := openAppend(s.path)
+	if err != nil || !s.manifest {
+		return f, nil, err
+	}
+	d, err := newFileDigest(s.path)
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, err
+	}
+	return f, d, nil
+}
+
+// Write appends p as one record.
+func (s *SharedFile) Write(p []byte) (int, error) {
+	if len(p) > s.maxWrite {
+		return 0, fmt.Errorf("%w: %d > %d bytes", ErrWriteTooLarge, len(p), s.maxWrite)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n, err := s.write(p)
+	if s.digest != nil {
+		_, _ = s.digest.Write(p[:n])
+	}
+	return n, err
+}
+
+func (s *SharedFile) write(p []byte) (int, error) {
+	if !s.lock {
+		return s.f.Write(p)
+	}
+	if err := lockFile(s.f); err != nil {
+		return 0, fmt.Errorf("bolt: lock %s: %w", s.path, err)
+	}
+	n, err := s.f.Write(p)
+	if uerr := unlockFile(s.f); err == nil && uerr != nil {
+		err = fmt.Errorf("bolt: unlock %s: %w", s.path, uerr)
+	}
+	return n, err
+}
+
+// Reopen closes the file and opens path again, picking up a new file
+// after an external tool such as logrotate has renamed the old one. The
+// new file is opened before the old one is closed, so on error the
+// SharedFile keeps writing to the old file. See [ReopenOnSignal].
+func (s *SharedFile) Reopen() error {
+	f, digest, err := s.open()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	old, oldDigest := s.f, s.digest
+	s.f, s.digest = f, digest
+	s.mu.Unlock()
+	return s.closeFile(old, oldDigest)
+}
+
+// Name returns the path the file was opened with.
+func (s *SharedFile) Name() string {
+	return s.path
+}
+
+// Sync commits the file's contents to stable storage.
+func (s *SharedFile) Sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.f.Sync()
+}
+
+// Close closes the file, writing its manifest if enabled.
+func (s *SharedFile) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d := s.digest
+	s.digest = nil // one manifest, even if Close is called again
+	return s.closeFile(s.f, d)
+}
+
+// closeFile closes f and, if d is non-nil, writes the manifest for it.
+func (s *SharedFile) closeFile(f *os.File, d *fileDigest) error {
+	if d == nil {
+		return f.Close()
+	}
+	name := s.currentName(f)
+	err := f.Close()
+	now := time.Now()
+	if name == "" {
+		name = s.path + "." + now.UTC().Format("20060102T150405Z")
+	}
+	if merr := writeManifest(name, d.manifest(filepath.Base(name), now)); merr != nil {
+		err = errors.Join(err, fmt.Errorf("bolt: write manifest for %s: %w", name, merr))
+	}
+	return err
+}
+
+// currentName returns where f lives now: its original path unless it has
+// been renamed, or "" if the new name cannot be determined.
+func (s *SharedFile) currentName(f *os.File) string {
+	fi, err := f.Stat()
+	if err != nil {
+		return ""
+	}
+	if pi, err := os.Stat(s.path); err == nil && os.SameFile(fi, pi) {
+		return s.path
+	}
+	name, _ := fileName(f)
+	return name
+}
+
+func openAppend(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600) // #nosec G304 -- caller-supplied log path
+}
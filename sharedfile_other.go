@@ -0,0 +1,11 @@
+//go:build !unix && !windows
+
+package bolt
+
+import "os"
+
+// File locking is unavailable (js/wasm, plan9); writes rely on O_APPEND.
+func lockFile(*os.File) error   { return nil }
+func unlockFile(*os.File) error { return nil }
+
+func fileName(*os.File) (string, bool) { return "", false }
@@ -0,0 +1,178 @@
+package bolt
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestSharedFileConcurrentWriters opens the file once per writer, as
+// separate processes would, and checks that no line is torn.
+func TestSharedFileConcurrentWriters(t *testing.T) {
+	for _, lock := range []bool{false, true} {
+		t.Run("lock="+strconv.FormatBool(lock), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "shared.log")
+			const writers, perWriter = 6, 300
+			payload := strings.Repeat("x", 3000) // spans several pipe-sized chunks
+
+			var wg sync.WaitGroup
+			for w := 0; w < writers; w++ {
+				f, err := OpenSharedFile(path, &SharedFileOptions{Lock: lock})
+				if err != nil {
+					t.Fatal(err)
+				}
+				logger := New(NewJSONHandler(f))
+				wg.Add(1)
+				go func(w int) {
+					defer wg.Done()
+					defer f.Close()
+					for i := 0; i < perWriter; i++ {
+						logger.Info().Int("writer", w).Int("i", i).Str("payload", payload).Msg("line")
+					}
+				}(w)
+			}
+			wg.Wait()
+
+			f, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			sc := bufio.NewScanner(f)
+			sc.Buffer(nil, 1<<20)
+			lines := 0
+			for sc.Scan() {
+				line := sc.Text()
+				if !strings.HasPrefix(line, `{"level":"info"`) || !strings.HasSuffix(line, `"message":"line"}`) {
+					t.Fatalf("torn line %d: %.80q...", lines, line)
+				}
+				lines++
+			}
+			if lines != writers*perWriter {
+				t.Errorf("got %d lines; want %d", lines, writers*perWriter)
+			}
+		})
+	}
+}
+
+func TestSharedFileRejectsOversizedRecords(t *testing.T) {
+	f, err := OpenSharedFile(filepath.Join(t.TempDir(), "x.log"), &SharedFileOptions{MaxWrite: 64})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var got error
+	logger := New(NewJSONHandler(f)).SetErrorHandler(func(err error) { got = err })
+	logger.Info().Str("big", strings.Repeat("y", 100)).Msg("too big")
+	if !errors.Is(got, ErrWriteTooLarge) {
+		t.Errorf("error = %v; want ErrWriteTooLarge", got)
+	}
+	if fi, _ := os.Stat(f.f.Name()); fi.Size() != 0 {
+		t.Errorf("partial record written: %d bytes", fi.Size())
+	}
+}
+
+func TestSharedFileDefaultFitsLargeRecords(t *testing.T) {
+	f, err := OpenSharedFile(filepath.Join(t.TempDir(), "x.log"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var got error
+	logger := New(NewJSONHandler(f)).SetErrorHandler(func(err error) { got = err })
+	// Fields up to MaxBufferSize, then the longest message.
+	e := logger.Info()
+	value := strings.Repeat("y", MaxValueLength)
+	for i := 0; i < MaxBufferSize/MaxValueLength-1; i++ {
+		e = e.Str("k"+strconv.Itoa(i), value)
+	}
+	e.Msg(strings.Repeat("\x01", MaxValueLength)) // escaped as \u0001
+	if got != nil {
+		t.Errorf("large record rejected: %v", got)
+	}
+	if fi, _ := os.Stat(f.f.Name()); fi.Size() <= MaxBufferSize {
+		t.Errorf("record not written: %d bytes", fi.Size())
+	}
+}
+
+func TestSharedFileManifest(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(path, []byte("existing\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := OpenSharedFile(path, &SharedFileOptions{Manifest: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := New(NewJSONHandler(f))
+	for i := 0; i < 3; i++ {
+		logger.Info().Int("i", i).Msg("line")
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := ReadManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if m.File != "app.log" || m.Lines != 4 || m.Bytes != uint64(len(data)) {
+		t.Errorf("manifest = %+v; want app.log, 4 lines, %d bytes", m, len(data))
+	}
+	if err := VerifyManifest(path); err != nil {
+		t.Errorf("VerifyManifest: %v", err)
+	}
+
+	// Simulate truncation in transit.
+	if err := os.WriteFile(path, data[:len(data)-5], 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyManifest(path); !errors.Is(err, ErrManifestMismatch) {
+		t.Errorf("VerifyManifest after truncation = %v; want ErrManifestMismatch", err)
+	}
+}
+
+func TestSharedFileManifestOnReopen(t *testing.T) {
+	if _, err := os.Stat("/proc/self/fd"); err != nil {
+		t.Skip("renamed file names are only resolved on Linux")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	f, err := OpenSharedFile(path, &SharedFileOptions{Manifest: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	logger := New(NewJSONHandler(f))
+	logger.Info().Msg("before rotation")
+
+	rotated := path + ".1"
+	if err := os.Rename(path, rotated); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	logger.Info().Msg("after rotation")
+
+	m, err := ReadManifest(rotated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.File != "app.log.1" || m.Lines != 1 {
+		t.Errorf("manifest = %+v; want app.log.1 with 1 line", m)
+	}
+	if err := VerifyManifest(rotated); err != nil {
+		t.Errorf("VerifyManifest: %v", err)
+	}
+	if _, err := os.Stat(path + ManifestSuffix); !os.IsNotExist(err) {
+		t.Errorf("live file already has a manifest: %v", err)
+	}
+}
@@ -0,0 +1,35 @@
+//go:build unix
+
+package bolt
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+func lockFile(f *os.File) error {
+	for {
+		err := unix.Flock(int(f.Fd()), unix.LOCK_EX)
+		if err != unix.EINTR {
+			return err
+		}
+	}
+}
+
+func unlockFile(f *os.File) error {
+	return unix.Flock(int(f.Fd()), unix.LOCK_UN)
+}
+
+// fileName resolves the current path of f, which changes when the file
+// is renamed while open. Only Linux exposes it, through /proc.
+func fileName(f *os.File) (string, bool) {
+	name, err := os.Readlink("/proc/self/fd/" + strconv.Itoa(int(f.Fd())))
+	if err != nil || !filepath.IsAbs(name) || strings.HasSuffix(name, " (deleted)") {
+		return "", false
+	}
+	return name, true
+}
@@ -0,0 +1,193 @@
+package bolt
+
+import "strconv"
+
+// DefaultCEFKeys maps conventional bolt field keys to CEF extension keys.
+// It is used by a [CEFEncoder] whose Keys is nil.
+var DefaultCEFKeys = map[string]string{
+	"user":      "suser",
+	"client_ip": "src",
+	"ip":        "src",
+	"method":    "requestMethod",
+	"url":       "request",
+	"path":      "request",
+	"error":     "reason",
+}
+
+// DefaultLEEFKeys maps conventional bolt field keys to LEEF attributes.
+// It is used by a [LEEFEncoder] whose Keys is nil.
+var DefaultLEEFKeys = map[string]string{
+	"user":      "usrName",
+	"client_ip": "src",
+	"ip":        "src",
+	"url":       "url",
+}
+
+// CEFEncoder is the [Encoder] for ArcSight Common Event Format, accepted
+// by most SIEMs:
+//
+//	CEF:0|Acme|billing|1.4.2|charge failed|charge failed|8|cat=error suser=ada order_id=o-17
+//
+// The message serves as both the signature ID and the name, the level
+// sets the severity (TRACE 1 to FATAL 10) and the "cat" extension, and
+// every field becomes an extension. Keys maps bolt field keys to
+// extension keys; nil uses [DefaultCEFKeys], and an empty map keeps every
+// key as it is. Characters other than letters, digits, '_' and '.' in
+// unmapped keys become '_', and objects and arrays are written as JSON.
+//
+//	logger := bolt.New(bolt.NewEncoderHandler(conn, bolt.CEFEncoder{
+//	    Vendor: "Acme", Product: "billing", Version: "1.4.2",
+//	}))
+type CEFEncoder struct {
+	// Vendor, Product and Version fill the device fields of the header.
+	Vendor, Product, Version string
+
+	// Keys maps bolt field keys to extension keys.
+	Keys map[string]string
+}
+
+// AppendBegin implements [Encoder].
+func (c CEFEncoder) AppendBegin(dst []byte, level Level, msg []byte) []byte {
+	dst = append(dst, "CEF:0|"...)
+	for _, f := range [...]string{c.Vendor, c.Product, c.Version} {
+		dst = appendSIEMHeader(dst, f)
+		dst = append(dst, '|')
+	}
+	dst = appendSIEMHeader(dst, msg)
+	dst = append(dst, '|')
+	dst = appendSIEMHeader(dst, msg)
+	dst = append(dst, '|')
+	dst = strconv.AppendInt(dst, int64(siemSeverity(level)), 10)
+	dst = append(dst, "|cat="...)
+	return append(dst, level.String()...)
+}
+
+// AppendField implements [Encoder].
+func (c CEFEncoder) AppendField(dst []byte, _ FieldKind, key, value []byte) []byte {
+	dst = append(dst, ' ')
+	dst = appendSIEMKey(dst, key, c.Keys, DefaultCEFKeys)
+	dst = append(dst, '=')
+	for _, b := range value {
+		switch b {
+		case '\\', '=':
+			dst = append(dst, '\\', b)
+		case '\n':
+			dst = append(dst, `\n`...)
+		case '\r':
+			dst = append(dst, `\r`...)
+		default:
+			dst = append(dst, b)
+		}
+	}
+	return dst
+}
+
+// AppendEnd implements [Encoder].
+func (CEFEncoder) AppendEnd(dst []byte, _ Level, _ []byte) []byte {
+	return append(dst, '\n')
+}
+
+// LEEFEncoder is the [Encoder] for IBM QRadar's Log Event Extended Format
+// 1.0:
+//
+//	LEEF:1.0|Acme|billing|1.4.2|charge failed|sev=8	cat=error	usrName=ada	order_id=o-17
+//
+// The message is the event ID, the level sets the "sev" (TRACE 1 to
+// FATAL 10) and "cat" attributes, and every field becomes a
+// tab-separated attribute. Keys maps bolt field keys to attribute names;
+// nil uses [DefaultLEEFKeys], and an empty map keeps every key as it is.
+// LEEF has no escaping, so tabs and line breaks in values become spaces.
+type LEEFEncoder struct {
+	// Vendor, Product and Version fill the header.
+	Vendor, Product, Version string
+
+	// Keys maps bolt field keys to attribute names.
+	Keys map[string]string
+}
+
+// AppendBegin implements [Encoder].
+func (l LEEFEncoder) AppendBegin(dst []byte, level Level, msg []byte) []byte {
+	dst = append(dst, "LEEF:1.0|"...)
+	for _, f := range [...]string{l.Vendor, l.Product, l.Version} {
+		dst = appendSIEMHeader(dst, f)
+		dst = append(dst, '|')
+	}
+	dst = appendSIEMHeader(dst, msg)
+	dst = append(dst, '|')
+	dst = append(dst, "sev="...)
+	dst = strconv.AppendInt(dst, int64(siemSeverity(level)), 10)
+	dst = append(dst, "\tcat="...)
+	return append(dst, level.String()...)
+}
+
+// AppendField implements [Encoder].
+func (l LEEFEncoder) AppendField(dst []byte, _ FieldKind, key, value []byte) []byte {
+	dst = append(dst, '\t')
+	dst = appendSIEMKey(dst, key, l.Keys, DefaultLEEFKeys)
+	dst = append(dst, '=')
+	for _, b := range value {
+		if b == '\t' || b == '\n' || b == '\r' {
+			b = ' '
+		}
+		dst = append(dst, b)
+	}
+	return dst
+}
+
+// AppendEnd implements [Encoder].
+func (LEEFEncoder) AppendEnd(dst []byte, _ Level, _ []byte) []byte {
+	return append(dst, '\n')
+}
+
+// siemSeverity maps a level onto the 0-10 severity scale shared by CEF
+// and LEEF.
+func siemSeverity(level Level) int {
+	switch level {
+	case TRACE:
+		return 1
+	case DEBUG:
+		return 2
+	case INFO:
+		return 3
+	case WARN:
+		return 6
+	case ERROR:
+		return 8
+	default:
+		return 10
+	}
+}
+
+// appendSIEMHeader appends a header field, escaping '|' and '\' and
+// replacing line breaks, which headers cannot contain.
+func appendSIEMHeader[S string | []byte](dst []byte, f S) []byte {
+	for i := 0; i < len(f); i++ {
+		switch b := f[i]; b {
+		case '|', '\\':
+			dst = append(dst, '\\', b)
+		case '\n', '\r':
+			dst = append(dst, ' ')
+		default:
+			dst = append(dst, b)
+		}
+	}
+	return dst
+}
+
+// appendSIEMKey appends the extension key for a bolt field key, mapped
+// through keys, or defaults if keys is nil.
+func appendSIEMKey(dst, key []byte, keys, defaults map[string]string) []byte {
+	if keys == nil {
+		keys = defaults
+	}
+	if name, ok := keys[string(key)]; ok {
+		return append(dst, name...)
+	}
+	for _, b := range key {
+		if !(b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9' || b == '_' || b == '.') {
+			b = '_'
+		}
+		dst = append(dst, b)
+	}
+	return dst
+}
@@ -0,0 +1,40 @@
+package bolt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCEFEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewEncoderHandler(&buf, CEFEncoder{Vendor: "Acme", Product: "bill|ing", Version: "1.4.2"}))
+	logger.Error().Str("user", "ada").Str("query", `a=b\c`).Str("order id", "o-17").Msg("charge failed")
+	logger.Info().Str("note", "two\nlines").Msg(`pipe|back\slash`)
+
+	want := []string{
+		`CEF:0|Acme|bill\|ing|1.4.2|charge failed|charge failed|8|cat=error suser=ada query=a\=b\\c order_id=o-17`,
+		`CEF:0|Acme|bill\|ing|1.4.2|pipe\|back\\slash|pipe\|back\\slash|3|cat=info note=two\nlines`,
+	}
+	if got := strings.TrimSuffix(buf.String(), "\n"); got != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", got, strings.Join(want, "\n"))
+	}
+}
+
+func TestCEFEncoderKeys(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewEncoderHandler(&buf, CEFEncoder{Keys: map[string]string{"order_id": "cs1"}}))
+	logger.Warn().Str("user", "ada").Str("order_id", "o-17").Msg("m")
+	if want := "CEF:0||||m|m|6|cat=warn user=ada cs1=o-17\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestLEEFEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewEncoderHandler(&buf, LEEFEncoder{Vendor: "Acme", Product: "billing", Version: "1.4.2"}))
+	logger.Warn().Str("user", "ada").Str("note", "a\tb\nc").Msg("slow charge")
+	if want := "LEEF:1.0|Acme|billing|1.4.2|slow charge|sev=6\tcat=warn\tusrName=ada\tnote=a b c\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
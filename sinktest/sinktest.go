@@ -0,0 +1,278 @@
+// Package sinktest provides a conformance suite for [bolt.Handler]
+// implementations that write to an [io.Writer], in the spirit of
+// testing/slogtest.
+//
+// Run the suite from a test in the sink's own package:
+//
+//	func TestConformance(t *testing.T) {
+//	    sinktest.Run(t, func(out io.Writer, onError bolt.ErrorHandler) sinktest.Sink {
+//	        h := mysink.New(out, mysink.Options{OnError: onError})
+//	        return sinktest.Sink{Handler: h, Flush: h.Flush, Close: h.Close}
+//	    })
+//	}
+//
+// The suite checks that, once written and flushed:
+//   - events from one goroutine reach out in the order they were logged;
+//   - events from many goroutines all arrive, each exactly once;
+//   - a flushed event is visible in out (read-your-writes), immediately
+//     after Msg for sinks without a Flush;
+//   - a failing writer surfaces its error through Write, Flush, Close or
+//     the onError callback, wrapped so that errors.Is still matches;
+//   - Close writes everything pending, may be called twice, and nothing
+//     reaches out after it returns.
+//
+// Checks look for each event's message text in out, so they work for any
+// encoding that writes the message verbatim (JSON, console, BSON).
+package sinktest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.klarlabs.de/bolt"
+)
+
+// Sink is a handler under test together with its lifecycle hooks.
+type Sink struct {
+	// Handler receives the events.
+	Handler bolt.Handler
+
+	// Flush, if set, blocks until every event written so far has reached
+	// the writer. Leave nil for sinks that write synchronously.
+	Flush func() error
+
+	// Close, if set, flushes and releases the sink.
+	Close func() error
+}
+
+// NewSink constructs a fresh sink writing to out. Errors that the sink
+// cannot return from Write, such as failures on a background goroutine,
+// must be reported to onError.
+type NewSink func(out io.Writer, onError bolt.ErrorHandler) Sink
+
+// errWriter is the error returned by the failing writer.
+var errWriter = errors.New("sinktest: write failed")
+
+// writer is a concurrency-safe in-memory destination that can be told to
+// fail.
+type writer struct {
+	mu   sync.Mutex
+	buf  bytes.Buffer
+	fail bool
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.fail {
+		return 0, errWriter
+	}
+	return w.buf.Write(p)
+}
+
+func (w *writer) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+// errorLog collects errors from every path the suite observes.
+type errorLog struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (l *errorLog) add(err error) {
+	if err == nil {
+		return
+	}
+	l.mu.Lock()
+	l.errs = append(l.errs, err)
+	l.mu.Unlock()
+}
+
+func (l *errorLog) list() []error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]error(nil), l.errs...)
+}
+
+// env is one sink instance wired to a fresh writer and error log.
+type env struct {
+	out  *writer
+	errs *errorLog
+	sink Sink
+	log  *bolt.Logger
+}
+
+func newEnv(t *testing.T, newSink NewSink) *env {
+	t.Helper()
+	e := &env{out: &writer{}, errs: &errorLog{}}
+	e.sink = newSink(e.out, e.errs.add)
+	if e.sink.Handler == nil {
+		t.Fatal("sinktest: NewSink returned a nil Handler")
+	}
+	e.log = bolt.New(e.sink.Handler).SetErrorHandler(e.errs.add)
+	t.Cleanup(func() { _ = e.close() })
+	return e
+}
+
+func (e *env) flush() error {
+	if e.sink.Flush == nil {
+		return nil
+	}
+	err := e.sink.Flush()
+	e.errs.add(err)
+	return err
+}
+
+func (e *env) close() error {
+	if e.sink.Close == nil {
+		return nil
+	}
+	err := e.sink.Close()
+	e.errs.add(err)
+	return err
+}
+
+func msg(prefix string, i int) string {
+	return fmt.Sprintf("sinktest-%s-%04d", prefix, i)
+}
+
+// Run runs the conformance suite as subtests of t, constructing a new sink
+// for each.
+func Run(t *testing.T, newSink NewSink) {
+	t.Run("Ordering", func(t *testing.T) { testOrdering(t, newSink) })
+	t.Run("Concurrent", func(t *testing.T) { testConcurrent(t, newSink) })
+	t.Run("ReadYourWrites", func(t *testing.T) { testReadYourWrites(t, newSink) })
+	t.Run("ErrorPropagation", func(t *testing.T) { testErrorPropagation(t, newSink) })
+	t.Run("Shutdown", func(t *testing.T) { testShutdown(t, newSink) })
+}
+
+func testOrdering(t *testing.T, newSink NewSink) {
+	e := newEnv(t, newSink)
+	const n = 200
+	for i := 0; i < n; i++ {
+		e.log.Info().Int("seq", i).Msg(msg("order", i))
+	}
+	if err := e.flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	out := e.out.String()
+	last := -1
+	for i := 0; i < n; i++ {
+		at := strings.Index(out, msg("order", i))
+		if at < 0 {
+			t.Fatalf("event %d missing after Flush", i)
+		}
+		if at < last {
+			t.Fatalf("event %d written before event %d", i, i-1)
+		}
+		last = at
+	}
+}
+
+func testConcurrent(t *testing.T, newSink NewSink) {
+	e := newEnv(t, newSink)
+	const goroutines, perG = 8, 50
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < perG; i++ {
+				e.log.Info().Msg(msg(fmt.Sprintf("g%d", g), i))
+			}
+		}(g)
+	}
+	wg.Wait()
+	if err := e.flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	out := e.out.String()
+	for g := 0; g < goroutines; g++ {
+		last := -1
+		for i := 0; i < perG; i++ {
+			m := msg(fmt.Sprintf("g%d", g), i)
+			if c := strings.Count(out, m); c != 1 {
+				t.Fatalf("%s written %d times; want 1", m, c)
+			}
+			at := strings.Index(out, m)
+			if at < last {
+				t.Fatalf("%s reordered within its goroutine", m)
+			}
+			last = at
+		}
+	}
+	if errs := e.errs.list(); len(errs) > 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+}
+
+func testReadYourWrites(t *testing.T, newSink NewSink) {
+	e := newEnv(t, newSink)
+	for i := 0; i < 3; i++ {
+		e.log.Warn().Msg(msg("ryw", i))
+		if err := e.flush(); err != nil {
+			t.Fatalf("Flush: %v", err)
+		}
+		if !strings.Contains(e.out.String(), msg("ryw", i)) {
+			if e.sink.Flush == nil {
+				t.Fatalf("event %d not visible after Msg returned; set Sink.Flush for buffering sinks", i)
+			}
+			t.Fatalf("event %d not visible after Flush", i)
+		}
+	}
+}
+
+func testErrorPropagation(t *testing.T, newSink NewSink) {
+	e := newEnv(t, newSink)
+	e.out.mu.Lock()
+	e.out.fail = true
+	e.out.mu.Unlock()
+
+	e.log.Error().Msg(msg("fail", 0))
+	_ = e.flush()
+	_ = e.close()
+
+	for _, err := range e.errs.list() {
+		if errors.Is(err, errWriter) {
+			return
+		}
+	}
+	t.Errorf("writer error never surfaced; got %v (wrap with %%w to keep errors.Is working)", e.errs.list())
+}
+
+func testShutdown(t *testing.T, newSink NewSink) {
+	e := newEnv(t, newSink)
+	const n = 50
+	for i := 0; i < n; i++ {
+		e.log.Info().Msg(msg("close", i))
+	}
+	if err := e.close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	out := e.out.String()
+	for i := 0; i < n; i++ {
+		if !strings.Contains(out, msg("close", i)) {
+			t.Fatalf("event %d lost on Close", i)
+		}
+	}
+	if e.sink.Close == nil {
+		return
+	}
+
+	// A second Close and writes after Close must not panic, and nothing
+	// may reach the writer once Close has returned.
+	_ = e.sink.Close()
+	e.log.Info().Msg(msg("after", 0))
+	_ = e.flush()
+	if strings.Contains(e.out.String(), msg("after", 0)) {
+		t.Errorf("event logged after Close reached the writer")
+	}
+}
@@ -0,0 +1,282 @@
+package sinktest_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"go.klarlabs.de/bolt"
+	"go.klarlabs.de/bolt/sinktest"
+)
+
+func TestJSONHandler(t *testing.T) {
+	sinktest.Run(t, func(out io.Writer, _ bolt.ErrorHandler) sinktest.Sink {
+		return sinktest.Sink{Handler: bolt.NewJSONHandler(out)}
+	})
+}
+
+func TestConsoleHandler(t *testing.T) {
+	sinktest.Run(t, func(out io.Writer, _ bolt.ErrorHandler) sinktest.Sink {
+		return sinktest.Sink{Handler: bolt.NewConsoleHandler(out)}
+	})
+}
+
+func TestBSONHandler(t *testing.T) {
+	sinktest.Run(t, func(out io.Writer, _ bolt.ErrorHandler) sinktest.Sink {
+		return sinktest.Sink{Handler: bolt.NewBSONHandler(out)}
+	})
+}
+
+func TestMultiHandler(t *testing.T) {
+	sinktest.Run(t, func(out io.Writer, _ bolt.ErrorHandler) sinktest.Sink {
+		return sinktest.Sink{Handler: bolt.MultiHandler(bolt.NewJSONHandler(out), bolt.NewJSONHandler(io.Discard))}
+	})
+}
+
+func TestAsyncHandler(t *testing.T) {
+	sinktest.Run(t, func(out io.Writer, onError bolt.ErrorHandler) sinktest.Sink {
+		h := bolt.NewAsyncHandler(bolt.NewJSONHandler(out), &bolt.AsyncOptions{ErrorHandler: onError})
+		return sinktest.Sink{
+			Handler: h,
+			Flush:   func() error { h.Flush(); return nil },
+			Close:   h.Close,
+		}
+	})
+}
+
+func TestBatchHandler(t *testing.T) {
+	sinktest.Run(t, func(out io.Writer, onError bolt.ErrorHandler) sinktest.Sink {
+		h := bolt.NewBatchHandler(bolt.NewJSONHandler(out), &bolt.BatchOptions{Block: true, ErrorHandler: onError})
+		return sinktest.Sink{
+			Handler: h,
+			Flush:   func() error { h.Flush(); return nil },
+			Close:   h.Close,
+		}
+	})
+}
+
+// roundTripFunc is an in-process http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+// postTo returns a client that writes request bodies to out and fails
+// the request when out does.
+func postTo(out io.Writer) *http.Client {
+	return &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		defer r.Body.Close()
+		if _, err := io.Copy(out, r.Body); err != nil {
+			return nil, err
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: r}, nil
+	})}
+}
+
+func TestHTTPHandler(t *testing.T) {
+	sinktest.Run(t, func(out io.Writer, onError bolt.ErrorHandler) sinktest.Sink {
+		h, err := bolt.NewHTTPHandler("http://collector.invalid/ingest", &bolt.HTTPOptions{
+			Client:     postTo(out),
+			Batch:      &bolt.BatchOptions{Block: true, ErrorHandler: onError},
+			MaxRetries: -1,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sinktest.Sink{
+			Handler: h,
+			Flush:   func() error { h.Flush(); return nil },
+			Close:   h.Close,
+		}
+	})
+}
+
+func TestSplunkHandler(t *testing.T) {
+	sinktest.Run(t, func(out io.Writer, onError bolt.ErrorHandler) sinktest.Sink {
+		h, err := bolt.NewSplunkHandler("http://splunk.invalid:8088", "token", &bolt.SplunkOptions{
+			Client:     postTo(out),
+			Batch:      &bolt.BatchOptions{Block: true, ErrorHandler: onError},
+			MaxRetries: -1,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sinktest.Sink{
+			Handler: h,
+			Flush:   func() error { h.Flush(); return nil },
+			Close:   h.Close,
+		}
+	})
+}
+
+// conn is an in-process net.Conn. Each Write goes to serve, which may
+// write records to the destination and returns the server's replies,
+// read back by Read.
+type conn struct {
+	serve func(p []byte) (reply []byte, err error)
+
+	mu      sync.Mutex
+	cond    sync.Cond
+	replies bytes.Buffer
+	closed  bool
+}
+
+func newConn(greeting string, serve func(p []byte) ([]byte, error)) *conn {
+	c := &conn{serve: serve}
+	c.cond.L = &c.mu
+	c.replies.WriteString(greeting)
+	return c
+}
+
+func (c *conn) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	closed := c.closed
+	c.mu.Unlock()
+	if closed {
+		return 0, net.ErrClosed
+	}
+	reply, err := c.serve(p)
+	if err != nil {
+		return 0, err
+	}
+	c.mu.Lock()
+	c.replies.Write(reply)
+	c.cond.Broadcast()
+	c.mu.Unlock()
+	return len(p), nil
+}
+
+func (c *conn) Read(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for c.replies.Len() == 0 && !c.closed {
+		c.cond.Wait()
+	}
+	if c.closed {
+		return 0, io.EOF
+	}
+	return c.replies.Read(p)
+}
+
+func (c *conn) Close() error {
+	c.mu.Lock()
+	c.closed = true
+	c.cond.Broadcast()
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *conn) LocalAddr() net.Addr              { return &net.TCPAddr{} }
+func (c *conn) RemoteAddr() net.Addr             { return &net.TCPAddr{} }
+func (c *conn) SetDeadline(time.Time) error      { return nil }
+func (c *conn) SetReadDeadline(time.Time) error  { return nil }
+func (c *conn) SetWriteDeadline(time.Time) error { return nil }
+
+func TestSyslogHandler(t *testing.T) {
+	sinktest.Run(t, func(out io.Writer, _ bolt.ErrorHandler) sinktest.Sink {
+		h, err := bolt.NewSyslogHandler(&bolt.SyslogOptions{
+			Network: "tcp",
+			Addr:    "syslog.invalid:514",
+			Dial: func(string, string) (net.Conn, error) {
+				return newConn("", func(p []byte) ([]byte, error) { _, err := out.Write(p); return nil, err }), nil
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sinktest.Sink{Handler: h, Close: h.Close}
+	})
+}
+
+// natsServe answers the NATS handshake and writes every published
+// payload to out.
+func natsServe(out io.Writer) func(p []byte) ([]byte, error) {
+	return func(p []byte) ([]byte, error) {
+		var reply []byte
+		for len(p) > 0 {
+			line, rest, _ := bytes.Cut(p, []byte("\r\n"))
+			p = rest
+			switch {
+			case bytes.Equal(line, []byte("PING")):
+				reply = append(reply, "PONG\r\n"...)
+			case bytes.HasPrefix(line, []byte("PUB ")):
+				n, err := strconv.Atoi(string(line[bytes.LastIndexByte(line, ' ')+1:]))
+				if err != nil || n+2 > len(p) {
+					return nil, fmt.Errorf("malformed PUB %q", line)
+				}
+				if _, err := out.Write(append(p[:n:n], '\n')); err != nil {
+					return nil, err
+				}
+				p = p[n+2:]
+			}
+		}
+		return reply, nil
+	}
+}
+
+func TestNATSHandler(t *testing.T) {
+	sinktest.Run(t, func(out io.Writer, onError bolt.ErrorHandler) sinktest.Sink {
+		h, err := bolt.NewNATSHandler(&bolt.NATSOptions{
+			Subject: "logs",
+			Dial: func(string, string) (net.Conn, error) {
+				return newConn("INFO {}\r\n", natsServe(out)), nil
+			},
+			ErrorHandler: onError,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sinktest.Sink{Handler: h, Close: h.Close}
+	})
+}
+
+func TestFailoverHandler(t *testing.T) {
+	sinktest.Run(t, func(out io.Writer, onError bolt.ErrorHandler) sinktest.Sink {
+		return sinktest.Sink{Handler: bolt.NewFailoverHandler(bolt.NewJSONHandler(out), bolt.NewJSONHandler(io.Discard), &bolt.FailoverOptions{
+			ErrorHandler: onError,
+		})}
+	})
+}
+
+func TestDocumentHandler(t *testing.T) {
+	sinktest.Run(t, func(out io.Writer, _ bolt.ErrorHandler) sinktest.Sink {
+		store := bolt.DocumentStoreFunc(func(_ string, doc []byte) error {
+			_, err := out.Write(doc)
+			return err
+		})
+		return sinktest.Sink{Handler: bolt.NewDocumentHandler(store, nil)}
+	})
+}
+
+func TestRotatingFile(t *testing.T) {
+	sinktest.Run(t, func(out io.Writer, onError bolt.ErrorHandler) sinktest.Sink {
+		f, err := bolt.OpenRotatingFile(filepath.Join(t.TempDir(), "app.log"), &bolt.RotatingFileOptions{
+			MaxSize:      4 << 10,
+			ErrorHandler: onError,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Every record that reaches the file is copied to out, so nothing
+		// reaches out once the file is closed.
+		return sinktest.Sink{Handler: bolt.NewJSONHandler(io.MultiWriter(f, out)), Close: f.Close}
+	})
+}
+
+func TestEncoderHandler(t *testing.T) {
+	sinktest.Run(t, func(out io.Writer, _ bolt.ErrorHandler) sinktest.Sink {
+		return sinktest.Sink{Handler: bolt.NewEncoderHandler(out, bolt.LogfmtEncoder{})}
+	})
+}
+
+func TestECSHandler(t *testing.T) {
+	sinktest.Run(t, func(out io.Writer, _ bolt.ErrorHandler) sinktest.Sink {
+		return sinktest.Sink{Handler: bolt.NewECSHandler(out, nil)}
+	})
+}
@@ -0,0 +1,268 @@
+package bolt
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Defaults for [SLOOptions]. The windows and burn rate follow the common
+// "fast burn" page: 2% of a 30-day error budget spent within one hour.
+const (
+	DefaultSLOObjective   = 0.999
+	DefaultSLOLongWindow  = time.Hour
+	DefaultSLOShortWindow = 5 * time.Minute
+	DefaultSLOBurnRate    = 14.4
+	DefaultSLOMinEvents   = 50
+)
+
+// Messages of the events logged by [SLOHook].
+const (
+	SLOBreachMessage    = "slo burn rate exceeded"
+	SLORecoveredMessage = "slo burn rate recovered"
+)
+
+// sloBucketsPerShortWindow sets the resolution of the rolling windows.
+const sloBucketsPerShortWindow = 5
+
+// SLOOptions configures [NewSLOHook]. A nil *SLOOptions uses the
+// defaults.
+type SLOOptions struct {
+	// Name identifies the objective in the breach events. Defaults to
+	// "default".
+	Name string
+
+	// Objective is the target ratio of good events, such as 0.999.
+	// Defaults to DefaultSLOObjective.
+	Objective float64
+
+	// Match selects the events the objective is measured over, for
+	// example with [HasField]. Defaults to every event.
+	Match func(e *Event, msg string) bool
+
+	// Good reports whether a matched event is a success, for example
+	// FieldLessThan("status_code", 500). Defaults to events below ERROR.
+	Good func(e *Event, msg string) bool
+
+	// LongWindow and ShortWindow are the two rolling windows that must
+	// both burn faster than BurnRate for a breach; the short one makes
+	// the alert reset quickly once the problem is fixed. Default to
+	// DefaultSLOLongWindow and DefaultSLOShortWindow.
+	LongWindow  time.Duration
+	ShortWindow time.Duration
+
+	// BurnRate is the error-budget consumption speed, relative to the
+	// rate that would exactly exhaust it, that counts as a breach.
+	// Defaults to DefaultSLOBurnRate.
+	BurnRate float64
+
+	// MinEvents is the number of matched events the short window needs
+	// before it is evaluated, so a single failure on an idle service
+	// does not page. Defaults to DefaultSLOMinEvents.
+	MinEvents int
+
+	// OnBreach, if set, is called after a breach has been logged.
+	OnBreach func(SLOBreach)
+}
+
+// SLOBreach describes one burn-rate breach.
+type SLOBreach struct {
+	SLO           string        `json:"slo"`
+	Objective     float64       `json:"objective"`
+	BurnRate      float64       `json:"burn_rate"`
+	ShortBurnRate float64       `json:"short_burn_rate"`
+	Window        time.Duration `json:"window"`
+	Good          int           `json:"good"`
+	Total         int           `json:"total"`
+}
+
+// SLOHook is an [EventHook] that turns log events into a service level
+// objective signal for environments without a metrics stack. It keeps
+// rolling counts of good and total matched events and, when the error
+// budget burns faster than the configured rate over both the long and
+// the short window, logs a WARN event with "alert":"slo_burn_rate",
+// "slo", "objective", "burn_rate", "short_burn_rate", "window", "good"
+// and "total". Once the short window drops below the rate again it logs
+// an INFO recovery event, and a later breach alerts anew.
+//
+//	logger.AddEventHook(bolt.NewSLOHook(logger, &bolt.SLOOptions{
+//	    Name:      "api-availability",
+//	    Objective: 0.999,
+//	    Match:     bolt.HasField("status_code"),
+//	    Good:      bolt.FieldLessThan("status_code", 500),
+//	}))
+//
+// SLOHook never suppresses events, and its own events are not counted.
+type SLOHook struct {
+	logger *Logger
+	opts   SLOOptions
+	bucket time.Duration
+	short  int // buckets in the short window
+
+	mu       sync.Mutex
+	buckets  []sloBucket
+	breached bool
+}
+
+type sloBucket struct {
+	epoch       int64
+	good, total int
+}
+
+// NewSLOHook returns an SLOHook logging breaches to logger. If opts is
+// nil, defaults are used.
+func NewSLOHook(logger *Logger, opts *SLOOptions) *SLOHook {
+	var o SLOOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.Name == "" {
+		o.Name = "default"
+	}
+	if o.Objective <= 0 || o.Objective >= 1 {
+		o.Objective = DefaultSLOObjective
+	}
+	if o.Match == nil {
+		o.Match = func(*Event, string) bool { return true }
+	}
+	if o.Good == nil {
+		o.Good = func(e *Event, _ string) bool { return e.Level() < ERROR }
+	}
+	if o.ShortWindow <= 0 {
+		o.ShortWindow = DefaultSLOShortWindow
+	}
+	if o.LongWindow < o.ShortWindow {
+		o.LongWindow = max(DefaultSLOLongWindow, o.ShortWindow)
+	}
+	if o.BurnRate <= 0 {
+		o.BurnRate = DefaultSLOBurnRate
+	}
+	if o.MinEvents <= 0 {
+		o.MinEvents = DefaultSLOMinEvents
+	}
+	bucket := max(o.ShortWindow/sloBucketsPerShortWindow, time.Millisecond)
+	n := int((o.LongWindow + bucket - 1) / bucket)
+	return &SLOHook{
+		logger:  logger,
+		opts:    o,
+		bucket:  bucket,
+		short:   sloBucketsPerShortWindow,
+		buckets: make([]sloBucket, n),
+	}
+}
+
+// Run implements [EventHook].
+func (h *SLOHook) Run(e *Event, msg string) bool {
+	if msg == SLOBreachMessage || msg == SLORecoveredMessage || !h.opts.Match(e, msg) {
+		return true
+	}
+	good := h.opts.Good(e, msg)
+
+	h.mu.Lock()
+	epoch := time.Now().UnixNano() / int64(h.bucket)
+	b := &h.buckets[epoch%int64(len(h.buckets))]
+	if b.epoch != epoch {
+		*b = sloBucket{epoch: epoch}
+	}
+	b.total++
+	if good {
+		b.good++
+	}
+	shortGood, shortTotal := h.sum(epoch, h.short)
+	longGood, longTotal := h.sum(epoch, len(h.buckets))
+	shortRate := h.burnRate(shortGood, shortTotal)
+	longRate := h.burnRate(longGood, longTotal)
+
+	var breach *SLOBreach
+	recovered := false
+	switch {
+	case !h.breached && shortTotal >= h.opts.MinEvents &&
+		shortRate >= h.opts.BurnRate && longRate >= h.opts.BurnRate:
+		h.breached = true
+		breach = &SLOBreach{
+			SLO: h.opts.Name, Objective: h.opts.Objective,
+			BurnRate: longRate, ShortBurnRate: shortRate,
+			Window: h.opts.LongWindow, Good: longGood, Total: longTotal,
+		}
+	case h.breached && shortRate < h.opts.BurnRate:
+		h.breached = false
+		recovered = true
+	}
+	h.mu.Unlock()
+
+	// Logged outside the lock: the events pass through this hook too.
+	switch {
+	case breach != nil:
+		h.logger.Warn().
+			Str("alert", "slo_burn_rate").
+			Str("slo", breach.SLO).
+			Float64("objective", breach.Objective).
+			Float64("burn_rate", breach.BurnRate).
+			Float64("short_burn_rate", breach.ShortBurnRate).
+			Dur("window", breach.Window).
+			Int("good", breach.Good).
+			Int("total", breach.Total).
+			Msg(SLOBreachMessage)
+		if h.opts.OnBreach != nil {
+			h.opts.OnBreach(*breach)
+		}
+	case recovered:
+		h.logger.Info().
+			Str("alert", "slo_burn_rate").
+			Str("slo", h.opts.Name).
+			Float64("short_burn_rate", shortRate).
+			Msg(SLORecoveredMessage)
+	}
+	return true
+}
+
+// sum adds up the last n buckets ending at epoch. Called with h.mu held.
+func (h *SLOHook) sum(epoch int64, n int) (good, total int) {
+	for i := range int64(n) {
+		b := h.buckets[(epoch-i)%int64(len(h.buckets))]
+		if b.epoch == epoch-i {
+			good += b.good
+			total += b.total
+		}
+	}
+	return good, total
+}
+
+func (h *SLOHook) burnRate(good, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	bad := float64(total-good) / float64(total)
+	return bad / (1 - h.opts.Objective)
+}
+
+// HasField returns an [SLOOptions.Match] predicate selecting events that
+// carry a top-level field named key.
+func HasField(key string) func(e *Event, msg string) bool {
+	return func(e *Event, _ string) bool {
+		found := false
+		e.WalkFields(func(k, _ []byte) bool {
+			found = string(k) == key
+			return !found
+		})
+		return found
+	}
+}
+
+// FieldLessThan returns an [SLOOptions.Good] predicate reporting whether
+// the numeric top-level field key is below limit. Events without the
+// field, or with a non-numeric value, are not good.
+func FieldLessThan(key string, limit float64) func(e *Event, msg string) bool {
+	return func(e *Event, _ string) bool {
+		var value []byte
+		e.WalkFields(func(k, v []byte) bool {
+			if string(k) == key {
+				value = v
+				return false
+			}
+			return true
+		})
+		f, err := strconv.ParseFloat(string(value), 64)
+		return err == nil && f < limit
+	}
+}
@@ -0,0 +1,71 @@
+package bolt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSLOHook(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewJSONHandler(&buf))
+	var breaches []SLOBreach
+	logger.AddEventHook(NewSLOHook(logger, &SLOOptions{
+		Name:        "api",
+		Objective:   0.75,
+		Match:       HasField("status_code"),
+		Good:        FieldLessThan("status_code", 500),
+		ShortWindow: 50 * time.Millisecond,
+		LongWindow:  time.Second,
+		BurnRate:    2, // more than 50% failures
+		MinEvents:   10,
+		OnBreach:    func(b SLOBreach) { breaches = append(breaches, b) },
+	}))
+
+	logger.Error().Msg("unrelated") // not matched
+	for i := range 9 {
+		logger.Info().Int("status_code", 500+i).Msg("request")
+	}
+	if len(breaches) != 0 {
+		t.Fatal("breach before MinEvents")
+	}
+	logger.Info().Int("status_code", 200).Msg("request")
+	logger.Info().Int("status_code", 503).Msg("request") // still breached: no second alert
+	if len(breaches) != 1 {
+		t.Fatalf("got %d breaches, want 1:\n%s", len(breaches), buf.String())
+	}
+	b := breaches[0]
+	if b.SLO != "api" || b.Total != 10 || b.Good != 1 || b.BurnRate != 3.6 {
+		t.Errorf("breach = %+v", b)
+	}
+	if !strings.Contains(buf.String(), `"alert":"slo_burn_rate","slo":"api","objective":0.75,"burn_rate":3.6,`) {
+		t.Errorf("breach event missing:\n%s", buf.String())
+	}
+
+	time.Sleep(60 * time.Millisecond) // let the short window drain
+	buf.Reset()
+	logger.Info().Int("status_code", 200).Msg("request")
+	if !strings.Contains(buf.String(), SLORecoveredMessage) {
+		t.Errorf("no recovery event:\n%s", buf.String())
+	}
+}
+
+func TestSLOPredicates(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewJSONHandler(&buf))
+	var got []bool
+	logger.AddEventHook(EventHookFunc(func(e *Event, msg string) bool {
+		got = append(got, HasField("code")(e, msg), FieldLessThan("code", 500)(e, msg))
+		return true
+	}))
+	logger.Info().Int("code", 200).Msg("a")
+	logger.Info().Str("code", "oops").Msg("b")
+	logger.Info().Msg("c")
+	want := []bool{true, true, true, false, false, false}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("predicates = %v, want %v", got, want)
+		}
+	}
+}
@@ -288,7 +288,7 @@ func appendSlogValue(b []byte, v slog.Value) []byte {
 		b = appendJSONString(b, v.String())
 		b = append(b, '"')
 	case slog.KindInt64:
-		b = appendInt(b, int(v.Int64()))
+		b = appendInt64(b, v.Int64())
 	case slog.KindUint64:
 		b = appendUint(b, v.Uint64())
 	case slog.KindFloat64:
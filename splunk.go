@@ -0,0 +1,272 @@
+package bolt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultSplunkRetries is how many times a SplunkHandler retries a
+	// batch when none is configured.
+	DefaultSplunkRetries = 5
+	// DefaultSplunkBackoff is the delay before the first retry when none
+	// is configured. It doubles with every attempt.
+	DefaultSplunkBackoff = 500 * time.Millisecond
+	// DefaultSplunkMaxBackoff caps the retry delay when no cap is
+	// configured.
+	DefaultSplunkMaxBackoff = 30 * time.Second
+	// splunkTimeout bounds each request of the default client.
+	splunkTimeout = 10 * time.Second
+	// splunkEventPath is the HEC endpoint for JSON events.
+	splunkEventPath = "/services/collector/event"
+)
+
+// ErrSplunkRejected is returned, wrapped with the HTTP status and
+// response body, when the HTTP Event Collector rejects a batch with a
+// status that retrying cannot fix, such as a bad token (401, 403) or a
+// malformed request (400).
+var ErrSplunkRejected = errors.New("bolt: splunk rejected batch")
+
+// SplunkOptions configures [NewSplunkHandler]. A nil *SplunkOptions uses
+// the defaults.
+type SplunkOptions struct {
+	// Index, Source, SourceType and Host set the metadata of every
+	// event. Empty values are omitted, so the token's defaults apply.
+	Index, Source, SourceType, Host string
+
+	// Batch configures batching and backpressure; see [BatchOptions].
+	// Its ErrorHandler receives batches that could not be delivered.
+	Batch *BatchOptions
+
+	// MaxRetries is how many times a batch is retried after a network
+	// error, a 5xx or a 429 response. Defaults to DefaultSplunkRetries;
+	// negative disables retries.
+	MaxRetries int
+
+	// Backoff is the delay before the first retry, doubled for every
+	// further attempt with random jitter, up to MaxBackoff. Defaults to
+	// DefaultSplunkBackoff and DefaultSplunkMaxBackoff.
+	Backoff, MaxBackoff time.Duration
+
+	// TLS configures HTTPS for the default client. Ignored if Client is
+	// set.
+	TLS *TLSConfig
+
+	// Client sends the requests. Defaults to a client with a 10s timeout.
+	Client *http.Client
+}
+
+// SplunkStats are the delivery counters of a [SplunkHandler].
+type SplunkStats struct {
+	Sent    uint64 // events accepted by Splunk
+	Failed  uint64 // events in batches given up after retries or rejected
+	Dropped uint64 // events dropped because the batch queue was full
+	Retries uint64 // batch retries
+	Queued  int    // full batches waiting to be sent
+}
+
+// SplunkHandler sends events to a Splunk HTTP Event Collector. Events are
+// collected into batches by an embedded [BatchHandler] and each batch is
+// posted as one request, with every record wrapped in a HEC envelope:
+//
+//	{"time":1714564800.123,"event":{"level":"info",...},"index":"app","sourcetype":"_json"}
+//
+// The time comes from the record's "timestamp" or "time" field when it
+// holds an RFC 3339 time; otherwise Splunk uses the time of receipt.
+// Batches failing with a network error, a 5xx or a 429 response are
+// retried with exponential backoff; other failures return
+// ErrSplunkRejected to the batch error handler. Retries block later
+// batches, which queue and are then dropped or block the logger as
+// [BatchOptions] configures. Close flushes pending events and waits for
+// their delivery.
+type SplunkHandler struct {
+	*BatchHandler
+	sender *splunkSender
+}
+
+// NewSplunkHandler returns a handler sending to the HTTP Event Collector
+// at rawURL, such as "https://splunk.example.com:8088", with the HEC
+// token sent as "Authorization: Splunk <token>". The event endpoint path
+// is added unless the URL has a path already.
+func NewSplunkHandler(rawURL, token string, opts *SplunkOptions) (*SplunkHandler, error) {
+	if opts == nil {
+		opts = &SplunkOptions{}
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("bolt: splunk url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("bolt: splunk url %q: scheme must be http or https", rawURL)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = splunkEventPath
+	}
+	s := &splunkSender{
+		url:        u.String(),
+		auth:       "Splunk " + token,
+		maxRetries: opts.MaxRetries,
+		backoff:    opts.Backoff,
+		maxBackoff: opts.MaxBackoff,
+		client:     opts.Client,
+	}
+	if s.maxRetries == 0 {
+		s.maxRetries = DefaultSplunkRetries
+	}
+	if s.backoff <= 0 {
+		s.backoff = DefaultSplunkBackoff
+	}
+	if s.maxBackoff <= 0 {
+		s.maxBackoff = DefaultSplunkMaxBackoff
+	}
+	if s.client == nil {
+		cfg, err := opts.TLS.Build()
+		if err != nil {
+			return nil, err
+		}
+		s.client = &http.Client{
+			Timeout:   splunkTimeout,
+			Transport: &http.Transport{TLSClientConfig: cfg, Proxy: http.ProxyFromEnvironment},
+		}
+	}
+	for _, m := range []struct{ key, value string }{
+		{"index", opts.Index}, {"source", opts.Source}, {"sourcetype", opts.SourceType}, {"host", opts.Host},
+	} {
+		if m.value != "" {
+			s.meta = append(s.meta, ",\""+m.key+"\":\""...)
+			s.meta = appendJSONString(s.meta, m.value)
+			s.meta = append(s.meta, '"')
+		}
+	}
+	return &SplunkHandler{BatchHandler: NewBatchHandler(s, opts.Batch), sender: s}, nil
+}
+
+// Stats returns the handler's delivery counters.
+func (h *SplunkHandler) Stats() SplunkStats {
+	return SplunkStats{
+		Sent:    h.sender.sent.Load(),
+		Failed:  h.sender.failed.Load(),
+		Dropped: h.Dropped(),
+		Retries: h.sender.retried.Load(),
+		Queued:  h.Queued(),
+	}
+}
+
+// splunkSender is the handler behind the batcher. It only runs on the
+// batch goroutine, so its buffers need no locking.
+type splunkSender struct {
+	url        string
+	auth       string
+	meta       []byte // pre-encoded metadata fields
+	maxRetries int
+	backoff    time.Duration
+	maxBackoff time.Duration
+	client     *http.Client
+
+	body  []byte
+	spans []FieldSpan
+
+	sent, failed, retried atomic.Uint64
+}
+
+// Write posts a batch of newline-separated records.
+func (s *splunkSender) Write(e *Event) error {
+	n := 0
+	s.body = s.body[:0]
+	for rest := e.buf; len(rest) > 0; {
+		line := rest
+		if i := bytes.IndexByte(rest, '\n'); i >= 0 {
+			line, rest = rest[:i], rest[i+1:]
+		} else {
+			rest = nil
+		}
+		if len(line) == 0 {
+			continue
+		}
+		s.body = s.appendEnvelope(s.body, line)
+		n++
+	}
+	if n == 0 {
+		return nil
+	}
+
+	for attempt := 0; ; attempt++ {
+		retry, err := s.post()
+		if err == nil {
+			s.sent.Add(uint64(n))
+			return nil
+		}
+		if !retry || attempt >= s.maxRetries {
+			s.failed.Add(uint64(n))
+			return fmt.Errorf("splunk: %d events not delivered: %w", n, err)
+		}
+		s.retried.Add(1)
+		time.Sleep(backoffDelay(s.backoff, s.maxBackoff, attempt))
+	}
+}
+
+// appendEnvelope appends record wrapped in a HEC event envelope.
+func (s *splunkSender) appendEnvelope(dst, record []byte) []byte {
+	dst = append(dst, '{')
+	if t, ok := s.recordTime(record); ok {
+		dst = append(dst, `"time":`...)
+		ms := t.UnixMilli()
+		dst = strconv.AppendInt(dst, ms/1000, 10)
+		dst = append(dst, '.', byte('0'+ms%1000/100), byte('0'+ms%100/10), byte('0'+ms%10), ',')
+	}
+	dst = append(dst, `"event":`...)
+	dst = append(dst, record...)
+	dst = append(dst, s.meta...)
+	return append(dst, '}')
+}
+
+// recordTime returns the time in the record's "timestamp" or "time"
+// field.
+func (s *splunkSender) recordTime(record []byte) (time.Time, bool) {
+	s.spans = appendFieldSpans(s.spans[:0], record, 1)
+	for _, sp := range s.spans {
+		switch string(sp.Key(record)) {
+		case "timestamp", "time":
+			v := sp.Value(record)
+			if len(v) < 2 || v[0] != '"' {
+				continue
+			}
+			if t, err := time.Parse(time.RFC3339Nano, string(v[1:len(v)-1])); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// post sends the body once and reports whether a failure is worth
+// retrying.
+func (s *splunkSender) post() (retry bool, err error) {
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(s.body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Authorization", s.auth)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		_, _ = io.Copy(io.Discard, resp.Body) // reuse the connection
+		return false, nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		return true, fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return false, fmt.Errorf("%w: %s: %s", ErrSplunkRejected, resp.Status, bytes.TrimSpace(msg))
+}
@@ -0,0 +1,124 @@
+package bolt
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// hecServer records request bodies and answers with the queued status
+// codes, then 200.
+type hecServer struct {
+	mu       sync.Mutex
+	bodies   []string
+	auth     []string
+	statuses []int
+}
+
+func (s *hecServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.auth = append(s.auth, r.Header.Get("Authorization"))
+	if r.URL.Path != "/services/collector/event" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if len(s.statuses) > 0 {
+		status := s.statuses[0]
+		s.statuses = s.statuses[1:]
+		w.WriteHeader(status)
+		io.WriteString(w, `{"text":"try later","code":9}`)
+		return
+	}
+	s.bodies = append(s.bodies, string(body))
+	io.WriteString(w, `{"text":"Success","code":0}`)
+}
+
+func TestSplunkHandler(t *testing.T) {
+	srv := &hecServer{}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	h, err := NewSplunkHandler(ts.URL, "secret", &SplunkOptions{
+		Index:      "app",
+		SourceType: "_json",
+		Batch:      &BatchOptions{MaxDelay: time.Hour},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := New(h)
+	logger.Info().Str("timestamp", "2024-05-01T12:00:00.123456Z").Msg("one")
+	logger.Warn().Msg("two")
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"time":1714564800.123,"event":{"level":"info","timestamp":"2024-05-01T12:00:00.123456Z","message":"one"},"index":"app","sourcetype":"_json"}` +
+		`{"event":{"level":"warn","message":"two"},"index":"app","sourcetype":"_json"}`
+	if len(srv.bodies) != 1 || srv.bodies[0] != want {
+		t.Errorf("bodies = %q\nwant one batch %q", srv.bodies, want)
+	}
+	if srv.auth[0] != "Splunk secret" {
+		t.Errorf("Authorization = %q", srv.auth[0])
+	}
+	if st := h.Stats(); st.Sent != 2 || st.Failed != 0 || st.Retries != 0 {
+		t.Errorf("Stats() = %+v", st)
+	}
+}
+
+func TestSplunkHandlerRetries(t *testing.T) {
+	srv := &hecServer{statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	h, err := NewSplunkHandler(ts.URL+"/", "secret", &SplunkOptions{Backoff: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	New(h).Info().Msg("eventually")
+	h.Close()
+
+	if len(srv.bodies) != 1 || !strings.Contains(srv.bodies[0], `"message":"eventually"`) {
+		t.Errorf("bodies = %q, want the event delivered on the third attempt", srv.bodies)
+	}
+	if st := h.Stats(); st.Sent != 1 || st.Retries != 2 {
+		t.Errorf("Stats() = %+v, want 1 sent after 2 retries", st)
+	}
+}
+
+func TestSplunkHandlerRejected(t *testing.T) {
+	srv := &hecServer{statuses: []int{http.StatusForbidden}}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	var errs []error
+	h, err := NewSplunkHandler(ts.URL, "bad", &SplunkOptions{
+		Backoff: time.Millisecond,
+		Batch:   &BatchOptions{ErrorHandler: func(err error) { errs = append(errs, err) }},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	New(h).Info().Msg("lost")
+	h.Close()
+
+	if len(errs) != 1 || !errors.Is(errs[0], ErrSplunkRejected) {
+		t.Errorf("errors = %v, want ErrSplunkRejected without retrying", errs)
+	}
+	if st := h.Stats(); st.Failed != 1 || st.Retries != 0 {
+		t.Errorf("Stats() = %+v, want 1 failed and no retries", st)
+	}
+}
+
+func TestNewSplunkHandlerURL(t *testing.T) {
+	if _, err := NewSplunkHandler("splunk:8088", "t", nil); err == nil {
+		t.Error("accepted a URL without http or https scheme")
+	}
+}
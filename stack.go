@@ -0,0 +1,143 @@
+package bolt
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+)
+
+// DefaultStackFrames is the number of frames [Event.Stack] keeps when no
+// limit is configured.
+const DefaultStackFrames = 32
+
+// DefaultStackSkip lists the frames dropped by [Event.Stack] when no skip
+// patterns are configured: the Go runtime and vendored dependencies.
+var DefaultStackSkip = []string{"runtime.", "/vendor/"}
+
+// StackOptions controls how [Event.Stack] renders the calling goroutine's
+// stack. A nil *StackOptions uses the defaults.
+type StackOptions struct {
+	// MaxFrames caps the number of frames kept after skipping. Defaults
+	// to DefaultStackFrames; a negative value keeps every frame.
+	MaxFrames int
+
+	// Skip drops frames whose function name starts with, or whose file
+	// path contains, one of the patterns. Defaults to DefaultStackSkip;
+	// set an empty non-nil slice to keep every frame.
+	Skip []string
+
+	// TrimPrefixes are removed from the start of file paths, typically
+	// the module or GOPATH root, so frames read "internal/api/user.go:42"
+	// instead of an absolute build path.
+	TrimPrefixes []string
+
+	// Args keeps the raw argument words the runtime prints for each call
+	// ("main.f(0xc000012345, 0x2)"). Off by default: they are rarely
+	// useful in logs, add bytes to every frame and can leak pointer values.
+	Args bool
+}
+
+// SetStackOptions configures the stack traces captured by [Event.Stack].
+// Pass nil to restore the defaults. Like SetKeyReplacer, it is intended
+// for setup-time configuration and is inherited by child loggers.
+func (l *Logger) SetStackOptions(opts *StackOptions) *Logger {
+	l.stackOpts = opts
+	return l
+}
+
+// stackFramesSkipped is the number of bolt frames at the top of the
+// runtime.Stack output: captureStack and Event.Stack.
+const stackFramesSkipped = 2
+
+// captureStack returns the current goroutine's stack rendered per opts,
+// one "function\n\tfile:line" pair per frame.
+func captureStack(opts *StackOptions) string {
+	if opts == nil {
+		opts = &StackOptions{}
+	}
+	maxFrames := opts.MaxFrames
+	if maxFrames == 0 {
+		maxFrames = DefaultStackFrames
+	}
+	skip := opts.Skip
+	if skip == nil {
+		skip = DefaultStackSkip
+	}
+
+	// Grow the buffer only as far as needed, up to StackTraceBufferSize.
+	var raw []byte
+	for size := 4096; ; size *= 2 {
+		raw = make([]byte, size)
+		n := runtime.Stack(raw, false)
+		if n < size || size >= StackTraceBufferSize {
+			raw = raw[:n]
+			break
+		}
+	}
+
+	// Drop the "goroutine N [running]:" header.
+	if i := bytes.IndexByte(raw, '\n'); i >= 0 {
+		raw = raw[i+1:]
+	}
+
+	var b strings.Builder
+	frames := 0
+	for idx := 0; len(raw) > 0 && (maxFrames < 0 || frames < maxFrames); idx++ {
+		var fn, loc []byte
+		fn, raw = cutLine(raw)
+		loc, raw = cutLine(raw)
+		if len(fn) == 0 {
+			break
+		}
+		if idx < stackFramesSkipped {
+			continue
+		}
+		file := bytes.TrimPrefix(loc, []byte{'\t'})
+		if i := bytes.LastIndex(file, []byte(" +0x")); i >= 0 {
+			file = file[:i]
+		}
+		name := fn
+		if i := bytes.LastIndexByte(fn, '('); i > 0 && fn[len(fn)-1] == ')' {
+			name = fn[:i]
+		}
+		if skipFrame(string(name), string(file), skip) {
+			continue
+		}
+		for _, p := range opts.TrimPrefixes {
+			if bytes.HasPrefix(file, []byte(p)) {
+				file = file[len(p):]
+				break
+			}
+		}
+
+		if frames > 0 {
+			b.WriteByte('\n')
+		}
+		if opts.Args {
+			b.Write(fn)
+		} else {
+			b.Write(name)
+		}
+		b.WriteString("\n\t")
+		b.Write(file)
+		frames++
+	}
+	return b.String()
+}
+
+func skipFrame(name, file string, patterns []string) bool {
+	name = strings.TrimPrefix(name, "created by ")
+	for _, p := range patterns {
+		if strings.HasPrefix(name, p) || strings.Contains(file, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func cutLine(b []byte) (line, rest []byte) {
+	if i := bytes.IndexByte(b, '\n'); i >= 0 {
+		return b[:i], b[i+1:]
+	}
+	return b, nil
+}
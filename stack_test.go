@@ -0,0 +1,79 @@
+package bolt
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func stackField(t *testing.T, logger *Logger, buf *bytes.Buffer) string {
+	t.Helper()
+	buf.Reset()
+	logger.Error().Stack().Msg("boom")
+	var rec struct{ Stack string }
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf, err)
+	}
+	return rec.Stack
+}
+
+func TestStackDefaults(t *testing.T) {
+	var buf bytes.Buffer
+	stack := stackField(t, New(NewJSONHandler(&buf)), &buf)
+
+	first, _, _ := strings.Cut(stack, "\n")
+	if first != "go.klarlabs.de/bolt.stackField" {
+		t.Errorf("first frame = %q; want the caller of Stack", first)
+	}
+	for _, unwanted := range []string{"[running]", "runtime.", " +0x", "(0x", "captureStack"} {
+		if strings.Contains(stack, unwanted) {
+			t.Errorf("stack contains %q:\n%s", unwanted, stack)
+		}
+	}
+	if !strings.Contains(stack, "\n\t") || !strings.Contains(stack, "stack_test.go:") {
+		t.Errorf("frames lack file:line:\n%s", stack)
+	}
+}
+
+func TestStackOptions(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewJSONHandler(&buf))
+
+	logger.SetStackOptions(&StackOptions{MaxFrames: 1})
+	if stack := stackField(t, logger, &buf); strings.Count(stack, "\n\t") != 1 {
+		t.Errorf("MaxFrames=1 kept:\n%s", stack)
+	}
+
+	logger.SetStackOptions(&StackOptions{Skip: []string{"go.klarlabs.de/bolt.stackField"}})
+	if stack := stackField(t, logger, &buf); !strings.HasPrefix(stack, "go.klarlabs.de/bolt.TestStackOptions") {
+		t.Errorf("Skip did not drop the first frame:\n%s", stack)
+	}
+
+	logger.SetStackOptions(&StackOptions{MaxFrames: -1, Skip: []string{"testing."}})
+	if stack := stackField(t, logger, &buf); strings.Contains(stack, "testing.") {
+		t.Errorf("Skip kept testing frames:\n%s", stack)
+	}
+
+	logger.SetStackOptions(&StackOptions{MaxFrames: 1, Args: true})
+	if stack := stackField(t, logger, &buf); !strings.Contains(stack, "stackField(0x") {
+		t.Errorf("Args did not keep arguments:\n%s", stack)
+	}
+
+	logger.SetStackOptions(&StackOptions{MaxFrames: 1})
+	full := stackField(t, logger, &buf)
+	_, file, _ := strings.Cut(full, "\n\t")
+	dir := file[:strings.LastIndexByte(file, '/')+1]
+	child := logger.With().Logger().SetStackOptions(&StackOptions{MaxFrames: 1, TrimPrefixes: []string{dir}})
+	if stack := stackField(t, child, &buf); !strings.HasSuffix(stack, "\n\tstack_test.go:13") {
+		t.Errorf("TrimPrefixes did not trim %q:\n%s", dir, stack)
+	}
+}
+
+func TestStackOptionsInherited(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewJSONHandler(&buf)).SetStackOptions(&StackOptions{MaxFrames: 1})
+	if stack := stackField(t, logger.With().Str("k", "v").Logger(), &buf); strings.Count(stack, "\n\t") != 1 {
+		t.Errorf("child logger did not inherit StackOptions:\n%s", stack)
+	}
+}
@@ -0,0 +1,79 @@
+package bolt
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+)
+
+// LogStartup writes a single standardized INFO event describing the
+// process at startup, so that incident reviews can tell exactly which
+// build was running and how it was configured.
+//
+// The event carries four objects:
+//   - "logging": the effective logger configuration (level, handler type,
+//     hook counts)
+//   - "runtime": Go version, GOOS/GOARCH, CPU counts, PID, hostname and
+//     the cgroup limits from [DetectContainerLimits] when containerized
+//   - "build": module path and version plus the VCS revision, commit
+//     time and dirty flag recorded by the Go toolchain
+//   - "config": cfg encoded with [Event.Any], omitted when cfg is nil
+//
+// If info is nil, [debug.ReadBuildInfo] is used. cfg is logged verbatim;
+// tag secret fields with `json:"-"` or pass a redacted copy.
+func LogStartup(logger *Logger, cfg any, info *debug.BuildInfo) {
+	if info == nil {
+		info, _ = debug.ReadBuildInfo()
+	}
+	e := logger.Info().
+		Dict("logging", func(d *Event) {
+			d.Str("level", logger.GetLevel().String()).
+				Str("handler", fmt.Sprintf("%T", logger.handler.load())).
+				Int("hooks", len(logger.hooks)).
+				Int("event_hooks", len(logger.eventHooks))
+		}).
+		Dict("runtime", func(d *Event) {
+			d.Str("go_version", runtime.Version()).
+				Str("goos", runtime.GOOS).
+				Str("goarch", runtime.GOARCH).
+				Int("num_cpu", runtime.NumCPU()).
+				Int("gomaxprocs", runtime.GOMAXPROCS(0)).
+				Int("pid", os.Getpid())
+			DetectContainerLimits().appendFields(d)
+			if host, err := os.Hostname(); err == nil {
+				d.Str("hostname", host)
+			}
+		})
+	if info != nil {
+		e = e.Dict("build", func(d *Event) { appendBuildInfo(d, info) })
+	}
+	if cfg != nil {
+		e = e.Any("config", cfg)
+	}
+	e.Msg("startup")
+}
+
+// appendBuildInfo adds the main module and VCS stamping to d. VCS settings
+// are only present for binaries built from a checkout with -buildvcs
+// enabled (the default for go build).
+func appendBuildInfo(d *Event, info *debug.BuildInfo) {
+	if info.Main.Path != "" {
+		d.Str("path", info.Main.Path)
+	}
+	if info.Main.Version != "" {
+		d.Str("version", info.Main.Version)
+	}
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs":
+			d.Str("vcs", s.Value)
+		case "vcs.revision":
+			d.Str("vcs_revision", s.Value)
+		case "vcs.time":
+			d.Str("vcs_time", s.Value)
+		case "vcs.modified":
+			d.Bool("vcs_modified", s.Value == "true")
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package bolt
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"runtime/debug"
+	"testing"
+)
+
+func TestLogStartup(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewJSONHandler(&buf)).SetLevel(INFO).AddHook(NewSampleHook(1))
+
+	info := &debug.BuildInfo{
+		Main: debug.Module{Path: "example.com/svc", Version: "v1.2.3"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs", Value: "git"},
+			{Key: "vcs.revision", Value: "abc123"},
+			{Key: "vcs.modified", Value: "true"},
+			{Key: "-trimpath", Value: "true"},
+		},
+	}
+	cfg := struct {
+		Port   int    `json:"port"`
+		Secret string `json:"-"`
+	}{Port: 8080, Secret: "hunter2"}
+
+	LogStartup(logger, cfg, info)
+
+	var got struct {
+		Message string `json:"message"`
+		Logging struct {
+			Level   string `json:"level"`
+			Handler string `json:"handler"`
+			Hooks   int    `json:"hooks"`
+		} `json:"logging"`
+		Runtime struct {
+			GoVersion string `json:"go_version"`
+		} `json:"runtime"`
+		Build struct {
+			Path     string `json:"path"`
+			Version  string `json:"version"`
+			Revision string `json:"vcs_revision"`
+			Modified bool   `json:"vcs_modified"`
+		} `json:"build"`
+		Config map[string]any `json:"config"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+
+	if got.Message != "startup" {
+		t.Errorf("message = %q", got.Message)
+	}
+	if got.Logging.Level != "info" || got.Logging.Handler != "*bolt.JSONHandler" || got.Logging.Hooks != 1 {
+		t.Errorf("logging = %+v", got.Logging)
+	}
+	if got.Runtime.GoVersion != runtime.Version() {
+		t.Errorf("go_version = %q", got.Runtime.GoVersion)
+	}
+	if got.Build.Path != "example.com/svc" || got.Build.Version != "v1.2.3" || got.Build.Revision != "abc123" || !got.Build.Modified {
+		t.Errorf("build = %+v", got.Build)
+	}
+	if got.Config["port"] != float64(8080) || len(got.Config) != 1 {
+		t.Errorf("config = %v", got.Config)
+	}
+}
+
+func TestLogStartup_DefaultBuildInfo(t *testing.T) {
+	var buf bytes.Buffer
+	LogStartup(New(NewJSONHandler(&buf)), nil, nil)
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	if _, ok := got["config"]; ok {
+		t.Error("nil config should be omitted")
+	}
+	if _, ok := got["build"]; !ok {
+		t.Error("build info from debug.ReadBuildInfo missing")
+	}
+}
+
+func TestGetLevel(t *testing.T) {
+	logger := New(NewJSONHandler(&bytes.Buffer{}))
+	if got := logger.GetLevel(); got != TRACE {
+		t.Errorf("default level = %v; want trace", got)
+	}
+	if got := logger.SetLevel(ERROR).GetLevel(); got != ERROR {
+		t.Errorf("GetLevel after SetLevel(ERROR) = %v", got)
+	}
+}
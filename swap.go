@@ -0,0 +1,137 @@
+package bolt
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// handlerSlot is a handler installed in a logger tree together with the
+// number of writes to it in progress, counted per P so that concurrent
+// writes do not contend on one counter.
+type handlerSlot struct {
+	h      Handler
+	active []activeStripe
+}
+
+// activeStripe counts the writes in progress started on one P. It fills
+// a cache line so that neighbouring stripes do not share one.
+type activeStripe struct {
+	n atomic.Int64
+	_ [56]byte
+}
+
+func newHandlerSlot(h Handler) *handlerSlot {
+	return &handlerSlot{h: h, active: make([]activeStripe, runtime.GOMAXPROCS(0))}
+}
+
+// handlerRef is the handler shared by a logger created with [New] and
+// every logger derived from it, so that [Logger.SwapHandler] reaches
+// them all.
+type handlerRef struct {
+	p atomic.Pointer[handlerSlot]
+}
+
+func newHandlerRef(h Handler) *handlerRef {
+	r := &handlerRef{}
+	r.p.Store(newHandlerSlot(h))
+	return r
+}
+
+// load returns the current handler.
+func (r *handlerRef) load() Handler {
+	return r.p.Load().h
+}
+
+// acquire returns the current slot with a write registered on it, and
+// the stripe it is counted on. The caller must pass the stripe to
+// release when the write is done; the goroutine may have moved to
+// another P by then.
+func (r *handlerRef) acquire() (*handlerSlot, int) {
+	pid := procPin()
+	procUnpin()
+	for {
+		s := r.p.Load()
+		i := pid % len(s.active) // GOMAXPROCS may have grown since s was made
+		s.active[i].n.Add(1)
+		if r.p.Load() == s {
+			return s, i
+		}
+		s.active[i].n.Add(-1) // swapped meanwhile; the new handler takes the write
+	}
+}
+
+func (s *handlerSlot) release(stripe int) {
+	s.active[stripe].n.Add(-1)
+}
+
+// idle reports whether no write is in progress on s.
+func (s *handlerSlot) idle() bool {
+	for i := range s.active {
+		if s.active[i].n.Load() != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// swapDrainPoll is how often SwapHandler checks for writes still in
+// progress on the old handler.
+const swapDrainPoll = 100 * time.Microsecond
+
+// DefaultSwapDrainTimeout bounds how long [Logger.SwapHandler] waits for
+// writes in progress on the old handler.
+const DefaultSwapDrainTimeout = 5 * time.Second
+
+// SwapHandler replaces the handler at runtime, for example to move from
+// a file to a network sink during a change window, and returns the old
+// one. The swap applies to the logger created by [New] and every logger
+// derived from it (With, Ctx, Hook, ...), which share one handler.
+//
+// The swap is atomic: every event is written whole to either the old or
+// the new handler. SwapHandler then waits up to [DefaultSwapDrainTimeout]
+// for writes still in progress on the old handler and, if it has a Close
+// method, as [AsyncHandler] does, closes it so queued events are flushed;
+// a Close error goes to the error handler. If the writes do not finish in
+// time the old handler is left open and the timeout goes to the error
+// handler. Writers wrapped by the old handler, such as a file under a
+// JSONHandler, are left open for the caller to close once SwapHandler
+// returns.
+//
+// SwapHandler is safe to call from an [ErrorHandler], for example to
+// fail over after a write error.
+func (l *Logger) SwapHandler(h Handler) Handler {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultSwapDrainTimeout)
+	defer cancel()
+	old, err := l.SwapHandlerContext(ctx, h)
+	if err != nil && l.errorHandler != nil {
+		l.errorHandler(err)
+	}
+	return old
+}
+
+// SwapHandlerContext is [Logger.SwapHandler] with the wait for writes in
+// progress on the old handler bounded by ctx instead of
+// [DefaultSwapDrainTimeout]. The new handler is installed either way; if
+// ctx ends first the old handler is returned unclosed, since a write may
+// still be using it, together with the context error. A Close error is
+// returned rather than sent to the error handler.
+func (l *Logger) SwapHandlerContext(ctx context.Context, h Handler) (Handler, error) {
+	old := l.handler.p.Swap(newHandlerSlot(h))
+	l.markDrift(1)
+	for !old.idle() {
+		select {
+		case <-ctx.Done():
+			return old.h, fmt.Errorf("draining swapped-out handler: %w", ctx.Err())
+		case <-time.After(swapDrainPoll):
+		}
+	}
+	if c, ok := old.h.(interface{ Close() error }); ok {
+		if err := c.Close(); err != nil {
+			return old.h, fmt.Errorf("closing swapped-out handler: %w", err)
+		}
+	}
+	return old.h, nil
+}
@@ -0,0 +1,168 @@
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// closingHandler records whether it was closed and whether a write
+// arrived after Close.
+type closingHandler struct {
+	*JSONHandler
+	closed    atomic.Bool
+	lateWrite atomic.Bool
+	delay     time.Duration
+	closeErr  error
+}
+
+func (h *closingHandler) Write(e *Event) error {
+	if h.closed.Load() {
+		h.lateWrite.Store(true)
+	}
+	time.Sleep(h.delay)
+	return h.JSONHandler.Write(e)
+}
+
+func (h *closingHandler) Close() error {
+	h.closed.Store(true)
+	return h.closeErr
+}
+
+func TestSwapHandler(t *testing.T) {
+	var oldBuf, newBuf ThreadSafeBuffer
+	oldH := &closingHandler{JSONHandler: NewJSONHandler(&oldBuf), delay: time.Millisecond}
+	root := New(oldH)
+	child := root.With().Str("component", "db").Logger()
+
+	var wg sync.WaitGroup
+	var stop atomic.Bool
+	var logged atomic.Int64
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for !stop.Load() {
+				child.Info().Msg("tick")
+				logged.Add(1)
+			}
+		}()
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	if got := root.SwapHandler(NewJSONHandler(&newBuf)); got != Handler(oldH) {
+		t.Errorf("SwapHandler returned %T, want the old handler", got)
+	}
+	if !oldH.closed.Load() {
+		t.Error("old handler not closed")
+	}
+	time.Sleep(5 * time.Millisecond)
+	stop.Store(true)
+	wg.Wait()
+
+	if oldH.lateWrite.Load() {
+		t.Error("old handler written to after Close")
+	}
+	child.Info().Msg("after")
+	if !strings.HasSuffix(newBuf.String(), `{"level":"info","component":"db","message":"after"}`+"\n") {
+		t.Errorf("derived logger did not follow the swap: %q", newBuf.String())
+	}
+	total := strings.Count(oldBuf.String(), "\n") + strings.Count(newBuf.String(), "\n")
+	if int64(total) != logged.Load()+1 {
+		t.Errorf("%d records written, %d logged", total, logged.Load()+1)
+	}
+}
+
+func TestSwapHandlerCloseError(t *testing.T) {
+	var buf bytes.Buffer
+	var got error
+	logger := New(&closingHandler{JSONHandler: NewJSONHandler(&buf), closeErr: errors.New("flush failed")}).
+		SetErrorHandler(func(err error) { got = err })
+
+	logger.SwapHandler(NewJSONHandler(&buf))
+	if got == nil || !strings.Contains(got.Error(), "flush failed") {
+		t.Errorf("error handler got %v", got)
+	}
+}
+
+func TestSwapHandlerFromErrorHandler(t *testing.T) {
+	var buf ThreadSafeBuffer
+	var logger *Logger
+	logger = New(&failingHandler{err: errors.New("sink down")}).SetErrorHandler(func(error) {
+		logger.SwapHandler(NewJSONHandler(&buf))
+	})
+
+	done := make(chan struct{})
+	go func() {
+		logger.Info().Msg("first")
+		logger.Info().Msg("second")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("SwapHandler from the error handler deadlocked")
+	}
+	if got := buf.String(); got != `{"level":"info","message":"second"}`+"\n" {
+		t.Errorf("fallback got %q", got)
+	}
+}
+
+// blockingHandler blocks every write until release is closed.
+type blockingHandler struct {
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (h *blockingHandler) Write(*Event) error {
+	h.entered <- struct{}{}
+	<-h.release
+	return nil
+}
+
+func TestSwapHandlerContextTimeout(t *testing.T) {
+	stuck := &blockingHandler{entered: make(chan struct{}), release: make(chan struct{})}
+	defer close(stuck.release)
+	logger := New(stuck)
+	go logger.Info().Msg("stuck")
+	<-stuck.entered
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	old, err := logger.SwapHandlerContext(ctx, NewJSONHandler(io.Discard))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want deadline exceeded", err)
+	}
+	if old != Handler(stuck) {
+		t.Errorf("SwapHandlerContext returned %T, want the old handler", old)
+	}
+}
+
+// BenchmarkHandlerAcquire measures the write registration every event
+// pays so that SwapHandler can drain the old handler. Writes are counted
+// per P, so the parallel case should match the serial one:
+//
+//	go test -run '^$' -bench HandlerAcquire -cpu 1,4,16
+func BenchmarkHandlerAcquire(b *testing.B) {
+	r := newHandlerRef(NewJSONHandler(io.Discard))
+	b.Run("Serial", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			s, stripe := r.acquire()
+			s.release(stripe)
+		}
+	})
+	b.Run("Parallel", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				s, stripe := r.acquire()
+				s.release(stripe)
+			}
+		})
+	})
+}
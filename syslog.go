@@ -0,0 +1,537 @@
+package bolt
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SyslogFormat selects the syslog message format written by a
+// [SyslogHandler].
+type SyslogFormat int
+
+const (
+	// RFC5424 is the structured syslog format. Event fields are sent as
+	// structured data and the message as MSG.
+	RFC5424 SyslogFormat = iota
+	// RFC3164 is the BSD syslog format still expected by many relays.
+	// It has no structured data, so MSG carries the whole JSON record.
+	RFC3164
+)
+
+const (
+	// DefaultSyslogFacility is the facility used when none is configured:
+	// user-level messages (1).
+	DefaultSyslogFacility = 1
+	// DefaultSyslogSDID is the SD-ID under which an RFC 5424 message
+	// carries the event's fields. 32473 is the private enterprise number
+	// reserved for documentation; register your own for production
+	// collectors that filter on it.
+	DefaultSyslogSDID = "bolt@32473"
+	// DefaultSyslogBuffer is how many bytes of messages a SyslogHandler
+	// holds while the server is unreachable when no size is configured.
+	DefaultSyslogBuffer = 1 << 20 // 1 MiB
+	// DefaultSyslogRetry is the minimum time between reconnection
+	// attempts when no delay is configured.
+	DefaultSyslogRetry = time.Second
+	// syslogDialTimeout bounds each connection attempt.
+	syslogDialTimeout = 5 * time.Second
+)
+
+// SyslogOptions configures [NewSyslogHandler]. A nil *SyslogOptions
+// writes RFC 5424 messages to the local syslog daemon.
+type SyslogOptions struct {
+	// Network is "udp", "tcp", "unix" or "unixgram", or empty for the
+	// local daemon's socket (/dev/log, /var/run/syslog or /var/run/log).
+	Network string
+
+	// Addr is the server address, or the socket path for unix networks.
+	Addr string
+
+	// TLS, if set with a "tcp" network, encrypts the connection as
+	// RFC 5425 describes.
+	TLS *TLSConfig
+
+	// Dial, if set, opens connections to Network and Addr in place of a
+	// net.Dialer, for proxies or in-process transports; TLS is negotiated
+	// over the connection it returns. It is not used for the local daemon.
+	Dial func(network, addr string) (net.Conn, error)
+
+	// Format selects RFC5424 (the default) or RFC3164.
+	Format SyslogFormat
+
+	// Facility is the syslog facility code, 1 through 23. Defaults to
+	// DefaultSyslogFacility; use 16 through 23 for local0..local7.
+	Facility int
+
+	// Severities maps levels to syslog severities with its Syslog map.
+	// Defaults to DefaultSeverities.
+	Severities *Severities
+
+	// AppName identifies the program. Defaults to the executable name.
+	AppName string
+
+	// Hostname is reported in every message. Defaults to os.Hostname.
+	Hostname string
+
+	// SDID is the RFC 5424 structured-data ID for event fields. Defaults
+	// to DefaultSyslogSDID.
+	SDID string
+
+	// BufferSize is how many bytes of messages are kept while the server
+	// is unreachable. The oldest are dropped beyond it. Defaults to
+	// DefaultSyslogBuffer.
+	BufferSize int
+
+	// RetryDelay is the minimum time between reconnection attempts.
+	// Defaults to DefaultSyslogRetry.
+	RetryDelay time.Duration
+}
+
+// SyslogHandler writes events to a syslog server or the local daemon.
+// Levels map to severities through [Severities.Syslog]. In the default
+// RFC 5424 format, fields other than level and message are sent as
+// structured data under one SD-ID:
+//
+//	<14>1 2024-05-01T12:00:00.000000Z web-1 api 4242 - [bolt@32473 user_id="42"] login
+//
+// Messages go one per datagram over udp and unixgram and, over tcp and
+// unix streams, with octet-counting framing (RFC 6587) for RFC 5424 or
+// newline-terminated for RFC 3164.
+//
+// When a write fails, the handler closes the connection, keeps the
+// message and reconnects on a later Write, at most once per RetryDelay,
+// sending held messages first in order. Up to BufferSize bytes are held;
+// older messages are dropped and counted by Dropped. Write returns the
+// connection error when an attempt fails, even though the message is
+// kept. A write for an event with a context, see [Event.Ctx], fails the
+// same way if it is still blocked at the context's deadline.
+// SyslogHandler is safe for concurrent use.
+type SyslogHandler struct {
+	network  string
+	addr     string
+	tls      *tls.Config
+	dialer   func(network, addr string) (net.Conn, error)
+	format   SyslogFormat
+	facility int
+	sev      *SeverityMap[int]
+	app      string
+	host     string
+	pid      string
+	sdid     string
+	maxBuf   int
+	retry    time.Duration
+	now      func() time.Time
+
+	mu       sync.Mutex
+	conn     net.Conn
+	stream   bool
+	local    bool
+	lastDial time.Time
+	pending  [][]byte
+	pendingN int
+	closed   bool
+	dropped  atomic.Uint64
+	deadline time.Time // write deadline of the current event; see Event.Ctx
+	bounded  bool      // a write deadline is set on conn
+
+	msg   []byte
+	out   []byte
+	spans []FieldSpan
+}
+
+// NewSyslogHandler connects to the server described by opts and returns a
+// handler writing to it. It fails if the first connection cannot be made.
+func NewSyslogHandler(opts *SyslogOptions) (*SyslogHandler, error) {
+	if opts == nil {
+		opts = &SyslogOptions{}
+	}
+	h := &SyslogHandler{
+		network:  opts.Network,
+		addr:     opts.Addr,
+		dialer:   opts.Dial,
+		format:   opts.Format,
+		facility: opts.Facility,
+		sev:      &DefaultSeverities.Syslog,
+		app:      opts.AppName,
+		host:     opts.Hostname,
+		pid:      strconv.Itoa(os.Getpid()),
+		sdid:     opts.SDID,
+		maxBuf:   opts.BufferSize,
+		retry:    opts.RetryDelay,
+		now:      time.Now,
+		local:    opts.Network == "",
+	}
+	switch h.network {
+	case "", "udp", "udp4", "udp6", "unixgram":
+	case "tcp", "tcp4", "tcp6", "unix":
+		h.stream = true
+	default:
+		return nil, fmt.Errorf("bolt: unsupported syslog network %q", h.network)
+	}
+	if h.facility < 1 || h.facility > 23 {
+		h.facility = DefaultSyslogFacility
+	}
+	if opts.Severities != nil {
+		h.sev = &opts.Severities.Syslog
+	}
+	if h.app == "" {
+		h.app = filepath.Base(os.Args[0])
+	}
+	h.app = syslogHeaderField(h.app, 48)
+	if h.host == "" {
+		h.host, _ = os.Hostname()
+	}
+	h.host = syslogHeaderField(h.host, 255)
+	if h.sdid == "" {
+		h.sdid = DefaultSyslogSDID
+	}
+	if h.maxBuf <= 0 {
+		h.maxBuf = DefaultSyslogBuffer
+	}
+	if h.retry <= 0 {
+		h.retry = DefaultSyslogRetry
+	}
+	if opts.TLS != nil {
+		cfg, err := opts.TLS.Build()
+		if err != nil {
+			return nil, err
+		}
+		h.tls = cfg
+	}
+
+	h.lastDial = h.now()
+	conn, err := h.dial()
+	if err != nil {
+		return nil, fmt.Errorf("bolt: syslog: %w", err)
+	}
+	h.conn = conn
+	return h, nil
+}
+
+// dial opens a connection, trying the usual local socket paths when no
+// network is configured.
+func (h *SyslogHandler) dial() (net.Conn, error) {
+	if !h.local && h.dialer != nil {
+		conn, err := h.dialer(h.network, h.addr)
+		if err != nil || h.tls == nil {
+			return conn, err
+		}
+		cfg := h.tls
+		if cfg.ServerName == "" {
+			cfg = cfg.Clone()
+			cfg.ServerName, _, _ = net.SplitHostPort(h.addr)
+		}
+		tc := tls.Client(conn, cfg)
+		ctx, cancel := context.WithTimeout(context.Background(), syslogDialTimeout)
+		defer cancel()
+		if err := tc.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return tc, nil
+	}
+	if !h.local {
+		d := net.Dialer{Timeout: syslogDialTimeout}
+		if h.tls != nil {
+			return tls.DialWithDialer(&d, h.network, h.addr, h.tls)
+		}
+		return d.Dial(h.network, h.addr)
+	}
+	paths := []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+	if h.addr != "" {
+		paths = []string{h.addr}
+	}
+	var err error
+	for _, network := range []string{"unixgram", "unix"} {
+		for _, path := range paths {
+			var conn net.Conn
+			if conn, err = net.DialTimeout(network, path, syslogDialTimeout); err == nil {
+				h.stream = network == "unix"
+				return conn, nil
+			}
+		}
+	}
+	return nil, err
+}
+
+// Write implements [Handler].
+func (h *SyslogHandler) Write(e *Event) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return ErrHandlerClosed
+	}
+	h.deadline, _ = e.deadline()
+	if h.format == RFC3164 {
+		h.msg = h.append3164(h.msg[:0], e)
+	} else {
+		h.msg = h.append5424(h.msg[:0], e)
+	}
+
+	if h.conn == nil {
+		if err := h.reconnect(); err != nil {
+			h.hold(h.msg)
+			return err
+		}
+		if h.conn == nil {
+			h.hold(h.msg) // waiting for RetryDelay
+			return nil
+		}
+	}
+	if err := h.send(h.msg); err != nil {
+		h.hold(h.msg)
+		return err
+	}
+	return nil
+}
+
+// reconnect dials again if RetryDelay has passed since the last attempt
+// and sends the held messages. It leaves h.conn nil if it did not try.
+func (h *SyslogHandler) reconnect() error {
+	if h.now().Sub(h.lastDial) < h.retry {
+		return nil
+	}
+	h.lastDial = h.now()
+	conn, err := h.dial()
+	if err != nil {
+		return fmt.Errorf("bolt: syslog: %w", err)
+	}
+	h.conn, h.bounded = conn, false
+	for len(h.pending) > 0 {
+		if err := h.send(h.pending[0]); err != nil {
+			return err
+		}
+		h.pendingN -= len(h.pending[0])
+		h.pending[0] = nil
+		h.pending = h.pending[1:]
+	}
+	h.pending = nil
+	return nil
+}
+
+// send writes one framed message, closing the connection on failure.
+func (h *SyslogHandler) send(msg []byte) error {
+	out := msg
+	if h.stream {
+		if h.format == RFC3164 {
+			out = append(append(h.out[:0], msg...), '\n')
+		} else {
+			out = strconv.AppendInt(h.out[:0], int64(len(msg)), 10)
+			out = append(append(out, ' '), msg...)
+		}
+		h.out = out
+	}
+	if h.bounded || !h.deadline.IsZero() {
+		_ = h.conn.SetWriteDeadline(h.deadline)
+		h.bounded = !h.deadline.IsZero()
+	}
+	if _, err := h.conn.Write(out); err != nil {
+		h.conn.Close()
+		h.conn = nil
+		return fmt.Errorf("bolt: syslog: %w", err)
+	}
+	return nil
+}
+
+// hold keeps a copy of msg to send after reconnecting, dropping the
+// oldest held messages beyond the buffer size.
+func (h *SyslogHandler) hold(msg []byte) {
+	h.pending = append(h.pending, append([]byte(nil), msg...))
+	h.pendingN += len(msg)
+	for h.pendingN > h.maxBuf && len(h.pending) > 0 {
+		h.pendingN -= len(h.pending[0])
+		h.pending[0] = nil
+		h.pending = h.pending[1:]
+		h.dropped.Add(1)
+	}
+}
+
+// Dropped returns the number of messages dropped because the buffer was
+// full while the server was unreachable.
+func (h *SyslogHandler) Dropped() uint64 {
+	return h.dropped.Load()
+}
+
+// Close tries once more to send held messages and closes the
+// connection. Writes after Close return ErrHandlerClosed.
+func (h *SyslogHandler) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil
+	}
+	h.closed = true
+	h.deadline = time.Time{}
+	var err error
+	if h.conn == nil && len(h.pending) > 0 {
+		h.lastDial = time.Time{}
+		err = h.reconnect()
+	}
+	if h.conn != nil {
+		if cerr := h.conn.Close(); err == nil {
+			err = cerr
+		}
+		h.conn = nil
+	}
+	return err
+}
+
+// priority returns the PRI value for e.
+func (h *SyslogHandler) priority(e *Event) int {
+	return h.facility*8 + h.sev.Of(e.Level())
+}
+
+// append5424 appends e as an RFC 5424 message.
+func (h *SyslogHandler) append5424(dst []byte, e *Event) []byte {
+	dst = append(dst, '<')
+	dst = strconv.AppendInt(dst, int64(h.priority(e)), 10)
+	dst = append(dst, ">1 "...)
+	dst = h.now().UTC().AppendFormat(dst, "2006-01-02T15:04:05.000000Z07:00")
+	dst = append(dst, ' ')
+	dst = append(dst, h.host...)
+	dst = append(dst, ' ')
+	dst = append(dst, h.app...)
+	dst = append(dst, ' ')
+	dst = append(dst, h.pid...)
+	dst = append(dst, " - "...) // no MSGID
+
+	buf := e.Buffer()
+	h.spans = e.FieldSpans(h.spans[:0])
+	var message []byte
+	sd := false
+	for _, s := range h.spans {
+		key, value := s.Key(buf), s.Value(buf)
+		switch string(key) {
+		case "level":
+			continue
+		case "message":
+			message = value
+			continue
+		}
+		if !sd {
+			dst = append(dst, '[')
+			dst = append(dst, h.sdid...)
+			sd = true
+		}
+		dst = append(dst, ' ')
+		dst = appendSDName(dst, key)
+		dst = append(dst, `="`...)
+		if len(value) > 1 && value[0] == '"' {
+			dst = appendSDValue(dst, value[1:len(value)-1], true)
+		} else {
+			dst = appendSDValue(dst, value, false)
+		}
+		dst = append(dst, '"')
+	}
+	if sd {
+		dst = append(dst, ']')
+	} else {
+		dst = append(dst, '-')
+	}
+	if len(message) > 1 {
+		dst = append(dst, ' ')
+		dst = appendJSONUnescaped(dst, message[1:len(message)-1])
+	}
+	return dst
+}
+
+// append3164 appends e as an RFC 3164 message whose content is the JSON
+// record. Like the standard library, it omits the hostname when writing
+// to the local daemon.
+func (h *SyslogHandler) append3164(dst []byte, e *Event) []byte {
+	dst = append(dst, '<')
+	dst = strconv.AppendInt(dst, int64(h.priority(e)), 10)
+	dst = append(dst, '>')
+	dst = h.now().AppendFormat(dst, time.Stamp)
+	dst = append(dst, ' ')
+	if !h.local {
+		dst = append(dst, h.host...)
+		dst = append(dst, ' ')
+	}
+	dst = append(dst, h.app...)
+	dst = append(dst, '[')
+	dst = append(dst, h.pid...)
+	dst = append(dst, "]: "...)
+	record := e.Buffer()
+	for len(record) > 0 && (record[len(record)-1] == '\n' || record[len(record)-1] == '\r') {
+		record = record[:len(record)-1]
+	}
+	return append(dst, record...)
+}
+
+// appendSDName appends key as an RFC 5424 PARAM-NAME: at most 32
+// printable ASCII characters other than '=', ' ', ']' and '"'.
+func appendSDName(dst, key []byte) []byte {
+	if len(key) > 32 {
+		key = key[:32]
+	}
+	for _, c := range key {
+		if c <= ' ' || c >= 0x7f || c == '=' || c == ']' || c == '"' {
+			c = '_'
+		}
+		dst = append(dst, c)
+	}
+	return dst
+}
+
+// appendSDValue appends v as an RFC 5424 PARAM-VALUE body, escaping '"',
+// '\' and ']'. If str is set, v is the body of a JSON string and is
+// decoded first.
+func appendSDValue(dst, v []byte, str bool) []byte {
+	start := len(dst)
+	if str {
+		dst = appendJSONUnescaped(dst, v)
+	} else {
+		dst = append(dst, v...)
+	}
+	n := 0
+	for _, c := range dst[start:] {
+		if c == '"' || c == '\\' || c == ']' {
+			n++
+		}
+	}
+	if n == 0 {
+		return dst
+	}
+	// Escape in place, back to front.
+	end := len(dst)
+	dst = append(dst, make([]byte, n)...)
+	j := len(dst)
+	for i := end - 1; i >= start; i-- {
+		c := dst[i]
+		j--
+		dst[j] = c
+		if c == '"' || c == '\\' || c == ']' {
+			j--
+			dst[j] = '\\'
+		}
+	}
+	return dst
+}
+
+// syslogHeaderField returns s as a header field of at most n printable
+// ASCII characters, or "-" if it is empty.
+func syslogHeaderField(s string, n int) string {
+	s = strings.Map(func(r rune) rune {
+		if r <= ' ' || r >= 0x7f {
+			return '_'
+		}
+		return r
+	}, s)
+	if len(s) > n {
+		s = s[:n]
+	}
+	if s == "" {
+		return "-"
+	}
+	return s
+}
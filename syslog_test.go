@@ -0,0 +1,199 @@
+package bolt
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var syslogTestTime = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func TestSyslogHandlerRFC5424(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	h, err := NewSyslogHandler(&SyslogOptions{
+		Network:  "udp",
+		Addr:     pc.LocalAddr().String(),
+		AppName:  "api",
+		Hostname: "web-1",
+		Facility: 16, // local0
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	h.now = func() time.Time { return syslogTestTime }
+
+	New(h).Warn().
+		Str("user_id", "42").
+		Str("note", `say "hi" \ [x]`).
+		Int("n", 3).
+		Msg("login\nfailed")
+
+	buf := make([]byte, 2048)
+	pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "<132>1 2024-05-01T12:00:00.000000Z web-1 api " + strconv.Itoa(os.Getpid()) +
+		` - [bolt@32473 user_id="42" note="say \"hi\" \\ [x\]" n="3"] login` + "\nfailed"
+	if got := string(buf[:n]); got != want {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+}
+
+func TestSyslogHandlerRFC3164OverTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	h, err := NewSyslogHandler(&SyslogOptions{
+		Network:  "tcp",
+		Addr:     ln.Addr().String(),
+		Format:   RFC3164,
+		AppName:  "api",
+		Hostname: "web-1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.now = func() time.Time { return syslogTestTime }
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	logger := New(h)
+	logger.Error().Str("k", "v").Msg("boom")
+	logger.Debug().Msg("detail")
+	h.Close()
+
+	lines, _ := io.ReadAll(conn)
+	pid := strconv.Itoa(os.Getpid())
+	want := "<11>May  1 12:00:00 web-1 api[" + pid + `]: {"level":"error","k":"v","message":"boom"}` + "\n" +
+		"<15>May  1 12:00:00 web-1 api[" + pid + `]: {"level":"debug","message":"detail"}` + "\n"
+	if string(lines) != want {
+		t.Errorf("got  %q\nwant %q", lines, want)
+	}
+}
+
+// failConn fails every write.
+type failConn struct{ net.Conn }
+
+func (failConn) Write([]byte) (int, error) { return 0, errors.New("connection reset") }
+func (failConn) Close() error              { return nil }
+
+func TestSyslogHandlerReconnects(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	h, err := NewSyslogHandler(&SyslogOptions{Network: "tcp", Addr: ln.Addr().String(), RetryDelay: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.Close()
+
+	var errs []error
+	logger := New(h).SetErrorHandler(func(err error) { errs = append(errs, err) })
+	h.conn = failConn{}
+	logger.Info().Msg("one") // fails and is held
+	logger.Info().Msg("two") // within RetryDelay: held without trying
+	if len(errs) != 1 {
+		t.Fatalf("errors = %v, want the one failed write", errs)
+	}
+
+	h.mu.Lock()
+	h.lastDial = time.Time{}
+	h.mu.Unlock()
+	logger.Info().Msg("three") // reconnects, sends one and two first
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	h.Close()
+
+	r := bufio.NewReader(conn)
+	var got []string
+	for {
+		n, err := readFrameLen(r)
+		if err != nil {
+			break
+		}
+		msg := make([]byte, n)
+		if _, err := io.ReadFull(r, msg); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, string(msg[strings.LastIndexByte(string(msg), ' ')+1:]))
+	}
+	if strings.Join(got, ",") != "one,two,three" {
+		t.Errorf("server received %q, want one,two,three in order", got)
+	}
+	if h.Dropped() != 0 {
+		t.Errorf("Dropped() = %d, want 0", h.Dropped())
+	}
+}
+
+func TestSyslogHandlerDropsOldest(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+	h, err := NewSyslogHandler(&SyslogOptions{Network: "udp", Addr: pc.LocalAddr().String(), BufferSize: 150, RetryDelay: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.conn = failConn{}
+	logger := New(h).SetErrorHandler(func(error) {})
+	for range 5 {
+		logger.Info().Msg("held")
+	}
+	if h.Dropped() == 0 || h.pendingN > 150 {
+		t.Errorf("Dropped() = %d with %d bytes held, want the oldest dropped", h.Dropped(), h.pendingN)
+	}
+	h.Close()
+	if err := h.Write(&Event{}); !errors.Is(err, ErrHandlerClosed) {
+		t.Errorf("Write after Close = %v, want ErrHandlerClosed", err)
+	}
+}
+
+func TestSDValueEscaping(t *testing.T) {
+	got := string(appendSDValue(nil, []byte(`a\"b\\c]dé`), true))
+	if want := `a\"b\\c\]dé`; got != want {
+		t.Errorf("appendSDValue = %s, want %s", got, want)
+	}
+	if got := string(appendSDName(nil, []byte(`a b=c"d]`+strings.Repeat("x", 40)))); len(got) != 32 || got[:8] != "a_b_c_d_" {
+		t.Errorf("appendSDName = %q", got)
+	}
+}
+
+// readFrameLen reads the length prefix of an octet-counted frame.
+func readFrameLen(r *bufio.Reader) (int, error) {
+	s, err := r.ReadString(' ')
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSuffix(s, " "))
+}
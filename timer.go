@@ -0,0 +1,123 @@
+package bolt
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultTimerInterval is the minimum time between two events of the same
+// timer when no interval is configured.
+const DefaultTimerInterval = time.Second
+
+// TimerOptions configures [Logger.TimerWith]. A nil *TimerOptions uses
+// the defaults.
+type TimerOptions struct {
+	// Level is the level of the completion events. Defaults to TRACE.
+	Level Level
+
+	// Interval is the minimum time between two events of timers with the
+	// same name; completions in between are counted and reported as
+	// "suppressed" on the next event. Defaults to DefaultTimerInterval;
+	// a negative value logs every completion.
+	Interval time.Duration
+
+	// Observe, if set, receives the duration of every completion,
+	// including those not logged, for example to feed a histogram:
+	//
+	//	Observe: func(name string, d time.Duration) {
+	//	    latency.WithLabelValues(name).Observe(d.Seconds())
+	//	},
+	Observe func(name string, d time.Duration)
+}
+
+// Timer measures one run of a code path. Create it with [Logger.Timer]
+// and call Done when the path completes.
+type Timer struct {
+	l     *Logger
+	name  string
+	opts  *TimerOptions
+	start time.Time
+}
+
+// timerState is the rate limit shared by timers with one name.
+type timerState struct {
+	last       atomic.Int64 // UnixNano of the last logged completion
+	suppressed atomic.Uint64
+}
+
+// timerStates maps timer names to their *timerState. Names are expected
+// to be constants, like messages, so the map stays small.
+var timerStates sync.Map
+
+// Timer starts timing the code path name with the default options,
+// replacing the start := time.Now() ... Dur("duration", time.Since(start))
+// boilerplate:
+//
+//	defer logger.Timer("load_users").Done()
+//
+// logs, at most once per second per name:
+//
+//	{"level":"trace","timer":"load_users","duration":1532000,"message":"load_users"}
+func (l *Logger) Timer(name string) Timer {
+	return l.TimerWith(name, nil)
+}
+
+// TimerWith is like [Logger.Timer] with options.
+func (l *Logger) TimerWith(name string, opts *TimerOptions) Timer {
+	if opts == nil {
+		opts = &TimerOptions{}
+	}
+	return Timer{l: l, name: name, opts: opts, start: time.Now()}
+}
+
+// Done ends the timer and returns the elapsed time. It passes the
+// duration to Observe and logs it, with the number of completions
+// suppressed since the previous event if any, unless the timer's level
+// is disabled or the rate limit suppresses it. A zero Timer does nothing.
+func (t Timer) Done() time.Duration {
+	d := time.Since(t.start)
+	if t.l == nil {
+		return d
+	}
+	if t.opts.Observe != nil {
+		t.opts.Observe(t.name, d)
+	}
+	suppressed, ok := timerAllow(t.name, t.opts.Interval)
+	if !ok {
+		return d
+	}
+	e := t.l.log(t.opts.Level, 0)
+	if e == nil {
+		return d
+	}
+	e.Str("timer", t.name).Dur("duration", d)
+	if suppressed > 0 {
+		e.Uint64("suppressed", suppressed)
+	}
+	e.Msg(t.name)
+	return d
+}
+
+// timerAllow reports whether a completion of the timer name may be
+// logged now, and how many completions were suppressed before it.
+func timerAllow(name string, interval time.Duration) (uint64, bool) {
+	if interval < 0 {
+		return 0, true
+	}
+	if interval == 0 {
+		interval = DefaultTimerInterval
+	}
+	v, ok := timerStates.Load(name)
+	if !ok {
+		v, _ = timerStates.LoadOrStore(name, &timerState{})
+	}
+	s := v.(*timerState)
+	now := time.Now().UnixNano()
+	last := s.last.Load()
+	if last != 0 && now-last < int64(interval) || !s.last.CompareAndSwap(last, now) {
+		s.suppressed.Add(1)
+		return 0, false
+	}
+	return s.suppressed.Swap(0), true
+}
@@ -0,0 +1,65 @@
+package bolt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimerLogsDuration(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewJSONHandler(&buf)).SetLevel(TRACE).SetCallerOptions(&CallerOptions{Always: true})
+	func() {
+		defer logger.TimerWith("timer_test_load", &TimerOptions{Interval: -1}).Done()
+		time.Sleep(time.Millisecond)
+	}()
+
+	out := buf.String()
+	if !strings.HasPrefix(out, `{"level":"trace","caller":"timer_test.go:`) ||
+		!strings.Contains(out, `"timer":"timer_test_load","duration":`) ||
+		!strings.HasSuffix(out, `"message":"timer_test_load"}`+"\n") {
+		t.Errorf("got %s", out)
+	}
+}
+
+func TestTimerRateLimitAndObserve(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewJSONHandler(&buf)).SetLevel(TRACE)
+	var observed []time.Duration
+	opts := &TimerOptions{
+		Level:    DEBUG,
+		Interval: 50 * time.Millisecond,
+		Observe:  func(name string, d time.Duration) { observed = append(observed, d) },
+	}
+	for range 3 {
+		logger.TimerWith("timer_test_rate", opts).Done()
+	}
+	time.Sleep(60 * time.Millisecond)
+	logger.TimerWith("timer_test_rate", opts).Done()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d events, want 2:\n%s", len(lines), buf.String())
+	}
+	if !strings.HasPrefix(lines[0], `{"level":"debug"`) || strings.Contains(lines[0], "suppressed") {
+		t.Errorf("first = %s", lines[0])
+	}
+	if !strings.Contains(lines[1], `"suppressed":2`) {
+		t.Errorf("second = %s, want the suppressed completions counted", lines[1])
+	}
+	if len(observed) != 4 {
+		t.Errorf("observed %d completions, want 4", len(observed))
+	}
+}
+
+func TestTimerDisabledLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewJSONHandler(&buf)).SetLevel(INFO)
+	if d := logger.Timer("timer_test_disabled").Done(); d < 0 || buf.Len() != 0 {
+		t.Errorf("Done = %v, output %q", d, buf.String())
+	}
+	if d := (Timer{}).Done(); d < 0 {
+		t.Errorf("zero Timer Done = %v", d)
+	}
+}
@@ -0,0 +1,200 @@
+package bolt
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTLSReloadInterval is how often [TLSConfig] checks certificate
+// files for changes when no interval is configured.
+const DefaultTLSReloadInterval = time.Minute
+
+// ErrPinMismatch is returned during the handshake when no certificate in
+// the server's verified chain matches [TLSConfig.PinnedKeys].
+var ErrPinMismatch = errors.New("bolt: server certificate does not match any pinned key")
+
+// TLSConfig is the TLS configuration shared by bolt's network sinks, so
+// every sink accepts the same options with the same defaults. Build turns
+// it into a *tls.Config.
+//
+// Client certificates may be rotated on disk, as spiffe-helper does for
+// SPIFFE X.509 SVIDs; CertFile and KeyFile are re-read when they change,
+// without reconnecting. For sources that are not files, such as the
+// SPIFFE Workload API, supply Certificate instead.
+type TLSConfig struct {
+	// MinVersion is the minimum protocol version. Defaults to TLS 1.2,
+	// which is also the lowest accepted.
+	MinVersion uint16
+
+	// ServerName overrides the name verified against the server
+	// certificate, which otherwise comes from the dialled address.
+	ServerName string
+
+	// CAFile is a PEM bundle of root CAs to trust instead of the system
+	// pool.
+	CAFile string
+
+	// CertFile and KeyFile are the PEM client certificate and key for
+	// mutual TLS. They are checked for changes every ReloadInterval.
+	CertFile, KeyFile string
+
+	// Certificate, if set, supplies the client certificate for every
+	// handshake and takes precedence over CertFile and KeyFile.
+	Certificate func() (*tls.Certificate, error)
+
+	// ReloadInterval bounds how often CertFile and KeyFile are checked.
+	// Defaults to DefaultTLSReloadInterval.
+	ReloadInterval time.Duration
+
+	// PinnedKeys are "sha256/<base64>" digests of SubjectPublicKeyInfo, as
+	// printed by curl --pinnedpubkey. When set, the handshake fails with
+	// ErrPinMismatch unless a certificate in the verified chain matches
+	// one of them. Pin a CA or an intermediate and a backup key, so
+	// routine leaf rotation does not lock the sink out.
+	PinnedKeys []string
+
+	// ErrorHandler receives certificate reload failures. The last good
+	// certificate stays in use.
+	ErrorHandler ErrorHandler
+}
+
+// Build returns the *tls.Config for c. A nil c yields a default client
+// configuration with TLS 1.2 as the minimum.
+func (c *TLSConfig) Build() (*tls.Config, error) {
+	if c == nil {
+		c = &TLSConfig{}
+	}
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12, ServerName: c.ServerName}
+	if c.MinVersion != 0 {
+		if c.MinVersion < tls.VersionTLS12 {
+			return nil, fmt.Errorf("bolt: TLS MinVersion %s is below TLS 1.2", tls.VersionName(c.MinVersion))
+		}
+		cfg.MinVersion = c.MinVersion
+	}
+
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("bolt: read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("bolt: no certificates in CA file %s", c.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	switch {
+	case c.Certificate != nil:
+		cfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return c.Certificate()
+		}
+	case c.CertFile != "" || c.KeyFile != "":
+		r := &certReloader{certFile: c.CertFile, keyFile: c.KeyFile, interval: c.ReloadInterval, onError: c.ErrorHandler}
+		if r.interval <= 0 {
+			r.interval = DefaultTLSReloadInterval
+		}
+		if err := r.load(); err != nil {
+			return nil, err
+		}
+		cfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return r.get(), nil
+		}
+	}
+
+	if len(c.PinnedKeys) > 0 {
+		pins := make(map[string]bool, len(c.PinnedKeys))
+		for _, p := range c.PinnedKeys {
+			if !strings.HasPrefix(p, "sha256/") {
+				return nil, fmt.Errorf("bolt: pinned key %q: want sha256/<base64>", p)
+			}
+			pins[strings.TrimPrefix(p, "sha256/")] = true
+		}
+		cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			for _, chain := range cs.VerifiedChains {
+				for _, cert := range chain {
+					if pins[SPKIPin(cert)] {
+						return nil
+					}
+				}
+			}
+			return ErrPinMismatch
+		}
+	}
+	return cfg, nil
+}
+
+// SPKIPin returns the base64 SHA-256 digest of cert's public key, the
+// part of a [TLSConfig.PinnedKeys] entry after "sha256/".
+func SPKIPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// certReloader serves a client certificate from files, re-reading them at
+// most once per interval when their modification time changes.
+type certReloader struct {
+	certFile, keyFile string
+	interval          time.Duration
+	onError           ErrorHandler
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+	checked time.Time
+}
+
+func (r *certReloader) load() error {
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return fmt.Errorf("bolt: stat client certificate: %w", err)
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("bolt: load client certificate: %w", err)
+	}
+	r.cert, r.modTime, r.checked = &cert, modTime, time.Now()
+	return nil
+}
+
+func (r *certReloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, name := range []string{r.certFile, r.keyFile} {
+		fi, err := os.Stat(name)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if fi.ModTime().After(latest) {
+			latest = fi.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+func (r *certReloader) get() *tls.Certificate {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if time.Since(r.checked) < r.interval {
+		return r.cert
+	}
+	r.checked = time.Now()
+	modTime, err := r.latestModTime()
+	if err == nil && modTime.Equal(r.modTime) {
+		return r.cert
+	}
+	if err == nil {
+		err = r.load()
+	}
+	if err != nil && r.onError != nil {
+		r.onError(err)
+	}
+	return r.cert
+}
@@ -0,0 +1,158 @@
+package bolt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeCAFile writes the test server's certificate as a CA bundle.
+func writeCAFile(t *testing.T, srv *httptest.Server) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestTLSConfigPinning(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer srv.Close()
+	caFile := writeCAFile(t, srv)
+	goodPin := "sha256/" + SPKIPin(srv.Certificate())
+
+	for _, tc := range []struct {
+		name string
+		pins []string
+		want error
+	}{
+		{"no pins", nil, nil},
+		{"matching pin", []string{"sha256/AAAA", goodPin}, nil},
+		{"wrong pin", []string{"sha256/AAAA"}, ErrPinMismatch},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := (&TLSConfig{CAFile: caFile, ServerName: "example.com", PinnedKeys: tc.pins}).Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: cfg}}
+			resp, err := client.Get(srv.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if !errors.Is(err, tc.want) {
+				t.Errorf("Get error = %v; want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestTLSConfigValidation(t *testing.T) {
+	if _, err := (&TLSConfig{MinVersion: tls.VersionTLS11}).Build(); err == nil {
+		t.Error("TLS 1.1 accepted")
+	}
+	if _, err := (&TLSConfig{PinnedKeys: []string{"abc"}}).Build(); err == nil {
+		t.Error("malformed pin accepted")
+	}
+	cfg, err := (*TLSConfig)(nil).Build()
+	if err != nil || cfg.MinVersion != tls.VersionTLS12 {
+		t.Errorf("nil config: %v, MinVersion %x", err, cfg.MinVersion)
+	}
+}
+
+// writeKeyPair writes a fresh self-signed client certificate.
+func writeKeyPair(t *testing.T, certFile, keyFile, cn string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTLSConfigReloadsClientCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "svid.pem"), filepath.Join(dir, "svid_key.pem")
+	writeKeyPair(t, certFile, keyFile, "first")
+
+	cfg, err := (&TLSConfig{CertFile: certFile, KeyFile: keyFile, ReloadInterval: time.Nanosecond}).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject := func() string {
+		c, err := cfg.GetClientCertificate(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		leaf, err := x509.ParseCertificate(c.Certificate[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return leaf.Subject.CommonName
+	}
+	if got := subject(); got != "first" {
+		t.Fatalf("CN = %q, want first", got)
+	}
+
+	writeKeyPair(t, certFile, keyFile, "rotated")
+	future := time.Now().Add(time.Minute)
+	for _, f := range []string{certFile, keyFile} {
+		if err := os.Chtimes(f, future, future); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := subject(); got != "rotated" {
+		t.Errorf("CN after rotation = %q, want rotated", got)
+	}
+
+	// A half-written rotation keeps the last good certificate.
+	var reloadErr error
+	cfg, err = (&TLSConfig{CertFile: certFile, KeyFile: keyFile, ReloadInterval: time.Nanosecond,
+		ErrorHandler: func(err error) { reloadErr = err }}).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	later := future.Add(time.Minute)
+	if err := os.WriteFile(keyFile, []byte("garbage"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(keyFile, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if got := subject(); got != "rotated" || reloadErr == nil {
+		t.Errorf("CN = %q, reload error = %v; want last good certificate and an error", got, reloadErr)
+	}
+}
@@ -0,0 +1,309 @@
+package bolt
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultVolumeWindow is the aggregation window used by [NewVolumeHook]
+// when none is configured.
+const DefaultVolumeWindow = time.Minute
+
+// volumeMaxKeys caps the number of distinct keys tracked per window; the
+// rest are folded into VolumeOtherKey so a key-cardinality bug cannot
+// grow the table without bound.
+const volumeMaxKeys = 1024
+
+// VolumeOtherKey aggregates keys beyond the per-window tracking limit.
+const VolumeOtherKey = "(other)"
+
+// volumeBuckets are the upper bounds, in bytes, of the event size
+// histogram. Larger events fall into a final +Inf bucket.
+var volumeBuckets = [...]int{128, 256, 512, 1024, 2048, 4096, 8192, 16384}
+
+// VolumeHook is an [EventHook] that accounts for where log bytes go: the
+// encoded size of every top-level field, by key, and a histogram of event
+// sizes, aggregated over a fixed window. It never suppresses events.
+//
+// Add it last so it measures events after other hooks have filtered or
+// enriched them, and read the result with [VolumeHook.Report] or mount
+// the hook on a debug mux, where it serves the report as JSON:
+//
+//	vol := bolt.NewVolumeHook(5 * time.Minute)
+//	logger.AddEventHook(vol)
+//	debugMux.Handle("/debug/bolt/volume", vol)
+//
+// A field's bytes include its key, quotes and separators; nested objects
+// are attributed to their top-level key, and logger context fields are
+// counted on every event that carries them. The message is reported under
+// "message". Sizes are measured before the message is escaped, so events
+// with heavily escaped messages are slightly undercounted. Counts are
+// kept in up to GOMAXPROCS independently locked shards and merged for the
+// report, so concurrent loggers rarely wait for each other.
+type VolumeHook struct {
+	window time.Duration
+	shards []volumeShard
+	next   atomic.Uint32 // shard to try first
+	start  atomic.Int64  // UnixNano start of the current window
+
+	mu   sync.Mutex    // serializes window rotation and Report
+	last *VolumeReport // most recently completed window
+}
+
+// volumeShard is one independently locked part of the current window, so
+// that concurrent events rarely wait for each other.
+type volumeShard struct {
+	mu    sync.Mutex
+	w     volumeWindow
+	spans []FieldSpan // scratch for the event being counted
+	_     [64]byte    // keep shards on separate cache lines
+}
+
+// volumeWindow accumulates one window's counts.
+type volumeWindow struct {
+	start   time.Time
+	events  uint64
+	bytes   uint64
+	buckets [len(volumeBuckets) + 1]uint64
+	keys    map[string]*KeyVolume
+}
+
+// KeyVolume is the volume attributed to one field key.
+type KeyVolume struct {
+	Key    string  `json:"key"`
+	Count  uint64  `json:"count"`
+	Bytes  uint64  `json:"bytes"`
+	Share  float64 `json:"share"` // fraction of all event bytes in the window
+	MaxLen int     `json:"max_bytes"`
+}
+
+// SizeBucket is one bar of the event size histogram. Le is the inclusive
+// upper bound in bytes; 0 marks the final, unbounded bucket.
+type SizeBucket struct {
+	Le    int    `json:"le"`
+	Count uint64 `json:"count"`
+}
+
+// VolumeReport summarizes one aggregation window.
+type VolumeReport struct {
+	Start   time.Time    `json:"start"`
+	End     time.Time    `json:"end"`
+	Partial bool         `json:"partial"` // window still in progress
+	Events  uint64       `json:"events"`
+	Bytes   uint64       `json:"bytes"`
+	Sizes   []SizeBucket `json:"sizes"`
+	Keys    []KeyVolume  `json:"keys"` // sorted by Bytes, largest first
+}
+
+// NewVolumeHook returns a VolumeHook aggregating over window, or over
+// DefaultVolumeWindow if window is not positive.
+func NewVolumeHook(window time.Duration) *VolumeHook {
+	if window <= 0 {
+		window = DefaultVolumeWindow
+	}
+	v := &VolumeHook{window: window, shards: make([]volumeShard, min(runtime.GOMAXPROCS(0), 64))}
+	now := time.Now()
+	v.start.Store(now.UnixNano())
+	for i := range v.shards {
+		v.shards[i].w.reset(now)
+	}
+	return v
+}
+
+func (w *volumeWindow) reset(now time.Time) {
+	*w = volumeWindow{start: now, keys: make(map[string]*KeyVolume, len(w.keys))}
+}
+
+// Run implements [EventHook].
+func (v *VolumeHook) Run(e *Event, msg string) bool {
+	// Final size once Msg appends `,"message":"…"}` and the newline.
+	size := len(e.Buffer()) + len(`,"message":""}`) + len(msg) + 1
+
+	now := time.Now()
+	if now.UnixNano()-v.start.Load() >= int64(v.window) {
+		v.mu.Lock()
+		if now.UnixNano()-v.start.Load() >= int64(v.window) {
+			v.rotate(now)
+		}
+		v.mu.Unlock()
+	}
+
+	s := v.lockShard()
+	w := &s.w
+	w.events++
+	w.bytes += uint64(size) // #nosec G115 -- size is non-negative
+	b := 0
+	for b < len(volumeBuckets) && size > volumeBuckets[b] {
+		b++
+	}
+	w.buckets[b]++
+
+	// Nested objects and arrays are part of their top-level field's span,
+	// so their bytes are attributed to the enclosing key.
+	buf := e.Buffer()
+	s.spans = e.FieldSpans(s.spans[:0])
+	for _, span := range s.spans {
+		w.add(span.Key(buf), span.End-span.Start+1) // +1 for the separating comma
+	}
+	w.add([]byte("message"), len(`,"message":""`)+len(msg))
+	s.mu.Unlock()
+	return true
+}
+
+// lockShard locks and returns a shard, preferring one that is free.
+func (v *VolumeHook) lockShard() *volumeShard {
+	first := int(v.next.Add(1) % uint32(len(v.shards))) // #nosec G115 -- len is at most 64
+	for i := range v.shards {
+		s := &v.shards[(first+i)%len(v.shards)]
+		if s.mu.TryLock() {
+			return s
+		}
+	}
+	s := &v.shards[first]
+	s.mu.Lock()
+	return s
+}
+
+func (w *volumeWindow) add(key []byte, n int) {
+	kv, ok := w.keys[string(key)]
+	if !ok {
+		kv = w.slot(string(key))
+	}
+	kv.Count++
+	kv.Bytes += uint64(n) // #nosec G115 -- n is non-negative
+	if n > kv.MaxLen {
+		kv.MaxLen = n
+	}
+}
+
+// slot returns the entry for a key not yet in w, which is VolumeOtherKey's
+// once volumeMaxKeys other keys are tracked.
+func (w *volumeWindow) slot(k string) *KeyVolume {
+	tracked := len(w.keys)
+	if _, ok := w.keys[VolumeOtherKey]; ok {
+		tracked--
+	}
+	if tracked >= volumeMaxKeys {
+		k = VolumeOtherKey
+	}
+	kv, ok := w.keys[k]
+	if !ok {
+		kv = &KeyVolume{Key: k}
+		w.keys[k] = kv
+	}
+	return kv
+}
+
+// merge adds the counts of o to w.
+func (w *volumeWindow) merge(o *volumeWindow) {
+	w.events += o.events
+	w.bytes += o.bytes
+	for i, c := range o.buckets {
+		w.buckets[i] += c
+	}
+	for k, okv := range o.keys {
+		kv, ok := w.keys[k]
+		if !ok {
+			kv = w.slot(k)
+		}
+		kv.Count += okv.Count
+		kv.Bytes += okv.Bytes
+		kv.MaxLen = max(kv.MaxLen, okv.MaxLen)
+	}
+}
+
+// collect merges the shards into one window, resetting them to start at
+// reset unless it is zero. Called with v.mu held.
+func (v *VolumeHook) collect(reset time.Time) volumeWindow {
+	w := volumeWindow{start: time.Unix(0, v.start.Load()), keys: map[string]*KeyVolume{}}
+	for i := range v.shards {
+		s := &v.shards[i]
+		s.mu.Lock()
+		w.merge(&s.w)
+		if !reset.IsZero() {
+			s.w.reset(reset)
+		}
+		s.mu.Unlock()
+	}
+	return w
+}
+
+// rotate closes the current window. Windows with no events in between are
+// skipped, so the report always describes the last window that saw
+// traffic. Called with v.mu held.
+func (v *VolumeHook) rotate(now time.Time) {
+	w := v.collect(now)
+	if w.events > 0 {
+		r := w.report(w.start.Add(v.window), false)
+		v.last = &r
+	}
+	v.start.Store(now.UnixNano())
+}
+
+func (w *volumeWindow) report(end time.Time, partial bool) VolumeReport {
+	r := VolumeReport{
+		Start:   w.start,
+		End:     end,
+		Partial: partial,
+		Events:  w.events,
+		Bytes:   w.bytes,
+		Sizes:   make([]SizeBucket, len(w.buckets)),
+		Keys:    make([]KeyVolume, 0, len(w.keys)),
+	}
+	for i, c := range w.buckets {
+		le := 0
+		if i < len(volumeBuckets) {
+			le = volumeBuckets[i]
+		}
+		r.Sizes[i] = SizeBucket{Le: le, Count: c}
+	}
+	for _, kv := range w.keys {
+		k := *kv
+		if w.bytes > 0 {
+			k.Share = float64(k.Bytes) / float64(w.bytes)
+		}
+		r.Keys = append(r.Keys, k)
+	}
+	sort.Slice(r.Keys, func(i, j int) bool {
+		if r.Keys[i].Bytes != r.Keys[j].Bytes {
+			return r.Keys[i].Bytes > r.Keys[j].Bytes
+		}
+		return r.Keys[i].Key < r.Keys[j].Key
+	})
+	return r
+}
+
+// Report returns the most recently completed window. Before the first
+// window has completed it returns the window in progress, marked Partial.
+func (v *VolumeHook) Report() VolumeReport {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	now := time.Now()
+	if now.UnixNano()-v.start.Load() >= int64(v.window) {
+		v.rotate(now)
+	}
+	if v.last != nil {
+		return *v.last
+	}
+	w := v.collect(time.Time{})
+	return w.report(now, true)
+}
+
+// ServeHTTP serves [VolumeHook.Report] as JSON. The optional "top" query
+// parameter limits the number of keys returned.
+func (v *VolumeHook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	report := v.Report()
+	if n, err := strconv.Atoi(r.URL.Query().Get("top")); err == nil && n >= 0 && n < len(report.Keys) {
+		report.Keys = report.Keys[:n]
+	}
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(report) // nothing useful to do if the client went away
+}
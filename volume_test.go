@@ -0,0 +1,159 @@
+package bolt
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestVolumeHookPerKeyBytes(t *testing.T) {
+	var sb strings.Builder
+	vol := NewVolumeHook(time.Hour)
+	logger := New(NewJSONHandler(&sb)).AddEventHook(vol)
+
+	logger.Info().
+		Str("user", "alice").
+		Dict("req", func(d *Event) { d.Str("a", "x,y").Int("b", 1) }).
+		Msg("hi")
+
+	r := vol.Report()
+	if !r.Partial || r.Events != 1 {
+		t.Fatalf("report = %+v", r)
+	}
+	if r.Bytes != uint64(sb.Len()) {
+		t.Errorf("Bytes = %d; want encoded size %d (%s)", r.Bytes, sb.Len(), sb.String())
+	}
+	want := map[string]uint64{
+		"level":   uint64(len(`{"level":"info"`)),
+		"user":    uint64(len(`,"user":"alice"`)),
+		"req":     uint64(len(`,"req":{"a":"x,y","b":1}`)),
+		"message": uint64(len(`,"message":"hi"`)),
+	}
+	if len(r.Keys) != len(want) {
+		t.Fatalf("keys = %+v; nested fields must not be reported separately", r.Keys)
+	}
+	var total uint64
+	for _, k := range r.Keys {
+		if k.Bytes != want[k.Key] {
+			t.Errorf("%s: %d bytes; want %d", k.Key, k.Bytes, want[k.Key])
+		}
+		total += k.Bytes
+	}
+	// Per-key spans plus the closing `}\n` account for the whole event.
+	if total+2 != r.Bytes {
+		t.Errorf("key bytes %d + 2 != event bytes %d", total, r.Bytes)
+	}
+	if r.Keys[0].Key != "req" {
+		t.Errorf("keys not sorted by volume: %+v", r.Keys)
+	}
+}
+
+func TestVolumeHookHistogramAndOverflow(t *testing.T) {
+	vol := NewVolumeHook(time.Hour)
+	logger := New(NewJSONHandler(io.Discard)).AddEventHook(vol)
+
+	logger.Info().Msg("small")
+	logger.Info().Str("blob", strings.Repeat("x", 20000)).Msg("huge")
+	for i := 0; i < volumeMaxKeys+10; i++ {
+		logger.Info().Int("k"+strconv.Itoa(i), i).Msg("")
+	}
+
+	r := vol.Report()
+	if r.Sizes[0].Count == 0 || r.Sizes[len(r.Sizes)-1].Count != 1 || r.Sizes[len(r.Sizes)-1].Le != 0 {
+		t.Errorf("histogram = %+v", r.Sizes)
+	}
+	if len(r.Keys) != volumeMaxKeys+1 {
+		t.Errorf("tracked %d keys; want cap %d plus %q", len(r.Keys), volumeMaxKeys, VolumeOtherKey)
+	}
+	if r.Keys[0].Key != "blob" || r.Keys[0].Share <= r.Keys[1].Share {
+		t.Errorf("top key = %+v", r.Keys[0])
+	}
+}
+
+func TestVolumeHookWindows(t *testing.T) {
+	vol := NewVolumeHook(100 * time.Millisecond)
+	logger := New(NewJSONHandler(io.Discard)).AddEventHook(vol)
+
+	logger.Info().Str("a", "1").Msg("")
+	time.Sleep(150 * time.Millisecond)
+	logger.Info().Str("b", "1").Msg("")
+	logger.Info().Str("b", "2").Msg("")
+
+	r := vol.Report()
+	if r.Partial || r.Events != 1 || r.Keys[0].Key == "b" {
+		t.Errorf("want the completed first window, got %+v", r)
+	}
+	time.Sleep(150 * time.Millisecond)
+	if r = vol.Report(); r.Events != 2 {
+		t.Errorf("second window events = %d; want 2", r.Events)
+	}
+	time.Sleep(150 * time.Millisecond)
+	if r = vol.Report(); r.Events != 2 {
+		t.Errorf("idle window replaced last report: %+v", r)
+	}
+}
+
+func TestVolumeHookServeHTTP(t *testing.T) {
+	vol := NewVolumeHook(time.Hour)
+	New(NewJSONHandler(io.Discard)).AddEventHook(vol).Info().Str("a", "b").Int("n", 1).Msg("m")
+
+	rec := httptest.NewRecorder()
+	vol.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/bolt/volume?top=2", nil))
+	var r VolumeReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &r); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, rec.Body.String())
+	}
+	if r.Events != 1 || len(r.Keys) != 2 {
+		t.Errorf("report = %+v", r)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+}
+
+func TestVolumeHookEscapedKeys(t *testing.T) {
+	var sb strings.Builder
+	vol := NewVolumeHook(time.Hour)
+	logger := New(NewJSONHandler(&sb)).AddEventHook(vol)
+	logger.Info().Str(`say "hi"`, "a,b").Str("next", "v").Msg("m")
+
+	r := vol.Report()
+	got := map[string]uint64{}
+	for _, k := range r.Keys {
+		got[k.Key] = k.Bytes
+	}
+	if got[`say \"hi\"`] != uint64(len(`,"say \"hi\"":"a,b"`)) || got["next"] != uint64(len(`,"next":"v"`)) {
+		t.Errorf("keys = %+v in %s", r.Keys, sb.String())
+	}
+}
+
+func TestVolumeHookConcurrent(t *testing.T) {
+	vol := NewVolumeHook(time.Hour)
+	logger := New(NewJSONHandler(io.Discard)).AddEventHook(vol)
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 500 {
+				logger.Info().Str("user", "alice").Msg("m")
+			}
+		}()
+	}
+	wg.Wait()
+
+	r := vol.Report()
+	if r.Events != 4000 {
+		t.Errorf("events = %d, want 4000", r.Events)
+	}
+	for _, k := range r.Keys {
+		if k.Count != 4000 {
+			t.Errorf("%s counted %d times, want 4000", k.Key, k.Count)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+// Package workflowlog adapts a bolt logger to the key/value logger
+// interface used by workflow engines, so workers no longer need zap just
+// to satisfy it.
+//
+// [Logger] has the method set of Temporal's go.temporal.io/sdk/log.Logger:
+//
+//	Debug(msg string, keyvals ...interface{})
+//	Info(msg string, keyvals ...interface{})
+//	Warn(msg string, keyvals ...interface{})
+//	Error(msg string, keyvals ...interface{})
+//
+// and can be passed to client.Options.Logger directly. The package does
+// not import the Temporal SDK; the interface is satisfied structurally.
+// Temporal's log.With wraps the logger in its own key/value prefixer
+// because [Logger.With] returns *Logger rather than log.Logger; the
+// output is the same.
+//
+// Temporal tags its records with keys such as "WorkflowID" and "RunID".
+// These are renamed to bolt's snake_case ("workflow_id", "run_id") using
+// [DefaultKeys], so workflow logs line up with the rest of the service's.
+//
+// Code that runs outside the SDK's logger, such as activity helpers,
+// can carry the same identifiers in a context with [ContextWithInfo] and
+// log through [Logger.WithContext]:
+//
+//	func (a *Activities) Charge(ctx context.Context, req ChargeRequest) error {
+//	    info := activity.GetInfo(ctx)
+//	    ctx = workflowlog.ContextWithInfo(ctx, workflowlog.Info{
+//	        WorkflowID: info.WorkflowExecution.ID,
+//	        RunID:      info.WorkflowExecution.RunID,
+//	        ActivityID: info.ActivityID,
+//	        Attempt:    int(info.Attempt),
+//	    })
+//	    log := a.log.WithContext(ctx)
+//	    log.Info("charging card", "amount", req.Amount)
+//	    ...
+//	}
+//
+// Cadence workers take a *zap.Logger rather than an interface and are not
+// covered by this package.
+package workflowlog
+
+import (
+	"context"
+	"fmt"
+
+	"go.klarlabs.de/bolt"
+)
+
+// DefaultKeys renames the keys Temporal attaches to its records.
+var DefaultKeys = map[string]string{
+	"Namespace":    "namespace",
+	"TaskQueue":    "task_queue",
+	"WorkerID":     "worker_id",
+	"WorkflowType": "workflow_type",
+	"WorkflowID":   "workflow_id",
+	"RunID":        "run_id",
+	"ActivityType": "activity_type",
+	"ActivityID":   "activity_id",
+	"Attempt":      "attempt",
+	"Error":        "error",
+}
+
+// BadKey is logged as the key of a trailing value without a key.
+const BadKey = "!BADKEY"
+
+// Options configures [New]. A nil *Options uses the defaults.
+type Options struct {
+	// Keys renames keys before they are logged. A nil map means
+	// DefaultKeys; use an empty, non-nil map to log keys unchanged.
+	Keys map[string]string
+}
+
+// Logger logs key/value pairs to a bolt logger.
+type Logger struct {
+	l    *bolt.Logger
+	keys map[string]string
+}
+
+// New returns a Logger writing to logger.
+func New(logger *bolt.Logger, opts *Options) *Logger {
+	keys := DefaultKeys
+	if opts != nil && opts.Keys != nil {
+		keys = opts.Keys
+	}
+	return &Logger{l: logger, keys: keys}
+}
+
+// Debug logs msg at DEBUG with the given key/value pairs.
+func (l *Logger) Debug(msg string, keyvals ...interface{}) {
+	l.fields(l.l.Debug(), keyvals).Msg(msg)
+}
+
+// Info logs msg at INFO with the given key/value pairs.
+func (l *Logger) Info(msg string, keyvals ...interface{}) {
+	l.fields(l.l.Info(), keyvals).Msg(msg)
+}
+
+// Warn logs msg at WARN with the given key/value pairs.
+func (l *Logger) Warn(msg string, keyvals ...interface{}) {
+	l.fields(l.l.Warn(), keyvals).Msg(msg)
+}
+
+// Error logs msg at ERROR with the given key/value pairs.
+func (l *Logger) Error(msg string, keyvals ...interface{}) {
+	l.fields(l.l.Error(), keyvals).Msg(msg)
+}
+
+// With returns a Logger that adds keyvals to every record.
+func (l *Logger) With(keyvals ...interface{}) *Logger {
+	if len(keyvals) == 0 {
+		return l
+	}
+	return &Logger{l: l.fields(l.l.With(), keyvals).Logger(), keys: l.keys}
+}
+
+// WithContext returns a Logger that adds the workflow identifiers stored
+// in ctx by [ContextWithInfo] and, through [bolt.Logger.Ctx], the
+// OpenTelemetry trace and span IDs.
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	logger := l.l.Ctx(ctx)
+	if info, ok := InfoFromContext(ctx); ok {
+		logger = info.appendFields(logger.With()).Logger()
+	}
+	if logger == l.l {
+		return l
+	}
+	return &Logger{l: logger, keys: l.keys}
+}
+
+// fields adds keyvals to e. Keys that are not strings are formatted with
+// fmt; a trailing value without a key is logged under BadKey.
+func (l *Logger) fields(e *bolt.Event, keyvals []interface{}) *bolt.Event {
+	if e == nil {
+		return e
+	}
+	for i := 0; i < len(keyvals); i += 2 {
+		if i+1 == len(keyvals) {
+			e = e.Any(BadKey, keyvals[i])
+			break
+		}
+		key, ok := keyvals[i].(string)
+		if !ok {
+			key = fmt.Sprint(keyvals[i])
+		}
+		if k, ok := l.keys[key]; ok {
+			key = k
+		}
+		switch v := keyvals[i+1].(type) {
+		case error:
+			e = e.Str(key, v.Error())
+		case fmt.Stringer:
+			e = e.Stringer(key, v)
+		default:
+			e = e.Any(key, v)
+		}
+	}
+	return e
+}
+
+type infoKey struct{}
+
+// Info identifies the workflow execution, and optionally the activity,
+// that a piece of code is running for. Empty fields are not logged.
+type Info struct {
+	Namespace    string
+	TaskQueue    string
+	WorkflowType string
+	WorkflowID   string
+	RunID        string
+	ActivityType string
+	ActivityID   string
+	Attempt      int
+}
+
+// ContextWithInfo returns a copy of ctx carrying info.
+func ContextWithInfo(ctx context.Context, info Info) context.Context {
+	return context.WithValue(ctx, infoKey{}, info)
+}
+
+// InfoFromContext returns the Info stored in ctx by [ContextWithInfo].
+func InfoFromContext(ctx context.Context) (Info, bool) {
+	info, ok := ctx.Value(infoKey{}).(Info)
+	return info, ok
+}
+
+func (info Info) appendFields(e *bolt.Event) *bolt.Event {
+	for _, f := range [...]struct{ key, value string }{
+		{"namespace", info.Namespace},
+		{"task_queue", info.TaskQueue},
+		{"workflow_type", info.WorkflowType},
+		{"workflow_id", info.WorkflowID},
+		{"run_id", info.RunID},
+		{"activity_type", info.ActivityType},
+		{"activity_id", info.ActivityID},
+	} {
+		if f.value != "" {
+			e = e.Str(f.key, f.value)
+		}
+	}
+	if info.Attempt > 0 {
+		e = e.Int("attempt", info.Attempt)
+	}
+	return e
+}
@@ -0,0 +1,115 @@
+package workflowlog_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.klarlabs.de/bolt"
+	"go.klarlabs.de/bolt/workflowlog"
+)
+
+// temporalLogger mirrors go.temporal.io/sdk/log.Logger.
+type temporalLogger interface {
+	Debug(msg string, keyvals ...interface{})
+	Info(msg string, keyvals ...interface{})
+	Warn(msg string, keyvals ...interface{})
+	Error(msg string, keyvals ...interface{})
+}
+
+var _ temporalLogger = (*workflowlog.Logger)(nil)
+
+func decode(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+	var m map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	return m
+}
+
+func TestLoggerKeyvals(t *testing.T) {
+	var buf bytes.Buffer
+	log := workflowlog.New(bolt.New(bolt.NewJSONHandler(&buf)), nil)
+
+	log.Warn("activity failed",
+		"WorkflowID", "order-42", "RunID", "r1", "Attempt", 3,
+		"Error", errors.New("card declined"), "custom", true, "dangling")
+
+	m := decode(t, &buf)
+	want := map[string]any{
+		"level":       "warn",
+		"message":     "activity failed",
+		"workflow_id": "order-42",
+		"run_id":      "r1",
+		"attempt":     float64(3),
+		"error":       "card declined",
+		"custom":      true,
+		"!BADKEY":     "dangling",
+	}
+	for k, v := range want {
+		if m[k] != v {
+			t.Errorf("%s = %v, want %v", k, m[k], v)
+		}
+	}
+}
+
+func TestLoggerLevels(t *testing.T) {
+	var buf bytes.Buffer
+	log := workflowlog.New(bolt.New(bolt.NewJSONHandler(&buf)).SetLevel(bolt.INFO), nil)
+
+	log.Debug("hidden")
+	if buf.Len() != 0 {
+		t.Fatalf("debug logged at INFO: %s", buf.String())
+	}
+	log.Error("boom")
+	if !strings.Contains(buf.String(), `"level":"error"`) {
+		t.Errorf("error level not logged: %s", buf.String())
+	}
+}
+
+func TestLoggerWith(t *testing.T) {
+	var buf bytes.Buffer
+	log := workflowlog.New(bolt.New(bolt.NewJSONHandler(&buf)), &workflowlog.Options{Keys: map[string]string{}})
+
+	log.With("WorkflowType", "Checkout").Info("started")
+	if !strings.Contains(buf.String(), `"WorkflowType":"Checkout"`) {
+		t.Errorf("keys renamed with empty Keys map: %s", buf.String())
+	}
+}
+
+func TestLoggerWithContext(t *testing.T) {
+	var buf bytes.Buffer
+	log := workflowlog.New(bolt.New(bolt.NewJSONHandler(&buf)), nil)
+
+	if log.WithContext(context.Background()) != log {
+		t.Error("WithContext without info returned a new logger")
+	}
+
+	ctx := workflowlog.ContextWithInfo(context.Background(), workflowlog.Info{
+		WorkflowID: "order-42",
+		RunID:      "r1",
+		ActivityID: "7",
+		Attempt:    2,
+	})
+	log.WithContext(ctx).Info("charging card", "amount", 1250)
+
+	m := decode(t, &buf)
+	for k, v := range map[string]any{
+		"workflow_id": "order-42",
+		"run_id":      "r1",
+		"activity_id": "7",
+		"attempt":     float64(2),
+		"amount":      float64(1250),
+	} {
+		if m[k] != v {
+			t.Errorf("%s = %v, want %v", k, m[k], v)
+		}
+	}
+	if _, ok := m["namespace"]; ok {
+		t.Error("empty namespace logged")
+	}
+}
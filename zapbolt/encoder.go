@@ -0,0 +1,123 @@
+package zapbolt
+
+import (
+	"strconv"
+	"time"
+
+	"go.klarlabs.de/bolt"
+	"go.uber.org/zap/zapcore"
+)
+
+// eventEncoder is a [zapcore.ObjectEncoder] writing to a bolt event.
+type eventEncoder struct {
+	e      *bolt.Event
+	prefix string // from OpenNamespace
+}
+
+func (enc *eventEncoder) key(k string) string {
+	return enc.prefix + k
+}
+
+func (enc *eventEncoder) AddArray(k string, v zapcore.ArrayMarshaler) error {
+	arr := &sliceEncoder{}
+	err := v.MarshalLogArray(arr)
+	enc.e.Any(enc.key(k), arr.elems)
+	return err
+}
+
+func (enc *eventEncoder) AddObject(k string, v zapcore.ObjectMarshaler) error {
+	var err error
+	enc.e.Dict(enc.key(k), func(d *bolt.Event) {
+		err = v.MarshalLogObject(&eventEncoder{e: d})
+	})
+	return err
+}
+
+func (enc *eventEncoder) AddBinary(k string, v []byte) { enc.e.Base64(enc.key(k), v) }
+func (enc *eventEncoder) AddByteString(k string, v []byte) {
+	enc.e.Str(enc.key(k), string(v))
+}
+func (enc *eventEncoder) AddBool(k string, v bool) { enc.e.Bool(enc.key(k), v) }
+func (enc *eventEncoder) AddComplex128(k string, v complex128) {
+	enc.e.Str(enc.key(k), strconv.FormatComplex(v, 'g', -1, 128))
+}
+func (enc *eventEncoder) AddComplex64(k string, v complex64) {
+	enc.e.Str(enc.key(k), strconv.FormatComplex(complex128(v), 'g', -1, 64))
+}
+func (enc *eventEncoder) AddDuration(k string, v time.Duration) { enc.e.Dur(enc.key(k), v) }
+func (enc *eventEncoder) AddFloat64(k string, v float64)        { enc.e.Float64(enc.key(k), v) }
+func (enc *eventEncoder) AddFloat32(k string, v float32)        { enc.e.Float64(enc.key(k), float64(v)) }
+func (enc *eventEncoder) AddInt(k string, v int)                { enc.e.Int(enc.key(k), v) }
+func (enc *eventEncoder) AddInt64(k string, v int64)            { enc.e.Int64(enc.key(k), v) }
+func (enc *eventEncoder) AddInt32(k string, v int32)            { enc.e.Int32(enc.key(k), v) }
+func (enc *eventEncoder) AddInt16(k string, v int16)            { enc.e.Int16(enc.key(k), v) }
+func (enc *eventEncoder) AddInt8(k string, v int8)              { enc.e.Int8(enc.key(k), v) }
+func (enc *eventEncoder) AddString(k, v string)                 { enc.e.Str(enc.key(k), v) }
+func (enc *eventEncoder) AddTime(k string, v time.Time)         { enc.e.Time(enc.key(k), v) }
+func (enc *eventEncoder) AddUint(k string, v uint)              { enc.e.Uint(enc.key(k), v) }
+func (enc *eventEncoder) AddUint64(k string, v uint64)          { enc.e.Uint64(enc.key(k), v) }
+func (enc *eventEncoder) AddUint32(k string, v uint32)          { enc.e.Uint32(enc.key(k), v) }
+func (enc *eventEncoder) AddUint16(k string, v uint16)          { enc.e.Uint16(enc.key(k), v) }
+func (enc *eventEncoder) AddUint8(k string, v uint8)            { enc.e.Uint8(enc.key(k), v) }
+func (enc *eventEncoder) AddUintptr(k string, v uintptr)        { enc.e.Uint64(enc.key(k), uint64(v)) }
+
+func (enc *eventEncoder) AddReflected(k string, v interface{}) error {
+	enc.e.Any(enc.key(k), v)
+	return nil
+}
+
+// OpenNamespace prefixes the keys that follow with k and ".".
+// Top-level zap.Namespace fields are nested properly by encodeFields.
+func (enc *eventEncoder) OpenNamespace(k string) {
+	enc.prefix += k + "."
+}
+
+// sliceEncoder is a [zapcore.ArrayEncoder] collecting elements for
+// [bolt.Event.Any].
+type sliceEncoder struct {
+	elems []any
+}
+
+func (s *sliceEncoder) AppendArray(v zapcore.ArrayMarshaler) error {
+	arr := &sliceEncoder{}
+	err := v.MarshalLogArray(arr)
+	s.elems = append(s.elems, arr.elems)
+	return err
+}
+
+func (s *sliceEncoder) AppendObject(v zapcore.ObjectMarshaler) error {
+	m := zapcore.NewMapObjectEncoder()
+	err := v.MarshalLogObject(m)
+	s.elems = append(s.elems, m.Fields)
+	return err
+}
+
+func (s *sliceEncoder) AppendReflected(v interface{}) error {
+	s.elems = append(s.elems, v)
+	return nil
+}
+
+func (s *sliceEncoder) AppendBool(v bool)         { s.elems = append(s.elems, v) }
+func (s *sliceEncoder) AppendByteString(v []byte) { s.elems = append(s.elems, string(v)) }
+func (s *sliceEncoder) AppendComplex128(v complex128) {
+	s.elems = append(s.elems, strconv.FormatComplex(v, 'g', -1, 128))
+}
+func (s *sliceEncoder) AppendComplex64(v complex64) {
+	s.elems = append(s.elems, strconv.FormatComplex(complex128(v), 'g', -1, 64))
+}
+func (s *sliceEncoder) AppendDuration(v time.Duration) { s.elems = append(s.elems, v) }
+func (s *sliceEncoder) AppendFloat64(v float64)        { s.elems = append(s.elems, v) }
+func (s *sliceEncoder) AppendFloat32(v float32)        { s.elems = append(s.elems, v) }
+func (s *sliceEncoder) AppendInt(v int)                { s.elems = append(s.elems, v) }
+func (s *sliceEncoder) AppendInt64(v int64)            { s.elems = append(s.elems, v) }
+func (s *sliceEncoder) AppendInt32(v int32)            { s.elems = append(s.elems, v) }
+func (s *sliceEncoder) AppendInt16(v int16)            { s.elems = append(s.elems, v) }
+func (s *sliceEncoder) AppendInt8(v int8)              { s.elems = append(s.elems, v) }
+func (s *sliceEncoder) AppendString(v string)          { s.elems = append(s.elems, v) }
+func (s *sliceEncoder) AppendTime(v time.Time)         { s.elems = append(s.elems, v) }
+func (s *sliceEncoder) AppendUint(v uint)              { s.elems = append(s.elems, v) }
+func (s *sliceEncoder) AppendUint64(v uint64)          { s.elems = append(s.elems, v) }
+func (s *sliceEncoder) AppendUint32(v uint32)          { s.elems = append(s.elems, v) }
+func (s *sliceEncoder) AppendUint16(v uint16)          { s.elems = append(s.elems, v) }
+func (s *sliceEncoder) AppendUint8(v uint8)            { s.elems = append(s.elems, v) }
+func (s *sliceEncoder) AppendUintptr(v uintptr)        { s.elems = append(s.elems, v) }
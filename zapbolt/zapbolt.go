@@ -0,0 +1,172 @@
+// Package zapbolt implements [zapcore.Core] on top of a bolt logger, so
+// services written against zap keep their call sites while bolt encodes
+// and ships the records:
+//
+//	logger := bolt.New(bolt.NewJSONHandler(os.Stdout)).SetLevel(bolt.INFO)
+//	zl := zap.New(zapbolt.NewCore(logger))
+//	zl.Info("order placed", zap.String("order_id", id), zap.Int("items", 3))
+//
+// The bolt logger is the single source of truth for the level: the core
+// reads it on every check, so bolt's SetLevel, [bolt.DebugBaggageKey]
+// elevation and the like take effect in zap immediately, and
+// zap.Logger.Level reports it. zap's DPanic and Panic levels are logged
+// at ERROR and Fatal at FATAL without exiting, so that zap, not bolt,
+// decides whether to panic or exit: zap.WithFatalHook applies, and the
+// other cores of a zapcore.NewTee still write the entry.
+//
+// Fields are encoded by bolt's typed methods. zap.Namespace nests the
+// fields that follow it; inside a zapcore.ObjectMarshaler, where fields
+// cannot be re-nested after the fact, a namespace prefixes the keys that
+// follow with "name." instead. The logger name, caller (with
+// zap.AddCaller) and stack (with zap.AddStacktrace) are logged under
+// "logger", "caller" and "stack".
+//
+// The package lives in its own go.mod so that the bolt core does not
+// depend on go.uber.org/zap.
+package zapbolt
+
+import (
+	"go.klarlabs.de/bolt"
+	"go.uber.org/zap/zapcore"
+)
+
+// Core is a [zapcore.Core] writing to a bolt logger.
+type Core struct {
+	l *bolt.Logger
+	// nested holds With fields from the first zap.Namespace on; they
+	// cannot be baked into the bolt logger's context because later
+	// fields nest inside them.
+	nested []zapcore.Field
+}
+
+var _ zapcore.Core = (*Core)(nil)
+
+// NewCore returns a Core writing to logger.
+func NewCore(logger *bolt.Logger) *Core {
+	return &Core{l: logger}
+}
+
+// boltLevel maps a zap level onto bolt's.
+func boltLevel(l zapcore.Level) bolt.Level {
+	switch {
+	case l < zapcore.InfoLevel:
+		return bolt.DEBUG
+	case l == zapcore.InfoLevel:
+		return bolt.INFO
+	case l == zapcore.WarnLevel:
+		return bolt.WARN
+	case l < zapcore.FatalLevel:
+		return bolt.ERROR
+	default:
+		return bolt.FATAL
+	}
+}
+
+// Enabled reports whether the bolt logger's level admits l.
+func (c *Core) Enabled(l zapcore.Level) bool {
+	return boltLevel(l) >= c.l.GetLevel()
+}
+
+// Level reports the bolt logger's level as a zap level, for
+// [zapcore.LevelOf] and zap.Logger.Level. TRACE reports as DEBUG.
+func (c *Core) Level() zapcore.Level {
+	switch c.l.GetLevel() {
+	case bolt.TRACE, bolt.DEBUG:
+		return zapcore.DebugLevel
+	case bolt.INFO:
+		return zapcore.InfoLevel
+	case bolt.WARN:
+		return zapcore.WarnLevel
+	case bolt.ERROR:
+		return zapcore.ErrorLevel
+	default:
+		return zapcore.FatalLevel
+	}
+}
+
+// With returns a Core that adds fields to every entry.
+func (c *Core) With(fields []zapcore.Field) zapcore.Core {
+	if len(fields) == 0 {
+		return c
+	}
+	child := &Core{l: c.l}
+	if len(c.nested) > 0 {
+		child.nested = append(append([]zapcore.Field(nil), c.nested...), fields...)
+		return child
+	}
+	i := 0
+	for i < len(fields) && fields[i].Type != zapcore.NamespaceType {
+		i++
+	}
+	if i > 0 {
+		ctx := c.l.With()
+		encodeFields(ctx, fields[:i])
+		child.l = ctx.Logger()
+	}
+	if i < len(fields) {
+		child.nested = append([]zapcore.Field(nil), fields[i:]...)
+	}
+	return child
+}
+
+// Check adds c to ce if the entry's level is enabled.
+func (c *Core) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write logs the entry and fields as one bolt event.
+func (c *Core) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	var e *bolt.Event
+	switch boltLevel(ent.Level) {
+	case bolt.DEBUG:
+		e = c.l.Debug()
+	case bolt.INFO:
+		e = c.l.Info()
+	case bolt.WARN:
+		e = c.l.Warn()
+	case bolt.ERROR:
+		e = c.l.Error()
+	default:
+		e = c.l.Fatal().NoExit() // zap's CheckWriteHook terminates
+	}
+	if ent.LoggerName != "" {
+		e = e.Str("logger", ent.LoggerName)
+	}
+	if ent.Caller.Defined {
+		e = e.Str("caller", ent.Caller.TrimmedPath())
+	}
+	if ent.Stack != "" {
+		e = e.Str("stack", ent.Stack)
+	}
+	if len(c.nested) > 0 {
+		all := make([]zapcore.Field, 0, len(c.nested)+len(fields))
+		fields = append(append(all, c.nested...), fields...)
+	}
+	encodeFields(e, fields)
+	e.Msg(ent.Message)
+	return nil
+}
+
+// Sync is a no-op: bolt handlers write each record as it is logged.
+// Flush buffering writers such as [bolt.BufferedWriter] directly.
+func (c *Core) Sync() error {
+	return nil
+}
+
+// encodeFields adds fields to e, nesting everything after a
+// zap.Namespace field in an object named by it.
+func encodeFields(e *bolt.Event, fields []zapcore.Field) {
+	enc := &eventEncoder{e: e}
+	for i, f := range fields {
+		if f.Type == zapcore.NamespaceType {
+			e.Dict(f.Key, func(d *bolt.Event) {
+				encodeFields(d, fields[i+1:])
+			})
+			return
+		}
+		f.AddTo(enc)
+	}
+}
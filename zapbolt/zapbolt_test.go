@@ -0,0 +1,123 @@
+package zapbolt_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"go.klarlabs.de/bolt"
+	"go.klarlabs.de/bolt/zapbolt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type user struct {
+	name string
+	tags []string
+}
+
+func (u user) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("name", u.name)
+	return enc.AddArray("tags", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+		for _, t := range u.tags {
+			arr.AppendString(t)
+		}
+		return nil
+	}))
+}
+
+func TestCoreFields(t *testing.T) {
+	var buf bytes.Buffer
+	zl := zap.New(zapbolt.NewCore(bolt.New(bolt.NewJSONHandler(&buf)))).
+		Named("orders").
+		With(zap.String("service", "checkout"))
+
+	zl.Warn("order placed",
+		zap.Int("items", 3),
+		zap.Duration("took", 1500*time.Microsecond),
+		zap.Bool("gift", true),
+		zap.Error(errors.New("card expired")),
+		zap.Object("user", user{name: "ann", tags: []string{"vip", "beta"}}),
+		zap.Namespace("shipping"),
+		zap.String("carrier", "dhl"),
+		zap.Float64("cost", 4.5),
+	)
+
+	want := `{"level":"warn","service":"checkout","logger":"orders","items":3,"took":1500000,` +
+		`"gift":true,"error":"card expired","user":{"name":"ann","tags":["vip","beta"]},` +
+		`"shipping":{"carrier":"dhl","cost":4.5},"message":"order placed"}` + "\n"
+	if buf.String() != want {
+		t.Errorf("got  %s\nwant %s", buf.String(), want)
+	}
+}
+
+func TestCoreNamespaceInWith(t *testing.T) {
+	var buf bytes.Buffer
+	zl := zap.New(zapbolt.NewCore(bolt.New(bolt.NewJSONHandler(&buf)))).
+		With(zap.String("app", "a"), zap.Namespace("req"), zap.String("id", "r1"))
+
+	zl.Info("handled", zap.Int("status", 200))
+
+	want := `{"level":"info","app":"a","req":{"id":"r1","status":200},"message":"handled"}` + "\n"
+	if buf.String() != want {
+		t.Errorf("got  %s\nwant %s", buf.String(), want)
+	}
+}
+
+func TestCoreLevelFollowsBolt(t *testing.T) {
+	var buf bytes.Buffer
+	logger := bolt.New(bolt.NewJSONHandler(&buf)).SetLevel(bolt.WARN)
+	zl := zap.New(zapbolt.NewCore(logger))
+
+	zl.Info("hidden")
+	if buf.Len() != 0 {
+		t.Fatalf("info logged at WARN: %s", buf.String())
+	}
+	if zl.Level() != zapcore.WarnLevel {
+		t.Errorf("zap level = %v, want warn", zl.Level())
+	}
+
+	logger.SetLevel(bolt.DEBUG)
+	if zl.Level() != zapcore.DebugLevel || !zl.Core().Enabled(zapcore.DebugLevel) {
+		t.Errorf("zap level did not follow bolt: %v", zl.Level())
+	}
+	zl.Debug("visible")
+	if buf.Len() == 0 {
+		t.Error("debug not logged after bolt SetLevel(DEBUG)")
+	}
+
+	buf.Reset()
+	func() {
+		defer func() { _ = recover() }()
+		zl.Panic("boom")
+	}()
+	if !bytes.Contains(buf.Bytes(), []byte(`"level":"error"`)) {
+		t.Errorf("panic entry not logged at error: %s", buf.String())
+	}
+}
+
+func TestCoreFatalUsesZapHook(t *testing.T) {
+	var buf, other bytes.Buffer
+	logger := bolt.New(bolt.NewJSONHandler(&buf))
+	tee := zapcore.NewTee(
+		zapbolt.NewCore(logger),
+		zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(&other), zapcore.DebugLevel),
+	)
+	zl := zap.New(tee, zap.WithFatalHook(zapcore.WriteThenPanic))
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("fatal hook did not run")
+			}
+		}()
+		zl.Fatal("shutting down")
+	}()
+	if !bytes.Contains(buf.Bytes(), []byte(`"level":"fatal"`)) {
+		t.Errorf("fatal entry not logged at fatal: %s", buf.String())
+	}
+	if !bytes.Contains(other.Bytes(), []byte("shutting down")) {
+		t.Errorf("other tee core did not receive the entry: %s", other.String())
+	}
+}